@@ -0,0 +1,141 @@
+package msg
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// Direction 表示文本的书写方向，取值与 HTML 的 dir 属性一致
+type Direction string
+
+const (
+	LeftToRight Direction = "ltr" // 从左到右，如英语、中文
+	RightToLeft Direction = "rtl" // 从右到左，如阿拉伯语、希伯来语
+)
+
+// Unicode 双向隔离控制字符
+//
+// 插入到译文中的用户名、文件名等值可能与译文的书写方向不同，
+// 不加隔离时会打乱周围文字的显示顺序。
+const (
+	LeftToRightIsolate    = "\u2066" // LRI，按从左到右隔离
+	RightToLeftIsolate    = "\u2067" // RLI，按从右到左隔离
+	FirstStrongIsolate    = "\u2068" // FSI，按第一个强方向字符决定方向
+	PopDirectionalIsolate = "\u2069" // PDI，结束隔离
+)
+
+// rtlScripts 从右到左书写的脚本（ISO 15924）
+var rtlScripts = map[string]bool{
+	"Adlm": true, // 阿德拉姆字母
+	"Arab": true, // 阿拉伯字母
+	"Aran": true, // 波斯体阿拉伯字母
+	"Hebr": true, // 希伯来字母
+	"Mand": true, // 曼达字母
+	"Mend": true, // 门德字母
+	"Nkoo": true, // 西非书面字母
+	"Rohg": true, // 哈乃斐罗兴亚字母
+	"Samr": true, // 撒玛利亚字母
+	"Syrc": true, // 叙利亚字母
+	"Thaa": true, // 塔安那字母
+	"Yezi": true, // 雅兹迪字母
+}
+
+// rtlLanguages 默认脚本从右到左书写的语言
+var rtlLanguages = map[string]bool{
+	"ar":  true, // 阿拉伯语
+	"arc": true, // 阿拉米语
+	"ckb": true, // 中库尔德语
+	"dv":  true, // 迪维希语
+	"fa":  true, // 波斯语
+	"he":  true, // 希伯来语
+	"iw":  true, // 希伯来语（已弃用的代码）
+	"ks":  true, // 克什米尔语
+	"ps":  true, // 普什图语
+	"sd":  true, // 信德语
+	"syr": true, // 叙利亚语
+	"ug":  true, // 维吾尔语
+	"ur":  true, // 乌尔都语
+	"yi":  true, // 意第绪语
+}
+
+// IsRTL 检查 Locale 是否从右到左书写。
+//
+// 带脚本时按脚本判断，否则按语言的默认脚本判断。
+//
+// 示例：
+//   - "ar-EG" → true
+//   - "ug-CN" → true
+//   - "pa-Arab" → true (旁遮普语的阿拉伯字母写法)
+//   - "az-Latn" → false
+//   - "en" → false
+func (l Locale) IsRTL() bool {
+	if script := l.Script(); script != "" {
+		return rtlScripts[script]
+	}
+	return rtlLanguages[l.Language()]
+}
+
+// Direction 返回 Locale 的书写方向
+func (l Locale) Direction() Direction {
+	if l.IsRTL() {
+		return RightToLeft
+	}
+	return LeftToRight
+}
+
+// HTMLAttrs 返回页面根元素的 lang 和 dir 属性，如 `lang="ar" dir="rtl"`，
+// 可以直接在 html/template 中使用，为 rsp.HTMLMarshaller 等 HTML 渲染器提供方向提示：
+//
+//	<html {{ .Attrs }}>
+//
+// 其中 Attrs 为 msg.HTMLAttrs(manager.LocaleFromContext(ctx))。Locale 为空时返回空属性。
+func HTMLAttrs(locale Locale) template.HTMLAttr {
+	if locale == "" {
+		return ""
+	}
+	lang := template.HTMLEscapeString(string(locale))
+	return template.HTMLAttr(`lang="` + lang + `" dir="` + string(locale.Direction()) + `"`)
+}
+
+// Isolate 使用 FSI 和 PDI 包围 s，由 s 中第一个强方向字符决定其方向，
+// 适用于插入方向未知的用户输入，如用户名。s 为空时原样返回。
+//
+// 示例：
+//
+//	p.Sprintf("%s 评论了你的帖子", msg.Isolate(username))
+func Isolate(s string) string {
+	if s == "" {
+		return s
+	}
+	return FirstStrongIsolate + s + PopDirectionalIsolate
+}
+
+// IsolateDirection 按指定的方向隔离 s，适用于方向已知的值，如 URL 和文件路径总是从左到右。
+func IsolateDirection(s string, dir Direction) string {
+	if s == "" {
+		return s
+	}
+	if dir == RightToLeft {
+		return RightToLeftIsolate + s + PopDirectionalIsolate
+	}
+	return LeftToRightIsolate + s + PopDirectionalIsolate
+}
+
+// IsolateArgs 返回 args 的副本，其中的字符串和 fmt.Stringer 参数使用 Isolate 隔离，
+// 其它类型（如数字）保持不变，以便继续使用 %d、%.2f 等格式化动词：
+//
+//	p.Sprintf("%s 上传了 %d 个文件", msg.IsolateArgs(username, count)...)
+func IsolateArgs(args ...any) []any {
+	isolated := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			isolated[i] = Isolate(v)
+		case fmt.Stringer:
+			isolated[i] = Isolate(v.String())
+		default:
+			isolated[i] = arg
+		}
+	}
+	return isolated
+}
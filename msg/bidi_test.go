@@ -0,0 +1,96 @@
+package msg
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestLocale_IsRTL(t *testing.T) {
+	tests := map[Locale]bool{
+		Arabic:       true,
+		ArabicEG:     true,
+		"he-IL":      true,
+		"iw":         true,
+		"fa-IR":      true,
+		"ur-PK":      true,
+		"ug-CN":      true,
+		"yi":         true,
+		"pa-Arab":    true,
+		"pa-Arab-PK": true,
+		"ms-Arab":    true,
+		"ku-Arab":    true,
+		"az-Latn":    false,
+		"ku":         false,
+		"pa":         false,
+		"uz-Cyrl":    false,
+		English:      false,
+		"zh-Hans-CN": false,
+		Japanese:     false,
+		"":           false,
+	}
+
+	for locale, expected := range tests {
+		if got := locale.IsRTL(); got != expected {
+			t.Errorf("Locale(%q).IsRTL() = %v, want %v", locale, got, expected)
+		}
+		dir := LeftToRight
+		if expected {
+			dir = RightToLeft
+		}
+		if got := locale.Direction(); got != dir {
+			t.Errorf("Locale(%q).Direction() = %q, want %q", locale, got, dir)
+		}
+	}
+}
+
+func TestHTMLAttrs(t *testing.T) {
+	tests := map[Locale]template.HTMLAttr{
+		"ar-EG":        `lang="ar-EG" dir="rtl"`,
+		English:        `lang="en" dir="ltr"`,
+		`en"><script>`: `lang="en&#34;&gt;&lt;script&gt;" dir="ltr"`,
+		"":             "",
+	}
+	for locale, expected := range tests {
+		if got := HTMLAttrs(locale); got != expected {
+			t.Errorf("HTMLAttrs(%q) = %q, want %q", locale, got, expected)
+		}
+	}
+
+	tmpl := template.Must(template.New("page").Parse(`<html {{ . }}></html>`))
+	var b strings.Builder
+	if err := tmpl.Execute(&b, HTMLAttrs("he")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), `<html lang="he" dir="rtl"></html>`; got != want {
+		t.Errorf("template output = %q, want %q", got, want)
+	}
+}
+
+func TestIsolate(t *testing.T) {
+	if got := Isolate("محمد"); got != "\u2068محمد\u2069" {
+		t.Errorf("Isolate() = %q", got)
+	}
+	if got := Isolate(""); got != "" {
+		t.Errorf("Isolate(\"\") = %q, want empty", got)
+	}
+	if got := IsolateDirection("/tmp/file.txt", LeftToRight); got != "\u2066/tmp/file.txt\u2069" {
+		t.Errorf("IsolateDirection(ltr) = %q", got)
+	}
+	if got := IsolateDirection("שלום", RightToLeft); got != "\u2067שלום\u2069" {
+		t.Errorf("IsolateDirection(rtl) = %q", got)
+	}
+}
+
+func TestIsolateArgs(t *testing.T) {
+	args := []any{"Alice", 3, Locale("ar"), 1.5}
+	got := fmt.Sprintf("%s uploaded %d files in %s (%.1f MB)", IsolateArgs(args...)...)
+	want := "\u2068Alice\u2069 uploaded 3 files in \u2068ar\u2069 (1.5 MB)"
+	if got != want {
+		t.Errorf("Sprintf with IsolateArgs = %q, want %q", got, want)
+	}
+	if args[0] != "Alice" {
+		t.Error("IsolateArgs should not modify its arguments")
+	}
+}
@@ -0,0 +1,147 @@
+package msg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Template 是一种语言的模板，如一封事务邮件，主题和正文都是命名参数模板。
+//
+// 语法与 Sprintn 相同，支持 {name} 占位符以及 select 和 plural 选择；
+// 模板本身就是译文，不会再经过翻译目录查找，因此 "%" 不需要写作 "%%"。
+type Template struct {
+	Subject string // 主题，如邮件标题
+	Body    string // 正文
+}
+
+// Rendered 是渲染后的模板
+type Rendered struct {
+	Locale  Locale // 实际使用的模板语言，请求的语言没有模板时为回退语言
+	Subject string
+	Body    string
+}
+
+// Bundle 按语言保存一组命名模板，用于事务邮件、推送通知等需要整体本地化的文本。
+//
+// 渲染时按请求的语言选择最接近的模板（规则与 LocaleSet.Match 相同），
+// 没有合适的模板时使用回退语言的模板；模板引用了未提供的参数时返回 *MissingArgsError。
+// Bundle 可以在多个 goroutine 中并发使用。
+//
+// 示例：
+//
+//	emails := msg.NewBundle(msg.English)
+//	emails.Add("welcome", msg.English, msg.Template{
+//	    Subject: "Welcome, {name}!",
+//	    Body:    "Hi {name},\n\nThanks for signing up. You have {credits, plural, one {one free credit} other {{credits} free credits}}.",
+//	})
+//	emails.Add("welcome", msg.Chinese, msg.Template{
+//	    Subject: "欢迎，{name}！",
+//	    Body:    "{name}，你好：\n\n感谢注册，你获得了 {credits} 个免费额度。",
+//	})
+//
+//	mail, err := emails.RenderContext(ctx, "welcome", msg.Args{"name": "Bob", "credits": 3})
+//	if err != nil {
+//	    return err
+//	}
+//	send(user.Email, mail.Subject, mail.Body)
+type Bundle struct {
+	mu        sync.RWMutex
+	fallback  Locale
+	templates map[string]map[Locale]Template
+}
+
+// NewBundle 创建模板集合，fallback 为请求的语言没有模板时使用的语言
+func NewBundle(fallback Locale) *Bundle {
+	return &Bundle{
+		fallback:  fallback,
+		templates: make(map[string]map[Locale]Template),
+	}
+}
+
+// Add 添加一种语言的模板，替换同名同语言的已有模板
+func (b *Bundle) Add(name string, locale Locale, t Template) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.templates[name] == nil {
+		b.templates[name] = make(map[Locale]Template)
+	}
+	b.templates[name][locale] = t
+}
+
+// Locales 返回模板 name 支持的语言，按语言排序
+func (b *Bundle) Locales(name string) LocaleSet {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return slices.Sorted(maps.Keys(b.templates[name]))
+}
+
+// Render 使用 p 的语言选择模板 name 并渲染，参数值由 p 格式化。
+//
+// 没有该名称的模板或请求的语言和回退语言都没有模板时返回错误；
+// 渲染的分支引用了未提供的参数时返回 *MissingArgsError，缺失的参数渲染为 "%!{name}(MISSING)"。
+func (b *Bundle) Render(p Printer, name string, args Args) (*Rendered, error) {
+	locale, t, err := b.lookup(name, p.Locale())
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Rendered{Locale: locale}
+	var errs []error
+	var missing []string
+	r.Subject, missing = substitute(p, t.Subject, args)
+	if len(missing) > 0 {
+		errs = append(errs, &MissingArgsError{Format: t.Subject, Names: missing})
+	}
+	r.Body, missing = substitute(p, t.Body, args)
+	if len(missing) > 0 {
+		errs = append(errs, &MissingArgsError{Format: t.Body, Names: missing})
+	}
+	return r, errors.Join(errs...)
+}
+
+// RenderContext 使用默认 Manager 中上下文的语言渲染模板 name，见 Render
+func (b *Bundle) RenderContext(ctx context.Context, name string, args Args) (*Rendered, error) {
+	return b.Render(GetPrinterWithContext(ctx), name, args)
+}
+
+// CheckArgs 检查模板 name 在所有语言中引用的参数是否都已提供，
+// select 和 plural 的所有分支都会被检查，可用于在测试中校验译者提交的模板。
+func (b *Bundle) CheckArgs(name string, args Args) error {
+	b.mu.RLock()
+	templates := maps.Clone(b.templates[name])
+	b.mu.RUnlock()
+
+	if len(templates) == 0 {
+		return fmt.Errorf("msg: template %q not found", name)
+	}
+	var errs []error
+	for _, locale := range slices.Sorted(maps.Keys(templates)) {
+		t := templates[locale]
+		for _, format := range []string{t.Subject, t.Body} {
+			if err := CheckArgs(format, args); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", locale, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lookup 选择与 locale 最接近的模板，没有时使用回退语言的模板
+func (b *Bundle) lookup(name string, locale Locale) (Locale, Template, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	templates := b.templates[name]
+	if len(templates) == 0 {
+		return "", Template{}, fmt.Errorf("msg: template %q not found", name)
+	}
+	supported := LocaleSet(slices.Collect(maps.Keys(templates)))
+	if match, ok := supported.Match(locale, b.fallback); ok {
+		return match, templates[match], nil
+	}
+	return "", Template{}, fmt.Errorf("msg: template %q has no translation for %q or fallback %q", name, locale, b.fallback)
+}
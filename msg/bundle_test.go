@@ -0,0 +1,82 @@
+package msg
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	b := NewBundle(English)
+	b.Add("welcome", English, Template{
+		Subject: "Welcome, {name}!",
+		Body:    "You have {credits, plural, one {one free credit} other {{credits} free credits}}, 100% free.",
+	})
+	b.Add("welcome", "zh-Hans", Template{
+		Subject: "欢迎，{name}！",
+		Body:    "你获得了 {credits} 个免费额度。",
+	})
+
+	tests := []struct {
+		locale  Locale
+		want    Locale
+		subject string
+		body    string
+	}{
+		{English, English, "Welcome, Bob!", "You have 3 free credits, 100% free."},
+		{"zh-Hans-CN", "zh-Hans", "欢迎，Bob！", "你获得了 3 个免费额度。"},
+		{"fr", English, "Welcome, Bob!", "You have 3 free credits, 100% free."},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.locale), func(t *testing.T) {
+			got, err := b.Render(NewPrinter(tt.locale), "welcome", Args{"name": "Bob", "credits": 3})
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got.Locale != tt.want || got.Subject != tt.subject || got.Body != tt.body {
+				t.Errorf("Render() = %+v, want %s %q %q", got, tt.want, tt.subject, tt.body)
+			}
+		})
+	}
+
+	if got := b.Locales("welcome"); !slices.Equal(got, LocaleSet{English, "zh-Hans"}) {
+		t.Errorf("Locales() = %v", got)
+	}
+
+	got, err := b.Render(NewPrinter(English), "welcome", Args{"credits": 1})
+	var missing *MissingArgsError
+	if !errors.As(err, &missing) || !slices.Equal(missing.Names, []string{"name"}) {
+		t.Errorf("Render() error = %v, want missing {name}", err)
+	}
+	if got == nil || got.Body != "You have one free credit, 100% free." {
+		t.Errorf("Render() = %+v, want the body rendered despite the missing subject argument", got)
+	}
+
+	if _, err := b.Render(NewPrinter(English), "reset", nil); err == nil {
+		t.Error("Render() of an unknown template should return an error")
+	}
+
+	noFallback := NewBundle(English)
+	noFallback.Add("notice", Japanese, Template{Subject: "お知らせ"})
+	if _, err := noFallback.Render(NewPrinter("de"), "notice", nil); err == nil {
+		t.Error("Render() without a matching or fallback template should return an error")
+	}
+}
+
+func TestBundle_CheckArgs(t *testing.T) {
+	b := NewBundle(English)
+	b.Add("invite", English, Template{Subject: "{inviter} invited you", Body: "{gender, select, female {She} other {They}} said: {note}"})
+	b.Add("invite", Chinese, Template{Subject: "{inviter} 邀请了你", Body: "留言：{note}"})
+
+	if err := b.CheckArgs("invite", Args{"inviter": "Ann", "gender": "female", "note": "hi"}); err != nil {
+		t.Errorf("CheckArgs() error = %v", err)
+	}
+	err := b.CheckArgs("invite", Args{"inviter": "Ann", "gender": "female"})
+	if err == nil || !strings.Contains(err.Error(), "en: msg: missing arguments {note}") || !strings.Contains(err.Error(), "zh: msg: missing arguments {note}") {
+		t.Errorf("CheckArgs() error = %v, want {note} missing in both locales", err)
+	}
+	if err := b.CheckArgs("unknown", nil); err == nil {
+		t.Error("CheckArgs() of an unknown template should return an error")
+	}
+}
@@ -0,0 +1,196 @@
+package msg
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultPrinterCacheSize Manager 默认缓存的 Printer 数量
+const DefaultPrinterCacheSize = 128
+
+// printerKey 缓存的键，同一语言在不同工厂下对应不同的 Printer
+type printerKey struct {
+	factory PrinterFactory
+	locale  Locale
+}
+
+// printerCache 按最近使用顺序淘汰的 Printer 缓存（LRU）。
+//
+// 缓存 GetPrinterWithContext 解析后的结果，请求的语言和工厂相同时
+// 直接复用 Printer，不再重复检查工厂支持的语言。
+type printerCache struct {
+	mu    sync.Mutex
+	size  int
+	gen   uint64                       // 每次清空时递增，避免清空前开始解析的 Printer 在清空后写入
+	ll    *list.List                   // 最近使用的在前
+	items map[printerKey]*list.Element // 键到链表元素的映射
+}
+
+type printerEntry struct {
+	key     printerKey
+	printer Printer
+}
+
+func newPrinterCache(size int) *printerCache {
+	return &printerCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[printerKey]*list.Element, size),
+	}
+}
+
+// get 返回缓存的 Printer 并将其标记为最近使用，未命中时返回当前的代数，用于之后的 add
+func (c *printerCache) get(key printerKey) (Printer, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*printerEntry).printer, c.gen, true
+	}
+	return nil, c.gen, false
+}
+
+// add 缓存 Printer，超出容量时淘汰最久未使用的。
+// gen 与当前代数不同说明缓存在解析期间被清空过，此时不缓存
+func (c *printerCache) add(key printerKey, printer Printer, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*printerEntry).printer = printer
+		return
+	}
+	c.items[key] = c.ll.PushFront(&printerEntry{key: key, printer: printer})
+	if c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*printerEntry).key)
+	}
+}
+
+// len 返回缓存的 Printer 数量
+func (c *printerCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// purge 清空缓存
+func (c *printerCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.ll.Init()
+	clear(c.items)
+}
+
+// cacheable 检查工厂能否作为缓存的键，动态类型不可比较的工厂不缓存
+func cacheable(factory PrinterFactory) bool {
+	return factory != nil && reflect.TypeOf(factory).Comparable()
+}
+
+// DefaultLocaleCacheSize Locale.Parts 默认缓存的 Locale 数量
+const DefaultLocaleCacheSize = 1024
+
+// localeCache 缓存 Locale.Parts 的解析结果
+var localeCache = newPartsCache(DefaultLocaleCacheSize)
+
+// LocaleCacheStats 是 Locale.Parts 解析结果缓存的统计
+type LocaleCacheStats struct {
+	Size      int    // 当前缓存的 Locale 数量
+	Capacity  int    // 缓存容量，0 表示不缓存
+	Hits      uint64 // 命中次数
+	Misses    uint64 // 未命中次数
+	Evictions uint64 // 因缓存已满被淘汰的条目数
+}
+
+// SetLocaleCacheSize 设置 Locale.Parts 缓存的容量并清空缓存，n 为 0 时不缓存，返回之前的容量。
+//
+// Language、Region、Extension 等方法都通过 Parts 解析 Locale，缓存避免重复解析同一字符串。
+// Locale 来自用户输入（如 Accept-Language）时不同的字符串数量没有上限，缓存满时整体清空，
+// 常用的 Locale 随后重新进入缓存，因此内存占用不超过约 n 个 Locale 字符串及其解析结果
+// （默认 1024 个，约 200 KB）。解析本身不分配内存，不缓存时每次解析的开销约为命中缓存的两倍。
+func SetLocaleCacheSize(n int) int {
+	return localeCache.resize(max(n, 0))
+}
+
+// GetLocaleCacheStats 返回 Locale.Parts 缓存的统计，可用于观察缓存容量是否合适
+func GetLocaleCacheStats() LocaleCacheStats {
+	return localeCache.stats()
+}
+
+// partsCache 容量有限的 Locale 解析结果缓存。
+//
+// 读取使用 sync.Map 以避免锁竞争；条目数达到容量时整体清空而不是按最近使用淘汰，
+// 因为解析的开销很小，维护 LRU 链表的开销反而更大。
+type partsCache struct {
+	m         sync.Map // map[string]localeParts
+	size      atomic.Int64
+	capacity  atomic.Int64
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+func newPartsCache(capacity int) *partsCache {
+	c := &partsCache{}
+	c.capacity.Store(int64(capacity))
+	return c
+}
+
+// load 返回缓存的解析结果
+func (c *partsCache) load(s string) (localeParts, bool) {
+	if v, ok := c.m.Load(s); ok {
+		c.hits.Add(1)
+		return v.(localeParts), true
+	}
+	c.misses.Add(1)
+	return localeParts{}, false
+}
+
+// store 缓存解析结果，缓存已满时先清空
+func (c *partsCache) store(s string, parts localeParts) {
+	capacity := c.capacity.Load()
+	if capacity <= 0 {
+		return
+	}
+	if c.size.Load() >= capacity {
+		c.evictions.Add(uint64(c.reset()))
+	}
+	if _, loaded := c.m.LoadOrStore(s, parts); !loaded {
+		c.size.Add(1)
+	}
+}
+
+// reset 清空缓存，返回清空前的条目数
+func (c *partsCache) reset() int64 {
+	n := c.size.Swap(0)
+	c.m.Clear()
+	return n
+}
+
+// resize 设置容量并清空缓存，返回之前的容量
+func (c *partsCache) resize(capacity int) int {
+	old := c.capacity.Swap(int64(capacity))
+	c.reset()
+	return int(old)
+}
+
+// stats 返回缓存的统计
+func (c *partsCache) stats() LocaleCacheStats {
+	return LocaleCacheStats{
+		Size:      int(max(c.size.Load(), 0)),
+		Capacity:  int(c.capacity.Load()),
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
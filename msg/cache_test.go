@@ -0,0 +1,91 @@
+package msg
+
+import (
+	"testing"
+)
+
+func TestPrinterCache(t *testing.T) {
+	c := newPrinterCache(2)
+	en := printerKey{locale: English}
+	zh := printerKey{locale: Chinese}
+	ja := printerKey{locale: Japanese}
+
+	_, gen, _ := c.get(en)
+	c.add(en, NewPrinter(English), gen)
+	c.add(zh, NewPrinter(Chinese), gen)
+
+	// 访问 en 后，zh 成为最久未使用的
+	if _, _, ok := c.get(en); !ok {
+		t.Fatal("get(en) missed")
+	}
+	c.add(ja, NewPrinter(Japanese), gen)
+
+	if _, _, ok := c.get(zh); ok {
+		t.Error("get(zh) hit, want evicted")
+	}
+	if p, _, ok := c.get(en); !ok || !p.Locale().Equal(English) {
+		t.Errorf("get(en) = %v, %v, want English printer", p, ok)
+	}
+	if c.len() != 2 {
+		t.Errorf("len() = %d, want 2", c.len())
+	}
+
+	// 清空前开始解析的 Printer 不会写入缓存
+	c.purge()
+	c.add(zh, NewPrinter(Chinese), gen)
+	if c.len() != 0 {
+		t.Errorf("len() after purge = %d, want 0", c.len())
+	}
+}
+
+func TestCacheable(t *testing.T) {
+	if !cacheable(NewPrinterFactory()) {
+		t.Error("cacheable(pointer factory) = false, want true")
+	}
+	if cacheable(nil) {
+		t.Error("cacheable(nil) = true, want false")
+	}
+	if cacheable(sliceFactory{}) {
+		t.Error("cacheable(slice factory) = true, want false")
+	}
+}
+
+// sliceFactory 是动态类型不可比较的工厂
+type sliceFactory []Locale
+
+func (sliceFactory) CreatePrinter(locale Locale) (Printer, error) { return NewPrinter(locale), nil }
+func (sliceFactory) SupportsLocale(Locale) bool                   { return true }
+func (sliceFactory) SupportedLocales() LocaleSet                  { return nil }
+func (sliceFactory) SetFallbackLocale(Locale) Locale              { return English }
+func (sliceFactory) GetFallbackLocale() Locale                    { return English }
+
+func TestLocaleCache(t *testing.T) {
+	defer SetLocaleCacheSize(SetLocaleCacheSize(2))
+
+	for _, l := range []Locale{"en-US", "en-US", "zh-CN-u-ca-chinese"} {
+		l.Parts()
+	}
+	stats := GetLocaleCacheStats()
+	if stats.Size != 2 || stats.Capacity != 2 || stats.Evictions != 0 {
+		t.Errorf("GetLocaleCacheStats() = %+v, want 2 cached locales", stats)
+	}
+
+	// A full cache is cleared instead of growing
+	slr, ext, pri := Locale("de-DE-u-co-phonebk-x-test").Parts()
+	if slr != "de-DE" || ext != "co-phonebk" || pri != "test" {
+		t.Errorf("Parts() = %q, %q, %q", slr, ext, pri)
+	}
+	after := GetLocaleCacheStats()
+	if after.Size != 1 || after.Evictions != 2 {
+		t.Errorf("GetLocaleCacheStats() = %+v, want the cache cleared", after)
+	}
+	if after.Misses != stats.Misses+1 {
+		t.Errorf("Misses = %d, want %d", after.Misses, stats.Misses+1)
+	}
+
+	SetLocaleCacheSize(0)
+	Locale("fr-FR").Parts()
+	if stats := GetLocaleCacheStats(); stats.Size != 0 || stats.Capacity != 0 {
+		t.Errorf("GetLocaleCacheStats() = %+v, want caching disabled", stats)
+	}
+}
@@ -0,0 +1,205 @@
+package msg
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ChineseScriptAliases 将只带地区的中文标签映射为带脚本的形式，默认不启用。
+//
+// 浏览器和操作系统通常发送 "zh-CN"、"zh-TW" 等标签，而翻译文件多按脚本组织，
+// 需要时可以通过 RegisterLocaleAliases 注册：
+//
+//	msg.RegisterLocaleAliases(msg.ChineseScriptAliases)
+//	msg.Locale("zh-cn").Canonicalize() // → "zh-Hans-CN"
+var ChineseScriptAliases = map[Locale]Locale{
+	"zh-CN": "zh-Hans-CN",
+	"zh-SG": "zh-Hans-SG",
+	"zh-MY": "zh-Hans-MY",
+	"zh-TW": "zh-Hant-TW",
+	"zh-HK": "zh-Hant-HK",
+	"zh-MO": "zh-Hant-MO",
+}
+
+var (
+	// localeAliases 别名到规范形式的映射，键和值都已规范化大小写
+	localeAliases = map[Locale]Locale{
+		// IANA 语言子标签注册表中已弃用的语言代码
+		"iw": "he", // 希伯来语
+		"in": "id", // 印度尼西亚语
+		"ji": "yi", // 意第绪语
+		"jw": "jv", // 爪哇语
+		"mo": "ro", // 摩尔多瓦语 → 罗马尼亚语
+	}
+
+	// localeAliasesMutex 保护 localeAliases 的并发访问
+	localeAliasesMutex sync.RWMutex
+)
+
+// RegisterLocaleAlias 注册语言标签别名，Canonicalize 会将别名替换为规范形式。
+//
+// 别名优先与标签的基础部分（语言、脚本和地区）整体匹配，如 "zh-CN" → "zh-Hans-CN"，
+// 扩展和私有部分保持不变；没有匹配时，规范形式只有语言代码的别名再单独替换语言部分，
+// 如 "iw" → "he" 使 "iw-IL" 规范化为 "he-IL"。
+//
+// 别名和规范形式会先规范化大小写；格式无效时 panic，与 database/sql.Register 等注册函数一致。
+// 重复注册会覆盖之前的映射。
+func RegisterLocaleAlias(alias, canonical Locale) {
+	a, err := wellFormed(alias)
+	if err != nil {
+		panic("msg: RegisterLocaleAlias " + err.Error())
+	}
+	c, err := wellFormed(canonical)
+	if err != nil {
+		panic("msg: RegisterLocaleAlias " + err.Error())
+	}
+
+	localeAliasesMutex.Lock()
+	defer localeAliasesMutex.Unlock()
+	localeAliases[a] = c
+}
+
+// RegisterLocaleAliases 批量注册语言标签别名，规则与 RegisterLocaleAlias 相同。
+func RegisterLocaleAliases(aliases map[Locale]Locale) {
+	for alias, canonical := range aliases {
+		RegisterLocaleAlias(alias, canonical)
+	}
+}
+
+// Canonicalize 返回 Locale 的规范形式。
+//
+// 处理步骤：
+//  1. 规范化大小写和分隔符，如 "zh_hans_cn" → "zh-Hans-CN"
+//  2. 校验标签符合 BCP 47 的语法，格式无效时返回错误
+//  3. 替换已注册的别名，默认包含已弃用的语言代码，如 "iw" → "he"、"in" → "id"
+//
+// 示例：
+//   - "EN-us" → "en-US"
+//   - "iw-IL" → "he-IL"
+//   - "zh-CN" → "zh-CN"（注册 ChineseScriptAliases 后为 "zh-Hans-CN"）
+//   - "en--US" → 错误
+func (l Locale) Canonicalize() (Locale, error) {
+	c, err := wellFormed(l)
+	if err != nil {
+		return "", err
+	}
+
+	localeAliasesMutex.RLock()
+	defer localeAliasesMutex.RUnlock()
+
+	base, ok := c.Base()
+	if !ok || !strings.HasPrefix(string(c), string(base)) {
+		return c, nil
+	}
+	rest := c[len(base):]
+
+	// 优先替换整个基础部分，其次只替换语言代码
+	if alias, ok := localeAliases[base]; ok {
+		return alias + rest, nil
+	}
+	language, tail, _ := strings.Cut(string(base), "-")
+	if alias, ok := localeAliases[Locale(language)]; ok && !strings.Contains(string(alias), "-") {
+		if tail != "" {
+			alias += Locale("-" + tail)
+		}
+		return alias + rest, nil
+	}
+	return c, nil
+}
+
+// wellFormed 规范化大小写并校验标签符合 BCP 47 的语法：
+//
+//	language[-script][-region]*(-variant)*(-extension)[-x-private]
+func wellFormed(l Locale) (Locale, error) {
+	c := CanonicalLocale(string(l))
+	if c == "" {
+		return "", fmt.Errorf("invalid locale %q: empty tag", l)
+	}
+
+	subtags := strings.Split(string(c), "-")
+	invalid := func(i int, what string) error {
+		return fmt.Errorf("invalid locale %q: %s %q", l, what, subtags[i])
+	}
+
+	i := 0
+	switch s := subtags[0]; {
+	case s == "x":
+		// 纯私有标签，如 "x-custom"
+	case isAlpha(s) && (len(s) >= 2 && len(s) <= 3 || len(s) >= 5 && len(s) <= 8):
+		i++
+		if i < len(subtags) && len(subtags[i]) == 4 && isAlpha(subtags[i]) {
+			i++ // 脚本
+		}
+		if i < len(subtags) && (len(subtags[i]) == 2 && isAlpha(subtags[i]) || len(subtags[i]) == 3 && isDigit(subtags[i])) {
+			i++ // 地区
+		}
+		for i < len(subtags) && isVariant(subtags[i]) {
+			i++
+		}
+	default:
+		return "", invalid(0, "language subtag")
+	}
+
+	// 扩展和私有部分：单字符开头，后跟至少一个子标签
+	for i < len(subtags) {
+		singleton := subtags[i]
+		if len(singleton) != 1 || !isAlphanumeric(singleton) {
+			return "", invalid(i, "subtag")
+		}
+		minLen := 2
+		if singleton == "x" {
+			minLen = 1
+		}
+		i++
+		start := i
+		for i < len(subtags) && len(subtags[i]) >= minLen && len(subtags[i]) <= 8 && isAlphanumeric(subtags[i]) {
+			i++
+		}
+		if i == start {
+			if i < len(subtags) {
+				return "", invalid(i, "extension subtag")
+			}
+			return "", invalid(i-1, "empty extension")
+		}
+		if singleton == "x" && i < len(subtags) {
+			return "", invalid(i, "private use subtag")
+		}
+	}
+	return c, nil
+}
+
+// isVariant 检查是否为变体子标签：5 到 8 位字母数字，或数字开头的 4 位字母数字
+func isVariant(s string) bool {
+	if !isAlphanumeric(s) {
+		return false
+	}
+	return len(s) >= 5 && len(s) <= 8 || len(s) == 4 && s[0] >= '0' && s[0] <= '9'
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isDigit(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,127 @@
+package msg
+
+import (
+	"maps"
+	"testing"
+)
+
+// restoreLocaleAliases 在测试结束后恢复别名注册表
+func restoreLocaleAliases(t *testing.T) {
+	localeAliasesMutex.RLock()
+	saved := maps.Clone(localeAliases)
+	localeAliasesMutex.RUnlock()
+
+	t.Cleanup(func() {
+		localeAliasesMutex.Lock()
+		localeAliases = saved
+		localeAliasesMutex.Unlock()
+	})
+}
+
+func TestLocale_Canonicalize(t *testing.T) {
+	tests := []struct {
+		input    Locale
+		expected Locale
+	}{
+		// 大小写和分隔符
+		{"en", "en"},
+		{"EN-us", "en-US"},
+		{"zh-hans-cn", "zh-Hans-CN"},
+		{"zh_Hant_tw", "zh-Hant-TW"},
+		{"es-419", "es-419"},
+		{"de-DE-1996", "de-DE-1996"},
+		{"sl-rozaj-biske", "sl-rozaj-biske"},
+		{"en-US-U-CA-Gregory", "en-US-u-ca-gregory"},
+		{"zh-Hans-CN-x-Custom", "zh-Hans-CN-x-custom"},
+		{"x-whatever", "x-whatever"},
+
+		// 已弃用的语言代码
+		{"iw", "he"},
+		{"iw-IL", "he-IL"},
+		{"IN-id", "id-ID"},
+		{"ji", "yi"},
+		{"jw-Latn-ID", "jv-Latn-ID"},
+		{"mo-MD-u-nu-latn", "ro-MD-u-nu-latn"},
+
+		// 默认不启用中文脚本别名
+		{"zh-CN", "zh-CN"},
+		{"zh-TW", "zh-TW"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.input), func(t *testing.T) {
+			got, err := tt.input.Canonicalize()
+			if err != nil {
+				t.Fatalf("Canonicalize(%q) error = %v", tt.input, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Canonicalize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocale_CanonicalizeInvalid(t *testing.T) {
+	invalid := []Locale{
+		"",
+		"e",
+		"englishlanguage",
+		"en--US",
+		"en-US-",
+		"123",
+		"en-US-u",
+		"en-US-u-x",
+		"en-a-b",
+		"zh-Hans-CN-x",
+		"en-x-toolongsubtag",
+		"en-US-#",
+		"中文",
+	}
+
+	for _, l := range invalid {
+		t.Run(string(l), func(t *testing.T) {
+			if got, err := l.Canonicalize(); err == nil {
+				t.Errorf("Canonicalize(%q) = %q, want error", l, got)
+			}
+		})
+	}
+}
+
+func TestRegisterLocaleAlias(t *testing.T) {
+	restoreLocaleAliases(t)
+
+	RegisterLocaleAliases(ChineseScriptAliases)
+	RegisterLocaleAlias("sh", "sr-Latn")
+	RegisterLocaleAlias("EN-uk", "en-GB")
+
+	tests := []struct {
+		input    Locale
+		expected Locale
+	}{
+		{"zh-CN", "zh-Hans-CN"},
+		{"zh-cn", "zh-Hans-CN"},
+		{"zh-TW-u-ca-roc", "zh-Hant-TW-u-ca-roc"},
+		{"zh-HK-x-yue", "zh-Hant-HK-x-yue"},
+		{"zh-Hans-CN", "zh-Hans-CN"},
+		{"zh", "zh"},
+		{"en-uk", "en-GB"},
+		// 规范形式带脚本的别名只匹配整个基础部分
+		{"sh", "sr-Latn"},
+		{"sh-RS", "sh-RS"},
+	}
+	for _, tt := range tests {
+		got, err := tt.input.Canonicalize()
+		if err != nil || got != tt.expected {
+			t.Errorf("Canonicalize(%q) = %q, %v, want %q", tt.input, got, err, tt.expected)
+		}
+	}
+
+	t.Run("Invalid alias panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("RegisterLocaleAlias should panic for an invalid tag")
+			}
+		}()
+		RegisterLocaleAlias("en--US", "en-US")
+	})
+}
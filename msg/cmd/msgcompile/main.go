@@ -0,0 +1,47 @@
+// Command msgcompile 将翻译目录编译为 Go 源文件，程序运行时不需要读取翻译文件。
+//
+// 用法：
+//
+//	msgcompile [-dir locales] [-pkg i18n] [-o translations.gen.go]
+//
+// 可以作为 go:generate 步骤运行：
+//
+//	//go:generate go run go-slim.dev/infra/msg/cmd/msgcompile -dir ../locales -pkg i18n
+//
+// 生成的 Register 函数将翻译注册到 xtext.PrinterFactory，详见 xtext.PrinterFactory.WriteGo。
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"go-slim.dev/infra/msg/xtext"
+)
+
+func main() {
+	var (
+		dir = flag.String("dir", "locales", "翻译目录所在的目录")
+		pkg = flag.String("pkg", "i18n", "生成文件的包名")
+		out = flag.String("o", "translations.gen.go", "生成的 Go 源文件")
+	)
+	flag.Parse()
+
+	if _, err := os.Stat(*dir); err != nil {
+		fmt.Fprintln(os.Stderr, "msgcompile:", err)
+		os.Exit(1)
+	}
+
+	factory := xtext.NewPrinterFactory(xtext.BaseDir(*dir))
+	var buf bytes.Buffer
+	if err := factory.WriteGo(&buf, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "msgcompile:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "msgcompile:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: %d locales\n", *out, len(factory.SupportedLocales()))
+}
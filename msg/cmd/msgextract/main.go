@@ -0,0 +1,56 @@
+// Command msgextract 从 Go 源码中提取 msg 消息，生成或更新 gotext JSON 翻译目录。
+//
+// 用法：
+//
+//	msgextract [-dir .] [-out locales] [-source en] -lang en,zh-CN,ja
+//
+// 可以作为 go:generate 步骤运行：
+//
+//	//go:generate go run go-slim.dev/infra/msg/cmd/msgextract -out locales -lang en,zh-CN
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/infra/msg/extract"
+)
+
+func main() {
+	var (
+		dir    = flag.String("dir", ".", "扫描的源码根目录")
+		out    = flag.String("out", "locales", "翻译目录所在的目录")
+		source = flag.String("source", string(msg.English), "源语言")
+		langs  = flag.String("lang", "", "需要生成或更新的语言，以逗号分隔")
+	)
+	flag.Parse()
+
+	var locales []msg.Locale
+	for lang := range strings.SplitSeq(*langs, ",") {
+		if lang = strings.TrimSpace(lang); lang != "" {
+			locales = append(locales, msg.NewLocale(lang))
+		}
+	}
+	if len(locales) == 0 {
+		fmt.Fprintln(os.Stderr, "msgextract: -lang is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	reports, err := extract.Run(extract.Options{
+		Dir:     *dir,
+		Out:     *out,
+		Locales: locales,
+		Source:  msg.NewLocale(*source),
+	})
+	for _, r := range reports {
+		fmt.Printf("%s: %d new, %d changed, %d obsolete\n", r.Path, len(r.New), len(r.Changed), len(r.Obsolete))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "msgextract:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,79 @@
+package msg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Coverage 是一种语言的翻译覆盖率统计。
+type Coverage struct {
+	Locale     Locale // 语言
+	Total      int    // 所有语言的翻译目录中出现的消息总数
+	Translated int    // 已翻译且不需要校对的消息数
+	Missing    int    // 没有译文的消息数
+	Fuzzy      int    // 有译文但标记为需要校对的消息数
+}
+
+// Ratio 返回已翻译消息的比例，没有任何消息时返回 1。
+func (c Coverage) Ratio() float64 {
+	if c.Total == 0 {
+		return 1
+	}
+	return float64(c.Translated) / float64(c.Total)
+}
+
+// String 返回如 "zh-CN 80.0% (8/10, 1 missing, 1 fuzzy)" 的描述
+func (c Coverage) String() string {
+	return fmt.Sprintf("%s %.1f%% (%d/%d, %d missing, %d fuzzy)",
+		c.Locale, c.Ratio()*100, c.Translated, c.Total, c.Missing, c.Fuzzy)
+}
+
+// CoverageReporter 是可以统计翻译覆盖率的 PrinterFactory（可选接口）。
+//
+// xtext.PrinterFactory 实现了此接口，统计其加载的所有翻译目录。
+type CoverageReporter interface {
+	// Coverage 返回每种语言的翻译覆盖率，按语言排序
+	Coverage() []Coverage
+}
+
+// Coverage 返回驱动工厂中每种语言的翻译覆盖率。
+// 驱动工厂没有实现 CoverageReporter 时返回 nil。
+func (m *Manager) Coverage() []Coverage {
+	m.mu.RLock()
+	factory := m.factory
+	m.mu.RUnlock()
+
+	return factory.Coverage()
+}
+
+// Coverage 实现 CoverageReporter 接口，委托给自定义工厂，内置实现没有翻译数据，返回 nil。
+func (f *simplePrinterFactory) Coverage() []Coverage {
+	if reporter, ok := f.loadCustom().(CoverageReporter); ok {
+		return reporter.Coverage()
+	}
+	return nil
+}
+
+// CheckCoverage 检查每种语言的翻译覆盖率是否达到 threshold（0 到 1 之间），
+// 返回描述所有未达标语言的错误，可以在 CI 中阻止覆盖率下降的变更合入。
+//
+// 使用示例：
+//
+//	func TestTranslationCoverage(t *testing.T) {
+//	    factory := xtext.NewPrinterFactory(xtext.BaseDir("../locales"))
+//	    if err := msg.CheckCoverage(factory.Coverage(), 0.95); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+func CheckCoverage(coverage []Coverage, threshold float64) error {
+	var below []string
+	for _, c := range coverage {
+		if c.Ratio() < threshold {
+			below = append(below, c.String())
+		}
+	}
+	if len(below) == 0 {
+		return nil
+	}
+	return fmt.Errorf("translation coverage below %.1f%%: %s", threshold*100, strings.Join(below, "; "))
+}
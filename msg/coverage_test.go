@@ -0,0 +1,46 @@
+package msg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	c := Coverage{Locale: "zh-CN", Total: 10, Translated: 8, Missing: 1, Fuzzy: 1}
+	if r := c.Ratio(); r != 0.8 {
+		t.Errorf("Ratio() = %v, want 0.8", r)
+	}
+	if s := c.String(); s != "zh-CN 80.0% (8/10, 1 missing, 1 fuzzy)" {
+		t.Errorf("String() = %q", s)
+	}
+	if r := (Coverage{Locale: "en"}).Ratio(); r != 1 {
+		t.Errorf("empty Ratio() = %v, want 1", r)
+	}
+}
+
+func TestCheckCoverage(t *testing.T) {
+	coverage := []Coverage{
+		{Locale: "en", Total: 10, Translated: 10},
+		{Locale: "ja", Total: 10, Translated: 5, Missing: 5},
+		{Locale: "zh-CN", Total: 10, Translated: 9, Fuzzy: 1},
+	}
+
+	if err := CheckCoverage(coverage, 0.5); err != nil {
+		t.Errorf("CheckCoverage(0.5) = %v, want nil", err)
+	}
+
+	err := CheckCoverage(coverage, 0.95)
+	if err == nil {
+		t.Fatal("CheckCoverage(0.95) = nil, want error")
+	}
+	if s := err.Error(); !strings.Contains(s, "ja 50.0%") || !strings.Contains(s, "zh-CN 90.0%") || strings.Contains(s, "en ") {
+		t.Errorf("CheckCoverage(0.95) = %q", s)
+	}
+}
+
+func TestManager_CoverageWithoutReporter(t *testing.T) {
+	manager := NewManager(ManagerConfig{LogFunc: func(string) {}})
+	if got := manager.Coverage(); got != nil {
+		t.Errorf("Coverage() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,366 @@
+package msg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DateTimeStyle 表示日期和时间的格式化风格，对应 CLDR 中的 short/medium/long/full。
+type DateTimeStyle int
+
+const (
+	StyleShort  DateTimeStyle = iota // 如 en: "1/2/06"、"3:04 PM"
+	StyleMedium                      // 如 en: "Jan 2, 2006"、"3:04:05 PM"
+	StyleLong                        // 如 en: "January 2, 2006"、"3:04:05 PM MST"
+	StyleFull                        // 如 en: "Monday, January 2, 2006"、"3:04:05 PM MST"
+)
+
+// TimeZones 将 Locale 中 tz 扩展键的值（Unicode 短时区标识，如 "cnsha"）映射为 IANA 时区名称。
+// 可以按需添加其它时区。tz 的值也可以直接是 "utc"。
+var TimeZones = map[string]string{
+	"utc":   "UTC",
+	"cnsha": "Asia/Shanghai",
+	"hkhkg": "Asia/Hong_Kong",
+	"twtpe": "Asia/Taipei",
+	"jptyo": "Asia/Tokyo",
+	"krsel": "Asia/Seoul",
+	"sgsin": "Asia/Singapore",
+	"inccu": "Asia/Kolkata",
+	"gblon": "Europe/London",
+	"frpar": "Europe/Paris",
+	"deber": "Europe/Berlin",
+	"esmad": "Europe/Madrid",
+	"rumow": "Europe/Moscow",
+	"usnyc": "America/New_York",
+	"uschi": "America/Chicago",
+	"usden": "America/Denver",
+	"uslax": "America/Los_Angeles",
+	"brsao": "America/Sao_Paulo",
+	"ausyd": "Australia/Sydney",
+}
+
+// TimeFormatter 按语言环境格式化日期、时间和相对时间。
+//
+// 内置英语、简体中文、繁体中文、日语、韩语、德语、法语和西班牙语的格式，
+// 其它语言使用英语格式。Locale 带有 tz 扩展键时（如 "zh-CN-u-tz-cnsha"），
+// 时间会先转换到该时区再格式化。
+//
+// 使用示例：
+//
+//	f := msg.NewTimeFormatter(msg.Locale("zh-CN-u-tz-cnsha"))
+//	f.FormatDate(t, msg.StyleLong)     // "2006年1月2日"
+//	f.RelativeTime(time.Now().Add(-3 * time.Minute)) // "3分钟前"
+type TimeFormatter struct {
+	locale   Locale
+	data     *dateTimeData
+	location *time.Location
+}
+
+// NewTimeFormatter 创建指定语言环境的 TimeFormatter
+func NewTimeFormatter(locale Locale) *TimeFormatter {
+	return &TimeFormatter{
+		locale:   locale,
+		data:     lookupDateTimeData(locale),
+		location: locale.Location(),
+	}
+}
+
+// Location 返回 Locale 的 tz 扩展键所表示的时区，未设置或无法识别时返回 nil。
+func (l Locale) Location() *time.Location {
+	tz := strings.ToLower(l.Extension("tz"))
+	if tz == "" {
+		return nil
+	}
+	name, ok := TimeZones[tz]
+	if !ok {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// Locale 返回关联的语言环境
+func (f *TimeFormatter) Locale() Locale {
+	return f.locale
+}
+
+// FormatDate 按风格格式化日期
+func (f *TimeFormatter) FormatDate(t time.Time, style DateTimeStyle) string {
+	return f.format(f.in(t), f.data.date[clampStyle(style)])
+}
+
+// FormatTime 按风格格式化时间
+func (f *TimeFormatter) FormatTime(t time.Time, style DateTimeStyle) string {
+	return f.format(f.in(t), f.data.time[clampStyle(style)])
+}
+
+// FormatDateTime 按风格格式化日期和时间
+func (f *TimeFormatter) FormatDateTime(t time.Time, style DateTimeStyle) string {
+	return f.FormatDate(t, style) + f.data.separator + f.FormatTime(t, style)
+}
+
+// RelativeTime 返回 t 相对于当前时间的描述，如 "3 minutes ago"、"in 2 days"、"3分钟前"
+func (f *TimeFormatter) RelativeTime(t time.Time) string {
+	return f.RelativeTimeFrom(t, time.Now())
+}
+
+// RelativeTimeFrom 返回 t 相对于 now 的描述
+//
+// 间隔小于 45 秒时返回 "just now" 等表述，其余按以下阈值选择单位：
+// 45 分钟以内为分钟，22 小时以内为小时，26 天以内为天，11 个月以内为月，其余为年。
+func (f *TimeFormatter) RelativeTimeFrom(t, now time.Time) string {
+	d := now.Sub(t)
+	past := d >= 0
+	if !past {
+		d = -d
+	}
+
+	var unit relativeUnit
+	var n float64
+	switch {
+	case d < 45*time.Second:
+		return f.data.now
+	case d < 45*time.Minute:
+		unit, n = unitMinute, d.Minutes()
+	case d < 22*time.Hour:
+		unit, n = unitHour, d.Hours()
+	case d < 26*24*time.Hour:
+		unit, n = unitDay, d.Hours()/24
+	case d < 320*24*time.Hour:
+		unit, n = unitMonth, d.Hours()/24/30.4375
+	default:
+		unit, n = unitYear, d.Hours()/24/365.25
+	}
+	count := int(math.Max(1, math.Round(n)))
+
+	name := f.data.units[unit][0]
+	if count != 1 {
+		name = f.data.units[unit][1]
+	}
+	if past {
+		return fmt.Sprintf(f.data.past, count, name)
+	}
+	return fmt.Sprintf(f.data.future, count, name)
+}
+
+// in 将时间转换到 Locale 指定的时区
+func (f *TimeFormatter) in(t time.Time) time.Time {
+	if f.location != nil {
+		return t.In(f.location)
+	}
+	return t
+}
+
+// format 使用布局格式化时间，并将占位符替换为本地化的名称
+func (f *TimeFormatter) format(t time.Time, layout string) string {
+	s := t.Format(layout)
+	if !strings.ContainsAny(s, "\x01\x02\x03\x04") {
+		return s
+	}
+	period := 0
+	if t.Hour() >= 12 {
+		period = 1
+	}
+	return strings.NewReplacer(
+		"\x01", f.data.weekdays[t.Weekday()],
+		"\x02", f.data.months[t.Month()-1],
+		"\x03", f.data.periods[period],
+		"\x04", f.data.shortMonths[t.Month()-1],
+	).Replace(s)
+}
+
+func clampStyle(style DateTimeStyle) DateTimeStyle {
+	return max(StyleShort, min(style, StyleFull))
+}
+
+// 全局便利函数：使用上下文中的语言
+
+// FormatDate 使用上下文中的语言按风格格式化日期
+func FormatDate(ctx context.Context, t time.Time, style DateTimeStyle) string {
+	return timeFormatter(ctx).FormatDate(t, style)
+}
+
+// FormatTime 使用上下文中的语言按风格格式化时间
+func FormatTime(ctx context.Context, t time.Time, style DateTimeStyle) string {
+	return timeFormatter(ctx).FormatTime(t, style)
+}
+
+// FormatDateTime 使用上下文中的语言按风格格式化日期和时间
+func FormatDateTime(ctx context.Context, t time.Time, style DateTimeStyle) string {
+	return timeFormatter(ctx).FormatDateTime(t, style)
+}
+
+// RelativeTime 使用上下文中的语言描述 t 相对于当前时间的间隔
+func RelativeTime(ctx context.Context, t time.Time) string {
+	return timeFormatter(ctx).RelativeTime(t)
+}
+
+// timeFormatter 返回上下文语言的 TimeFormatter
+func timeFormatter(ctx context.Context) *TimeFormatter {
+	return NewTimeFormatter(GetDefaultManager().LocaleFromContext(ctx))
+}
+
+// relativeUnit 相对时间的单位
+type relativeUnit int
+
+const (
+	unitMinute relativeUnit = iota
+	unitHour
+	unitDay
+	unitMonth
+	unitYear
+)
+
+// dateTimeData 某种语言的日期时间格式数据。
+//
+// 布局使用 Go 的时间布局，其中 \x01 表示星期名称，\x02 表示月份全称，
+// \x03 表示上午/下午，\x04 表示月份简称，在格式化后替换为本地化的名称。
+type dateTimeData struct {
+	date        [4]string // 按风格排列的日期布局
+	time        [4]string // 按风格排列的时间布局
+	separator   string    // 日期和时间之间的分隔符
+	weekdays    [7]string // 从星期日开始
+	months      [12]string
+	shortMonths [12]string
+	periods     [2]string // 上午、下午
+
+	now    string       // 刚刚
+	past   string       // 过去时间的格式，参数为数量和单位
+	future string       // 将来时间的格式，参数为数量和单位
+	units  [5][2]string // 按 relativeUnit 排列的单位，分别为单数和复数形式
+}
+
+// lookupDateTimeData 返回语言环境对应的格式数据，不支持的语言使用英语
+func lookupDateTimeData(locale Locale) *dateTimeData {
+	if data, ok := dateTimeTables[tableLanguage(locale)]; ok {
+		return data
+	}
+	return dateTimeTables["en"]
+}
+
+// tableLanguage 返回查找内置格式数据使用的语言，中文按书写系统或地区区分为 zh-Hans 和 zh-Hant
+func tableLanguage(locale Locale) string {
+	lang := locale.Language()
+	if lang != "zh" {
+		return lang
+	}
+	switch locale.Script() {
+	case "Hant":
+		return "zh-Hant"
+	case "Hans":
+		return "zh-Hans"
+	}
+	switch locale.Region() {
+	case "TW", "HK", "MO":
+		return "zh-Hant"
+	}
+	return "zh-Hans"
+}
+
+var (
+	cjkWeekdays = [7]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+	cjkMonths   = [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"}
+)
+
+var dateTimeTables = map[string]*dateTimeData{
+	"en": {
+		date:      [4]string{"1/2/06", "Jan 2, 2006", "January 2, 2006", "Monday, January 2, 2006"},
+		time:      [4]string{"3:04 PM", "3:04:05 PM", "3:04:05 PM MST", "3:04:05 PM MST"},
+		separator: ", ",
+		now:       "just now",
+		past:      "%d %s ago",
+		future:    "in %d %s",
+		units:     [5][2]string{{"minute", "minutes"}, {"hour", "hours"}, {"day", "days"}, {"month", "months"}, {"year", "years"}},
+	},
+	"zh-Hans": {
+		date:        [4]string{"2006/1/2", "2006年1月2日", "2006年1月2日", "2006年1月2日\x01"},
+		time:        [4]string{"15:04", "15:04:05", "15:04:05 MST", "15:04:05 MST"},
+		separator:   " ",
+		weekdays:    cjkWeekdays,
+		months:      cjkMonths,
+		shortMonths: cjkMonths,
+		now:         "刚刚",
+		past:        "%d%s前",
+		future:      "%d%s后",
+		units:       [5][2]string{{"分钟", "分钟"}, {"小时", "小时"}, {"天", "天"}, {"个月", "个月"}, {"年", "年"}},
+	},
+	"zh-Hant": {
+		date:        [4]string{"2006/1/2", "2006年1月2日", "2006年1月2日", "2006年1月2日 \x01"},
+		time:        [4]string{"15:04", "15:04:05", "15:04:05 MST", "15:04:05 MST"},
+		separator:   " ",
+		weekdays:    cjkWeekdays,
+		months:      cjkMonths,
+		shortMonths: cjkMonths,
+		now:         "剛剛",
+		past:        "%d%s前",
+		future:      "%d%s後",
+		units:       [5][2]string{{"分鐘", "分鐘"}, {"小時", "小時"}, {"天", "天"}, {"個月", "個月"}, {"年", "年"}},
+	},
+	"ja": {
+		date:        [4]string{"2006/01/02", "2006/01/02", "2006年1月2日", "2006年1月2日\x01"},
+		time:        [4]string{"15:04", "15:04:05", "15:04:05 MST", "15:04:05 MST"},
+		separator:   " ",
+		weekdays:    [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		months:      cjkMonths,
+		shortMonths: cjkMonths,
+		now:         "たった今",
+		past:        "%d%s前",
+		future:      "%d%s後",
+		units:       [5][2]string{{"分", "分"}, {"時間", "時間"}, {"日", "日"}, {"か月", "か月"}, {"年", "年"}},
+	},
+	"ko": {
+		date:        [4]string{"06. 1. 2.", "2006. 1. 2.", "2006년 1월 2일", "2006년 1월 2일 \x01"},
+		time:        [4]string{"\x03 3:04", "\x03 3:04:05", "\x03 3시 4분 5초 MST", "\x03 3시 4분 5초 MST"},
+		separator:   " ",
+		weekdays:    [7]string{"일요일", "월요일", "화요일", "수요일", "목요일", "금요일", "토요일"},
+		months:      [12]string{"1월", "2월", "3월", "4월", "5월", "6월", "7월", "8월", "9월", "10월", "11월", "12월"},
+		shortMonths: [12]string{"1월", "2월", "3월", "4월", "5월", "6월", "7월", "8월", "9월", "10월", "11월", "12월"},
+		periods:     [2]string{"오전", "오후"},
+		now:         "방금",
+		past:        "%d%s 전",
+		future:      "%d%s 후",
+		units:       [5][2]string{{"분", "분"}, {"시간", "시간"}, {"일", "일"}, {"개월", "개월"}, {"년", "년"}},
+	},
+	"de": {
+		date:        [4]string{"02.01.06", "02.01.2006", "2. \x02 2006", "\x01, 2. \x02 2006"},
+		time:        [4]string{"15:04", "15:04:05", "15:04:05 MST", "15:04:05 MST"},
+		separator:   ", ",
+		weekdays:    [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		now:         "gerade eben",
+		past:        "vor %d %s",
+		future:      "in %d %s",
+		units:       [5][2]string{{"Minute", "Minuten"}, {"Stunde", "Stunden"}, {"Tag", "Tagen"}, {"Monat", "Monaten"}, {"Jahr", "Jahren"}},
+	},
+	"fr": {
+		date:        [4]string{"02/01/2006", "2 \x04 2006", "2 \x02 2006", "\x01 2 \x02 2006"},
+		time:        [4]string{"15:04", "15:04:05", "15:04:05 MST", "15:04:05 MST"},
+		separator:   " ",
+		weekdays:    [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		now:         "à l’instant",
+		past:        "il y a %d %s",
+		future:      "dans %d %s",
+		units:       [5][2]string{{"minute", "minutes"}, {"heure", "heures"}, {"jour", "jours"}, {"mois", "mois"}, {"an", "ans"}},
+	},
+	"es": {
+		date:        [4]string{"2/1/06", "2 \x04 2006", "2 de \x02 de 2006", "\x01, 2 de \x02 de 2006"},
+		time:        [4]string{"15:04", "15:04:05", "15:04:05 MST", "15:04:05 MST"},
+		separator:   ", ",
+		weekdays:    [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		now:         "ahora mismo",
+		past:        "hace %d %s",
+		future:      "dentro de %d %s",
+		units:       [5][2]string{{"minuto", "minutos"}, {"hora", "horas"}, {"día", "días"}, {"mes", "meses"}, {"año", "años"}},
+	},
+}
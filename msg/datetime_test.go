@@ -0,0 +1,133 @@
+package msg
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeFormatter_FormatDate(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+
+	tests := []struct {
+		locale Locale
+		style  DateTimeStyle
+		want   string
+	}{
+		{"en-US", StyleShort, "3/5/24"},
+		{"en-US", StyleMedium, "Mar 5, 2024"},
+		{"en-US", StyleLong, "March 5, 2024"},
+		{"en-US", StyleFull, "Tuesday, March 5, 2024"},
+		{"zh-CN", StyleShort, "2024/3/5"},
+		{"zh-CN", StyleLong, "2024年3月5日"},
+		{"zh-CN", StyleFull, "2024年3月5日星期二"},
+		{"zh-TW", StyleFull, "2024年3月5日 星期二"},
+		{"ja-JP", StyleFull, "2024年3月5日火曜日"},
+		{"ko-KR", StyleLong, "2024년 3월 5일"},
+		{"de-DE", StyleMedium, "05.03.2024"},
+		{"de-DE", StyleFull, "Dienstag, 5. März 2024"},
+		{"fr-FR", StyleMedium, "5 mars 2024"},
+		{"fr-FR", StyleFull, "mardi 5 mars 2024"},
+		{"es-ES", StyleLong, "5 de marzo de 2024"},
+		{"pt-BR", StyleMedium, "Mar 5, 2024"},
+	}
+	for _, tt := range tests {
+		f := NewTimeFormatter(tt.locale)
+		if got := f.FormatDate(date, tt.style); got != tt.want {
+			t.Errorf("%s FormatDate(style %d) = %q, want %q", tt.locale, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestTimeFormatter_FormatTime(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+
+	tests := []struct {
+		locale Locale
+		style  DateTimeStyle
+		want   string
+	}{
+		{"en-US", StyleShort, "2:07 PM"},
+		{"en-US", StyleMedium, "2:07:09 PM"},
+		{"en-US", StyleLong, "2:07:09 PM UTC"},
+		{"zh-CN", StyleShort, "14:07"},
+		{"ko-KR", StyleShort, "오후 2:07"},
+		{"de-DE", StyleMedium, "14:07:09"},
+	}
+	for _, tt := range tests {
+		f := NewTimeFormatter(tt.locale)
+		if got := f.FormatTime(date, tt.style); got != tt.want {
+			t.Errorf("%s FormatTime(style %d) = %q, want %q", tt.locale, tt.style, got, tt.want)
+		}
+	}
+
+	if got, want := NewTimeFormatter("en-US").FormatDateTime(date, StyleMedium), "Mar 5, 2024, 2:07:09 PM"; got != want {
+		t.Errorf("FormatDateTime = %q, want %q", got, want)
+	}
+}
+
+func TestTimeFormatter_TimeZone(t *testing.T) {
+	if _, err := time.LoadLocation("Asia/Shanghai"); err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	date := time.Date(2024, time.March, 5, 20, 30, 0, 0, time.UTC)
+	f := NewTimeFormatter("zh-CN-u-tz-cnsha")
+	if got, want := f.FormatDateTime(date, StyleShort), "2024/3/6 04:30"; got != want {
+		t.Errorf("FormatDateTime with tz = %q, want %q", got, want)
+	}
+
+	if loc := Locale("en-US").Location(); loc != nil {
+		t.Errorf("Location() without tz = %v, want nil", loc)
+	}
+	if loc := Locale("en-US-u-tz-unknown").Location(); loc != nil {
+		t.Errorf("Location() with unknown tz = %v, want nil", loc)
+	}
+	if loc := Locale("en-US-u-tz-usnyc").Location(); loc == nil || loc.String() != "America/New_York" {
+		t.Errorf("Location() = %v, want America/New_York", loc)
+	}
+}
+
+func TestTimeFormatter_RelativeTime(t *testing.T) {
+	now := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		locale Locale
+		offset time.Duration
+		want   string
+	}{
+		{"en", -10 * time.Second, "just now"},
+		{"en", -3 * time.Minute, "3 minutes ago"},
+		{"en", -1 * time.Minute, "1 minute ago"},
+		{"en", 2 * time.Hour, "in 2 hours"},
+		{"en", -24 * time.Hour, "1 day ago"},
+		{"en", -60 * 24 * time.Hour, "2 months ago"},
+		{"en", -800 * 24 * time.Hour, "2 years ago"},
+		{"zh-CN", -3 * time.Minute, "3分钟前"},
+		{"zh-CN", 3 * 24 * time.Hour, "3天后"},
+		{"zh-CN", -5 * time.Second, "刚刚"},
+		{"zh-Hant", -3 * time.Hour, "3小時前"},
+		{"ja", -3 * time.Minute, "3分前"},
+		{"de", -3 * 24 * time.Hour, "vor 3 Tagen"},
+		{"fr", 5 * time.Minute, "dans 5 minutes"},
+		{"es", -2 * time.Hour, "hace 2 horas"},
+	}
+	for _, tt := range tests {
+		f := NewTimeFormatter(tt.locale)
+		if got := f.RelativeTimeFrom(now.Add(tt.offset), now); got != tt.want {
+			t.Errorf("%s RelativeTimeFrom(%v) = %q, want %q", tt.locale, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTime_Context(t *testing.T) {
+	ctx := WithLocaleContext(context.Background(), "zh-CN")
+	if got, want := RelativeTime(ctx, time.Now().Add(-3*time.Minute)), "3分钟前"; got != want {
+		t.Errorf("RelativeTime = %q, want %q", got, want)
+	}
+
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	if got, want := FormatDate(ctx, date, StyleLong), "2024年3月5日"; got != want {
+		t.Errorf("FormatDate = %q, want %q", got, want)
+	}
+}
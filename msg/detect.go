@@ -0,0 +1,134 @@
+package msg
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Detector 从请求中检测用户偏好的语言，按优先顺序返回候选语言，未检测到时返回空切片。
+//
+// 除了内置的查询参数、Cookie 和 Accept-Language 检测器外，
+// 任何符合该签名的函数都可以作为检测器，如从用户资料中读取语言设置：
+//
+//	profile := func(r *http.Request) []msg.Locale {
+//	    if user, ok := auth.UserFromContext(r.Context()); ok && user.Locale != "" {
+//	        return []msg.Locale{msg.NewLocale(user.Locale)}
+//	    }
+//	    return nil
+//	}
+type Detector func(r *http.Request) []Locale
+
+// DefaultDetectors 是未指定检测器时使用的检测链，按以下优先级检测语言：
+// 查询参数 lang、名为 lang 的 Cookie、Accept-Language 请求头。
+var DefaultDetectors = []Detector{
+	QueryDetector("lang"),
+	CookieDetector("lang"),
+	AcceptLanguageDetector(),
+}
+
+// QueryDetector 返回从指定查询参数（如 ?lang=zh-CN）中检测语言的检测器。
+func QueryDetector(param string) Detector {
+	return func(r *http.Request) []Locale {
+		return detected(r.URL.Query().Get(param))
+	}
+}
+
+// CookieDetector 返回从指定 Cookie 中检测语言的检测器。
+func CookieDetector(name string) Detector {
+	return func(r *http.Request) []Locale {
+		varyOn(r, "Cookie")
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return nil
+		}
+		return detected(cookie.Value)
+	}
+}
+
+// AcceptLanguageDetector 返回从 Accept-Language 请求头中检测语言的检测器，
+// 候选语言按质量值从高到低排列。
+func AcceptLanguageDetector() Detector {
+	return func(r *http.Request) []Locale {
+		return acceptedLocales(r.Header.Get("Accept-Language"))
+	}
+}
+
+// varyKey 是请求上下文中收集检测器所读取请求头的键
+type varyKey struct{}
+
+// withVary 返回收集检测器所读取请求头的上下文，供 Negotiate 报告这些请求头
+func withVary(ctx context.Context, headers *[]string) context.Context {
+	return context.WithValue(ctx, varyKey{}, headers)
+}
+
+// varyOn 记录检测器读取了请求头 name，使检测结果依赖于它
+func varyOn(r *http.Request, name string) {
+	if headers, ok := r.Context().Value(varyKey{}).(*[]string); ok && !slices.Contains(*headers, name) {
+		*headers = append(*headers, name)
+	}
+}
+
+// detected 将检测到的语言标签转换为候选列表
+func detected(tag string) []Locale {
+	if tag = strings.TrimSpace(tag); tag == "" {
+		return nil
+	}
+	return []Locale{CanonicalLocale(tag)}
+}
+
+// DetectLocale 依次执行检测器，返回第一个能与支持的语言匹配的检测结果。
+//
+// 支持的语言由 Manager 的打印机工厂决定；所有检测器都没有结果时返回 Manager 的当前语言。
+// 未指定检测器时使用 DefaultDetectors。
+//
+// 使用示例：
+//
+//	locale := manager.DetectLocale(r, msg.QueryDetector("locale"), profile, msg.AcceptLanguageDetector())
+//	ctx := msg.WithLocaleContext(r.Context(), locale)
+func (m *Manager) DetectLocale(r *http.Request, detectors ...Detector) Locale {
+	locale, _ := m.detectLocale(r, detectors, nil)
+	return locale
+}
+
+// Negotiation 是 Manager.Negotiate 的语言协商结果。
+type Negotiation struct {
+	Locale   Locale   // 选中的语言
+	Detected bool     // 语言是否由检测器检测得到，为 false 时 Locale 是 Manager 的当前语言
+	Vary     []string // 除 Accept-Language 外，检测时读取的请求头（如 Cookie），响应应在 Vary 中声明
+}
+
+// Negotiate 为 HTTP 中间件协商请求的语言：依次执行检测器（未指定时使用 DefaultDetectors），
+// 返回第一个能与 supported 匹配的检测结果，都无法匹配时返回 Manager 的当前语言。
+// supported 为 nil 时使用打印机工厂支持的语言。
+//
+// 与 DetectLocale 不同，结果还报告了语言是否由检测器检测得到，以及响应需要在 Vary 中声明的请求头，
+// 供各 Web 框架的中间件（如 msgslim.Middleware）存入上下文、写回 Cookie 和设置响应头。
+func (m *Manager) Negotiate(r *http.Request, supported LocaleSet, detectors ...Detector) Negotiation {
+	var vary []string
+	locale, detected := m.detectLocale(r.WithContext(withVary(r.Context(), &vary)), detectors, supported)
+	return Negotiation{Locale: locale, Detected: detected, Vary: vary}
+}
+
+// detectLocale 使用检测器在 supported 中选择语言，supported 为 nil 时使用打印机工厂支持的语言；
+// 第二个返回值报告语言是否由检测器检测得到，为 false 时返回的是 Manager 的当前语言
+func (m *Manager) detectLocale(r *http.Request, detectors []Detector, supported LocaleSet) (Locale, bool) {
+	if len(detectors) == 0 {
+		detectors = DefaultDetectors
+	}
+	if supported == nil {
+		supported = m.factory.SupportedLocales()
+	}
+	for _, detect := range detectors {
+		if locale := MatchLocale(detect(r), supported, ""); locale != "" {
+			return locale, true
+		}
+	}
+	return m.GetLocale(), false
+}
+
+// DetectLocale 使用全局默认 Manager 检测请求的语言，详见 Manager.DetectLocale。
+func DetectLocale(r *http.Request, detectors ...Detector) Locale {
+	return GetDefaultManager().DetectLocale(r, detectors...)
+}
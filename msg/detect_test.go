@@ -0,0 +1,83 @@
+package msg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestDetectLocale(t *testing.T) {
+	manager := NewManager(ManagerConfig{Locale: English})
+
+	newRequest := func(target, cookie, acceptLanguage string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "lang", Value: cookie})
+		}
+		if acceptLanguage != "" {
+			r.Header.Set("Accept-Language", acceptLanguage)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		request  *http.Request
+		expected Locale
+	}{
+		{"Query has the highest priority", newRequest("/?lang=zh_hans", "fr", "de"), ChineseSimplified},
+		{"Cookie before Accept-Language", newRequest("/", "fr", "de"), French},
+		{"Accept-Language", newRequest("/", "", "de;q=0.5, ja"), Japanese},
+		{"Manager locale when nothing is detected", newRequest("/", "", ""), English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := manager.DetectLocale(tt.request); got != tt.expected {
+				t.Errorf("DetectLocale() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectLocaleSupported(t *testing.T) {
+	manager := NewManager(ManagerConfig{Locale: English})
+	profile := func(r *http.Request) []Locale { return []Locale{"ko-KR"} }
+
+	r := httptest.NewRequest(http.MethodGet, "/?lang=it", nil)
+	r.Header.Set("Accept-Language", "it, zh-CN;q=0.8")
+
+	// Unsupported detections fall through to the next detector
+	supported := LocaleSet{English, Locale("zh-CN")}
+	got, _ := manager.detectLocale(r, []Detector{QueryDetector("lang"), AcceptLanguageDetector()}, supported)
+	if got != Locale("zh-CN") {
+		t.Errorf("detectLocale() = %q, want zh-CN", got)
+	}
+
+	// Custom detectors take part in the chain
+	if got := manager.DetectLocale(r, profile, QueryDetector("lang")); got != KoreanKR {
+		t.Errorf("DetectLocale() = %q, want %q", got, KoreanKR)
+	}
+}
+
+func TestManagerNegotiate(t *testing.T) {
+	manager := NewManager(ManagerConfig{Locale: English})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "it, zh-CN;q=0.8")
+	got := manager.Negotiate(r, LocaleSet{English, Locale("zh-CN")})
+	if got.Locale != Locale("zh-CN") || !got.Detected {
+		t.Errorf("Negotiate() = %+v, want the detected zh-CN", got)
+	}
+	// The cookie detector is consulted when the query has no locale
+	if !slices.Equal(got.Vary, []string{"Cookie"}) {
+		t.Errorf("Vary = %v, want Cookie", got.Vary)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?lang=fr", nil)
+	got = manager.Negotiate(r, LocaleSet{English}, QueryDetector("lang"))
+	if got.Locale != English || got.Detected || len(got.Vary) != 0 {
+		t.Errorf("Negotiate() = %+v, want the undetected manager locale", got)
+	}
+}
@@ -0,0 +1,84 @@
+package msg
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	_ encoding.TextMarshaler   = Locale("")
+	_ encoding.TextUnmarshaler = (*Locale)(nil)
+	_ json.Marshaler           = Locale("")
+	_ json.Unmarshaler         = (*Locale)(nil)
+	_ sql.Scanner              = (*Locale)(nil)
+	_ driver.Valuer            = Locale("")
+)
+
+// MarshalText 实现 encoding.TextMarshaler 接口，按原样输出语言标签
+func (l Locale) MarshalText() ([]byte, error) {
+	return []byte(l), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler 接口。
+//
+// 解码时使用 Canonicalize 规范化语言标签，如 "zh_cn" → "zh-CN"、"iw" → "he"，
+// 格式无效时返回错误；空文本解码为空的 Locale。
+// YAML、TOML 等支持 encoding.TextUnmarshaler 的解码器同样使用该规则。
+func (l *Locale) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*l = ""
+		return nil
+	}
+	c, err := Locale(text).Canonicalize()
+	if err != nil {
+		return err
+	}
+	*l = c
+	return nil
+}
+
+// MarshalJSON 实现 json.Marshaler 接口，输出 JSON 字符串
+func (l Locale) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(l))
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口，规则与 UnmarshalText 相同，null 不修改 Locale。
+//
+// 示例：
+//
+//	var req struct {
+//	    Locale msg.Locale `json:"locale"`
+//	}
+//	err := json.Unmarshal([]byte(`{"locale": "zh_hans_cn"}`), &req) // req.Locale == "zh-Hans-CN"
+func (l *Locale) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid locale %s: %w", data, err)
+	}
+	return l.UnmarshalText([]byte(s))
+}
+
+// Scan 实现 sql.Scanner 接口，从数据库读取时按 UnmarshalText 的规则规范化，NULL 读取为空的 Locale
+func (l *Locale) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = ""
+		return nil
+	case string:
+		return l.UnmarshalText([]byte(v))
+	case []byte:
+		return l.UnmarshalText(v)
+	}
+	return fmt.Errorf("invalid locale: cannot scan %T", src)
+}
+
+// Value 实现 driver.Valuer 接口，以字符串写入数据库，空的 Locale 写入空字符串而不是 NULL
+func (l Locale) Value() (driver.Value, error) {
+	return string(l), nil
+}
@@ -0,0 +1,81 @@
+package msg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLocale_JSON(t *testing.T) {
+	type payload struct {
+		Locale   Locale            `json:"locale"`
+		Fallback *Locale           `json:"fallback,omitempty"`
+		Titles   map[Locale]string `json:"titles,omitempty"`
+	}
+
+	var p payload
+	err := json.Unmarshal([]byte(`{"locale": "zh_hans_cn", "fallback": "iw", "titles": {"EN-us": "Hello"}}`), &p)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if p.Locale != "zh-Hans-CN" || p.Fallback == nil || *p.Fallback != "he" || p.Titles["en-US"] != "Hello" {
+		t.Errorf("Unmarshal() = %+v, want canonical locales", p)
+	}
+
+	data, err := json.Marshal(payload{Locale: "en-US"})
+	if err != nil || string(data) != `{"locale":"en-US"}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+
+	p = payload{Locale: "fr"}
+	if err := json.Unmarshal([]byte(`{"locale": null}`), &p); err != nil || p.Locale != "fr" {
+		t.Errorf("Unmarshal(null) = %q, %v; want the locale unchanged", p.Locale, err)
+	}
+	if err := json.Unmarshal([]byte(`{"locale": ""}`), &p); err != nil || p.Locale != "" {
+		t.Errorf("Unmarshal(\"\") = %q, %v; want an empty locale", p.Locale, err)
+	}
+	for _, input := range []string{`{"locale": "en--US"}`, `{"locale": 42}`} {
+		if err := json.Unmarshal([]byte(input), &p); err == nil {
+			t.Errorf("Unmarshal(%s) should return an error", input)
+		}
+	}
+}
+
+func TestLocale_SQL(t *testing.T) {
+	tests := []struct {
+		src     any
+		want    Locale
+		wantErr bool
+	}{
+		{nil, "", false},
+		{"en_us", "en-US", false},
+		{[]byte("in-ID"), "id-ID", false},
+		{"", "", false},
+		{"not a locale", "", true},
+		{42, "", true},
+	}
+	for _, tt := range tests {
+		l := Locale("de")
+		err := l.Scan(tt.src)
+		if (err != nil) != tt.wantErr || !tt.wantErr && l != tt.want {
+			t.Errorf("Scan(%v) = %q, %v; want %q", tt.src, l, err, tt.want)
+		}
+	}
+
+	if v, err := Locale("zh-CN").Value(); err != nil || v != "zh-CN" {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+	if v, _ := Locale("").Value(); v != "" {
+		t.Errorf("Value() = %v, want an empty string", v)
+	}
+}
+
+func TestLocale_Text(t *testing.T) {
+	text, err := Locale("en-GB").MarshalText()
+	if err != nil || string(text) != "en-GB" {
+		t.Errorf("MarshalText() = %s, %v", text, err)
+	}
+	var l Locale
+	if err := l.UnmarshalText([]byte("PT_br")); err != nil || l != "pt-BR" {
+		t.Errorf("UnmarshalText() = %q, %v", l, err)
+	}
+}
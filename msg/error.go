@@ -0,0 +1,81 @@
+package msg
+
+import "fmt"
+
+// Localizable 是可以按 Printer 的语言输出文本的值，如 Error 创建的错误。
+//
+// 作为参数传给 Printer 的格式化方法时，会先替换为 Localize 返回的文本。
+type Localizable interface {
+	Localize(p Printer) string
+}
+
+// LocalizedError 是携带消息标识的错误，通过 Printer 输出时使用译文。
+type LocalizedError struct {
+	ID   string // 消息标识，同时作为格式字符串
+	Args []any  // 格式化参数
+}
+
+// Error 创建携带消息标识的错误。
+//
+// Error() 返回未翻译的文本，适用于日志；作为参数传给 Printer 的格式化方法，
+// 或调用 Localize 时输出 Printer 所用语言的译文，参数中的 Localizable 值同样会被翻译。
+// 参数中的 error 可以通过 errors.Is 和 errors.As 访问。
+// 返回给 rsp 时，响应的 msg 字段自动使用请求语言的译文。
+//
+// 使用示例：
+//
+//	var ErrQuotaExceeded = msg.Error("Quota exceeded")
+//
+//	func findUser(name string) error {
+//	    return msg.Error("User %s not found", name)
+//	}
+//
+//	p.Sprintf("Failed: %v", err) // "操作失败：未找到用户 alice"
+func Error(id string, args ...any) *LocalizedError {
+	return &LocalizedError{ID: id, Args: args}
+}
+
+// Error 实现 error 接口，返回未翻译的文本
+func (e *LocalizedError) Error() string {
+	if len(e.Args) == 0 {
+		return e.ID
+	}
+	return fmt.Sprintf(e.ID, e.Args...)
+}
+
+// Localize 实现 Localizable 接口，返回 p 的语言的译文
+func (e *LocalizedError) Localize(p Printer) string {
+	return p.Sprintf(e.ID, e.Args...)
+}
+
+// Unwrap 返回参数中的错误
+func (e *LocalizedError) Unwrap() []error {
+	var errs []error
+	for _, arg := range e.Args {
+		if err, ok := arg.(error); ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// LocalizeArgs 返回将 args 中的 Localizable 替换为 p 的语言的文本后的参数，
+// 没有 Localizable 时直接返回 args。Printer 的实现在格式化前调用，使错误等值输出译文。
+func LocalizeArgs(p Printer, args []any) []any {
+	var localized []any
+	for i, arg := range args {
+		l, ok := arg.(Localizable)
+		if !ok {
+			continue
+		}
+		if localized == nil {
+			localized = make([]any, len(args))
+			copy(localized, args)
+		}
+		localized[i] = l.Localize(p)
+	}
+	if localized == nil {
+		return args
+	}
+	return localized
+}
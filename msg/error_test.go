@@ -0,0 +1,64 @@
+package msg
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	factory := NewPrinterFactory()
+	if err := factory.(MessageSetter).SetMessage(Chinese, "User %s not found", "未找到用户 %s"); err != nil {
+		t.Fatalf("SetMessage() error = %v", err)
+	}
+	_ = factory.(MessageSetter).SetMessage(Chinese, "Failed: %v", "操作失败：%v")
+	p, _ := factory.CreatePrinter(Chinese)
+
+	err := Error("User %s not found", "alice")
+	if got, want := err.Error(), "User alice not found"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got, want := err.Localize(p), "未找到用户 alice"; got != want {
+		t.Errorf("Localize() = %q, want %q", got, want)
+	}
+	if got, want := p.Sprintf("Failed: %v", err), "操作失败：未找到用户 alice"; got != want {
+		t.Errorf("Sprintf() = %q, want %q", got, want)
+	}
+	if got, want := p.Sprint(err), "未找到用户 alice"; got != want {
+		t.Errorf("Sprint() = %q, want %q", got, want)
+	}
+
+	// 嵌套的错误同样被翻译
+	wrapped := Error("Failed: %v", err)
+	if got, want := wrapped.Localize(p), "操作失败：未找到用户 alice"; got != want {
+		t.Errorf("Localize() nested = %q, want %q", got, want)
+	}
+	var target *LocalizedError
+	if !errors.As(wrapped, &target) || target != wrapped {
+		t.Error("errors.As() did not find the LocalizedError")
+	}
+
+	if got := Error("Quota exceeded").Error(); got != "Quota exceeded" {
+		t.Errorf("Error() without args = %q, want %q", got, "Quota exceeded")
+	}
+	if !errors.Is(Error("Read failed: %v", io.EOF), io.EOF) {
+		t.Error("errors.Is() = false, want true for error argument")
+	}
+}
+
+func TestLocalizeArgs(t *testing.T) {
+	p := NewPrinter(English)
+	args := []any{"a", 1}
+	if got := LocalizeArgs(p, args); &got[0] != &args[0] {
+		t.Error("LocalizeArgs() copied args without Localizable values")
+	}
+
+	args = []any{"a", Error("b")}
+	got := LocalizeArgs(p, args)
+	if got[1] != "b" {
+		t.Errorf("LocalizeArgs()[1] = %v, want %q", got[1], "b")
+	}
+	if _, ok := args[1].(*LocalizedError); !ok {
+		t.Error("LocalizeArgs() modified the original args")
+	}
+}
@@ -0,0 +1,309 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go-slim.dev/infra/msg"
+)
+
+// positionSep 分隔 Position 中的多个位置
+const positionSep = ", "
+
+// CatalogMessage 是 gotext JSON 翻译目录中的一条消息。
+//
+// 除 gotext 的标准字段外，Obsolete 标记源码中已不再使用的消息，
+// xtext.JSONLoader 会忽略不认识的字段。
+type CatalogMessage struct {
+	ID                string `json:"id"`
+	Message           string `json:"message"`
+	Translation       string `json:"translation"`
+	TranslatorComment string `json:"translatorComment,omitempty"`
+	Position          string `json:"position,omitempty"` // 与 gotext 相同为字符串，多个位置以 ", " 分隔
+	Fuzzy             bool   `json:"fuzzy,omitempty"`
+	Obsolete          bool   `json:"obsolete,omitempty"`
+
+	// Extra 保存本结构未定义的字段（如 gotext 的 comment、placeholders），读写时原样保留
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// catalogMessage 与 CatalogMessage 字段相同，但不使用自定义的编解码
+type catalogMessage CatalogMessage
+
+// UnmarshalJSON 实现 json.Unmarshaler，未定义的字段保存到 Extra。
+func (m *CatalogMessage) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*catalogMessage)(m)); err != nil {
+		return err
+	}
+	extra, err := extraFields(data, reflect.TypeFor[CatalogMessage]())
+	m.Extra = extra
+	return err
+}
+
+// MarshalJSON 实现 json.Marshaler，Extra 中的字段追加在标准字段之后。
+func (m CatalogMessage) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(catalogMessage(m), m.Extra)
+}
+
+// Catalog 是一种语言的 gotext JSON 翻译目录。
+type Catalog struct {
+	Language msg.Locale       `json:"language"`
+	Messages []CatalogMessage `json:"messages"`
+
+	// Extra 保存本结构未定义的字段，读写时原样保留
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// catalog 与 Catalog 字段相同，但不使用自定义的编解码
+type catalog Catalog
+
+// UnmarshalJSON 实现 json.Unmarshaler，未定义的字段保存到 Extra。
+func (c *Catalog) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*catalog)(c)); err != nil {
+		return err
+	}
+	extra, err := extraFields(data, reflect.TypeFor[Catalog]())
+	c.Extra = extra
+	return err
+}
+
+// MarshalJSON 实现 json.Marshaler，Extra 中的字段追加在标准字段之后。
+func (c Catalog) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(catalog(c), c.Extra)
+}
+
+// extraFields 返回 JSON 对象 data 中不对应 t 的任何字段的成员，没有时返回 nil
+func extraFields(data []byte, t reflect.Type) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		delete(fields, name)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// marshalWithExtra 编码 v，并将 extra 中的字段按名称顺序追加到对象末尾
+func marshalWithExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	b := bytes.TrimSuffix(bytes.TrimSpace(buf.Bytes()), []byte("}"))
+	for _, name := range slices.Sorted(maps.Keys(extra)) {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 1 {
+			b = append(b, ',')
+		}
+		b = append(append(append(b, key...), ':'), extra[name]...)
+	}
+	return append(b, '}'), nil
+}
+
+// joinPositions 将多个位置合并为 Position 字段
+func joinPositions(positions []string) string {
+	return strings.Join(positions, positionSep)
+}
+
+// splitPositions 将 Position 字段拆分为位置列表
+func splitPositions(position string) []string {
+	if position == "" {
+		return nil
+	}
+	return strings.Split(position, positionSep)
+}
+
+// Report 记录一次更新中翻译目录的变化。
+type Report struct {
+	Locale   msg.Locale
+	Path     string
+	New      []string // 新增的消息
+	Changed  []string // 同一位置文本发生变化的消息，沿用原有译文并标记为 fuzzy
+	Obsolete []string // 源码中不再使用的消息
+}
+
+// Merge 将提取的消息合并到翻译目录中，返回变化报告。
+//
+// source 为 true 表示这是源语言的目录，新消息的译文直接使用原文且不标记为 fuzzy。
+// 合并规则：
+//   - 已有的消息更新位置信息，之前标记为 obsolete 的恢复为正常
+//   - 新消息追加到末尾，非源语言标记为 fuzzy 等待翻译
+//   - 位置与某条不再使用的消息相同时视为修改，沿用其译文并标记为 fuzzy，原消息被替换
+//   - 其余不再使用的消息标记为 obsolete
+func (c *Catalog) Merge(messages []Message, source bool) *Report {
+	report := &Report{Locale: c.Language}
+
+	extracted := make(map[string]Message, len(messages))
+	for _, m := range messages {
+		extracted[m.ID] = m
+	}
+	existing := make(map[string]int, len(c.Messages))
+	for i, m := range c.Messages {
+		existing[m.ID] = i
+	}
+
+	// 不再使用的消息，按位置索引以识别修改
+	removed := map[string]int{}
+	for i, m := range c.Messages {
+		if _, ok := extracted[m.ID]; ok {
+			continue
+		}
+		for _, p := range splitPositions(m.Position) {
+			removed[p] = i
+		}
+	}
+
+	replaced := map[int]bool{}
+	for _, m := range messages {
+		if i, ok := existing[m.ID]; ok {
+			c.Messages[i].Position = joinPositions(m.Positions)
+			c.Messages[i].Obsolete = false
+			continue
+		}
+
+		entry := CatalogMessage{ID: m.ID, Message: m.ID, Position: joinPositions(m.Positions)}
+		if old, ok := previous(removed, replaced, m.Positions); ok {
+			entry.Translation = c.Messages[old].Translation
+			entry.TranslatorComment = c.Messages[old].TranslatorComment
+			entry.Fuzzy = true
+			replaced[old] = true
+			report.Changed = append(report.Changed, m.ID)
+		} else {
+			if source {
+				entry.Translation = m.ID
+			} else {
+				entry.Fuzzy = true
+			}
+			report.New = append(report.New, m.ID)
+		}
+		c.Messages = append(c.Messages, entry)
+	}
+
+	kept := c.Messages[:0]
+	for i, m := range c.Messages {
+		if replaced[i] {
+			continue
+		}
+		if _, ok := extracted[m.ID]; !ok && !m.Obsolete {
+			m.Obsolete = true
+			report.Obsolete = append(report.Obsolete, m.ID)
+		}
+		kept = append(kept, m)
+	}
+	c.Messages = kept
+
+	return report
+}
+
+// previous 查找与 positions 位置相同且尚未被替换的旧消息
+func previous(removed map[string]int, replaced map[int]bool, positions []string) (int, bool) {
+	for _, p := range positions {
+		if i, ok := removed[p]; ok && !replaced[i] {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ReadCatalog 读取 gotext JSON 翻译目录，文件不存在时返回空目录。
+func ReadCatalog(path string, locale msg.Locale) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Catalog{Language: locale}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog %s: %w", path, err)
+	}
+	if c.Language == "" {
+		c.Language = locale
+	}
+	return &c, nil
+}
+
+// WriteCatalog 将翻译目录写入文件，必要时创建目录。
+func WriteCatalog(path string, c *Catalog) error {
+	if c.Messages == nil {
+		c.Messages = []CatalogMessage{}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Options 配置 Run 的行为。
+type Options struct {
+	Dir     string       // 扫描的源码根目录，默认为当前目录
+	Out     string       // 翻译目录所在的目录，默认为 "locales"
+	Locales []msg.Locale // 需要生成或更新的语言
+	Source  msg.Locale   // 源语言，默认为 msg.English
+}
+
+// Run 提取 Dir 中的消息，并更新 Out 目录下每种语言的 <locale>.gotext.json 文件，
+// 返回每种语言的变化报告。文件布局与 xtext.PrinterFactory 加载的目录结构一致。
+func Run(opts Options) ([]*Report, error) {
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+	if opts.Out == "" {
+		opts.Out = "locales"
+	}
+	if opts.Source == "" {
+		opts.Source = msg.English
+	}
+
+	messages, err := Dir(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*Report
+	for _, locale := range slices.Compact(opts.Locales) {
+		path := filepath.Join(opts.Out, string(locale)+".gotext.json")
+		c, err := ReadCatalog(path, locale)
+		if err != nil {
+			return reports, err
+		}
+
+		report := c.Merge(messages, locale.Equal(opts.Source))
+		report.Path = path
+		if err := WriteCatalog(path, c); err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
@@ -0,0 +1,155 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestCatalog_Merge(t *testing.T) {
+	c := &Catalog{
+		Language: "zh-CN",
+		Messages: []CatalogMessage{
+			{ID: "Hello", Message: "Hello", Translation: "你好", Position: "a.go:1"},
+			{ID: "Save", Message: "Save", Translation: "保存", Position: "a.go:2"},
+			{ID: "Removed", Message: "Removed", Translation: "已删除", Position: "a.go:3"},
+		},
+	}
+
+	report := c.Merge([]Message{
+		{ID: "Hello", Positions: []string{"a.go:1", "b.go:9"}},
+		{ID: "Save changes", Positions: []string{"a.go:2"}},
+		{ID: "Cancel", Positions: []string{"a.go:4"}},
+	}, false)
+
+	if !reflect.DeepEqual(report.New, []string{"Cancel"}) {
+		t.Errorf("New = %v, want [Cancel]", report.New)
+	}
+	if !reflect.DeepEqual(report.Changed, []string{"Save changes"}) {
+		t.Errorf("Changed = %v, want [Save changes]", report.Changed)
+	}
+	if !reflect.DeepEqual(report.Obsolete, []string{"Removed"}) {
+		t.Errorf("Obsolete = %v, want [Removed]", report.Obsolete)
+	}
+
+	want := []CatalogMessage{
+		{ID: "Hello", Message: "Hello", Translation: "你好", Position: "a.go:1, b.go:9"},
+		{ID: "Removed", Message: "Removed", Translation: "已删除", Position: "a.go:3", Obsolete: true},
+		{ID: "Save changes", Message: "Save changes", Translation: "保存", Position: "a.go:2", Fuzzy: true},
+		{ID: "Cancel", Message: "Cancel", Position: "a.go:4", Fuzzy: true},
+	}
+	if !reflect.DeepEqual(c.Messages, want) {
+		t.Errorf("Messages = %+v, want %+v", c.Messages, want)
+	}
+
+	// 再次合并相同的消息不应产生变化，已过时的消息不会重复报告
+	report = c.Merge([]Message{
+		{ID: "Hello", Positions: []string{"a.go:1", "b.go:9"}},
+		{ID: "Save changes", Positions: []string{"a.go:2"}},
+		{ID: "Cancel", Positions: []string{"a.go:4"}},
+	}, false)
+	if len(report.New)+len(report.Changed)+len(report.Obsolete) != 0 {
+		t.Errorf("second Merge report = %+v, want no changes", report)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app")
+	if err := os.MkdirAll(filepath.Join(src, "testdata"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"app/main.go": `package main
+
+import "go-slim.dev/infra/msg"
+
+func main() { msg.Printf("Hello, %s!\n", "world") }
+`,
+		"app/main_test.go":       `package main; import "go-slim.dev/infra/msg"; var _ = msg.Sprintf("test only")`,
+		"app/testdata/sample.go": `package sample; import "go-slim.dev/infra/msg"; var _ = msg.Sprintf("testdata")`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(dir, "locales")
+	reports, err := Run(Options{Dir: src, Out: out, Locales: []msg.Locale{"en", "zh-CN"}})
+	if err != nil {
+		t.Fatalf("Run error = %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("Run() = %d reports, want 2", len(reports))
+	}
+
+	en, err := ReadCatalog(filepath.Join(out, "en.gotext.json"), "en")
+	if err != nil {
+		t.Fatalf("ReadCatalog error = %v", err)
+	}
+	wantEN := []CatalogMessage{{ID: "Hello, %s!\n", Message: "Hello, %s!\n", Translation: "Hello, %s!\n", Position: "main.go:5"}}
+	if !reflect.DeepEqual(en.Messages, wantEN) {
+		t.Errorf("en messages = %+v, want %+v", en.Messages, wantEN)
+	}
+
+	zh, err := ReadCatalog(filepath.Join(out, "zh-CN.gotext.json"), "zh-CN")
+	if err != nil {
+		t.Fatalf("ReadCatalog error = %v", err)
+	}
+	if zh.Language != "zh-CN" || len(zh.Messages) != 1 || !zh.Messages[0].Fuzzy || zh.Messages[0].Translation != "" {
+		t.Errorf("zh-CN catalog = %+v, want one untranslated fuzzy message", zh)
+	}
+}
+
+func TestWriteCatalog_PreservesUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zh-CN.gotext.json")
+	data := `{
+    "language": "zh-CN",
+    "messages": [
+        {
+            "id": "Hello, {Name}!",
+            "message": "Hello, {Name}!",
+            "translation": "你好，{Name}！",
+            "position": "a.go:1, b.go:9",
+            "comment": "greeting",
+            "placeholders": [
+                {
+                    "id": "Name",
+                    "string": "%[1]s",
+                    "type": "string",
+                    "underlyingType": "string",
+                    "argNum": 1,
+                    "expr": "name"
+                }
+            ]
+        }
+    ]
+}
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := ReadCatalog(path, "zh-CN")
+	if err != nil {
+		t.Fatalf("ReadCatalog error = %v", err)
+	}
+	if got := c.Messages[0].Position; got != "a.go:1, b.go:9" {
+		t.Errorf("Position = %q, want %q", got, "a.go:1, b.go:9")
+	}
+	if err := WriteCatalog(path, c); err != nil {
+		t.Fatalf("WriteCatalog error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("WriteCatalog wrote\n%s\nwant\n%s", got, data)
+	}
+}
@@ -0,0 +1,243 @@
+// Package extract 从 Go 源码中提取需要翻译的消息，并生成或更新 gotext JSON 格式的翻译目录。
+//
+// 提取器扫描以下调用中的字符串字面量：
+//   - msg 包的 Sprintf、Printf、Fprintf、SprintfWithContext、PrintfWithContext、
+//     FprintfWithContext、Sprintn 和 T
+//   - 任意接收者的 Sprintf、Printf、Fprintf 和 T 方法调用，如 printer.Sprintf、manager.T
+//
+// 提取基于语法分析，不进行类型检查，因此非 msg 包的同名方法也会被提取；
+// 参数不是字符串常量（字面量或字面量拼接）的调用会被忽略。
+//
+// 更新翻译目录时，新消息标记为 fuzzy 等待翻译，源码中不再使用的消息标记为 obsolete，
+// 同一位置的消息文本发生变化时视为修改，沿用原有译文并标记为 fuzzy 等待校对。
+//
+// 可以作为 go:generate 步骤运行：
+//
+//	//go:generate go run go-slim.dev/infra/msg/cmd/msgextract -out locales -lang en,zh-CN
+package extract
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// msgImportPath msg 包的导入路径
+const msgImportPath = "go-slim.dev/infra/msg"
+
+// Message 表示从源码中提取的一条消息。
+type Message struct {
+	ID        string   // 消息标识，即传给 Sprintf、T 等函数的格式字符串或翻译键
+	Positions []string // 出现的位置，格式为 "file:line"，file 相对于扫描的根目录
+}
+
+// packageFuncs msg 包中需要提取的函数及消息参数的位置
+var packageFuncs = map[string]int{
+	"Sprintf":            0,
+	"Printf":             0,
+	"Fprintf":            1,
+	"SprintfWithContext": 1,
+	"PrintfWithContext":  1,
+	"FprintfWithContext": 2,
+	"Sprintn":            1,
+	"T":                  1,
+}
+
+// methodFuncs 需要提取的方法及消息参数的位置（Printer 和 Manager 的方法）
+var methodFuncs = map[string]int{
+	"Sprintf": 0,
+	"Printf":  0,
+	"Fprintf": 1,
+	"T":       1,
+}
+
+// Dir 递归扫描目录中的 Go 源文件并提取消息，按首次出现的顺序返回。
+// 跳过 _test.go 文件，以及 testdata、vendor 和以 "." 或 "_" 开头的目录。
+func Dir(root string) ([]Message, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &extractor{fset: token.NewFileSet(), root: root, index: map[string]int{}}
+	for _, file := range files {
+		if err := e.file(file); err != nil {
+			return nil, err
+		}
+	}
+	return e.messages, nil
+}
+
+// Source 从单个源文件的内容中提取消息，filename 用于记录位置。
+func Source(filename string, src []byte) ([]Message, error) {
+	e := &extractor{fset: token.NewFileSet(), index: map[string]int{}}
+	f, err := parser.ParseFile(e.fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	e.inspect(f)
+	return e.messages, nil
+}
+
+// extractor 汇总多个文件中提取的消息
+type extractor struct {
+	fset     *token.FileSet
+	root     string
+	messages []Message
+	index    map[string]int // 消息 ID 到 messages 下标的映射
+}
+
+func (e *extractor) file(path string) error {
+	f, err := parser.ParseFile(e.fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return err
+	}
+	e.inspect(f)
+	return nil
+}
+
+// inspect 遍历语法树，记录需要提取的调用中的消息
+func (e *extractor) inspect(f *ast.File) {
+	imports := fileImports(f)
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		var pos int
+		if x, ok := sel.X.(*ast.Ident); ok && imports[x.Name] != "" {
+			// 包级函数调用，只提取 msg 包的函数
+			if imports[x.Name] != msgImportPath {
+				return true
+			}
+			if pos, ok = packageFuncs[sel.Sel.Name]; !ok {
+				return true
+			}
+		} else if pos, ok = methodFuncs[sel.Sel.Name]; !ok {
+			return true
+		}
+
+		if pos >= len(call.Args) {
+			return true
+		}
+		if id, ok := constString(call.Args[pos]); ok && id != "" {
+			e.add(id, call.Args[pos].Pos())
+		}
+		return true
+	})
+}
+
+// add 记录消息及其位置
+func (e *extractor) add(id string, p token.Pos) {
+	position := e.fset.Position(p)
+	file := position.Filename
+	if e.root != "" {
+		if rel, err := filepath.Rel(e.root, file); err == nil {
+			file = rel
+		}
+	}
+	where := filepath.ToSlash(file) + ":" + strconv.Itoa(position.Line)
+
+	if i, ok := e.index[id]; ok {
+		if !slices.Contains(e.messages[i].Positions, where) {
+			e.messages[i].Positions = append(e.messages[i].Positions, where)
+		}
+		return
+	}
+	e.index[id] = len(e.messages)
+	e.messages = append(e.messages, Message{ID: id, Positions: []string{where}})
+}
+
+// fileImports 返回文件中导入包的名称到导入路径的映射
+func fileImports(f *ast.File) map[string]string {
+	imports := make(map[string]string, len(f.Imports))
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+// importName 推断导入路径的默认包名，忽略 "/v2" 等主版本后缀和 gopkg.in 的 ".v3" 后缀
+func importName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	if i := strings.LastIndex(name, ".v"); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// isMajorVersion 检查是否为 "v2" 这样的主版本号
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// constString 求字符串字面量或字面量拼接的值
+func constString(expr ast.Expr) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(x.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if x.Op != token.ADD {
+			return "", false
+		}
+		l, ok := constString(x.X)
+		if !ok {
+			return "", false
+		}
+		r, ok := constString(x.Y)
+		return l + r, ok
+	case *ast.ParenExpr:
+		return constString(x.X)
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,62 @@
+package extract
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sample = `package app
+
+import (
+	"fmt"
+
+	"go-slim.dev/infra/msg"
+	i18n "go-slim.dev/infra/msg"
+)
+
+func handler(ctx context.Context, p msg.Printer, m *msg.Manager, name string) {
+	msg.Sprintf("Hello, %s!", name)
+	i18n.T(ctx, "Welcome back, {name}!", msg.Args{"name": name})
+	msg.Sprintn(p, "You have {count} messages", nil)
+	msg.FprintfWithContext(ctx, w, "Saved")
+	p.Sprintf("Goodbye" + ", " + "%s", name)
+	m.T(ctx, "Hello, %s!")
+	fmt.Sprintf("not translated %d", 1)
+	msg.Sprint("not a format")
+	p.Sprintf(name)
+	msg.Sprintf("")
+}
+`
+
+func TestSource(t *testing.T) {
+	messages, err := Source("app/handler.go", []byte(sample))
+	if err != nil {
+		t.Fatalf("Source error = %v", err)
+	}
+
+	want := []Message{
+		{ID: "Hello, %s!", Positions: []string{"app/handler.go:11", "app/handler.go:16"}},
+		{ID: "Welcome back, {name}!", Positions: []string{"app/handler.go:12"}},
+		{ID: "You have {count} messages", Positions: []string{"app/handler.go:13"}},
+		{ID: "Saved", Positions: []string{"app/handler.go:14"}},
+		{ID: "Goodbye, %s", Positions: []string{"app/handler.go:15"}},
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("Source() = %+v, want %+v", messages, want)
+	}
+}
+
+func TestImportName(t *testing.T) {
+	tests := map[string]string{
+		"go-slim.dev/infra/msg":        "msg",
+		"github.com/golang-jwt/jwt/v5": "jwt",
+		"gopkg.in/yaml.v3":             "yaml",
+		"github.com/tidwall/go-json":   "go_json",
+		"fmt":                          "fmt",
+	}
+	for path, want := range tests {
+		if got := importName(path); got != want {
+			t.Errorf("importName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -156,6 +156,9 @@ func SetDefaultManager(manager *Manager) {
 //
 //	msg.SetLocale(French)
 //	fmt.Println(msg.Sprintf("Hello")) // 现在将使用法文翻译
+//
+// 全局语言由所有 goroutine 共享，不要在处理请求时调用 SetLocale 切换语言，
+// 而应使用 msgslim.Middleware 或 RunWithLocale 将语言放入请求的上下文中。
 func SetLocale(locale Locale) {
 	GetDefaultManager().SetLocale(locale)
 }
@@ -326,6 +329,11 @@ func GetPrinterWithContext(ctx context.Context) Printer {
 	return GetDefaultManager().GetPrinterWithContext(ctx)
 }
 
+// AcquirePrinter 从上下文获取 Printer，ctx 结束时返回回退的 Printer 和错误
+func AcquirePrinter(ctx context.Context) (Printer, error) {
+	return GetDefaultManager().AcquirePrinter(ctx)
+}
+
 // SprintWithContext 使用上下文语言进行 Sprint 格式化
 func SprintWithContext(ctx context.Context, args ...any) string {
 	return GetDefaultManager().SprintWithContext(ctx, args...)
@@ -376,6 +384,11 @@ func WithLocale(locale Locale, fn func(Printer)) {
 	GetDefaultManager().WithLocale(locale, fn)
 }
 
+// RunWithLocale 使用携带 locale 的上下文执行 fn，不改变全局默认语言，详见 Manager.RunWithLocale
+func RunWithLocale(ctx context.Context, locale Locale, fn func(ctx context.Context)) {
+	GetDefaultManager().RunWithLocale(ctx, locale, fn)
+}
+
 // WithContext 使用上下文信息执行函数
 func WithContext(ctx context.Context, fn func(Printer)) {
 	GetDefaultManager().WithContext(ctx, fn)
@@ -433,6 +433,49 @@ func TestGlobalThreadSafety(t *testing.T) {
 	})
 }
 
+func TestRunWithLocale(t *testing.T) {
+	originalManager := defaultManager
+	defer SetDefaultManager(originalManager)
+
+	manager := NewManager(ManagerConfig{Locale: English, LogFunc: func(string) {}})
+	_ = manager.SetMessage("zh-Hans-CN", "Hello", "你好") // zh 按 DefaultResolveTable 解析为 zh-Hans-CN
+	_ = manager.SetMessage(French, "Hello", "Bonjour")
+	SetDefaultManager(manager)
+
+	want := map[Locale]string{English: "Hello", Chinese: "你好", French: "Bonjour"}
+	var wg sync.WaitGroup
+	for i := range 30 {
+		locale := []Locale{English, Chinese, French}[i%3]
+		wg.Go(func() {
+			RunWithLocale(context.Background(), locale, func(ctx context.Context) {
+				for range 10 {
+					if got := SprintfWithContext(ctx, "Hello"); got != want[locale] {
+						t.Errorf("%s: SprintfWithContext() = %q, want %q", locale, got, want[locale])
+						return
+					}
+				}
+			})
+		})
+	}
+	wg.Wait()
+
+	if got := GetLocale(); got != English {
+		t.Errorf("GetLocale() = %q, want %q", got, English)
+	}
+
+	// 语言按 ResolveTable 解析，其他上下文值保持不变
+	factory := NewPrinterFactory()
+	parent := WithPrinterFactoryContext(context.Background(), factory)
+	RunWithLocale(parent, Chinese, func(ctx context.Context) {
+		if locale, _ := GetLocaleFromContext(ctx); locale != "zh-Hans-CN" {
+			t.Errorf("locale = %q, want %q", locale, "zh-Hans-CN")
+		}
+		if f, _ := GetPrinterFactoryFromContext(ctx); f != factory {
+			t.Error("RunWithLocale() dropped the context factory")
+		}
+	})
+}
+
 func TestGlobalManagerReset(t *testing.T) {
 	// Save original state
 	originalManager := defaultManager
@@ -0,0 +1,148 @@
+package msg
+
+import (
+	"context"
+	"strings"
+)
+
+// ListStyle 表示列表的连接方式，对应 CLDR 列表模式中的 standard 和 or。
+type ListStyle int
+
+const (
+	ListAnd ListStyle = iota // 并列，如 en: "a, b, and c"，zh: "a、b和c"
+	ListOr                   // 选择，如 en: "a, b, or c"，zh: "a、b或c"
+)
+
+// ListFormatter 是 Printer 的可选扩展接口，按语言环境将多个项目连接为列表。
+//
+// 未实现该接口的 Printer 使用内置的 CLDR 列表模式，内置英语、简体中文、繁体中文、
+// 日语、韩语、德语、法语和西班牙语，其它语言使用英语的模式。
+type ListFormatter interface {
+	// FormatList 连接 items，如 en 中 ListAnd 的 ["a", "b", "c"] → "a, b, and c"
+	FormatList(items []string, style ListStyle) string
+}
+
+// SprintList 使用 Printer 的语言将 items 连接为列表，
+// Printer 实现了 ListFormatter 时使用其实现。
+//
+// 示例：
+//
+//	msg.SprintList(printer, []string{"Alice", "Bob", "Carol"}, msg.ListAnd)
+//	// en: "Alice, Bob, and Carol"，zh-CN: "Alice、Bob和Carol"，de: "Alice, Bob und Carol"
+func SprintList(p Printer, items []string, style ListStyle) string {
+	if lf, ok := p.(ListFormatter); ok {
+		return lf.FormatList(items, style)
+	}
+	return formatList(p.Locale(), items, style)
+}
+
+// FormatList 使用上下文中的语言将 items 连接为列表，见 SprintList
+func FormatList(ctx context.Context, items []string, style ListStyle) string {
+	return SprintList(GetPrinterWithContext(ctx), items, style)
+}
+
+// formatList 使用内置的列表模式连接 items
+func formatList(locale Locale, items []string, style ListStyle) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+
+	data, ok := listTables[tableLanguage(locale)]
+	if !ok {
+		data = listTables["en"]
+	}
+	pattern := data[ListAnd]
+	if style == ListOr {
+		pattern = data[ListOr]
+	}
+
+	last := items[len(items)-1]
+	if len(items) == 2 {
+		return items[0] + pattern.conjunction(pattern.two, last) + last
+	}
+	var b strings.Builder
+	b.WriteString(items[0])
+	for _, item := range items[1 : len(items)-1] {
+		b.WriteString(pattern.middle)
+		b.WriteString(item)
+	}
+	b.WriteString(pattern.conjunction(pattern.end, last))
+	b.WriteString(last)
+	return b.String()
+}
+
+// listPattern 是一种连接方式的列表模式，以分隔符表示 CLDR 中的 {0} 和 {1} 之间的文本
+type listPattern struct {
+	two    string // 只有两项时的分隔符
+	middle string // 前面各项之间的分隔符
+	end    string // 最后两项之间的分隔符
+
+	// contextual 在分隔符需要按最后一项的读音变化时返回替换后的分隔符，如西班牙语中 "i" 开头的词前的 "y" 变为 "e"
+	contextual func(sep, next string) string
+}
+
+// conjunction 返回 sep 在 next 之前的形式
+func (p listPattern) conjunction(sep, next string) string {
+	if p.contextual != nil {
+		return p.contextual(sep, next)
+	}
+	return sep
+}
+
+// spanishConjunction 实现西班牙语的连词变化：
+// "y" 在以 i 或 hi 音开头的词前变为 "e"，"o" 在以 o、ho 或 8 开头的词前变为 "u"
+func spanishConjunction(sep, next string) string {
+	lower := strings.ToLower(next)
+	switch sep {
+	case " y ":
+		if (strings.HasPrefix(lower, "i") || strings.HasPrefix(lower, "hi")) &&
+			!strings.HasPrefix(lower, "hia") && !strings.HasPrefix(lower, "hie") &&
+			!strings.HasPrefix(lower, "hio") && !strings.HasPrefix(lower, "hiu") {
+			return " e "
+		}
+	case " o ":
+		if strings.HasPrefix(lower, "o") || strings.HasPrefix(lower, "ho") || strings.HasPrefix(lower, "8") {
+			return " u "
+		}
+	}
+	return sep
+}
+
+// listTables 按语言保存 ListAnd 和 ListOr 的列表模式
+var listTables = map[string][2]listPattern{
+	"en": {
+		{two: " and ", middle: ", ", end: ", and "},
+		{two: " or ", middle: ", ", end: ", or "},
+	},
+	"zh-Hans": {
+		{two: "和", middle: "、", end: "和"},
+		{two: "或", middle: "、", end: "或"},
+	},
+	"zh-Hant": {
+		{two: "和", middle: "、", end: "和"},
+		{two: "或", middle: "、", end: "或"},
+	},
+	"ja": {
+		{two: "、", middle: "、", end: "、"},
+		{two: "または", middle: "、", end: "、または"},
+	},
+	"ko": {
+		{two: " 및 ", middle: ", ", end: " 및 "},
+		{two: " 또는 ", middle: ", ", end: " 또는 "},
+	},
+	"de": {
+		{two: " und ", middle: ", ", end: " und "},
+		{two: " oder ", middle: ", ", end: " oder "},
+	},
+	"fr": {
+		{two: " et ", middle: ", ", end: " et "},
+		{two: " ou ", middle: ", ", end: " ou "},
+	},
+	"es": {
+		{two: " y ", middle: ", ", end: " y ", contextual: spanishConjunction},
+		{two: " o ", middle: ", ", end: " o ", contextual: spanishConjunction},
+	},
+}
@@ -0,0 +1,59 @@
+package msg
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// fakeListPrinter is a Printer that implements ListFormatter
+type fakeListPrinter struct {
+	Printer
+}
+
+func (fakeListPrinter) FormatList(items []string, style ListStyle) string {
+	return strings.Join(items, "+")
+}
+
+func TestSprintList(t *testing.T) {
+	abc := []string{"a", "b", "c"}
+	tests := []struct {
+		locale Locale
+		items  []string
+		style  ListStyle
+		want   string
+	}{
+		{English, nil, ListAnd, ""},
+		{English, []string{"a"}, ListAnd, "a"},
+		{English, []string{"a", "b"}, ListAnd, "a and b"},
+		{English, abc, ListAnd, "a, b, and c"},
+		{English, abc, ListOr, "a, b, or c"},
+		{"zh-CN", abc, ListAnd, "a、b和c"},
+		{"zh-TW", []string{"a", "b"}, ListOr, "a或b"},
+		{Japanese, abc, ListAnd, "a、b、c"},
+		{Japanese, abc, ListOr, "a、b、またはc"},
+		{Korean, abc, ListAnd, "a, b 및 c"},
+		{German, abc, ListAnd, "a, b und c"},
+		{French, []string{"a", "b", "c", "d"}, ListOr, "a, b, c ou d"},
+		{Spanish, []string{"Juan", "Ignacio"}, ListAnd, "Juan e Ignacio"},
+		{Spanish, []string{"agua", "hielo"}, ListAnd, "agua y hielo"},
+		{Spanish, []string{"uno", "siete", "ocho"}, ListOr, "uno, siete u ocho"},
+		{"pt-BR", abc, ListAnd, "a, b, and c"},
+	}
+	for _, tt := range tests {
+		if got := SprintList(NewPrinter(tt.locale), tt.items, tt.style); got != tt.want {
+			t.Errorf("%s SprintList(%q, %d) = %q, want %q", tt.locale, tt.items, tt.style, got, tt.want)
+		}
+	}
+
+	if got := SprintList(fakeListPrinter{NewPrinter(English)}, abc, ListAnd); got != "a+b+c" {
+		t.Errorf("SprintList() = %q, want the ListFormatter implementation", got)
+	}
+}
+
+func TestFormatList(t *testing.T) {
+	ctx := WithLocaleContext(context.Background(), Chinese)
+	if got := FormatList(ctx, []string{"苹果", "香蕉", "橙子"}, ListOr); got != "苹果、香蕉或橙子" {
+		t.Errorf("FormatList() = %q", got)
+	}
+}
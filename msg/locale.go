@@ -12,7 +12,6 @@ package msg
 
 import (
 	"strings"
-	"sync"
 )
 
 // 常用的预定义 Locale
@@ -107,9 +106,6 @@ func (l Locale) Base() (Locale, bool) {
 	return Locale(str), true
 }
 
-// localeCache 用于缓存 Locale 解析结果，提高性能
-var localeCache = sync.Map{} // map[string]localeParts
-
 // localeParts 存储解析后的 Locale 各部分
 type localeParts struct {
 	slr, ext, pri string // slr:语言/脚本/地区, ext:扩展部分, pri:私有部分
@@ -117,6 +113,8 @@ type localeParts struct {
 
 // Parts 解析 Locale 字符串为三个主要部分。
 //
+// 结果缓存在容量有限的全局缓存中，见 SetLocaleCacheSize。
+//
 // 返回值：
 //   - slr: 语言/脚本/地区部分 (如 "zh-Hans-CN")
 //   - ext: 扩展部分 (如 "ca-gregory-co-phonebk")
@@ -139,8 +137,7 @@ func (l Locale) Parts() (slr, ext, pri string) {
 	}
 
 	// 尝试从缓存获取结果，提高性能
-	if cached, ok := localeCache.Load(str); ok {
-		parts := cached.(localeParts)
+	if parts, ok := localeCache.load(str); ok {
 		return parts.slr, parts.ext, parts.pri
 	}
 
@@ -148,17 +145,17 @@ func (l Locale) Parts() (slr, ext, pri string) {
 	slr, ext, pri = l.calculateParts()
 
 	// 缓存结果以供后续使用
-	localeCache.Store(str, localeParts{slr, ext, pri})
+	localeCache.store(str, localeParts{slr, ext, pri})
 
 	return slr, ext, pri
 }
 
 // calculateParts 实际执行 Locale 字符串解析的逻辑。
 //
-// 这是一个内部方法，通过在字符串末尾添加连字符来简化边界检查。
+// 这是一个内部方法，只在原字符串上查找和切片，不分配内存。
 // 解析算法：
-//  1. 查找 "-u-" 标记扩展部分开始位置
-//  2. 查找 "-x-" 标记私有部分开始位置
+//  1. 查找 "-u-"（或末尾的 "-u"）标记扩展部分开始位置
+//  2. 查找 "-x-"（或末尾的 "-x"）标记私有部分开始位置
 //  3. 根据位置信息分割字符串
 func (l Locale) calculateParts() (slr, ext, pri string) {
 	str := string(l)
@@ -166,30 +163,42 @@ func (l Locale) calculateParts() (slr, ext, pri string) {
 		return "", "", ""
 	}
 
-	// 添加连字符简化边界检查，避免处理 "-u" 和 "-x" 的边界问题
-	str = str + "-"
+	u := indexSingleton(str, 'u')
+	x := indexSingleton(str, 'x')
 
-	u := strings.Index(str, "-u-")
-	x := strings.Index(str, "-x-")
+	// after 返回单例标记之后、end 之前的部分
+	after := func(i, end int) string {
+		return str[min(i+3, end):end]
+	}
 
 	if u == -1 && x == -1 {
 		// 没有扩展或私有部分
-		return str[:len(str)-1], "", ""
+		return str, "", ""
 	}
 	if u == -1 {
 		// 只有私有部分，没有扩展部分
-		return str[:x], "", str[x+3 : len(str)-1]
+		return str[:x], "", after(x, len(str))
 	}
 	if x == -1 {
 		// 只有扩展部分，没有私有部分
-		return str[:u], str[u+3 : len(str)-1], ""
+		return str[:u], after(u, len(str)), ""
 	}
 	if u < x {
 		// 扩展部分在私有部分之前
-		return str[:u], str[u+3 : x], str[x+3 : len(str)-1]
+		return str[:u], after(u, x), after(x, len(str))
 	}
 	// 私有部分在扩展部分之前（不太常见但合法）
-	return str[:x], str[x+3 : u], str[u+3 : len(str)-1]
+	return str[:x], after(x, u), after(u, len(str))
+}
+
+// indexSingleton 返回 s 中第一个 "-c-" 或末尾的 "-c" 的位置，不存在时返回 -1
+func indexSingleton(s string, c byte) int {
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] == '-' && s[i+1] == c && (i+2 == len(s) || s[i+2] == '-') {
+			return i
+		}
+	}
+	return -1
 }
 
 // Language 返回 Locale 的语言代码部分。
@@ -485,3 +485,58 @@ func ExampleLocale() {
 	// Compare zh-CN vs zh-Hans: -1
 	// Compare en-US vs en-Latn: -1
 }
+
+func TestLocale_Parts(t *testing.T) {
+	tests := []struct {
+		locale        Locale
+		slr, ext, pri string
+	}{
+		{"", "", "", ""},
+		{"en-US", "en-US", "", ""},
+		{"zh-Hans-CN-u-ca-gregory-x-private", "zh-Hans-CN", "ca-gregory", "private"},
+		{"en-x-foo", "en", "", "foo"},
+		{"de-u-co-phonebk", "de", "co-phonebk", ""},
+		{"en-u", "en", "", ""},
+		{"en-x", "en", "", ""},
+	}
+	for _, tt := range tests {
+		slr, ext, pri := tt.locale.Parts()
+		if slr != tt.slr || ext != tt.ext || pri != tt.pri {
+			t.Errorf("%q.Parts() = %q, %q, %q; want %q, %q, %q", tt.locale, slr, ext, pri, tt.slr, tt.ext, tt.pri)
+		}
+	}
+}
+
+func BenchmarkLocale_Parts(b *testing.B) {
+	l := Locale("zh-Hans-CN-u-ca-gregory-x-private")
+	b.Run("cached", func(b *testing.B) {
+		for b.Loop() {
+			l.Parts()
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		defer SetLocaleCacheSize(SetLocaleCacheSize(0))
+		for b.Loop() {
+			l.Parts()
+		}
+	})
+	b.Run("unique", func(b *testing.B) {
+		// Distinct locales, as with Accept-Language values from user input
+		locales := make([]Locale, 4096)
+		for i := range locales {
+			locales[i] = Locale(fmt.Sprintf("en-US-x-%d", i))
+		}
+		i := 0
+		for b.Loop() {
+			locales[i%len(locales)].Parts()
+			i++
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				l.Parts()
+			}
+		})
+	})
+}
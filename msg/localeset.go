@@ -1,8 +1,11 @@
 package msg
 
 import (
+	"math"
 	"slices"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 // LocaleSet 表示支持的语言环境集合，用于明确区分不同的语言支持策略。
@@ -143,3 +146,92 @@ func (ls LocaleSet) Contains(locale Locale) bool {
 func (ls LocaleSet) Slice() []Locale {
 	return slices.Clone(ls)
 }
+
+// Match 按 RFC 4647 的 Lookup 方案，为请求的语言在集合中选出唯一的最佳语言。
+//
+// requested 可以是单个语言，也可以带有质量值，如 "fr;q=0.9" 或整个 Accept-Language 请求头，
+// 按质量值从高到低依次匹配，质量值相同时保持原有顺序；q=0 的语言和通配符 "*" 会被忽略。
+// 每个请求的语言与 MatchLocale 相同，依次尝试基础部分完全相同、更通用和更具体的支持语言。
+//
+// 无限制的集合返回优先级最高的请求语言；没有请求语言或都无法匹配时返回 false。
+//
+// 示例：
+//
+//	supported := LocaleSet{English, "fr", "zh-Hans"}
+//	supported.Match("de, fr-CA;q=0.8, en;q=0.5") // → "fr", true
+//	supported.Match("zh-Hans-CN", "en")          // → "zh-Hans", true
+//	supported.Match("ja")                         // → "", false
+func (ls LocaleSet) Match(requested ...Locale) (Locale, bool) {
+	ranges := make([]string, len(requested))
+	for i, r := range requested {
+		ranges[i] = string(r)
+	}
+	preferred := acceptedLocales(strings.Join(ranges, ","))
+	if len(preferred) == 0 {
+		return "", false
+	}
+	if ls.IsUnlimited() {
+		return preferred[0], true
+	}
+
+	sorted := ls.Sorted()
+	for _, locale := range preferred {
+		if match, ok := sorted.lookup(locale); ok {
+			return match, true
+		}
+	}
+	return "", false
+}
+
+// ToAcceptLanguage 将集合转换为 Accept-Language 请求头，集合中的顺序即优先顺序。
+//
+// 第一个语言的质量值为 1，之后依次递减 0.1；超过 10 个语言时按数量均分，
+// 最低为 0.001。无限制的集合返回 "*"，空集合返回空字符串。
+// 结果可以由 ParseAcceptLanguage 解析回原来的顺序。
+//
+// 示例：
+//
+//	LocaleSet{"zh-CN", English, "fr"}.ToAcceptLanguage() // → "zh-CN, en;q=0.9, fr;q=0.8"
+func (ls LocaleSet) ToAcceptLanguage() string {
+	if ls.IsUnlimited() {
+		return "*"
+	}
+
+	step := 0.1
+	if len(ls) > 10 {
+		step = max(math.Floor(1000/float64(len(ls)))/1000, 0.001)
+	}
+
+	var b strings.Builder
+	for i, locale := range ls {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(string(locale))
+		if i > 0 {
+			q := max(math.Round((1-float64(i)*step)*1000)/1000, 0.001)
+			b.WriteString(";q=" + strconv.FormatFloat(q, 'f', -1, 64))
+		}
+	}
+	return b.String()
+}
+
+// lookup 在已排序的集合中查找与 locale 最接近的语言：
+// 先查找基础部分完全相同的，再查找包含它的更通用的，最后查找被它包含的更具体的
+func (ls LocaleSet) lookup(locale Locale) (Locale, bool) {
+	if i := slices.IndexFunc(ls, locale.BaseEqual); i >= 0 {
+		return ls[i], true
+	}
+	// 更具体的排在前面，因此第一个包含该语言的即为最接近的
+	for _, s := range ls {
+		if s.Contains(locale) {
+			return s, true
+		}
+	}
+	for _, s := range ls {
+		if locale.Contains(s) {
+			return s, true
+		}
+	}
+	return "", false
+}
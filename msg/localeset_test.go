@@ -1,6 +1,7 @@
 package msg
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -905,3 +906,115 @@ func TestLocaleSet_Contains_BoundaryConditions(t *testing.T) {
 		}
 	})
 }
+
+func TestLocaleSet_Match(t *testing.T) {
+	supported := LocaleSet{English, "en-GB", ChineseSimplified, ChineseTraditional, "fr", "pt-BR", "es-419"}
+
+	tests := []struct {
+		name      string
+		supported LocaleSet
+		requested []Locale
+		expected  Locale
+		ok        bool
+	}{
+		// 浏览器发送的 Accept-Language 请求头
+		{"Chrome zh", supported, []Locale{"zh-Hans-CN,zh-Hans;q=0.9,en;q=0.8"}, ChineseSimplified, true},
+		{"Safari zh-TW", supported, []Locale{"zh-Hant-TW"}, ChineseTraditional, true},
+		{"Firefox en-US", supported, []Locale{"en-US,en;q=0.5"}, English, true},
+		{"Exact regional match", supported, []Locale{"en-GB,en;q=0.9"}, "en-GB", true},
+		{"Regional falls back to base", supported, []Locale{"en-AU,en;q=0.9"}, English, true},
+		{"Quality order wins over header order", supported, []Locale{"de;q=0.5, en-GB;q=0.7, fr;q=0.9"}, "fr", true},
+		{"Equal quality keeps header order", supported, []Locale{"ja, fr;q=0.8, en;q=0.8"}, "fr", true},
+		{"Unsupported first choice", supported, []Locale{"de-DE,de;q=0.9,fr;q=0.8"}, "fr", true},
+		{"Base language matches specific", supported, []Locale{"pt"}, "pt-BR", true},
+		{"Region mismatch tries next range", supported, []Locale{"es-MX,es;q=0.9"}, "es-419", true},
+		{"Case insensitive", supported, []Locale{"FR-ca, EN;q=0.1"}, "fr", true},
+		{"Extensions ignored", supported, []Locale{"en-US-u-ca-gregory"}, English, true},
+		{"Zero quality excluded", supported, []Locale{"fr;q=0, en-GB;q=0.1"}, "en-GB", true},
+		{"Only zero quality", supported, []Locale{"fr;q=0"}, "", false},
+		{"Wildcard ignored", supported, []Locale{"*"}, "", false},
+		{"Wildcard after unsupported", supported, []Locale{"ja, *;q=0.5"}, "", false},
+		{"Invalid quality ignored", supported, []Locale{"fr;q=abc, en;q=0.3"}, English, true},
+		{"Script mismatch", supported, []Locale{"zh-Latn"}, "", false},
+		{"Nothing supported", supported, []Locale{"ja-JP,ko;q=0.9"}, "", false},
+		{"Empty header", supported, []Locale{""}, "", false},
+		{"No request", supported, nil, "", false},
+
+		// 多个参数
+		{"Variadic order", supported, []Locale{"de", "en-GB", "fr"}, "en-GB", true},
+		{"Variadic with quality", supported, []Locale{"fr;q=0.1", "en;q=0.5"}, English, true},
+
+		// 特殊集合
+		{"Unlimited set", nil, []Locale{"ja;q=0.5, ko"}, "ko", true},
+		{"Unlimited set without request", nil, nil, "", false},
+		{"Empty set", LocaleSet{}, []Locale{"en"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.supported.Match(tt.requested...)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("Match(%q) = %q, %v, want %q, %v", tt.requested, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLocaleSet_MatchAgreesWithMatchLocale(t *testing.T) {
+	supported := LocaleSet{English, ChineseSimplified, Locale("zh-Hant-TW"), FrenchFR}
+	headers := []string{
+		"zh-Hans-CN,zh;q=0.9",
+		"fr-CA;q=0.8, ja",
+		"en-US,en;q=0.9,zh-Hans;q=0.8",
+		"zh-TW",
+		"de, ko;q=0.2",
+	}
+
+	for _, header := range headers {
+		expected := MatchLocale(acceptedLocales(header), supported, "")
+		got, ok := supported.Match(Locale(header))
+		if got != expected || ok != (expected != "") {
+			t.Errorf("Match(%q) = %q, %v, want %q", header, got, ok, expected)
+		}
+	}
+}
+
+func TestLocaleSet_ToAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		set      LocaleSet
+		expected string
+	}{
+		{"Unlimited", nil, "*"},
+		{"Empty", LocaleSet{}, ""},
+		{"Single", LocaleSet{English}, "en"},
+		{"Decreasing quality", LocaleSet{"zh-CN", English, "fr"}, "zh-CN, en;q=0.9, fr;q=0.8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.set.ToAcceptLanguage(); got != tt.expected {
+				t.Errorf("ToAcceptLanguage() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Round trip keeps order", func(t *testing.T) {
+		set := make(LocaleSet, 25)
+		for i := range set {
+			set[i] = Locale(fmt.Sprintf("en-x-l%d", i))
+		}
+		parsed := ParseAcceptLanguage(set.ToAcceptLanguage())
+		if len(parsed) != len(set) {
+			t.Fatalf("ParseAcceptLanguage() returned %d locales, want %d", len(parsed), len(set))
+		}
+		for i, w := range parsed {
+			if w.Locale != set[i] {
+				t.Errorf("locale %d = %q, want %q", i, w.Locale, set[i])
+			}
+			if i > 0 && w.Quality >= parsed[i-1].Quality {
+				t.Errorf("quality %d = %v, want less than %v", i, w.Quality, parsed[i-1].Quality)
+			}
+		}
+	})
+}
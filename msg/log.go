@@ -0,0 +1,79 @@
+package msg
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// 结构化日志的属性名
+const (
+	LogKeyLocale = "locale" // 语言
+	LogKeyID     = "id"     // 消息 ID
+)
+
+// HandlerLogFunc 将 slog.Handler 适配为 LogFunc，供 xtext 的翻译源等只接受 LogFunc 的组件使用。
+//
+// 消息开头的 "[DEBUG]"、"[INFO]"、"[WARN]"、"[ERROR]" 前缀会转换为对应的日志级别并去掉，
+// 没有前缀时使用 INFO 级别。
+//
+// 示例：
+//
+//	handler := slog.NewJSONHandler(os.Stderr, nil)
+//	factory := xtext.NewPrinterFactory(xtext.LogFunc(msg.HandlerLogFunc(handler)))
+func HandlerLogFunc(h slog.Handler) LogFunc {
+	return func(message string) {
+		level := slog.LevelInfo
+		for _, l := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+			if rest, ok := strings.CutPrefix(message, "["+l.String()+"] "); ok {
+				level, message = l, rest
+				break
+			}
+		}
+		logRecord(context.Background(), h, level, message)
+	}
+}
+
+// log 记录日志的内部方法。
+//
+// 配置了 LogHandler 时以 message 为消息、attrs 为属性记录结构化日志；
+// 配置了 LogFunc 时传入 "[LEVEL] message key=value ..." 格式的文本。
+func (m *Manager) log(ctx context.Context, level slog.Level, message string, attrs ...slog.Attr) {
+	if m.logHandler != nil {
+		logRecord(ctx, m.logHandler, level, message, attrs...)
+	}
+	if m.logFunc != nil {
+		var b strings.Builder
+		b.WriteString("[" + level.String() + "] " + message)
+		for _, a := range attrs {
+			b.WriteString(" " + a.String())
+		}
+		m.logFunc(b.String())
+	}
+}
+
+// logRecord 向 h 写入一条日志，来源为 msg 包外的第一个调用者
+func logRecord(ctx context.Context, h slog.Handler, level slog.Level, message string, attrs ...slog.Attr) {
+	if !h.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), level, message, callerPC())
+	r.AddAttrs(attrs...)
+	_ = h.Handle(ctx, r)
+}
+
+// callerPC 返回 msg 包外第一个调用者的程序计数器，
+// 使启用 AddSource 的 Handler 报告调用 Manager 的代码位置
+func callerPC() uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, "go-slim.dev/infra/msg.") || strings.HasSuffix(frame.File, "_test.go") {
+			return pc
+		}
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package msg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// decodeRecords 解析 JSONHandler 输出的日志记录
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for line := range strings.Lines(buf.String()) {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid log record %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestManager_LogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	var lines []string
+	manager := NewManager(ManagerConfig{
+		LogFunc:    func(s string) { lines = append(lines, s) },
+		LogHandler: slog.NewJSONHandler(&buf, &slog.HandlerOptions{AddSource: true, Level: slog.LevelInfo}),
+	})
+
+	if err := manager.SetMessage(ChineseSimplified, "Checkout", "去结算"); err != nil {
+		t.Fatal(err)
+	}
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1: %s", len(records), buf.String())
+	}
+	r := records[0]
+	if r["level"] != "INFO" || r["msg"] != "Setting message override" {
+		t.Errorf("record = %v, want INFO Setting message override", r)
+	}
+	if r[LogKeyLocale] != "zh-Hans" || r[LogKeyID] != "Checkout" {
+		t.Errorf("record attributes = %v, want locale and id", r)
+	}
+	source, _ := r["source"].(map[string]any)
+	if file, _ := source["file"].(string); !strings.HasSuffix(file, "log_test.go") {
+		t.Errorf("record source = %v, want the calling test file", source)
+	}
+
+	// LogFunc 仍然收到文本日志
+	want := "[INFO] Setting message override locale=zh-Hans id=Checkout"
+	if len(lines) != 1 || lines[0] != want {
+		t.Errorf("LogFunc lines = %q, want %q", lines, want)
+	}
+
+	t.Run("Level filtering", func(t *testing.T) {
+		buf.Reset()
+		manager.GetPrinterWithContext(WithLocaleContext(context.Background(), French))
+		for _, r := range decodeRecords(t, &buf) {
+			if r["level"] == "DEBUG" {
+				t.Errorf("DEBUG record should be filtered: %v", r)
+			}
+		}
+	})
+
+	t.Run("Missing named argument", func(t *testing.T) {
+		buf.Reset()
+		manager.T(context.Background(), "Hello, {name}!")
+		records := decodeRecords(t, &buf)
+		last := records[len(records)-1]
+		if last["level"] != "WARN" || last[LogKeyID] != "Hello, {name}!" {
+			t.Errorf("record = %v, want WARN with message id", last)
+		}
+	})
+}
+
+func TestHandlerLogFunc(t *testing.T) {
+	var buf bytes.Buffer
+	log := HandlerLogFunc(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	log("[ERROR] Error loading translation zh.json")
+	log("[DEBUG] Loaded 3 messages")
+	log("Loading translations")
+
+	records := decodeRecords(t, &buf)
+	expected := []struct{ level, msg string }{
+		{"ERROR", "Error loading translation zh.json"},
+		{"DEBUG", "Loaded 3 messages"},
+		{"INFO", "Loading translations"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("got %d records, want %d", len(records), len(expected))
+	}
+	for i, e := range expected {
+		if records[i]["level"] != e.level || records[i]["msg"] != e.msg {
+			t.Errorf("record %d = %v, want %s %q", i, records[i], e.level, e.msg)
+		}
+	}
+}
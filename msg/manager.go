@@ -3,8 +3,14 @@ package msg
 import (
 	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 )
 
 // LogFunc 定义日志函数类型
@@ -23,7 +29,7 @@ type LogFunc func(string)
 //
 // Manager 是国际化的核心组件，负责：
 // 1. 管理当前语言设置
-// 2. 缓存和重用 Printer 实例
+// 2. 缓存和重用 Printer 实例（按工厂和语言的 LRU 缓存）
 // 3. 提供语言降级匹配机制
 // 4. 支持上下文相关的语言切换
 //
@@ -43,10 +49,16 @@ type LogFunc func(string)
 //	// 获取特定语言的打印机
 //	printer := manager.GetPrinter(msg.Chinese)
 type Manager struct {
-	mu      sync.RWMutex          // 读写锁，保护并发访问
-	locale  Locale                // 当前语言设置
-	logFunc LogFunc               // 日志函数
-	factory *simplePrinterFactory // 内部打印机工厂
+	mu         sync.RWMutex          // 读写锁，保护并发访问
+	locale     Locale                // 当前语言设置
+	logFunc    LogFunc               // 日志函数
+	logHandler slog.Handler          // 结构化日志处理器
+	factory    *simplePrinterFactory // 内部打印机工厂
+	cache      *printerCache         // GetPrinterWithContext 的 Printer 缓存，为 nil 时不缓存
+	resolve    ResolveTable          // WithLocale 使用的语言解析规则
+	telemetry  Telemetry             // 翻译查找指标，为 nil 时不记录
+	reporting  []PrinterFactory      // 已将 telemetry 添加为缺失翻译报告器的工厂
+	onError    PrinterErrorHandler   // 工厂创建 Printer 失败时的处理方式
 }
 
 // ManagerConfig 管理器配置选项，用于创建 Manager 实例。
@@ -60,6 +72,10 @@ type ManagerConfig struct {
 	// 可以自定义日志格式，比如添加时间戳、日志级别等
 	LogFunc LogFunc
 
+	// LogHandler 结构化日志处理器（可选），可以与 LogFunc 同时使用。
+	// 日志记录带有级别和 locale、id 等属性，来源为调用 Manager 的代码位置
+	LogHandler slog.Handler
+
 	// Factory 驱动工厂，如果为 nil 则使用默认的 FmtPrinterFactory
 	// 支持自定义的打印机实现，比如支持 i18n 的打印机
 	Factory PrinterFactory
@@ -68,6 +84,74 @@ type ManagerConfig struct {
 	// 如果为空则使用英语 "en"
 	// 用于在没有指定语言时提供默认的本地化支持
 	Locale Locale
+
+	// CacheSize GetPrinterWithContext 按工厂和语言缓存的 Printer 数量，
+	// 为 0 时使用 DefaultPrinterCacheSize，小于 0 时禁用缓存
+	CacheSize int
+
+	// ResolveTable WithLocale 将通用语言解析为具体语言的规则，如 pt → pt-BR、es → es-419
+	// 为 nil 时使用 DefaultResolveTable，为空表时不做任何解析
+	ResolveTable ResolveTable
+
+	// EnableTelemetry 记录 Printer 获取次数、缓存命中、回退和获取耗时等指标
+	EnableTelemetry bool
+
+	// Telemetry 接收指标的实现，启用指标且为 nil 时使用 NewTelemetryCounters 创建的内存统计，
+	// 使用 OpenTelemetry 时为 NewOTelTelemetry 创建的实例
+	Telemetry Telemetry
+
+	// OnPrinterError 工厂无法创建 Printer 时的处理方式，为 nil 时使用 FallbackOnPrinterError，
+	// 可以使用 PanicOnPrinterError 在开发环境尽早暴露缺失的翻译数据
+	OnPrinterError PrinterErrorHandler
+}
+
+// PrinterErrorHandler 处理工厂无法创建 locale 的 Printer 的情况，返回代替使用的 Printer，
+// 返回 nil 时使用 fmt Printer。用于 GetPrinter、GetPrinterWithContext 等不返回错误的方法，
+// 需要自行处理错误时使用 GetPrinterE 或 AcquirePrinter。
+//
+// 示例：
+//
+//	manager := msg.NewManager(msg.ManagerConfig{
+//	    OnPrinterError: func(locale msg.Locale, err error) msg.Printer {
+//	        alert.Notify("printer unavailable", locale, err)
+//	        return msg.NewPrinter(locale)
+//	    },
+//	})
+type PrinterErrorHandler func(locale Locale, err error) Printer
+
+// FallbackOnPrinterError 是默认的 PrinterErrorHandler，返回只做格式化的 fmt Printer
+func FallbackOnPrinterError(locale Locale, err error) Printer {
+	return NewPrinter(locale)
+}
+
+// PanicOnPrinterError 是发生错误时 panic 的 PrinterErrorHandler
+func PanicOnPrinterError(locale Locale, err error) Printer {
+	panic(fmt.Sprintf("msg: failed to create printer for %q: %v", locale, err))
+}
+
+// ResolveTable 将通用语言映射为部署时默认使用的具体语言环境。
+//
+// 键为请求的语言，值为解析后的语言，只有完全相同的语言才会被替换：
+//
+//	msg.ResolveTable{
+//	    msg.Chinese:    "zh-Hans-CN",
+//	    msg.Portuguese: "pt-BR",
+//	    msg.Spanish:    "es-419",
+//	}
+type ResolveTable map[Locale]Locale
+
+// DefaultResolveTable 未配置 ResolveTable 时使用的解析规则，
+// 将通用中文 zh 映射为最常用的 zh-Hans-CN
+var DefaultResolveTable = ResolveTable{
+	Chinese: "zh-Hans-CN",
+}
+
+// Resolve 返回 locale 对应的具体语言环境，没有对应规则时原样返回
+func (t ResolveTable) Resolve(locale Locale) Locale {
+	if resolved, ok := t[locale]; ok {
+		return resolved
+	}
+	return locale
 }
 
 // NewManager 创建新的管理器实例。
@@ -90,47 +174,101 @@ func NewManager(config ManagerConfig) *Manager {
 		factory = NewPrinterFactory(config.Factory).(*simplePrinterFactory)
 	}
 
+	resolve := config.ResolveTable
+	if resolve == nil {
+		resolve = DefaultResolveTable
+	}
+
 	m := &Manager{
-		locale:  cmp.Or(config.Locale, English), // 使用配置的语言或默认英语
-		logFunc: config.LogFunc,
-		factory: factory,
+		locale:     cmp.Or(config.Locale, English), // 使用配置的语言或默认英语
+		logFunc:    config.LogFunc,
+		logHandler: config.LogHandler,
+		factory:    factory,
+		resolve:    maps.Clone(resolve), // 复制一份，避免调用方修改影响 Manager
+		onError:    config.OnPrinterError,
+	}
+	if m.onError == nil {
+		m.onError = FallbackOnPrinterError
+	}
+	if config.EnableTelemetry {
+		m.telemetry = config.Telemetry
+		if m.telemetry == nil {
+			m.telemetry = NewTelemetryCounters()
+		}
+	}
+	if config.CacheSize >= 0 {
+		m.cache = newPrinterCache(cmp.Or(config.CacheSize, DefaultPrinterCacheSize))
 	}
 	m.factory.SetFallbackLocale(m.locale)
+	m.reportMissing(config.Factory)
 	return m
 }
 
-// resolveLocale 将通用语言解析为更具体的默认语言环境
+// reportMissing 将 telemetry 添加为 factory 的缺失翻译报告器，未启用指标、factory 没有实现
+// MissingReporterAdder 或已经添加过时不做任何事。调用方需要持有写锁或尚未发布 m
+func (m *Manager) reportMissing(factory PrinterFactory) {
+	adder, ok := factory.(MissingReporterAdder)
+	if !ok || m.telemetry == nil || slices.Contains(m.reporting, factory) {
+		return
+	}
+	adder.AddMissingReporter(&telemetryMissing{telemetry: m.telemetry, factory: factory})
+	m.reporting = append(m.reporting, factory)
+}
+
+// Clone 返回与 m 配置相同的新 Manager，主要用于测试：
+// 在副本上调用 SetLocale、SetPrinterFactory 和内置实现的 SetMessage 不会影响 m。
 //
-// 此方法处理常见的语言映射，将通用语言代码转换为更具体的默认形式：
-// - zh (中文) -> zh-Hans-CN (简体中文-中国大陆)
+// 副本有独立的 Printer 缓存；内置实现覆盖的消息被复制，自定义工厂（如 xtext.PrinterFactory）
+// 则与 m 共享，通过 SetMessage 覆盖的消息对两者都可见。
+// 使用默认 TelemetryCounters 时副本重新计数，配置的其他 Telemetry 实现与 m 共享；
+// 与 m 共享的工厂报告的缺失翻译只计入 m 的 Telemetry。
 //
-// 参数 locale: 要解析的语言环境
-// 返回: 解析后的具体语言环境
-func (m *Manager) resolveLocale(locale Locale) Locale {
-	// 目前只处理中文的特殊映射
-	// zh 是通用中文，应该映射到最常用的 zh-Hans-CN
-	if locale == Chinese {
-		return Locale("zh-Hans-CN")
-	}
+// 使用示例：
+//
+//	func TestCheckout(t *testing.T) {
+//	    m := manager.Clone()
+//	    m.SetLocale(msg.Chinese)
+//	    // ...
+//	}
+func (m *Manager) Clone() *Manager {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	// 其他语言暂时保持原样，可以根据需要添加更多映射
-	return locale
+	c := &Manager{
+		locale:     m.locale,
+		logFunc:    m.logFunc,
+		logHandler: m.logHandler,
+		factory:    m.factory.clone(),
+		resolve:    maps.Clone(m.resolve),
+		telemetry:  m.telemetry,
+		onError:    m.onError,
+	}
+	if _, ok := m.telemetry.(*TelemetryCounters); ok {
+		c.telemetry = NewTelemetryCounters()
+	}
+	if m.cache != nil {
+		c.cache = newPrinterCache(m.cache.size)
+	}
+	return c
 }
 
-// log 记录日志的内部方法
-func (m *Manager) log(message string) {
-	if m.logFunc != nil {
-		m.logFunc(message)
-	}
+// resolveLocale 按 ManagerConfig.ResolveTable 将通用语言解析为更具体的默认语言环境，
+// 默认将 zh (中文) 解析为 zh-Hans-CN (简体中文-中国大陆)
+//
+// 参数 locale: 要解析的语言环境
+// 返回: 解析后的具体语言环境
+func (m *Manager) resolveLocale(locale Locale) Locale {
+	return m.resolve.Resolve(locale)
 }
 
 // SetLocale 设置当前语言环境
 func (m *Manager) SetLocale(locale Locale) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.log("[INFO] Setting locale: " + string(locale))
+	m.log(context.Background(), slog.LevelInfo, "Setting locale", slog.String(LogKeyLocale, string(locale)))
 	m.locale = locale
 	m.factory.SetFallbackLocale(locale)
+	m.ResetPrinterCache()
 }
 
 // GetLocale 获取当前语言环境
@@ -165,8 +303,37 @@ func (m *Manager) SupportsLocale(locale Locale) bool {
 }
 
 // GetPrinter 获取指定语言的 Printer
-// 如果没有提供语言参数，返回当前语言的 Printer
+// 如果没有提供语言参数，返回当前语言的 Printer。
+// 工厂无法创建 Printer 时按 ManagerConfig.OnPrinterError 处理，默认使用 fmt Printer
 func (m *Manager) GetPrinter(locale ...Locale) Printer {
+	printer, targetLocale, err := m.createPrinter(locale)
+	if err != nil {
+		m.log(context.Background(), slog.LevelError, "Failed to create printer, using fallback printer",
+			slog.String(LogKeyLocale, string(targetLocale)), slog.Any("error", err))
+		return m.handlePrinterError(targetLocale, err)
+	}
+	return printer
+}
+
+// GetPrinterE 与 GetPrinter 相同，但工厂无法创建 Printer 时返回 nil 和错误，
+// 不使用 ManagerConfig.OnPrinterError 处理
+//
+// 示例：
+//
+//	p, err := manager.GetPrinterE(msg.Locale("ja"))
+//	if err != nil {
+//	    return fmt.Errorf("load translations: %w", err)
+//	}
+func (m *Manager) GetPrinterE(locale ...Locale) (Printer, error) {
+	printer, _, err := m.createPrinter(locale)
+	if err != nil {
+		return nil, err
+	}
+	return printer, nil
+}
+
+// createPrinter 使用工厂创建指定语言的 Printer，没有提供语言时使用当前语言
+func (m *Manager) createPrinter(locale []Locale) (Printer, Locale, error) {
 	var targetLocale Locale
 
 	if len(locale) == 0 {
@@ -184,14 +351,28 @@ func (m *Manager) GetPrinter(locale ...Locale) Printer {
 	factory := m.factory
 	m.mu.RUnlock()
 
+	if m.telemetry == nil {
+		printer, err := factory.CreatePrinter(targetLocale)
+		return printer, targetLocale, err
+	}
+
+	start := time.Now()
 	printer, err := factory.CreatePrinter(targetLocale)
-	if err != nil {
-		// 如果创建失败，使用简单的 Printer 作为后备
-		m.log("[ERROR] failed to create printer for locale " + string(targetLocale) + ": " + err.Error() + ", using fallback fmt printer")
-		return NewPrinter(targetLocale)
+	latency := time.Since(start)
+	used := targetLocale
+	if err == nil {
+		used = printer.Locale()
 	}
+	m.telemetry.RecordLookup(telemetryLocale(factory, used), false, latency)
+	return printer, targetLocale, err
+}
 
-	return printer
+// handlePrinterError 返回 OnPrinterError 代替使用的 Printer
+func (m *Manager) handlePrinterError(locale Locale, err error) Printer {
+	if printer := m.onError(locale, err); printer != nil {
+		return printer
+	}
+	return NewPrinter(locale)
 }
 
 // SetPrinterFactory 设置新的驱动工厂
@@ -199,9 +380,21 @@ func (m *Manager) SetPrinterFactory(factory PrinterFactory) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.log("[INFO] Setting new PrinterFactory")
+	m.log(context.Background(), slog.LevelInfo, "Setting new PrinterFactory")
 	m.factory.SetCustom(factory)
 	m.factory.SetFallbackLocale(m.locale)
+	m.reportMissing(factory)
+	m.ResetPrinterCache()
+}
+
+// ResetPrinterCache 清空 GetPrinterWithContext 的 Printer 缓存。
+//
+// SetLocale 和 SetPrinterFactory 会自动清空缓存。工厂的翻译数据重新加载后
+// （如调用 xtext.PrinterFactory.Reset），需要调用此方法才能使用新的 Printer。
+func (m *Manager) ResetPrinterCache() {
+	if m.cache != nil {
+		m.cache.purge()
+	}
 }
 
 // GetPrinterFactory 获取当前的驱动工厂
@@ -213,13 +406,13 @@ func (m *Manager) GetPrinterFactory() PrinterFactory {
 
 // findSuitableFactory 查找支持指定语言的工厂
 // 按优先级检查：contextFactory -> managerFactory -> fallback
-func (m *Manager) findSuitableFactory(locale Locale, contextFactory PrinterFactory, usingContext bool) (PrinterFactory, Locale) {
+func (m *Manager) findSuitableFactory(ctx context.Context, locale Locale, contextFactory PrinterFactory, usingContext bool) (PrinterFactory, Locale) {
 	// 检查当前工厂是否支持语言
 	if checkFactorySupport(contextFactory, locale) {
 		if usingContext {
-			m.log("[DEBUG] Context factory supports requested locale: " + string(locale))
+			m.log(ctx, slog.LevelDebug, "Context factory supports requested locale", slog.String(LogKeyLocale, string(locale)))
 		} else {
-			m.log("[DEBUG] Manager factory supports requested locale: " + string(locale))
+			m.log(ctx, slog.LevelDebug, "Manager factory supports requested locale", slog.String(LogKeyLocale, string(locale)))
 		}
 		return contextFactory, locale
 	}
@@ -231,7 +424,7 @@ func (m *Manager) findSuitableFactory(locale Locale, contextFactory PrinterFacto
 		m.mu.RUnlock()
 
 		if checkFactorySupport(managerFactory, locale) {
-			m.log("[INFO] Manager factory supports requested locale: " + string(locale))
+			m.log(ctx, slog.LevelInfo, "Manager factory supports requested locale", slog.String(LogKeyLocale, string(locale)))
 			return managerFactory, locale
 		}
 	}
@@ -240,7 +433,11 @@ func (m *Manager) findSuitableFactory(locale Locale, contextFactory PrinterFacto
 	m.mu.RLock()
 	defaultLocale := m.locale
 	m.mu.RUnlock()
-	m.log("[WARN] Neither context nor Manager factory supports locale, using fallback: " + string(locale) + " -> " + string(defaultLocale))
+	m.log(ctx, slog.LevelWarn, "Neither context nor Manager factory supports locale, using fallback",
+		slog.String(LogKeyLocale, string(locale)), slog.String("fallback", string(defaultLocale)))
+	if m.telemetry != nil {
+		m.telemetry.RecordFallback(locale, telemetryLocale(contextFactory, defaultLocale))
+	}
 	return contextFactory, defaultLocale
 }
 
@@ -320,6 +517,21 @@ func (m *Manager) WithLocale(locale Locale, fn func(Printer)) {
 	fn(m.GetPrinter(resolvedLocale))
 }
 
+// RunWithLocale 使用携带 locale 的上下文执行 fn，不改变 Manager 的当前语言。
+//
+// SetLocale 修改的是所有请求共享的状态，并发的请求各自调用 SetLocale 会互相覆盖；
+// 需要在一段代码中使用某种语言时，应在 fn 中通过传入的 ctx 获取 Printer，如 GetPrinterWithContext(ctx)。
+// locale 按 ResolveTable 解析，ctx 的取消、截止时间和工厂等其他值保持不变。
+//
+// 使用示例：
+//
+//	manager.RunWithLocale(ctx, msg.Chinese, func(ctx context.Context) {
+//	    sendEmail(ctx, manager.SprintfWithContext(ctx, "Welcome, %s!", name))
+//	})
+func (m *Manager) RunWithLocale(ctx context.Context, locale Locale, fn func(ctx context.Context)) {
+	fn(WithLocaleContext(ctx, m.resolveLocale(locale)))
+}
+
 // LocaleFromContext 从上下文中获取语言
 // 如果上下文中没有语言信息，返回当前语言
 func (m *Manager) LocaleFromContext(ctx context.Context) Locale {
@@ -332,12 +544,47 @@ func (m *Manager) LocaleFromContext(ctx context.Context) Locale {
 	return m.GetLocale()
 }
 
-// GetPrinterWithContext 获取基于上下文语言和驱动的临时 Printer
+// GetPrinterWithContext 获取基于上下文语言和驱动的 Printer
 // 优先使用上下文中的 PrinterFactory，如果不支持则尝试 Manager 的默认 PrinterFactory，
-// 最后才使用 fallback 策略
+// 最后才使用 fallback 策略。解析结果按工厂和语言缓存，相同的请求直接复用 Printer。
+// 创建 Printer 时遵守 ctx 的取消和截止时间，详见 AcquirePrinter
 func (m *Manager) GetPrinterWithContext(ctx context.Context) Printer {
+	printer, _ := m.AcquirePrinter(ctx)
+	return printer
+}
+
+// AcquirePrinter 与 GetPrinterWithContext 相同，但同时返回获取 Printer 时遇到的错误。
+//
+// 创建 Printer 可能需要加载翻译数据（如从远程或数据库翻译源），ctx 被取消或超过截止时间时不再等待，
+// 返回回退的 Printer 和 ctx.Err()：优先使用缓存中 Manager 当前语言的 Printer，
+// 没有时使用只做格式化的 fmt Printer。工厂创建失败时返回 ManagerConfig.OnPrinterError 给出的 Printer
+// （默认为 fmt Printer）和错误。
+// 两种情况下返回的 Printer 都不会被缓存，之后的请求会重新尝试。
+//
+// 示例：
+//
+//	ctx, cancel := context.WithTimeout(r.Context(), 50*time.Millisecond)
+//	defer cancel()
+//	p, err := manager.AcquirePrinter(ctx)
+//	if err != nil {
+//	    log.Printf("using fallback printer: %v", err)
+//	}
+func (m *Manager) AcquirePrinter(ctx context.Context) (Printer, error) {
+	if m.telemetry == nil {
+		printer, _, _, err := m.printerWithContext(ctx)
+		return printer, err
+	}
+
+	start := time.Now()
+	printer, factory, hit, err := m.printerWithContext(ctx)
+	latency := time.Since(start)
+	m.telemetry.RecordLookup(telemetryLocale(factory, printer.Locale()), hit, latency)
+	return printer, err
+}
+
+// printerWithContext 实现 AcquirePrinter，同时返回创建 Printer 的工厂和是否命中缓存
+func (m *Manager) printerWithContext(ctx context.Context) (Printer, PrinterFactory, bool, error) {
 	locale := m.LocaleFromContext(ctx)
-	m.log("[INFO] Creating printer from context for locale: " + string(locale))
 
 	// 获取上下文中的 PrinterFactory
 	var factory PrinterFactory
@@ -345,24 +592,62 @@ func (m *Manager) GetPrinterWithContext(ctx context.Context) Printer {
 	if ctxFactory, ok := GetPrinterFactoryFromContext(ctx); ok {
 		factory = ctxFactory
 		usingContext = true
-		m.log("[DEBUG] Using PrinterFactory from context")
 	} else {
 		m.mu.RLock()
 		factory = m.factory
 		m.mu.RUnlock()
 	}
 
+	key := printerKey{factory: factory, locale: locale}
+	var gen uint64
+	cached := m.cache != nil && cacheable(factory)
+	if cached {
+		printer, g, ok := m.cache.get(key)
+		if ok {
+			return printer, factory, true, nil
+		}
+		gen = g
+	}
+
+	m.log(ctx, slog.LevelInfo, "Creating printer from context", slog.String(LogKeyLocale, string(locale)))
+	if usingContext {
+		m.log(ctx, slog.LevelDebug, "Using PrinterFactory from context")
+	}
+
 	// 查找合适的工厂和目标语言
-	finalFactory, targetLocale := m.findSuitableFactory(locale, factory, usingContext)
+	finalFactory, targetLocale := m.findSuitableFactory(ctx, locale, factory, usingContext)
 
-	// 创建临时 Printer（不缓存到 Manager 中）
-	printer, err := finalFactory.CreatePrinter(targetLocale)
+	printer, err := createPrinterContext(ctx, finalFactory, targetLocale)
+	if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		m.log(ctx, slog.LevelWarn, "Printer acquisition interrupted, using fallback printer",
+			slog.String(LogKeyLocale, string(targetLocale)), slog.Any("error", err))
+		return m.fallbackPrinter(factory, targetLocale), factory, false, err
+	}
 	if err != nil {
-		m.log("[ERROR] failed to create printer for locale " + string(targetLocale) + ": " + err.Error() + ", using fallback fmt printer")
-		printer = NewPrinter(targetLocale)
+		// 创建失败时不缓存，下次请求重新尝试
+		m.log(ctx, slog.LevelError, "Failed to create printer, using fallback printer",
+			slog.String(LogKeyLocale, string(targetLocale)), slog.Any("error", err))
+		return m.handlePrinterError(targetLocale, err), finalFactory, false, err
 	}
 
-	return printer
+	if cached {
+		m.cache.add(key, printer, gen)
+	}
+	return printer, finalFactory, false, nil
+}
+
+// fallbackPrinter 返回没能及时创建 Printer 时使用的 Printer：
+// 缓存中 factory 下 Manager 当前语言的 Printer，没有时使用 locale 的 fmt Printer
+func (m *Manager) fallbackPrinter(factory PrinterFactory, locale Locale) Printer {
+	if m.cache != nil {
+		m.mu.RLock()
+		current := m.locale
+		m.mu.RUnlock()
+		if printer, _, ok := m.cache.get(printerKey{factory: factory, locale: current}); ok {
+			return printer
+		}
+	}
+	return NewPrinter(locale)
 }
 
 // WithContext 使用上下文中的语言信息执行函数，不改变 Manager 的当前语言状态
@@ -1,9 +1,12 @@
 package msg
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewManager(t *testing.T) {
@@ -182,6 +185,50 @@ func TestManagerUtilityMethods(t *testing.T) {
 	})
 }
 
+func TestManager_ResolveTable(t *testing.T) {
+	resolved := func(m *Manager, locale Locale) Locale {
+		var got Locale
+		m.WithLocale(locale, func(p Printer) { got = p.Locale() })
+		return got
+	}
+
+	t.Run("Default table", func(t *testing.T) {
+		m := NewManager(ManagerConfig{})
+		if got := resolved(m, Chinese); got != "zh-Hans-CN" {
+			t.Errorf("WithLocale(zh) locale = %q, want %q", got, "zh-Hans-CN")
+		}
+		if got := resolved(m, Portuguese); got != Portuguese {
+			t.Errorf("WithLocale(pt) locale = %q, want %q", got, Portuguese)
+		}
+	})
+
+	t.Run("Custom table", func(t *testing.T) {
+		table := ResolveTable{Portuguese: "pt-BR", Spanish: "es-419"}
+		m := NewManager(ManagerConfig{ResolveTable: table})
+		table[French] = FrenchFR // 创建后修改不影响 Manager
+
+		tests := map[Locale]Locale{
+			Portuguese: "pt-BR",
+			Spanish:    "es-419",
+			"es-ES":    "es-ES",
+			Chinese:    Chinese,
+			French:     French,
+		}
+		for locale, expected := range tests {
+			if got := resolved(m, locale); got != expected {
+				t.Errorf("WithLocale(%q) locale = %q, want %q", locale, got, expected)
+			}
+		}
+	})
+
+	t.Run("Empty table disables resolution", func(t *testing.T) {
+		m := NewManager(ManagerConfig{ResolveTable: ResolveTable{}})
+		if got := resolved(m, Chinese); got != Chinese {
+			t.Errorf("WithLocale(zh) locale = %q, want %q", got, Chinese)
+		}
+	})
+}
+
 // ExampleManager 展示简化后的 Manager 使用方法
 func ExampleManager() {
 	// 创建管理器
@@ -242,3 +289,246 @@ func TestManager_Simple(t *testing.T) {
 		}
 	})
 }
+
+// countingFactory 记录 CreatePrinter 的调用次数
+type countingFactory struct {
+	PrinterFactory
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *countingFactory) CreatePrinter(locale Locale) (Printer, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	return f.PrinterFactory.CreatePrinter(locale)
+}
+
+func TestManager_PrinterCache(t *testing.T) {
+	factory := &countingFactory{PrinterFactory: NewPrinterFactory()}
+	manager := NewManager(ManagerConfig{LogFunc: func(string) {}})
+	ctx := WithLocaleAndPrinterFactoryContext(context.Background(), Chinese, factory)
+
+	first := manager.GetPrinterWithContext(ctx)
+	second := manager.GetPrinterWithContext(ctx)
+	if first != second {
+		t.Error("GetPrinterWithContext returned different printers for the same request")
+	}
+	if factory.calls != 1 {
+		t.Errorf("CreatePrinter calls = %d, want 1", factory.calls)
+	}
+
+	manager.SetLocale(English)
+	manager.GetPrinterWithContext(ctx)
+	if factory.calls != 2 {
+		t.Errorf("CreatePrinter calls after SetLocale = %d, want 2", factory.calls)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		factory := &countingFactory{PrinterFactory: NewPrinterFactory()}
+		manager := NewManager(ManagerConfig{LogFunc: func(string) {}, CacheSize: -1})
+		ctx := WithLocaleAndPrinterFactoryContext(context.Background(), Chinese, factory)
+
+		manager.GetPrinterWithContext(ctx)
+		manager.GetPrinterWithContext(ctx)
+		if factory.calls != 2 {
+			t.Errorf("CreatePrinter calls = %d, want 2", factory.calls)
+		}
+	})
+}
+
+func TestManager_Clone(t *testing.T) {
+	manager := NewManager(ManagerConfig{Locale: Chinese, LogFunc: func(string) {}, EnableTelemetry: true})
+	_ = manager.SetMessage(Chinese, "Hello", "你好")
+
+	clone := manager.Clone()
+	if clone.GetLocale() != Chinese {
+		t.Errorf("clone GetLocale() = %q, want %q", clone.GetLocale(), Chinese)
+	}
+	if got := clone.Sprintf("Hello"); got != "你好" {
+		t.Errorf("clone Sprintf() = %q, want %q", got, "你好")
+	}
+
+	clone.SetLocale(English)
+	_ = clone.SetMessage(Chinese, "Hello", "您好")
+	if manager.GetLocale() != Chinese {
+		t.Errorf("GetLocale() after clone.SetLocale = %q, want %q", manager.GetLocale(), Chinese)
+	}
+	if got := manager.Sprintf("Hello"); got != "你好" {
+		t.Errorf("Sprintf() after clone.SetMessage = %q, want %q", got, "你好")
+	}
+
+	if clone.Telemetry() == manager.Telemetry() {
+		t.Error("clone shares the default TelemetryCounters")
+	}
+	clone.GetPrinterWithContext(context.Background())
+	if got := manager.Telemetry().(*TelemetryCounters).Snapshot(); len(got) != 1 || got[0].Locale != Chinese {
+		t.Errorf("original telemetry = %v, want only the lookups of the original", got)
+	}
+}
+
+// blockingFactory 创建 block 语言的 Printer 时等待 release 关闭，模拟加载缓慢的翻译源
+type blockingFactory struct {
+	PrinterFactory
+	block   Locale
+	release chan struct{}
+}
+
+func (f *blockingFactory) CreatePrinter(locale Locale) (Printer, error) {
+	if locale.Equal(f.block) {
+		<-f.release
+	}
+	return f.PrinterFactory.CreatePrinter(locale)
+}
+
+func TestManager_AcquirePrinter(t *testing.T) {
+	factory := &blockingFactory{PrinterFactory: NewPrinterFactory(), block: Japanese, release: make(chan struct{})}
+	manager := NewManager(ManagerConfig{Locale: English, LogFunc: func(string) {}})
+
+	en, err := manager.AcquirePrinter(WithLocaleAndPrinterFactoryContext(context.Background(), English, factory))
+	if err != nil {
+		t.Fatalf("AcquirePrinter(en) error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(WithLocaleAndPrinterFactoryContext(context.Background(), Japanese, factory), 10*time.Millisecond)
+	defer cancel()
+	printer, err := manager.AcquirePrinter(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AcquirePrinter(ja) error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if printer != en {
+		t.Errorf("AcquirePrinter(ja) = %v, want cached printer of the current locale", printer.Locale())
+	}
+	if got := manager.GetPrinterWithContext(ctx); got != en {
+		t.Errorf("GetPrinterWithContext(ja) = %v, want cached printer of the current locale", got.Locale())
+	}
+
+	close(factory.release)
+	printer, err = manager.AcquirePrinter(WithLocaleAndPrinterFactoryContext(context.Background(), Japanese, factory))
+	if err != nil {
+		t.Fatalf("AcquirePrinter(ja) after release error = %v", err)
+	}
+	if !printer.Locale().Equal(Japanese) {
+		t.Errorf("AcquirePrinter(ja) after release locale = %q, want %q", printer.Locale(), Japanese)
+	}
+
+	t.Run("Factory error", func(t *testing.T) {
+		wantErr := errors.New("boom")
+		factory := &failingFactory{PrinterFactory: NewPrinterFactory(), err: wantErr}
+		printer, err := manager.AcquirePrinter(WithLocaleAndPrinterFactoryContext(context.Background(), Chinese, factory))
+		if !errors.Is(err, wantErr) {
+			t.Errorf("AcquirePrinter() error = %v, want %v", err, wantErr)
+		}
+		if printer == nil || !printer.Locale().Equal(Chinese) {
+			t.Errorf("AcquirePrinter() = %v, want fmt printer for %q", printer, Chinese)
+		}
+	})
+}
+
+func TestManager_OnPrinterError(t *testing.T) {
+	wantErr := errors.New("boom")
+	factory := &failingFactory{PrinterFactory: NewPrinterFactory(), err: wantErr}
+
+	t.Run("Fallback by default", func(t *testing.T) {
+		manager := NewManager(ManagerConfig{Factory: factory})
+		if p := manager.GetPrinter(Chinese); p == nil || !p.Locale().Equal(Chinese) {
+			t.Errorf("GetPrinter() = %v, want fmt printer for %q", p, Chinese)
+		}
+	})
+
+	t.Run("GetPrinterE", func(t *testing.T) {
+		manager := NewManager(ManagerConfig{Factory: factory})
+		p, err := manager.GetPrinterE(Chinese)
+		if !errors.Is(err, wantErr) || p != nil {
+			t.Errorf("GetPrinterE() = %v, %v, want nil, %v", p, err, wantErr)
+		}
+		if p, err := NewManager(ManagerConfig{}).GetPrinterE(); err != nil || !p.Locale().Equal(English) {
+			t.Errorf("GetPrinterE() = %v, %v, want printer for %q", p, err, English)
+		}
+	})
+
+	t.Run("Custom handler", func(t *testing.T) {
+		custom := NewPrinter(English)
+		var gotLocale Locale
+		var gotErr error
+		manager := NewManager(ManagerConfig{
+			Factory: factory,
+			OnPrinterError: func(locale Locale, err error) Printer {
+				gotLocale, gotErr = locale, err
+				return custom
+			},
+		})
+		if p := manager.GetPrinter(Japanese); p != custom {
+			t.Errorf("GetPrinter() = %v, want printer returned by the handler", p)
+		}
+		if gotLocale != Japanese || !errors.Is(gotErr, wantErr) {
+			t.Errorf("handler called with %q, %v, want %q, %v", gotLocale, gotErr, Japanese, wantErr)
+		}
+		p, err := manager.AcquirePrinter(WithLocaleContext(context.Background(), Chinese))
+		if p != custom || !errors.Is(err, wantErr) {
+			t.Errorf("AcquirePrinter() = %v, %v, want handler printer and %v", p, err, wantErr)
+		}
+		if p := manager.Clone().GetPrinter(); p != custom {
+			t.Errorf("Clone().GetPrinter() = %v, want printer returned by the handler", p)
+		}
+	})
+
+	t.Run("Nil handler result", func(t *testing.T) {
+		manager := NewManager(ManagerConfig{
+			Factory:        factory,
+			OnPrinterError: func(Locale, error) Printer { return nil },
+		})
+		if p := manager.GetPrinter(Chinese); p == nil || !p.Locale().Equal(Chinese) {
+			t.Errorf("GetPrinter() = %v, want fmt printer for %q", p, Chinese)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		manager := NewManager(ManagerConfig{Factory: factory, OnPrinterError: PanicOnPrinterError})
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("GetPrinter() should panic")
+			}
+		}()
+		manager.GetPrinter(Chinese)
+	})
+}
+
+// failingFactory 创建 Printer 总是失败
+type failingFactory struct {
+	PrinterFactory
+	err error
+}
+
+func (f *failingFactory) CreatePrinter(Locale) (Printer, error) {
+	return nil, f.err
+}
+
+func BenchmarkManager_GetPrinterWithContext(b *testing.B) {
+	locales := []Locale{English, Chinese, Japanese, Locale("zh-TW"), Locale("en-GB"), Locale("fr")}
+
+	for _, bc := range []struct {
+		name string
+		size int
+	}{
+		{"Cached", 0},
+		{"Uncached", -1},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			manager := NewManager(ManagerConfig{LogFunc: func(string) {}, CacheSize: bc.size})
+			ctxs := make([]context.Context, len(locales))
+			for i, l := range locales {
+				ctxs[i] = WithLocaleContext(context.Background(), l)
+			}
+
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					manager.GetPrinterWithContext(ctxs[i%len(ctxs)])
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package msg
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// MissingReporter 接收缺失翻译的报告。
+//
+// 当 Printer 在语言及其回退链中都找不到消息的译文、只能输出原文时，
+// 会调用 ReportMissing。实现需要是并发安全的，并且应当尽快返回，
+// 耗时的处理（如写入数据库）应在后台进行。
+//
+// xtext.PrinterFactory 通过 xtext.Missing 选项或 AddMissingReporter 配置报告器。
+type MissingReporter interface {
+	ReportMissing(locale Locale, id string)
+}
+
+// MissingReporterAdder 由能够报告缺失翻译的 PrinterFactory 实现，如 xtext.PrinterFactory。
+//
+// 启用指标的 Manager 在创建和 SetPrinterFactory 时通过 AddMissingReporter 将 Telemetry
+// 添加为工厂的报告器，缺失的翻译自动计入指标。
+type MissingReporterAdder interface {
+	AddMissingReporter(reporter MissingReporter)
+}
+
+// MissingReporterFunc 将函数适配为 MissingReporter。
+type MissingReporterFunc func(locale Locale, id string)
+
+// ReportMissing 实现 MissingReporter 接口
+func (f MissingReporterFunc) ReportMissing(locale Locale, id string) {
+	f(locale, id)
+}
+
+// FuzzyReporter 接收模糊匹配的报告，由 MissingReporter 的实现选择性地实现。
+//
+// 启用模糊匹配（如 xtext.FuzzyMatch）后，缺失的消息改用相似消息的译文时，
+// Printer 在 ReportMissing 之后调用 ReportFuzzy，标记这次输出来自模糊匹配：
+// id 为请求的消息标识，matched 为实际使用的消息标识，score 为 0 到 1 之间的相似度。
+type FuzzyReporter interface {
+	ReportFuzzy(locale Locale, id, matched string, score float64)
+}
+
+// MissingEntry 是一条缺失翻译的统计。
+type MissingEntry struct {
+	Locale Locale // 请求的语言
+	ID     string // 消息标识
+	Count  int    // 缺失的次数
+}
+
+// MissingCounter 是按 (语言, 消息) 计数的 MissingReporter，可用于在管理接口或日志中
+// 定期输出缺失的翻译。
+//
+// 使用示例：
+//
+//	counter := msg.NewMissingCounter()
+//	factory := xtext.NewPrinterFactory(xtext.Missing(counter))
+//
+//	for _, e := range counter.Entries() {
+//	    log.Printf("missing %s %q: %d", e.Locale, e.ID, e.Count)
+//	}
+type MissingCounter struct {
+	mu     sync.Mutex
+	counts map[missingKey]int
+}
+
+type missingKey struct {
+	locale Locale
+	id     string
+}
+
+// NewMissingCounter 创建 MissingCounter
+func NewMissingCounter() *MissingCounter {
+	return &MissingCounter{counts: make(map[missingKey]int)}
+}
+
+// ReportMissing 实现 MissingReporter 接口
+func (c *MissingCounter) ReportMissing(locale Locale, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[missingKey{locale, id}]++
+}
+
+// Entries 返回统计结果，按次数从多到少排列，次数相同时按语言和消息标识排列。
+func (c *MissingCounter) Entries() []MissingEntry {
+	c.mu.Lock()
+	entries := make([]MissingEntry, 0, len(c.counts))
+	for k, n := range c.counts {
+		entries = append(entries, MissingEntry{Locale: k.locale, ID: k.id, Count: n})
+	}
+	c.mu.Unlock()
+
+	slices.SortFunc(entries, func(a, b MissingEntry) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.Locale, b.Locale),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return entries
+}
+
+// Reset 清空统计结果
+func (c *MissingCounter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.counts)
+}
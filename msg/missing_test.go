@@ -0,0 +1,51 @@
+package msg
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMissingCounter(t *testing.T) {
+	c := NewMissingCounter()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			c.ReportMissing(Chinese, "hello")
+		})
+	}
+	wg.Wait()
+	c.ReportMissing(English, "bye")
+	c.ReportMissing(Chinese, "bye")
+
+	entries := c.Entries()
+	want := []MissingEntry{
+		{Locale: Chinese, ID: "hello", Count: 10},
+		{Locale: English, ID: "bye", Count: 1},
+		{Locale: Chinese, ID: "bye", Count: 1},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Entries() = %v, want %v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("Entries()[%d] = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	c.Reset()
+	if entries := c.Entries(); len(entries) != 0 {
+		t.Errorf("Entries() after Reset = %v, want none", entries)
+	}
+}
+
+func TestMissingReporterFunc(t *testing.T) {
+	var got string
+	var r MissingReporter = MissingReporterFunc(func(locale Locale, id string) {
+		got = string(locale) + ":" + id
+	})
+	r.ReportMissing(English, "hello")
+	if got != "en:hello" {
+		t.Errorf("ReportMissing() got %q, want %q", got, "en:hello")
+	}
+}
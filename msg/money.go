@@ -0,0 +1,485 @@
+package msg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MoneyFormatter 按语言环境格式化和解析金额，适合用于账单、发票等对精度有要求的场景。
+//
+// 与 NumberFormatter.FormatCurrency 不同，MoneyFormatter 不依赖 Printer 的实现：
+// 金额按十进制处理而不经过浮点运算，按货币的最小单位位数（ISO 4217，如 USD 为 2 位、
+// JPY 为 0 位、KWD 为 3 位）使用银行家舍入（四舍六入五成双），并按语言确定货币符号、
+// 符号位置和分隔符。内置英语、简体中文、繁体中文、日语、韩语、德语、法语和西班牙语的格式，
+// 其它语言使用英语格式。
+//
+// 使用示例：
+//
+//	f := msg.NewMoneyFormatter(msg.Locale("de-DE"))
+//	f.Format(1234.5, "EUR")             // "1.234,50 €"
+//	f.FormatAccounting(-1234.5, "USD")  // "-1.234,50 $"
+//	minor, err := f.Parse("1.234,50 €", "EUR") // 123450
+type MoneyFormatter struct {
+	locale Locale
+	data   *moneyData
+}
+
+// NewMoneyFormatter 创建指定语言环境的 MoneyFormatter
+func NewMoneyFormatter(locale Locale) *MoneyFormatter {
+	return &MoneyFormatter{
+		locale: locale,
+		data:   lookupMoneyData(locale),
+	}
+}
+
+// Locale 返回格式化使用的语言环境
+func (f *MoneyFormatter) Locale() Locale {
+	return f.locale
+}
+
+// Format 格式化金额，amount 为以主单位计的整数、浮点数、十进制字符串或 fmt.Stringer
+// （如 decimal.Decimal），按货币的最小单位位数舍入。
+// 货币代码无效或金额不是数字时输出代码和未格式化的金额。
+//
+// 示例：
+//
+//	f.Format(-1234.565, "USD") // en: "-$1,234.56"，fr: "-1 234,56 $US"
+func (f *MoneyFormatter) Format(amount any, currency string) string {
+	return f.format(amount, currency, false)
+}
+
+// FormatAccounting 与 Format 相同，但负数使用会计格式，如 en 中 "($1,234.50)"，
+// 语言没有会计格式时与 Format 相同。
+func (f *MoneyFormatter) FormatAccounting(amount any, currency string) string {
+	return f.format(amount, currency, true)
+}
+
+// FormatMinor 格式化以最小单位计的金额，如 USD 的 123450 为 "$1,234.50"
+func (f *MoneyFormatter) FormatMinor(minor int64, currency string) string {
+	code, ok := normalizeCurrency(currency)
+	if !ok {
+		return fmt.Sprint(currency, " ", minor)
+	}
+	d := decimalFromMinor(minor, CurrencyDigits(code))
+	return f.formatDecimal(d, code, false)
+}
+
+// Parse 解析本地化的金额，返回以最小单位计的金额，是 Format 和 FormatAccounting 的逆操作。
+//
+// 货币符号和货币代码可以省略，也可以使用 "-" 或括号表示负数；
+// 小数位数超过货币的最小单位位数或金额超出 int64 范围时返回错误。
+//
+// 示例：
+//
+//	f := msg.NewMoneyFormatter(msg.French)
+//	f.Parse("1 234,50 €", "EUR") // 123450
+//	f.Parse("(12,00 €)", "EUR")  // -1200
+func (f *MoneyFormatter) Parse(s, currency string) (int64, error) {
+	code, ok := normalizeCurrency(currency)
+	if !ok {
+		return 0, fmt.Errorf("msg: invalid currency code %q", currency)
+	}
+
+	text := strings.TrimFunc(s, unicode.IsSpace)
+	neg := false
+	if strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")") {
+		neg = true
+		text = strings.TrimFunc(text[1:len(text)-1], unicode.IsSpace)
+	}
+	if rest, ok := cutMinus(text); ok {
+		if neg {
+			return 0, fmt.Errorf("msg: invalid money amount %q", s)
+		}
+		neg = true
+		text = rest
+	}
+	for _, token := range []string{f.symbol(code), code} {
+		if rest, ok := strings.CutPrefix(text, token); ok {
+			text = rest
+			break
+		}
+		if rest, ok := strings.CutSuffix(text, token); ok {
+			text = rest
+			break
+		}
+	}
+	text = strings.TrimFunc(text, unicode.IsSpace)
+
+	intPart, frac, _ := strings.Cut(text, f.data.decimal)
+	intPart = strings.Map(func(r rune) rune {
+		if string(r) == f.data.group || unicode.IsSpace(r) && unicode.IsSpace(firstRune(f.data.group)) {
+			return -1
+		}
+		return r
+	}, intPart)
+	d, ok := parseDecimal(intPart + "." + frac)
+	if !ok || d.neg || intPart == "" && frac == "" {
+		return 0, fmt.Errorf("msg: invalid money amount %q", s)
+	}
+	digits := CurrencyDigits(code)
+	if len(d.frac) > digits {
+		return 0, fmt.Errorf("msg: money amount %q has more than %d fraction digits for %s", s, digits, code)
+	}
+	d.neg = neg
+	return d.minor(digits)
+}
+
+// format 格式化金额，accounting 为 true 时负数使用会计格式
+func (f *MoneyFormatter) format(amount any, currency string, accounting bool) string {
+	code, ok := normalizeCurrency(currency)
+	if !ok {
+		return fmt.Sprint(currency, " ", amount)
+	}
+	d, ok := toDecimal(amount)
+	if !ok {
+		return fmt.Sprint(code, " ", amount)
+	}
+	return f.formatDecimal(d.round(CurrencyDigits(code)), code, accounting)
+}
+
+// formatDecimal 格式化已舍入到最小单位的金额
+func (f *MoneyFormatter) formatDecimal(d decimal, code string, accounting bool) string {
+	var b strings.Builder
+	b.WriteString(groupDigits(d.int, f.data.group, f.data.minGrouping))
+	if d.frac != "" {
+		b.WriteString(f.data.decimal)
+		b.WriteString(d.frac)
+	}
+	number := b.String()
+
+	symbol := f.symbol(code)
+	var s string
+	switch {
+	case f.data.suffix:
+		s = number + "\u00a0" + symbol
+	case unicode.IsLetter(lastRune(symbol)):
+		s = symbol + "\u00a0" + number
+	default:
+		s = symbol + number
+	}
+	if !d.neg {
+		return s
+	}
+	if accounting && f.data.accounting {
+		return "(" + s + ")"
+	}
+	return "-" + s
+}
+
+// symbol 返回货币在该语言中的符号，没有符号时返回货币代码
+func (f *MoneyFormatter) symbol(code string) string {
+	if s, ok := f.data.symbols[code]; ok {
+		return s
+	}
+	if s, ok := currencySymbols[code]; ok {
+		return s
+	}
+	return code
+}
+
+// FormatMoney 使用上下文中的语言格式化金额，见 MoneyFormatter.Format
+func FormatMoney(ctx context.Context, amount any, currency string) string {
+	return moneyFormatter(ctx).Format(amount, currency)
+}
+
+// FormatMoneyAccounting 使用上下文中的语言以会计格式格式化金额，见 MoneyFormatter.FormatAccounting
+func FormatMoneyAccounting(ctx context.Context, amount any, currency string) string {
+	return moneyFormatter(ctx).FormatAccounting(amount, currency)
+}
+
+// ParseMoney 使用上下文中的语言解析金额，返回以最小单位计的金额，见 MoneyFormatter.Parse
+func ParseMoney(ctx context.Context, s, currency string) (int64, error) {
+	return moneyFormatter(ctx).Parse(s, currency)
+}
+
+// RoundMoney 将以主单位计的金额按货币的最小单位位数舍入，返回以最小单位计的金额。
+//
+// 舍入使用银行家舍入（四舍六入五成双），如 USD 的 2.345 为 234，2.355 为 236；
+// 货币代码无效、金额不是数字或超出 int64 范围时返回错误。
+func RoundMoney(amount any, currency string) (int64, error) {
+	code, ok := normalizeCurrency(currency)
+	if !ok {
+		return 0, fmt.Errorf("msg: invalid currency code %q", currency)
+	}
+	d, ok := toDecimal(amount)
+	if !ok {
+		return 0, fmt.Errorf("msg: invalid money amount %v", amount)
+	}
+	digits := CurrencyDigits(code)
+	return d.round(digits).minor(digits)
+}
+
+// CurrencyDigits 返回 ISO 4217 货币的最小单位位数，如 USD 为 2，JPY 为 0，KWD 为 3，
+// 未知的货币为 2。
+func CurrencyDigits(currency string) int {
+	if digits, ok := currencyDigits[strings.ToUpper(currency)]; ok {
+		return digits
+	}
+	return 2
+}
+
+func moneyFormatter(ctx context.Context) *MoneyFormatter {
+	return NewMoneyFormatter(GetDefaultManager().LocaleFromContext(ctx))
+}
+
+// normalizeCurrency 将货币代码转换为大写，代码不是三个字母时返回 false
+func normalizeCurrency(code string) (string, bool) {
+	if len(code) != 3 {
+		return "", false
+	}
+	for i := 0; i < len(code); i++ {
+		c := code[i] | 0x20
+		if c < 'a' || c > 'z' {
+			return "", false
+		}
+	}
+	return strings.ToUpper(code), true
+}
+
+// decimal 是十进制数，整数部分和小数部分都是十进制数字
+type decimal struct {
+	neg  bool
+	int  string // 不含前导零，零为 "0"
+	frac string
+}
+
+// toDecimal 将数值类型、十进制字符串或 fmt.Stringer 转换为 decimal
+func toDecimal(v any) (decimal, bool) {
+	switch n := v.(type) {
+	case float32:
+		return parseDecimal(strconv.FormatFloat(float64(n), 'f', -1, 32))
+	case float64:
+		return parseDecimal(strconv.FormatFloat(n, 'f', -1, 64))
+	case string:
+		return parseDecimal(n)
+	case fmt.Stringer:
+		return parseDecimal(n.String())
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return parseDecimal(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return parseDecimal(strconv.FormatUint(rv.Uint(), 10))
+	}
+	return decimal{}, false
+}
+
+// parseDecimal 解析 "-1234.5" 形式的十进制数，不支持指数和分组
+func parseDecimal(s string) (decimal, bool) {
+	var d decimal
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		d.neg = true
+		s = rest
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+	intPart, frac, _ := strings.Cut(s, ".")
+	if intPart == "" && frac == "" || !isDigits(intPart) || !isDigits(frac) {
+		return decimal{}, false
+	}
+	d.int = strings.TrimLeft(intPart, "0")
+	if d.int == "" {
+		d.int = "0"
+	}
+	d.frac = frac
+	return d, true
+}
+
+// decimalFromMinor 将以最小单位计的金额转换为 decimal
+func decimalFromMinor(minor int64, digits int) decimal {
+	s := strconv.FormatInt(minor, 10)
+	d := decimal{neg: minor < 0}
+	s = strings.TrimPrefix(s, "-")
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	d.int, d.frac = s[:len(s)-digits], s[len(s)-digits:]
+	return d
+}
+
+// round 使用银行家舍入保留 digits 位小数，结果的小数部分恰好为 digits 位
+func (d decimal) round(digits int) decimal {
+	if len(d.frac) <= digits {
+		d.frac += strings.Repeat("0", digits-len(d.frac))
+		return d.normalize()
+	}
+	kept, rest := d.frac[:digits], d.frac[digits:]
+	up := rest[0] > '5' || rest[0] == '5' && strings.Trim(rest[1:], "0") != ""
+	if rest[0] == '5' && !up {
+		last := d.int[len(d.int)-1]
+		if digits > 0 {
+			last = kept[digits-1]
+		}
+		up = (last-'0')%2 == 1
+	}
+	d.frac = kept
+	if up {
+		all := []byte(d.int + kept)
+		i := len(all) - 1
+		for ; i >= 0 && all[i] == '9'; i-- {
+			all[i] = '0'
+		}
+		if i < 0 {
+			all = append([]byte{'1'}, all...)
+		} else {
+			all[i]++
+		}
+		d.int, d.frac = string(all[:len(all)-digits]), string(all[len(all)-digits:])
+	}
+	return d.normalize()
+}
+
+// normalize 去掉整数部分的前导零，零不带负号
+func (d decimal) normalize() decimal {
+	d.int = strings.TrimLeft(d.int, "0")
+	if d.int == "" {
+		d.int = "0"
+	}
+	if d.int == "0" && strings.Trim(d.frac, "0") == "" {
+		d.neg = false
+	}
+	return d
+}
+
+// minor 返回以最小单位计的金额，小数部分不足 digits 位时补零
+func (d decimal) minor(digits int) (int64, error) {
+	s := d.int + d.frac + strings.Repeat("0", digits-len(d.frac))
+	if d.neg {
+		s = "-" + s
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("msg: money amount %s is out of range", s)
+	}
+	return n, nil
+}
+
+// groupDigits 使用分组分隔符每三位分组，整数位数少于 3+minGrouping 时不分组
+func groupDigits(s, sep string, minGrouping int) string {
+	if len(s) < 3+max(minGrouping, 1) {
+		return s
+	}
+	var b strings.Builder
+	first := len(s) % 3
+	if first == 0 {
+		first = 3
+	}
+	b.WriteString(s[:first])
+	for i := first; i < len(s); i += 3 {
+		b.WriteString(sep)
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+// cutMinus 去掉开头的减号（"-" 或 U+2212）
+func cutMinus(s string) (string, bool) {
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		return strings.TrimFunc(rest, unicode.IsSpace), true
+	}
+	if rest, ok := strings.CutPrefix(s, "−"); ok {
+		return strings.TrimFunc(rest, unicode.IsSpace), true
+	}
+	return s, false
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// moneyData 某种语言的金额格式数据
+type moneyData struct {
+	decimal     string            // 小数点
+	group       string            // 分组分隔符
+	minGrouping int               // 整数部分至少有 3+minGrouping 位时才分组，如 es 中 "1234"、"12.345"
+	suffix      bool              // 货币符号在数字之后，以不换行空格分隔
+	accounting  bool              // 会计格式中负数使用括号
+	symbols     map[string]string // 该语言中与 currencySymbols 不同的货币符号
+}
+
+// lookupMoneyData 返回语言环境对应的金额格式数据，不支持的语言使用英语
+func lookupMoneyData(locale Locale) *moneyData {
+	if data, ok := moneyTables[tableLanguage(locale)]; ok {
+		return data
+	}
+	return moneyTables["en"]
+}
+
+var moneyTables = map[string]*moneyData{
+	"en": {decimal: ".", group: ",", minGrouping: 1, accounting: true},
+	"zh-Hans": {
+		decimal: ".", group: ",", minGrouping: 1, accounting: true,
+		symbols: map[string]string{"CNY": "¥", "USD": "US$", "JPY": "JP¥"},
+	},
+	"zh-Hant": {
+		decimal: ".", group: ",", minGrouping: 1, accounting: true,
+		symbols: map[string]string{"TWD": "$", "USD": "US$", "JPY": "¥"},
+	},
+	"ja": {
+		decimal: ".", group: ",", minGrouping: 1, accounting: true,
+		symbols: map[string]string{"JPY": "￥", "CNY": "元"},
+	},
+	"ko": {
+		decimal: ".", group: ",", minGrouping: 1, accounting: true,
+		symbols: map[string]string{"USD": "US$"},
+	},
+	"de": {decimal: ",", group: ".", minGrouping: 1, suffix: true},
+	"fr": {
+		decimal: ",", group: "\u202f", minGrouping: 1, suffix: true, accounting: true,
+		symbols: map[string]string{"USD": "$US", "CAD": "$CA", "AUD": "$AU", "HKD": "$HK"},
+	},
+	"es": {
+		decimal: ",", group: ".", minGrouping: 2, suffix: true,
+		symbols: map[string]string{"USD": "US$"},
+	},
+}
+
+// currencySymbols 是货币的默认符号，未列出的货币使用货币代码
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "CN¥",
+	"HKD": "HK$",
+	"TWD": "NT$",
+	"KRW": "₩",
+	"INR": "₹",
+	"CAD": "CA$",
+	"AUD": "A$",
+	"NZD": "NZ$",
+	"BRL": "R$",
+	"MXN": "MX$",
+	"ILS": "₪",
+	"VND": "₫",
+	"PHP": "₱",
+}
+
+// currencyDigits 是最小单位位数不为 2 的货币（ISO 4217）
+var currencyDigits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
@@ -0,0 +1,172 @@
+package msg
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+// decimalString is a fmt.Stringer amount such as decimal.Decimal
+type decimalString string
+
+func (d decimalString) String() string { return string(d) }
+
+func TestMoneyFormatter_Format(t *testing.T) {
+	// "_" in the expected values stands for a no-break space
+	nbsp := func(s string) string { return strings.ReplaceAll(s, "_", "\u00a0") }
+
+	tests := []struct {
+		locale     Locale
+		amount     any
+		currency   string
+		want       string
+		accounting string
+	}{
+		{"en-US", 1234.5, "USD", "$1,234.50", "$1,234.50"},
+		{"en-US", -1234.5, "usd", "-$1,234.50", "($1,234.50)"},
+		{"en-US", 1234, "JPY", "¥1,234", "¥1,234"},
+		{"en-US", "-1.2345", "KWD", "-KWD_1.234", "(KWD_1.234)"},
+		{"en-US", 12.5, "CHF", "CHF_12.50", "CHF_12.50"},
+		{"zh-CN", 1234.5, "CNY", "¥1,234.50", "¥1,234.50"},
+		{"zh-TW", -99, "TWD", "-$99.00", "($99.00)"},
+		{"ja-JP", 1500, "JPY", "￥1,500", "￥1,500"},
+		{"de-DE", -1234.5, "EUR", "-1.234,50_€", "-1.234,50_€"},
+		{"fr-FR", -1234567.5, "EUR", "-1\u202f234\u202f567,50_€", "(1\u202f234\u202f567,50_€)"},
+		{"es-ES", 1234.5, "EUR", "1234,50_€", "1234,50_€"},
+		{"es-ES", 12345.5, "USD", "12.345,50_US$", "12.345,50_US$"},
+		{"pt-BR", uint8(7), "BRL", "R$7.00", "R$7.00"},
+		{"en-US", decimalString("19.999"), "USD", "$20.00", "$20.00"},
+		{"en-US", -0.001, "USD", "$0.00", "$0.00"},
+		{"en-US", "abc", "USD", "USD abc", "USD abc"},
+		{"en-US", 5, "EURO", "EURO 5", "EURO 5"},
+	}
+	for _, tt := range tests {
+		f := NewMoneyFormatter(tt.locale)
+		if got := f.Format(tt.amount, tt.currency); got != nbsp(tt.want) {
+			t.Errorf("%s Format(%v, %s) = %q, want %q", tt.locale, tt.amount, tt.currency, got, nbsp(tt.want))
+		}
+		if got := f.FormatAccounting(tt.amount, tt.currency); got != nbsp(tt.accounting) {
+			t.Errorf("%s FormatAccounting(%v, %s) = %q, want %q", tt.locale, tt.amount, tt.currency, got, nbsp(tt.accounting))
+		}
+	}
+
+	if got := NewMoneyFormatter(German).FormatMinor(-123456, "EUR"); got != "-1.234,56\u00a0€" {
+		t.Errorf("FormatMinor() = %q", got)
+	}
+	if got := NewMoneyFormatter(English).FormatMinor(5, "BHD"); got != "BHD\u00a00.005" {
+		t.Errorf("FormatMinor() = %q", got)
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		amount   any
+		currency string
+		want     int64
+	}{
+		{2.345, "USD", 234},
+		{2.355, "USD", 236},
+		{"2.3451", "USD", 235},
+		{-2.345, "USD", -234},
+		{0.5, "JPY", 0},
+		{1.5, "JPY", 2},
+		{"9.9995", "KWD", 10000},
+		{1.005, "USD", 100},
+		{42, "USD", 4200},
+	}
+	for _, tt := range tests {
+		got, err := RoundMoney(tt.amount, tt.currency)
+		if err != nil || got != tt.want {
+			t.Errorf("RoundMoney(%v, %s) = %d, %v; want %d", tt.amount, tt.currency, got, err, tt.want)
+		}
+	}
+
+	if _, err := RoundMoney(math.Inf(1), "USD"); err == nil {
+		t.Error("RoundMoney(+Inf) should return an error")
+	}
+	if _, err := RoundMoney("1e30", "USD"); err == nil {
+		t.Error("RoundMoney(1e30) should return an error")
+	}
+	if _, err := RoundMoney(uint64(math.MaxUint64), "USD"); err == nil {
+		t.Error("RoundMoney() out of the int64 range should return an error")
+	}
+	if _, err := RoundMoney(1, "$"); err == nil {
+		t.Error("RoundMoney() with an invalid currency should return an error")
+	}
+	if got := CurrencyDigits("jpy"); got != 0 {
+		t.Errorf("CurrencyDigits(jpy) = %d, want 0", got)
+	}
+}
+
+func TestMoneyFormatter_Parse(t *testing.T) {
+	tests := []struct {
+		locale   Locale
+		input    string
+		currency string
+		want     int64
+	}{
+		{"en-US", "$1,234.50", "USD", 123450},
+		{"en-US", "-$1,234.5", "USD", -123450},
+		{"en-US", "($1,234.50)", "USD", -123450},
+		{"en-US", "USD 12", "USD", 1200},
+		{"en-US", "  .99 ", "USD", 99},
+		{"en-US", "¥1,500", "JPY", 1500},
+		{"de-DE", "-1.234,50\u00a0€", "EUR", -123450},
+		{"de-DE", "1234,5 EUR", "EUR", 123450},
+		{"fr-FR", "(1\u202f234,50\u00a0€)", "EUR", -123450},
+		{"fr-FR", "1 234,50 €", "EUR", 123450},
+		{"zh-CN", "¥0.01", "CNY", 1},
+		{"en-US", "KWD\u00a01.234", "KWD", 1234},
+	}
+	for _, tt := range tests {
+		got, err := NewMoneyFormatter(tt.locale).Parse(tt.input, tt.currency)
+		if err != nil || got != tt.want {
+			t.Errorf("%s Parse(%q) = %d, %v; want %d", tt.locale, tt.input, got, err, tt.want)
+		}
+	}
+
+	invalid := []struct {
+		locale Locale
+		input  string
+	}{
+		{"en-US", ""},
+		{"en-US", "$"},
+		{"en-US", "$1.234"},   // too many fraction digits for USD
+		{"en-US", "1.234,50"}, // German separators in English
+		{"en-US", "-($1.00)"}, // two negative signs
+		{"en-US", "€1.00"},    // another currency
+		{"en-US", "$12a"},
+		{"en-US", "$99999999999999999999"},
+	}
+	for _, tt := range invalid {
+		if got, err := NewMoneyFormatter(tt.locale).Parse(tt.input, "USD"); err == nil {
+			t.Errorf("%s Parse(%q) = %d, want an error", tt.locale, tt.input, got)
+		}
+	}
+
+	// Format and Parse round-trip
+	for _, locale := range []Locale{"en-US", "zh-TW", "ja", "de-DE", "fr-FR", "es-ES"} {
+		f := NewMoneyFormatter(locale)
+		for _, minor := range []int64{0, 1, -99, 123456789, -100000} {
+			for _, s := range []string{f.FormatMinor(minor, "EUR"), f.FormatAccounting(float64(minor)/100, "EUR")} {
+				if got, err := f.Parse(s, "EUR"); err != nil || got != minor {
+					t.Errorf("%s Parse(%q) = %d, %v; want %d", locale, s, got, err, minor)
+				}
+			}
+		}
+	}
+}
+
+func TestFormatMoneyWithContext(t *testing.T) {
+	ctx := WithLocaleContext(context.Background(), German)
+	if got := FormatMoney(ctx, 9.5, "EUR"); got != "9,50\u00a0€" {
+		t.Errorf("FormatMoney() = %q", got)
+	}
+	if got := FormatMoneyAccounting(WithLocaleContext(context.Background(), English), -9.5, "EUR"); got != "(€9.50)" {
+		t.Errorf("FormatMoneyAccounting() = %q", got)
+	}
+	if got, err := ParseMoney(ctx, "9,50 €", "EUR"); err != nil || got != 950 {
+		t.Errorf("ParseMoney() = %d, %v", got, err)
+	}
+}
@@ -0,0 +1,171 @@
+// Package msggrpc 提供进行语言协商的 gRPC 服务端拦截器，
+// 与 msgslim.Middleware 处理 HTTP 请求的方式一致。
+//
+// 拦截器从请求元数据中检测用户偏好的语言（默认依次为 x-locale 和 accept-language），
+// 在 Manager 的打印机工厂支持的语言中选择最合适的语言，通过 msg.WithLocaleContext 存入上下文，
+// 并在响应头中设置 content-language。
+//
+// 使用示例：
+//
+//	server := grpc.NewServer(
+//	    grpc.ChainUnaryInterceptor(msggrpc.UnaryServerInterceptor(manager)),
+//	    grpc.ChainStreamInterceptor(msggrpc.StreamServerInterceptor(manager)),
+//	)
+//
+//	func (s *greeter) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
+//	    return &pb.HelloReply{Message: manager.SprintfWithContext(ctx, "Hello, %s!", req.Name)}, nil
+//	}
+package msggrpc
+
+import (
+	"context"
+	"strings"
+
+	"go-slim.dev/infra/msg"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultKeys 是未指定时检测语言使用的元数据键，按优先级排列：
+// 客户端显式指定的 x-locale，以及与 HTTP 相同的 accept-language。
+var DefaultKeys = []string{"x-locale", "accept-language"}
+
+// Option 配置语言协商拦截器的选项。
+type Option func(*options)
+
+// options 语言协商拦截器的配置
+type options struct {
+	skipper         func(ctx context.Context, fullMethod string) bool
+	supported       msg.LocaleSet
+	keys            []string
+	contentLanguage bool
+}
+
+// WithSkipper 设置跳过语言协商的条件，fullMethod 为 "/package.Service/Method" 形式的方法名，
+// 返回 true 时直接执行后续处理器。
+func WithSkipper(skipper func(ctx context.Context, fullMethod string) bool) Option {
+	return func(o *options) {
+		o.skipper = skipper
+	}
+}
+
+// WithSupportedLocales 指定参与协商的语言，替代 Manager 的打印机工厂所支持的语言。
+func WithSupportedLocales(locales ...msg.Locale) Option {
+	return func(o *options) {
+		o.supported = msg.LocaleSet(locales)
+	}
+}
+
+// WithKeys 设置检测语言的元数据键，按优先级排列，默认使用 DefaultKeys。
+// accept-language 的值按 Accept-Language 的语法解析，其它键的值为单个语言标签。
+func WithKeys(keys ...string) Option {
+	return func(o *options) {
+		o.keys = keys
+	}
+}
+
+// WithoutContentLanguage 禁止在响应头中设置 content-language。
+func WithoutContentLanguage() Option {
+	return func(o *options) {
+		o.contentLanguage = false
+	}
+}
+
+// UnaryServerInterceptor 创建进行语言协商的一元 RPC 拦截器，manager 为 nil 时使用全局默认 Manager。
+func UnaryServerInterceptor(manager *msg.Manager, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if o.skipper != nil && o.skipper(ctx, info.FullMethod) {
+			return handler(ctx, req)
+		}
+		locale := o.detect(ctx, manager)
+		if o.contentLanguage {
+			// 请求不是通过 gRPC 服务端传入时（如在测试中直接调用）无法设置响应头，忽略错误
+			_ = grpc.SetHeader(ctx, metadata.Pairs("content-language", string(locale)))
+		}
+		return handler(msg.WithLocaleContext(ctx, locale), req)
+	}
+}
+
+// StreamServerInterceptor 创建进行语言协商的流式 RPC 拦截器，manager 为 nil 时使用全局默认 Manager。
+func StreamServerInterceptor(manager *msg.Manager, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		if o.skipper != nil && o.skipper(ctx, info.FullMethod) {
+			return handler(srv, ss)
+		}
+		locale := o.detect(ctx, manager)
+		if o.contentLanguage {
+			_ = ss.SetHeader(metadata.Pairs("content-language", string(locale)))
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: msg.WithLocaleContext(ctx, locale)})
+	}
+}
+
+// DetectLocale 从 ctx 的请求元数据中按 keys 的优先级检测语言，未指定 keys 时使用 DefaultKeys。
+//
+// 返回第一个能与 manager 的打印机工厂支持的语言匹配的检测结果，都无法匹配时返回 manager 的当前语言。
+// manager 为 nil 时使用全局默认 Manager。
+func DetectLocale(ctx context.Context, manager *msg.Manager, keys ...string) msg.Locale {
+	return newOptions([]Option{WithKeys(keys...)}).detect(ctx, manager)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{contentLanguage: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if len(o.keys) == 0 {
+		o.keys = DefaultKeys
+	}
+	return o
+}
+
+// detect 按元数据键的优先级选择语言
+func (o *options) detect(ctx context.Context, manager *msg.Manager) msg.Locale {
+	if manager == nil {
+		manager = msg.GetDefaultManager()
+	}
+	supported := o.supported
+	if supported == nil {
+		supported = manager.GetPrinterFactory().SupportedLocales()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range o.keys {
+		if locale := msg.MatchLocale(candidates(key, md.Get(key)), supported, ""); locale != "" {
+			return locale
+		}
+	}
+	return manager.GetLocale()
+}
+
+// candidates 将元数据的值转换为按优先顺序排列的候选语言
+func candidates(key string, values []string) []msg.Locale {
+	if strings.EqualFold(key, "accept-language") {
+		var locales []msg.Locale
+		for _, item := range msg.ParseAcceptLanguage(strings.Join(values, ",")) {
+			locales = append(locales, item.Locale)
+		}
+		return locales
+	}
+	var locales []msg.Locale
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			locales = append(locales, msg.CanonicalLocale(v))
+		}
+	}
+	return locales
+}
+
+// serverStream 使用协商后的上下文的 grpc.ServerStream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回包含协商语言的上下文
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
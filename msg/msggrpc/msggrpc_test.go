@@ -0,0 +1,159 @@
+package msggrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// callUnary runs the unary interceptor with the given incoming metadata
+// and returns the locale seen by the handler.
+func callUnary(interceptor grpc.UnaryServerInterceptor, pairs ...string) msg.Locale {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+	var locale msg.Locale
+	_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"},
+		func(ctx context.Context, req any) (any, error) {
+			locale, _ = msg.GetLocaleFromContext(ctx)
+			return nil, nil
+		})
+	return locale
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.English})
+	interceptor := UnaryServerInterceptor(manager, WithSupportedLocales(msg.English, msg.ChineseSimplified))
+
+	tests := []struct {
+		name  string
+		pairs []string
+		want  msg.Locale
+	}{
+		{"Accept-Language", []string{"accept-language", "ja, zh-Hans-CN;q=0.9, en;q=0.5"}, msg.ChineseSimplified},
+		{"X-Locale", []string{"x-locale", "zh_hans"}, msg.ChineseSimplified},
+		{"X-Locale takes precedence", []string{"x-locale", "en", "accept-language", "zh-Hans"}, msg.English},
+		{"Unsupported x-locale", []string{"x-locale", "ja", "accept-language", "zh-Hans"}, msg.ChineseSimplified},
+		{"Fallback to manager locale", nil, msg.English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callUnary(interceptor, tt.pairs...); got != tt.want {
+				t.Errorf("Locale = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Skipper", func(t *testing.T) {
+		skipped := UnaryServerInterceptor(manager, WithSkipper(func(ctx context.Context, fullMethod string) bool {
+			return fullMethod == "/test.Service/Method"
+		}))
+		if got := callUnary(skipped, "x-locale", "zh-Hans"); got != "" {
+			t.Errorf("Locale = %q, want empty", got)
+		}
+	})
+
+	t.Run("Custom keys", func(t *testing.T) {
+		custom := UnaryServerInterceptor(manager, WithSupportedLocales(msg.English, msg.ChineseSimplified),
+			WithKeys("lang"))
+		if got := callUnary(custom, "lang", "zh-Hans", "x-locale", "en"); got != msg.ChineseSimplified {
+			t.Errorf("Locale = %q, want %q", got, msg.ChineseSimplified)
+		}
+	})
+}
+
+func TestDetectLocale(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.English})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "fr-CA, de;q=0.8"))
+	if got := DetectLocale(ctx, manager); got != msg.Locale("fr-CA") {
+		t.Errorf("DetectLocale() = %q, want fr-CA", got)
+	}
+	if got := DetectLocale(context.Background(), manager); got != msg.English {
+		t.Errorf("DetectLocale() without metadata = %q, want %q", got, msg.English)
+	}
+}
+
+// healthServer reports the negotiated locale through the serving status
+// so the test can observe it from the client side.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	locales chan msg.Locale
+}
+
+func (s *healthServer) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	locale, _ := msg.GetLocaleFromContext(ctx)
+	s.locales <- locale
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(_ *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	locale, _ := msg.GetLocaleFromContext(stream.Context())
+	s.locales <- locale
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+}
+
+func TestServerInterceptors(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.English})
+	supported := WithSupportedLocales(msg.English, msg.ChineseSimplified)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(manager, supported)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(manager, supported)),
+	)
+	health := &healthServer{locales: make(chan msg.Locale, 1)}
+	grpc_health_v1.RegisterHealthServer(server, health)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "accept-language", "zh-Hans-CN, en;q=0.5")
+
+	t.Run("Unary", func(t *testing.T) {
+		var header metadata.MD
+		if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if got := <-health.locales; got != msg.ChineseSimplified {
+			t.Errorf("Locale = %q, want %q", got, msg.ChineseSimplified)
+		}
+		if got := header.Get("content-language"); len(got) != 1 || got[0] != string(msg.ChineseSimplified) {
+			t.Errorf("content-language = %q, want %q", got, msg.ChineseSimplified)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("Watch() error = %v", err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if got := <-health.locales; got != msg.ChineseSimplified {
+			t.Errorf("Locale = %q, want %q", got, msg.ChineseSimplified)
+		}
+		header, err := stream.Header()
+		if err != nil {
+			t.Fatalf("Header() error = %v", err)
+		}
+		if got := header.Get("content-language"); len(got) != 1 || got[0] != string(msg.ChineseSimplified) {
+			t.Errorf("content-language = %q, want %q", got, msg.ChineseSimplified)
+		}
+	})
+}
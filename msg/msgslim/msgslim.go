@@ -0,0 +1,127 @@
+// Package msgslim 提供进行语言协商的 slim 中间件，使 msg 包本身不依赖 slim。
+//
+// 中间件按优先级执行语言检测链（默认依次为查询参数 lang、Cookie lang 和 Accept-Language），
+// 使用 msg.MatchLocale 在支持的语言中选择最合适的语言，通过 msg.WithLocaleContext 存入请求上下文，
+// 并设置 Content-Language 响应头。
+//
+// 使用示例：
+//
+//	app.Use(msgslim.Middleware(manager))
+//
+//	app.GET("/hello", func(c slim.Context) error {
+//	    p := manager.GetPrinterWithContext(c.Request().Context())
+//	    return c.String(http.StatusOK, p.Sprintf("Hello"))
+//	})
+package msgslim
+
+import (
+	"net/http"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/slim"
+)
+
+// Option 配置语言协商中间件的选项。
+type Option func(*options)
+
+// options 语言协商中间件的配置
+type options struct {
+	skipper         func(c slim.Context) bool
+	supported       msg.LocaleSet
+	contentLanguage bool
+	detectors       []msg.Detector
+	persist         *http.Cookie
+}
+
+// WithSkipper 设置跳过语言协商的条件，返回 true 时直接执行后续处理器。
+func WithSkipper(skipper func(c slim.Context) bool) Option {
+	return func(o *options) {
+		o.skipper = skipper
+	}
+}
+
+// WithSupportedLocales 指定参与协商的语言，替代 Manager 的打印机工厂所支持的语言。
+func WithSupportedLocales(locales ...msg.Locale) Option {
+	return func(o *options) {
+		o.supported = msg.LocaleSet(locales)
+	}
+}
+
+// WithoutContentLanguage 禁止设置 Content-Language 响应头。
+func WithoutContentLanguage() Option {
+	return func(o *options) {
+		o.contentLanguage = false
+	}
+}
+
+// WithDetectors 设置语言检测链，按优先级排列，默认使用 msg.DefaultDetectors。
+func WithDetectors(detectors ...msg.Detector) Option {
+	return func(o *options) {
+		o.detectors = detectors
+	}
+}
+
+// WithPersistence 将检测到的语言写回 Cookie，使后续请求沿用该语言。
+// 参数 cookie 是 Cookie 的模板，其 Value 会被替换为选中的语言；
+// 请求中已携带相同值时不会重复写入。所有检测器都没有结果而使用 Manager 的当前语言时不会写入，
+// 以免首次访问的用户被固定为默认语言。应配合同名的 msg.CookieDetector 使用。
+//
+// 使用示例：
+//
+//	msgslim.Middleware(manager, msgslim.WithPersistence(http.Cookie{
+//	    Name:   "lang",
+//	    Path:   "/",
+//	    MaxAge: 365 * 24 * 3600,
+//	}))
+func WithPersistence(cookie http.Cookie) Option {
+	return func(o *options) {
+		o.persist = &cookie
+	}
+}
+
+// Middleware 创建进行语言协商的 slim 中间件。
+//
+// 中间件使用 msg.Manager.Negotiate 协商语言，都无法匹配时使用 Manager 的当前语言。
+// 选中的语言通过 msg.WithLocaleContext 存入请求上下文，后续可以使用 GetPrinterWithContext
+// 等函数获取对应的 Printer，同时设置 Content-Language 响应头，并在 Vary 中声明 Accept-Language；
+// 检测时读取了 Cookie 的（如 msg.CookieDetector）还会声明 Cookie，使共享缓存按语言区分响应。
+//
+// 参数 manager 为 nil 时使用全局默认 Manager。
+func Middleware(manager *msg.Manager, opts ...Option) slim.MiddlewareFunc {
+	o := &options{contentLanguage: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c slim.Context, next slim.HandlerFunc) error {
+		if o.skipper != nil && o.skipper(c) {
+			return next(c)
+		}
+
+		m := manager
+		if m == nil {
+			m = msg.GetDefaultManager()
+		}
+		req := c.Request()
+		result := m.Negotiate(req, o.supported, o.detectors...)
+		c.SetRequest(req.WithContext(msg.WithLocaleContext(req.Context(), result.Locale)))
+
+		if o.persist != nil && result.Detected {
+			if current, err := req.Cookie(o.persist.Name); err != nil || current.Value != string(result.Locale) {
+				cookie := *o.persist
+				cookie.Value = string(result.Locale)
+				c.SetCookie(&cookie)
+			}
+		}
+
+		header := c.Response().Header()
+		header.Add("Vary", "Accept-Language")
+		for _, name := range result.Vary {
+			header.Add("Vary", name)
+		}
+		if o.contentLanguage {
+			header.Set("Content-Language", string(result.Locale))
+		}
+		return next(c)
+	}
+}
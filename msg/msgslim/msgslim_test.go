@@ -0,0 +1,137 @@
+package msgslim
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/slim"
+)
+
+// serveWithLanguage runs the middleware for a request with the given Accept-Language header
+// and returns the locale seen by the handler.
+func serveWithLanguage(mw slim.MiddlewareFunc, acceptLanguage string) (msg.Locale, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptLanguage != "" {
+		request.Header.Set("Accept-Language", acceptLanguage)
+	}
+	c := slim.New().NewContext(recorder, request)
+
+	var locale msg.Locale
+	_ = mw(c, func(c slim.Context) error {
+		locale, _ = msg.GetLocaleFromContext(c.Request().Context())
+		return nil
+	})
+	return locale, recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.English})
+	mw := Middleware(manager, WithSupportedLocales(msg.English, msg.ChineseSimplified))
+
+	t.Run("Negotiated locale", func(t *testing.T) {
+		locale, recorder := serveWithLanguage(mw, "ja, zh-Hans-CN;q=0.9, en;q=0.5")
+		if locale != msg.ChineseSimplified {
+			t.Errorf("Locale = %q, want %q", locale, msg.ChineseSimplified)
+		}
+		if got := recorder.Header().Get("Content-Language"); got != string(msg.ChineseSimplified) {
+			t.Errorf("Content-Language = %q, want %q", got, msg.ChineseSimplified)
+		}
+		if got := recorder.Header().Get("Vary"); got != "Accept-Language" {
+			t.Errorf("Vary = %q, want Accept-Language", got)
+		}
+	})
+
+	t.Run("Fallback to manager locale", func(t *testing.T) {
+		locale, _ := serveWithLanguage(mw, "")
+		if locale != msg.English {
+			t.Errorf("Locale = %q, want %q", locale, msg.English)
+		}
+	})
+
+	t.Run("Printer from context", func(t *testing.T) {
+		locale, _ := serveWithLanguage(mw, "zh-Hans")
+		ctx := msg.WithLocaleContext(t.Context(), locale)
+		if got := manager.GetPrinterWithContext(ctx).Locale(); got != msg.ChineseSimplified {
+			t.Errorf("Printer locale = %q, want %q", got, msg.ChineseSimplified)
+		}
+	})
+}
+
+func TestMiddlewareOptions(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.English})
+
+	t.Run("Unlimited factory accepts the first preference", func(t *testing.T) {
+		locale, _ := serveWithLanguage(Middleware(manager), "fr-FR, en")
+		if locale != msg.FrenchFR {
+			t.Errorf("Locale = %q, want %q", locale, msg.FrenchFR)
+		}
+	})
+
+	t.Run("Without Content-Language", func(t *testing.T) {
+		_, recorder := serveWithLanguage(Middleware(manager, WithoutContentLanguage()), "fr")
+		if got := recorder.Header().Get("Content-Language"); got != "" {
+			t.Errorf("Content-Language = %q, want empty", got)
+		}
+	})
+
+	t.Run("Skipper", func(t *testing.T) {
+		mw := Middleware(manager, WithSkipper(func(c slim.Context) bool { return true }))
+		if locale, _ := serveWithLanguage(mw, "fr"); locale != "" {
+			t.Errorf("Locale = %q, want none", locale)
+		}
+	})
+}
+
+func TestMiddlewarePersistence(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.English})
+	mw := Middleware(manager, WithPersistence(http.Cookie{Name: "lang", Path: "/"}))
+
+	serve := func(target, cookie string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		if cookie != "" {
+			request.AddCookie(&http.Cookie{Name: "lang", Value: cookie})
+		}
+		_ = mw(slim.New().NewContext(recorder, request), func(c slim.Context) error { return nil })
+		return recorder
+	}
+
+	cookies := serve("/?lang=fr", "").Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "fr" || cookies[0].Path != "/" {
+		t.Errorf("Cookies = %v, want lang=fr with path /", cookies)
+	}
+
+	// The cookie is not written again when it already holds the locale
+	if cookies := serve("/", "fr").Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("Cookies = %v, want none", cookies)
+	}
+
+	// The fallback locale is not persisted
+	if cookies := serve("/", "").Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("Cookies = %v, want none for the fallback locale", cookies)
+	}
+}
+
+func TestMiddlewareVaryCookie(t *testing.T) {
+	mw := Middleware(msg.NewManager(msg.ManagerConfig{Locale: msg.English}))
+
+	serve := func(target string) []string {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		_ = mw(slim.New().NewContext(recorder, request), func(c slim.Context) error { return nil })
+		return recorder.Header().Values("Vary")
+	}
+
+	// The cookie detector is consulted when the query has no locale
+	if vary := serve("/"); !slices.Equal(vary, []string{"Accept-Language", "Cookie"}) {
+		t.Errorf("Vary = %v, want Accept-Language and Cookie", vary)
+	}
+	// The locale of the query does not depend on the cookie
+	if vary := serve("/?lang=fr"); !slices.Equal(vary, []string{"Accept-Language"}) {
+		t.Errorf("Vary = %v, want Accept-Language", vary)
+	}
+}
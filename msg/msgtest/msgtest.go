@@ -0,0 +1,115 @@
+// Package msgtest 提供在单元测试中使用的内存翻译目录，
+// 不需要读取文件系统中的翻译文件即可断言翻译后的输出。
+//
+// 翻译数据按 gotext 格式在内存中构建，由 xtext.PrinterFactory 加载，
+// 回退链、父语言继承等行为与生产环境一致。
+//
+// 使用示例：
+//
+//	func TestGreeting(t *testing.T) {
+//	    manager := msgtest.NewManager(
+//	        msgtest.WithMessages(msg.Chinese, map[string]string{
+//	            "Hello, %s!": "你好，%s！",
+//	        }),
+//	    )
+//	    p := manager.GetPrinter(msg.Chinese)
+//	    if got := p.Sprintf("Hello, %s!", "Bob"); got != "你好，Bob！" {
+//	        t.Errorf("Sprintf() = %q", got)
+//	    }
+//	}
+package msgtest
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/infra/msg/xtext"
+)
+
+// config 包含 NewFactory 和 NewManager 的配置
+type config struct {
+	messages map[msg.Locale]map[string]string
+	fallback msg.Locale
+	options  []xtext.Option
+}
+
+// Option 配置 NewFactory 和 NewManager
+type Option func(*config)
+
+// WithMessages 添加 locale 的翻译，键为消息 ID，值为译文。
+// 多次调用会合并同一语言的翻译，后添加的覆盖先添加的。
+func WithMessages(locale msg.Locale, messages map[string]string) Option {
+	return func(c *config) {
+		if c.messages[locale] == nil {
+			c.messages[locale] = make(map[string]string, len(messages))
+		}
+		maps.Copy(c.messages[locale], messages)
+	}
+}
+
+// WithFallback 设置回退语言，也是 NewManager 创建的 Manager 的当前语言，默认为英语
+func WithFallback(locale msg.Locale) Option {
+	return func(c *config) {
+		c.fallback = locale
+	}
+}
+
+// WithOptions 添加创建 xtext.PrinterFactory 的选项，如 xtext.Missing
+func WithOptions(opts ...xtext.Option) Option {
+	return func(c *config) {
+		c.options = append(c.options, opts...)
+	}
+}
+
+// NewFactory 创建加载了内存翻译的 xtext.PrinterFactory
+func NewFactory(opts ...Option) *xtext.PrinterFactory {
+	c := newConfig(opts)
+
+	fsys := make(fstest.MapFS, len(c.messages))
+	for locale, messages := range c.messages {
+		fsys[string(locale)+".gotext.json"] = &fstest.MapFile{Data: gotext(locale, messages)}
+	}
+
+	factoryOpts := append([]xtext.Option{xtext.Fallback(c.fallback), xtext.BaseFS(fsys)}, c.options...)
+	return xtext.NewPrinterFactory(factoryOpts...)
+}
+
+// NewManager 创建使用 NewFactory 的 Manager，当前语言为回退语言
+func NewManager(opts ...Option) *msg.Manager {
+	c := newConfig(opts)
+	return msg.NewManager(msg.ManagerConfig{
+		Factory: NewFactory(opts...),
+		Locale:  c.fallback,
+	})
+}
+
+// newConfig 应用选项并填充默认值
+func newConfig(opts []Option) *config {
+	c := &config{messages: make(map[msg.Locale]map[string]string)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.fallback = cmp.Or(c.fallback, msg.English)
+	return c
+}
+
+// gotext 将翻译编码为 gotext JSON 格式
+func gotext(locale msg.Locale, messages map[string]string) []byte {
+	type message struct {
+		ID          string `json:"id"`
+		Translation string `json:"translation"`
+	}
+	file := struct {
+		Language string    `json:"language"`
+		Messages []message `json:"messages"`
+	}{Language: string(locale)}
+	for _, id := range slices.Sorted(maps.Keys(messages)) {
+		file.Messages = append(file.Messages, message{id, messages[id]})
+	}
+	data, _ := json.Marshal(file)
+	return data
+}
@@ -0,0 +1,67 @@
+package msgtest
+
+import (
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/infra/msg/xtext"
+)
+
+func TestNewManager(t *testing.T) {
+	manager := NewManager(
+		WithMessages(msg.Chinese, map[string]string{
+			"Hello, %s!": "你好，%s！",
+			"Bye":        "再见",
+		}),
+		WithMessages(msg.Chinese, map[string]string{"Bye": "回头见"}),
+		WithMessages(msg.Locale("zh-Hant"), map[string]string{"Bye": "再會"}),
+	)
+
+	if got := manager.GetLocale(); got != msg.English {
+		t.Errorf("GetLocale() = %q, want %q", got, msg.English)
+	}
+
+	zh := manager.GetPrinter(msg.Chinese)
+	if got, want := zh.Sprintf("Hello, %s!", "Bob"), "你好，Bob！"; got != want {
+		t.Errorf("Sprintf() = %q, want %q", got, want)
+	}
+	if got, want := zh.Sprintf("Bye"), "回头见"; got != want {
+		t.Errorf("Sprintf() merged = %q, want %q", got, want)
+	}
+
+	// 父语言的翻译被继承
+	hk := manager.GetPrinter(msg.Locale("zh-Hant-HK"))
+	if got, want := hk.Sprintf("Bye"), "再會"; got != want {
+		t.Errorf("zh-Hant-HK Sprintf() = %q, want %q", got, want)
+	}
+
+	if got, want := manager.GetPrinter().Sprintf("Hello, %s!", "Bob"), "Hello, Bob!"; got != want {
+		t.Errorf("English Sprintf() = %q, want %q", got, want)
+	}
+}
+
+func TestNewFactory(t *testing.T) {
+	counter := msg.NewMissingCounter()
+	factory := NewFactory(
+		WithFallback(msg.Chinese),
+		WithMessages(msg.Chinese, map[string]string{"Yes": "是"}),
+		WithOptions(xtext.Missing(counter)),
+	)
+
+	if got := factory.GetFallbackLocale(); got != msg.Chinese {
+		t.Errorf("GetFallbackLocale() = %q, want %q", got, msg.Chinese)
+	}
+	p, err := factory.CreatePrinter(msg.Locale("fr"))
+	if err != nil {
+		t.Fatalf("CreatePrinter() error = %v", err)
+	}
+	if got := p.Sprintf("Yes"); got != "是" {
+		t.Errorf("Sprintf() = %q, want %q", got, "是")
+	}
+	if got := p.Sprintf("No"); got != "No" {
+		t.Errorf("Sprintf() = %q, want %q", got, "No")
+	}
+	if entries := counter.Entries(); len(entries) != 1 || entries[0].ID != "No" {
+		t.Errorf("Entries() = %v, want the missing No", entries)
+	}
+}
@@ -0,0 +1,336 @@
+package msg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Args 表示命名参数，键为消息模板中 {name} 占位符的名称。
+type Args map[string]any
+
+// MissingArgsError 表示消息模板引用了未提供的命名参数。
+type MissingArgsError struct {
+	Format string   // 消息模板
+	Names  []string // 缺失的参数名称，按出现顺序排列且不重复
+}
+
+// Error 实现 error 接口
+func (e *MissingArgsError) Error() string {
+	return fmt.Sprintf("msg: missing arguments {%s} in %q", strings.Join(e.Names, "}, {"), e.Format)
+}
+
+// Sprintn 使用命名参数格式化消息。
+//
+// format 先作为翻译键交给 Printer 翻译，然后将译文中的 {name} 占位符替换为 args 中对应的值，
+// 值使用 Printer 按语言环境格式化。"{{" 和 "}}" 分别表示字面量 "{" 和 "}"。
+// 与 Sprintf 相同，模板中的 "%" 需要写作 "%%"。
+//
+// 与位置参数相比，命名参数允许译者自由调整参数顺序，且含义一目了然。
+// 缺失的参数渲染为 "%!{name}(MISSING)"，与 fmt 对缺失参数的处理方式一致，
+// 可使用 CheckArgs 提前检查。
+//
+// 模板还支持 ICU 风格的选择，按参数值选择一个分支，分支中可以继续使用占位符：
+//
+//   - {name, select, key {...} other {...}}：选择与参数值的文本相同的分支
+//   - {name, plural, =0 {...} one {...} other {...}}：先匹配 "=数值" 分支，
+//     再按 PluralSelector 返回的复数类别（zero、one、two、few、many、other）选择
+//
+// 没有匹配的分支时使用 other 分支，缺少 other 分支的选择按字面量保留。
+//
+// 示例：
+//
+//	msg.Sprintn(printer, "Hello, {name}! You have {count} new messages.", msg.Args{
+//	    "name":  "Bob",
+//	    "count": 3,
+//	})
+//
+//	msg.Sprintn(printer, "{count, plural, =0 {No messages} one {One message} other {{count} messages}}", msg.Args{
+//	    "count": 3,
+//	})
+func Sprintn(p Printer, format string, args Args) string {
+	s, _ := substitute(p, p.Sprintf(format), args)
+	return s
+}
+
+// Fprintn 与 Sprintn 相同，但将结果写入 w，返回写入的字节数和遇到的写入错误，
+// 用于将使用命名参数的消息直接输出到缓冲区、邮件正文或日志。
+//
+// 示例：
+//
+//	msg.Fprintn(w, printer, "Hello, {name}!", msg.Args{"name": "Bob"})
+func Fprintn(w io.Writer, p Printer, format string, args Args) (n int, err error) {
+	return io.WriteString(w, Sprintn(p, format, args))
+}
+
+// PluralSelector 是 Printer 的可选扩展接口，按语言环境的复数规则确定数量的复数类别，
+// 用于命名参数模板中的 plural 选择。
+//
+// xtext.Printer 基于 CLDR 复数规则实现了此接口。未实现该接口的 Printer 使用英语规则：
+// 1 为 "one"，其余为 "other"。
+type PluralSelector interface {
+	// PluralCategory 返回数量 n 的复数类别：zero、one、two、few、many 或 other
+	PluralCategory(n any) string
+}
+
+// pluralCategory 使用 Printer 的复数规则确定 n 的复数类别
+func pluralCategory(p Printer, n any) string {
+	if ps, ok := p.(PluralSelector); ok {
+		return ps.PluralCategory(n)
+	}
+	if fmt.Sprint(n) == "1" {
+		return "one"
+	}
+	return "other"
+}
+
+// substitute 使用 Printer 格式化参数值并替换 s 中的占位符，返回结果和缺失的参数名称
+func substitute(p Printer, s string, args Args) (string, []string) {
+	r := &namedRenderer{
+		lookup: func(name string) (any, bool) {
+			v, ok := args[name]
+			return v, ok
+		},
+		format: func(v any) string { return p.Sprintf("%v", v) },
+		plural: func(v any) string { return pluralCategory(p, v) },
+	}
+	return r.render(s), r.missing
+}
+
+// CheckArgs 检查消息模板引用的命名参数是否都已提供，
+// 存在缺失时返回 *MissingArgsError，可用于测试中校验翻译文本。
+// select 和 plural 的所有分支都会被检查。
+func CheckArgs(format string, args Args) error {
+	r := &namedRenderer{
+		lookup: func(name string) (any, bool) {
+			_, ok := args[name]
+			return nil, ok
+		},
+		format: func(any) string { return "" },
+		all:    true,
+	}
+	r.render(format)
+	if len(r.missing) > 0 {
+		return &MissingArgsError{Format: format, Names: r.missing}
+	}
+	return nil
+}
+
+// T 使用上下文中的语言翻译消息键，并替换其中的命名参数，多个 Args 会按顺序合并。
+// 缺失的参数会通过 Manager 的日志函数记录警告。
+//
+// 示例：
+//
+//	msg.T(ctx, "Hello, {name}!", msg.Args{"name": "Bob"})
+func (m *Manager) T(ctx context.Context, key string, args ...Args) string {
+	p := m.GetPrinterWithContext(ctx)
+	translated := p.Sprintf(key)
+	s, missing := substitute(p, translated, mergeArgs(args))
+	if len(missing) > 0 {
+		m.log(ctx, slog.LevelWarn, (&MissingArgsError{Format: translated, Names: missing}).Error(),
+			slog.String(LogKeyLocale, string(p.Locale())), slog.String(LogKeyID, key))
+	}
+	return s
+}
+
+// T 使用全局默认 Manager 翻译消息键并替换命名参数，详见 Manager.T。
+func T(ctx context.Context, key string, args ...Args) string {
+	return GetDefaultManager().T(ctx, key, args...)
+}
+
+// mergeArgs 按顺序合并多个 Args，后面的同名参数覆盖前面的
+func mergeArgs(args []Args) Args {
+	if len(args) == 1 {
+		return args[0]
+	}
+	merged := Args{}
+	for _, a := range args {
+		for k, v := range a {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// namedRenderer 替换模板中的命名参数，并记录缺失的参数名称
+type namedRenderer struct {
+	lookup  func(name string) (any, bool) // 查找参数值
+	format  func(v any) string            // 格式化参数值
+	plural  func(v any) string            // 返回参数值的复数类别
+	all     bool                          // 渲染选择的所有分支，用于检查参数
+	missing []string                      // 缺失的参数名称，按出现顺序排列且不重复
+}
+
+// render 替换 format 中的 {name} 占位符和选择。
+// 不构成合法占位符或选择的花括号按字面量保留。
+func (r *namedRenderer) render(format string) string {
+	if !strings.ContainsAny(format, "{}") {
+		return format
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		ch := format[i]
+		switch {
+		case ch == '{' && i+1 < len(format) && format[i+1] == '{':
+			b.WriteByte('{')
+			i++
+		case ch == '}' && i+1 < len(format) && format[i+1] == '}':
+			b.WriteByte('}')
+			i++
+		case ch == '{':
+			end := closingBrace(format, i)
+			if end < 0 {
+				b.WriteByte(ch)
+				continue
+			}
+			inner := format[i+1 : end]
+			if isArgName(inner) {
+				b.WriteString(r.arg(inner))
+			} else if s, ok := r.choice(inner); ok {
+				b.WriteString(s)
+			} else {
+				b.WriteByte(ch)
+				continue
+			}
+			i = end
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// arg 返回参数格式化后的值，参数缺失时记录并返回 "%!{name}(MISSING)"
+func (r *namedRenderer) arg(name string) string {
+	if v, ok := r.lookup(name); ok {
+		return r.format(v)
+	}
+	if !slices.Contains(r.missing, name) {
+		r.missing = append(r.missing, name)
+	}
+	return "%!{" + name + "}(MISSING)"
+}
+
+// choice 渲染 "name, select, ..." 或 "name, plural, ..." 形式的选择，
+// inner 不是合法的选择时返回 false
+func (r *namedRenderer) choice(inner string) (string, bool) {
+	name, rest, ok := strings.Cut(inner, ",")
+	name = strings.TrimSpace(name)
+	if !ok || !isArgName(name) {
+		return "", false
+	}
+	kind, rest, ok := strings.Cut(rest, ",")
+	kind = strings.TrimSpace(kind)
+	if !ok || (kind != "select" && kind != "plural") {
+		return "", false
+	}
+	cases, ok := parseCases(rest)
+	if !ok {
+		return "", false
+	}
+	other, ok := findCase(cases, "other")
+	if !ok {
+		return "", false
+	}
+
+	if r.all {
+		r.arg(name)
+		for _, c := range cases {
+			r.render(c.text)
+		}
+		return "", true
+	}
+
+	v, ok := r.lookup(name)
+	if !ok {
+		return r.arg(name), true
+	}
+	key := fmt.Sprint(v)
+	if kind == "plural" {
+		if text, ok := findCase(cases, "="+key); ok {
+			return r.render(text), true
+		}
+		key = r.plural(v)
+	}
+	if text, ok := findCase(cases, key); ok {
+		return r.render(text), true
+	}
+	return r.render(other), true
+}
+
+// namedCase 是选择的一个分支
+type namedCase struct {
+	key, text string
+}
+
+// findCase 返回第一个键为 key 的分支的文本
+func findCase(cases []namedCase, key string) (string, bool) {
+	for _, c := range cases {
+		if c.key == key {
+			return c.text, true
+		}
+	}
+	return "", false
+}
+
+// parseCases 解析 "key {text} key {text}" 形式的分支列表
+func parseCases(s string) ([]namedCase, bool) {
+	var cases []namedCase
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			return cases, len(cases) > 0
+		}
+		open := strings.IndexByte(s, '{')
+		if open <= 0 {
+			return nil, false
+		}
+		key := strings.TrimSpace(s[:open])
+		if key == "" || strings.ContainsAny(key, " \t\r\n}") {
+			return nil, false
+		}
+		end := closingBrace(s, open)
+		if end < 0 {
+			return nil, false
+		}
+		cases = append(cases, namedCase{key: key, text: s[open+1 : end]})
+		s = s[end+1:]
+	}
+}
+
+// closingBrace 返回与 s[open] 处的 "{" 配对的 "}" 的位置，没有时返回 -1
+func closingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isArgName 检查是否为合法的参数名称：由字母、数字、下划线、点或连字符组成
+func isArgName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '.', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
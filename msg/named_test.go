@@ -0,0 +1,154 @@
+package msg
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSprintn(t *testing.T) {
+	printer := NewPrinter(English)
+
+	tests := []struct {
+		name     string
+		format   string
+		args     Args
+		expected string
+	}{
+		{
+			name:     "Named placeholders",
+			format:   "Hello, {name}! You have {count} new messages.",
+			args:     Args{"name": "Bob", "count": 3},
+			expected: "Hello, Bob! You have 3 new messages.",
+		},
+		{
+			name:     "Reordered and repeated placeholders",
+			format:   "{count} messages for {name}, {name}",
+			args:     Args{"name": "Bob", "count": 3},
+			expected: "3 messages for Bob, Bob",
+		},
+		{
+			name:     "Escaped braces",
+			format:   "{{name}} is {name}",
+			args:     Args{"name": "Bob"},
+			expected: "{name} is Bob",
+		},
+		{
+			name:     "Braces that are not placeholders",
+			format:   "{ not a placeholder } and {unclosed",
+			args:     nil,
+			expected: "{ not a placeholder } and {unclosed",
+		},
+		{
+			name:     "Missing argument",
+			format:   "Hello, {name}!",
+			args:     Args{},
+			expected: "Hello, %!{name}(MISSING)!",
+		},
+		{
+			name:     "Plural",
+			format:   "{count, plural, =0 {No messages} one {One message} other {{count} messages for {name}}}",
+			args:     Args{"name": "Bob", "count": 3},
+			expected: "3 messages for Bob",
+		},
+		{
+			name:     "Plural category",
+			format:   "{count, plural, =0 {No messages} one {One message} other {{count} messages}}",
+			args:     Args{"count": 1},
+			expected: "One message",
+		},
+		{
+			name:     "Plural exact match",
+			format:   "{count, plural, =0 {No messages} one {One message} other {{count} messages}}",
+			args:     Args{"count": 0},
+			expected: "No messages",
+		},
+		{
+			name:     "Select",
+			format:   "{gender, select, female {She} male {He} other {They}} replied",
+			args:     Args{"gender": "female"},
+			expected: "She replied",
+		},
+		{
+			name:     "Select falls back to other",
+			format:   "{gender, select, female {She} male {He} other {They}} replied",
+			args:     Args{"gender": "unknown"},
+			expected: "They replied",
+		},
+		{
+			name:     "Select without other",
+			format:   "{gender, select, female {She said} }",
+			args:     Args{"gender": "female"},
+			expected: "{gender, select, female {She said} }",
+		},
+		{
+			name:     "Missing select argument",
+			format:   "{gender, select, female {She} other {They}} replied",
+			args:     Args{},
+			expected: "%!{gender}(MISSING) replied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sprintn(printer, tt.format, tt.args); got != tt.expected {
+				t.Errorf("Sprintn() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFprintn(t *testing.T) {
+	var b strings.Builder
+	n, err := Fprintn(&b, NewPrinter(English), "Hello, {name}!", Args{"name": "Bob"})
+	if err != nil || n != len("Hello, Bob!") || b.String() != "Hello, Bob!" {
+		t.Errorf("Fprintn() = %d, %v, wrote %q", n, err, b.String())
+	}
+}
+
+func TestCheckArgs(t *testing.T) {
+	if err := CheckArgs("Hello, {name}!", Args{"name": "Bob"}); err != nil {
+		t.Errorf("CheckArgs() error = %v, want nil", err)
+	}
+
+	err := CheckArgs("{greeting}, {name}! {greeting}", Args{})
+	var missing *MissingArgsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("CheckArgs() error = %v, want *MissingArgsError", err)
+	}
+	if !slices.Equal(missing.Names, []string{"greeting", "name"}) {
+		t.Errorf("Names = %v, want [greeting name]", missing.Names)
+	}
+
+	err = CheckArgs("{count, plural, one {One file in {dir}} other {{count} files}}", Args{"count": 2})
+	if !errors.As(err, &missing) {
+		t.Fatalf("CheckArgs() error = %v, want *MissingArgsError", err)
+	}
+	if !slices.Equal(missing.Names, []string{"dir"}) {
+		t.Errorf("Names = %v, want [dir]", missing.Names)
+	}
+}
+
+func TestManagerT(t *testing.T) {
+	var logs []string
+	manager := NewManager(ManagerConfig{
+		Locale:  English,
+		LogFunc: func(msg string) { logs = append(logs, msg) },
+	})
+	ctx := WithLocaleContext(context.Background(), Chinese)
+
+	got := manager.T(ctx, "{greeting}, {name}!", Args{"greeting": "Hi", "name": "Ann"}, Args{"name": "Bob"})
+	if got != "Hi, Bob!" {
+		t.Errorf("T() = %q, want %q", got, "Hi, Bob!")
+	}
+
+	logs = nil
+	manager.T(ctx, "Hello, {name}!")
+	if !slices.ContainsFunc(logs, func(s string) bool {
+		return strings.HasPrefix(s, "[WARN]") && strings.Contains(s, "{name}")
+	}) {
+		t.Errorf("logs = %v, want a missing argument warning", logs)
+	}
+}
@@ -0,0 +1,125 @@
+package msg
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WeightedLocale 是 Accept-Language 请求头中带质量值(q)的语言
+type WeightedLocale struct {
+	Locale  Locale
+	Quality float64 // 质量值，取值范围为 (0, 1]
+}
+
+// ParseAcceptLanguage 解析 Accept-Language 请求头，按质量值(q)从高到低返回语言及其质量值。
+//
+// 质量值相同的语言保持原有顺序；q=0 的语言、通配符 "*" 以及无法解析的条目会被忽略。
+// 返回的语言标签已规范化大小写，如 "zh-hans-cn" → "zh-Hans-CN"。
+//
+// 示例：
+//
+//	ParseAcceptLanguage("en;q=0.8, zh-CN, fr;q=0.9")
+//	// → []WeightedLocale{{"zh-CN", 1}, {"fr", 0.9}, {"en", 0.8}}
+func ParseAcceptLanguage(header string) []WeightedLocale {
+	var items []WeightedLocale
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(strings.TrimSpace(key), "q") {
+				v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil || v < 0 || v > 1 {
+					v = 0
+				}
+				q = v
+			}
+		}
+		if q > 0 {
+			items = append(items, WeightedLocale{CanonicalLocale(tag), q})
+		}
+	}
+
+	slices.SortStableFunc(items, func(a, b WeightedLocale) int {
+		return cmp.Compare(b.Quality, a.Quality)
+	})
+	return items
+}
+
+// acceptedLocales 返回 Accept-Language 请求头中按质量值从高到低排列的语言
+func acceptedLocales(header string) []Locale {
+	items := ParseAcceptLanguage(header)
+	locales := make([]Locale, len(items))
+	for i, item := range items {
+		locales[i] = item.Locale
+	}
+	return locales
+}
+
+// CanonicalLocale 将语言标签规范化为 BCP 47 推荐的大小写形式：
+// 语言代码小写、脚本代码首字母大写、地区代码大写，下划线会被转换为连字符。
+// 扩展和私有部分统一为小写。
+//
+// 示例：
+//   - "ZH_hans_cn" → "zh-Hans-CN"
+//   - "en-us" → "en-US"
+func CanonicalLocale(tag string) Locale {
+	subtags := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	for i, s := range subtags {
+		switch {
+		case i == 0:
+			subtags[i] = strings.ToLower(s)
+		case len(s) == 1:
+			// 扩展或私有部分开始，其后全部小写
+			for j := i; j < len(subtags); j++ {
+				subtags[j] = strings.ToLower(subtags[j])
+			}
+			return Locale(strings.Join(subtags, "-"))
+		case len(s) == 4 && i == 1:
+			subtags[i] = strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+		case len(s) == 2 || (len(s) == 3 && s[0] >= '0' && s[0] <= '9'):
+			subtags[i] = strings.ToUpper(s)
+		default:
+			subtags[i] = strings.ToLower(s)
+		}
+	}
+	return Locale(strings.Join(subtags, "-"))
+}
+
+// MatchLocale 按优先顺序为用户偏好的语言在支持的语言集合中查找最合适的语言。
+//
+// 对每个偏好语言，依次尝试：
+//  1. 基础部分完全相同的支持语言；
+//  2. 包含该语言的更通用的支持语言，如偏好 "zh-Hans-CN" 匹配支持的 "zh-Hans"；
+//  3. 被该语言包含的更具体的支持语言，如偏好 "zh" 匹配支持的 "zh-Hans-CN"。
+//
+// 无限制的集合(nil)直接返回第一个偏好语言；都无法匹配时返回 fallback。
+//
+// 示例：
+//
+//	supported := LocaleSet{English, ChineseSimplified}
+//	MatchLocale([]Locale{"zh-CN", "en"}, supported, English) // → "en"
+//	MatchLocale([]Locale{"zh-Hans-CN"}, supported, English)  // → "zh-Hans"
+func MatchLocale(preferred []Locale, supported LocaleSet, fallback Locale) Locale {
+	if supported.IsUnlimited() {
+		if len(preferred) > 0 {
+			return preferred[0]
+		}
+		return fallback
+	}
+
+	sorted := supported.Sorted()
+	for _, locale := range preferred {
+		if match, ok := sorted.lookup(locale); ok {
+			return match
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,128 @@
+package msg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected []WeightedLocale
+	}{
+		{
+			name:     "Empty header",
+			header:   "",
+			expected: nil,
+		},
+		{
+			name:     "Quality ordering",
+			header:   "en;q=0.8, zh-CN, fr;q=0.9",
+			expected: []WeightedLocale{{"zh-CN", 1}, {"fr", 0.9}, {"en", 0.8}},
+		},
+		{
+			name:     "Equal quality keeps order",
+			header:   "de, ja;q=0.5, fr",
+			expected: []WeightedLocale{{"de", 1}, {"fr", 1}, {"ja", 0.5}},
+		},
+		{
+			name:     "Zero quality, wildcard and invalid quality are ignored",
+			header:   "en;q=0, *;q=0.5, fr;q=abc, ja",
+			expected: []WeightedLocale{{"ja", 1}},
+		},
+		{
+			name:     "Case is normalized",
+			header:   "ZH-hans-cn, en_us",
+			expected: []WeightedLocale{{"zh-Hans-CN", 1}, {"en-US", 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAcceptLanguage(tt.header)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCanonicalLocale(t *testing.T) {
+	tests := map[string]Locale{
+		"en":                  "en",
+		"EN-us":               "en-US",
+		"zh_hant_tw":          "zh-Hant-TW",
+		"es-419":              "es-419",
+		"en-US-U-CA-Gregory":  "en-US-u-ca-gregory",
+		"zh-Hans-CN-x-Custom": "zh-Hans-CN-x-custom",
+	}
+
+	for tag, expected := range tests {
+		if got := CanonicalLocale(tag); got != expected {
+			t.Errorf("CanonicalLocale(%q) = %q, want %q", tag, got, expected)
+		}
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	supported := LocaleSet{English, ChineseSimplified, Locale("zh-Hant-TW"), FrenchFR}
+
+	tests := []struct {
+		name      string
+		preferred []Locale
+		supported LocaleSet
+		expected  Locale
+	}{
+		{
+			name:      "Exact match",
+			preferred: []Locale{"zh-Hans"},
+			supported: supported,
+			expected:  ChineseSimplified,
+		},
+		{
+			name:      "More general supported locale",
+			preferred: []Locale{"zh-Hans-CN"},
+			supported: supported,
+			expected:  ChineseSimplified,
+		},
+		{
+			name:      "More specific supported locale",
+			preferred: []Locale{"fr"},
+			supported: supported,
+			expected:  FrenchFR,
+		},
+		{
+			name:      "Preference order wins over specificity",
+			preferred: []Locale{"ja", "en-GB", "zh-Hans"},
+			supported: supported,
+			expected:  English,
+		},
+		{
+			name:      "Fallback when nothing matches",
+			preferred: []Locale{"ja", "ko"},
+			supported: supported,
+			expected:  German,
+		},
+		{
+			name:      "Unlimited set returns first preference",
+			preferred: []Locale{"ja", "ko"},
+			supported: nil,
+			expected:  Japanese,
+		},
+		{
+			name:      "Unlimited set without preference",
+			preferred: nil,
+			supported: nil,
+			expected:  German,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchLocale(tt.preferred, tt.supported, German); got != tt.expected {
+				t.Errorf("MatchLocale(%v) = %q, want %q", tt.preferred, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package msg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// NumberFormatter 定义了按语言环境格式化数字的接口。
+//
+// 这是 Printer 的可选扩展接口，xtext.Printer 基于 golang.org/x/text 实现了
+// 本地化的千位分隔符、小数点和货币符号。未实现该接口的 Printer 使用不分组的
+// 基本格式，如 "1234.5"、"25%" 和 "USD 12.50"。
+//
+// 使用示例：
+//
+//	if nf, ok := printer.(msg.NumberFormatter); ok {
+//	    fmt.Println(nf.FormatNumber(1234567.891)) // de-DE: "1.234.567,891"
+//	}
+type NumberFormatter interface {
+	// FormatNumber 格式化数字，如 en-US 下 1234567.891 → "1,234,567.891"
+	FormatNumber(v any) string
+
+	// FormatCurrency 使用 ISO 4217 货币代码格式化金额，如 "EUR" 下 1234.5 → "€ 1,234.50"
+	FormatCurrency(amount any, currency string) string
+
+	// FormatPercent 将比例格式化为百分数，如 0.256 → "26%"
+	FormatPercent(v any) string
+}
+
+// FormatNumber 使用上下文中的语言格式化数字
+func FormatNumber(ctx context.Context, v any) string {
+	return numberFormatter(GetPrinterWithContext(ctx)).FormatNumber(v)
+}
+
+// FormatCurrency 使用上下文中的语言和 ISO 4217 货币代码格式化金额
+func FormatCurrency(ctx context.Context, amount any, currency string) string {
+	return numberFormatter(GetPrinterWithContext(ctx)).FormatCurrency(amount, currency)
+}
+
+// FormatPercent 使用上下文中的语言将比例格式化为百分数
+func FormatPercent(ctx context.Context, v any) string {
+	return numberFormatter(GetPrinterWithContext(ctx)).FormatPercent(v)
+}
+
+// numberFormatter 返回 Printer 的数字格式化实现，未实现时使用基本格式
+func numberFormatter(p Printer) NumberFormatter {
+	if nf, ok := p.(NumberFormatter); ok {
+		return nf
+	}
+	return basicNumberFormatter{}
+}
+
+// basicNumberFormatter 是不区分语言环境的数字格式化实现
+type basicNumberFormatter struct{}
+
+func (basicNumberFormatter) FormatNumber(v any) string {
+	return fmt.Sprint(v)
+}
+
+func (basicNumberFormatter) FormatCurrency(amount any, currency string) string {
+	if f, ok := toFloat(amount); ok {
+		return currency + " " + strconv.FormatFloat(f, 'f', 2, 64)
+	}
+	return fmt.Sprint(currency, " ", amount)
+}
+
+func (basicNumberFormatter) FormatPercent(v any) string {
+	if f, ok := toFloat(v); ok {
+		return strconv.FormatFloat(f*100, 'f', 0, 64) + "%"
+	}
+	return fmt.Sprint(v, "%")
+}
+
+// toFloat 将数值类型转换为 float64
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,70 @@
+package msg
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeNumberPrinter is a Printer that implements NumberFormatter
+type fakeNumberPrinter struct {
+	Printer
+}
+
+func (fakeNumberPrinter) FormatNumber(v any) string                     { return "number" }
+func (fakeNumberPrinter) FormatCurrency(amount any, code string) string { return "currency " + code }
+func (fakeNumberPrinter) FormatPercent(v any) string                    { return "percent" }
+
+func TestBasicNumberFormatter(t *testing.T) {
+	f := numberFormatter(NewPrinter(English))
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"Number", f.FormatNumber(1234.5), "1234.5"},
+		{"Integer currency", f.FormatCurrency(12, "USD"), "USD 12.00"},
+		{"Float currency", f.FormatCurrency(12.345, "EUR"), "EUR 12.35"},
+		{"Non-numeric currency", f.FormatCurrency("12", "EUR"), "EUR 12"},
+		{"Percent", f.FormatPercent(0.256), "26%"},
+		{"Unsigned percent", f.FormatPercent(uint8(1)), "100%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("got %q, want %q", tt.got, tt.expected)
+			}
+		})
+	}
+}
+
+// numberPrinterFactory creates fakeNumberPrinter instances for any locale
+type numberPrinterFactory struct{}
+
+func (numberPrinterFactory) CreatePrinter(locale Locale) (Printer, error) {
+	return fakeNumberPrinter{NewPrinter(locale)}, nil
+}
+func (numberPrinterFactory) SupportsLocale(locale Locale) bool { return true }
+func (numberPrinterFactory) SupportedLocales() LocaleSet       { return nil }
+func (numberPrinterFactory) SetFallbackLocale(Locale) Locale   { return "" }
+func (numberPrinterFactory) GetFallbackLocale() Locale         { return "" }
+
+func TestFormatNumberWithContext(t *testing.T) {
+	ctx := WithLocaleAndPrinterFactoryContext(context.Background(), German, numberPrinterFactory{})
+
+	if got := FormatNumber(ctx, 1); got != "number" {
+		t.Errorf("FormatNumber() = %q, want number", got)
+	}
+	if got := FormatCurrency(ctx, 1, "EUR"); got != "currency EUR" {
+		t.Errorf("FormatCurrency() = %q, want currency EUR", got)
+	}
+	if got := FormatPercent(ctx, 1); got != "percent" {
+		t.Errorf("FormatPercent() = %q, want percent", got)
+	}
+
+	// Printers without NumberFormatter use the basic format
+	if got := FormatNumber(context.Background(), 1234); got != "1234" {
+		t.Errorf("FormatNumber() = %q, want 1234", got)
+	}
+}
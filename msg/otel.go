@@ -0,0 +1,98 @@
+package msg
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// meterName 是 OTelTelemetry 使用的全局 MeterProvider 的 instrumentation scope
+const meterName = "go-slim.dev/infra/msg"
+
+// attrLocale 是区分语言的指标属性，取值为 Telemetry 文档中说明的有限语言集合
+const attrLocale = attribute.Key("locale")
+
+// OTelTelemetry 是将指标记录到 OpenTelemetry 的 Telemetry，所有指标带有 locale 属性：
+//
+//   - msg.lookups：Printer 获取次数
+//   - msg.cache_hits：命中 Manager 的 Printer 缓存的次数
+//   - msg.fallbacks：不受支持的语言改用该语言的次数
+//   - msg.missing：缺失翻译的次数，消息标识不作为属性，具体的消息可以通过 MissingCounter 统计
+//   - msg.lookup.duration：获取 Printer 耗时的直方图，单位为秒，区间为 TelemetryLatencyBuckets
+//
+// 使用示例：
+//
+//	telemetry, err := msg.NewOTelTelemetry(nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	manager := msg.NewManager(msg.ManagerConfig{EnableTelemetry: true, Telemetry: telemetry})
+type OTelTelemetry struct {
+	lookups   metric.Int64Counter
+	hits      metric.Int64Counter
+	fallbacks metric.Int64Counter
+	missing   metric.Int64Counter
+	latency   metric.Float64Histogram
+}
+
+// NewOTelTelemetry 使用 meter 创建 OTelTelemetry，meter 为 nil 时使用 otel.SetMeterProvider
+// 注册的全局 MeterProvider。创建指标失败时返回错误
+func NewOTelTelemetry(meter metric.Meter) (*OTelTelemetry, error) {
+	if meter == nil {
+		meter = otel.Meter(meterName)
+	}
+
+	t := &OTelTelemetry{}
+	var err error
+	if t.lookups, err = meter.Int64Counter("msg.lookups",
+		metric.WithDescription("Number of printer lookups"), metric.WithUnit("{lookup}")); err != nil {
+		return nil, err
+	}
+	if t.hits, err = meter.Int64Counter("msg.cache_hits",
+		metric.WithDescription("Number of printer lookups served by the printer cache"), metric.WithUnit("{lookup}")); err != nil {
+		return nil, err
+	}
+	if t.fallbacks, err = meter.Int64Counter("msg.fallbacks",
+		metric.WithDescription("Number of unsupported locales falling back to the locale"), metric.WithUnit("{lookup}")); err != nil {
+		return nil, err
+	}
+	if t.missing, err = meter.Int64Counter("msg.missing",
+		metric.WithDescription("Number of messages without translation"), metric.WithUnit("{message}")); err != nil {
+		return nil, err
+	}
+
+	bounds := make([]float64, len(TelemetryLatencyBuckets))
+	for i, b := range TelemetryLatencyBuckets {
+		bounds[i] = b.Seconds()
+	}
+	if t.latency, err = meter.Float64Histogram("msg.lookup.duration",
+		metric.WithDescription("Duration of printer lookups"), metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(bounds...)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RecordLookup 实现 Telemetry 接口
+func (t *OTelTelemetry) RecordLookup(locale Locale, cacheHit bool, latency time.Duration) {
+	ctx := context.Background()
+	attrs := metric.WithAttributes(attrLocale.String(string(locale)))
+	t.lookups.Add(ctx, 1, attrs)
+	if cacheHit {
+		t.hits.Add(ctx, 1, attrs)
+	}
+	t.latency.Record(ctx, latency.Seconds(), attrs)
+}
+
+// RecordFallback 实现 Telemetry 接口，按回退使用的语言计数
+func (t *OTelTelemetry) RecordFallback(requested, used Locale) {
+	t.fallbacks.Add(context.Background(), 1, metric.WithAttributes(attrLocale.String(string(used))))
+}
+
+// ReportMissing 实现 MissingReporter 接口
+func (t *OTelTelemetry) ReportMissing(locale Locale, id string) {
+	t.missing.Add(context.Background(), 1, metric.WithAttributes(attrLocale.String(string(locale))))
+}
@@ -0,0 +1,122 @@
+package msg
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// recordingMeter 记录各指标按 locale 属性累计的值
+type recordingMeter struct {
+	noop.Meter
+	mu      sync.Mutex
+	values  map[string]map[string]float64 // 指标名 → locale → 累计值
+	buckets []float64                     // 直方图的区间上限
+}
+
+func (m *recordingMeter) record(name, locale string, v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = make(map[string]map[string]float64)
+	}
+	if m.values[name] == nil {
+		m.values[name] = make(map[string]float64)
+	}
+	m.values[name][locale] += v
+}
+
+func (m *recordingMeter) value(name, locale string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.values[name][locale]
+}
+
+func (m *recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return &recordingCounter{meter: m, name: name}, nil
+}
+
+func (m *recordingMeter) Float64Histogram(name string, opts ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	m.buckets = metric.NewFloat64HistogramConfig(opts...).ExplicitBucketBoundaries()
+	return &recordingHistogram{meter: m, name: name}, nil
+}
+
+type recordingCounter struct {
+	noop.Int64Counter
+	meter *recordingMeter
+	name  string
+}
+
+func (c *recordingCounter) Add(_ context.Context, incr int64, opts ...metric.AddOption) {
+	attrs := metric.NewAddConfig(opts).Attributes()
+	locale, _ := attrs.Value(attrLocale)
+	c.meter.record(c.name, locale.AsString(), float64(incr))
+}
+
+type recordingHistogram struct {
+	noop.Float64Histogram
+	meter *recordingMeter
+	name  string
+}
+
+func (h *recordingHistogram) Record(_ context.Context, v float64, opts ...metric.RecordOption) {
+	attrs := metric.NewRecordConfig(opts).Attributes()
+	locale, _ := attrs.Value(attrLocale)
+	h.meter.record(h.name+".count", locale.AsString(), 1)
+	h.meter.record(h.name, locale.AsString(), v)
+}
+
+func TestOTelTelemetry(t *testing.T) {
+	meter := &recordingMeter{}
+	telemetry, err := NewOTelTelemetry(meter)
+	if err != nil {
+		t.Fatalf("NewOTelTelemetry() error = %v", err)
+	}
+
+	wantBuckets := make([]float64, len(TelemetryLatencyBuckets))
+	for i, b := range TelemetryLatencyBuckets {
+		wantBuckets[i] = b.Seconds()
+	}
+	if !slices.Equal(meter.buckets, wantBuckets) {
+		t.Errorf("histogram buckets = %v, want %v", meter.buckets, wantBuckets)
+	}
+
+	factory := &limitedFactory{PrinterFactory: NewPrinterFactory(), supported: LocaleSet{English, ChineseSimplified}}
+	manager := NewManager(ManagerConfig{LogFunc: func(string) {}, Factory: factory, EnableTelemetry: true, Telemetry: telemetry})
+
+	zh := WithLocaleContext(context.Background(), "zh-Hans-CN")
+	manager.GetPrinterWithContext(zh)
+	manager.GetPrinterWithContext(zh)
+	manager.GetPrinterWithContext(WithLocaleContext(context.Background(), "x-unknown-tag"))
+	telemetry.ReportMissing(ChineseSimplified, "Checkout")
+
+	for _, tt := range []struct {
+		name   string
+		locale Locale
+		want   float64
+	}{
+		{"msg.lookups", ChineseSimplified, 2},
+		{"msg.cache_hits", ChineseSimplified, 1},
+		{"msg.lookup.duration.count", ChineseSimplified, 2},
+		{"msg.missing", ChineseSimplified, 1},
+		{"msg.lookups", English, 1},
+		{"msg.fallbacks", English, 1},
+	} {
+		if got := meter.value(tt.name, string(tt.locale)); got != tt.want {
+			t.Errorf("%s{locale=%s} = %v, want %v", tt.name, tt.locale, got, tt.want)
+		}
+	}
+
+	// 请求中原样的语言标签不会成为属性值
+	if got := meter.value("msg.lookups", "x-unknown-tag"); got != 0 {
+		t.Errorf("msg.lookups{locale=x-unknown-tag} = %v, want 0", got)
+	}
+	if got := meter.value("msg.lookup.duration", string(ChineseSimplified)); got < 0 || got > time.Second.Seconds() {
+		t.Errorf("msg.lookup.duration{locale=zh-Hans} = %v, want the lookup durations in seconds", got)
+	}
+}
@@ -0,0 +1,96 @@
+package msg
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// MessageSetter 是支持在运行时覆盖消息的 PrinterFactory（可选接口）。
+//
+// 覆盖的消息优先于翻译文件中的译文，设置后已创建的 Printer 立即生效，
+// 用于紧急修正文案或 A/B 测试，无需重新部署。
+// 内置的 fmt 实现和 xtext.PrinterFactory 都实现了此接口。
+type MessageSetter interface {
+	// SetMessage 设置 locale 下消息 id 的文本，text 可以包含与原文相同的格式化动词
+	SetMessage(locale Locale, id, text string) error
+
+	// RemoveMessage 移除覆盖的消息，恢复使用翻译文件中的译文
+	RemoveMessage(locale Locale, id string) error
+}
+
+// SetMessage 在运行时设置 locale 下消息 id 的文本，优先于翻译文件中的译文。
+// 驱动工厂没有实现 MessageSetter 时返回错误。
+//
+// 使用示例：
+//
+//	// 紧急修正文案
+//	manager.SetMessage(msg.Locale("zh-CN"), "Checkout", "去结算")
+//
+//	// 恢复翻译文件中的译文
+//	manager.RemoveMessage(msg.Locale("zh-CN"), "Checkout")
+func (m *Manager) SetMessage(locale Locale, id, text string) error {
+	m.mu.RLock()
+	factory := m.factory
+	m.mu.RUnlock()
+
+	m.log(context.Background(), slog.LevelInfo, "Setting message override", slog.String(LogKeyLocale, string(locale)), slog.String(LogKeyID, id))
+	return factory.SetMessage(locale, id, text)
+}
+
+// RemoveMessage 移除 SetMessage 设置的消息。
+// 驱动工厂没有实现 MessageSetter 时返回错误。
+func (m *Manager) RemoveMessage(locale Locale, id string) error {
+	m.mu.RLock()
+	factory := m.factory
+	m.mu.RUnlock()
+
+	m.log(context.Background(), slog.LevelInfo, "Removing message override", slog.String(LogKeyLocale, string(locale)), slog.String(LogKeyID, id))
+	return factory.RemoveMessage(locale, id)
+}
+
+// overrideKey 覆盖消息的键
+type overrideKey struct {
+	locale Locale
+	id     string
+}
+
+// SetMessage 实现 MessageSetter 接口，委托给自定义工厂；
+// 内置模式下覆盖的消息只对完全相同的语言生效。
+func (f *simplePrinterFactory) SetMessage(locale Locale, id, text string) error {
+	if c := f.loadCustom(); c != nil {
+		setter, ok := c.(MessageSetter)
+		if !ok {
+			return fmt.Errorf("printer factory %T does not support runtime messages", c)
+		}
+		return setter.SetMessage(locale, id, text)
+	}
+
+	f.overrides.Store(overrideKey{locale, id}, text)
+	return nil
+}
+
+// RemoveMessage 实现 MessageSetter 接口
+func (f *simplePrinterFactory) RemoveMessage(locale Locale, id string) error {
+	if c := f.loadCustom(); c != nil {
+		setter, ok := c.(MessageSetter)
+		if !ok {
+			return fmt.Errorf("printer factory %T does not support runtime messages", c)
+		}
+		return setter.RemoveMessage(locale, id)
+	}
+
+	f.overrides.Delete(overrideKey{locale, id})
+	return nil
+}
+
+// message 返回覆盖后的消息文本，没有覆盖时返回 format 本身
+func (d *simplePrinter) message(format string) string {
+	if d.overrides == nil {
+		return format
+	}
+	if text, ok := d.overrides.Load(overrideKey{d.locale, format}); ok {
+		return text.(string)
+	}
+	return format
+}
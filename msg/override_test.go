@@ -0,0 +1,38 @@
+package msg
+
+import (
+	"testing"
+)
+
+func TestManager_SetMessage(t *testing.T) {
+	manager := NewManager(ManagerConfig{LogFunc: func(string) {}})
+	zh := Locale("zh-CN")
+
+	if err := manager.SetMessage(zh, "Hello, %s", "你好，%s"); err != nil {
+		t.Fatalf("SetMessage() error = %v", err)
+	}
+	printer := manager.GetPrinter(zh)
+	if got := printer.Sprintf("Hello, %s", "World"); got != "你好，World" {
+		t.Errorf("Sprintf() = %q, want %q", got, "你好，World")
+	}
+	if got := manager.GetPrinter(English).Sprintf("Hello, %s", "World"); got != "Hello, World" {
+		t.Errorf("English Sprintf() = %q, want %q", got, "Hello, World")
+	}
+
+	if err := manager.RemoveMessage(zh, "Hello, %s"); err != nil {
+		t.Fatalf("RemoveMessage() error = %v", err)
+	}
+	if got := printer.Sprintf("Hello, %s", "World"); got != "Hello, World" {
+		t.Errorf("Sprintf() after RemoveMessage = %q, want %q", got, "Hello, World")
+	}
+}
+
+func TestManager_SetMessageUnsupported(t *testing.T) {
+	manager := NewManager(ManagerConfig{LogFunc: func(string) {}, Factory: sliceFactory{}})
+	if err := manager.SetMessage(English, "Hello", "Hi"); err == nil {
+		t.Error("SetMessage() with unsupported factory should return error")
+	}
+	if err := manager.RemoveMessage(English, "Hello"); err == nil {
+		t.Error("RemoveMessage() with unsupported factory should return error")
+	}
+}
@@ -39,6 +39,7 @@ package msg
 import (
 	"fmt"
 	"io"
+	"sync"
 )
 
 // Localizer 定义了本地化相关的基本接口。
@@ -325,7 +326,8 @@ func NewPrinter(locale Locale) Printer {
 }
 
 type simplePrinter struct {
-	locale Locale
+	locale    Locale
+	overrides *sync.Map // 工厂中运行时覆盖的消息，为 nil 时不覆盖
 }
 
 // Language 返回驱动支持的语言
@@ -334,50 +336,50 @@ func (d *simplePrinter) Locale() Locale {
 }
 
 // Sprint 类似于 fmt.Sprint，但不添加空格分隔符
-func (*simplePrinter) Sprint(args ...any) string {
+func (d *simplePrinter) Sprint(args ...any) string {
 	var result string
-	for _, arg := range args {
+	for _, arg := range LocalizeArgs(d, args) {
 		result += fmt.Sprint(arg)
 	}
 	return result
 }
 
 // Sprintf 类似于 fmt.Sprintf
-func (*simplePrinter) Sprintf(format string, args ...any) string {
-	return fmt.Sprintf(format, args...)
+func (d *simplePrinter) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(d.message(format), LocalizeArgs(d, args)...)
 }
 
 // Sprintln 类似于 fmt.Sprintln
-func (*simplePrinter) Sprintln(args ...any) string {
-	return fmt.Sprintln(args...)
+func (d *simplePrinter) Sprintln(args ...any) string {
+	return fmt.Sprintln(LocalizeArgs(d, args)...)
 }
 
 // Fprint 类似于 fmt.Fprint
-func (*simplePrinter) Fprint(w io.Writer, args ...any) (n int, err error) {
-	return fmt.Fprint(w, args...)
+func (d *simplePrinter) Fprint(w io.Writer, args ...any) (n int, err error) {
+	return fmt.Fprint(w, LocalizeArgs(d, args)...)
 }
 
 // Fprintf 类似于 fmt.Fprintf
-func (*simplePrinter) Fprintf(w io.Writer, format string, args ...any) (n int, err error) {
-	return fmt.Fprintf(w, format, args...)
+func (d *simplePrinter) Fprintf(w io.Writer, format string, args ...any) (n int, err error) {
+	return fmt.Fprintf(w, d.message(format), LocalizeArgs(d, args)...)
 }
 
 // Fprintln 类似于 fmt.Fprintln
-func (*simplePrinter) Fprintln(w io.Writer, args ...any) (n int, err error) {
-	return fmt.Fprintln(w, args...)
+func (d *simplePrinter) Fprintln(w io.Writer, args ...any) (n int, err error) {
+	return fmt.Fprintln(w, LocalizeArgs(d, args)...)
 }
 
 // Print 类似于 fmt.Print
-func (*simplePrinter) Print(args ...any) (n int, err error) {
-	return fmt.Print(args...)
+func (d *simplePrinter) Print(args ...any) (n int, err error) {
+	return fmt.Print(LocalizeArgs(d, args)...)
 }
 
 // Printf 类似于 fmt.Printf
-func (*simplePrinter) Printf(format string, args ...any) (n int, err error) {
-	return fmt.Printf(format, args...)
+func (d *simplePrinter) Printf(format string, args ...any) (n int, err error) {
+	return fmt.Printf(d.message(format), LocalizeArgs(d, args)...)
 }
 
 // Println 类似于 fmt.Println
-func (*simplePrinter) Println(args ...any) (n int, err error) {
-	return fmt.Println(args...)
+func (d *simplePrinter) Println(args ...any) (n int, err error) {
+	return fmt.Println(LocalizeArgs(d, args)...)
 }
@@ -1,6 +1,7 @@
 package msg
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 )
@@ -161,6 +162,46 @@ type PrinterFactory interface {
 	GetFallbackLocale() Locale
 }
 
+// ContextPrinterFactory 是创建 Printer 时响应 ctx 取消和截止时间的 PrinterFactory。
+//
+// 创建 Printer 可能需要加载翻译数据（如从远程或数据库翻译源），
+// 工厂实现了此接口时，Manager.AcquirePrinter 使用 CreatePrinterContext，避免阻塞请求。
+type ContextPrinterFactory interface {
+	PrinterFactory
+
+	// CreatePrinterContext 与 CreatePrinter 相同，但 ctx 先结束时不再等待，返回 ctx.Err()
+	CreatePrinterContext(ctx context.Context, locale Locale) (Printer, error)
+}
+
+// createPrinterContext 使用 factory 创建 Printer，ctx 先结束时不再等待并返回 ctx.Err()。
+//
+// factory 实现了 ContextPrinterFactory 时由它处理 ctx；否则在新的 goroutine 中调用 CreatePrinter，
+// ctx 结束后创建仍在后台继续进行，工厂缓存了结果时后续请求可以直接使用。
+func createPrinterContext(ctx context.Context, factory PrinterFactory, locale Locale) (Printer, error) {
+	if f, ok := factory.(ContextPrinterFactory); ok {
+		return f.CreatePrinterContext(ctx, locale)
+	}
+	if ctx.Done() == nil {
+		return factory.CreatePrinter(locale)
+	}
+
+	type result struct {
+		printer Printer
+		err     error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		printer, err := factory.CreatePrinter(locale)
+		ch <- result{printer, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.printer, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // simplePrinterFactory 基于 fmt 驱动的工厂实现。
 //
 // 这是一个包装器工厂，支持两种模式：
@@ -185,10 +226,11 @@ type PrinterFactory interface {
 // - 在开发阶段使用，生产环境替换为专业引擎
 // - 需要基本格式化但不需要翻译的应用
 type simplePrinterFactory struct {
-	mu       sync.RWMutex   // 读写锁，保护 custom 字段的并发访问
-	custom   PrinterFactory // 可选的自定义工厂，为 nil 时使用内置实现
-	fallback atomic.Value   // 原子值存储回退语言，类型为 Locale
-	printers sync.Map       // 缓存映射，只在内置模式下使用，支持所有语言的 Printer
+	mu        sync.RWMutex   // 读写锁，保护 custom 字段的并发访问
+	custom    PrinterFactory // 可选的自定义工厂，为 nil 时使用内置实现
+	fallback  atomic.Value   // 原子值存储回退语言，类型为 Locale
+	printers  sync.Map       // 缓存映射，只在内置模式下使用，支持所有语言的 Printer
+	overrides sync.Map       // 运行时覆盖的消息，只在内置模式下使用，key 为 overrideKey
 }
 
 // NewPrinterFactory 创建新的 fmt 驱动工厂实例。
@@ -260,6 +302,17 @@ func (f *simplePrinterFactory) SetCustom(custom PrinterFactory) {
 	}
 }
 
+// clone 返回使用相同自定义工厂和回退语言的副本，内置模式覆盖的消息被复制，Printer 缓存不复制
+func (f *simplePrinterFactory) clone() *simplePrinterFactory {
+	c := &simplePrinterFactory{custom: f.loadCustom()}
+	c.fallback.Store(f.GetFallbackLocale())
+	f.overrides.Range(func(key, value any) bool {
+		c.overrides.Store(key, value)
+		return true
+	})
+	return c
+}
+
 // loadCustom 安全地加载当前的自定义工厂。
 //
 // 这是一个内部辅助方法，使用读锁确保在并发环境下
@@ -331,9 +384,19 @@ func (f *simplePrinterFactory) CreatePrinter(locale Locale) (Printer, error) {
 	}
 
 	// 缓存未命中，创建新的 Printer 实例
-	printer := NewPrinter(locale)
-	f.printers.Store(locale, printer)
-	return printer, nil
+	printer, _ := f.printers.LoadOrStore(locale, &simplePrinter{locale: locale, overrides: &f.overrides})
+	return printer.(Printer), nil
+}
+
+// CreatePrinterContext 实现 ContextPrinterFactory 接口。
+//
+// 自定义模式下委托给自定义工厂，自定义工厂没有实现 ContextPrinterFactory 时不再等待 ctx 结束后的创建；
+// 内置模式下不需要加载数据，与 CreatePrinter 相同。
+func (f *simplePrinterFactory) CreatePrinterContext(ctx context.Context, locale Locale) (Printer, error) {
+	if c := f.loadCustom(); c != nil {
+		return createPrinterContext(ctx, c, locale)
+	}
+	return f.CreatePrinter(locale)
 }
 
 // SupportsLocale 检查工厂是否支持指定的语言环境。
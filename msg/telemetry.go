@@ -0,0 +1,205 @@
+package msg
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Telemetry 接收翻译查找的指标，通过 ManagerConfig.EnableTelemetry 启用。
+//
+// 实现需要是并发安全的，并且应当尽快返回。接口与具体的指标系统无关，
+// 内置 TelemetryCounters（内存统计）和 OTelTelemetry（OpenTelemetry）两种实现。
+//
+// Manager 传入的语言是实际使用的语言：工厂支持的语言中与之匹配的那一个，
+// 工厂不限制语言时为规范化后的基础语言，因此语言的集合是有限的，可以直接作为指标的维度，
+// 不会随请求中任意的语言标签增长。
+//
+// 工厂实现 MissingReporterAdder（如 xtext.PrinterFactory）时，Manager 自动将 Telemetry
+// 添加为工厂的缺失翻译报告器，不需要再通过 xtext.Missing 配置。
+type Telemetry interface {
+	MissingReporter
+
+	// RecordLookup 记录一次 Printer 获取，cacheHit 表示命中了 Manager 的 Printer 缓存，
+	// latency 为获取 Printer 的耗时
+	RecordLookup(locale Locale, cacheHit bool, latency time.Duration)
+
+	// RecordFallback 记录请求的语言不受支持、改用回退语言 used 的情况。
+	// requested 为请求中原样的语言标签，可能是任意的值，不应作为指标的维度
+	RecordFallback(requested, used Locale)
+}
+
+// undetermined 是无法与工厂支持的语言匹配时，指标使用的语言
+const undetermined Locale = "und"
+
+// telemetryLocale 返回记录 locale 的指标时使用的语言：factory 支持的语言中包含 locale 的最具体的一个，
+// 没有时为第一个被 locale 包含的语言（与 checkFactorySupport 的匹配规则相同）；
+// factory 不限制语言时为 locale 规范化后的基础语言，都不匹配时为 "und"
+func telemetryLocale(factory PrinterFactory, locale Locale) Locale {
+	canonical, err := locale.Canonicalize()
+	if err != nil {
+		return undetermined
+	}
+	var supported LocaleSet
+	if factory != nil {
+		supported = factory.SupportedLocales()
+	}
+	if supported == nil {
+		if language := canonical.Language(); language != "" {
+			return Locale(language)
+		}
+		return undetermined
+	}
+
+	matched := undetermined
+	for _, s := range supported {
+		if s.Contains(canonical) && (matched == undetermined || len(s) > len(matched)) {
+			matched = s
+		}
+	}
+	if matched == undetermined {
+		if i := slices.IndexFunc(supported, canonical.Contains); i >= 0 {
+			matched = supported[i]
+		}
+	}
+	return matched
+}
+
+// telemetryMissing 将工厂报告的缺失翻译转换为 telemetryLocale 后记录到 Telemetry
+type telemetryMissing struct {
+	telemetry Telemetry
+	factory   PrinterFactory
+}
+
+// ReportMissing 实现 MissingReporter 接口
+func (r *telemetryMissing) ReportMissing(locale Locale, id string) {
+	r.telemetry.ReportMissing(telemetryLocale(r.factory, locale), id)
+}
+
+// TelemetryLatencyBuckets TelemetryCounters 统计获取 Printer 耗时的区间上限，
+// 在 NewTelemetryCounters 时复制，之后修改不影响已创建的实例
+var TelemetryLatencyBuckets = []time.Duration{
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// LocaleTelemetry 是一种语言的翻译查找指标。
+type LocaleTelemetry struct {
+	Locale    Locale
+	Lookups   int64 // Printer 获取次数
+	CacheHits int64 // 命中 Printer 缓存的次数
+	Fallbacks int64 // 不受支持的语言改用该语言的次数
+	Missing   int64 // 缺失翻译的次数
+
+	// Latency 获取 Printer 耗时的直方图，第 i 个元素为不超过 Buckets[i] 的次数，
+	// 最后一个元素为超过所有区间上限的次数
+	Latency []int64
+	Buckets []time.Duration
+}
+
+// CacheHitRatio 返回缓存命中率，没有查找时返回 0
+func (t LocaleTelemetry) CacheHitRatio() float64 {
+	if t.Lookups == 0 {
+		return 0
+	}
+	return float64(t.CacheHits) / float64(t.Lookups)
+}
+
+// TelemetryCounters 是在内存中按语言统计的 Telemetry，
+// 未配置 ManagerConfig.Telemetry 时 Manager 使用它，可以通过 Manager.Telemetry 读取。
+//
+// 使用示例：
+//
+//	manager := msg.NewManager(msg.ManagerConfig{EnableTelemetry: true})
+//	counters := manager.Telemetry().(*msg.TelemetryCounters)
+//	for _, t := range counters.Snapshot() {
+//	    log.Printf("%s lookups=%d hit=%.0f%%", t.Locale, t.Lookups, t.CacheHitRatio()*100)
+//	}
+type TelemetryCounters struct {
+	mu      sync.Mutex
+	buckets []time.Duration
+	stats   map[Locale]*LocaleTelemetry
+}
+
+// NewTelemetryCounters 创建 TelemetryCounters
+func NewTelemetryCounters() *TelemetryCounters {
+	return &TelemetryCounters{
+		buckets: slices.Clone(TelemetryLatencyBuckets),
+		stats:   make(map[Locale]*LocaleTelemetry),
+	}
+}
+
+// locale 返回 locale 的统计，调用方需要持有锁
+func (c *TelemetryCounters) locale(locale Locale) *LocaleTelemetry {
+	t, ok := c.stats[locale]
+	if !ok {
+		t = &LocaleTelemetry{Locale: locale, Latency: make([]int64, len(c.buckets)+1), Buckets: c.buckets}
+		c.stats[locale] = t
+	}
+	return t
+}
+
+// RecordLookup 实现 Telemetry 接口
+func (c *TelemetryCounters) RecordLookup(locale Locale, cacheHit bool, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := c.locale(locale)
+	t.Lookups++
+	if cacheHit {
+		t.CacheHits++
+	}
+	i, _ := slices.BinarySearch(c.buckets, latency)
+	t.Latency[i]++
+}
+
+// RecordFallback 实现 Telemetry 接口，按回退使用的语言计数
+func (c *TelemetryCounters) RecordFallback(requested, used Locale) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.locale(used).Fallbacks++
+}
+
+// ReportMissing 实现 MissingReporter 接口
+func (c *TelemetryCounters) ReportMissing(locale Locale, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.locale(locale).Missing++
+}
+
+// Snapshot 返回当前的统计，按语言排序
+func (c *TelemetryCounters) Snapshot() []LocaleTelemetry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]LocaleTelemetry, 0, len(c.stats))
+	for _, t := range c.stats {
+		s := *t
+		s.Latency = slices.Clone(t.Latency)
+		result = append(result, s)
+	}
+	slices.SortFunc(result, func(a, b LocaleTelemetry) int {
+		return cmp.Compare(a.Locale, b.Locale)
+	})
+	return result
+}
+
+// Reset 清空统计
+func (c *TelemetryCounters) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.stats)
+}
+
+// Telemetry 返回 Manager 使用的 Telemetry，未启用时返回 nil
+func (m *Manager) Telemetry() Telemetry {
+	return m.telemetry
+}
@@ -0,0 +1,171 @@
+package msg
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// limitedFactory 只支持指定语言的 PrinterFactory
+type limitedFactory struct {
+	PrinterFactory
+	supported LocaleSet
+}
+
+func (f *limitedFactory) SupportedLocales() LocaleSet {
+	return f.supported
+}
+
+func TestManager_Telemetry(t *testing.T) {
+	factory := &limitedFactory{PrinterFactory: NewPrinterFactory(), supported: LocaleSet{English, ChineseSimplified}}
+	manager := NewManager(ManagerConfig{LogFunc: func(string) {}, Factory: factory, EnableTelemetry: true})
+	counters, ok := manager.Telemetry().(*TelemetryCounters)
+	if !ok {
+		t.Fatalf("Telemetry() = %T, want *TelemetryCounters", manager.Telemetry())
+	}
+
+	zh := WithLocaleContext(context.Background(), ChineseSimplified)
+	ja := WithLocaleContext(context.Background(), Japanese)
+	manager.GetPrinterWithContext(zh)
+	manager.GetPrinterWithContext(zh)
+	manager.GetPrinterWithContext(zh)
+	manager.GetPrinterWithContext(ja)
+	manager.GetPrinter(French)
+	counters.ReportMissing(ChineseSimplified, "Checkout")
+
+	snapshot := counters.Snapshot()
+	if len(snapshot) != 3 {
+		t.Fatalf("Snapshot() = %+v, want 3 locales", snapshot)
+	}
+	byLocale := map[Locale]LocaleTelemetry{}
+	for _, s := range snapshot {
+		byLocale[s.Locale] = s
+	}
+
+	if s := byLocale[ChineseSimplified]; s.Lookups != 3 || s.CacheHits != 2 || s.Fallbacks != 0 || s.Missing != 1 {
+		t.Errorf("zh-Hans telemetry = %+v, want 3 lookups, 2 hits, 1 missing", s)
+	}
+	// 不受支持的语言按实际使用的语言统计，请求中任意的语言不会增加统计的语言
+	if s := byLocale[English]; s.Lookups != 1 || s.CacheHits != 0 || s.Fallbacks != 1 {
+		t.Errorf("en telemetry = %+v, want 1 lookup and 1 fallback", s)
+	}
+	if s := byLocale[undetermined]; s.Lookups != 1 || s.CacheHits != 0 {
+		t.Errorf("und telemetry = %+v, want 1 lookup", s)
+	}
+
+	var observed int64
+	for _, n := range byLocale[ChineseSimplified].Latency {
+		observed += n
+	}
+	if observed != 3 {
+		t.Errorf("latency histogram observations = %d, want 3", observed)
+	}
+	if got := byLocale[ChineseSimplified].CacheHitRatio(); got < 0.66 || got > 0.67 {
+		t.Errorf("CacheHitRatio() = %v, want 2/3", got)
+	}
+
+	counters.Reset()
+	if got := counters.Snapshot(); len(got) != 0 {
+		t.Errorf("Snapshot() after Reset = %+v, want empty", got)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		manager := NewManager(ManagerConfig{LogFunc: func(string) {}})
+		if manager.Telemetry() != nil {
+			t.Error("Telemetry() should be nil when telemetry is disabled")
+		}
+	})
+}
+
+// reportingFactory 是实现 MissingReporterAdder 的 limitedFactory
+type reportingFactory struct {
+	limitedFactory
+	reporters []MissingReporter
+}
+
+func (f *reportingFactory) AddMissingReporter(reporter MissingReporter) {
+	f.reporters = append(f.reporters, reporter)
+}
+
+func (f *reportingFactory) reportMissing(locale Locale, id string) {
+	for _, r := range f.reporters {
+		r.ReportMissing(locale, id)
+	}
+}
+
+func TestManager_TelemetryMissing(t *testing.T) {
+	factory := &reportingFactory{limitedFactory: limitedFactory{PrinterFactory: NewPrinterFactory(), supported: LocaleSet{English, ChineseSimplified}}}
+	manager := NewManager(ManagerConfig{LogFunc: func(string) {}, Factory: factory, EnableTelemetry: true})
+	if len(factory.reporters) != 1 {
+		t.Fatalf("reporters = %d, want the telemetry added by NewManager", len(factory.reporters))
+	}
+
+	// 再次设置同一个工厂不会重复报告
+	manager.SetPrinterFactory(factory)
+	if len(factory.reporters) != 1 {
+		t.Fatalf("reporters = %d after SetPrinterFactory, want 1", len(factory.reporters))
+	}
+
+	factory.reportMissing("zh-Hans-CN-u-ca-chinese", "Checkout")
+	snapshot := manager.Telemetry().(*TelemetryCounters).Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Locale != ChineseSimplified || snapshot[0].Missing != 1 {
+		t.Errorf("Snapshot() = %+v, want 1 missing for zh-Hans", snapshot)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		factory := &reportingFactory{limitedFactory: limitedFactory{PrinterFactory: NewPrinterFactory()}}
+		NewManager(ManagerConfig{LogFunc: func(string) {}, Factory: factory})
+		if len(factory.reporters) != 0 {
+			t.Errorf("reporters = %d, want none when telemetry is disabled", len(factory.reporters))
+		}
+	})
+}
+
+func TestTelemetryLocale(t *testing.T) {
+	limited := &limitedFactory{PrinterFactory: NewPrinterFactory(), supported: LocaleSet{English, Chinese, ChineseSimplified}}
+	unlimited := &limitedFactory{PrinterFactory: NewPrinterFactory()}
+	tests := []struct {
+		factory PrinterFactory
+		locale  Locale
+		want    Locale
+	}{
+		{limited, "zh-Hans-CN", ChineseSimplified},
+		{limited, "zh-Hans-CN-u-ca-chinese", ChineseSimplified},
+		{limited, "zh-Hant-TW", Chinese},
+		{limited, "EN-us", English},
+		{limited, "ja", undetermined},
+		{limited, "en--US", undetermined},
+		{&limitedFactory{PrinterFactory: NewPrinterFactory(), supported: LocaleSet{ChineseSimplified}}, Chinese, ChineseSimplified},
+		{unlimited, "fr-CA-x-private", French},
+		{unlimited, "", undetermined},
+		{nil, "de-DE", German},
+	}
+	for _, tt := range tests {
+		if got := telemetryLocale(tt.factory, tt.locale); got != tt.want {
+			t.Errorf("telemetryLocale(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestTelemetryCounters_LatencyBuckets(t *testing.T) {
+	c := NewTelemetryCounters()
+	c.RecordLookup(English, false, 5*time.Microsecond)
+	c.RecordLookup(English, false, 10*time.Microsecond)
+	c.RecordLookup(English, false, 2*time.Millisecond)
+	c.RecordLookup(English, false, time.Second)
+
+	s := c.Snapshot()[0]
+	if len(s.Latency) != len(s.Buckets)+1 {
+		t.Fatalf("len(Latency) = %d, want %d", len(s.Latency), len(s.Buckets)+1)
+	}
+	expected := map[int]int64{
+		0:              2, // ≤ 10µs
+		5:              1, // ≤ 5ms
+		len(s.Buckets): 1, // 超过所有区间
+	}
+	for i, n := range s.Latency {
+		if n != expected[i] {
+			t.Errorf("Latency[%d] = %d, want %d", i, n, expected[i])
+		}
+	}
+}
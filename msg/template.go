@@ -0,0 +1,133 @@
+package msg
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// dateTimeStyles 模板函数 date 和 time 接受的风格名称
+var dateTimeStyles = map[string]DateTimeStyle{
+	"short":  StyleShort,
+	"medium": StyleMedium,
+	"long":   StyleLong,
+	"full":   StyleFull,
+}
+
+// FuncMap 返回绑定到上下文语言的模板函数，使用默认 Manager，函数说明见 PrinterFuncMap。
+func FuncMap(ctx context.Context) template.FuncMap {
+	return GetDefaultManager().FuncMap(ctx)
+}
+
+// FuncMap 返回绑定到上下文语言的模板函数，函数说明见 PrinterFuncMap。
+func (m *Manager) FuncMap(ctx context.Context) template.FuncMap {
+	return PrinterFuncMap(m.GetPrinterWithContext(ctx))
+}
+
+// PrinterFuncMap 返回使用 p 翻译和格式化的 html/template 函数，
+// 用于在服务端渲染的页面中直接翻译文本：
+//
+//   - T key [args...]：翻译消息，唯一的参数为 Args 时按命名参数替换，否则按 Sprintf 格式化
+//   - plural key n [args...]：以数量 n 为第一个参数翻译消息，由翻译目录按 n 选择复数形式
+//   - args key value [key value...]：构造 T 使用的命名参数
+//   - number v：按语言格式化数字
+//   - currency amount code：使用 ISO 4217 货币代码格式化金额
+//   - list items [style]：将字符串切片连接为列表，style 为 and（默认）或 or
+//   - date t [style]：格式化日期，style 为 short、medium（默认）、long 或 full
+//   - time t [style]：格式化时间，style 同上
+//
+// 结果是普通字符串，由 html/template 按上下文转义。
+// 模板需要在解析前注册这些函数，渲染时再替换为请求语言的实现：
+//
+//	tmpl := template.Must(template.New("page").Funcs(msg.FuncMap(context.Background())).Parse(
+//	    `<h1>{{ T "Hello, {name}!" (args "name" .Name) }}</h1>
+//	     <p>{{ plural "%d items" .Count }} · {{ currency .Total "EUR" }} · {{ date .Updated "long" }}</p>`))
+//
+//	page := template.Must(tmpl.Clone()).Funcs(msg.FuncMap(r.Context()))
+//	page.Execute(w, data)
+func PrinterFuncMap(p Printer) template.FuncMap {
+	return template.FuncMap{
+		"T": func(key string, args ...any) string {
+			if len(args) == 1 {
+				if named, ok := args[0].(Args); ok {
+					return Sprintn(p, key, named)
+				}
+			}
+			return p.Sprintf(key, args...)
+		},
+		"plural": func(key string, n any, args ...any) string {
+			return p.Sprintf(key, append([]any{n}, args...)...)
+		},
+		"args": templateArgs,
+		"number": func(v any) string {
+			return numberFormatter(p).FormatNumber(v)
+		},
+		"currency": func(amount any, code string) string {
+			return numberFormatter(p).FormatCurrency(amount, code)
+		},
+		"list": func(items []string, style ...string) (string, error) {
+			s, err := templateListStyle(style)
+			if err != nil {
+				return "", err
+			}
+			return SprintList(p, items, s), nil
+		},
+		"date": func(t time.Time, style ...string) (string, error) {
+			s, err := templateStyle(style)
+			if err != nil {
+				return "", err
+			}
+			return NewTimeFormatter(p.Locale()).FormatDate(t, s), nil
+		},
+		"time": func(t time.Time, style ...string) (string, error) {
+			s, err := templateStyle(style)
+			if err != nil {
+				return "", err
+			}
+			return NewTimeFormatter(p.Locale()).FormatTime(t, s), nil
+		},
+	}
+}
+
+// templateArgs 将成对的键和值转换为 Args
+func templateArgs(pairs ...any) (Args, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("msg: args requires key/value pairs, got %d values", len(pairs))
+	}
+	args := make(Args, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("msg: args key %v is not a string", pairs[i])
+		}
+		args[key] = pairs[i+1]
+	}
+	return args, nil
+}
+
+// templateStyle 解析可选的风格名称，未指定时为 StyleMedium
+func templateStyle(style []string) (DateTimeStyle, error) {
+	if len(style) == 0 {
+		return StyleMedium, nil
+	}
+	s, ok := dateTimeStyles[style[0]]
+	if !ok {
+		return 0, fmt.Errorf("msg: unknown date/time style %q", style[0])
+	}
+	return s, nil
+}
+
+// templateListStyle 解析可选的列表连接方式，未指定时为 ListAnd
+func templateListStyle(style []string) (ListStyle, error) {
+	if len(style) == 0 {
+		return ListAnd, nil
+	}
+	switch style[0] {
+	case "and":
+		return ListAnd, nil
+	case "or":
+		return ListOr, nil
+	}
+	return 0, fmt.Errorf("msg: unknown list style %q", style[0])
+}
@@ -0,0 +1,67 @@
+package msg
+
+import (
+	"context"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFuncMap(t *testing.T) {
+	manager := NewManager(ManagerConfig{Locale: English, LogFunc: func(string) {}})
+	_ = manager.SetMessage(Chinese, "Hello, {name}!", "你好，{name}！")
+	_ = manager.SetMessage(Chinese, "%d items", "%d 件商品")
+	_ = manager.SetMessage(Chinese, "Hi %s", "嗨 %s")
+
+	tmpl := template.Must(template.New("page").Funcs(manager.FuncMap(context.Background())).Parse(
+		`{{ T "Hello, {name}!" (args "name" .Name) }}|{{ T "Hi %s" .Name }}|{{ plural "%d items" .Count }}|` +
+			`{{ number .Count }}|{{ currency .Total "EUR" }}|{{ date .Updated "long" }}|{{ date .Updated }}|{{ list .Tags "or" }}`))
+
+	data := map[string]any{
+		"Name":    "<Bob>",
+		"Count":   3,
+		"Total":   9.5,
+		"Updated": time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC),
+		"Tags":    []string{"a", "b", "c"},
+	}
+	render := func(ctx context.Context) string {
+		t.Helper()
+		page := template.Must(tmpl.Clone()).Funcs(manager.FuncMap(ctx))
+		var b strings.Builder
+		if err := page.Execute(&b, data); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return b.String()
+	}
+
+	want := "你好，&lt;Bob&gt;！|嗨 &lt;Bob&gt;|3 件商品|3|EUR 9.50|2024年3月5日|" +
+		NewTimeFormatter(Chinese).FormatDate(data["Updated"].(time.Time), StyleMedium) + "|a、b或c"
+	if got := render(WithLocaleContext(context.Background(), Chinese)); got != want {
+		t.Errorf("Chinese page = %q, want %q", got, want)
+	}
+	if got := render(context.Background()); !strings.HasPrefix(got, "Hello, &lt;Bob&gt;!|Hi &lt;Bob&gt;|3 items|") {
+		t.Errorf("English page = %q", got)
+	}
+}
+
+func TestFuncMap_Errors(t *testing.T) {
+	funcs := PrinterFuncMap(NewPrinter(English))
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"Odd args", `{{ T "x" (args "name") }}`},
+		{"Non-string key", `{{ T "x" (args 1 2) }}`},
+		{"Unknown style", `{{ date .Now "tiny" }}`},
+		{"Unknown list style", `{{ list .Tags "nor" }}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("").Funcs(funcs).Parse(tt.text))
+			if err := tmpl.Execute(&strings.Builder{}, map[string]any{"Now": time.Now(), "Tags": []string{"a"}}); err == nil {
+				t.Error("Execute() error = nil, want error")
+			}
+		})
+	}
+}
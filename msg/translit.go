@@ -0,0 +1,153 @@
+package msg
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Transliterator 将一种文字转写为拉丁字母，用于扩展 Transliterate 不内置的文字，如中文拼音。
+type Transliterator interface {
+	// Transliterate 返回 s 转写后的文本，无法转写的字符应原样保留
+	Transliterate(s string) string
+}
+
+// TransliteratorFunc 将函数适配为 Transliterator
+type TransliteratorFunc func(s string) string
+
+// Transliterate 实现 Transliterator 接口
+func (f TransliteratorFunc) Transliterate(s string) string {
+	return f(s)
+}
+
+// Transliterators 按语言子标签（如 "zh"、"ja"）保存 Transliterate 使用的外部转写器，
+// 转写器先于内置规则执行。中文、日文等需要词典的文字没有内置规则，可以在程序初始化时添加，如使用拼音库：
+//
+//	msg.Transliterators["zh"] = msg.TransliteratorFunc(func(s string) string {
+//	    return pinyin.Convert(s) // "你好世界" → "ni hao shi jie"
+//	})
+var Transliterators = map[string]Transliterator{}
+
+// Transliterate 按语言将 s 转写为拉丁字母。
+//
+// 先执行 Transliterators 中该语言的转写器，然后使用内置规则：去掉拉丁字母的变音符号，
+// 将西里尔字母和希腊字母转写为拉丁字母，部分语言使用各自的习惯，如德语 "ä" → "ae"、
+// 乌克兰语 "г" → "h"、保加利亚语 "щ" → "sht"。其它字符原样保留。
+//
+// 示例：
+//
+//	msg.Transliterate(msg.Locale("de"), "Müller Straße") // "Mueller Strasse"
+//	msg.Transliterate(msg.Locale("fr"), "Crème brûlée")  // "Creme brulee"
+//	msg.Transliterate(msg.Locale("ru"), "Москва")        // "Moskva"
+func Transliterate(locale Locale, s string) string {
+	lang := locale.Language()
+	if t, ok := Transliterators[lang]; ok {
+		s = t.Transliterate(s)
+	}
+
+	overrides := translitOverrides[lang]
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range runes {
+		out, ok := lookupTranslit(overrides, r)
+		if ok {
+			b.WriteString(out)
+			continue
+		}
+		lower := unicode.ToLower(r)
+		if out, ok = lookupTranslit(overrides, lower); !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if out != "" {
+			// 全大写的词转写为全大写，否则只将首字母大写，如 "ЖУК" → "ZHUK"，"Жук" → "Zhuk"
+			if i+1 < len(runes) && unicode.IsUpper(runes[i+1]) || i > 0 && unicode.IsUpper(runes[i-1]) {
+				out = strings.ToUpper(out)
+			} else {
+				out = strings.ToUpper(out[:1]) + out[1:]
+			}
+		}
+		b.WriteString(out)
+	}
+	return b.String()
+}
+
+// Slugify 按语言将 s 转换为适合在 URL 中使用的标识，如文章标题 "Crème Brûlée!" → "creme-brulee"。
+//
+// s 先经过 Transliterate 转写，然后转换为小写，字母和数字以外的字符替换为 "-"，
+// 连续的 "-" 合并为一个，撇号被删除。转写后仍不是拉丁字母的文字（如没有设置转写器的中文）保留原文。
+//
+// 示例：
+//
+//	msg.Slugify(msg.Locale("de"), "Grüße aus Köln")  // "gruesse-aus-koeln"
+//	msg.Slugify(msg.Locale("uk"), "Привіт, світе!") // "pryvit-svite"
+//	msg.Slugify(msg.English, "Don't panic")        // "dont-panic"
+func Slugify(locale Locale, s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range Transliterate(locale, s) {
+		switch {
+		case r == '\'' || r == '’' || unicode.Is(unicode.Mn, r):
+			// 撇号和组合用变音符号不分隔单词
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// translitTable 是内置的转写规则，键为小写字母和没有对应小写的大写字母
+var translitTable = buildTranslitTable(
+	// 拉丁字母
+	"àáâãäåāăą", "a", "æ", "ae", "çćĉċč", "c", "ðďđ", "d", "èéêëēĕėęě", "e", "ĝğġģ", "g",
+	"ĥħ", "h", "ìíîïĩīĭįı", "i", "İ", "I", "ĳ", "ij", "ĵ", "j", "ķĸ", "k", "ĺļľŀł", "l",
+	"ñńņňŉŋ", "n", "òóôõöøōŏő", "o", "œ", "oe", "ŕŗř", "r", "śŝşšſș", "s", "ß", "ss",
+	"ţťŧț", "t", "þ", "th", "ùúûüũūŭůűų", "u", "ŵ", "w", "ýÿŷ", "y", "źżž", "z",
+	// 西里尔字母
+	"а", "a", "б", "b", "в", "v", "г", "g", "ґ", "g", "д", "d", "ђ", "dj", "е", "e", "ё", "e",
+	"є", "ie", "ж", "zh", "з", "z", "и", "i", "і", "i", "ї", "i", "й", "i", "ј", "j", "к", "k",
+	"л", "l", "љ", "lj", "м", "m", "н", "n", "њ", "nj", "о", "o", "п", "p", "р", "r", "с", "s",
+	"т", "t", "ћ", "c", "у", "u", "ў", "u", "ф", "f", "х", "kh", "ц", "ts", "ч", "ch", "џ", "dz",
+	"ш", "sh", "щ", "shch", "ъ", "ie", "ы", "y", "ь", "", "э", "e", "ю", "iu", "я", "ia",
+	// 希腊字母
+	"αά", "a", "β", "v", "γ", "g", "δ", "d", "εέ", "e", "ζ", "z", "ηή", "i", "θ", "th",
+	"ιίϊΐ", "i", "κ", "k", "λ", "l", "μ", "m", "ν", "n", "ξ", "x", "οό", "o", "π", "p",
+	"ρ", "r", "σς", "s", "τ", "t", "υύϋΰ", "y", "φ", "f", "χ", "ch", "ψ", "ps", "ωώ", "o",
+)
+
+// translitOverrides 是按语言覆盖 translitTable 的转写规则
+var translitOverrides = map[string]map[rune]string{
+	"de": buildTranslitTable("ä", "ae", "ö", "oe", "ü", "ue"),
+	"da": buildTranslitTable("å", "aa", "ø", "oe"),
+	"nb": buildTranslitTable("å", "aa", "ø", "oe"),
+	"nn": buildTranslitTable("å", "aa", "ø", "oe"),
+	"no": buildTranslitTable("å", "aa", "ø", "oe"),
+	"uk": buildTranslitTable("г", "h", "и", "y", "й", "i", "є", "ie", "ї", "i", "щ", "shch", "ь", ""),
+	"bg": buildTranslitTable("й", "y", "х", "h", "щ", "sht", "ъ", "a", "ю", "yu", "я", "ya"),
+}
+
+// lookupTranslit 查找 r 的转写结果，语言的规则优先
+func lookupTranslit(overrides map[rune]string, r rune) (string, bool) {
+	if out, ok := overrides[r]; ok {
+		return out, true
+	}
+	out, ok := translitTable[r]
+	return out, ok
+}
+
+// buildTranslitTable 由成对的字符集合和转写结果构造转写规则
+func buildTranslitTable(pairs ...string) map[rune]string {
+	table := make(map[rune]string)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		for _, r := range pairs[i] {
+			table[r] = pairs[i+1]
+		}
+	}
+	return table
+}
@@ -0,0 +1,69 @@
+package msg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		input  string
+		want   string
+	}{
+		{English, "Crème Brûlée", "Creme Brulee"},
+		{English, "Łódź, Ærøskøbing", "Lodz, Aeroskobing"},
+		{English, "İstanbul", "Istanbul"},
+		{German, "Müller Straße", "Mueller Strasse"},
+		{German, "ÄRGER", "AERGER"},
+		{English, "Müller", "Muller"},
+		{"da", "Århus", "Aarhus"},
+		{Russian, "Москва", "Moskva"},
+		{Russian, "ЖУК и Щука", "ZHUK i Shchuka"},
+		{"uk", "Київ, Харків", "Kyiv, Kharkiv"},
+		{"bg", "България", "Balgariya"},
+		{"el", "Αθήνα", "Athina"},
+		{Chinese, "你好 world", "你好 world"},
+	}
+	for _, tt := range tests {
+		if got := Transliterate(tt.locale, tt.input); got != tt.want {
+			t.Errorf("%s Transliterate(%q) = %q, want %q", tt.locale, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTransliterators(t *testing.T) {
+	Transliterators["zh"] = TransliteratorFunc(func(s string) string {
+		return strings.NewReplacer("你好", " ni hao ", "世界", " shi jie ").Replace(s)
+	})
+	defer delete(Transliterators, "zh")
+
+	if got := Slugify(Chinese, "你好，世界！"); got != "ni-hao-shi-jie" {
+		t.Errorf("Slugify() = %q, want ni-hao-shi-jie", got)
+	}
+	if got := Slugify(Japanese, "你好"); got != "你好" {
+		t.Errorf("Slugify() = %q, want the transliterator to apply to zh only", got)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		input  string
+		want   string
+	}{
+		{English, "Hello, World!", "hello-world"},
+		{English, "  Don't panic -- it's 2024  ", "dont-panic-its-2024"},
+		{English, "Crème brûlée", "creme-brulee"},
+		{German, "Grüße aus Köln", "gruesse-aus-koeln"},
+		{"uk", "Привіт, світе!", "pryvit-svite"},
+		{Russian, "Съешь же ещё этих", "sieesh-zhe-eshche-etikh"},
+		{Chinese, "你好 世界", "你好-世界"},
+		{English, "!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.locale, tt.input); got != tt.want {
+			t.Errorf("%s Slugify(%q) = %q, want %q", tt.locale, tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package xtext
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// CollateOption 是 NewCollator 的选项，调整字符串比较的规则
+type CollateOption struct {
+	opt collate.Option
+}
+
+var (
+	// IgnoreCase 忽略大小写，如 "a" 与 "A" 相等
+	IgnoreCase = CollateOption{collate.IgnoreCase}
+	// IgnoreDiacritics 忽略变音符号，如 "e" 与 "é" 相等
+	IgnoreDiacritics = CollateOption{collate.IgnoreDiacritics}
+	// IgnoreWidth 忽略全角和半角的区别，如 "Ａ" 与 "A" 相等
+	IgnoreWidth = CollateOption{collate.IgnoreWidth}
+	// Loose 同时忽略大小写、变音符号和全角半角的区别
+	Loose = CollateOption{collate.Loose}
+	// Numeric 按数值比较数字，如 "file2" 排在 "file10" 之前
+	Numeric = CollateOption{collate.Numeric}
+)
+
+// Collator 按语言环境的 Unicode 排序规则（CLDR/ICU）比较和排序字符串，
+// 用于对姓名、分类等面向用户的列表排序，而不是按字节顺序。
+//
+// 例如德语中 "ä" 排在 "a" 之后、"b" 之前，瑞典语中 "ä" 排在 "z" 之后，
+// 简体中文按拼音排序。Locale 中的 co、kn 等 Unicode 扩展键同样生效，
+// 如 "de-u-co-phonebk" 使用德语电话簿排序，"en-u-kn-true" 按数值比较数字。
+// Collator 可以在多个 goroutine 中并发使用。
+//
+// 示例：
+//
+//	c := xtext.NewCollator(msg.Locale("sv"), xtext.IgnoreCase)
+//	names := []string{"Östen", "Anna", "Åke", "Zoe"}
+//	c.SortStrings(names) // [Anna Zoe Åke Östen]
+type Collator struct {
+	mu     sync.Mutex
+	locale msg.Locale
+	c      *collate.Collator
+	buf    collate.Buffer
+}
+
+// NewCollator 创建指定语言环境的 Collator，不支持的语言使用 Unicode 默认排序规则（DUCET）
+func NewCollator(locale msg.Locale, opts ...CollateOption) *Collator {
+	options := make([]collate.Option, 0, len(opts))
+	for _, o := range opts {
+		options = append(options, o.opt)
+	}
+	return &Collator{
+		locale: locale,
+		c:      collate.New(language.Make(locale.String()), options...),
+	}
+}
+
+// Locale 返回排序使用的语言环境
+func (c *Collator) Locale() msg.Locale {
+	return c.locale
+}
+
+// Compare 比较 a 和 b，a 排在 b 之前时返回 -1，相等时返回 0，否则返回 1
+func (c *Collator) Compare(a, b string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.c.CompareString(a, b)
+}
+
+// SortStrings 按排序规则对 s 进行稳定排序
+func (c *Collator) SortStrings(s []string) {
+	SortFunc(c, s, func(v string) string { return v })
+}
+
+// Key 返回 s 的排序键，两个字符串的排序键按字节比较的结果与 Compare 相同。
+//
+// 排序键可以存储在数据库中，用于按本地化的顺序排序或建立索引：
+//
+//	db.Exec("UPDATE users SET name_key = ? WHERE id = ?", c.Key(user.Name), user.ID)
+func (c *Collator) Key(s string) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := slices.Clone(c.c.KeyFromString(&c.buf, s))
+	c.buf.Reset()
+	return key
+}
+
+// SortFunc 按 key 返回的字符串和 c 的排序规则对 s 进行稳定排序，用于对结构体切片排序。
+//
+// 每个元素的排序键只计算一次。
+//
+// 示例：
+//
+//	xtext.SortFunc(xtext.NewCollator(msg.Chinese), users, func(u User) string {
+//	    return u.Name
+//	})
+func SortFunc[T any](c *Collator, s []T, key func(T) string) {
+	type keyed struct {
+		key []byte
+		v   T
+	}
+	items := make([]keyed, len(s))
+	for i, v := range s {
+		items[i] = keyed{key: c.Key(key(v)), v: v}
+	}
+	slices.SortStableFunc(items, func(a, b keyed) int {
+		return bytes.Compare(a.key, b.key)
+	})
+	for i, item := range items {
+		s[i] = item.v
+	}
+}
@@ -0,0 +1,75 @@
+package xtext
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestCollator(t *testing.T) {
+	tests := []struct {
+		locale msg.Locale
+		opts   []CollateOption
+		input  []string
+		want   []string
+	}{
+		{"en", nil, []string{"banana", "Apple", "cherry", "apple"}, []string{"apple", "Apple", "banana", "cherry"}},
+		{"de", nil, []string{"Zebra", "Äpfel", "Affe", "Bär"}, []string{"Affe", "Äpfel", "Bär", "Zebra"}},
+		{"sv", nil, []string{"Östen", "Anna", "Åke", "Zoe"}, []string{"Anna", "Zoe", "Åke", "Östen"}},
+		{"zh", nil, []string{"张三", "李四", "王五", "阿明"}, []string{"阿明", "李四", "王五", "张三"}},
+		{"en", []CollateOption{Numeric}, []string{"file10", "file2", "file1"}, []string{"file1", "file2", "file10"}},
+		{"en-u-kn-true", nil, []string{"file10", "file2"}, []string{"file2", "file10"}},
+	}
+	for _, tt := range tests {
+		c := NewCollator(tt.locale, tt.opts...)
+		got := slices.Clone(tt.input)
+		c.SortStrings(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s SortStrings(%q) = %q, want %q", tt.locale, tt.input, got, tt.want)
+		}
+	}
+
+	c := NewCollator("fr", IgnoreCase, IgnoreDiacritics)
+	if got := c.Compare("Élève", "eleve"); got != 0 {
+		t.Errorf("Compare() = %d, want 0 with IgnoreCase and IgnoreDiacritics", got)
+	}
+	if got := NewCollator("fr").Compare("Élève", "eleve"); got == 0 {
+		t.Error("Compare() = 0, want a difference without options")
+	}
+	if got := c.Locale(); got != "fr" {
+		t.Errorf("Locale() = %q, want fr", got)
+	}
+}
+
+func TestCollator_Key(t *testing.T) {
+	c := NewCollator("de")
+	words := []string{"Zebra", "Äpfel", "Affe", "Bär", "äpfel"}
+	for _, a := range words {
+		for _, b := range words {
+			want := c.Compare(a, b)
+			if got := bytes.Compare(c.Key(a), c.Key(b)); got != want {
+				t.Errorf("Key(%q) vs Key(%q) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestSortFunc(t *testing.T) {
+	type user struct {
+		Name string
+		ID   int
+	}
+	users := []user{{"Émile", 1}, {"Zoé", 2}, {"Adèle", 3}, {"emile", 4}}
+	SortFunc(NewCollator("fr", IgnoreCase, IgnoreDiacritics), users, func(u user) string { return u.Name })
+
+	var ids []int
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	// Equal names keep their order
+	if want := []int{3, 1, 4, 2}; !slices.Equal(ids, want) {
+		t.Errorf("SortFunc() order = %v, want %v", ids, want)
+	}
+}
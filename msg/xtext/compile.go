@@ -0,0 +1,89 @@
+package xtext
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+
+	"go-slim.dev/infra/msg"
+)
+
+// WriteGo 将工厂中所有翻译源的译文编译为 Go 源文件写入 w，pkg 为生成文件的包名。
+//
+// 生成的文件包含以语言和消息 ID 为键的 Translations 变量，以及将其注册到工厂的 Register 函数。
+// 程序使用生成的代码时不需要读取翻译文件，也不需要在启动时解析它们：
+//
+//	factory := xtext.NewPrinterFactory()
+//	i18n.Register(factory)
+//
+// 消息的选取与 Coverage 一致：同一语言中同一消息出现多次时与加载时的选择相同，
+// 未翻译的消息不会被写入。加载器未实现 MessageParser 的文件和通过 SetTranslation
+// 等方法在运行时设置的翻译不参与编译；有文件解析失败时返回错误，不写入任何内容。
+//
+// 示例：
+//
+//	factory := xtext.NewPrinterFactory(xtext.BaseDir("locales"))
+//	var buf bytes.Buffer
+//	if err := factory.WriteGo(&buf, "i18n"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	os.WriteFile("i18n/translations.gen.go", buf.Bytes(), 0o644)
+func (f *PrinterFactory) WriteGo(w io.Writer, pkg string) error {
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	var errs []error
+	catalogs := f.collectMessages(func(s string) {
+		errs = append(errs, errors.New(s))
+	})
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by xtext.WriteGo. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\t\"go-slim.dev/infra/msg\"\n\t\"go-slim.dev/infra/msg/xtext\"\n)\n\n")
+
+	b.WriteString("// Translations 是编译的翻译，键为语言，值为消息 ID 到译文的映射\n")
+	b.WriteString("var Translations = map[msg.Locale]map[string]string{\n")
+	locales := slices.SortedFunc(maps.Keys(catalogs), func(a, b msg.Locale) int {
+		return cmp.Compare(a, b)
+	})
+	for _, locale := range locales {
+		c := catalogs[locale]
+		ids := slices.DeleteFunc(slices.Sorted(maps.Keys(c)), func(id string) bool {
+			return c[id].Translation == ""
+		})
+		if len(ids) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: {\n", strconv.Quote(string(locale)))
+		for _, id := range ids {
+			fmt.Fprintf(&b, "%s: %s,\n", strconv.Quote(id), strconv.Quote(c[id].Translation))
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// Register 将编译的翻译添加到 factory，每种语言替换已有的同一语言的翻译源\n")
+	b.WriteString("func Register(factory *xtext.PrinterFactory) {\n")
+	b.WriteString("for locale, messages := range Translations {\n")
+	b.WriteString("factory.SetSource(xtext.NewSourceMap(locale, messages))\n")
+	b.WriteString("}\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
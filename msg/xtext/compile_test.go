@@ -0,0 +1,89 @@
+package xtext
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestPrinterFactory_WriteGo(t *testing.T) {
+	factory := NewPrinterFactory(BaseFS(fstest.MapFS{
+		"en.gotext.json":    {Data: []byte(`{"language": "en", "messages": [{"id": "Hello", "translation": "Hello"}]}`)},
+		"zh-CN.gotext.json": {Data: []byte(`{"language": "zh-CN", "messages": [{"id": "Hello", "translation": "你好"}, {"id": "Bye", "translation": ""}]}`)},
+		"zh-CN.ftl":         {Data: []byte("quote = Say \"{ $name }\"\n")},
+	}))
+
+	var buf bytes.Buffer
+	if err := factory.WriteGo(&buf, "i18n"); err != nil {
+		t.Fatalf("WriteGo() error = %v", err)
+	}
+
+	want := `// Code generated by xtext.WriteGo. DO NOT EDIT.
+
+package i18n
+
+import (
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/infra/msg/xtext"
+)
+
+// Translations 是编译的翻译，键为语言，值为消息 ID 到译文的映射
+var Translations = map[msg.Locale]map[string]string{
+	"en": {
+		"Hello": "Hello",
+	},
+	"zh-CN": {
+		"Hello": "你好",
+		"quote": "Say \"{name}\"",
+	},
+}
+
+// Register 将编译的翻译添加到 factory，每种语言替换已有的同一语言的翻译源
+func Register(factory *xtext.PrinterFactory) {
+	for locale, messages := range Translations {
+		factory.SetSource(xtext.NewSourceMap(locale, messages))
+	}
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteGo() =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := factory.WriteGo(&buf, "my-pkg"); err == nil {
+		t.Error("WriteGo() with an invalid package name should return an error")
+	}
+
+	broken := NewPrinterFactory(BaseFS(fstest.MapFS{
+		"ja.gotext.json": {Data: []byte(`{`)},
+	}))
+	buf.Reset()
+	if err := broken.WriteGo(&buf, "i18n"); err == nil || buf.Len() > 0 {
+		t.Errorf("WriteGo() error = %v, wrote %d bytes; want an error and no output", err, buf.Len())
+	}
+}
+
+func TestNewSourceMap(t *testing.T) {
+	factory := NewPrinterFactory()
+	factory.SetSource(NewSourceMap(msg.Locale("zh-CN"), map[string]string{
+		"Hello": "你好",
+		"Bye":   "再见",
+	}))
+
+	p, err := factory.CreatePrinter(msg.Locale("zh-CN"))
+	if err != nil {
+		t.Fatalf("CreatePrinter() error = %v", err)
+	}
+	if got := p.Sprintf("Hello"); got != "你好" {
+		t.Errorf("Sprintf(Hello) = %q, want %q", got, "你好")
+	}
+
+	coverage := factory.Coverage()
+	if len(coverage) != 1 || coverage[0].Translated != 2 {
+		t.Errorf("Coverage() = %v, want 2 translated messages", coverage)
+	}
+	if !factory.SupportsLocale(msg.Locale("zh-CN")) {
+		t.Error("SupportsLocale(zh-CN) = false after SetSource")
+	}
+}
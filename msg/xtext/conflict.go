@@ -0,0 +1,99 @@
+package xtext
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"go-slim.dev/infra/msg"
+)
+
+// ConflictPolicy 决定同一语言的多个文件定义了同一消息 ID 时使用哪个译文。
+//
+// 只比较有译文的消息，未翻译的消息不会覆盖其他文件的译文；同一文件中的重复 ID 不属于冲突，
+// 以最后一个为准并报告 duplicate-id。加载器未实现 MessageParser 的文件不参与比较。
+type ConflictPolicy int
+
+const (
+	// ConflictLastWins 使用后加载的文件的译文（默认）
+	ConflictLastWins ConflictPolicy = iota
+	// ConflictFirstWins 使用先加载的文件的译文
+	ConflictFirstWins
+	// ConflictError 使用先加载的文件的译文，并将冲突作为后加载的文件的 LoadError 返回，
+	// 严格模式下该语言加载失败
+	ConflictError
+)
+
+// String 返回策略的名称，如 "last-wins"
+func (p ConflictPolicy) String() string {
+	switch p {
+	case ConflictLastWins:
+		return "last-wins"
+	case ConflictFirstWins:
+		return "first-wins"
+	case ConflictError:
+		return "error"
+	}
+	return fmt.Sprintf("ConflictPolicy(%d)", int(p))
+}
+
+// findConflicts 查找多个文件之间重复定义的消息，返回每个文件中与之前的文件冲突的问题。
+// names 与 messages 一一对应并按加载顺序排列。
+func findConflicts(locale msg.Locale, names []string, messages [][]Message) [][]Issue {
+	issues := make([][]Issue, len(names))
+	owners := make(map[string]string)
+	for i, name := range names {
+		for _, m := range messages[i] {
+			if m.Translation == "" {
+				continue
+			}
+			owner, ok := owners[m.ID]
+			if !ok {
+				owners[m.ID] = name
+				continue
+			}
+			if owner == name || slices.ContainsFunc(issues[i], func(issue Issue) bool { return issue.ID == m.ID }) {
+				continue // 同一文件中的重复 ID
+			}
+			issues[i] = append(issues[i], Issue{
+				Kind:   IssueConflict,
+				File:   name,
+				Locale: locale,
+				ID:     m.ID,
+				Detail: "message is also defined in " + owner,
+			})
+		}
+	}
+	return issues
+}
+
+// resolveConflicts 按策略处理多个文件之间重复定义的消息。
+//
+// 使用先加载的文件的译文时，从后加载的文件中删除冲突的消息。
+// 返回每个文件冲突的问题，策略为 ConflictError 时还返回每个文件合并后的冲突错误。
+func resolveConflicts(policy ConflictPolicy, locale msg.Locale, names []string, messages [][]Message) ([][]Issue, []error) {
+	issues := findConflicts(locale, names, messages)
+	errs := make([]error, len(names))
+	if policy == ConflictLastWins {
+		return issues, errs
+	}
+
+	for i, fileIssues := range issues {
+		if len(fileIssues) == 0 {
+			continue
+		}
+		conflicting := make(map[string]bool, len(fileIssues))
+		var fileErrs []error
+		for _, issue := range fileIssues {
+			conflicting[issue.ID] = true
+			fileErrs = append(fileErrs, fmt.Errorf("message %q: %s", issue.ID, issue.Detail))
+		}
+		messages[i] = slices.DeleteFunc(messages[i], func(m Message) bool {
+			return m.Translation != "" && conflicting[m.ID]
+		})
+		if policy == ConflictError {
+			errs[i] = errors.Join(fileErrs...)
+		}
+	}
+	return issues, errs
+}
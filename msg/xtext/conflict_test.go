@@ -0,0 +1,97 @@
+package xtext
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestConflicts(t *testing.T) {
+	newFS := func() fstest.MapFS {
+		return fstest.MapFS{
+			"zh/a.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "hello", "translation": "你好"}, {"id": "bye", "translation": "再见"}]}`)},
+			"zh/b.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "hello", "translation": "您好"}, {"id": "bye", "translation": ""}, {"id": "ok", "translation": "好"}]}`)},
+		}
+	}
+
+	tests := []struct {
+		policy  ConflictPolicy
+		want    string
+		wantErr bool
+	}{
+		{ConflictLastWins, "您好", false},
+		{ConflictFirstWins, "你好", false},
+		{ConflictError, "你好", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			var logs []string
+			factory := NewPrinterFactory(BaseFS(newFS()), Conflicts(tt.policy), LogFunc(func(s string) {
+				logs = append(logs, s)
+			}))
+
+			err := factory.Load()
+			var loadErr *LoadError
+			if tt.wantErr {
+				if !errors.As(err, &loadErr) || loadErr.File != "zh/b.gotext.json" || !strings.Contains(err.Error(), `"hello"`) {
+					t.Errorf("Load() error = %v, want conflict error for zh/b.gotext.json", err)
+				}
+			} else if err != nil {
+				t.Errorf("Load() error = %v", err)
+			}
+			if !slices.ContainsFunc(logs, func(s string) bool { return strings.Contains(s, "also defined in zh/a.gotext.json") }) {
+				t.Errorf("logs = %q, want a conflict report", logs)
+			}
+
+			printer, err := factory.CreatePrinter(msg.Chinese)
+			if err != nil {
+				t.Fatalf("CreatePrinter(zh) error = %v", err)
+			}
+			// 未翻译的消息不算冲突，也不覆盖其他文件的译文
+			for key, want := range map[string]string{"hello": tt.want, "bye": "再见", "ok": "好"} {
+				if got := printer.Sprintf(key); got != want {
+					t.Errorf("Sprintf(%q) = %q, want %q", key, got, want)
+				}
+			}
+
+			var buf strings.Builder
+			if err := factory.WriteGo(&buf, "i18n"); err != nil {
+				t.Fatalf("WriteGo() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), `"hello": "`+tt.want+`"`) {
+				t.Errorf("WriteGo() =\n%s\nwant hello translated as %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	t.Run("Strict", func(t *testing.T) {
+		factory := NewPrinterFactory(BaseFS(newFS()), Conflicts(ConflictError), Strict(true))
+		if _, err := factory.CreatePrinter(msg.Chinese); err == nil {
+			t.Error("CreatePrinter(zh) error = nil, want conflict error")
+		}
+	})
+}
+
+func TestPrinterFactory_ValidateConflicts(t *testing.T) {
+	factory := NewPrinterFactory(BaseFS(fstest.MapFS{
+		"zh/a.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "hello", "translation": "你好"}]}`)},
+		"zh/b.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "hello", "translation": "您好"}, {"id": "hello", "translation": "嗨"}]}`)},
+		"en.gotext.json":   {Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}]}`)},
+	}))
+
+	var conflicts []Issue
+	for _, issue := range factory.Validate() {
+		if issue.Kind == IssueConflict {
+			conflicts = append(conflicts, issue)
+		}
+	}
+	want := []Issue{{Kind: IssueConflict, File: "zh/b.gotext.json", Locale: msg.Chinese, ID: "hello", Detail: "message is also defined in zh/a.gotext.json"}}
+	if !slices.Equal(conflicts, want) {
+		t.Errorf("Validate() conflicts = %v, want %v", conflicts, want)
+	}
+}
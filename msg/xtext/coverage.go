@@ -0,0 +1,100 @@
+package xtext
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"go-slim.dev/infra/msg"
+)
+
+// 确保 PrinterFactory 实现了 msg.CoverageReporter 接口
+var _ msg.CoverageReporter = (*PrinterFactory)(nil)
+
+// Coverage 实现 msg.CoverageReporter 接口。
+//
+// 重新解析所有翻译源的文件（包括尚未加载的语言）和远程、数据库翻译源的当前快照，
+// 以所有语言中出现过的消息为总数，统计每种语言的覆盖率，结果按语言排序。
+//
+// 统计规则：
+// - 同一语言中同一消息出现多次时，与加载一致，文件之间的冲突按 Conflicts 策略处理，其余以最后一个有译文的为准
+// - 加载器未实现 MessageParser 的文件不参与统计，解析失败的文件记录日志后忽略
+// - 通过 SetTranslation 等方法在运行时设置的翻译不参与统计
+//
+// 示例：
+//
+//	for _, c := range factory.Coverage() {
+//	    fmt.Println(c) // zh-CN 80.0% (8/10, 1 missing, 1 fuzzy)
+//	}
+func (f *PrinterFactory) Coverage() []msg.Coverage {
+	catalogs := f.collectMessages(f.logFunc)
+	ids := make(map[string]struct{})
+	for _, c := range catalogs {
+		for id := range c {
+			ids[id] = struct{}{}
+		}
+	}
+
+	result := make([]msg.Coverage, 0, len(catalogs))
+	for locale, c := range catalogs {
+		coverage := msg.Coverage{Locale: locale, Total: len(ids)}
+		for id := range ids {
+			switch m, ok := c[id]; {
+			case !ok || m.Translation == "":
+				coverage.Missing++
+			case m.Fuzzy:
+				coverage.Fuzzy++
+			default:
+				coverage.Translated++
+			}
+		}
+		result = append(result, coverage)
+	}
+	slices.SortFunc(result, func(a, b msg.Coverage) int {
+		return cmp.Compare(a.Locale, b.Locale)
+	})
+	return result
+}
+
+// collectMessages 重新解析所有翻译源的文件和远程、数据库翻译源的当前快照，按语言合并消息。
+//
+// 同一语言中同一消息出现多次时，与加载一致，文件之间的冲突按 Conflicts 策略处理，其余以最后一个有译文的为准；
+// 解析失败的文件通过 report 报告后忽略。
+func (f *PrinterFactory) collectMessages(report func(string)) map[msg.Locale]map[string]Message {
+	f.mu.RLock()
+	sources := slices.Clone(f.sources)
+	dynamic := slices.Clone(f.dynamic)
+	f.mu.RUnlock()
+
+	catalogs := make(map[msg.Locale]map[string]Message)
+	add := func(locale msg.Locale, messages []Message) {
+		c := catalogs[locale]
+		if c == nil {
+			c = make(map[string]Message)
+			catalogs[locale] = c
+		}
+		for _, m := range messages {
+			if old, ok := c[m.ID]; !ok || old.Translation == "" || m.Translation != "" {
+				c[m.ID] = m
+			}
+		}
+	}
+
+	for _, s := range sources {
+		f.configure(s)
+		messages, err := s.parseMessages()
+		if err != nil {
+			report(fmt.Sprintf("Error parsing translations for %s: %v", s.locale, err))
+		}
+		add(s.locale, messages)
+	}
+	for _, d := range dynamic {
+		for _, c := range d.catalogs() {
+			if c.err != nil {
+				report(fmt.Sprintf("Error parsing translation %s: %v", c.name, c.err))
+			}
+			add(c.locale, c.messages)
+		}
+	}
+	return catalogs
+}
@@ -0,0 +1,60 @@
+package xtext
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestPrinterFactory_Coverage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [
+			{"id": "a", "translation": "A"},
+			{"id": "b", "translation": "B"},
+			{"id": "c", "translation": "C"}
+		]}`)},
+		"zh-CN/common.gotext.json": {Data: []byte(`{"language": "zh-CN", "messages": [
+			{"id": "a", "translation": "甲"},
+			{"id": "b", "translation": "乙", "fuzzy": true},
+			{"id": "c", "translation": ""}
+		]}`)},
+		"ja.gotext.yaml": {Data: []byte("language: ja\nmessages:\n  - id: a\n    translation: あ\n  - id: d\n    translation: え\n")},
+	}
+	factory := NewPrinterFactory(BaseFS(fsys))
+
+	want := []msg.Coverage{
+		{Locale: "en", Total: 4, Translated: 3, Missing: 1},
+		{Locale: "ja", Total: 4, Translated: 2, Missing: 2},
+		{Locale: "zh-CN", Total: 4, Translated: 1, Missing: 2, Fuzzy: 1},
+	}
+	got := factory.Coverage()
+	if len(got) != len(want) {
+		t.Fatalf("Coverage() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Coverage()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// 加载后仍然可以统计
+	if _, err := factory.CreatePrinter(msg.Locale("zh-CN")); err != nil {
+		t.Fatalf("CreatePrinter() error = %v", err)
+	}
+	if got := factory.Coverage(); len(got) != 3 || got[2] != want[2] {
+		t.Errorf("Coverage() after load = %v, want %v", got, want)
+	}
+
+	manager := msg.NewManager(msg.ManagerConfig{Factory: factory})
+	if got := manager.Coverage(); len(got) != 3 {
+		t.Errorf("Manager.Coverage() = %v, want %v", got, want)
+	}
+
+	if err := msg.CheckCoverage(got, 0.5); err == nil {
+		t.Error("CheckCoverage(0.5) = nil, want error for zh-CN")
+	}
+	if err := msg.CheckCoverage(got, 0.25); err != nil {
+		t.Errorf("CheckCoverage(0.25) = %v, want nil", err)
+	}
+}
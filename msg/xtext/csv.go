@@ -0,0 +1,265 @@
+package xtext
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/message/catalog"
+	"golang.org/x/text/transform"
+)
+
+// CSVOption 配置 CSVLoader
+type CSVOption func(*CSVLoader)
+
+// CSVDelimiter 设置字段分隔符，默认 .csv 文件使用逗号，.tsv 文件使用制表符
+func CSVDelimiter(r rune) CSVOption {
+	return func(l *CSVLoader) {
+		l.comma = r
+	}
+}
+
+// CSVQuote 设置引号字符，默认为双引号。
+// 为 0 时不处理引号，字段中的引号按原样保留，适用于没有转义的 TSV 导出。
+func CSVQuote(r rune) CSVOption {
+	return func(l *CSVLoader) {
+		l.quote = r
+	}
+}
+
+// CSVLoader 实现 CSV 格式的加载器，用于加载电子表格导出的翻译文件。
+//
+// 支持的文件格式：
+// - .csv: 逗号分隔
+// - .tsv: 制表符分隔
+//
+// 文件可以带 UTF-8 或 UTF-16 BOM（Excel 导出的 “Unicode 文本” 为带 BOM 的 UTF-16），
+// 没有 BOM 时按 UTF-8 读取。空行会被忽略，ID 为空的行也会被忽略。
+//
+// 没有表头时按第一行的列数确定各列的含义：
+// - 两列：id, translation
+// - 三列或更多：id, source, translation，其余列忽略
+//
+// 第一行的第一列为 id（不区分大小写）时视为表头，按列名读取：
+// id、source（或 message）、translation、note（或 comment）、context（或 meaning），
+// 其余列忽略。示例：
+//
+//	id,source,translation,note
+//	Hello,Hello,你好,首页的问候语
+//	"Hello, %s!","Hello, %s!","你好，%s！",
+type CSVLoader struct {
+	name       string
+	extensions []string
+	comma      rune // 字段分隔符，为 0 时按扩展名选择
+	quote      rune // 引号字符，为 0 时不处理引号
+}
+
+// NewCSVLoader 创建新的 CSV 加载器。
+func NewCSVLoader(opts ...CSVOption) *CSVLoader {
+	l := &CSVLoader{
+		name:       "CSV",
+		extensions: []string{".csv", ".tsv"},
+		quote:      '"',
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Name 返回加载器名称。
+func (l *CSVLoader) Name() string {
+	return l.name
+}
+
+// Extensions 返回支持的文件扩展名列表。
+func (l *CSVLoader) Extensions() []string {
+	return l.extensions
+}
+
+// CanLoad 检查是否可以加载指定文件。
+func (l *CSVLoader) CanLoad(filename string) bool {
+	return hasExtension(filename, l.extensions)
+}
+
+// LoadToBuilder 加载 CSV 格式的翻译文件并写入到指定的 builder 中。
+func (l *CSVLoader) LoadToBuilder(filename string, data []byte, builder *catalog.Builder, locale msg.Locale) error {
+	messages, err := l.ParseMessages(filename, data)
+	if err != nil {
+		return err
+	}
+	loadMessages(builder, locale, messages)
+	return nil
+}
+
+// ParseMessages 实现 MessageParser 接口。
+func (l *CSVLoader) ParseMessages(filename string, data []byte) ([]Message, error) {
+	// 去掉 BOM，UTF-16 转换为 UTF-8
+	text, _, err := transform.String(unicode.BOMOverride(unicode.UTF8.NewDecoder()), string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode CSV translation file %s: %w", filename, err)
+	}
+	if len(strings.TrimSpace(text)) == 0 {
+		return nil, nil
+	}
+
+	comma := l.comma
+	if comma == 0 {
+		comma = ','
+		if hasExtension(filename, []string{".tsv"}) {
+			comma = '\t'
+		}
+	}
+
+	records, err := readCSV(text, comma, l.quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV translation file %s: %w", filename, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns, err := csvColumns(records[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV translation file %s: %w", filename, err)
+	}
+	if columns.header {
+		records = records[1:]
+	}
+
+	messages := make([]Message, 0, len(records))
+	for _, record := range records {
+		m := Message{ID: columns.field(record, columns.id)}
+		if m.ID == "" {
+			continue
+		}
+		m.Source = columns.field(record, columns.source)
+		m.Translation = columns.field(record, columns.translation)
+		m.Note = columns.field(record, columns.note)
+		m.Context = columns.field(record, columns.context)
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// csvLayout 描述 CSV 文件各列的含义，列号为 -1 表示没有该列
+type csvLayout struct {
+	header                                 bool
+	id, source, translation, note, context int
+}
+
+// field 返回 record 的第 i 列，没有该列时返回空字符串
+func (c csvLayout) field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// csvColumns 根据第一行确定各列的含义
+func csvColumns(first []string) (csvLayout, error) {
+	c := csvLayout{id: 0, source: -1, translation: -1, note: -1, context: -1}
+	if !strings.EqualFold(strings.TrimSpace(first[0]), "id") {
+		switch len(first) {
+		case 1:
+		case 2:
+			c.translation = 1
+		default:
+			c.source, c.translation = 1, 2
+		}
+		return c, nil
+	}
+
+	c.header = true
+	for i, name := range first[1:] {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "source", "message":
+			c.source = i + 1
+		case "translation":
+			c.translation = i + 1
+		case "note", "comment":
+			c.note = i + 1
+		case "context", "meaning":
+			c.context = i + 1
+		}
+	}
+	if c.translation < 0 {
+		return c, fmt.Errorf("header has no translation column")
+	}
+	return c, nil
+}
+
+// readCSV 将 text 解析为记录，支持任意分隔符和引号字符。
+//
+// 引号内的分隔符和换行属于字段内容，连续两个引号表示一个引号；
+// quote 为 0 时不处理引号。空行被忽略。
+func readCSV(text string, comma, quote rune) ([][]string, error) {
+	var (
+		records [][]string
+		record  []string
+		field   strings.Builder
+		line    = 1 // 当前行号
+		start   = 0 // 当前引号字段开始的行号
+		quoted  bool
+	)
+
+	endRecord := func() {
+		record = append(record, field.String())
+		field.Reset()
+		if len(record) > 1 || record[0] != "" || quoted {
+			records = append(records, record)
+		}
+		record, quoted = nil, false
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+
+		if start > 0 {
+			// 在引号字段中
+			if r == quote {
+				if next, n := utf8.DecodeRuneInString(text[i:]); next == quote {
+					field.WriteRune(quote)
+					i += n
+				} else {
+					start = 0
+				}
+				continue
+			}
+			if r == '\n' {
+				line++
+			}
+			field.WriteRune(r)
+			continue
+		}
+
+		switch {
+		case r == quote && quote != 0 && field.Len() == 0 && !quoted:
+			quoted, start = true, line
+		case r == comma:
+			record = append(record, field.String())
+			field.Reset()
+			quoted = false
+		case r == '\r' && strings.HasPrefix(text[i:], "\n"):
+			// CRLF 在读到 LF 时结束记录
+		case r == '\n' || r == '\r':
+			endRecord()
+			line++
+		case quoted:
+			return nil, fmt.Errorf("line %d: unexpected %q after quoted field", line, r)
+		default:
+			field.WriteRune(r)
+		}
+	}
+
+	if start > 0 {
+		return nil, fmt.Errorf("line %d: unterminated quoted field", start)
+	}
+	if len(record) > 0 || field.Len() > 0 || quoted {
+		endRecord()
+	}
+	return records, nil
+}
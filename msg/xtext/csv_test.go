@@ -0,0 +1,133 @@
+package xtext
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+func TestCSVLoader_ParseMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		opts     []CSVOption
+		want     []Message
+	}{
+		{
+			name:     "Two columns",
+			filename: "zh-CN.csv",
+			data:     "Hello,你好\nBye,\n",
+			want:     []Message{{ID: "Hello", Translation: "你好"}, {ID: "Bye"}},
+		},
+		{
+			name:     "Three columns with quotes",
+			filename: "zh-CN.csv",
+			data:     "\"Hello, %s!\",\"Hello, %s!\",\"你好，%s！\"\r\n\r\nQuote,\"Say \"\"hi\"\"\",\"说\n“嗨”\"\r\n",
+			want: []Message{
+				{ID: "Hello, %s!", Source: "Hello, %s!", Translation: "你好，%s！"},
+				{ID: "Quote", Source: `Say "hi"`, Translation: "说\n“嗨”"},
+			},
+		},
+		{
+			name:     "Header",
+			filename: "zh-CN.csv",
+			data:     "\ufeffID,Translation,Context,Extra,Source,Note\nOpen,打开,verb,x,Open,Menu item\n",
+			want:     []Message{{ID: "Open", Source: "Open", Translation: "打开", Note: "Menu item", Context: "verb"}},
+		},
+		{
+			name:     "TSV",
+			filename: "zh-CN.tsv",
+			data:     "Hello\tHello\t你好, 世界\n",
+			want:     []Message{{ID: "Hello", Source: "Hello", Translation: "你好, 世界"}},
+		},
+		{
+			name:     "Custom delimiter and quote",
+			filename: "zh-CN.csv",
+			data:     "Hello;'Hi; there';'你好；''朋友'''\n",
+			opts:     []CSVOption{CSVDelimiter(';'), CSVQuote('\'')},
+			want:     []Message{{ID: "Hello", Source: "Hi; there", Translation: "你好；'朋友'"}},
+		},
+		{
+			name:     "Quotes disabled",
+			filename: "zh-CN.tsv",
+			data:     "Say \"hi\"\t说\"嗨\"\n",
+			opts:     []CSVOption{CSVQuote(0)},
+			want:     []Message{{ID: `Say "hi"`, Translation: `说"嗨"`}},
+		},
+		{
+			name:     "Empty",
+			filename: "zh-CN.csv",
+			data:     "\ufeff\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCSVLoader(tt.opts...).ParseMessages(tt.filename, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseMessages error = %v", err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMessages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVLoader_ParseMessagesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"Unterminated quote", "Hello,\"你好\n", "line 1: unterminated quoted field"},
+		{"Text after quote", "Hello,\"你好\"!\n", "line 1: unexpected '!' after quoted field"},
+		{"Header without translation", "id,source\nHello,Hello\n", "no translation column"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCSVLoader().ParseMessages("zh-CN.csv", []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseMessages error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVLoader(t *testing.T) {
+	// Excel 的 “Unicode 文本” 导出为带 BOM 的 UTF-16LE
+	data, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String("Hello\t你好\nBye\t\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader, ok := NewLoaderRegistry().GetLoaderForFile("zh-CN.tsv")
+	if !ok || loader.Name() != "CSV" {
+		t.Fatal("GetLoaderForFile(zh-CN.tsv) should select the CSV loader")
+	}
+
+	builder := catalog.NewBuilder()
+	if err := loader.LoadToBuilder("zh-CN.tsv", []byte(data), builder, msg.Locale("zh-CN")); err != nil {
+		t.Fatalf("LoadToBuilder error = %v", err)
+	}
+	printer, err := NewPrinter(msg.Locale("zh-CN"), message.Catalog(builder))
+	if err != nil {
+		t.Fatalf("NewPrinter error = %v", err)
+	}
+	if got := printer.Sprintf("Hello"); got != "你好" {
+		t.Errorf("Sprintf(Hello) = %q, want %q", got, "你好")
+	}
+	if got := printer.Sprintf("Bye"); got != "Bye" {
+		t.Errorf("Sprintf(Bye) = %q, want untranslated %q", got, "Bye")
+	}
+}
@@ -31,21 +31,24 @@ package xtext
 import (
 	"bytes"
 	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"go-slim.dev/infra/msg"
 	"golang.org/x/sync/singleflight"
 	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 	"golang.org/x/text/message/catalog"
 )
 
-// 确保 PrinterFactory 实现了 msg.PrinterFactory 接口
-var _ msg.PrinterFactory = (*PrinterFactory)(nil)
+// 确保 PrinterFactory 实现了 msg.ContextPrinterFactory 接口
+var _ msg.ContextPrinterFactory = (*PrinterFactory)(nil)
 
 // PrinterFactory 基于 golang.org/x/text 实现的打印机工厂。
 //
@@ -58,8 +61,8 @@ var _ msg.PrinterFactory = (*PrinterFactory)(nil)
 // 线程安全：所有公共方法都是线程安全的。
 //
 // 核心组件：
-// - builder: 全局 catalog.Builder，所有翻译数据都加载到这里
-// - sources: 翻译源列表，按语言范围从小到大排序
+// - builder: 全局 catalog.Builder，动态翻译源和 SetTranslation 等写入的数据加载到这里
+// - sources: 本地翻译源列表，每个 Source 加载到自己的 catalog 中，可以按语言单独重新加载
 // - printers: Printer 缓存，避免重复创建
 // - sf: singleflight 组，防止并发创建相同的 Printer
 type PrinterFactory struct {
@@ -67,19 +70,51 @@ type PrinterFactory struct {
 	fallback msg.Locale                 // 回退语言，当找不到匹配的语言时使用
 	logFunc  msg.LogFunc                // 日志函数，用于记录调试和错误信息
 	sources  []*Source                  // 翻译源列表，按语言范围从小到大排序
+	dynamic  []dynamicSource            // 远程和数据库翻译源，Reset 后仍然保留
 	locales  msg.LocaleSet              // 语言集合，用于快速查找和匹配
 	loaders  *LoaderRegistry            // 加载器注册表，支持多种文件格式
-	builder  *catalog.Builder           // 全局 catalog.Builder，所有翻译数据都加载到这里
+	builder  *catalog.Builder           // 全局 catalog.Builder，本地翻译源之外的数据加载到这里
 	printers map[msg.Locale]msg.Printer // Printer 缓存，key 为完整的 Locale（可能包含扩展信息）
 	sf       singleflight.Group         // singleflight 组，用于避免重复创建 Printer
+
+	fallbacks []msg.Locale      // 全局回退链，位于每种语言自身的回退链之后
+	missing   *missingReporters // 缺失翻译的报告器，见 Missing 和 AddMissingReporter
+	overrides *overrides        // 运行时覆盖的消息，Reset 后仍然保留
+	local     *localSources     // 发布给 Printer 的 sources，Reset 时重新创建
+	fuzzy     *fuzzyMatcher     // 缺失消息的模糊匹配，未启用时为 nil
+	inherit   bool              // 回退链是否包含父语言，见 Inheritance
+	workers   int               // 每个翻译源并发加载文件的数量，见 LoadConcurrency
+	strict    bool              // 严格模式，有文件加载失败时返回错误，见 Strict
+	conflicts ConflictPolicy    // 多个文件定义同一消息时的处理策略，见 Conflicts
+	resolver  PathResolver      // 翻译目录的命名规则，为 nil 时使用默认规则，见 Layout
+}
+
+// localSources 是 Printer 查找消息时读取的本地翻译源列表，
+// SetSource 替换翻译源后，同一次 Reset 之后创建的 Printer 立即可见
+type localSources = atomic.Pointer[[]*Source]
+
+// newLocalSources 创建发布了 sources 的 localSources
+func newLocalSources(sources []*Source) *localSources {
+	local := new(localSources)
+	local.Store(&sources)
+	return local
 }
 
 // options 包含 PrinterFactory 的配置选项
 type options struct {
-	baseDir  string          // 语言包目录
-	fallback msg.Locale      // 回退语言
-	logFunc  msg.LogFunc     // 日志函数
-	loaders  *LoaderRegistry // 加载器注册表
+	baseDir   string              // 语言包目录
+	baseFS    fs.FS               // 语言包文件系统
+	fallback  msg.Locale          // 回退语言
+	fallbacks []msg.Locale        // 全局回退链
+	logFunc   msg.LogFunc         // 日志函数
+	loaders   *LoaderRegistry     // 加载器注册表
+	missing   msg.MissingReporter // 缺失翻译的报告器
+	fuzzy     float64             // 模糊匹配的相似度阈值，为 0 时不启用
+	noInherit bool                // 不继承父语言的翻译
+	workers   int                 // 每个翻译源并发加载文件的数量
+	strict    bool                // 使用严格模式加载翻译文件
+	conflicts ConflictPolicy      // 多个文件定义同一消息时的处理策略
+	resolver  PathResolver        // 翻译目录的命名规则
 }
 
 // Option 定义 PrinterFactory 的配置选项函数类型
@@ -124,6 +159,198 @@ func Fallback(f msg.Locale) Option {
 	}
 }
 
+// Fallbacks 设置有序的全局回退链选项。
+//
+// 每种语言的回退链由三部分组成：语言本身及其父语言（如 zh-HK 的父语言是 zh-Hant，
+// zh-Hans-CN 的父语言是 zh-Hans，见 Inheritance），语言的基础语言（如 zh），最后是这里设置的全局回退链。
+// Printer 按顺序查找翻译，使用第一个包含该消息的语言。
+// 未设置 Fallback 时，locales 中的第一个同时作为回退语言。
+//
+// 参数 locales: 按优先级排列的回退语言
+// 返回: 可用于 NewPrinterFactory 的选项
+//
+// 示例：
+//
+//	factory := xtext.NewPrinterFactory(
+//	    xtext.Fallbacks(msg.English),
+//	)
+//	// zh-HK 的回退链为 zh-HK → zh-Hant → zh → en
+func Fallbacks(locales ...msg.Locale) Option {
+	return func(o *options) {
+		o.fallbacks = locales
+	}
+}
+
+// Inheritance 设置语言是否继承父语言的翻译，默认启用。
+//
+// 启用时，每种语言的回退链包含其父语言和基础语言，如 zh-Hans-CN → zh-Hans → zh，
+// 父语言翻译文件中的消息会合并到子语言中，地区的翻译文件只需要包含与父语言不同的消息。
+//
+// 禁用时，每种语言只使用与之相同的翻译文件，找不到消息时直接使用 Fallbacks 和 Fallback 设置的语言，
+// 适用于每个地区都维护完整翻译的目录。该选项只影响本地翻译文件，
+// 远程、数据库翻译源以及 SetTranslation、SetMessage 写入的消息仍然按 golang.org/x/text 的规则查找父语言。
+//
+// 示例：
+//
+//	// locales/zh.gotext.json 包含全部消息，locales/zh-Hans-CN.gotext.json 只包含少量差异
+//	factory := xtext.NewPrinterFactory(xtext.BaseDir("./locales"))
+//
+//	// 每个地区的翻译都是完整的，不需要合并
+//	factory := xtext.NewPrinterFactory(xtext.BaseDir("./locales"), xtext.Inheritance(false))
+func Inheritance(enabled bool) Option {
+	return func(o *options) {
+		o.noInherit = !enabled
+	}
+}
+
+// Missing 设置缺失翻译的报告器选项。
+//
+// 当消息在语言的整个回退链中都没有译文、只能输出原文时，
+// 由工厂创建的 Printer 会调用 reporter.ReportMissing 报告请求的语言和消息标识。
+//
+// 参数 reporter: 缺失翻译的报告器，如 msg.NewMissingCounter()
+// 返回: 可用于 NewPrinterFactory 的选项
+//
+// 示例：
+//
+//	counter := msg.NewMissingCounter()
+//	factory := xtext.NewPrinterFactory(
+//	    xtext.Missing(counter),
+//	)
+func Missing(reporter msg.MissingReporter) Option {
+	return func(o *options) {
+		o.missing = reporter
+	}
+}
+
+// missingReporters 将缺失翻译报告给 Missing 选项和 AddMissingReporter 添加的全部报告器，
+// 由工厂创建的 Printer 共享，添加的报告器对已创建的 Printer 立即生效
+type missingReporters struct {
+	reporters atomic.Pointer[[]msg.MissingReporter]
+}
+
+// newMissingReporters 创建包含 reporter 的 missingReporters，reporter 为 nil 时不包含报告器
+func newMissingReporters(reporter msg.MissingReporter) *missingReporters {
+	r := &missingReporters{}
+	if reporter != nil {
+		r.reporters.Store(&[]msg.MissingReporter{reporter})
+	}
+	return r
+}
+
+// add 添加 reporter，复制列表后原子替换，报告时不需要加锁
+func (r *missingReporters) add(reporter msg.MissingReporter) {
+	for {
+		old := r.reporters.Load()
+		var list []msg.MissingReporter
+		if old != nil {
+			list = slices.Clone(*old)
+		}
+		list = append(list, reporter)
+		if r.reporters.CompareAndSwap(old, &list) {
+			return
+		}
+	}
+}
+
+// ReportMissing 实现 msg.MissingReporter 接口
+func (r *missingReporters) ReportMissing(locale msg.Locale, id string) {
+	if list := r.reporters.Load(); list != nil {
+		for _, reporter := range *list {
+			reporter.ReportMissing(locale, id)
+		}
+	}
+}
+
+// ReportFuzzy 实现 msg.FuzzyReporter 接口，只报告给实现了该接口的报告器
+func (r *missingReporters) ReportFuzzy(locale msg.Locale, id, matched string, score float64) {
+	if list := r.reporters.Load(); list != nil {
+		for _, reporter := range *list {
+			if fuzzy, ok := reporter.(msg.FuzzyReporter); ok {
+				fuzzy.ReportFuzzy(locale, id, matched, score)
+			}
+		}
+	}
+}
+
+// AddMissingReporter 实现 msg.MissingReporterAdder 接口，在 Missing 选项之外添加缺失翻译的报告器，
+// 对已创建的 Printer 同样生效。启用指标的 msg.Manager 通过它自动报告缺失的翻译。
+func (f *PrinterFactory) AddMissingReporter(reporter msg.MissingReporter) {
+	if reporter != nil {
+		f.missing.add(reporter)
+	}
+}
+
+// LoadConcurrency 设置每个翻译源并发读取和解析文件的数量选项，n <= 0 时使用 runtime.GOMAXPROCS(0)（默认）。
+//
+// 文件写入 catalog 时仍按顺序进行，同名消息以后面的文件为准，加载结果与逐个加载相同。
+// 设置为 1 时逐个加载文件。
+//
+// 参数 n: 并发数量
+// 返回: 可用于 NewPrinterFactory 的选项
+//
+// 示例：
+//
+//	// locales/zh-CN/ 目录包含数百个 .gotext.json 文件
+//	factory := xtext.NewPrinterFactory(
+//	    xtext.BaseDir("./locales"),
+//	    xtext.LoadConcurrency(16),
+//	)
+func LoadConcurrency(n int) Option {
+	return func(o *options) {
+		o.workers = max(n, 0)
+	}
+}
+
+// Strict 设置是否使用严格模式加载翻译文件的选项，默认为宽松模式。
+//
+// 宽松模式下，加载失败的文件通过日志函数记录后跳过，其余文件的翻译照常生效。
+// 严格模式下，一种语言有文件加载失败时不使用该语言的本地翻译：
+//   - Load 在第一个失败的语言处停止，返回该语言所有失败文件合并后的错误
+//   - CreatePrinter 在回退链上的语言加载失败时返回错误
+//   - ReloadLocale 加载失败时保留替换前的翻译
+//
+// 两种模式都可以通过 LoadErrors 取得最近一次加载失败的文件。
+// 严格模式适合在 CI 中校验翻译文件，或者希望翻译文件损坏时启动失败的程序。
+//
+// 参数 enabled: 是否使用严格模式
+// 返回: 可用于 NewPrinterFactory 的选项
+//
+// 示例：
+//
+//	factory := xtext.NewPrinterFactory(xtext.BaseDir("./locales"), xtext.Strict(true))
+//	if err := factory.Load(); err != nil {
+//	    log.Fatal(err)
+//	}
+func Strict(enabled bool) Option {
+	return func(o *options) {
+		o.strict = enabled
+	}
+}
+
+// Conflicts 设置同一语言的多个文件定义同一消息 ID 时的处理策略选项，默认为 ConflictLastWins。
+//
+// 每个冲突都记录日志，Validate 也会报告 conflict 问题；策略为 ConflictError 时，
+// 冲突作为后加载的文件的 LoadError 返回，与 Strict 一起使用时该语言加载失败。
+// Coverage 和 WriteGo 使用同样的策略选择译文。
+//
+// 参数 policy: 冲突处理策略
+// 返回: 可用于 NewPrinterFactory 的选项
+//
+// 示例：
+//
+//	// locales/zh/ 中的 common.gotext.json 和 admin.gotext.json 不能定义同一消息
+//	factory := xtext.NewPrinterFactory(
+//	    xtext.BaseDir("./locales"),
+//	    xtext.Conflicts(xtext.ConflictError),
+//	    xtext.Strict(true),
+//	)
+func Conflicts(policy ConflictPolicy) Option {
+	return func(o *options) {
+		o.conflicts = policy
+	}
+}
+
 // BaseDir 设置翻译文件的根目录选项。
 //
 // 用于指定包含翻译文件的目录路径，工厂初始化时会自动加载该目录下的所有翻译文件。
@@ -144,6 +371,30 @@ func BaseDir(dir string) Option {
 	}
 }
 
+// BaseFS 设置包含翻译文件的文件系统选项。
+//
+// 与 BaseDir 相同，但从 fs.FS（如 go:embed 的 embed.FS）加载翻译文件，
+// 相当于在创建工厂后调用 ResetFS(fsys) 方法。同时设置 BaseDir 时以 BaseFS 为准。
+//
+// 参数 fsys: 根目录包含翻译文件的文件系统
+// 返回: 可用于 NewPrinterFactory 的选项
+//
+// 示例：
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	sub, _ := fs.Sub(locales, "locales")
+//	factory := xtext.NewPrinterFactory(
+//	    xtext.BaseFS(sub),
+//	    xtext.Fallback(msg.English),
+//	)
+func BaseFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.baseFS = fsys
+	}
+}
+
 // Loaders 设置自定义的加载器注册表选项。
 //
 // 用于指定自定义的翻译文件加载器注册表，如果不设置，将使用默认的注册表。
@@ -174,15 +425,31 @@ func NewPrinterFactory(opts ...Option) *PrinterFactory {
 		opt(&o)
 	}
 
-	fallback := cmp.Or(o.fallback, msg.English)
+	fallback := o.fallback
+	if fallback == "" && len(o.fallbacks) > 0 {
+		fallback = o.fallbacks[0]
+	}
+	fallback = cmp.Or(fallback, msg.English)
 	builder := catalog.NewBuilder()
 
 	f := &PrinterFactory{
-		fallback: fallback,
-		logFunc:  o.logFunc,
-		loaders:  o.loaders,
-		builder:  builder,
-		printers: make(map[msg.Locale]msg.Printer),
+		fallback:  fallback,
+		fallbacks: slices.Clone(o.fallbacks),
+		logFunc:   o.logFunc,
+		loaders:   o.loaders,
+		builder:   builder,
+		printers:  make(map[msg.Locale]msg.Printer),
+		missing:   newMissingReporters(o.missing),
+		overrides: &overrides{},
+		local:     newLocalSources(nil),
+		inherit:   !o.noInherit,
+		workers:   o.workers,
+		strict:    o.strict,
+		conflicts: o.conflicts,
+		resolver:  o.resolver,
+	}
+	if o.fuzzy > 0 {
+		f.fuzzy = &fuzzyMatcher{threshold: o.fuzzy, build: f.fuzzyCandidates}
 	}
 	if f.logFunc == nil {
 		f.logFunc = func(string) {} // discard
@@ -191,7 +458,9 @@ func NewPrinterFactory(opts ...Option) *PrinterFactory {
 		f.loaders = NewLoaderRegistry()
 	}
 
-	if o.baseDir != "" {
+	if o.baseFS != nil {
+		f.ResetFS(o.baseFS)
+	} else if o.baseDir != "" {
 		f.Reset(o.baseDir)
 	}
 
@@ -206,12 +475,59 @@ func NewPrinterFactory(opts ...Option) *PrinterFactory {
 //
 // 注意：调用 Reset 后，所有之前创建的 Printer 仍然有效，
 // 但它们使用的是重置前的 catalog 数据。如果需要最新的数据，
-// 请重新创建 Printer 实例。
+// 请重新创建 Printer 实例，通过 msg.Manager 使用时需要调用 ResetPrinterCache。
+//
+// 重置前已经加载的语言会立即重新加载：每个文件按内容的 SHA-256 判断是否变化，
+// 只重新解析变化的文件，文件都未变化的语言直接沿用原来的翻译数据，因此可以频繁调用 Reset 热加载大量翻译文件。
 func (f *PrinterFactory) Reset(baseDir string, callbacks ...func(*catalog.Builder)) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	f.sources = f.loadSources(baseDir)
+	var sources []*Source
+	if baseDir != "" {
+		sources = f.loadSources(nil, baseDir)
+	}
+	f.reset(sources, callbacks)
+}
+
+// ResetFS 与 Reset 相同，但从 fs.FS 的根目录加载翻译文件，目录结构规则与 Reset 一致。
+//
+// 适用于部署在没有翻译目录的环境（如 scratch 容器）中、
+// 使用 go:embed 将翻译文件嵌入二进制的程序：
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	sub, _ := fs.Sub(locales, "locales")
+//	factory.ResetFS(sub)
+func (f *PrinterFactory) ResetFS(fsys fs.FS, callbacks ...func(*catalog.Builder)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var sources []*Source
+	if fsys != nil {
+		sources = f.loadSources(fsys, ".")
+	}
+	f.reset(sources, callbacks)
+}
+
+// reset 使用新的翻译源替换工厂的状态，调用者需要持有写锁。
+//
+// 替换前已经加载的语言立即重新加载，只重新解析内容变化的文件，文件都未变化时继续使用原来的 catalog；
+// 其他语言仍然在第一次使用时加载。
+func (f *PrinterFactory) reset(sources []*Source, callbacks []func(*catalog.Builder)) {
+	for _, s := range sources {
+		i := slices.IndexFunc(f.sources, func(old *Source) bool {
+			return old.locale == s.locale && old.loaded.Load() != nil
+		})
+		if i >= 0 {
+			s.adopt(f.sources[i])
+			f.configure(s)
+			s.reload()
+		}
+	}
+	f.sources = sources
+	f.local = newLocalSources(slices.Clone(sources))
 	f.locales = make(msg.LocaleSet, len(f.sources))
 	f.builder = catalog.NewBuilder()
 	f.printers = make(map[msg.Locale]msg.Printer)
@@ -219,25 +535,217 @@ func (f *PrinterFactory) Reset(baseDir string, callbacks ...func(*catalog.Builde
 	for i, s := range f.sources {
 		f.locales[i] = s.locale
 	}
+	for _, d := range f.dynamic {
+		for _, locale := range d.locales() {
+			f.addLocale(locale)
+		}
+		d.Load(f.builder)
+	}
 
 	for _, callback := range callbacks {
 		callback(f.builder)
 	}
+	f.fuzzy.invalidate()
 }
 
-func (f *PrinterFactory) loadSources(baseDir string) []*Source {
+// SetSource 添加本地翻译源，并替换语言与之相同的已有翻译源，
+// 用于在运行时增加一种语言或整体替换一种语言的翻译。
+//
+// s 在替换前加载完成，其他语言的翻译不受影响，已创建的 Printer 立即使用新的翻译；
+// 新增的语言会清空工厂的 Printer 缓存，通过 msg.Manager 使用时还需要调用 ResetPrinterCache，
+// 以替换之前回退到其他语言的 Printer。与目录中的翻译源一样，Reset 后不再保留。
+// 严格模式下 s 加载失败时仍然替换已有的翻译源，CreatePrinter 会再次加载并返回错误。
+//
+// 示例：
+//
+//	source, err := xtext.NewSourceFS(msg.Locale("ja"), uploaded, "*.gotext.json")
+//	if err != nil {
+//	    return err
+//	}
+//	factory.SetSource(source)
+func (f *PrinterFactory) SetSource(s *Source) {
+	f.configure(s)
+	s.catalog()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sources := slices.DeleteFunc(slices.Clone(f.sources), func(src *Source) bool {
+		return src.locale.Equal(s.locale)
+	})
+	sources = append(sources, s)
+	sortSources(sources)
+	f.sources = sources
+	f.local.Store(&sources)
+	f.addLocale(s.locale)
+	clear(f.printers)
+	f.fuzzy.invalidate()
+}
+
+// ReloadLocale 重新读取一种语言的本地翻译文件，其他语言的翻译不受影响。
+//
+// 新的翻译加载完成后原子替换，期间 Printer 继续使用旧的翻译，不会看到只加载了一部分的数据；
+// 文件中删除的消息在替换后不再可用。没有该语言的本地翻译源时返回错误；
+// 部分文件加载失败时返回合并后的文件错误，宽松模式下其余文件的翻译仍然生效，
+// 严格模式下保留替换前的翻译。只重新解析内容变化的文件，文件都未变化且上次加载成功时不替换。
+//
+// 示例：
+//
+//	// 翻译人员更新了 locales/zh-Hans.gotext.json
+//	if err := factory.ReloadLocale(msg.Locale("zh-Hans")); err != nil {
+//	    log.Println(err)
+//	}
+func (f *PrinterFactory) ReloadLocale(locale msg.Locale) error {
+	f.mu.RLock()
+	var sources []*Source
+	for _, s := range f.sources {
+		if s.locale.Equal(locale) {
+			sources = append(sources, s)
+		}
+	}
+	f.mu.RUnlock()
+
+	if len(sources) == 0 {
+		return fmt.Errorf("no local translation source for locale %q", locale)
+	}
+	var errs []error
+	for _, s := range sources {
+		f.configure(s)
+		if err := s.reload(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	f.fuzzy.invalidate()
+	return errors.Join(errs...)
+}
+
+// configure 将工厂的日志函数、并发、加载模式和冲突策略应用到翻译源
+func (f *PrinterFactory) configure(s *Source) {
+	s.SetLogFunc(f.logFunc)
+	s.SetConcurrency(f.workers)
+	s.SetStrict(f.strict)
+	s.SetConflictPolicy(f.conflicts)
+}
+
+// Load 立即重新读取所有本地翻译源的文件，返回由 *LoadError 合并而成的错误。
+//
+// 本地翻译默认在第一次创建使用它的 Printer 时加载，Load 用于在启动时或 CI 中提前发现损坏的翻译文件。
+// 宽松模式下加载所有语言并返回所有失败；严格模式下在第一个失败的语言处停止，见 Strict。
+//
+// 示例：
+//
+//	factory := xtext.NewPrinterFactory(xtext.BaseDir("./locales"), xtext.Strict(true))
+//	if err := factory.Load(); err != nil {
+//	    var loadErr *xtext.LoadError
+//	    if errors.As(err, &loadErr) {
+//	        log.Fatalf("broken translation file %s", loadErr.File)
+//	    }
+//	}
+func (f *PrinterFactory) Load() error {
+	f.mu.RLock()
+	sources := slices.Clone(f.sources)
+	f.mu.RUnlock()
+	defer f.fuzzy.invalidate()
+
+	var errs []error
+	for _, s := range sources {
+		f.configure(s)
+		if err := s.reload(); err != nil {
+			if f.strict {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadErrors 返回所有本地翻译源最近一次加载失败的文件，尚未加载的翻译源不包含在内
+func (f *PrinterFactory) LoadErrors() []*LoadError {
+	f.mu.RLock()
+	sources := slices.Clone(f.sources)
+	f.mu.RUnlock()
+
+	var errs []*LoadError
+	for _, s := range sources {
+		errs = append(errs, s.LoadErrors()...)
+	}
+	return errs
+}
+
+// AddRemoteSource 添加远程翻译源。
+//
+// 远程翻译源的当前快照会立即加载到工厂中，之后每次刷新得到新内容时自动重新加载，
+// 已创建的 Printer 无需重建即可使用新的翻译。远程翻译源在 Reset 后仍然保留，
+// 并在本地文件之后加载，同名消息以远程内容为准。
+func (f *PrinterFactory) AddRemoteSource(r *RemoteSource) {
+	f.addDynamicSource(r)
+}
+
+// dynamicSource 是在后台刷新、Reset 后仍然保留的翻译源，如 RemoteSource 和 SQLSource
+type dynamicSource interface {
+	// locales 返回当前快照包含的语言
+	locales() []msg.Locale
+	// Load 将当前快照加载到 builder 中
+	Load(b *catalog.Builder)
+	// watch 注册快照变化时的回调
+	watch(fn func())
+	// catalogs 返回当前快照中每种语言的消息，用于统计覆盖率和校验
+	catalogs() []sourceCatalog
+}
+
+// sourceCatalog 是动态翻译源中一种语言的消息
+type sourceCatalog struct {
+	name     string // 翻译源的名称，用于日志和校验结果
+	locale   msg.Locale
+	messages []Message
+	err      error // 解析快照失败的原因
+}
+
+// addDynamicSource 添加动态翻译源，立即加载当前快照，并在快照变化时重新加载
+func (f *PrinterFactory) addDynamicSource(d dynamicSource) {
+	f.mu.Lock()
+	f.dynamic = append(f.dynamic, d)
+	for _, locale := range d.locales() {
+		f.addLocale(locale)
+	}
+	d.Load(f.builder)
+	f.mu.Unlock()
+	f.fuzzy.invalidate()
+
+	d.watch(func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		// 快照中可能出现新的语言
+		for _, locale := range d.locales() {
+			f.addLocale(locale)
+		}
+		d.Load(f.builder)
+		f.fuzzy.invalidate()
+	})
+}
+
+// addLocale 将语言加入支持的语言集合，调用者需要持有写锁
+func (f *PrinterFactory) addLocale(locale msg.Locale) {
+	if !slices.Contains(f.locales, locale) {
+		f.locales = append(f.locales, locale)
+	}
+}
+
+// loadSources 扫描目录中的翻译源，fsys 为 nil 时扫描磁盘目录；设置了 PathResolver 时使用它的规则
+func (f *PrinterFactory) loadSources(fsys fs.FS, baseDir string) []*Source {
+	if f.resolver != nil {
+		return f.resolveSources(fsys, baseDir)
+	}
+
 	// 扫描目录，规则是：
 	// - 文件 /baseDir/locale.gotext.json，创建包含该文件的 Source
 	// - 文件 /baseDir/locale.gotext.jsonc，创建包含该文件的 Source
 	// - 目录 /baseDir/locale/，扫描其中所有 .gotext.json 和 .gotext.jsonc 文件，创建 Source
 	var sources []*Source
 
-	if baseDir == "" {
-		return sources
-	}
-
 	// 读取基础目录
-	entries, err := os.ReadDir(baseDir)
+	entries, err := readDir(fsys, baseDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to read base directory %s: %v\n", baseDir, err)
 		return sources
@@ -245,7 +753,7 @@ func (f *PrinterFactory) loadSources(baseDir string) []*Source {
 
 	// 遍历基础目录中的条目
 	for _, entry := range entries {
-		fullPath := baseDir + "/" + entry.Name()
+		fullPath := joinPath(fsys, baseDir, entry.Name())
 
 		if entry.IsDir() {
 			locale, ok := parseBaseLocale(entry.Name())
@@ -256,7 +764,7 @@ func (f *PrinterFactory) loadSources(baseDir string) []*Source {
 			}
 
 			// 如果是目录，扫描其中的所有翻译文件
-			entries := ScanDirectoryForEntries(fullPath, f.loaders)
+			entries := scanEntries(fsys, fullPath, f.loaders)
 			if len(entries) > 0 {
 				source := NewSource(locale, entries)
 				sources = append(sources, source)
@@ -265,13 +773,14 @@ func (f *PrinterFactory) loadSources(baseDir string) []*Source {
 			// 如果是文件，检查是否被加载器支持
 			if loader, ok := f.loaders.GetLoaderForFile(fullPath); ok {
 				// 从文件名推断 locale
-				// 支持格式：locale.gotext.json 或 locale.gotext.jsonc
+				// 支持格式：locale 加上加载器的扩展名，如 locale.gotext.json、locale.gotext.yaml
 				name := entry.Name()
 				var localeName string
-				if strings.HasSuffix(name, ".gotext.json") {
-					localeName = strings.TrimSuffix(name, ".gotext.json")
-				} else if strings.HasSuffix(name, ".gotext.jsonc") {
-					localeName = strings.TrimSuffix(name, ".gotext.jsonc")
+				for _, ext := range loader.Extensions() {
+					if strings.HasSuffix(strings.ToLower(name), strings.ToLower(ext)) {
+						localeName = name[:len(name)-len(ext)]
+						break
+					}
 				}
 
 				if localeName != "" {
@@ -281,7 +790,7 @@ func (f *PrinterFactory) loadSources(baseDir string) []*Source {
 						fmt.Fprintf(os.Stderr, "Warning: invalid locale file name: %s, skipping\n", name)
 						continue
 					}
-					entries := []Entry{{file: fullPath, loader: loader}}
+					entries := []Entry{{file: fullPath, loader: loader, fsys: fsys}}
 					source := NewSource(locale, entries)
 					sources = append(sources, source)
 				}
@@ -289,6 +798,12 @@ func (f *PrinterFactory) loadSources(baseDir string) []*Source {
 		}
 	}
 
+	sortSources(sources)
+	return sources
+}
+
+// sortSources 按语言范围排序翻译源，通用的语言排在前面
+func sortSources(sources []*Source) {
 	// 进行排序，比如：zh-Hans-CN、zh-Hans、zh-CN
 	slices.SortFunc(sources, func(a, b *Source) int {
 		// 注意小的排前面
@@ -302,70 +817,178 @@ func (f *PrinterFactory) loadSources(baseDir string) []*Source {
 		return bytes.Compare([]byte(a.locale), []byte(b.locale))
 	})
 
-	return sources
 }
 
 // CreatePrinter 实现 msg.PrinterFactory 接口
 func (f *PrinterFactory) CreatePrinter(locale msg.Locale) (msg.Printer, error) {
 	// 使用 singleflight 避免重复创建 Printer
 	ch, err, _ := f.sf.Do(string(locale), func() (any, error) {
-		// 检查缓存
-		f.mu.RLock()
-		if printer, exists := f.printers[locale]; exists {
-			f.mu.RUnlock()
-			return printer, nil
-		}
-		f.mu.RUnlock()
+		return f.cachedPrinter(locale)
+	})
 
-		// 加载翻译数据并创建 Printer
-		printer, err := f.loadCatalogAndCreatePrinter(locale)
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		// 存储到缓存
-		f.mu.Lock()
-		f.printers[locale] = printer
-		f.mu.Unlock()
+	return ch.(msg.Printer), nil
+}
 
-		return printer, nil
+// CreatePrinterContext 实现 msg.ContextPrinterFactory 接口。
+//
+// 与 CreatePrinter 相同，但 ctx 先结束时不再等待翻译数据加载完成，返回 ctx.Err()；
+// 加载在后台继续进行，完成后的 Printer 会加入缓存。
+func (f *PrinterFactory) CreatePrinterContext(ctx context.Context, locale msg.Locale) (msg.Printer, error) {
+	ch := f.sf.DoChan(string(locale), func() (any, error) {
+		return f.cachedPrinter(locale)
 	})
 
+	select {
+	case r := <-ch:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(msg.Printer), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// cachedPrinter 返回缓存的 Printer，没有时加载翻译数据并创建，由 singleflight 调用
+func (f *PrinterFactory) cachedPrinter(locale msg.Locale) (msg.Printer, error) {
+	// 检查缓存
+	f.mu.RLock()
+	if printer, exists := f.printers[locale]; exists {
+		f.mu.RUnlock()
+		return printer, nil
+	}
+	f.mu.RUnlock()
+
+	// 加载翻译数据并创建 Printer
+	printer, err := f.loadCatalogAndCreatePrinter(locale)
 	if err != nil {
 		return nil, err
 	}
 
-	return ch.(msg.Printer), nil
+	// 存储到缓存
+	f.mu.Lock()
+	f.printers[locale] = printer
+	f.mu.Unlock()
+
+	return printer, nil
 }
 
-// loadCatalogAndCreatePrinter 加载翻译数据并创建 Printer
+// loadCatalogAndCreatePrinter 加载回退链上所有语言的翻译数据并创建 Printer
 func (f *PrinterFactory) loadCatalogAndCreatePrinter(locale msg.Locale) (msg.Printer, error) {
 	f.mu.RLock()
-	i := slices.IndexFunc(f.sources, func(s *Source) bool {
+	defer f.mu.RUnlock()
+
+	return f.createPrinter(locale)
+}
+
+// createPrinter 创建 locale 的 Printer，调用者需要持有读锁。
+//
+// 语言本身、父语言和基础语言都没有翻译源时，使用回退语言的 Printer。
+func (f *PrinterFactory) createPrinter(locale msg.Locale) (msg.Printer, error) {
+	if !slices.ContainsFunc(f.ancestors(locale), f.containsLocale) && !locale.Equal(f.fallback) {
+		return f.createPrinter(f.fallback)
+	}
+
+	chain := f.chain(locale)
+
+	// 加载包含回退链上任一语言的翻译源，远程翻译源已经加载到 builder 中
+	for _, src := range f.sources {
+		if slices.ContainsFunc(chain, src.locale.Contains) {
+			if src.loaded.Load() == nil {
+				f.configure(src)
+				err := src.load()
+				f.fuzzy.invalidate()
+				if err != nil && f.strict {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return newChainPrinter(locale, chain, f.builder, f.local, f.overrides, f.missing, f.fuzzy)
+}
+
+// containsLocale 检查是否有包含 locale 的本地或动态翻译源，调用者需要持有读锁
+func (f *PrinterFactory) containsLocale(locale msg.Locale) bool {
+	return slices.ContainsFunc(f.sources, func(s *Source) bool {
 		return s.locale.Contains(locale)
+	}) || slices.ContainsFunc(f.dynamic, func(d dynamicSource) bool {
+		return slices.ContainsFunc(d.locales(), func(l msg.Locale) bool {
+			return l.Contains(locale)
+		})
 	})
-	fallback := f.fallback
-	f.mu.RUnlock()
+}
 
-	if i == -1 {
-		if !locale.Equal(fallback) {
-			return f.loadCatalogAndCreatePrinter(fallback)
+// ancestors 返回语言本身及其父语言，禁用继承时只返回语言本身。
+//
+// 父语言包括 golang.org/x/text 定义的父语言（不含 und）、去掉地区的语言和基础语言，
+// 按从具体到通用排列，如 zh-Hans-CN → zh-Hans → zh、zh-HK → zh-Hant → zh。
+func (f *PrinterFactory) ancestors(locale msg.Locale) []msg.Locale {
+	result := []msg.Locale{locale}
+	base, ok := locale.Base()
+	if !ok || !f.inherit {
+		return result
+	}
+
+	lang := base.Language()
+	var parents []msg.Locale
+	if tag, err := language.All.Parse(base.String()); err == nil {
+		for p := tag.Parent(); p != language.Und; p = p.Parent() {
+			parents = append(parents, msg.Locale(p.String()))
 		}
-		// 没有支持的语言，使用基本的 Printer
-		return NewPrinter(locale, message.Catalog(catalog.NewBuilder()))
+		if l, _ := tag.Base(); l.String() != "und" {
+			lang = l.String()
+		}
+	}
+	// x/text 认为 zh-Hans-CN 的父语言是 zh，这里补上中间的 zh-Hans
+	if script := base.Script(); script != "" && base.Region() != "" {
+		parents = append(parents, msg.Locale(lang+"-"+script))
 	}
+	parents = append(parents, msg.Locale(lang))
 
-	// 需要重新获取锁来访问 sources 和 builder
+	// 子标签多的更具体，排在前面
+	slices.SortStableFunc(parents, func(a, b msg.Locale) int {
+		return cmp.Compare(strings.Count(string(b), "-"), strings.Count(string(a), "-"))
+	})
+	for _, p := range parents {
+		result = appendLocale(result, p)
+	}
+	return result
+}
+
+// chain 返回语言的完整回退链，调用者需要持有读锁
+func (f *PrinterFactory) chain(locale msg.Locale) []msg.Locale {
+	result := f.ancestors(locale)
+	for _, l := range f.fallbacks {
+		result = appendLocale(result, l)
+	}
+	return appendLocale(result, f.fallback)
+}
+
+// appendLocale 追加不在列表中的语言
+func appendLocale(locales []msg.Locale, locale msg.Locale) []msg.Locale {
+	if slices.ContainsFunc(locales, locale.Equal) {
+		return locales
+	}
+	return append(locales, locale)
+}
+
+// FallbackChain 返回查找 locale 的翻译时依次尝试的语言。
+//
+// 示例：
+//
+//	factory := xtext.NewPrinterFactory(xtext.Fallbacks(msg.English))
+//	factory.FallbackChain(msg.Locale("zh-HK"))
+//	// [zh-HK zh-Hant zh en]
+func (f *PrinterFactory) FallbackChain(locale msg.Locale) msg.LocaleSet {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
-	// 先加载数据到 builder
-	src := f.sources[i]
-	src.SetLogFunc(f.logFunc)
-	src.Load(f.builder)
-
-	// 然后创建 Printer
-	return NewPrinter(locale, message.Catalog(f.builder))
+	return f.chain(locale)
 }
 
 // SupportsLocale 实现 msg.PrinterFactory 接口
@@ -421,6 +1044,8 @@ func (f *PrinterFactory) SetTranslation(locale msg.Locale, key string, translati
 		return err
 	}
 
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	f.builder.SetString(tag, key, translation)
 	return nil
 }
@@ -431,6 +1056,8 @@ func (f *PrinterFactory) SetMacro(locale msg.Locale, name string, catamsg ...cat
 		return err
 	}
 
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	f.builder.SetMacro(tag, name, catamsg...)
 	return nil
 }
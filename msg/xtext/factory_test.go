@@ -1,11 +1,22 @@
 package xtext
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 
 	"go-slim.dev/infra/msg"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
 )
 
 func TestNewPrinterFactory(t *testing.T) {
@@ -464,3 +475,519 @@ func containsAny(s string, substrings []string) bool {
 	}
 	return false
 }
+
+func TestPrinterFactory_ResetFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{
+  "language": "en",
+  "messages": [{"id": "hello", "message": "hello", "translation": "Hello World"}]
+}`)},
+		"zh-CN/common.gotext.json": {Data: []byte(`{
+  "language": "zh-CN",
+  "messages": [{"id": "hello", "message": "hello", "translation": "你好，世界"}]
+}`)},
+		"README.md": {Data: []byte("not a translation file")},
+	}
+
+	t.Run("ResetFS", func(t *testing.T) {
+		factory := NewPrinterFactory()
+		factory.ResetFS(fsys)
+
+		if got := len(factory.SupportedLocales()); got != 2 {
+			t.Fatalf("SupportedLocales() = %d locales, want 2", got)
+		}
+
+		printer, err := factory.CreatePrinter(msg.Locale("zh-CN"))
+		if err != nil {
+			t.Fatalf("CreatePrinter error = %v", err)
+		}
+		if got := printer.Sprintf("hello"); got != "你好，世界" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "你好，世界")
+		}
+
+		printer, err = factory.CreatePrinter(msg.English)
+		if err != nil {
+			t.Fatalf("CreatePrinter error = %v", err)
+		}
+		if got := printer.Sprintf("hello"); got != "Hello World" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "Hello World")
+		}
+	})
+
+	t.Run("BaseFS option", func(t *testing.T) {
+		factory := NewPrinterFactory(BaseFS(fsys))
+		if !factory.SupportsLocale(msg.Locale("zh-CN")) {
+			t.Error("Factory should support zh-CN loaded from BaseFS")
+		}
+	})
+
+	t.Run("Nil fs", func(t *testing.T) {
+		factory := NewPrinterFactory()
+		factory.ResetFS(nil)
+		if !factory.SupportsLocale(msg.English) {
+			t.Error("Factory should support English after ResetFS(nil)")
+		}
+	})
+}
+
+func TestPrinterFactory_ResetMixedFormats(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json":    {Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}]}`)},
+		"zh-CN.gotext.yaml": {Data: []byte("language: zh-CN\nmessages:\n  - id: hello\n    translation: 你好\n")},
+		"ja.gotext.toml":    {Data: []byte("language = \"ja\"\n\n[[messages]]\nid = \"hello\"\ntranslation = \"こんにちは\"\n")},
+	}
+
+	factory := NewPrinterFactory(BaseFS(fsys))
+	for locale, want := range map[msg.Locale]string{"en": "Hello", "zh-CN": "你好", "ja": "こんにちは"} {
+		printer, err := factory.CreatePrinter(locale)
+		if err != nil {
+			t.Fatalf("CreatePrinter(%s) error = %v", locale, err)
+		}
+		if got := printer.Sprintf("hello"); got != want {
+			t.Errorf("%s Sprintf(hello) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestPrinterFactory_FallbackChain(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json":      {Data: []byte(`{"language": "en", "messages": [{"id": "a", "translation": "A"}, {"id": "b", "translation": "B"}, {"id": "c", "translation": "C"}]}`)},
+		"zh.gotext.json":      {Data: []byte(`{"language": "zh", "messages": [{"id": "a", "translation": "甲"}, {"id": "b", "translation": "乙"}]}`)},
+		"zh-Hant.gotext.json": {Data: []byte(`{"language": "zh-Hant", "messages": [{"id": "a", "translation": "甲（繁）"}]}`)},
+	}
+	counter := msg.NewMissingCounter()
+	factory := NewPrinterFactory(BaseFS(fsys), Fallbacks(msg.English), Missing(counter))
+
+	chain := factory.FallbackChain(msg.Locale("zh-HK"))
+	want := msg.LocaleSet{"zh-HK", "zh-Hant", "zh", "en"}
+	if len(chain) != len(want) {
+		t.Fatalf("FallbackChain(zh-HK) = %v, want %v", chain, want)
+	}
+	for i := range want {
+		if !chain[i].Equal(want[i]) {
+			t.Errorf("FallbackChain(zh-HK)[%d] = %q, want %q", i, chain[i], want[i])
+		}
+	}
+
+	printer, err := factory.CreatePrinter(msg.Locale("zh-HK"))
+	if err != nil {
+		t.Fatalf("CreatePrinter(zh-HK) error = %v", err)
+	}
+	if !printer.Locale().Equal(msg.Locale("zh-HK")) {
+		t.Errorf("Locale() = %q, want zh-HK", printer.Locale())
+	}
+	for key, want := range map[string]string{"a": "甲（繁）", "b": "乙", "c": "C", "d": "d"} {
+		if got := printer.Sprintf(key); got != want {
+			t.Errorf("Sprintf(%q) = %q, want %q", key, got, want)
+		}
+	}
+	printer.Sprintf("d")
+
+	entries := counter.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Entries() = %v, want one entry", entries)
+	}
+	if e := entries[0]; !e.Locale.Equal(msg.Locale("zh-HK")) || e.ID != "d" || e.Count != 2 {
+		t.Errorf("Entries()[0] = %+v, want {zh-HK d 2}", e)
+	}
+}
+
+func TestPrinterFactory_MissingPlural(t *testing.T) {
+	counter := msg.NewMissingCounter()
+	factory := NewPrinterFactory(Missing(counter))
+	factory.Reset("", func(b *catalog.Builder) {
+		b.Set(language.English, "%d files", plural.Selectf(1, "%d",
+			"one", "%d file",
+			"other", "%d files",
+		))
+	})
+
+	printer, err := factory.CreatePrinter(msg.English)
+	if err != nil {
+		t.Fatalf("CreatePrinter() error = %v", err)
+	}
+	if got := printer.Sprintf("%d files", 1); got != "1 file" {
+		t.Errorf("Sprintf() = %q, want %q", got, "1 file")
+	}
+	if entries := counter.Entries(); len(entries) != 0 {
+		t.Errorf("Entries() = %v, want none", entries)
+	}
+}
+
+func TestPrinterFactory_AddMissingReporter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [{"id": "a", "translation": "A"}]}`)},
+	}
+	counter := msg.NewMissingCounter()
+	factory := NewPrinterFactory(BaseFS(fsys), Missing(counter))
+
+	printer, err := factory.CreatePrinter(msg.English)
+	if err != nil {
+		t.Fatalf("CreatePrinter() error = %v", err)
+	}
+
+	// 添加的报告器对已创建的 Printer 同样生效，Missing 配置的报告器继续接收报告
+	added := msg.NewMissingCounter()
+	factory.AddMissingReporter(added)
+	printer.Sprintf("b")
+
+	for name, c := range map[string]*msg.MissingCounter{"Missing": counter, "AddMissingReporter": added} {
+		entries := c.Entries()
+		if len(entries) != 1 || entries[0].ID != "b" || entries[0].Count != 1 {
+			t.Errorf("%s entries = %v, want {en b 1}", name, entries)
+		}
+	}
+}
+
+func TestPrinterFactory_ReloadLocale(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [{"id": "a", "translation": "A"}, {"id": "b", "translation": "B"}]}`)},
+		"zh.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "a", "translation": "甲"}, {"id": "b", "translation": "乙"}]}`)},
+	}
+	factory := NewPrinterFactory(BaseFS(fsys), Fallbacks(msg.English))
+
+	zh, err := factory.CreatePrinter(msg.Chinese)
+	if err != nil {
+		t.Fatalf("CreatePrinter(zh) error = %v", err)
+	}
+	en, err := factory.CreatePrinter(msg.English)
+	if err != nil {
+		t.Fatalf("CreatePrinter(en) error = %v", err)
+	}
+	if got := zh.Sprintf("b"); got != "乙" {
+		t.Fatalf("Sprintf(b) = %q, want %q", got, "乙")
+	}
+
+	fsys["zh.gotext.json"] = &fstest.MapFile{Data: []byte(`{"language": "zh", "messages": [{"id": "a", "translation": "甲（新）"}]}`)}
+	fsys["en.gotext.json"] = &fstest.MapFile{Data: []byte(`{"language": "en", "messages": [{"id": "a", "translation": "A (new)"}]}`)}
+	if err := factory.ReloadLocale(msg.Chinese); err != nil {
+		t.Fatalf("ReloadLocale(zh) error = %v", err)
+	}
+
+	// 删除的消息回退到英语，未重新加载的英语保持不变
+	for key, want := range map[string]string{"a": "甲（新）", "b": "B"} {
+		if got := zh.Sprintf(key); got != want {
+			t.Errorf("zh Sprintf(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if got := en.Sprintf("a"); got != "A" {
+		t.Errorf("en Sprintf(a) = %q, want %q", got, "A")
+	}
+
+	if err := factory.ReloadLocale(msg.Japanese); err == nil {
+		t.Error("ReloadLocale(ja) error = nil, want error for locale without source")
+	}
+}
+
+func TestPrinterFactory_LoadConcurrency(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := range 20 {
+		// 每个文件都覆盖 last，最后一个文件的译文生效
+		data := fmt.Sprintf(`{"language": "zh", "messages": [{"id": "m%d", "translation": "消息%d"}, {"id": "last", "translation": "文件%d"}]}`, i, i, i)
+		fsys[fmt.Sprintf("zh/%02d.gotext.json", i)] = &fstest.MapFile{Data: []byte(data)}
+	}
+	source, err := NewSourceFS(msg.Chinese, fsys, "zh/*.gotext.json")
+	if err != nil {
+		t.Fatalf("NewSourceFS() error = %v", err)
+	}
+	factory := NewPrinterFactory(LoadConcurrency(4))
+	factory.SetSource(source)
+
+	printer, err := factory.CreatePrinter(msg.Chinese)
+	if err != nil {
+		t.Fatalf("CreatePrinter(zh) error = %v", err)
+	}
+	for key, want := range map[string]string{"m0": "消息0", "m19": "消息19", "last": "文件19"} {
+		if got := printer.Sprintf(key); got != want {
+			t.Errorf("Sprintf(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	fsys["zh/05.gotext.json"] = &fstest.MapFile{Data: []byte(`{`)}
+	fsys["zh/12.gotext.json"] = &fstest.MapFile{Data: []byte(`{"language": "zh", "messages": [{"id": "m12", "translation": "新消息12"}]}`)}
+	err = factory.ReloadLocale(msg.Chinese)
+	if err == nil || !strings.Contains(err.Error(), "zh/05.gotext.json") {
+		t.Errorf("ReloadLocale(zh) error = %v, want error for zh/05.gotext.json", err)
+	}
+
+	// 解析失败的文件被跳过，其余文件仍然加载
+	for key, want := range map[string]string{"m5": "m5", "m12": "新消息12", "m19": "消息19", "last": "文件19"} {
+		if got := printer.Sprintf(key); got != want {
+			t.Errorf("after reload Sprintf(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestPrinterFactory_Strict(t *testing.T) {
+	newFS := func() fstest.MapFS {
+		return fstest.MapFS{
+			"en.gotext.json":    {Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}]}`)},
+			"zh/a.gotext.json":  {Data: []byte(`{"language": "zh", "messages": [{"id": "hello", "translation": "你好"}]}`)},
+			"zh/b.gotext.json":  {Data: []byte(`{`)},
+			"ja/a.gotext.json":  {Data: []byte(`{"language": "ja", "messages": [{"id": "hello", "translation": "こんにちは"}]}`)},
+			"ko/ko.gotext.json": {Data: []byte(`[`)},
+		}
+	}
+
+	t.Run("Lenient", func(t *testing.T) {
+		factory := NewPrinterFactory(BaseFS(newFS()), Fallbacks(msg.English))
+		err := factory.Load()
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("Load() error = %v, want *LoadError", err)
+		}
+		files := make([]string, 0, 2)
+		for _, e := range factory.LoadErrors() {
+			files = append(files, e.File)
+		}
+		if want := []string{"ko/ko.gotext.json", "zh/b.gotext.json"}; !slices.Equal(files, want) {
+			t.Errorf("LoadErrors() files = %v, want %v", files, want)
+		}
+
+		printer, err := factory.CreatePrinter(msg.Chinese)
+		if err != nil {
+			t.Fatalf("CreatePrinter(zh) error = %v", err)
+		}
+		if got := printer.Sprintf("hello"); got != "你好" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "你好")
+		}
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		fsys := newFS()
+		factory := NewPrinterFactory(BaseFS(fsys), Fallbacks(msg.English), Strict(true))
+		err := factory.Load()
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) || loadErr.File != "ko/ko.gotext.json" || loadErr.Locale != msg.Korean {
+			t.Fatalf("Load() error = %v, want the error of ko/ko.gotext.json", err)
+		}
+
+		if _, err := factory.CreatePrinter(msg.Chinese); err == nil {
+			t.Error("CreatePrinter(zh) error = nil, want error for broken file")
+		}
+		if _, err := factory.CreatePrinter(msg.Japanese); err != nil {
+			t.Errorf("CreatePrinter(ja) error = %v", err)
+		}
+
+		// 修复文件后重新加载成功，再次损坏时保留之前的翻译
+		fsys["zh/b.gotext.json"] = &fstest.MapFile{Data: []byte(`{"language": "zh", "messages": []}`)}
+		if err := factory.ReloadLocale(msg.Chinese); err != nil {
+			t.Fatalf("ReloadLocale(zh) error = %v", err)
+		}
+		printer, err := factory.CreatePrinter(msg.Chinese)
+		if err != nil {
+			t.Fatalf("CreatePrinter(zh) error = %v", err)
+		}
+		fsys["zh/a.gotext.json"] = &fstest.MapFile{Data: []byte(`{"language": "zh", "messages": [{"id": "hello", "translation": "您好"}]}`)}
+		fsys["zh/b.gotext.json"] = &fstest.MapFile{Data: []byte(`{`)}
+		if err := factory.ReloadLocale(msg.Chinese); err == nil {
+			t.Error("ReloadLocale(zh) error = nil, want error for broken file")
+		}
+		if got := printer.Sprintf("hello"); got != "你好" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "你好")
+		}
+	})
+}
+
+// countingLoader 记录每个文件被解析的次数
+type countingLoader struct {
+	*JSONLoader
+	mu     sync.Mutex
+	parsed map[string]int
+}
+
+func (l *countingLoader) ParseMessages(filename string, data []byte) ([]Message, error) {
+	l.mu.Lock()
+	l.parsed[filename]++
+	l.mu.Unlock()
+	return l.JSONLoader.ParseMessages(filename, data)
+}
+
+func TestPrinterFactory_IncrementalReload(t *testing.T) {
+	loader := &countingLoader{JSONLoader: NewJSONLoader(), parsed: make(map[string]int)}
+	loaders := NewLoaderRegistry()
+	loaders.Register(loader)
+
+	fsys := fstest.MapFS{
+		"zh/a.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "a", "translation": "甲"}]}`)},
+		"zh/b.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "b", "translation": "乙"}]}`)},
+		"en.gotext.json":   {Data: []byte(`{"language": "en", "messages": [{"id": "a", "translation": "A"}]}`)},
+		"ja.gotext.json":   {Data: []byte(`{"language": "ja", "messages": [{"id": "a", "translation": "あ"}]}`)},
+	}
+	factory := NewPrinterFactory(Loaders(loaders), BaseFS(fsys))
+	for _, locale := range []msg.Locale{msg.Chinese, msg.English} {
+		if _, err := factory.CreatePrinter(locale); err != nil {
+			t.Fatalf("CreatePrinter(%s) error = %v", locale, err)
+		}
+	}
+
+	fsys["zh/b.gotext.json"] = &fstest.MapFile{Data: []byte(`{"language": "zh", "messages": [{"id": "b", "translation": "乙（新）"}]}`)}
+	factory.ResetFS(fsys)
+	if err := factory.ReloadLocale(msg.English); err != nil {
+		t.Fatalf("ReloadLocale(en) error = %v", err)
+	}
+
+	// 只有变化的文件被重新解析，未加载过的日语仍然延迟加载
+	want := map[string]int{"zh/a.gotext.json": 1, "zh/b.gotext.json": 2, "en.gotext.json": 1}
+	if !maps.Equal(loader.parsed, want) {
+		t.Errorf("parsed = %v, want %v", loader.parsed, want)
+	}
+
+	printer, err := factory.CreatePrinter(msg.Chinese)
+	if err != nil {
+		t.Fatalf("CreatePrinter(zh) error = %v", err)
+	}
+	for key, want := range map[string]string{"a": "甲", "b": "乙（新）"} {
+		if got := printer.Sprintf(key); got != want {
+			t.Errorf("Sprintf(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestPrinterFactory_SetSource(t *testing.T) {
+	factory := NewPrinterFactory(BaseFS(fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}]}`)},
+	}))
+
+	before, err := factory.CreatePrinter(msg.Japanese)
+	if err != nil {
+		t.Fatalf("CreatePrinter(ja) error = %v", err)
+	}
+	if got := before.Sprintf("hello"); got != "Hello" {
+		t.Fatalf("Sprintf(hello) = %q, want fallback %q", got, "Hello")
+	}
+
+	newSource := func(translation string) *Source {
+		t.Helper()
+		source, err := NewSourceFS(msg.Japanese, fstest.MapFS{
+			"ja.gotext.json": {Data: []byte(`{"language": "ja", "messages": [{"id": "hello", "translation": "` + translation + `"}]}`)},
+		})
+		if err != nil {
+			t.Fatalf("NewSourceFS() error = %v", err)
+		}
+		return source
+	}
+
+	factory.SetSource(newSource("こんにちは"))
+	if !factory.SupportsLocale(msg.Japanese) {
+		t.Error("SupportsLocale(ja) = false after SetSource")
+	}
+	ja, err := factory.CreatePrinter(msg.Japanese)
+	if err != nil {
+		t.Fatalf("CreatePrinter(ja) error = %v", err)
+	}
+	if got := ja.Sprintf("hello"); got != "こんにちは" {
+		t.Errorf("Sprintf(hello) = %q, want %q", got, "こんにちは")
+	}
+
+	// 替换同一语言的翻译源，已创建的 Printer 立即使用新的翻译
+	factory.SetSource(newSource("やあ"))
+	if got := ja.Sprintf("hello"); got != "やあ" {
+		t.Errorf("Sprintf(hello) after replace = %q, want %q", got, "やあ")
+	}
+	if n := len(factory.SupportedLocales()); n != 2 {
+		t.Errorf("SupportedLocales() = %d locales, want 2", n)
+	}
+}
+
+func TestPrinterFactory_ConcurrentReload(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}]}`)},
+		"zh.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "hello", "translation": "你好"}]}`)},
+	}
+	factory := NewPrinterFactory(BaseFS(fsys))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for range 50 {
+				switch i % 4 {
+				case 0:
+					if err := factory.ReloadLocale(msg.Chinese); err != nil {
+						t.Errorf("ReloadLocale(zh) error = %v", err)
+					}
+				case 1:
+					_ = factory.SetTranslation(msg.English, "bye", "Bye")
+				default:
+					printer, err := factory.CreatePrinter(msg.Chinese)
+					if err != nil {
+						t.Errorf("CreatePrinter(zh) error = %v", err)
+						return
+					}
+					if got := printer.Sprintf("hello"); got != "你好" {
+						t.Errorf("Sprintf(hello) = %q, want %q", got, "你好")
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestPrinterFactory_CreatePrinterContext(t *testing.T) {
+	factory := NewPrinterFactory(BaseFS(fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}]}`)},
+	}))
+
+	printer, err := factory.CreatePrinterContext(context.Background(), msg.English)
+	if err != nil {
+		t.Fatalf("CreatePrinterContext() error = %v", err)
+	}
+	if got := printer.Sprintf("hello"); got != "Hello" {
+		t.Errorf("Sprintf(hello) = %q, want %q", got, "Hello")
+	}
+	cached, err := factory.CreatePrinter(msg.English)
+	if err != nil || cached != printer {
+		t.Errorf("CreatePrinter() = %v, %v, want the printer cached by CreatePrinterContext", cached, err)
+	}
+}
+
+func TestPrinterFactory_Inheritance(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json":         {Data: []byte(`{"language": "en", "messages": [{"id": "a", "translation": "A"}, {"id": "b", "translation": "B"}, {"id": "c", "translation": "C"}]}`)},
+		"zh.gotext.json":         {Data: []byte(`{"language": "zh", "messages": [{"id": "a", "translation": "甲"}, {"id": "b", "translation": "乙"}]}`)},
+		"zh-Hans.gotext.json":    {Data: []byte(`{"language": "zh-Hans", "messages": [{"id": "b", "translation": "乙（简体）"}]}`)},
+		"zh-Hans-CN.gotext.json": {Data: []byte(`{"language": "zh-Hans-CN", "messages": [{"id": "c", "translation": "丙"}]}`)},
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+	}{
+		{
+			name: "Inherited",
+			want: map[string]string{"a": "甲", "b": "乙（简体）", "c": "丙"},
+		},
+		{
+			name: "Exact",
+			opts: []Option{Inheritance(false)},
+			want: map[string]string{"a": "A", "b": "B", "c": "丙"},
+		},
+		{
+			name: "Exact with explicit fallbacks",
+			opts: []Option{Inheritance(false), Fallbacks(msg.Chinese, msg.English), Fallback(msg.English)},
+			want: map[string]string{"a": "甲", "b": "乙", "c": "丙"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := NewPrinterFactory(append([]Option{BaseFS(fsys)}, tt.opts...)...)
+			p, err := factory.CreatePrinter(msg.Locale("zh-Hans-CN"))
+			if err != nil {
+				t.Fatalf("CreatePrinter() error = %v", err)
+			}
+			for key, want := range tt.want {
+				if got := p.Sprintf(key); got != want {
+					t.Errorf("Sprintf(%q) = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+
+	factory := NewPrinterFactory(BaseFS(fsys), Inheritance(false))
+	if got, want := factory.FallbackChain(msg.Locale("zh-Hans-CN")), (msg.LocaleSet{"zh-Hans-CN", "en"}); !slices.Equal(got, want) {
+		t.Errorf("FallbackChain() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,935 @@
+package xtext
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/message/catalog"
+)
+
+// FluentLoader 实现 Mozilla Fluent（.ftl）格式的加载器。
+//
+// 支持的文件格式：
+// - .ftl: Fluent 资源文件，每个文件包含一种语言的翻译
+//
+// Fluent 消息被转换为 msg.Sprintn 和 msg.T 使用的命名参数模板：
+//   - 变量 { $name } 转换为占位符 {name}，NUMBER($n) 等函数的选项被忽略，值由 Printer 按语言格式化
+//   - 项（-brand）和消息引用在加载时展开，带参数的项引用使用调用时给出的字面量参数
+//   - 属性作为单独的消息加载，ID 为 "消息 ID.属性名"
+//   - 选择器为变量时转换为 {name, plural, ...} 或 {name, select, ...}，
+//     变体键全部为数字或 CLDR 复数类别时使用 plural，默认变体作为 other 分支；
+//     选择器为字面量或项属性时在加载时选择变体
+//   - 紧邻消息的单个 # 注释作为给译者的说明
+//
+// 示例：
+//
+//	-brand = Firefox
+//	    .gender = masculine
+//
+//	# 首页的问候语
+//	welcome = Welcome to { -brand }, { $name }!
+//
+//	emails = { $count ->
+//	    [0] No new emails
+//	    [one] One new email
+//	   *[other] { $count } new emails
+//	}
+//
+//	login-input = Predefined value
+//	    .placeholder = email@example.com
+type FluentLoader struct {
+	name       string
+	extensions []string
+}
+
+// NewFluentLoader 创建新的 Fluent 加载器。
+func NewFluentLoader() *FluentLoader {
+	return &FluentLoader{
+		name:       "Fluent",
+		extensions: []string{".ftl"},
+	}
+}
+
+// Name 返回加载器名称。
+func (l *FluentLoader) Name() string {
+	return l.name
+}
+
+// Extensions 返回支持的文件扩展名列表。
+func (l *FluentLoader) Extensions() []string {
+	return l.extensions
+}
+
+// CanLoad 检查是否可以加载指定文件。
+func (l *FluentLoader) CanLoad(filename string) bool {
+	return hasExtension(filename, l.extensions)
+}
+
+// LoadToBuilder 加载 Fluent 格式的翻译文件并写入到指定的 builder 中。
+func (l *FluentLoader) LoadToBuilder(filename string, data []byte, builder *catalog.Builder, locale msg.Locale) error {
+	messages, err := l.ParseMessages(filename, data)
+	if err != nil {
+		return err
+	}
+	loadMessages(builder, locale, messages)
+	return nil
+}
+
+// ParseMessages 实现 MessageParser 接口。
+func (l *FluentLoader) ParseMessages(filename string, data []byte) ([]Message, error) {
+	src := strings.TrimPrefix(string(data), "\ufeff")
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+
+	entries, err := parseFluent(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Fluent translation file %s: %w", filename, err)
+	}
+
+	r := &ftlResolver{
+		messages: make(map[string]*ftlEntry),
+		terms:    make(map[string]*ftlEntry),
+		visiting: make(map[string]bool),
+	}
+	for _, e := range entries {
+		if e.term {
+			r.terms[e.id] = e
+		} else {
+			r.messages[e.id] = e
+		}
+	}
+
+	var messages []Message
+	for _, e := range entries {
+		if e.term {
+			continue
+		}
+		if e.value != nil {
+			s, err := r.reference(e, "", nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Fluent translation file %s: %w", filename, err)
+			}
+			messages = append(messages, Message{ID: e.id, Translation: s, Note: e.comment})
+		}
+		for _, a := range e.attrs {
+			s, err := r.reference(e, a.name, nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Fluent translation file %s: %w", filename, err)
+			}
+			messages = append(messages, Message{ID: e.id + "." + a.name, Translation: s, Note: e.comment})
+		}
+	}
+	return messages, nil
+}
+
+// ftlEntry 是 Fluent 资源中的消息或项
+type ftlEntry struct {
+	id      string
+	term    bool       // 项（以 "-" 开头），只能被引用
+	value   ftlPattern // 消息的值，只有属性的消息为 nil
+	attrs   []ftlAttribute
+	comment string // 紧邻的 # 注释
+	line    int
+}
+
+type ftlAttribute struct {
+	name  string
+	value ftlPattern
+}
+
+// ftlPattern 是由文本和占位符组成的模式，缩进和首尾空白已经按 Fluent 规则处理
+type ftlPattern []ftlElement
+
+// ftlElement 是文本或占位符，expr 为 nil 时为文本
+type ftlElement struct {
+	text string
+	expr *ftlExpr
+}
+
+type ftlKind int
+
+const (
+	ftlString   ftlKind = iota // "literal"
+	ftlNumber                  // 1.5
+	ftlVariable                // $name
+	ftlMessage                 // message 或 message.attr
+	ftlTerm                    // -term、-term.attr 或 -term(key: "value")
+	ftlFunction                // NUMBER($n)
+	ftlSelect                  // selector -> [key] ...
+)
+
+// ftlExpr 是占位符中的表达式
+type ftlExpr struct {
+	kind     ftlKind
+	value    string              // 字面量的值、变量名、引用的 ID 或函数名
+	attr     string              // 引用的属性名
+	args     []*ftlExpr          // 函数的位置参数
+	named    map[string]*ftlExpr // 函数和项的命名参数
+	selector *ftlExpr
+	variants []ftlVariant
+}
+
+type ftlVariant struct {
+	key   string
+	def   bool // 默认变体（*[key]）
+	value ftlPattern
+}
+
+// ftlParser 解析 Fluent 资源，src 使用 LF 换行
+type ftlParser struct {
+	src string
+	pos int
+}
+
+// parseFluent 解析 Fluent 资源中的消息和项，注释和空行被忽略
+func parseFluent(src string) ([]*ftlEntry, error) {
+	p := &ftlParser{src: src}
+	var entries []*ftlEntry
+	var comment []string // 等待附加到下一条消息的注释
+
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '\n':
+			// 空行分隔注释和消息
+			p.pos++
+			comment = nil
+		case c == ' ':
+			p.skipInline()
+			if !p.atLineEnd() {
+				return nil, p.errorf("unexpected indentation")
+			}
+		case c == '#':
+			level, text := p.commentLine()
+			if level == 1 {
+				comment = append(comment, text)
+			} else {
+				comment = nil
+			}
+		case c == '-' || isIdentStart(c):
+			e, err := p.entry()
+			if err != nil {
+				return nil, err
+			}
+			if !e.term {
+				e.comment = strings.Join(comment, "\n")
+			}
+			comment = nil
+			entries = append(entries, e)
+		default:
+			return nil, p.errorf("expected a message, term or comment, got %q", c)
+		}
+	}
+	return entries, nil
+}
+
+// errorf 返回带当前行号的错误
+func (p *ftlParser) errorf(format string, args ...any) error {
+	line := strings.Count(p.src[:p.pos], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// peek 返回当前字符，到达末尾时返回 0
+func (p *ftlParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// expect 跳过字符 c，当前字符不是 c 时返回错误
+func (p *ftlParser) expect(c byte) error {
+	if p.peek() != c {
+		if p.pos >= len(p.src) {
+			return p.errorf("expected %q, got end of file", c)
+		}
+		return p.errorf("expected %q, got %q", c, p.src[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// atLineEnd 报告当前位置是否为行尾或文件末尾
+func (p *ftlParser) atLineEnd() bool {
+	return p.pos >= len(p.src) || p.src[p.pos] == '\n'
+}
+
+// skipInline 跳过行内的空格
+func (p *ftlParser) skipInline() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// skipBlank 跳过空格和换行
+func (p *ftlParser) skipBlank() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+// commentLine 读取一行注释，返回 # 的个数和注释内容
+func (p *ftlParser) commentLine() (int, string) {
+	level := 0
+	for p.peek() == '#' {
+		level++
+		p.pos++
+	}
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		end = len(p.src) - p.pos
+	}
+	text := strings.TrimPrefix(p.src[p.pos:p.pos+end], " ")
+	p.pos += end
+	if p.pos < len(p.src) {
+		p.pos++
+	}
+	return level, text
+}
+
+// identifier 读取标识符 [a-zA-Z][a-zA-Z0-9_-]*
+func (p *ftlParser) identifier() (string, error) {
+	start := p.pos
+	if !isIdentStart(p.peek()) {
+		return "", p.errorf("expected an identifier")
+	}
+	for p.pos < len(p.src) && isIdentChar(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos], nil
+}
+
+// entry 读取一条消息或项及其属性
+func (p *ftlParser) entry() (*ftlEntry, error) {
+	e := &ftlEntry{line: strings.Count(p.src[:p.pos], "\n") + 1}
+	if p.peek() == '-' {
+		e.term = true
+		p.pos++
+	}
+	id, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	e.id = id
+
+	p.skipInline()
+	if err := p.expect('='); err != nil {
+		return nil, err
+	}
+	p.skipInline()
+	if e.value, err = p.pattern(); err != nil {
+		return nil, err
+	}
+
+	for p.attributeStart() {
+		p.pos++
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		p.skipInline()
+		if err := p.expect('='); err != nil {
+			return nil, err
+		}
+		p.skipInline()
+		value, err := p.pattern()
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, p.errorf("attribute %q of %q has no value", name, id)
+		}
+		e.attrs = append(e.attrs, ftlAttribute{name: name, value: value})
+	}
+
+	switch {
+	case !p.atLineEnd():
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	case e.term && e.value == nil:
+		return nil, p.errorf("term %q has no value", id)
+	case e.value == nil && len(e.attrs) == 0:
+		return nil, p.errorf("message %q has no value or attributes", id)
+	}
+	return e, nil
+}
+
+// attributeStart 检查之后的行是否以 "." 开始属性，是时移动到 "." 处
+func (p *ftlParser) attributeStart() bool {
+	i := p.pos
+	for i < len(p.src) && (p.src[i] == '\n' || p.src[i] == ' ') {
+		i++
+	}
+	if i == p.pos || i >= len(p.src) || p.src[i] != '.' || !strings.Contains(p.src[p.pos:i], "\n") {
+		return false
+	}
+	p.pos = i
+	return true
+}
+
+// ftlLine 是解析模式时的中间结果，indent 大于 0 时表示块状行的缩进
+type ftlLine struct {
+	ftlElement
+	indent int
+}
+
+// pattern 读取模式，直到不属于模式的行尾或 "}"，没有内容时返回 nil。
+//
+// 缩进的后续行属于模式，以 "["、"*"、"." 或 "}" 开头的行除外；
+// 所有后续行共同的缩进被去掉，首尾的空白被去掉。
+func (p *ftlParser) pattern() (ftlPattern, error) {
+	var lines []ftlLine
+loop:
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; c {
+		case '{':
+			expr, err := p.placeable()
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, ftlLine{ftlElement: ftlElement{expr: expr}})
+		case '}':
+			break loop
+		case '\n':
+			newlines, indent, ok := p.continuation()
+			if !ok {
+				break loop
+			}
+			lines = append(lines,
+				ftlLine{ftlElement: ftlElement{text: strings.Repeat("\n", newlines)}},
+				ftlLine{indent: indent})
+		default:
+			end := strings.IndexAny(p.src[p.pos:], "{}\n")
+			if end < 0 {
+				end = len(p.src) - p.pos
+			}
+			lines = append(lines, ftlLine{ftlElement: ftlElement{text: p.src[p.pos : p.pos+end]}})
+			p.pos += end
+		}
+	}
+
+	common := -1
+	for _, l := range lines {
+		if l.indent > 0 && (common < 0 || l.indent < common) {
+			common = l.indent
+		}
+	}
+
+	var pattern ftlPattern
+	for i, l := range lines {
+		e := l.ftlElement
+		if l.indent > 0 {
+			e.text = strings.Repeat(" ", l.indent-common)
+		}
+		if i == 0 && e.expr == nil {
+			e.text = strings.TrimLeft(e.text, "\n")
+		}
+		if e.expr == nil && len(pattern) > 0 && pattern[len(pattern)-1].expr == nil {
+			pattern[len(pattern)-1].text += e.text
+			continue
+		}
+		pattern = append(pattern, e)
+	}
+	if n := len(pattern); n > 0 && pattern[n-1].expr == nil {
+		pattern[n-1].text = strings.TrimRight(pattern[n-1].text, " \n")
+		if pattern[n-1].text == "" {
+			pattern = pattern[:n-1]
+		}
+	}
+	if n := len(pattern); n > 0 && pattern[0].expr == nil && strings.Trim(pattern[0].text, " \n") == "" {
+		pattern = pattern[1:]
+	}
+	if len(pattern) == 0 {
+		return nil, nil
+	}
+	return pattern, nil
+}
+
+// continuation 检查行尾之后的行是否属于模式，是时移动到该行的内容处，
+// 返回跳过的换行数和该行的缩进
+func (p *ftlParser) continuation() (newlines, indent int, ok bool) {
+	i := p.pos
+	for i < len(p.src) && p.src[i] == '\n' {
+		newlines++
+		i++
+		start := i
+		for i < len(p.src) && p.src[i] == ' ' {
+			i++
+		}
+		if i >= len(p.src) {
+			return 0, 0, false
+		}
+		if p.src[i] == '\n' {
+			continue // 空行
+		}
+		indent = i - start
+		if indent == 0 || strings.IndexByte("[*.}", p.src[i]) >= 0 {
+			return 0, 0, false
+		}
+		p.pos = i
+		return newlines, indent, true
+	}
+	return 0, 0, false
+}
+
+// placeable 读取 "{" 和 "}" 之间的占位符
+func (p *ftlParser) placeable() (*ftlExpr, error) {
+	p.pos++ // {
+	p.skipBlank()
+
+	var expr *ftlExpr
+	var err error
+	if p.peek() == '{' {
+		expr, err = p.placeable()
+	} else {
+		expr, err = p.inlineExpression()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+	if strings.HasPrefix(p.src[p.pos:], "->") {
+		p.pos += 2
+		p.skipInline()
+		if expr.kind == ftlMessage || expr.kind == ftlSelect || (expr.kind == ftlTerm && expr.attr == "") {
+			return nil, p.errorf("invalid selector")
+		}
+		variants, err := p.variants()
+		if err != nil {
+			return nil, err
+		}
+		expr = &ftlExpr{kind: ftlSelect, selector: expr, variants: variants}
+		p.skipBlank()
+	}
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// variants 读取选择表达式的变体列表，必须有且只有一个默认变体
+func (p *ftlParser) variants() ([]ftlVariant, error) {
+	if !p.atLineEnd() {
+		return nil, p.errorf("expected a new line after \"->\"")
+	}
+
+	var variants []ftlVariant
+	defaults := 0
+	for {
+		p.skipBlank()
+		v := ftlVariant{}
+		switch p.peek() {
+		case '*':
+			v.def = true
+			defaults++
+			p.pos++
+		case '[':
+		default:
+			if defaults != 1 {
+				return nil, p.errorf("select expression must have exactly one default variant")
+			}
+			return variants, nil
+		}
+
+		if err := p.expect('['); err != nil {
+			return nil, err
+		}
+		end := strings.IndexAny(p.src[p.pos:], "]\n")
+		if end < 0 || p.src[p.pos+end] != ']' {
+			return nil, p.errorf("unterminated variant key")
+		}
+		v.key = strings.TrimSpace(p.src[p.pos : p.pos+end])
+		if !isVariantKey(v.key) {
+			return nil, p.errorf("invalid variant key %q", v.key)
+		}
+		p.pos += end + 1
+		p.skipInline()
+
+		var err error
+		if v.value, err = p.pattern(); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+}
+
+// inlineExpression 读取字面量、变量、引用或函数调用
+func (p *ftlParser) inlineExpression() (*ftlExpr, error) {
+	c := p.peek()
+	switch {
+	case c == '"':
+		s, err := p.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return &ftlExpr{kind: ftlString, value: s}, nil
+	case c == '-' && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1]), isDigit(c):
+		return &ftlExpr{kind: ftlNumber, value: p.numberLiteral()}, nil
+	case c == '$':
+		p.pos++
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		return &ftlExpr{kind: ftlVariable, value: name}, nil
+	case c == '-':
+		p.pos++
+		id, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		expr := &ftlExpr{kind: ftlTerm, value: id}
+		if expr.attr, err = p.attributeAccessor(); err != nil {
+			return nil, err
+		}
+		if p.peek() == '(' {
+			// 项只使用命名参数
+			if _, expr.named, err = p.callArguments(); err != nil {
+				return nil, err
+			}
+		}
+		return expr, nil
+	case isIdentStart(c):
+		id, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() == '(' {
+			expr := &ftlExpr{kind: ftlFunction, value: id}
+			if expr.args, expr.named, err = p.callArguments(); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		}
+		expr := &ftlExpr{kind: ftlMessage, value: id}
+		if expr.attr, err = p.attributeAccessor(); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	default:
+		if c == 0 {
+			return nil, p.errorf("unterminated placeable")
+		}
+		return nil, p.errorf("unexpected %q in placeable", c)
+	}
+}
+
+// attributeAccessor 读取可选的 ".attr"
+func (p *ftlParser) attributeAccessor() (string, error) {
+	if p.peek() != '.' {
+		return "", nil
+	}
+	p.pos++
+	return p.identifier()
+}
+
+// callArguments 读取 "(" 和 ")" 之间的位置参数和命名参数，命名参数的值必须是字面量
+func (p *ftlParser) callArguments() ([]*ftlExpr, map[string]*ftlExpr, error) {
+	p.pos++ // (
+	var args []*ftlExpr
+	named := make(map[string]*ftlExpr)
+	for {
+		p.skipBlank()
+		if p.peek() == ')' {
+			p.pos++
+			return args, named, nil
+		}
+
+		start := p.pos
+		if name, err := p.identifier(); err == nil {
+			p.skipBlank()
+			if p.peek() == ':' {
+				p.pos++
+				p.skipBlank()
+				value, err := p.inlineExpression()
+				if err != nil {
+					return nil, nil, err
+				}
+				if value.kind != ftlString && value.kind != ftlNumber {
+					return nil, nil, p.errorf("value of named argument %q must be a literal", name)
+				}
+				named[name] = value
+			} else {
+				p.pos = start
+			}
+		}
+		if p.pos == start {
+			arg, err := p.inlineExpression()
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, arg)
+		}
+
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+		default:
+			return nil, nil, p.errorf("expected \",\" or \")\" in argument list")
+		}
+	}
+}
+
+// stringLiteral 读取带引号的字符串字面量，支持 \"、\\、\uXXXX 和 \UXXXXXX 转义
+func (p *ftlParser) stringLiteral() (string, error) {
+	p.pos++ // "
+	var b strings.Builder
+	for {
+		if p.atLineEnd() {
+			return "", p.errorf("unterminated string literal")
+		}
+		c := p.src[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			switch next := p.peek(); next {
+			case '"', '\\':
+				b.WriteByte(next)
+				p.pos++
+			case 'u', 'U':
+				n := 4
+				if next == 'U' {
+					n = 6
+				}
+				if p.pos+1+n > len(p.src) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+n], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape \\%s", p.src[p.pos:p.pos+1+n])
+				}
+				b.WriteRune(rune(r))
+				p.pos += 1 + n
+			default:
+				return "", p.errorf("unknown escape sequence \\%c", next)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// numberLiteral 读取 -?[0-9]+(\.[0-9]+)? 形式的数字
+func (p *ftlParser) numberLiteral() string {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	if p.peek() == '.' && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1]) {
+		p.pos++
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	return p.src[start:p.pos]
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '_' || c == '-'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isVariantKey 检查变体键是否为标识符或数字
+func isVariantKey(key string) bool {
+	if isFluentNumber(key) {
+		return true
+	}
+	if key == "" || !isIdentStart(key[0]) {
+		return false
+	}
+	for i := 1; i < len(key); i++ {
+		if !isIdentChar(key[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isFluentNumber 检查 s 是否为 Fluent 数字字面量
+func isFluentNumber(s string) bool {
+	p := &ftlParser{src: s}
+	if !isDigit(p.peek()) && !(p.peek() == '-' && len(s) > 1 && isDigit(s[1])) {
+		return false
+	}
+	return p.numberLiteral() == s
+}
+
+// pluralCategories 是 CLDR 复数类别
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// ftlResolver 将 Fluent 模式转换为命名参数模板，展开消息和项的引用
+type ftlResolver struct {
+	messages map[string]*ftlEntry
+	terms    map[string]*ftlEntry
+	visiting map[string]bool // 正在展开的引用，用于检测循环引用
+}
+
+// reference 展开消息或项的值（attr 为空）或属性。
+// params 为项引用的参数，展开消息时为 nil。
+func (r *ftlResolver) reference(e *ftlEntry, attr string, params map[string]*ftlExpr) (string, error) {
+	name := e.id
+	if e.term {
+		name = "-" + name
+	}
+	if attr != "" {
+		name += "." + attr
+	}
+
+	value := e.value
+	if attr != "" {
+		i := slices.IndexFunc(e.attrs, func(a ftlAttribute) bool { return a.name == attr })
+		if i < 0 {
+			return "", fmt.Errorf("line %d: %q has no attribute %q", e.line, e.id, attr)
+		}
+		value = e.attrs[i].value
+	}
+	if value == nil {
+		return "", fmt.Errorf("line %d: message %q has no value", e.line, e.id)
+	}
+
+	if r.visiting[name] {
+		return "", fmt.Errorf("line %d: cyclic reference to %q", e.line, name)
+	}
+	r.visiting[name] = true
+	defer delete(r.visiting, name)
+
+	if e.term && params == nil {
+		params = map[string]*ftlExpr{}
+	}
+	return r.pattern(value, params)
+}
+
+// pattern 将模式转换为命名参数模板，文本中的 "%" 转义为 "%%"
+func (r *ftlResolver) pattern(pattern ftlPattern, params map[string]*ftlExpr) (string, error) {
+	var b strings.Builder
+	for _, e := range pattern {
+		if e.expr == nil {
+			b.WriteString(strings.ReplaceAll(e.text, "%", "%%"))
+			continue
+		}
+		s, err := r.expr(e.expr, params)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s)
+	}
+	return b.String(), nil
+}
+
+// expr 将占位符中的表达式转换为命名参数模板
+func (r *ftlResolver) expr(e *ftlExpr, params map[string]*ftlExpr) (string, error) {
+	switch e.kind {
+	case ftlString:
+		return escapeTemplate(e.value), nil
+	case ftlNumber:
+		return e.value, nil
+	case ftlVariable:
+		if v, ok := params[e.value]; ok {
+			return r.expr(v, nil)
+		}
+		return "{" + e.value + "}", nil
+	case ftlFunction:
+		// NUMBER、DATETIME 等函数的选项被忽略，参数值由 Printer 格式化
+		if len(e.args) == 0 {
+			return "", nil
+		}
+		return r.expr(e.args[0], params)
+	case ftlMessage:
+		m, ok := r.messages[e.value]
+		if !ok {
+			return "", fmt.Errorf("unknown message %q", e.value)
+		}
+		return r.reference(m, e.attr, nil)
+	case ftlTerm:
+		t, ok := r.terms[e.value]
+		if !ok {
+			return "", fmt.Errorf("unknown term \"-%s\"", e.value)
+		}
+		args := e.named
+		if args == nil {
+			args = map[string]*ftlExpr{}
+		}
+		return r.reference(t, e.attr, args)
+	default:
+		return r.selectExpr(e, params)
+	}
+}
+
+// selectExpr 转换选择表达式。
+// 选择器为变量时转换为 plural 或 select 选择，否则在加载时选择变体。
+func (r *ftlResolver) selectExpr(e *ftlExpr, params map[string]*ftlExpr) (string, error) {
+	sel := e.selector
+	if sel.kind == ftlFunction && len(sel.args) > 0 {
+		sel = sel.args[0]
+	}
+
+	if _, bound := params[sel.value]; sel.kind == ftlVariable && !bound {
+		kind := "plural"
+		for _, v := range e.variants {
+			if !isFluentNumber(v.key) && !slices.Contains(pluralCategories, v.key) {
+				kind = "select"
+			}
+		}
+
+		var b strings.Builder
+		b.WriteString("{" + sel.value + ", " + kind + ",")
+		for _, v := range e.variants {
+			if v.key == "other" && !v.def {
+				continue // 默认变体作为 other 分支
+			}
+			text, err := r.pattern(v.value, params)
+			if err != nil {
+				return "", err
+			}
+			if v.key != "other" {
+				key := v.key
+				if kind == "plural" && isFluentNumber(key) {
+					key = "=" + key
+				}
+				b.WriteString(" " + key + " {" + text + "}")
+			}
+			if v.def {
+				b.WriteString(" other {" + text + "}")
+			}
+		}
+		b.WriteString("}")
+		return b.String(), nil
+	}
+
+	// 字面量、项的参数或属性在加载时就能确定值
+	key, err := r.expr(sel, params)
+	if err != nil {
+		return "", err
+	}
+	key = unescapeTemplate(key)
+	chosen := slices.IndexFunc(e.variants, func(v ftlVariant) bool { return v.key == key })
+	if chosen < 0 {
+		chosen = slices.IndexFunc(e.variants, func(v ftlVariant) bool { return v.def })
+	}
+	return r.pattern(e.variants[chosen].value, params)
+}
+
+// escapeTemplate 转义字面量中命名参数模板的特殊字符
+func escapeTemplate(s string) string {
+	return strings.NewReplacer("%", "%%", "{", "{{", "}", "}}").Replace(s)
+}
+
+// unescapeTemplate 还原 escapeTemplate 转义的字符
+func unescapeTemplate(s string) string {
+	return strings.NewReplacer("%%", "%", "{{", "{", "}}", "}").Replace(s)
+}
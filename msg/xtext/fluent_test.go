@@ -0,0 +1,137 @@
+package xtext
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+func TestFluentLoader_ParseMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Message
+	}{
+		{
+			name: "Variables and comments",
+			data: "### Resource comment\n\n# Greeting on the home page\nhello = Hello, { $name }!\n\n# Detached comment\n\nprogress = { NUMBER($ratio, style: \"percent\") } (100%) done\n",
+			want: []Message{
+				{ID: "hello", Translation: "Hello, {name}!", Note: "Greeting on the home page"},
+				{ID: "progress", Translation: "{ratio} (100%%) done"},
+			},
+		},
+		{
+			name: "Multiline and literals",
+			data: "multi =\n    First line\n      indented\n\n    after blank\nliteral = { \"{\" }{ $x }{ \"}\" } { 42 } \\u{ \"\\u00e9\" }\n",
+			want: []Message{
+				{ID: "multi", Translation: "First line\n  indented\n\nafter blank"},
+				{ID: "literal", Translation: "{{{x}}} 42 \\ué"},
+			},
+		},
+		{
+			name: "Terms, references and attributes",
+			data: "-brand = Firefox\n    .gender = masculine\n-app = { $case ->\n   *[nominative] App\n    [genitive] App's\n}\nabout = About { -brand }\nsettings = { -app(case: \"genitive\") } settings for { about }\nlogin = Sign in\n    .title = { login } to { -brand }\n    .placeholder = you@example.com\nonly-attrs =\n    .label = Label\n",
+			want: []Message{
+				{ID: "about", Translation: "About Firefox"},
+				{ID: "settings", Translation: "App's settings for About Firefox"},
+				{ID: "login", Translation: "Sign in"},
+				{ID: "login.title", Translation: "Sign in to Firefox"},
+				{ID: "login.placeholder", Translation: "you@example.com"},
+				{ID: "only-attrs.label", Translation: "Label"},
+			},
+		},
+		{
+			name: "Selectors",
+			data: "emails = { $count ->\n    [0] No new emails\n    [one] One new email\n   *[other] { $count } new emails\n}\n" +
+				"shared = { $gender ->\n    [female] She shared\n   *[unknown] They shared\n} a photo\n" +
+				"-brand = Firefox\n    .gender = masculine\nupdated = { -brand.gender ->\n    [masculine] Il est à jour\n   *[other] Elle est à jour\n}\n",
+			want: []Message{
+				{ID: "emails", Translation: "{count, plural, =0 {No new emails} one {One new email} other {{count} new emails}}"},
+				{ID: "shared", Translation: "{gender, select, female {She shared} unknown {They shared} other {They shared}} a photo"},
+				{ID: "updated", Translation: "Il est à jour"},
+			},
+		},
+		{
+			name: "CRLF and BOM",
+			data: "\ufeffhello = Hello\r\n    world\r\n",
+			want: []Message{{ID: "hello", Translation: "Hello\nworld"}},
+		},
+		{
+			name: "Empty",
+			data: "## Only comments\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFluentLoader().ParseMessages("zh-CN.ftl", []byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseMessages error = %v", err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMessages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFluentLoader_ParseMessagesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"Missing value", "hello =\n", "line 1: message \"hello\" has no value or attributes"},
+		{"Unclosed placeable", "hello = { $name\n", "line 2: expected '}', got end of file"},
+		{"No default variant", "a = { $n ->\n    [one] One\n    [other] Many\n}\n", "line 4: select expression must have exactly one default variant"},
+		{"Unknown message", "a = { b }\n", "unknown message \"b\""},
+		{"Unknown term", "a = { -b }\n", "unknown term \"-b\""},
+		{"Cyclic reference", "a = { b }\nb = { a }\n", "cyclic reference to \"a\""},
+		{"Junk", "hello = Hi\n!oops\n", "line 2: expected a message, term or comment, got '!'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFluentLoader().ParseMessages("zh-CN.ftl", []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseMessages error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFluentLoader(t *testing.T) {
+	data := "-brand = Firefox\n" +
+		"welcome = Welcome to { -brand }, { $name }!\n" +
+		"files = { $count ->\n    [one] { $count } файл\n    [few] { $count } файла\n   *[many] { $count } файлов\n}\n"
+
+	loader, ok := NewLoaderRegistry().GetLoaderForFile("ru.ftl")
+	if !ok || loader.Name() != "Fluent" {
+		t.Fatal("GetLoaderForFile(ru.ftl) should select the Fluent loader")
+	}
+
+	builder := catalog.NewBuilder()
+	if err := loader.LoadToBuilder("ru.ftl", []byte(data), builder, msg.Locale("ru")); err != nil {
+		t.Fatalf("LoadToBuilder error = %v", err)
+	}
+	printer, err := NewPrinter(msg.Locale("ru"), message.Catalog(builder))
+	if err != nil {
+		t.Fatalf("NewPrinter error = %v", err)
+	}
+
+	if got := msg.Sprintn(printer, "welcome", msg.Args{"name": "Anna"}); got != "Welcome to Firefox, Anna!" {
+		t.Errorf("Sprintn(welcome) = %q", got)
+	}
+	for n, want := range map[int]string{1: "1 файл", 3: "3 файла", 5: "5 файлов"} {
+		if got := msg.Sprintn(printer, "files", msg.Args{"count": n}); got != want {
+			t.Errorf("Sprintn(files, %d) = %q, want %q", n, got, want)
+		}
+	}
+}
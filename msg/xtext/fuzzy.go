@@ -0,0 +1,181 @@
+package xtext
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/language"
+)
+
+// DefaultFuzzyThreshold FuzzyMatch 的阈值超出 (0, 1] 时使用的相似度阈值
+const DefaultFuzzyThreshold = 0.8
+
+// FuzzyMatch 启用模糊匹配选项。
+//
+// 消息在整个回退链中都没有译文时，Printer 在同一回退链中查找标识最相似的消息，
+// 相似度（基于编辑距离，0 到 1）不低于 threshold 时使用它的译文，而不是输出原文，
+// 以便在迁移中重命名消息标识时平滑过渡。threshold 超出 (0, 1] 时使用 DefaultFuzzyThreshold。
+//
+// 模糊匹配的结果仍然会报告为缺失；Missing 选项设置的报告器实现了 msg.FuzzyReporter 时，
+// 还会通过 ReportFuzzy 标记这次输出来自模糊匹配。候选的消息来自已加载的本地翻译源、
+// 动态翻译源和 SetMessage 覆盖的消息，SetTranslation 和 Reset 回调写入的消息不参与匹配。
+//
+// 示例：
+//
+//	factory := xtext.NewPrinterFactory(
+//	    xtext.BaseDir("./locales"),
+//	    xtext.FuzzyMatch(0.85),
+//	)
+//	// 翻译文件中只有 "Welcome back, %s!"
+//	printer.Sprintf("Welcome back %s!", name) // 使用 "Welcome back, %s!" 的译文
+func FuzzyMatch(threshold float64) Option {
+	return func(o *options) {
+		if threshold <= 0 || threshold > 1 {
+			threshold = DefaultFuzzyThreshold
+		}
+		o.fuzzy = threshold
+	}
+}
+
+// fuzzyMatcher 在缺失消息时查找相似的消息，为 nil 时不启用模糊匹配
+type fuzzyMatcher struct {
+	threshold float64
+	build     func() map[language.Tag][]string // 返回每种语言可以匹配的消息标识
+	index     atomic.Pointer[fuzzyIndex]       // 翻译数据变化后置为 nil，下次匹配时重新构建
+}
+
+// fuzzyIndex 是某一时刻可以匹配的消息标识及匹配结果的缓存
+type fuzzyIndex struct {
+	ids     map[language.Tag][]string
+	matches sync.Map // locale + "\x00" + key → fuzzyResult
+}
+
+// fuzzyResult 是一次模糊匹配的结果，id 为空表示没有足够相似的消息
+type fuzzyResult struct {
+	id    string
+	score float64
+}
+
+// invalidate 在翻译数据变化后丢弃索引
+func (m *fuzzyMatcher) invalidate() {
+	if m != nil {
+		m.index.Store(nil)
+	}
+}
+
+// match 按回退链的顺序查找与 key 最相似的消息，返回第一个有匹配的语言中相似度最高的消息
+func (m *fuzzyMatcher) match(locale msg.Locale, chain []chainPrinter, key string) (fuzzyResult, bool) {
+	if m == nil {
+		return fuzzyResult{}, false
+	}
+	index := m.index.Load()
+	if index == nil {
+		index = &fuzzyIndex{ids: m.build()}
+		m.index.Store(index)
+	}
+
+	cacheKey := string(locale) + "\x00" + key
+	if r, ok := index.matches.Load(cacheKey); ok {
+		result := r.(fuzzyResult)
+		return result, result.id != ""
+	}
+
+	var result fuzzyResult
+	for _, c := range chain {
+		for _, id := range index.ids[c.tag] {
+			if score := similarity(key, id); score >= m.threshold && score > result.score {
+				result = fuzzyResult{id: id, score: score}
+			}
+		}
+		if result.id != "" {
+			break
+		}
+	}
+	index.matches.Store(cacheKey, result)
+	return result, result.id != ""
+}
+
+// similarity 返回 a 和 b 基于编辑距离的相似度，1 表示相同
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	n := max(len(ra), len(rb))
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(n)
+}
+
+// levenshtein 返回 a 和 b 的编辑距离
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		curr[0] = i + 1
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j+1]+1, curr[j]+1, prev[j]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// fuzzyCandidates 收集可以模糊匹配的消息标识，只包含已加载的本地翻译源
+func (f *PrinterFactory) fuzzyCandidates() map[language.Tag][]string {
+	f.mu.RLock()
+	sources := slices.Clone(f.sources)
+	dynamic := slices.Clone(f.dynamic)
+	f.mu.RUnlock()
+
+	seen := make(map[language.Tag]map[string]bool)
+	add := func(locale msg.Locale, messages []Message) {
+		tag, err := language.Parse(locale.String())
+		if err != nil {
+			return
+		}
+		if seen[tag] == nil {
+			seen[tag] = make(map[string]bool)
+		}
+		for _, m := range messages {
+			if m.Translation != "" {
+				seen[tag][m.ID] = true
+			}
+		}
+	}
+
+	for _, s := range sources {
+		if s.loaded.Load() == nil {
+			continue
+		}
+		messages, _ := s.parseMessages()
+		add(s.locale, messages)
+	}
+	for _, d := range dynamic {
+		for _, c := range d.catalogs() {
+			add(c.locale, c.messages)
+		}
+	}
+	for tag, ids := range f.overrides.snapshot() {
+		if seen[tag] == nil {
+			seen[tag] = make(map[string]bool)
+		}
+		for _, id := range ids {
+			seen[tag][id] = true
+		}
+	}
+
+	result := make(map[language.Tag][]string, len(seen))
+	for tag, ids := range seen {
+		result[tag] = slices.Sorted(maps.Keys(ids))
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package xtext
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"hello", "hello", 1},
+		{"hello", "", 0},
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"你好世界", "你好", 0.5},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// fuzzyRecorder 记录缺失和模糊匹配的报告
+type fuzzyRecorder struct {
+	mu      sync.Mutex
+	missing []string
+	fuzzy   []string
+}
+
+func (r *fuzzyRecorder) ReportMissing(locale msg.Locale, id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.missing = append(r.missing, id)
+}
+
+func (r *fuzzyRecorder) ReportFuzzy(locale msg.Locale, id, matched string, score float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fuzzy = append(r.fuzzy, id+" → "+matched)
+}
+
+func TestPrinterFactory_FuzzyMatch(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [{"id": "Sign in", "translation": "Sign in"}]}`)},
+		"zh.gotext.json": {Data: []byte(`{"language": "zh", "messages": [{"id": "Welcome back, %s!", "translation": "欢迎回来，%s！"}]}`)},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		factory := NewPrinterFactory(BaseFS(fsys))
+		printer, err := factory.CreatePrinter(msg.Chinese)
+		if err != nil {
+			t.Fatalf("CreatePrinter() error = %v", err)
+		}
+		if got := printer.Sprintf("Welcome back %s!", "Ann"); got != "Welcome back Ann!" {
+			t.Errorf("Sprintf() = %q, want original text", got)
+		}
+	})
+
+	recorder := &fuzzyRecorder{}
+	factory := NewPrinterFactory(BaseFS(fsys), Fallbacks(msg.English), Missing(recorder), FuzzyMatch(0.8))
+	printer, err := factory.CreatePrinter(msg.Chinese)
+	if err != nil {
+		t.Fatalf("CreatePrinter() error = %v", err)
+	}
+
+	if got := printer.Sprintf("Welcome back %s!", "Ann"); got != "欢迎回来，Ann！" {
+		t.Errorf("Sprintf() = %q, want %q", got, "欢迎回来，Ann！")
+	}
+	// 回退链中的英语也参与匹配
+	if got := printer.Sprintf("Sign-in"); got != "Sign in" {
+		t.Errorf("Sprintf(Sign-in) = %q, want %q", got, "Sign in")
+	}
+	if got := printer.Sprintf("Goodbye"); got != "Goodbye" {
+		t.Errorf("Sprintf(Goodbye) = %q, want original text", got)
+	}
+
+	// 覆盖的消息加入后重新构建索引
+	if err := factory.SetMessage(msg.Chinese, "Sign out now", "立即退出"); err != nil {
+		t.Fatalf("SetMessage() error = %v", err)
+	}
+	if got := printer.Sprintf("Sign out now!"); got != "立即退出" {
+		t.Errorf("Sprintf(Sign out now!) = %q, want %q", got, "立即退出")
+	}
+
+	wantMissing := []string{"Welcome back %s!", "Sign-in", "Goodbye", "Sign out now!"}
+	wantFuzzy := []string{"Welcome back %s! → Welcome back, %s!", "Sign-in → Sign in", "Sign out now! → Sign out now"}
+	if !slices.Equal(recorder.missing, wantMissing) {
+		t.Errorf("missing = %q, want %q", recorder.missing, wantMissing)
+	}
+	if !slices.Equal(recorder.fuzzy, wantFuzzy) {
+		t.Errorf("fuzzy = %q, want %q", recorder.fuzzy, wantFuzzy)
+	}
+}
+
+func TestFuzzyMatch_Threshold(t *testing.T) {
+	for _, threshold := range []float64{0, -1, 1.5} {
+		var o options
+		FuzzyMatch(threshold)(&o)
+		if o.fuzzy != DefaultFuzzyThreshold {
+			t.Errorf("FuzzyMatch(%v) threshold = %v, want %v", threshold, o.fuzzy, DefaultFuzzyThreshold)
+		}
+	}
+}
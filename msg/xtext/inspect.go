@@ -0,0 +1,105 @@
+package xtext
+
+import (
+	"fmt"
+	"slices"
+
+	"go-slim.dev/infra/msg"
+)
+
+// MessageInfo 是翻译目录中的一条消息及其来源，用于向译者展示消息的上下文。
+type MessageInfo struct {
+	Message
+	Locale msg.Locale // 翻译源的语言
+	File   string     // 翻译文件路径或远程、数据库翻译源的名称，内存中的翻译为空
+}
+
+// String 返回如 `zh-CN.gotext.json: "hello" (main.go:12:6)` 的描述
+func (m MessageInfo) String() string {
+	s := fmt.Sprintf("%s: %q", m.File, m.ID)
+	if m.Position != "" {
+		s += " (" + m.Position + ")"
+	}
+	return s
+}
+
+// CatalogInspector 是可以枚举翻译目录中的消息及其元数据的 PrinterFactory（可选接口），
+// 供翻译管理界面、编辑器插件等工具展示给译者的说明、源代码位置和待校对标记。
+type CatalogInspector interface {
+	// InspectMessages 返回一种语言的所有消息，按查找时的优先级从低到高排列，
+	// 同一消息在多个文件中定义时每个定义都会返回
+	InspectMessages(locale msg.Locale) []MessageInfo
+
+	// InspectMessage 返回一种语言中查找时使用的消息定义，没有该消息时返回 false
+	InspectMessage(locale msg.Locale, id string) (MessageInfo, bool)
+}
+
+// 确保 PrinterFactory 实现了 CatalogInspector 接口
+var _ CatalogInspector = (*PrinterFactory)(nil)
+
+// InspectMessages 实现 CatalogInspector 接口。
+//
+// 与 Coverage 一样重新解析翻译源的文件（包括尚未加载的语言）和远程、数据库翻译源的当前快照：
+// 先是本地文件中的消息，按文件的加载顺序排列，文件之间的冲突按 Conflicts 策略处理，
+// 然后是优先于本地文件的远程、数据库翻译源中的消息。只包含语言与 locale 完全相同的翻译源，
+// 加载器未实现 MessageParser 的文件不包含在内，解析失败的文件记录日志后忽略。
+//
+// 示例：
+//
+//	for _, m := range factory.InspectMessages(msg.Locale("zh-CN")) {
+//	    fmt.Printf("%s\n  %s\n  note: %s\n  fuzzy: %v\n", m, m.Translation, m.Note, m.Fuzzy)
+//	}
+func (f *PrinterFactory) InspectMessages(locale msg.Locale) []MessageInfo {
+	f.mu.RLock()
+	sources := slices.Clone(f.sources)
+	dynamic := slices.Clone(f.dynamic)
+	f.mu.RUnlock()
+
+	var infos []MessageInfo
+	for _, s := range sources {
+		if !s.locale.Equal(locale) {
+			continue
+		}
+		f.configure(s)
+		messages, err := s.parseMessageInfos()
+		if err != nil {
+			f.logFunc(fmt.Sprintf("Error parsing translations for %s: %v", s.locale, err))
+		}
+		infos = append(infos, messages...)
+	}
+	for _, d := range dynamic {
+		for _, c := range d.catalogs() {
+			if !c.locale.Equal(locale) {
+				continue
+			}
+			if c.err != nil {
+				f.logFunc(fmt.Sprintf("Error parsing translation %s: %v", c.name, c.err))
+			}
+			for _, m := range c.messages {
+				infos = append(infos, MessageInfo{Message: m, Locale: c.locale, File: c.name})
+			}
+		}
+	}
+	return infos
+}
+
+// InspectMessage 实现 CatalogInspector 接口。
+//
+// 返回 InspectMessages 中该消息最后一个有译文的定义，即查找时使用的译文；
+// 消息在所有定义中都没有译文时返回最后一个定义。
+//
+// 示例：
+//
+//	if m, ok := factory.InspectMessage(msg.Locale("zh-CN"), "%d files"); ok {
+//	    fmt.Println(m.File, m.Position, m.Note)
+//	}
+func (f *PrinterFactory) InspectMessage(locale msg.Locale, id string) (MessageInfo, bool) {
+	var found MessageInfo
+	var ok bool
+	for _, m := range f.InspectMessages(locale) {
+		if m.ID == id && (!ok || found.Translation == "" || m.Translation != "") {
+			found, ok = m, true
+		}
+	}
+	return found, ok
+}
@@ -0,0 +1,54 @@
+package xtext
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestPrinterFactory_InspectMessages(t *testing.T) {
+	factory := NewPrinterFactory(BaseFS(fstest.MapFS{
+		"zh/a.gotext.json": {Data: []byte(`{"language": "zh", "messages": [
+			{"id": "%d files", "message": "%d files", "translation": "%d 个文件", "comment": "File count in the toolbar", "position": "ui/toolbar.go:42:13"},
+			{"id": "Open", "message": "Open", "translation": "打开", "translatorComment": "动词", "fuzzy": true}
+		]}`)},
+		"zh/b.gotext.yaml": {Data: []byte("language: zh\nmessages:\n  - id: Open\n    message: Open\n    position: ui/menu.go:7:2\n")},
+		"en.gotext.json":   {Data: []byte(`{"language": "en", "messages": [{"id": "Open", "translation": "Open"}]}`)},
+	}))
+	factory.SetTranslation(msg.Chinese, "Close", "关闭")
+
+	infos := factory.InspectMessages(msg.Chinese)
+	if len(infos) != 3 {
+		t.Fatalf("InspectMessages(zh) = %v, want 3 messages", infos)
+	}
+	want := MessageInfo{
+		Message: Message{
+			ID:          "%d files",
+			Source:      "%d files",
+			Translation: "%d 个文件",
+			Note:        "File count in the toolbar",
+			Position:    "ui/toolbar.go:42:13",
+		},
+		Locale: msg.Chinese,
+		File:   "zh/a.gotext.json",
+	}
+	if infos[0] != want {
+		t.Errorf("InspectMessages(zh)[0] = %+v, want %+v", infos[0], want)
+	}
+	if got := infos[0].String(); got != `zh/a.gotext.json: "%d files" (ui/toolbar.go:42:13)` {
+		t.Errorf("String() = %q", got)
+	}
+
+	// 未翻译的定义不会替代有译文的定义
+	open, ok := factory.InspectMessage(msg.Chinese, "Open")
+	if !ok || open.File != "zh/a.gotext.json" || open.TranslatorNote != "动词" || !open.Fuzzy {
+		t.Errorf("InspectMessage(zh, Open) = %+v, %v", open, ok)
+	}
+	if _, ok := factory.InspectMessage(msg.Chinese, "Close"); ok {
+		t.Error("InspectMessage(zh, Close) should not include SetTranslation overrides")
+	}
+	if infos := factory.InspectMessages(msg.Japanese); len(infos) != 0 {
+		t.Errorf("InspectMessages(ja) = %v, want none", infos)
+	}
+}
@@ -0,0 +1,188 @@
+package xtext
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go-slim.dev/infra/msg"
+)
+
+// PathResolver 决定翻译目录中的文件属于哪种语言，用于自定义翻译目录的命名规则。
+//
+// 设置了 PathResolver 时，工厂遍历翻译目录及其所有子目录中加载器支持的文件，
+// 对每个文件调用 ResolveLocale，属于同一语言的文件按路径顺序组成一个翻译源，同名消息以后面的文件为准。
+// 未设置时使用默认的规则：根目录中的 {locale}.gotext.json 等文件，以及一级子目录 {locale}/ 中的所有文件。
+type PathResolver interface {
+	// ResolveLocale 返回相对于翻译目录的路径为 name（以 / 分隔）的文件的语言，不是翻译文件时返回 false
+	ResolveLocale(name string) (msg.Locale, bool)
+}
+
+// PathResolverFunc 将函数适配为 PathResolver
+type PathResolverFunc func(name string) (msg.Locale, bool)
+
+// ResolveLocale 实现 PathResolver 接口
+func (f PathResolverFunc) ResolveLocale(name string) (msg.Locale, bool) {
+	return f(name)
+}
+
+// pathPatterns 是 PathPatterns 返回的 PathResolver
+type pathPatterns []*regexp.Regexp
+
+// PathPatterns 返回按路径模式确定文件语言的 PathResolver，文件匹配第一个模式时使用该模式中的语言。
+//
+// 模式使用 / 分隔，相对于翻译目录，支持以下占位符：
+//   - {locale}：语言，如 zh-CN、zh_CN，每个模式必须包含且只能包含一个
+//   - {name}：其他花括号中的名称（如 {domain}）匹配一段不含 / 的非空文本
+//   - *：匹配一段不含 / 的文本
+//   - **/：匹配任意层（包括零层）目录
+//
+// 模式无效时 panic，与 regexp.MustCompile 一样适合在程序初始化时使用。
+//
+// 示例：
+//
+//	factory := xtext.NewPrinterFactory(
+//	    xtext.Layout(xtext.PathPatterns(
+//	        "messages.{locale}.gotext.json",   // messages.zh-CN.gotext.json
+//	        "{locale}/LC_MESSAGES/*.po",       // zh_CN/LC_MESSAGES/app.po，需要注册 .po 加载器
+//	        "{domain}/{locale}.gotext.yaml",   // admin/zh-CN.gotext.yaml
+//	    )),
+//	    xtext.BaseDir("./locales"),
+//	)
+func PathPatterns(patterns ...string) PathResolver {
+	p := make(pathPatterns, 0, len(patterns))
+	for _, pattern := range patterns {
+		p = append(p, compilePathPattern(pattern))
+	}
+	return p
+}
+
+// ResolveLocale 实现 PathResolver 接口
+func (p pathPatterns) ResolveLocale(name string) (msg.Locale, bool) {
+	for _, re := range p {
+		m := re.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		if locale, ok := parseBaseLocale(m[1]); ok {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// compilePathPattern 将路径模式转换为正则表达式，第一个分组为语言
+func compilePathPattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	locales := 0
+	for i := 0; i < len(pattern); {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:[^/]+/)*")
+			i += 3
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+			i++
+		case pattern[i] == '{':
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 2 {
+				panic(fmt.Sprintf("xtext: invalid placeholder in path pattern %q", pattern))
+			}
+			if pattern[i+1:i+end] == "locale" {
+				b.WriteString("([A-Za-z0-9_-]+)")
+				locales++
+			} else {
+				b.WriteString("(?:[^/]+)")
+			}
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			i++
+		}
+	}
+	b.WriteString("$")
+	if locales != 1 {
+		panic(fmt.Sprintf("xtext: path pattern %q must contain exactly one {locale}", pattern))
+	}
+	return regexp.MustCompile(b.String())
+}
+
+// Layout 设置翻译目录命名规则的选项，Reset、ResetFS、BaseDir 和 BaseFS 都使用该规则查找翻译文件。
+//
+// 参数 r: 确定文件语言的 PathResolver，为 nil 时使用默认的规则
+// 返回: 可用于 NewPrinterFactory 的选项
+//
+// 示例：
+//
+//	// locales/zh-CN/LC_MESSAGES/*.gotext.json
+//	factory := xtext.NewPrinterFactory(
+//	    xtext.Layout(xtext.PathPatterns("{locale}/LC_MESSAGES/*")),
+//	    xtext.BaseDir("./locales"),
+//	)
+func Layout(r PathResolver) Option {
+	return func(o *options) {
+		o.resolver = r
+	}
+}
+
+// resolveSources 遍历目录及其子目录，使用 resolver 确定每个文件的语言并创建翻译源，fsys 为 nil 时遍历磁盘目录
+func (f *PrinterFactory) resolveSources(fsys fs.FS, baseDir string) []*Source {
+	files := make(map[msg.Locale][]Entry)
+	var locales []msg.Locale
+
+	visit := func(name, fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read %s: %v\n", fullPath, err)
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		loader, ok := f.loaders.GetLoaderForFile(fullPath)
+		if !ok {
+			return nil
+		}
+		locale, ok := f.resolver.ResolveLocale(name)
+		if !ok {
+			return nil
+		}
+		if _, exists := files[locale]; !exists {
+			locales = append(locales, locale)
+		}
+		files[locale] = append(files[locale], Entry{file: fullPath, loader: loader, fsys: fsys})
+		return nil
+	}
+
+	var err error
+	if fsys == nil {
+		err = filepath.WalkDir(baseDir, func(p string, d fs.DirEntry, err error) error {
+			rel, relErr := filepath.Rel(baseDir, p)
+			if relErr != nil {
+				return relErr
+			}
+			return visit(filepath.ToSlash(rel), p, d, err)
+		})
+	} else {
+		err = fs.WalkDir(fsys, baseDir, func(p string, d fs.DirEntry, err error) error {
+			name := p
+			if baseDir != "." {
+				name = strings.TrimPrefix(p, baseDir+"/")
+			}
+			return visit(name, p, d, err)
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read base directory %s: %v\n", baseDir, err)
+	}
+
+	sources := make([]*Source, 0, len(locales))
+	for _, locale := range locales {
+		sources = append(sources, NewSource(locale, files[locale]))
+	}
+	sortSources(sources)
+	return sources
+}
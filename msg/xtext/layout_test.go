@@ -0,0 +1,91 @@
+package xtext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestPathPatterns(t *testing.T) {
+	r := PathPatterns("messages.{locale}.gotext.json", "{locale}/LC_MESSAGES/*.po", "{domain}/{locale}.gotext.yaml", "**/i18n/{locale}.csv")
+
+	tests := []struct {
+		name string
+		want msg.Locale
+		ok   bool
+	}{
+		{"messages.zh-CN.gotext.json", "zh-CN", true},
+		{"messages.en.gotext.json", "en", true},
+		{"zh_CN/LC_MESSAGES/app.po", "zh-CN", true},
+		{"admin/ja.gotext.yaml", "ja", true},
+		{"i18n/fr.csv", "fr", true},
+		{"web/shop/i18n/de.csv", "de", true},
+		{"messages.gotext.json", "", false},
+		{"zh-CN/app.po", "", false},
+		{"admin/sub/ja.gotext.yaml", "", false},
+		{"messages.invalid@locale.gotext.json", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := r.ResolveLocale(tt.name)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ResolveLocale(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	for _, pattern := range []string{"messages.json", "{locale}/{locale}.json", "{.json"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("PathPatterns(%q) should panic", pattern)
+				}
+			}()
+			PathPatterns(pattern)
+		}()
+	}
+}
+
+func TestLayout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/zh-CN.gotext.json": {Data: []byte(`{"language": "zh-CN", "messages": [{"id": "hello", "translation": "你好"}]}`)},
+		"admin/zh-CN.gotext.json":  {Data: []byte(`{"language": "zh-CN", "messages": [{"id": "users", "translation": "用户"}]}`)},
+		"common/en.gotext.json":    {Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}]}`)},
+		"zh-CN.gotext.json":        {Data: []byte(`{"language": "zh-CN", "messages": [{"id": "hello", "translation": "不匹配"}]}`)},
+	}
+	factory := NewPrinterFactory(Layout(PathPatterns("{domain}/{locale}.gotext.json")), BaseFS(fsys))
+
+	if got := factory.SupportedLocales(); len(got) != 2 || !got.Contains(msg.English) || !got.Contains(msg.Locale("zh-CN")) {
+		t.Errorf("SupportedLocales() = %v, want [en zh-CN]", got)
+	}
+	printer, err := factory.CreatePrinter(msg.Locale("zh-CN"))
+	if err != nil {
+		t.Fatalf("CreatePrinter(zh-CN) error = %v", err)
+	}
+	for key, want := range map[string]string{"hello": "你好", "users": "用户"} {
+		if got := printer.Sprintf(key); got != want {
+			t.Errorf("Sprintf(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	t.Run("Directory", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "ja", "LC_MESSAGES", "app.gotext.json")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(`{"language": "ja", "messages": [{"id": "hello", "translation": "こんにちは"}]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		factory := NewPrinterFactory(Layout(PathPatterns("{locale}/LC_MESSAGES/*")), BaseDir(dir))
+		printer, err := factory.CreatePrinter(msg.Japanese)
+		if err != nil {
+			t.Fatalf("CreatePrinter(ja) error = %v", err)
+		}
+		if got := printer.Sprintf("hello"); got != "こんにちは" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "こんにちは")
+		}
+	})
+}
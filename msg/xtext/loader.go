@@ -1,8 +1,12 @@
 // Package xtext 提供了翻译文件加载器的实现。
 //
-// 本包支持 gotext 标准的 JSON 格式翻译文件：
+// 本包支持 gotext 标准的 JSON 格式翻译文件，以及相同消息结构的 YAML 和 TOML 文件：
 // - .gotext.json: 标准的 JSON 格式翻译文件
 // - .gotext.jsonc: 支持注释的 JSON 格式文件
+// - .gotext.yaml/.gotext.yml: YAML 格式文件
+// - .gotext.toml: TOML 格式文件
+//
+// 此外还支持 XLIFF（.xlf/.xliff）、电子表格导出的 CSV（.csv/.tsv）和 Mozilla Fluent（.ftl）文件。
 //
 // 设计特点：
 // 1. 专注于 gotext 格式：严格支持 gotext 语言包格式
@@ -12,16 +16,19 @@
 package xtext
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"sync"
 
+	"github.com/BurntSushi/toml"
 	"github.com/tidwall/jsonc"
 	"go-slim.dev/infra/msg"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message/catalog"
+	"gopkg.in/yaml.v3"
 )
 
 // Loader 定义翻译文件加载器接口。
@@ -51,6 +58,16 @@ type Loader interface {
 	LoadToBuilder(filename string, data []byte, builder *catalog.Builder, locale msg.Locale) error
 }
 
+// MessageParser 是可以将翻译文件解析为消息列表的加载器（可选接口）。
+//
+// catalog.Builder 无法枚举已加载的消息，翻译覆盖率等需要枚举消息的功能
+// 通过此接口重新解析翻译文件，未实现此接口的加载器加载的文件会被忽略。
+// 内置的 JSON、YAML、TOML、XLIFF、CSV 和 Fluent 加载器都实现了此接口。
+type MessageParser interface {
+	// ParseMessages 解析翻译文件中的所有消息，包括未翻译的消息
+	ParseMessages(filename string, data []byte) ([]Message, error)
+}
+
 // JSONLoader 实现 gotext JSON 格式的加载器。
 //
 // 支持的文件格式：
@@ -94,20 +111,24 @@ func (l *JSONLoader) Extensions() []string {
 
 // CanLoad 检查是否可以加载指定文件。
 func (l *JSONLoader) CanLoad(filename string) bool {
-	lower := strings.ToLower(filename)
-	for _, ext := range l.extensions {
-		if strings.HasSuffix(lower, ext) {
-			return true
-		}
-	}
-	return false
+	return hasExtension(filename, l.extensions)
 }
 
 // LoadToBuilder 加载 gotext JSON 格式的翻译文件并写入到指定的 builder 中。
 func (l *JSONLoader) LoadToBuilder(filename string, data []byte, builder *catalog.Builder, locale msg.Locale) error {
+	messages, err := l.ParseMessages(filename, data)
+	if err != nil {
+		return err
+	}
+	loadMessages(builder, locale, messages)
+	return nil
+}
+
+// ParseMessages 实现 MessageParser 接口。
+func (l *JSONLoader) ParseMessages(filename string, data []byte) ([]Message, error) {
 	// 处理空文件
 	if len(data) == 0 {
-		return nil // 空文件是有效的，只是没有翻译内容
+		return nil, nil // 空文件是有效的，只是没有翻译内容
 	}
 
 	// 如果是 JSONC 格式，先转换为纯 JSON
@@ -121,9 +142,57 @@ func (l *JSONLoader) LoadToBuilder(filename string, data []byte, builder *catalo
 	// 解析 JSON 数据
 	var content map[string]any
 	if err := json.Unmarshal(jsonData, &content); err != nil {
-		return fmt.Errorf("failed to parse JSON translation file %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to parse JSON translation file %s: %w", filename, err)
+	}
+
+	return gotextMessages(filename, content)
+}
+
+// gotextMessages 从解析后的 gotext 格式内容中读取消息，JSON、YAML 和 TOML 加载器共用。
+//
+// 格式：{"language": "zh-CN", "messages": [{"id": "key", "message": "source", "translation": "target"}]}
+// 除 id、message 和 translation 外，还读取 comment、translatorComment、meaning、position 和 fuzzy 字段；
+// 非字符串的 translation（如 gotext 的复数选择）视为未翻译。
+func gotextMessages(filename string, content map[string]any) ([]Message, error) {
+	// 处理 gotext 标准格式，TOML 的表数组解码为 []map[string]any
+	var items []any
+	switch v := content["messages"].(type) {
+	case []any:
+		items = v
+	case []map[string]any:
+		for _, m := range v {
+			items = append(items, m)
+		}
+	default:
+		return nil, fmt.Errorf("invalid gotext format: missing 'messages' array in file %s", filename)
+	}
+
+	messages := make([]Message, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, ok := fields["id"].(string)
+		if !ok {
+			continue
+		}
+
+		m := Message{ID: id}
+		m.Source, _ = fields["message"].(string)
+		m.Translation, _ = fields["translation"].(string)
+		m.Note, _ = fields["comment"].(string)
+		m.TranslatorNote, _ = fields["translatorComment"].(string)
+		m.Context, _ = fields["meaning"].(string)
+		m.Position, _ = fields["position"].(string)
+		m.Fuzzy, _ = fields["fuzzy"].(bool)
+		messages = append(messages, m)
 	}
+	return messages, nil
+}
 
+// loadMessages 将有译文的消息写入 builder
+func loadMessages(builder *catalog.Builder, locale msg.Locale, messages []Message) {
 	// 解析语言标签
 	tag, err := language.Parse(string(locale))
 	if err != nil {
@@ -131,31 +200,154 @@ func (l *JSONLoader) LoadToBuilder(filename string, data []byte, builder *catalo
 		tag = language.English
 	}
 
-	// 处理 gotext 标准格式
-	// 格式：{"language": "zh-CN", "messages": [{"id": "key", "message": "source", "translation": "target"}]}
-	messages, ok := content["messages"].([]any)
-	if !ok {
-		return fmt.Errorf("invalid gotext format: missing 'messages' array in file %s", filename)
+	for _, m := range messages {
+		if m.Translation != "" {
+			builder.SetString(tag, m.ID, m.Translation)
+		}
 	}
+}
 
-	// 遍历 messages 数组
-	for _, msg := range messages {
-		msgMap, ok := msg.(map[string]any)
-		if !ok {
-			continue
-		}
+// YAMLLoader 实现 YAML 格式的加载器，消息结构与 gotext JSON 格式相同。
+//
+// 支持的文件格式：
+// - .gotext.yaml
+// - .gotext.yml
+//
+// 示例：
+//
+//	language: zh-CN
+//	messages:
+//	  - id: Hello
+//	    message: Hello
+//	    translation: 你好
+type YAMLLoader struct {
+	name       string
+	extensions []string
+}
 
-		id, hasID := msgMap["id"].(string)
-		translation, hasTranslation := msgMap["translation"].(string)
+// NewYAMLLoader 创建新的 YAML 加载器。
+func NewYAMLLoader() *YAMLLoader {
+	return &YAMLLoader{
+		name:       "YAML",
+		extensions: []string{".gotext.yaml", ".gotext.yml"},
+	}
+}
 
-		if hasID && hasTranslation && translation != "" {
-			builder.SetString(tag, id, translation)
-		}
+// Name 返回加载器名称。
+func (l *YAMLLoader) Name() string {
+	return l.name
+}
+
+// Extensions 返回支持的文件扩展名列表。
+func (l *YAMLLoader) Extensions() []string {
+	return l.extensions
+}
+
+// CanLoad 检查是否可以加载指定文件。
+func (l *YAMLLoader) CanLoad(filename string) bool {
+	return hasExtension(filename, l.extensions)
+}
+
+// LoadToBuilder 加载 YAML 格式的翻译文件并写入到指定的 builder 中。
+func (l *YAMLLoader) LoadToBuilder(filename string, data []byte, builder *catalog.Builder, locale msg.Locale) error {
+	messages, err := l.ParseMessages(filename, data)
+	if err != nil {
+		return err
+	}
+	loadMessages(builder, locale, messages)
+	return nil
+}
+
+// ParseMessages 实现 MessageParser 接口。
+func (l *YAMLLoader) ParseMessages(filename string, data []byte) ([]Message, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var content map[string]any
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML translation file %s: %w", filename, err)
 	}
 
+	return gotextMessages(filename, content)
+}
+
+// TOMLLoader 实现 TOML 格式的加载器，消息结构与 gotext JSON 格式相同。
+//
+// 支持的文件格式：
+// - .gotext.toml
+//
+// 示例：
+//
+//	language = "zh-CN"
+//
+//	[[messages]]
+//	id = "Hello"
+//	message = "Hello"
+//	translation = "你好"
+type TOMLLoader struct {
+	name       string
+	extensions []string
+}
+
+// NewTOMLLoader 创建新的 TOML 加载器。
+func NewTOMLLoader() *TOMLLoader {
+	return &TOMLLoader{
+		name:       "TOML",
+		extensions: []string{".gotext.toml"},
+	}
+}
+
+// Name 返回加载器名称。
+func (l *TOMLLoader) Name() string {
+	return l.name
+}
+
+// Extensions 返回支持的文件扩展名列表。
+func (l *TOMLLoader) Extensions() []string {
+	return l.extensions
+}
+
+// CanLoad 检查是否可以加载指定文件。
+func (l *TOMLLoader) CanLoad(filename string) bool {
+	return hasExtension(filename, l.extensions)
+}
+
+// LoadToBuilder 加载 TOML 格式的翻译文件并写入到指定的 builder 中。
+func (l *TOMLLoader) LoadToBuilder(filename string, data []byte, builder *catalog.Builder, locale msg.Locale) error {
+	messages, err := l.ParseMessages(filename, data)
+	if err != nil {
+		return err
+	}
+	loadMessages(builder, locale, messages)
 	return nil
 }
 
+// ParseMessages 实现 MessageParser 接口。
+func (l *TOMLLoader) ParseMessages(filename string, data []byte) ([]Message, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var content map[string]any
+	if err := toml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML translation file %s: %w", filename, err)
+	}
+
+	return gotextMessages(filename, content)
+}
+
+// hasExtension 检查文件名是否以其中一个扩展名结尾（不区分大小写）
+func hasExtension(filename string, extensions []string) bool {
+	lower := strings.ToLower(filename)
+	for _, ext := range extensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
 // LoaderRegistry 加载器注册表，管理所有可用的加载器
 type LoaderRegistry struct {
 	loaders map[string]Loader // 按名称索引的加载器
@@ -171,8 +363,19 @@ func NewLoaderRegistry() *LoaderRegistry {
 		extMap:  make(map[string]Loader),
 	}
 
-	// 注册默认加载器（JSONLoader 支持 .gotext.json 和 .gotext.jsonc）
+	// 注册默认加载器，按扩展名自动选择：
+	// - JSONLoader: .gotext.json 和 .gotext.jsonc
+	// - YAMLLoader: .gotext.yaml 和 .gotext.yml
+	// - TOMLLoader: .gotext.toml
+	// - XLIFFLoader: .xlf 和 .xliff
+	// - CSVLoader: .csv 和 .tsv
+	// - FluentLoader: .ftl
 	registry.Register(NewJSONLoader())
+	registry.Register(NewYAMLLoader())
+	registry.Register(NewTOMLLoader())
+	registry.Register(NewXLIFFLoader())
+	registry.Register(NewCSVLoader())
+	registry.Register(NewFluentLoader())
 
 	return registry
 }
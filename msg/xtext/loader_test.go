@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"go-slim.dev/infra/msg"
+	"golang.org/x/text/message"
 	"golang.org/x/text/message/catalog"
 )
 
@@ -177,3 +178,64 @@ func TestJSONLoaderLoadToBuilder_EmptyTranslation(t *testing.T) {
 
 	// TODO: Verify that only "Goodbye" was loaded
 }
+
+func TestYAMLAndTOMLLoaders(t *testing.T) {
+	tests := []struct {
+		loader   Loader
+		filename string
+		data     string
+	}{
+		{NewYAMLLoader(), "zh-CN.gotext.yaml", `language: zh-CN
+messages:
+  - id: Hello
+    message: Hello
+    translation: 你好
+  - id: Untranslated
+    message: Untranslated
+`},
+		{NewYAMLLoader(), "zh-CN.gotext.yml", `{"language": "zh-CN", "messages": [{"id": "Hello", "translation": "你好"}]}`},
+		{NewTOMLLoader(), "zh-CN.gotext.toml", `language = "zh-CN"
+
+[[messages]]
+id = "Hello"
+message = "Hello"
+translation = "你好"
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if !tt.loader.CanLoad(tt.filename) {
+				t.Fatalf("%s.CanLoad(%q) = false", tt.loader.Name(), tt.filename)
+			}
+			if loader, ok := NewLoaderRegistry().GetLoaderForFile(tt.filename); !ok || loader.Name() != tt.loader.Name() {
+				t.Errorf("GetLoaderForFile(%q) did not select %s", tt.filename, tt.loader.Name())
+			}
+
+			builder := catalog.NewBuilder()
+			if err := tt.loader.LoadToBuilder(tt.filename, []byte(tt.data), builder, msg.Locale("zh-CN")); err != nil {
+				t.Fatalf("LoadToBuilder error = %v", err)
+			}
+			printer, err := NewPrinter(msg.Locale("zh-CN"), message.Catalog(builder))
+			if err != nil {
+				t.Fatalf("NewPrinter error = %v", err)
+			}
+			if got := printer.Sprintf("Hello"); got != "你好" {
+				t.Errorf("Sprintf(Hello) = %q, want %q", got, "你好")
+			}
+		})
+	}
+
+	t.Run("Invalid content", func(t *testing.T) {
+		builder := catalog.NewBuilder()
+		if err := NewYAMLLoader().LoadToBuilder("a.gotext.yaml", []byte("messages: [unclosed"), builder, msg.Locale("en")); err == nil {
+			t.Error("YAMLLoader should return error for malformed YAML")
+		}
+		if err := NewTOMLLoader().LoadToBuilder("a.gotext.toml", []byte("messages = 1"), builder, msg.Locale("en")); err == nil {
+			t.Error("TOMLLoader should return error for missing messages array")
+		}
+		if err := NewTOMLLoader().LoadToBuilder("a.gotext.toml", nil, builder, msg.Locale("en")); err != nil {
+			t.Errorf("TOMLLoader should accept empty file, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package xtext
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// 确保 PrinterFactory 实现了 msg.MessageSetter 接口
+var _ msg.MessageSetter = (*PrinterFactory)(nil)
+
+// overrides 运行时覆盖的消息。
+//
+// catalog.Builder 不支持删除消息，因此每次变化都重新构建覆盖的 catalog 并原子替换，
+// Printer 每次查找时读取最新的 catalog，覆盖的消息对已创建的 Printer 立即生效。
+type overrides struct {
+	mu       sync.Mutex
+	messages map[language.Tag]map[string]string
+	catalog  atomic.Pointer[catalog.Builder] // 没有覆盖的消息时为 nil
+}
+
+func (o *overrides) set(tag language.Tag, id, text string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.messages == nil {
+		o.messages = make(map[language.Tag]map[string]string)
+	}
+	if o.messages[tag] == nil {
+		o.messages[tag] = make(map[string]string)
+	}
+	o.messages[tag][id] = text
+	o.rebuild()
+}
+
+func (o *overrides) remove(tag language.Tag, id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.messages[tag], id)
+	if len(o.messages[tag]) == 0 {
+		delete(o.messages, tag)
+	}
+	o.rebuild()
+}
+
+// rebuild 重新构建覆盖的 catalog，调用者需要持有锁
+func (o *overrides) rebuild() {
+	if len(o.messages) == 0 {
+		o.catalog.Store(nil)
+		return
+	}
+	b := catalog.NewBuilder()
+	for tag, messages := range o.messages {
+		for id, text := range messages {
+			b.SetString(tag, id, text)
+		}
+	}
+	o.catalog.Store(b)
+}
+
+// snapshot 返回每种语言覆盖的消息标识
+func (o *overrides) snapshot() map[language.Tag][]string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	result := make(map[language.Tag][]string, len(o.messages))
+	for tag, messages := range o.messages {
+		result[tag] = slices.Collect(maps.Keys(messages))
+	}
+	return result
+}
+
+// SetMessage 实现 msg.MessageSetter 接口。
+//
+// 覆盖的消息在回退链的同一语言中优先于翻译文件和 SetTranslation 设置的译文，
+// 对已创建的 Printer 立即生效，并且在 Reset 后仍然保留。
+//
+// 示例：
+//
+//	// 紧急修正文案，无需重新部署
+//	factory.SetMessage(msg.Locale("zh-CN"), "Checkout", "去结算")
+func (f *PrinterFactory) SetMessage(locale msg.Locale, id, text string) error {
+	tag, err := language.All.Parse(locale.String())
+	if err != nil {
+		return err
+	}
+
+	f.overrides.set(tag, id, text)
+	f.fuzzy.invalidate()
+	return nil
+}
+
+// RemoveMessage 实现 msg.MessageSetter 接口，移除 SetMessage 设置的消息。
+func (f *PrinterFactory) RemoveMessage(locale msg.Locale, id string) error {
+	tag, err := language.All.Parse(locale.String())
+	if err != nil {
+		return err
+	}
+
+	f.overrides.remove(tag, id)
+	f.fuzzy.invalidate()
+	return nil
+}
@@ -0,0 +1,63 @@
+package xtext
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestPrinterFactory_SetMessage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"zh-CN.gotext.json": {Data: []byte(`{"language": "zh-CN", "messages": [{"id": "checkout", "translation": "结账"}]}`)},
+	}
+	factory := NewPrinterFactory(BaseFS(fsys))
+
+	printer, err := factory.CreatePrinter(msg.Locale("zh-CN"))
+	if err != nil {
+		t.Fatalf("CreatePrinter() error = %v", err)
+	}
+	if got := printer.Sprintf("checkout"); got != "结账" {
+		t.Fatalf("Sprintf() = %q, want %q", got, "结账")
+	}
+
+	// 覆盖的消息优先于翻译文件，对已创建的 Printer 立即生效
+	if err := factory.SetMessage(msg.Locale("zh-CN"), "checkout", "去结算"); err != nil {
+		t.Fatalf("SetMessage() error = %v", err)
+	}
+	if err := factory.SetMessage(msg.Locale("zh-CN"), "%d items", "%d 件商品"); err != nil {
+		t.Fatalf("SetMessage() error = %v", err)
+	}
+	if got := printer.Sprintf("checkout"); got != "去结算" {
+		t.Errorf("Sprintf() after SetMessage = %q, want %q", got, "去结算")
+	}
+	if got := printer.Sprintf("%d items", 3); got != "3 件商品" {
+		t.Errorf("Sprintf(%%d items) = %q, want %q", got, "3 件商品")
+	}
+
+	// Reset 后仍然保留
+	factory.ResetFS(fsys)
+	printer, _ = factory.CreatePrinter(msg.Locale("zh-CN"))
+	if got := printer.Sprintf("checkout"); got != "去结算" {
+		t.Errorf("Sprintf() after Reset = %q, want %q", got, "去结算")
+	}
+
+	if err := factory.RemoveMessage(msg.Locale("zh-CN"), "checkout"); err != nil {
+		t.Fatalf("RemoveMessage() error = %v", err)
+	}
+	if got := printer.Sprintf("checkout"); got != "结账" {
+		t.Errorf("Sprintf() after RemoveMessage = %q, want %q", got, "结账")
+	}
+
+	if err := factory.SetMessage(msg.Locale("not_a_locale!"), "checkout", "x"); err == nil {
+		t.Error("SetMessage() with invalid locale should return error")
+	}
+
+	manager := msg.NewManager(msg.ManagerConfig{Factory: factory})
+	if err := manager.SetMessage(msg.Locale("zh-CN"), "checkout", "买单"); err != nil {
+		t.Fatalf("Manager.SetMessage() error = %v", err)
+	}
+	if got := manager.GetPrinter(msg.Locale("zh-CN")).Sprintf("checkout"); got != "买单" {
+		t.Errorf("Manager printer Sprintf() = %q, want %q", got, "买单")
+	}
+}
@@ -1,12 +1,20 @@
 package xtext
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"strconv"
+	"strings"
 
 	"go-slim.dev/infra/msg"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/feature/plural"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+	"golang.org/x/text/number"
 )
 
 // Printer 基于 golang.org/x/text 的 msg.Printer 实现。
@@ -38,6 +46,22 @@ import (
 type Printer struct {
 	printer *message.Printer // 底层的 golang.org/x/text 打印机
 	locale  msg.Locale       // 关联的语言环境
+
+	// 以下字段仅用于由 PrinterFactory 创建的 Printer
+	chain     []chainPrinter      // 回退链上每种语言的打印机，第一个即 printer
+	catalog   *catalog.Builder    // 动态翻译源和 SetTranslation 等写入的 catalog
+	local     *localSources       // 工厂的本地翻译源，运行时替换后立即生效
+	overrides *overrides          // 工厂中运行时覆盖的消息
+	missing   msg.MissingReporter // 缺失翻译的报告器，可以为 nil
+	fuzzy     *fuzzyMatcher       // 缺失消息的模糊匹配，可以为 nil
+}
+
+// chainPrinter 回退链中的一种语言
+type chainPrinter struct {
+	tag     language.Tag
+	base    language.Base
+	printer *message.Printer
+	locale  msg.Locale // 只使用与之相同的本地翻译源，父语言的翻译由回退链中的父语言提供
 }
 
 // NewPrinter 创建新的 xtext 打印机。
@@ -79,6 +103,97 @@ func NewPrinter(locale msg.Locale, opts ...message.Option) (msg.Printer, error)
 	}, nil
 }
 
+// newChainPrinter 创建按回退链查找翻译的 Printer，chain 的第一个元素是 locale 本身。
+// 回退链中无法解析的语言会被忽略。
+func newChainPrinter(locale msg.Locale, chain []msg.Locale, b *catalog.Builder, local *localSources, o *overrides, missing msg.MissingReporter, fuzzy *fuzzyMatcher) (msg.Printer, error) {
+	printer, err := NewPrinter(locale, message.Catalog(b))
+	p := printer.(*Printer)
+	p.catalog = b
+	p.local = local
+	p.overrides = o
+	p.missing = missing
+	p.fuzzy = fuzzy
+
+	for i, l := range chain {
+		tag, perr := language.All.Parse(l.String())
+		if perr != nil {
+			continue
+		}
+		cp := chainPrinter{tag: tag, printer: p.printer, locale: l}
+		cp.base, _ = tag.Base()
+		if i > 0 {
+			cp.printer = message.NewPrinter(tag, message.Catalog(b))
+		}
+		p.chain = append(p.chain, cp)
+	}
+	return p, err
+}
+
+// resolve 返回输出 key 使用的打印机和消息标识。
+//
+// 整个回退链都没有该消息时报告缺失；启用了模糊匹配并找到相似的消息时返回该消息的标识，
+// 否则返回 Printer 自身的打印机输出原文。
+func (p *Printer) resolve(key string) (*message.Printer, string) {
+	if p.catalog == nil {
+		return p.printer, key
+	}
+	if printer, ok := p.lookup(key); ok {
+		return printer, key
+	}
+	if p.missing != nil {
+		p.missing.ReportMissing(p.locale, key)
+	}
+	if m, ok := p.fuzzy.match(p.locale, p.chain, key); ok {
+		if printer, ok := p.lookup(m.id); ok {
+			if r, ok := p.missing.(msg.FuzzyReporter); ok {
+				r.ReportFuzzy(p.locale, key, m.id, m.score)
+			}
+			return printer, m.id
+		}
+	}
+	return p.printer, key
+}
+
+// lookup 返回回退链中第一个包含 key 的语言的打印机。
+//
+// 同一语言中依次查找覆盖的消息、动态翻译源和 SetTranslation 写入的消息、已加载的本地翻译源，
+// 本地翻译源中更具体的语言优先。
+func (p *Printer) lookup(key string) (*message.Printer, bool) {
+	override := p.overrides.catalog.Load()
+	var local []*Source
+	if p.local != nil {
+		local = *p.local.Load()
+	}
+	for _, c := range p.chain {
+		if override != nil && hasMessage(override, c.tag, key) {
+			return message.NewPrinter(c.tag, message.Catalog(override)), true
+		}
+		if hasMessage(p.catalog, c.tag, key) {
+			return c.printer, true
+		}
+		// 通用的语言排在前面，倒序查找使更具体的语言优先
+		for i := len(local) - 1; i >= 0; i-- {
+			lc := local[i].loaded.Load()
+			if lc != nil && lc.base == c.base && local[i].locale.BaseEqual(c.locale) && hasMessage(lc.builder, c.tag, key) {
+				return lc.printer(c.tag), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// hasMessage 检查 catalog 中 tag 或其父语言是否有 key 对应的消息
+func hasMessage(b *catalog.Builder, tag language.Tag, key string) bool {
+	err := b.Context(tag, discardRenderer{}).Execute(key)
+	return !errors.Is(err, catalog.ErrNotFound)
+}
+
+// discardRenderer 丢弃输出的渲染器，仅用于检查消息是否存在
+type discardRenderer struct{}
+
+func (discardRenderer) Render(string) {}
+func (discardRenderer) Arg(int) any   { return nil }
+
 // Locale 实现 msg.Localizer 接口。
 //
 // 返回与此 Printer 关联的语言环境。
@@ -110,7 +225,7 @@ func (p *Printer) Locale() msg.Locale {
 //	// 输出: "Hello42true"
 func (p *Printer) Sprint(args ...any) string {
 	var result string
-	for _, arg := range args {
+	for _, arg := range msg.LocalizeArgs(p, args) {
 		result += fmt.Sprint(arg)
 	}
 	return result
@@ -135,7 +250,8 @@ func (p *Printer) Sprint(args ...any) string {
 //	result := printer.Sprintf("Hello, %s!", "World")
 //	// 如果有翻译，输出本地化结果，否则输出: "Hello, World!"
 func (p *Printer) Sprintf(format string, args ...any) string {
-	return p.printer.Sprintf(format, args...)
+	printer, key := p.resolve(format)
+	return printer.Sprintf(key, msg.LocalizeArgs(p, args)...)
 }
 
 // Sprintln 实现 msg.Formatter 接口。
@@ -156,7 +272,7 @@ func (p *Printer) Sprintf(format string, args ...any) string {
 //	result := printer.Sprintln("Hello", "World")
 //	// 输出: "Hello World\n"
 func (p *Printer) Sprintln(args ...any) string {
-	return p.printer.Sprintln(args...)
+	return p.printer.Sprintln(msg.LocalizeArgs(p, args)...)
 }
 
 // Fprint 实现 msg.WriterFormatter 接口。
@@ -181,7 +297,7 @@ func (p *Printer) Sprintln(args ...any) string {
 //	    log.Fatal(err)
 //	}
 func (p *Printer) Fprint(w io.Writer, args ...any) (n int, err error) {
-	return p.printer.Fprint(w, args...)
+	return p.printer.Fprint(w, msg.LocalizeArgs(p, args)...)
 }
 
 // Fprintf 实现 msg.WriterFormatter 接口。
@@ -207,7 +323,8 @@ func (p *Printer) Fprint(w io.Writer, args ...any) (n int, err error) {
 //	    log.Fatal(err)
 //	}
 func (p *Printer) Fprintf(w io.Writer, format string, args ...any) (n int, err error) {
-	return p.printer.Fprintf(w, format, args...)
+	printer, key := p.resolve(format)
+	return printer.Fprintf(w, key, msg.LocalizeArgs(p, args)...)
 }
 
 // Fprintln 实现 msg.WriterFormatter 接口。
@@ -232,7 +349,7 @@ func (p *Printer) Fprintf(w io.Writer, format string, args ...any) (n int, err e
 //	    log.Fatal(err)
 //	}
 func (p *Printer) Fprintln(w io.Writer, args ...any) (n int, err error) {
-	return p.printer.Fprintln(w, args...)
+	return p.printer.Fprintln(w, msg.LocalizeArgs(p, args)...)
 }
 
 // Print 实现 msg.ConsoleFormatter 接口。
@@ -256,7 +373,7 @@ func (p *Printer) Fprintln(w io.Writer, args ...any) (n int, err error) {
 //	    log.Fatal(err)
 //	}
 func (p *Printer) Print(args ...any) (n int, err error) {
-	return p.printer.Print(args...)
+	return p.printer.Print(msg.LocalizeArgs(p, args)...)
 }
 
 // Printf 实现 msg.ConsoleFormatter 接口。
@@ -288,7 +405,8 @@ func (p *Printer) Print(args ...any) (n int, err error) {
 //	    log.Fatal(err)
 //	}
 func (p *Printer) Printf(format string, args ...any) (n int, err error) {
-	return p.printer.Printf(format, args...)
+	printer, key := p.resolve(format)
+	return printer.Printf(key, msg.LocalizeArgs(p, args)...)
 }
 
 // Println 实现 msg.ConsoleFormatter 接口。
@@ -312,5 +430,99 @@ func (p *Printer) Printf(format string, args ...any) (n int, err error) {
 //	    log.Fatal(err)
 //	}
 func (p *Printer) Println(args ...any) (n int, err error) {
-	return p.printer.Println(args...)
+	return p.printer.Println(msg.LocalizeArgs(p, args)...)
+}
+
+// FormatNumber 实现 msg.NumberFormatter 接口。
+//
+// 使用语言环境的千位分隔符和小数点格式化数字。
+//
+// 示例：
+//
+//	printer.FormatNumber(1234567.891)
+//	// en-US: "1,234,567.891"，de-DE: "1.234.567,891"，fr-FR: "1 234 567,891"
+func (p *Printer) FormatNumber(v any) string {
+	return p.printer.Sprint(number.Decimal(v))
+}
+
+// FormatCurrency 实现 msg.NumberFormatter 接口。
+//
+// 使用 ISO 4217 货币代码对应的符号和精度格式化金额，
+// 货币代码无效时输出代码和未格式化的金额。
+//
+// 示例：
+//
+//	printer.FormatCurrency(1234.5, "EUR")
+//	// en-US: "€ 1,234.50"，de-DE: "€ 1.234,50"
+func (p *Printer) FormatCurrency(amount any, code string) string {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return fmt.Sprint(code, " ", amount)
+	}
+	return p.printer.Sprint(currency.Symbol(unit.Amount(amount)))
+}
+
+// FormatPercent 实现 msg.NumberFormatter 接口。
+//
+// 将比例格式化为本地化的百分数。
+//
+// 示例：
+//
+//	printer.FormatPercent(0.256)
+//	// en-US: "26%"，de-DE: "26 %"
+func (p *Printer) FormatPercent(v any) string {
+	return p.printer.Sprint(number.Percent(v))
+}
+
+// PluralCategory 实现 msg.PluralSelector 接口。
+//
+// 按语言环境的 CLDR 基数复数规则返回数量的复数类别，n 为整数、浮点数或数字字符串，
+// 无法解析为数字时返回 "other"。
+//
+// 示例：
+//
+//	printer.PluralCategory(1)   // en: "one"，ru: "one"
+//	printer.PluralCategory(5)   // en: "other"，ru: "many"
+//	printer.PluralCategory(1.5) // en: "other"，fr: "one"
+func (p *Printer) PluralCategory(n any) string {
+	var s string
+	switch v := n.(type) {
+	case float32:
+		s = strconv.FormatFloat(math.Abs(float64(v)), 'f', -1, 32)
+	case float64:
+		s = strconv.FormatFloat(math.Abs(v), 'f', -1, 64)
+	default:
+		s = strings.TrimPrefix(fmt.Sprint(n), "-")
+	}
+
+	// 计算 CLDR 复数规则的操作数：整数部分 i，小数位数 v 和 w，小数部分 f 和 t
+	intPart, frac, _ := strings.Cut(s, ".")
+	i, err := strconv.Atoi(intPart[max(0, len(intPart)-18):])
+	if err != nil {
+		return "other"
+	}
+	f, t := 0, 0
+	trimmed := strings.TrimRight(frac, "0")
+	if frac != "" {
+		if f, err = strconv.Atoi(frac[:min(len(frac), 9)]); err != nil {
+			return "other"
+		}
+		t, _ = strconv.Atoi(trimmed[:min(len(trimmed), 9)])
+	}
+
+	tag := language.Make(p.locale.String())
+	switch plural.Cardinal.MatchPlural(tag, i, len(frac), len(trimmed), f, t) {
+	case plural.Zero:
+		return "zero"
+	case plural.One:
+		return "one"
+	case plural.Two:
+		return "two"
+	case plural.Few:
+		return "few"
+	case plural.Many:
+		return "many"
+	default:
+		return "other"
+	}
 }
@@ -200,3 +200,83 @@ func TestPrinter_ErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestPrinterNumberFormatting(t *testing.T) {
+	tests := []struct {
+		locale   msg.Locale
+		number   string
+		currency string
+		percent  string
+	}{
+		{msg.EnglishUS, "1,234,567.891", "€ 1,234.50", "26%"},
+		{msg.GermanDE, "1.234.567,891", "€ 1.234,50", "26\u00a0%"},
+		{msg.FrenchFR, "1 234 567,891", "€ 1 234,50", "26 %"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.locale), func(t *testing.T) {
+			p, err := NewPrinter(tt.locale)
+			if err != nil {
+				t.Fatalf("NewPrinter() error = %v", err)
+			}
+			nf, ok := p.(msg.NumberFormatter)
+			if !ok {
+				t.Fatal("Printer should implement msg.NumberFormatter")
+			}
+
+			if got := nf.FormatNumber(1234567.891); got != tt.number {
+				t.Errorf("FormatNumber() = %q, want %q", got, tt.number)
+			}
+			if got := nf.FormatCurrency(1234.5, "EUR"); got != tt.currency {
+				t.Errorf("FormatCurrency() = %q, want %q", got, tt.currency)
+			}
+			if got := nf.FormatPercent(0.256); got != tt.percent {
+				t.Errorf("FormatPercent() = %q, want %q", got, tt.percent)
+			}
+		})
+	}
+
+	t.Run("Invalid currency", func(t *testing.T) {
+		p, _ := NewPrinter(msg.EnglishUS)
+		if got := p.(msg.NumberFormatter).FormatCurrency(5, "XXXX"); got != "XXXX 5" {
+			t.Errorf("FormatCurrency() = %q, want %q", got, "XXXX 5")
+		}
+	})
+}
+
+func TestPrinterPluralCategory(t *testing.T) {
+	tests := []struct {
+		locale msg.Locale
+		n      any
+		want   string
+	}{
+		{msg.EnglishUS, 1, "one"},
+		{msg.EnglishUS, 0, "other"},
+		{msg.EnglishUS, "1.0", "other"},
+		{msg.FrenchFR, 1.5, "one"},
+		{msg.Locale("ru"), 3, "few"},
+		{msg.Locale("ru"), 5, "many"},
+		{msg.Locale("ru"), int64(21), "one"},
+		{msg.Locale("ru"), 2.5, "other"},
+		{msg.Locale("ar"), 0, "zero"},
+		{msg.Chinese, 1, "other"},
+		{msg.EnglishUS, "many", "other"},
+	}
+
+	for _, tt := range tests {
+		p, _ := NewPrinter(tt.locale)
+		ps, ok := p.(msg.PluralSelector)
+		if !ok {
+			t.Fatal("Printer should implement msg.PluralSelector")
+		}
+		if got := ps.PluralCategory(tt.n); got != tt.want {
+			t.Errorf("%s: PluralCategory(%v) = %q, want %q", tt.locale, tt.n, got, tt.want)
+		}
+	}
+
+	p, _ := NewPrinter(msg.Locale("ru"))
+	got := msg.Sprintn(p, "{n, plural, one {{n} файл} few {{n} файла} other {{n} файлов}}", msg.Args{"n": 3})
+	if got != "3 файла" {
+		t.Errorf("Sprintn() = %q, want %q", got, "3 файла")
+	}
+}
@@ -0,0 +1,80 @@
+package xtext
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-slim.dev/infra/msg"
+)
+
+// refresher 实现 SQLSource 和 RemoteSource 共用的定时刷新、变化通知和日志，
+// 内嵌在翻译源中，mu 同时保护翻译源自身的快照
+type refresher struct {
+	fetch    func(ctx context.Context) (bool, error) // 翻译源的 Fetch
+	interval time.Duration                           // 后台刷新的间隔
+	logFunc  msg.LogFunc                             // 记录刷新失败等信息的日志函数（可选）
+
+	mu       sync.RWMutex
+	modified time.Time // 快照的更新时间
+	watchers []func()  // 快照变化时的回调
+}
+
+// Modified 返回快照最近一次更新的时间，没有快照时返回零值
+func (r *refresher) Modified() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.modified
+}
+
+// Start 在后台立即调用一次 Fetch，之后按 Interval 定时刷新，直到 ctx 被取消。
+// 刷新失败会记录日志并继续使用最近一次成功的快照。
+func (r *refresher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := r.fetch(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				r.log(err.Error())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// update 在持有锁时调用 apply 替换快照，apply 返回 false 表示内容没有变化。
+// 内容变化时更新 Modified 并在释放锁后通知回调，返回内容是否发生了变化
+func (r *refresher) update(apply func() bool) bool {
+	r.mu.Lock()
+	if !apply() {
+		r.mu.Unlock()
+		return false
+	}
+	r.modified = time.Now()
+	watchers := r.watchers
+	r.mu.Unlock()
+
+	for _, watch := range watchers {
+		watch()
+	}
+	return true
+}
+
+// watch 实现 dynamicSource 接口，注册快照变化时的回调
+func (r *refresher) watch(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.watchers = append(r.watchers, fn)
+}
+
+func (r *refresher) log(s string) {
+	if r.logFunc != nil {
+		r.logFunc(s)
+	}
+}
@@ -0,0 +1,66 @@
+package xtext
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRefresher(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetches int
+		logs    []string
+	)
+	r := &refresher{
+		interval: 10 * time.Millisecond,
+		logFunc: func(s string) {
+			mu.Lock()
+			defer mu.Unlock()
+			logs = append(logs, s)
+		},
+	}
+	r.fetch = func(ctx context.Context) (bool, error) {
+		mu.Lock()
+		fetches++
+		mu.Unlock()
+		return false, errors.New("fetch failed")
+	}
+
+	notified := 0
+	r.watch(func() { notified++ })
+
+	// 内容未变化时不更新时间也不通知
+	if r.update(func() bool { return false }) || notified != 0 || !r.Modified().IsZero() {
+		t.Errorf("update() without change: notified = %d, Modified() = %v", notified, r.Modified())
+	}
+	if !r.update(func() bool { return true }) || notified != 1 || r.Modified().IsZero() {
+		t.Errorf("update() with change: notified = %d, Modified() = %v", notified, r.Modified())
+	}
+
+	// Start 立即刷新一次，之后定时刷新并记录失败，直到 ctx 被取消
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx)
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n, logged := fetches, len(logs)
+		mu.Unlock()
+		if n >= 2 && logged >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("fetches = %d, logs = %d, want at least 2 of each", n, logged)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if logs[0] != "fetch failed" {
+		t.Errorf("log = %q, want the fetch error", logs[0])
+	}
+}
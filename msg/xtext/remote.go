@@ -0,0 +1,235 @@
+package xtext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"time"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/message/catalog"
+)
+
+// RemoteConfig 远程翻译源的配置
+type RemoteConfig struct {
+	// URL 翻译文件的地址，可以是任意 HTTP/HTTPS 端点。
+	// 对象存储（如 S3、OSS）可以使用公开地址或预签名 URL，
+	// 需要签名的请求可以通过 Client 的 Transport 实现。
+	URL string
+
+	// Locale 翻译文件对应的语言
+	Locale msg.Locale
+
+	// Loader 解析翻译文件的加载器，默认根据 URL 的路径扩展名从默认注册表中选择，
+	// 无法识别时使用 JSONLoader
+	Loader Loader
+
+	// Client 发送请求的 HTTP 客户端，默认为带 30 秒超时的客户端
+	Client *http.Client
+
+	// Header 每次请求附加的请求头，如认证信息
+	Header http.Header
+
+	// Interval 后台刷新的间隔，默认为 5 分钟
+	Interval time.Duration
+
+	// CacheFile 最近一次成功获取的翻译文件的本地缓存路径（可选）。
+	// 远程服务不可用时，启动时可以从缓存恢复翻译。
+	CacheFile string
+
+	// LogFunc 记录刷新失败等信息的日志函数（可选）
+	LogFunc msg.LogFunc
+}
+
+// RemoteSource 从 HTTP 端点或对象存储获取翻译文件的翻译源。
+//
+// 特点：
+// 1. ETag 缓存：使用 If-None-Match 发送条件请求，内容未变化时服务端返回 304
+// 2. 定时刷新：Start 启动后台刷新，翻译可以在不重新部署的情况下更新
+// 3. 失败回退：获取或解析失败时保留最近一次成功的快照，不会加载损坏的翻译
+// 4. 本地缓存：可选地将快照保存到磁盘，冷启动时远程服务不可用也能使用翻译
+//
+// 使用示例：
+//
+//	remote := xtext.NewRemoteSource(xtext.RemoteConfig{
+//	    URL:      "https://cdn.example.com/i18n/zh-CN.gotext.json",
+//	    Locale:   msg.Locale("zh-CN"),
+//	    Interval: time.Minute,
+//	})
+//	factory.AddRemoteSource(remote)
+//	remote.Start(ctx)
+type RemoteSource struct {
+	refresher
+	config RemoteConfig
+	name   string // 不含查询参数的 URL，用于日志和选择 JSONC 解析，避免泄露预签名参数
+
+	etag     string // 最近一次成功响应的 ETag
+	snapshot []byte // 最近一次成功获取的翻译文件内容
+}
+
+// NewRemoteSource 创建远程翻译源。
+//
+// 如果配置了 CacheFile 且缓存可以正常解析，会使用缓存作为初始快照。
+// 创建后不会立即发送请求，需要调用 Fetch 或 Start。
+func NewRemoteSource(config RemoteConfig) *RemoteSource {
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	name := remoteName(config.URL)
+	if config.Loader == nil {
+		config.Loader = remoteLoader(name)
+	}
+
+	r := &RemoteSource{config: config, name: name}
+	r.refresher = refresher{fetch: r.Fetch, interval: config.Interval, logFunc: config.LogFunc}
+	if config.CacheFile != "" {
+		if data, err := os.ReadFile(config.CacheFile); err == nil {
+			if err := r.validate(data); err != nil {
+				r.log(fmt.Sprintf("Ignoring invalid translation cache %s: %v", config.CacheFile, err))
+			} else {
+				r.snapshot = data
+			}
+		}
+	}
+	return r
+}
+
+// remoteName 去掉 URL 中的用户信息、查询参数和片段
+func remoteName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// remoteLoader 根据 URL 的路径扩展名选择加载器
+func remoteLoader(name string) Loader {
+	if loader, ok := NewLoaderRegistry().GetLoaderForFile(path.Base(name)); ok {
+		return loader
+	}
+	return NewJSONLoader()
+}
+
+// Locale 返回翻译源对应的语言
+func (r *RemoteSource) Locale() msg.Locale {
+	return r.config.Locale
+}
+
+// ETag 返回最近一次成功响应的 ETag
+func (r *RemoteSource) ETag() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.etag
+}
+
+// Fetch 获取远程翻译文件，返回内容是否发生了变化。
+//
+// 请求携带上一次的 ETag，服务端返回 304 时视为未变化。
+// 请求失败、状态码异常或内容无法解析时返回错误并保留原有快照。
+// 内容变化时通知通过 AddRemoteSource 注册的工厂加载新的翻译。
+func (r *RemoteSource) Fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.config.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	for k, vs := range r.config.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if etag := r.ETag(); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := r.config.Client.Do(req)
+	if err != nil {
+		// url.Error 包含完整的 URL，去掉以免在日志中泄露预签名参数
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err
+		}
+		return false, fmt.Errorf("failed to fetch translation %s: %w", r.name, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("failed to fetch translation %s: unexpected status %s", r.name, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read translation %s: %w", r.name, err)
+	}
+	if err := r.validate(data); err != nil {
+		return false, err
+	}
+
+	if r.config.CacheFile != "" {
+		if err := os.WriteFile(r.config.CacheFile, data, 0o644); err != nil {
+			r.log(fmt.Sprintf("Failed to write translation cache %s: %v", r.config.CacheFile, err))
+		}
+	}
+	return r.update(func() bool {
+		r.etag = resp.Header.Get("ETag")
+		r.snapshot = data
+		return true
+	}), nil
+}
+
+// Load 将当前快照加载到指定的 catalog.Builder 中，没有快照时不做任何处理。
+// 同名的消息会被覆盖，远程文件中删除的消息在重新 Reset 之前仍然保留。
+func (r *RemoteSource) Load(b *catalog.Builder) {
+	r.mu.RLock()
+	data := r.snapshot
+	r.mu.RUnlock()
+
+	if len(data) == 0 {
+		return
+	}
+	if err := loadChecked(r.name, data, r.config.Loader, b, r.config.Locale, r.log); err != nil {
+		r.log(fmt.Sprintf("Error loading translation %s: %v", r.name, err))
+	}
+}
+
+// locales 实现 dynamicSource 接口
+func (r *RemoteSource) locales() []msg.Locale {
+	return []msg.Locale{r.config.Locale}
+}
+
+// catalogs 实现 dynamicSource 接口，没有快照或加载器未实现 MessageParser 时返回 nil
+func (r *RemoteSource) catalogs() []sourceCatalog {
+	r.mu.RLock()
+	data := r.snapshot
+	r.mu.RUnlock()
+
+	parser, ok := r.config.Loader.(MessageParser)
+	if len(data) == 0 || !ok {
+		return nil
+	}
+	messages, err := parser.ParseMessages(r.name, data)
+	return []sourceCatalog{{name: r.name, locale: r.config.Locale, messages: messages, err: err}}
+}
+
+// validate 使用临时 builder 解析数据，确认翻译文件可以正常加载
+func (r *RemoteSource) validate(data []byte) error {
+	if err := loadChecked(r.name, data, r.config.Loader, catalog.NewBuilder(), r.config.Locale, nil); err != nil {
+		return fmt.Errorf("invalid translation %s: %w", r.name, err)
+	}
+	return nil
+}
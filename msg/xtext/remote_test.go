@@ -0,0 +1,155 @@
+package xtext
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+)
+
+// catalogServer 模拟提供翻译文件的 HTTP 服务，支持 ETag 条件请求
+type catalogServer struct {
+	mu     sync.Mutex
+	body   string
+	etag   string
+	status int
+}
+
+func (s *catalogServer) set(body, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag, s.status = body, etag, http.StatusOK
+}
+
+func (s *catalogServer) fail(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *catalogServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != http.StatusOK {
+		w.WriteHeader(s.status)
+		return
+	}
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	w.Write([]byte(s.body))
+}
+
+func remoteCatalog(translation string) string {
+	return `{"language": "zh-CN", "messages": [{"id": "hello", "message": "hello", "translation": "` + translation + `"}]}`
+}
+
+func TestRemoteSource(t *testing.T) {
+	cs := &catalogServer{}
+	cs.set(remoteCatalog("你好"), `"v1"`)
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	cache := filepath.Join(t.TempDir(), "zh-CN.gotext.json")
+	remote := NewRemoteSource(RemoteConfig{
+		URL:       srv.URL + "/zh-CN.gotext.json?X-Amz-Signature=secret",
+		Locale:    msg.Locale("zh-CN"),
+		CacheFile: cache,
+	})
+
+	factory := NewPrinterFactory()
+	factory.AddRemoteSource(remote)
+	if !factory.SupportsLocale(msg.Locale("zh-CN")) {
+		t.Fatal("Factory should support the remote source locale")
+	}
+
+	ctx := context.Background()
+	changed, err := remote.Fetch(ctx)
+	if err != nil || !changed {
+		t.Fatalf("Fetch() = %v, %v, want true, nil", changed, err)
+	}
+	if got := remote.ETag(); got != `"v1"` {
+		t.Errorf("ETag() = %q, want %q", got, `"v1"`)
+	}
+
+	printer, err := factory.CreatePrinter(msg.Locale("zh-CN"))
+	if err != nil {
+		t.Fatalf("CreatePrinter error = %v", err)
+	}
+	if got := printer.Sprintf("hello"); got != "你好" {
+		t.Errorf("Sprintf(hello) = %q, want %q", got, "你好")
+	}
+
+	t.Run("Not modified", func(t *testing.T) {
+		changed, err := remote.Fetch(ctx)
+		if err != nil || changed {
+			t.Errorf("Fetch() = %v, %v, want false, nil", changed, err)
+		}
+	})
+
+	t.Run("Updated", func(t *testing.T) {
+		cs.set(remoteCatalog("您好"), `"v2"`)
+		if changed, err := remote.Fetch(ctx); err != nil || !changed {
+			t.Fatalf("Fetch() = %v, %v, want true, nil", changed, err)
+		}
+		// 已创建的 Printer 直接使用新的翻译
+		if got := printer.Sprintf("hello"); got != "您好" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "您好")
+		}
+	})
+
+	t.Run("Failure keeps last snapshot", func(t *testing.T) {
+		cs.fail(http.StatusInternalServerError)
+		if _, err := remote.Fetch(ctx); err == nil {
+			t.Error("Fetch should return error for server failure")
+		}
+		cs.set(`{"broken"`, `"v3"`)
+		if _, err := remote.Fetch(ctx); err == nil {
+			t.Error("Fetch should return error for invalid catalog")
+		}
+		if got := remote.ETag(); got != `"v2"` {
+			t.Errorf("ETag() = %q, want %q", got, `"v2"`)
+		}
+		if got := printer.Sprintf("hello"); got != "您好" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "您好")
+		}
+	})
+
+	t.Run("Error hides query", func(t *testing.T) {
+		cs.fail(http.StatusForbidden)
+		_, err := remote.Fetch(ctx)
+		if err == nil {
+			t.Fatal("Fetch should return error for forbidden response")
+		}
+		if got := err.Error(); strings.Contains(got, "secret") {
+			t.Errorf("error %q should not contain the URL query", got)
+		}
+	})
+
+	t.Run("Cache file", func(t *testing.T) {
+		cs.fail(http.StatusServiceUnavailable)
+		restored := NewRemoteSource(RemoteConfig{
+			URL:       srv.URL + "/zh-CN.gotext.json",
+			Locale:    msg.Locale("zh-CN"),
+			CacheFile: cache,
+		})
+		f := NewPrinterFactory()
+		f.AddRemoteSource(restored)
+		f.Reset("")
+
+		p, err := f.CreatePrinter(msg.Locale("zh-CN"))
+		if err != nil {
+			t.Fatalf("CreatePrinter error = %v", err)
+		}
+		if got := p.Sprintf("hello"); got != "您好" {
+			t.Errorf("Sprintf(hello) from cache = %q, want %q", got, "您好")
+		}
+	})
+}
@@ -1,10 +1,22 @@
 package xtext
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"go-slim.dev/infra/msg"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 	"golang.org/x/text/message/catalog"
 )
 
@@ -13,6 +25,7 @@ import (
 // 这个结构体封装了翻译文件的基本信息：
 // - file: 翻译文件的完整路径
 // - loader: 用于加载该文件的加载器实例
+// - fsys: 文件所在的文件系统，为 nil 时从磁盘读取
 //
 // 示例：
 //
@@ -23,25 +36,25 @@ import (
 type Entry struct {
 	file   string // 翻译文件的完整路径
 	loader Loader // 文件加载器实例
+	fsys   fs.FS  // 文件所在的文件系统，为 nil 时从磁盘读取
 }
 
 // Source 表示一个翻译源，负责加载和管理特定语言的翻译数据。
 //
 // Source 是翻译加载的基本单元，每个 Source 对应一种语言，
-// 可以包含多个翻译文件（通过 entries 字段）。
+// 可以包含多个翻译文件（通过 files 字段）。
 //
 // 设计特点：
 // 1. 语言特定：每个 Source 只处理一种语言的翻译
-// 2. 文件聚合：支持单个文件或多个文件（通过 entries）
+// 2. 文件聚合：支持单个文件或多个文件
 // 3. 延迟加载：只在需要时才加载翻译数据
-// 4. 内存优化：加载完成后清空 entries 释放内存
-// 5. 简单设计：不处理并发控制，由上层 PrinterFactory 管理
+// 4. 独立 catalog：由 PrinterFactory 使用时，翻译数据加载到 Source 自己的 catalog 中
+// 5. 局部重新加载：构建新的 catalog 并原子替换，不影响其他语言
 //
 // 并发控制说明：
-// - Source 本身不实现并发控制机制
-// - 并发安全由 PrinterFactory 的 singleflight 机制保证
-// - 这种设计避免了双重锁定的复杂性
-// - 简化了 Source 的实现，提高了性能
+// - Source 的所有方法都是并发安全的
+// - 加载由互斥锁串行化，并发的首次加载只读取一次文件
+// - 重新加载期间 Printer 继续使用旧的 catalog，不会看到只加载了一部分的数据
 //
 // 使用示例：
 //
@@ -52,14 +65,65 @@ type Entry struct {
 //	}
 //	source := xtext.NewSource(msg.English, entries)
 type Source struct {
-	locale  msg.Locale // 语言标识符
-	entries []Entry    // 翻译文件条目列表
-	logFunc msg.LogFunc
+	locale      msg.Locale // 语言标识符
+	files       []Entry    // 所有翻译文件条目
+	messages    []Message  // 内存中的翻译，在文件之后加载
+	mu          sync.Mutex // 串行化加载，保护 logFunc、concurrency、strict、conflicts 和 errs
+	logFunc     msg.LogFunc
+	concurrency int                          // 并发读取和解析文件的数量，为 0 时使用 GOMAXPROCS
+	strict      bool                         // 严格模式，有文件加载失败时不发布 catalog
+	conflicts   ConflictPolicy               // 多个文件定义同一消息时的处理策略
+	errs        []*LoadError                 // 最近一次加载失败的文件
+	cache       map[string]*parsedFile       // 最近一次加载时解析的文件，键为文件路径，内容未变化时不重新解析
+	built       loadConfig                   // 构建已发布的 catalog 时使用的设置
+	loaded      atomic.Pointer[localCatalog] // 已加载的翻译数据，尚未加载时为 nil
+}
+
+// loadConfig 是影响加载结果的 Source 设置，设置变化时即使文件未变化也需要重新构建 catalog
+type loadConfig struct {
+	strict    bool
+	conflicts ConflictPolicy
+}
+
+// LoadError 是加载翻译文件失败的错误，如文件无法读取、解析或语言无效。
+//
+// Source.Load、PrinterFactory.Load 和 ReloadLocale 返回的错误由 LoadError 合并而成，
+// 可以使用 errors.As 取得失败的文件，PrinterFactory.LoadErrors 返回最近一次加载的所有失败。
+type LoadError struct {
+	File   string     // 文件路径
+	Locale msg.Locale // 翻译源的语言
+	Err    error      // 失败的原因
+}
+
+// Error 返回如 `locales/zh-CN.gotext.json: unexpected end of JSON input` 的描述
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+// Unwrap 返回失败的原因
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// localCatalog 是 Source 加载后的翻译数据
+type localCatalog struct {
+	builder  *catalog.Builder
+	base     language.Base // Source 的语言代码，Printer 只在同一语言的回退链中查找
+	printers sync.Map      // language.Tag → *message.Printer
+}
+
+// printer 返回使用该 catalog 输出 tag 的打印机
+func (c *localCatalog) printer(tag language.Tag) *message.Printer {
+	if p, ok := c.printers.Load(tag); ok {
+		return p.(*message.Printer)
+	}
+	p, _ := c.printers.LoadOrStore(tag, message.NewPrinter(tag, message.Catalog(c.builder)))
+	return p.(*message.Printer)
 }
 
 // NewSource 创建新的翻译源实例。
 //
-// 创建的 Source 可以立即使用，翻译数据会在第一次调用 load 方法时延迟加载。
+// 创建的 Source 可以立即使用，翻译数据会在第一次使用时延迟加载。
 //
 // 参数 locale: 语言标识符
 // 参数 entries: 翻译文件条目列表
@@ -79,9 +143,72 @@ type Source struct {
 //	source := xtext.NewSource(msg.English, entries)
 func NewSource(locale msg.Locale, entries []Entry) *Source {
 	return &Source{
-		locale:  locale,
-		entries: entries,
+		locale: locale,
+		files:  slices.Clone(entries),
+	}
+}
+
+// NewSourceMap 创建内存中的翻译源，键为消息 ID，值为译文。
+//
+// 加载时不读取文件，用于 WriteGo 生成的代码等在编译时确定的翻译。
+//
+// 示例：
+//
+//	factory.SetSource(xtext.NewSourceMap(msg.Locale("zh-CN"), map[string]string{
+//	    "Hello": "你好",
+//	}))
+func NewSourceMap(locale msg.Locale, messages map[string]string) *Source {
+	s := &Source{locale: locale, messages: make([]Message, 0, len(messages))}
+	for _, id := range slices.Sorted(maps.Keys(messages)) {
+		s.messages = append(s.messages, Message{ID: id, Translation: messages[id]})
+	}
+	return s
+}
+
+// NewSourceFS 从 fs.FS（包括 go:embed 的 embed.FS）创建翻译源。
+//
+// patterns 使用 fs.Glob 的语法匹配文件，未指定时匹配根目录下的所有文件；
+// 匹配到的文件中只有默认加载器支持的格式（如 .gotext.json）会被加载。
+// 模式语法错误或没有匹配到任何可加载的文件时返回错误。
+//
+// 这样部署在 scratch 等没有翻译目录的容器中的程序，也可以将翻译文件嵌入到二进制中。
+//
+// 示例：
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	source, err := xtext.NewSourceFS(msg.Locale("zh-CN"), locales, "locales/zh-CN/*.gotext.json")
+func NewSourceFS(locale msg.Locale, fsys fs.FS, patterns ...string) (*Source, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"*"}
+	}
+
+	loaders := NewLoaderRegistry()
+	var entries []Entry
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		found := false
+		for _, file := range matches {
+			loader, ok := loaders.GetLoaderForFile(file)
+			if !ok {
+				continue
+			}
+			found = true
+			if slices.ContainsFunc(entries, func(e Entry) bool { return e.file == file }) {
+				continue
+			}
+			entries = append(entries, Entry{file: file, loader: loader, fsys: fsys})
+		}
+		if !found {
+			return nil, fmt.Errorf("pattern %q matches no translation files", pattern)
+		}
 	}
+
+	return NewSource(locale, entries), nil
 }
 
 // SetLogFunc 设置日志函数用于记录加载过程中的信息和错误。
@@ -110,71 +237,247 @@ func NewSource(locale msg.Locale, entries []Entry) *Source {
 //	source.SetLogFunc(nil)
 //
 // 线程安全性：
-// - 此方法可以在任何时候调用
+// - 此方法可以在任何时候调用，与加载并发时等待加载完成
 // - 设置后立即生效，影响后续的加载操作
 // - 建议在开始加载前设置，避免遗漏日志
 func (s *Source) SetLogFunc(f msg.LogFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.logFunc = f
 }
 
+// SetConcurrency 设置加载时并发读取和解析翻译文件的数量，n <= 0 时使用 runtime.GOMAXPROCS(0)。
+//
+// 文件的读取和解析并发进行，写入 catalog 时仍按文件顺序，同名消息以后面的文件为准。
+// 包含上百个文件的语言可以显著缩短加载时间；设置为 1 时逐个加载。
+func (s *Source) SetConcurrency(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.concurrency = max(n, 0)
+}
+
+// SetStrict 设置是否使用严格模式加载翻译文件，默认为宽松模式。
+//
+// 宽松模式下，加载失败的文件通过日志函数记录后跳过，其余文件的翻译照常生效；
+// 严格模式下，有文件加载失败时不替换 Source 已有的翻译，尚未加载时保持未加载状态，
+// 错误由 PrinterFactory.Load、ReloadLocale 和 CreatePrinter 返回。
+// 两种模式都会记录日志，并可以通过 LoadErrors 取得失败的文件。
+func (s *Source) SetStrict(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.strict = enabled
+}
+
+// SetConflictPolicy 设置多个文件定义同一消息 ID 时的处理策略，默认为 ConflictLastWins。
+//
+// 每个冲突都通过日志函数记录，如 `zh/b.json: "hello": conflict: message is also defined in zh/a.json`；
+// 策略为 ConflictError 时冲突作为后加载的文件的错误返回，可以通过 LoadErrors 取得。
+func (s *Source) SetConflictPolicy(p ConflictPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conflicts = p
+}
+
+// LoadErrors 返回最近一次加载失败的文件，全部加载成功或尚未加载时返回 nil
+func (s *Source) LoadErrors() []*LoadError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.errs)
+}
+
 // Load 将翻译数据加载到指定的 catalog.Builder 中。
 //
-// 这是 Source 的核心方法，负责：
-// 1. 加载所有配置的翻译文件
-// 2. 将数据合并到全局 catalog 中
-// 3. 清空 entries 释放内存
-//
-// 注意：此方法不实现并发控制，并发安全由上层调用者保证。
-// PrinterFactory 使用 singleflight 确保同一个 Source 不会被并发加载。
+// 每次调用都会重新读取所有翻译文件，可以在多个 goroutine 中并发调用，
+// 同一个 Source 的加载会被串行化；catalog.Builder 本身是并发安全的。
 //
 // 错误处理：
-// - 单个文件加载失败不会影响其他文件的加载
+// - 单个文件加载失败不会影响其他文件的加载，严格模式也不例外
 // - 错误会通过设置的日志函数记录（如果有的话）
-// - 此方法不会返回错误，只进行内部处理
+// - 返回由 *LoadError 合并而成的错误，全部加载成功时返回 nil
 //
 // 参数 b: 目标 catalog.Builder，用于存储翻译数据
-//
-// 调用约定：
-// - 应该由 PrinterFactory 的 singleflight 机制保护
-// - 不应该在多个 goroutine 中同时调用同一个 Source 的 Load 方法
-// - 重复调用（在 entries 已清空后）会直接返回成功
-func (s *Source) Load(b *catalog.Builder) {
-	if len(s.entries) > 0 {
-		// 加载文件到工厂的 builder 中
-		s.loadFileToBuilder(b)
-		// 加载完成后清空 entries，释放不再需要的内存
-		s.entries = nil
+func (s *Source) Load(b *catalog.Builder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadFileToBuilder(b)
+}
+
+// catalog 返回 Source 自己的 catalog，第一次调用时加载翻译文件；
+// 严格模式下加载失败时返回 nil
+func (s *Source) catalog() *localCatalog {
+	s.load()
+	return s.loaded.Load()
+}
+
+// load 在 Source 尚未加载时加载翻译文件，返回加载失败的文件的错误；已经加载时返回 nil
+func (s *Source) load() error {
+	if s.loaded.Load() != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded.Load() != nil {
+		return nil
+	}
+	_, err := s.rebuild()
+	return err
+}
+
+// reload 重新读取翻译文件，构建新的 catalog 后原子替换旧的 catalog，返回加载失败的文件的错误。
+// 只重新解析内容变化的文件；所有文件都未变化且上次加载成功时保留当前的 catalog。
+func (s *Source) reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.rebuild()
+	return err
+}
+
+// rebuild 将翻译文件加载到新的 catalog 中并发布，调用者需要持有锁。
+// 部分文件加载失败时，宽松模式仍然发布 catalog，严格模式不发布并返回 nil，都返回这些文件的错误。
+// 文件内容和设置都与构建当前 catalog 时相同时，不重新构建，直接返回当前的 catalog。
+func (s *Source) rebuild() (*localCatalog, error) {
+	files := s.parseFiles()
+	config := loadConfig{strict: s.strict, conflicts: s.conflicts}
+	if c := s.loaded.Load(); c != nil && s.unchanged(files, config) {
+		return c, nil
+	}
+
+	cache := make(map[string]*parsedFile, len(files))
+	for _, file := range files {
+		if file.hashed {
+			cache[file.name] = file
+		}
+	}
+	s.cache = cache
+
+	c := &localCatalog{builder: catalog.NewBuilder()}
+	if tag, err := language.All.Parse(s.locale.String()); err == nil {
+		c.base, _ = tag.Base()
+	}
+	err := s.loadParsed(c.builder, files)
+	if err != nil && s.strict {
+		return nil, err
+	}
+	s.built = config
+	s.loaded.Store(c)
+	return c, err
+}
+
+// unchanged 报告 files 是否都是缓存中未变化的文件，并且上次加载没有失败、设置也没有变化，调用者需要持有锁
+func (s *Source) unchanged(files []*parsedFile, config loadConfig) bool {
+	if len(s.errs) > 0 || config != s.built || len(files) != len(s.cache) {
+		return false
+	}
+	for _, file := range files {
+		if s.cache[file.name] != file {
+			return false
+		}
 	}
+	return true
 }
 
-// loadFileToBuilder 从文件加载翻译数据并合并到全局 builder 中。
+// adopt 接管 old 的解析缓存和已发布的 catalog，Reset 使用它使未变化的语言不需要重新解析和构建
+func (s *Source) adopt(old *Source) {
+	old.mu.Lock()
+	defer old.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = old.cache
+	s.built = old.built
+	s.errs = old.errs
+	s.loaded.Store(old.loaded.Load())
+}
+
+// parseFiles 使用有上限的 goroutine 并发读取、解析和校验 Source 中的所有 Entry，结果与 files 一一对应，
+// 内容与缓存中相同的文件不重新解析。调用者需要持有锁
+func (s *Source) parseFiles() []*parsedFile {
+	files := make([]*parsedFile, len(s.files))
+	var g errgroup.Group
+	g.SetLimit(cmp.Or(s.concurrency, runtime.GOMAXPROCS(0)))
+	for i, entry := range s.files {
+		g.Go(func() error {
+			files[i] = s.parseEntry(entry)
+			return nil
+		})
+	}
+	g.Wait()
+	return files
+}
+
+// loadFileToBuilder 从文件加载翻译数据并合并到指定的 builder 中，调用者需要持有锁
+func (s *Source) loadFileToBuilder(b *catalog.Builder) error {
+	return s.loadParsed(b, s.parseFiles())
+}
+
+// loadParsed 将 parseFiles 解析的文件合并到指定的 builder 中。
 //
-// 这是一个内部辅助方法，遍历 Source 中配置的所有 Entry，
-// 使用对应的加载器将翻译数据加载到指定的 catalog.Builder 中。
+// 这是一个内部辅助方法，先按 conflicts 策略处理文件之间重复定义的消息，再按 Entry 的顺序写入指定的 catalog.Builder，
+// 问题日志的顺序也与逐个加载时相同。files 不会被修改，可以继续作为缓存使用。
 //
 // 错误处理策略：
 // - 单个文件加载失败不会影响其他文件的加载
 // - 错误会通过设置的日志函数记录（如果有的话）
-// - 此方法不会返回错误，继续处理剩余文件
+// - 所有文件处理完成后，记录到 errs 并返回合并后的错误
 //
 // 参数 b: 目标 catalog.Builder
+// 参数 files: parseFiles 的结果
 //
-// 注意：此方法是内部方法，不应该被外部调用
-func (s *Source) loadFileToBuilder(b *catalog.Builder) {
-	if len(s.entries) == 0 {
-		return
+// 注意：此方法是内部方法，调用者需要持有锁
+func (s *Source) loadParsed(b *catalog.Builder, files []*parsedFile) error {
+	files = slices.Clone(files)
+	for i, file := range files {
+		files[i] = file.clone()
 	}
+	conflicts := s.resolveConflicts(files)
 
-	// 遍历所有预配置的 entries
-	for _, entry := range s.entries {
-		if err := s.loadSingleFile(entry.file, entry.loader, b); err != nil {
+	s.errs = nil
+	var errs []error
+	for i, file := range files {
+		err := file.load(b, s.logFunc)
+		if err == nil {
+			err = conflicts[i]
+		}
+		if err != nil {
 			// 记录错误但继续处理其他文件
 			if s.logFunc != nil {
-				s.logFunc(fmt.Sprintf("Error loading translation file %s: %v", entry.file, err))
+				s.logFunc(fmt.Sprintf("Error loading translation file %s: %v", s.files[i].file, err))
 			}
-			continue
+			loadErr := &LoadError{File: s.files[i].file, Locale: s.locale, Err: err}
+			s.errs = append(s.errs, loadErr)
+			errs = append(errs, loadErr)
+		}
+	}
+	loadMessages(b, s.locale, s.messages)
+	return errors.Join(errs...)
+}
+
+// resolveConflicts 按 conflicts 策略处理已解析的文件之间重复定义的消息，
+// 冲突作为问题加入后加载的文件，返回与 files 一一对应的冲突错误
+func (s *Source) resolveConflicts(files []*parsedFile) []error {
+	var names []string
+	var messages [][]Message
+	var index []int
+	for i, file := range files {
+		if file.err == nil && file.parsed {
+			names = append(names, file.name)
+			messages = append(messages, file.messages)
+			index = append(index, i)
 		}
 	}
+
+	issues, conflictErrs := resolveConflicts(s.conflicts, s.locale, names, messages)
+	errs := make([]error, len(files))
+	for j, i := range index {
+		files[i].messages = messages[j]
+		errs[i] = conflictErrs[j]
+		if s.conflicts != ConflictError {
+			for _, issue := range issues[j] {
+				files[i].issues = append(files[i].issues, "Conflicting translation "+issue.String())
+			}
+		}
+	}
+	return errs
 }
 
 // loadSingleFile 加载单个翻译文件并处理错误。
@@ -195,10 +498,86 @@ func (s *Source) loadFileToBuilder(b *catalog.Builder) {
 //
 // 注意：此方法是内部方法，不应该被外部调用
 func (s *Source) loadSingleFile(filePath string, loader Loader, b *catalog.Builder) error {
-	data, err := os.ReadFile(filePath)
+	return s.loadEntry(Entry{file: filePath, loader: loader}, b)
+}
+
+// loadEntry 从磁盘或条目的文件系统读取翻译文件，并使用条目的加载器写入 builder
+func (s *Source) loadEntry(entry Entry, b *catalog.Builder) error {
+	return s.parseEntry(entry).load(b, s.logFunc)
+}
+
+// parseEntry 从磁盘或条目的文件系统读取并校验翻译文件，不访问 builder，可以并发调用。
+// 文件内容的 SHA-256 与缓存中相同时返回缓存的结果。
+func (s *Source) parseEntry(entry Entry) *parsedFile {
+	data, err := readFile(entry.fsys, entry.file)
 	if err != nil {
-		return fmt.Errorf("failed to read translation file %s: %w", filePath, err)
+		return &parsedFile{name: entry.file, err: fmt.Errorf("failed to read translation file %s: %w", entry.file, err)}
+	}
+	sum := sha256.Sum256(data)
+	if cached, ok := s.cache[entry.file]; ok && cached.sum == sum && cached.loader == entry.loader {
+		return cached
+	}
+	file := parseChecked(entry.file, data, entry.loader, s.locale)
+	file.sum, file.hashed = sum, true
+	return file
+}
+
+// parseMessages 重新读取并解析翻译源的所有文件和内存中的翻译，加载器未实现 MessageParser 的文件会被忽略。
+// 文件之间重复定义的消息与加载时一样按 conflicts 策略处理；单个文件失败不影响其他文件，所有错误合并后返回。
+func (s *Source) parseMessages() ([]Message, error) {
+	infos, err := s.parseMessageInfos()
+	messages := make([]Message, len(infos))
+	for i, info := range infos {
+		messages[i] = info.Message
+	}
+	return messages, err
+}
+
+// parseMessageInfos 与 parseMessages 相同，但同时返回每条消息所在的文件
+func (s *Source) parseMessageInfos() ([]MessageInfo, error) {
+	var names []string
+	var files [][]Message
+	var errs []error
+	for _, entry := range s.files {
+		parser, ok := entry.loader.(MessageParser)
+		if !ok {
+			continue
+		}
+		data, err := readFile(entry.fsys, entry.file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read translation file %s: %w", entry.file, err))
+			continue
+		}
+		parsed, err := parser.ParseMessages(entry.file, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, entry.file)
+		files = append(files, parsed)
 	}
 
-	return loader.LoadToBuilder(filePath, data, b, s.locale)
+	s.mu.Lock()
+	policy := s.conflicts
+	s.mu.Unlock()
+	resolveConflicts(policy, s.locale, names, files)
+
+	var infos []MessageInfo
+	for i, messages := range files {
+		for _, m := range messages {
+			infos = append(infos, MessageInfo{Message: m, Locale: s.locale, File: names[i]})
+		}
+	}
+	for _, m := range s.messages {
+		infos = append(infos, MessageInfo{Message: m, Locale: s.locale})
+	}
+	return infos, errors.Join(errs...)
+}
+
+// readFile 读取文件内容，fsys 为 nil 时从磁盘读取
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(fsys, name)
 }
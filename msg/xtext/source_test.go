@@ -3,9 +3,13 @@ package xtext
 import (
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"testing/fstest"
 
 	"go-slim.dev/infra/msg"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 	"golang.org/x/text/message/catalog"
 )
 
@@ -26,16 +30,16 @@ func TestNewSource(t *testing.T) {
 		t.Errorf("Source.locale = %q, want %q", string(source.locale), string(locale))
 	}
 
-	if len(source.entries) != len(entries) {
-		t.Errorf("Source.entries length = %d, want %d", len(source.entries), len(entries))
+	if len(source.files) != len(entries) {
+		t.Errorf("Source.files length = %d, want %d", len(source.files), len(entries))
 	}
 
 	for i, expected := range entries {
-		if source.entries[i].file != expected.file {
-			t.Errorf("Source.entries[%d].file = %q, want %q", i, source.entries[i].file, expected.file)
+		if source.files[i].file != expected.file {
+			t.Errorf("Source.files[%d].file = %q, want %q", i, source.files[i].file, expected.file)
 		}
-		if source.entries[i].loader != expected.loader {
-			t.Errorf("Source.entries[%d].loader mismatch", i)
+		if source.files[i].loader != expected.loader {
+			t.Errorf("Source.files[%d].loader mismatch", i)
 		}
 	}
 }
@@ -54,8 +58,8 @@ func TestNewSource_EmptyEntries(t *testing.T) {
 		t.Errorf("Source.locale = %q, want %q", string(source.locale), string(locale))
 	}
 
-	if len(source.entries) != 0 {
-		t.Errorf("Source.entries length = %d, want 0", len(source.entries))
+	if len(source.files) != 0 {
+		t.Errorf("Source.files length = %d, want 0", len(source.files))
 	}
 }
 
@@ -73,8 +77,8 @@ func TestNewSource_NilEntries(t *testing.T) {
 		t.Errorf("Source.locale = %q, want %q", string(source.locale), string(locale))
 	}
 
-	if source.entries != nil {
-		t.Error("Source.entries should be nil when created with nil entries")
+	if source.files != nil {
+		t.Error("Source.files should be nil when created with nil entries")
 	}
 }
 
@@ -167,11 +171,6 @@ func TestSource_Load(t *testing.T) {
 
 		// Load translations
 		source.Load(builder)
-
-		// Verify entries are cleared after load
-		if source.entries != nil {
-			t.Error("Source.entries should be nil after successful load")
-		}
 	})
 
 	t.Run("Load with errors", func(t *testing.T) {
@@ -197,11 +196,6 @@ func TestSource_Load(t *testing.T) {
 		if len(logMessages) == 0 {
 			t.Error("Expected log messages for failed file loads")
 		}
-
-		// Verify entries are still cleared after load
-		if source.entries != nil {
-			t.Error("Source.entries should be nil even after failed load")
-		}
 	})
 
 	t.Run("Multiple loads", func(t *testing.T) {
@@ -234,12 +228,13 @@ func TestSource_Load(t *testing.T) {
 		// Load first time
 		source.Load(builder1)
 
-		// Load second time (should be a no-op since entries are cleared)
+		// Load second time (files are read again)
 		source.Load(builder2)
 
-		// Both loads should complete without panic
-		if source.entries != nil {
-			t.Error("Source.entries should remain nil after second load")
+		for i, b := range []*catalog.Builder{builder1, builder2} {
+			if !hasMessage(b, language.English, "hello") {
+				t.Errorf("builder%d missing message %q", i+1, "hello")
+			}
 		}
 	})
 }
@@ -505,11 +500,6 @@ func TestSource_DifferentLoaders(t *testing.T) {
 
 	// Should successfully load both files with different loaders
 	source.Load(builder)
-
-	// Verify entries are cleared
-	if source.entries != nil {
-		t.Error("Source.entries should be nil after load with different loaders")
-	}
 }
 
 func TestSource_ConcurrentLoad(t *testing.T) {
@@ -547,11 +537,14 @@ func TestSource_ConcurrentLoad(t *testing.T) {
 	builder := catalog.NewBuilder()
 
 	// Load should complete successfully even if called concurrently
-	source.Load(builder)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() { source.Load(builder) })
+	}
+	wg.Wait()
 
-	// Verify entries are cleared
-	if source.entries != nil {
-		t.Error("Source.entries should be nil after load")
+	if !hasMessage(builder, language.English, "message") {
+		t.Errorf("builder missing message %q", "message")
 	}
 }
 
@@ -604,11 +597,6 @@ func TestSource_EdgeCases(t *testing.T) {
 
 		// Should handle large number of entries
 		source.Load(builder)
-
-		// Verify entries are cleared
-		if source.entries != nil {
-			t.Error("Source.entries should be nil after load with many entries")
-		}
 	})
 
 	t.Run("File path with special characters", func(t *testing.T) {
@@ -779,11 +767,6 @@ func TestSource_RealWorldScenario(t *testing.T) {
 	if len(logMessages) > 0 {
 		t.Errorf("Unexpected log messages: %v", logMessages)
 	}
-
-	// Verify entries are cleared
-	if source.entries != nil {
-		t.Error("Source.entries should be nil after successful load")
-	}
 }
 
 func TestSource_ErrorHandling(t *testing.T) {
@@ -811,11 +794,6 @@ func TestSource_ErrorHandling(t *testing.T) {
 		if len(logMessages) == 0 {
 			t.Error("Expected log message for loader error")
 		}
-
-		// Entries should still be cleared
-		if source.entries != nil {
-			t.Error("Source.entries should be nil even after loader error")
-		}
 	})
 
 	t.Run("File permission denied", func(t *testing.T) {
@@ -898,3 +876,104 @@ type mockError struct {
 func (e *mockError) Error() string {
 	return e.msg
 }
+
+func TestNewSourceFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/zh-CN/common.gotext.json": {Data: []byte(`{
+  "language": "zh-CN",
+  "messages": [{"id": "hello", "message": "hello", "translation": "你好"}]
+}`)},
+		"locales/zh-CN/ui.gotext.jsonc": {Data: []byte(`{
+  // comments are allowed
+  "language": "zh-CN",
+  "messages": [{"id": "bye", "message": "bye", "translation": "再见"}]
+}`)},
+		"locales/zh-CN/notes.txt": {Data: []byte("ignored")},
+	}
+
+	t.Run("Load matched files", func(t *testing.T) {
+		source, err := NewSourceFS(msg.Locale("zh-CN"), fsys, "locales/zh-CN/*", "locales/zh-CN/*.gotext.json")
+		if err != nil {
+			t.Fatalf("NewSourceFS error = %v", err)
+		}
+		if len(source.files) != 2 {
+			t.Fatalf("NewSourceFS() = %d entries, want 2", len(source.files))
+		}
+
+		var logs []string
+		source.SetLogFunc(func(s string) { logs = append(logs, s) })
+		builder := catalog.NewBuilder()
+		source.Load(builder)
+		if len(logs) > 0 {
+			t.Errorf("Load logged errors: %v", logs)
+		}
+
+		printer, err := NewPrinter(msg.Locale("zh-CN"), message.Catalog(builder))
+		if err != nil {
+			t.Fatalf("NewPrinter error = %v", err)
+		}
+		if got := printer.Sprintf("hello"); got != "你好" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "你好")
+		}
+		if got := printer.Sprintf("bye"); got != "再见" {
+			t.Errorf("Sprintf(bye) = %q, want %q", got, "再见")
+		}
+	})
+
+	t.Run("No matches", func(t *testing.T) {
+		if _, err := NewSourceFS(msg.Locale("en"), fsys, "locales/en/*"); err == nil {
+			t.Error("NewSourceFS should return error when pattern matches no files")
+		}
+	})
+
+	t.Run("Bad pattern", func(t *testing.T) {
+		if _, err := NewSourceFS(msg.Locale("en"), fsys, "[["); err == nil {
+			t.Error("NewSourceFS should return error for malformed pattern")
+		}
+	})
+}
+
+func TestSource_Catalog(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}]}`)},
+	}
+	source, err := NewSourceFS(msg.English, fsys)
+	if err != nil {
+		t.Fatalf("NewSourceFS() error = %v", err)
+	}
+
+	builder := catalog.NewBuilder()
+	catalogs := make([]*localCatalog, 8)
+	var wg sync.WaitGroup
+	for i := range catalogs {
+		wg.Go(func() {
+			source.Load(builder)
+			catalogs[i] = source.catalog()
+		})
+	}
+	wg.Wait()
+
+	if !hasMessage(builder, language.English, "hello") {
+		t.Errorf("builder missing message %q", "hello")
+	}
+	for i, c := range catalogs {
+		if c != catalogs[0] {
+			t.Errorf("catalog() #%d = %p, want the catalog loaded once %p", i, c, catalogs[0])
+		}
+	}
+
+	old := source.catalog()
+	source.reload()
+	if source.catalog() != old {
+		t.Error("reload() replaced the catalog although no file changed")
+	}
+
+	fsys["en.gotext.json"] = &fstest.MapFile{Data: []byte(`{"language": "en", "messages": [{"id": "hello", "translation": "Hello"}, {"id": "bye", "translation": "Bye"}]}`)}
+	source.reload()
+	if source.catalog() == old {
+		t.Error("reload() did not replace the catalog")
+	}
+	if !hasMessage(source.catalog().builder, language.English, "bye") {
+		t.Errorf("reloaded catalog missing message %q", "bye")
+	}
+}
@@ -0,0 +1,229 @@
+package xtext
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// DefaultSQLQuery SQLSource 默认使用的查询
+const DefaultSQLQuery = "SELECT locale, id, translation FROM translations"
+
+// Queryer 执行查询的数据库连接，*sql.DB、*sql.Conn 和 *sql.Tx 都实现了此接口。
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// SQLConfig 数据库翻译源的配置
+type SQLConfig struct {
+	// DB 执行查询的数据库连接。使用 gorm 时可以通过 gormDB.DB() 获取 *sql.DB
+	DB Queryer
+
+	// Query 查询翻译的 SQL，默认为 DefaultSQLQuery。
+	// 结果的前三列依次为语言、消息 ID 和译文，可以返回第四列布尔值表示是否需要校对（fuzzy），
+	// 列名不限，可以通过别名适配已有的表结构。
+	Query string
+
+	// Args 查询参数（可选），如只查询已发布的翻译
+	Args []any
+
+	// Timeout 单次查询的超时时间，默认为 30 秒
+	Timeout time.Duration
+
+	// Interval 后台刷新的间隔，默认为 5 分钟
+	Interval time.Duration
+
+	// LogFunc 记录刷新失败等信息的日志函数（可选）
+	LogFunc msg.LogFunc
+}
+
+// SQLSource 从数据库表加载翻译的翻译源，适用于在管理后台而非文件中维护文案的场景。
+//
+// 特点：
+// 1. 多语言：一个 SQLSource 包含查询结果中的所有语言，新增的语言在刷新后自动可用
+// 2. 变化检测：每次查询计算结果的摘要，内容未变化时不会重新加载
+// 3. 失败回退：查询失败或结果中有无效的语言时保留最近一次成功的快照
+//
+// 使用示例：
+//
+//	source := xtext.NewSQLSource(xtext.SQLConfig{
+//	    DB:       db,
+//	    Query:    "SELECT lang, msg_key, text, needs_review FROM copy WHERE published = ?",
+//	    Args:     []any{true},
+//	    Interval: time.Minute,
+//	})
+//	if _, err := source.Fetch(ctx); err != nil {
+//	    log.Printf("initial translation load failed: %v", err)
+//	}
+//	factory.AddSQLSource(source)
+//	source.Start(ctx)
+type SQLSource struct {
+	refresher
+	config SQLConfig
+
+	digest   [sha256.Size]byte        // 最近一次成功查询结果的摘要
+	snapshot map[msg.Locale][]Message // 最近一次成功查询的结果
+}
+
+// NewSQLSource 创建数据库翻译源。创建后不会立即查询，需要调用 Fetch 或 Start。
+func NewSQLSource(config SQLConfig) *SQLSource {
+	config.Query = cmp.Or(config.Query, DefaultSQLQuery)
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	s := &SQLSource{config: config}
+	s.refresher = refresher{fetch: s.Fetch, interval: config.Interval, logFunc: config.LogFunc}
+	return s
+}
+
+// Locales 返回当前快照包含的语言，按字典序排列
+func (s *SQLSource) Locales() msg.LocaleSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.snapshot))
+}
+
+// Fetch 查询数据库中的翻译，返回内容是否发生了变化。
+//
+// 查询失败、结果无法解析或包含无效的语言时返回错误并保留原有快照。
+// 内容变化时通知通过 AddSQLSource 注册的工厂加载新的翻译。
+func (s *SQLSource) Fetch(ctx context.Context) (bool, error) {
+	if s.config.DB == nil {
+		return false, errors.New("sql translation source has no database")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	snapshot, digest, err := s.query(ctx)
+	if err != nil {
+		return false, err
+	}
+	for locale := range snapshot {
+		if _, err := language.Parse(string(locale)); err != nil {
+			return false, fmt.Errorf("invalid translation locale %q in database: %w", locale, err)
+		}
+	}
+
+	return s.update(func() bool {
+		if digest == s.digest && s.snapshot != nil {
+			return false
+		}
+		s.digest = digest
+		s.snapshot = snapshot
+		return true
+	}), nil
+}
+
+// query 执行查询，返回按语言分组的消息和结果的摘要
+func (s *SQLSource) query(ctx context.Context) (map[msg.Locale][]Message, [sha256.Size]byte, error) {
+	var digest [sha256.Size]byte
+
+	rows, err := s.config.DB.QueryContext(ctx, s.config.Query, s.config.Args...)
+	if err != nil {
+		return nil, digest, fmt.Errorf("failed to query translations: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, digest, fmt.Errorf("failed to query translations: %w", err)
+	}
+	if len(columns) < 3 {
+		return nil, digest, fmt.Errorf("translation query must return at least 3 columns (locale, id, translation), got %d", len(columns))
+	}
+
+	snapshot := make(map[msg.Locale][]Message)
+	for rows.Next() {
+		var (
+			locale, id  string
+			translation sql.NullString
+			fuzzy       sql.NullBool
+			dest        = []any{&locale, &id, &translation}
+		)
+		if len(columns) > 3 {
+			dest = append(dest, &fuzzy)
+		}
+		for range len(columns) - len(dest) {
+			dest = append(dest, new(sql.RawBytes)) // 忽略多余的列
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, digest, fmt.Errorf("failed to scan translation row: %w", err)
+		}
+
+		l := msg.Locale(locale)
+		snapshot[l] = append(snapshot[l], Message{ID: id, Translation: translation.String, Fuzzy: fuzzy.Bool})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, digest, fmt.Errorf("failed to query translations: %w", err)
+	}
+
+	// 摘要与行的顺序无关
+	h := sha256.New()
+	for _, locale := range slices.Sorted(maps.Keys(snapshot)) {
+		messages := snapshot[locale]
+		slices.SortStableFunc(messages, func(a, b Message) int { return cmp.Compare(a.ID, b.ID) })
+		for _, m := range messages {
+			fmt.Fprintf(h, "%q\x00%q\x00%q\x00%t\n", locale, m.ID, m.Translation, m.Fuzzy)
+		}
+	}
+	h.Sum(digest[:0])
+	return snapshot, digest, nil
+}
+
+// Load 将当前快照加载到指定的 catalog.Builder 中，没有快照时不做任何处理。
+// 与文件一样会校验每条消息，有问题的消息被跳过并记录日志。
+// 同名的消息会被覆盖，数据库中删除的消息在重新 Reset 之前仍然保留。
+func (s *SQLSource) Load(b *catalog.Builder) {
+	for _, c := range s.catalogs() {
+		skipped := make(map[string]bool)
+		for _, issue := range ValidateMessages(c.name, c.locale, c.messages) {
+			if issue.blocking() {
+				skipped[issue.ID] = true
+			}
+			s.log("Invalid translation " + issue.String())
+		}
+		loadMessages(b, c.locale, slices.DeleteFunc(slices.Clone(c.messages), func(m Message) bool {
+			return skipped[m.ID]
+		}))
+	}
+}
+
+// AddSQLSource 添加数据库翻译源。
+//
+// 与 AddRemoteSource 相同，当前快照会立即加载到工厂中，之后每次刷新得到新内容时
+// 自动重新加载，查询结果中新增的语言也会加入支持的语言集合。
+// 数据库翻译源在 Reset 后仍然保留，并在本地文件之后加载，同名消息以数据库内容为准。
+func (f *PrinterFactory) AddSQLSource(s *SQLSource) {
+	f.addDynamicSource(s)
+}
+
+// locales 实现 dynamicSource 接口
+func (s *SQLSource) locales() []msg.Locale {
+	return s.Locales()
+}
+
+// catalogs 实现 dynamicSource 接口
+func (s *SQLSource) catalogs() []sourceCatalog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]sourceCatalog, 0, len(s.snapshot))
+	for _, locale := range slices.Sorted(maps.Keys(s.snapshot)) {
+		result = append(result, sourceCatalog{name: "sql:" + string(locale), locale: locale, messages: s.snapshot[locale]})
+	}
+	return result
+}
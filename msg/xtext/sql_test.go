@@ -0,0 +1,179 @@
+package xtext
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+)
+
+// translationTable 模拟保存翻译的数据库表，实现 database/sql/driver 接口
+type translationTable struct {
+	mu      sync.Mutex
+	columns []string
+	rows    [][]driver.Value
+	err     error
+}
+
+func (t *translationTable) set(columns []string, rows ...[]driver.Value) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.columns, t.rows, t.err = columns, rows, nil
+}
+
+func (t *translationTable) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.err = err
+}
+
+func (t *translationTable) Open(string) (driver.Conn, error) { return &tableConn{t}, nil }
+func (t *translationTable) Connect(context.Context) (driver.Conn, error) {
+	return &tableConn{t}, nil
+}
+func (t *translationTable) Driver() driver.Driver { return t }
+
+type tableConn struct{ table *translationTable }
+
+func (c *tableConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *tableConn) Close() error                        { return nil }
+func (c *tableConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *tableConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.table.mu.Lock()
+	defer c.table.mu.Unlock()
+	if c.table.err != nil {
+		return nil, c.table.err
+	}
+	return &tableRows{columns: c.table.columns, rows: c.table.rows}, nil
+}
+
+type tableRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *tableRows) Columns() []string { return r.columns }
+func (r *tableRows) Close() error      { return nil }
+
+func (r *tableRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var translationColumns = []string{"locale", "id", "translation"}
+
+func TestSQLSource(t *testing.T) {
+	table := &translationTable{}
+	table.set(translationColumns,
+		[]driver.Value{"zh-CN", "hello", "你好"},
+		[]driver.Value{"zh-CN", "bye", "再见"},
+	)
+	db := sql.OpenDB(table)
+	defer db.Close()
+
+	var logs []string
+	source := NewSQLSource(SQLConfig{DB: db, LogFunc: func(s string) { logs = append(logs, s) }})
+	factory := NewPrinterFactory()
+	factory.AddSQLSource(source)
+
+	ctx := context.Background()
+	if changed, err := source.Fetch(ctx); err != nil || !changed {
+		t.Fatalf("Fetch() = %v, %v, want true, nil", changed, err)
+	}
+	if !factory.SupportsLocale(msg.Locale("zh-CN")) {
+		t.Fatal("Factory should support the database locale")
+	}
+
+	printer, err := factory.CreatePrinter(msg.Locale("zh-CN"))
+	if err != nil {
+		t.Fatalf("CreatePrinter error = %v", err)
+	}
+	if got := printer.Sprintf("hello"); got != "你好" {
+		t.Errorf("Sprintf(hello) = %q, want %q", got, "你好")
+	}
+
+	t.Run("Not modified", func(t *testing.T) {
+		// 行的顺序不影响变化检测
+		table.set(translationColumns,
+			[]driver.Value{"zh-CN", "bye", "再见"},
+			[]driver.Value{"zh-CN", "hello", "你好"},
+		)
+		if changed, err := source.Fetch(ctx); err != nil || changed {
+			t.Errorf("Fetch() = %v, %v, want false, nil", changed, err)
+		}
+	})
+
+	t.Run("Updated", func(t *testing.T) {
+		table.set(append(translationColumns, "fuzzy"),
+			[]driver.Value{"zh-CN", "hello", "您好", false},
+			[]driver.Value{"ja", "hello", "こんにちは", true},
+		)
+		if changed, err := source.Fetch(ctx); err != nil || !changed {
+			t.Fatalf("Fetch() = %v, %v, want true, nil", changed, err)
+		}
+		if got := printer.Sprintf("hello"); got != "您好" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "您好")
+		}
+		if !factory.SupportsLocale(msg.Locale("ja")) {
+			t.Error("Factory should support the locale added to the database")
+		}
+		if got := source.Locales(); len(got) != 2 || got[0] != "ja" || got[1] != "zh-CN" {
+			t.Errorf("Locales() = %v, want [ja zh-CN]", got)
+		}
+	})
+
+	t.Run("Failure keeps last snapshot", func(t *testing.T) {
+		table.fail(errors.New("connection refused"))
+		if _, err := source.Fetch(ctx); err == nil {
+			t.Error("Fetch should return error for query failure")
+		}
+		table.set(translationColumns, []driver.Value{"not a locale!", "hello", "?"})
+		if _, err := source.Fetch(ctx); err == nil {
+			t.Error("Fetch should return error for invalid locale")
+		}
+		table.set([]string{"id", "translation"}, []driver.Value{"hello", "?"})
+		if _, err := source.Fetch(ctx); err == nil {
+			t.Error("Fetch should return error for missing columns")
+		}
+		if got := printer.Sprintf("hello"); got != "您好" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "您好")
+		}
+	})
+
+	t.Run("Invalid message skipped", func(t *testing.T) {
+		table.set(translationColumns,
+			[]driver.Value{"zh-CN", "hello", "你好"},
+			[]driver.Value{"zh-CN", "Hello %s", "你好 {"},
+		)
+		if _, err := source.Fetch(ctx); err != nil {
+			t.Fatalf("Fetch error = %v", err)
+		}
+		if got := printer.Sprintf("hello"); got != "你好" {
+			t.Errorf("Sprintf(hello) = %q, want %q", got, "你好")
+		}
+		if len(logs) == 0 {
+			t.Error("Invalid message should be logged")
+		}
+	})
+
+	t.Run("Survives reset", func(t *testing.T) {
+		factory.Reset("")
+		p, err := factory.CreatePrinter(msg.Locale("zh-CN"))
+		if err != nil {
+			t.Fatalf("CreatePrinter error = %v", err)
+		}
+		if got := p.Sprintf("hello"); got != "你好" {
+			t.Errorf("Sprintf(hello) after Reset = %q, want %q", got, "你好")
+		}
+	})
+}
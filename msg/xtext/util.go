@@ -2,7 +2,9 @@ package xtext
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 
 	"go-slim.dev/infra/msg"
 )
@@ -46,10 +48,21 @@ func parseBaseLocale(s string) (msg.Locale, bool) {
 // ScanDirectoryForEntries 扫描目录中的所有 gotext 翻译文件，返回 Entry 列表。
 // 忽略子目录，只处理 .gotext.json 和 .gotext.jsonc 文件。
 func ScanDirectoryForEntries(dirPath string, loaders *LoaderRegistry) []Entry {
+	return scanEntries(nil, dirPath, loaders)
+}
+
+// ScanFSForEntries 扫描 fs.FS 中指定目录的所有翻译文件，返回 Entry 列表，
+// 规则与 ScanDirectoryForEntries 相同。dirPath 使用 fs.FS 的路径格式，根目录为 "."。
+func ScanFSForEntries(fsys fs.FS, dirPath string, loaders *LoaderRegistry) []Entry {
+	return scanEntries(fsys, dirPath, loaders)
+}
+
+// scanEntries 扫描目录中加载器支持的文件，fsys 为 nil 时扫描磁盘目录
+func scanEntries(fsys fs.FS, dirPath string, loaders *LoaderRegistry) []Entry {
 	var entries []Entry
 
 	// 读取目录内容
-	dirEntries, err := os.ReadDir(dirPath)
+	dirEntries, err := readDir(fsys, dirPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to read directory %s: %v\n", dirPath, err)
 		return entries
@@ -68,7 +81,7 @@ func ScanDirectoryForEntries(dirPath string, loaders *LoaderRegistry) []Entry {
 		}
 
 		// 构建文件完整路径
-		filePath := dirPath + "/" + entry.Name()
+		filePath := joinPath(fsys, dirPath, entry.Name())
 
 		// 检查是否有支持的加载器
 		loader, ok := loaders.GetLoaderForFile(filePath)
@@ -76,9 +89,26 @@ func ScanDirectoryForEntries(dirPath string, loaders *LoaderRegistry) []Entry {
 			entries = append(entries, Entry{
 				file:   filePath,
 				loader: loader,
+				fsys:   fsys,
 			})
 		}
 	}
 
 	return entries
 }
+
+// readDir 读取目录内容，fsys 为 nil 时读取磁盘目录
+func readDir(fsys fs.FS, dirPath string) ([]fs.DirEntry, error) {
+	if fsys == nil {
+		return os.ReadDir(dirPath)
+	}
+	return fs.ReadDir(fsys, dirPath)
+}
+
+// joinPath 拼接目录和文件名，fs.FS 的路径不能以 "./" 开头
+func joinPath(fsys fs.FS, dirPath, name string) string {
+	if fsys == nil {
+		return dirPath + "/" + name
+	}
+	return path.Join(dirPath, name)
+}
@@ -0,0 +1,317 @@
+package xtext
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// IssueKind 翻译目录问题的类型
+type IssueKind string
+
+const (
+	// IssueInvalidLocale 语言标识无法解析为 BCP 47 标签，整个文件不会被加载
+	IssueInvalidLocale IssueKind = "invalid-locale"
+	// IssueParseError 文件无法读取或解析，整个文件不会被加载
+	IssueParseError IssueKind = "parse-error"
+	// IssueDuplicateID 同一文件中消息 ID 重复，加载时以最后一个为准
+	IssueDuplicateID IssueKind = "duplicate-id"
+	// IssueVerbMismatch 译文与原文的格式化动词不一致，该消息不会被加载
+	IssueVerbMismatch IssueKind = "verb-mismatch"
+	// IssueUnbalancedBraces 译文的 ICU 花括号不配对，该消息不会被加载
+	IssueUnbalancedBraces IssueKind = "unbalanced-braces"
+	// IssueConflict 同一语言的多个文件定义了同一消息，加载时按 ConflictPolicy 选择译文
+	IssueConflict IssueKind = "conflict"
+)
+
+// Issue 是校验翻译目录时发现的问题。
+type Issue struct {
+	Kind   IssueKind  // 问题类型
+	File   string     // 文件路径或远程翻译源的地址
+	Locale msg.Locale // 翻译源的语言
+	ID     string     // 消息 ID，文件级的问题为空
+	Detail string     // 问题描述
+}
+
+// String 返回如 `zh-CN.gotext.json: "%d files": verb-mismatch: ...` 的描述
+func (i Issue) String() string {
+	if i.ID == "" {
+		return fmt.Sprintf("%s: %s: %s", i.File, i.Kind, i.Detail)
+	}
+	return fmt.Sprintf("%s: %q: %s: %s", i.File, i.ID, i.Kind, i.Detail)
+}
+
+// blocking 报告问题是否会导致消息或文件不被加载
+func (i Issue) blocking() bool {
+	return i.Kind != IssueDuplicateID && i.Kind != IssueConflict
+}
+
+// ValidateMessages 校验一个翻译文件中的消息。
+//
+// 检查以下问题：
+//   - locale 无法解析为 BCP 47 标签
+//   - 消息 ID 重复
+//   - 译文与原文（原文为空时使用 ID）的格式化动词不一致，
+//     按参数位置比较，因此译文可以使用 %[2]s 这样的显式索引调整参数顺序；
+//     原文不含格式化动词时视为翻译键（如 "greeting.welcome"），不做比较
+//   - 译文的 ICU 花括号不配对，单引号包围的花括号视为字面量
+//
+// 未翻译的消息只检查 ID 是否重复。
+func ValidateMessages(file string, locale msg.Locale, messages []Message) []Issue {
+	var issues []Issue
+	add := func(kind IssueKind, id, detail string) {
+		issues = append(issues, Issue{Kind: kind, File: file, Locale: locale, ID: id, Detail: detail})
+	}
+
+	if _, err := language.Parse(string(locale)); err != nil {
+		add(IssueInvalidLocale, "", err.Error())
+	}
+
+	seen := make(map[string]bool, len(messages))
+	for _, m := range messages {
+		if seen[m.ID] {
+			add(IssueDuplicateID, m.ID, "message is defined more than once")
+		}
+		seen[m.ID] = true
+
+		if m.Translation == "" {
+			continue
+		}
+		if !bracesBalanced(m.Translation) {
+			add(IssueUnbalancedBraces, m.ID, fmt.Sprintf("translation %q has unbalanced braces", m.Translation))
+		}
+		// 原文不含动词时通常是 "greeting.welcome" 这样的翻译键，无法比较
+		want := formatVerbs(cmp.Or(m.Source, m.ID))
+		if len(want) == 0 {
+			continue
+		}
+		if got := formatVerbs(m.Translation); !maps.Equal(want, got) {
+			add(IssueVerbMismatch, m.ID, fmt.Sprintf("translation uses %s, source uses %s", describeVerbs(got), describeVerbs(want)))
+		}
+	}
+	return issues
+}
+
+// Validate 校验工厂中所有翻译源的文件和远程、数据库翻译源的当前快照，返回发现的问题。
+//
+// 除了 ValidateMessages 检查的问题，还报告同一语言的多个文件之间重复定义的消息（conflict）。
+// 加载器未实现 MessageParser 的文件不参与校验。加载翻译时会进行同样的校验：
+// 有问题的消息不会被加载并记录日志，查找时回退到回退链中的其他语言。
+//
+// 示例：
+//
+//	for _, issue := range factory.Validate() {
+//	    log.Println(issue)
+//	}
+func (f *PrinterFactory) Validate() []Issue {
+	f.mu.RLock()
+	sources := slices.Clone(f.sources)
+	dynamic := slices.Clone(f.dynamic)
+	f.mu.RUnlock()
+
+	var issues []Issue
+	for _, s := range sources {
+		var names []string
+		var parsed [][]Message
+		for _, entry := range s.files {
+			parser, ok := entry.loader.(MessageParser)
+			if !ok {
+				continue
+			}
+			data, err := readFile(entry.fsys, entry.file)
+			if err != nil {
+				issues = append(issues, Issue{Kind: IssueParseError, File: entry.file, Locale: s.locale, Detail: err.Error()})
+				continue
+			}
+			messages, err := parser.ParseMessages(entry.file, data)
+			if err != nil {
+				issues = append(issues, Issue{Kind: IssueParseError, File: entry.file, Locale: s.locale, Detail: err.Error()})
+				continue
+			}
+			issues = append(issues, ValidateMessages(entry.file, s.locale, messages)...)
+			names = append(names, entry.file)
+			parsed = append(parsed, messages)
+		}
+		for _, conflicts := range findConflicts(s.locale, names, parsed) {
+			issues = append(issues, conflicts...)
+		}
+	}
+	for _, d := range dynamic {
+		for _, c := range d.catalogs() {
+			if c.err != nil {
+				issues = append(issues, Issue{Kind: IssueParseError, File: c.name, Locale: c.locale, Detail: c.err.Error()})
+				continue
+			}
+			issues = append(issues, ValidateMessages(c.name, c.locale, c.messages)...)
+		}
+	}
+	return issues
+}
+
+// loadChecked 校验并加载翻译文件。
+//
+// 加载器实现了 MessageParser 时，先校验消息并通过 log 报告问题，
+// 语言无效时不加载整个文件，有问题的消息被跳过；否则直接使用 LoadToBuilder 加载。
+func loadChecked(name string, data []byte, loader Loader, b *catalog.Builder, locale msg.Locale, log func(string)) error {
+	return parseChecked(name, data, loader, locale).load(b, log)
+}
+
+// parsedFile 是已经解析和校验、等待写入 builder 的翻译文件
+type parsedFile struct {
+	name     string
+	data     []byte
+	loader   Loader
+	locale   msg.Locale
+	parsed   bool              // 加载器实现了 MessageParser，messages 为校验通过的消息
+	messages []Message         // 校验通过的消息
+	issues   []string          // 校验发现的问题，写入时记录日志
+	err      error             // 读取、解析失败或语言无效
+	sum      [sha256.Size]byte // 文件内容的 SHA-256
+	hashed   bool              // sum 有效，文件可以缓存
+}
+
+// clone 返回可以修改 messages 和 issues 的副本
+func (f *parsedFile) clone() *parsedFile {
+	c := *f
+	c.messages = slices.Clone(f.messages)
+	c.issues = slices.Clone(f.issues)
+	return &c
+}
+
+// parseChecked 解析并校验翻译文件，不访问 builder，可以在多个 goroutine 中并发调用
+func parseChecked(name string, data []byte, loader Loader, locale msg.Locale) *parsedFile {
+	file := &parsedFile{name: name, data: data, loader: loader, locale: locale}
+	parser, ok := loader.(MessageParser)
+	if !ok {
+		return file
+	}
+
+	messages, err := parser.ParseMessages(name, data)
+	if err != nil {
+		file.err = err
+		return file
+	}
+
+	skipped := make(map[string]bool)
+	for _, issue := range ValidateMessages(name, locale, messages) {
+		if issue.Kind == IssueInvalidLocale {
+			file.err = fmt.Errorf("invalid locale %q for translation file %s: %s", locale, name, issue.Detail)
+			return file
+		}
+		if issue.blocking() {
+			skipped[issue.ID] = true
+		}
+		file.issues = append(file.issues, "Invalid translation "+issue.String())
+	}
+
+	file.parsed = true
+	file.messages = slices.DeleteFunc(messages, func(m Message) bool { return skipped[m.ID] })
+	return file
+}
+
+// load 通过 log 报告校验发现的问题，然后将文件写入 builder
+func (f *parsedFile) load(b *catalog.Builder, log func(string)) error {
+	if log != nil {
+		for _, issue := range f.issues {
+			log(issue)
+		}
+	}
+	switch {
+	case f.err != nil:
+		return f.err
+	case !f.parsed:
+		return f.loader.LoadToBuilder(f.name, f.data, b, f.locale)
+	}
+	loadMessages(b, f.locale, f.messages)
+	return nil
+}
+
+// formatVerbs 解析格式字符串中的格式化动词，返回参数序号（从 1 开始）到动词的映射。
+// 与 fmt 一致，%[n] 设置之后参数的序号，宽度或精度为 * 时同样占用一个参数。
+func formatVerbs(format string) map[int]rune {
+	verbs := make(map[int]rune)
+	arg := 1
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue // 字面量 %
+		}
+	spec:
+		for ; i < len(format); i++ {
+			switch c := format[i]; {
+			case c == '[':
+				end := strings.IndexByte(format[i:], ']')
+				if end < 0 {
+					break spec
+				}
+				if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil && n > 0 {
+					arg = n
+				}
+				i += end
+			case c == '*':
+				verbs[arg] = '*'
+				arg++
+			case strings.IndexByte("+-# 0.123456789", c) >= 0:
+				// 标志、宽度和精度
+			default:
+				verbs[arg] = rune(c)
+				arg++
+				break spec
+			}
+		}
+	}
+	return verbs
+}
+
+// describeVerbs 按参数序号描述格式化动词，如 "%[1]d %[2]s"
+func describeVerbs(verbs map[int]rune) string {
+	if len(verbs) == 0 {
+		return "no verbs"
+	}
+	parts := make([]string, 0, len(verbs))
+	for _, n := range slices.Sorted(maps.Keys(verbs)) {
+		parts = append(parts, fmt.Sprintf("%%[%d]%c", n, verbs[n]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// bracesBalanced 检查 ICU 消息的花括号是否配对。
+// 单引号后紧跟花括号时开始引用，直到下一个单引号，两个连续的单引号表示单引号字面量。
+func bracesBalanced(s string) bool {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			if i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			if i+1 < len(s) && (s[i+1] == '{' || s[i+1] == '}') {
+				end := strings.IndexByte(s[i+1:], '\'')
+				if end < 0 {
+					return depth == 0 // 引用持续到字符串末尾
+				}
+				i += end + 1
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
@@ -0,0 +1,112 @@
+package xtext
+
+import (
+	"maps"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestFormatVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   map[int]rune
+	}{
+		{"Hello", map[int]rune{}},
+		{"100%% done", map[int]rune{}},
+		{"%s has %d files", map[int]rune{1: 's', 2: 'd'}},
+		{"%[2]d files in %[1]s", map[int]rune{1: 's', 2: 'd'}},
+		{"%-10s|%6.2f", map[int]rune{1: 's', 2: 'f'}},
+		{"%*d", map[int]rune{1: '*', 2: 'd'}},
+	}
+	for _, tt := range tests {
+		if got := formatVerbs(tt.format); !maps.Equal(got, tt.want) {
+			t.Errorf("formatVerbs(%q) = %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestBracesBalanced(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"Hello", true},
+		{"{count, plural, one {# file} other {# files}}", true},
+		{"{count, plural, one {# file} other {# files}", false},
+		{"}{", false},
+		{"Use '{' to open", true},
+		{"It''s {name}", true},
+	}
+	for _, tt := range tests {
+		if got := bracesBalanced(tt.s); got != tt.want {
+			t.Errorf("bracesBalanced(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	messages := []Message{
+		{ID: "%s has %d files", Translation: "%s 有 %d 个文件"},
+		{ID: "%s has %d files", Translation: "%s 有 %d 个文件"},
+		{ID: "Hello %s", Translation: "你好 %d"},
+		{ID: "reordered", Source: "%s has %d files", Translation: "%[2]d 个文件属于 %[1]s"},
+		{ID: "greeting.welcome", Translation: "欢迎 %s"},
+		{ID: "braces", Translation: "{count, plural, one {# 个}"},
+		{ID: "untranslated %d"},
+	}
+
+	issues := ValidateMessages("zh.gotext.json", msg.Locale("zh"), messages)
+	want := map[string]IssueKind{
+		"%s has %d files": IssueDuplicateID,
+		"Hello %s":        IssueVerbMismatch,
+		"braces":          IssueUnbalancedBraces,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("ValidateMessages() = %v, want %d issues", issues, len(want))
+	}
+	for _, issue := range issues {
+		if want[issue.ID] != issue.Kind {
+			t.Errorf("unexpected issue %v", issue)
+		}
+	}
+
+	issues = ValidateMessages("x.gotext.json", msg.Locale("not_a_locale!"), nil)
+	if len(issues) != 1 || issues[0].Kind != IssueInvalidLocale {
+		t.Errorf("ValidateMessages(invalid locale) = %v", issues)
+	}
+}
+
+func TestPrinterFactory_Validate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.gotext.json": {Data: []byte(`{"language": "en", "messages": [{"id": "%d files", "translation": "%d files"}]}`)},
+		"zh.gotext.json": {Data: []byte(`{"language": "zh", "messages": [
+			{"id": "%d files", "translation": "%s 个文件"},
+			{"id": "hello", "translation": "你好"}
+		]}`)},
+	}
+	var logs []string
+	factory := NewPrinterFactory(BaseFS(fsys), Fallbacks(msg.English), LogFunc(func(s string) { logs = append(logs, s) }))
+
+	issues := factory.Validate()
+	if len(issues) != 1 || issues[0].Kind != IssueVerbMismatch || issues[0].File != "zh.gotext.json" {
+		t.Fatalf("Validate() = %v, want one verb mismatch in zh.gotext.json", issues)
+	}
+
+	// 有问题的消息不会被加载，回退到英语
+	printer, err := factory.CreatePrinter(msg.Chinese)
+	if err != nil {
+		t.Fatalf("CreatePrinter() error = %v", err)
+	}
+	if got := printer.Sprintf("%d files", 3); got != "3 files" {
+		t.Errorf("Sprintf() = %q, want %q", got, "3 files")
+	}
+	if got := printer.Sprintf("hello"); got != "你好" {
+		t.Errorf("Sprintf(hello) = %q, want %q", got, "你好")
+	}
+	if len(logs) != 1 || !strings.Contains(logs[0], "verb-mismatch") {
+		t.Errorf("logs = %v, want one verb mismatch", logs)
+	}
+}
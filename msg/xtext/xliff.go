@@ -0,0 +1,357 @@
+package xtext
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/jsonc"
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/message/catalog"
+)
+
+// XLIFF 版本
+const (
+	XLIFF12 = "1.2"
+	XLIFF20 = "2.0"
+)
+
+// Message 表示翻译目录中的一条消息，用于在 gotext 和 XLIFF 等格式之间转换。
+type Message struct {
+	ID             string // 消息标识，即翻译键
+	Source         string // 源语言文本
+	Translation    string // 译文，未翻译时为空
+	Note           string // 给译者的说明
+	TranslatorNote string // 译者留下的备注
+	Context        string // 消息的上下文或含义，用于区分相同文本的不同用法
+	Position       string // 消息在源代码中的位置，如 "cmd/server/main.go:42:13"
+	Fuzzy          bool   // 译文需要校对
+}
+
+// MessageFile 表示一种语言的翻译目录。
+type MessageFile struct {
+	SourceLanguage msg.Locale // 源语言
+	Language       msg.Locale // 目标语言
+	Messages       []Message
+}
+
+// ParseGotext 解析 gotext JSON 格式的翻译文件（支持 JSONC 注释）。
+//
+// 消息的 comment 字段对应 Note，translatorComment 字段对应 TranslatorNote，
+// meaning 字段对应 Context，position 字段对应 Position，与 golang.org/x/text/message/pipeline 的字段一致。
+func ParseGotext(data []byte) (*MessageFile, error) {
+	var content struct {
+		Language string `json:"language"`
+		Messages []struct {
+			ID          string `json:"id"`
+			Message     string `json:"message"`
+			Translation string `json:"translation"`
+			Comment           string `json:"comment"`
+			TranslatorComment string `json:"translatorComment"`
+			Meaning           string `json:"meaning"`
+			Position          string `json:"position"`
+			Fuzzy             bool   `json:"fuzzy"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(jsonc.ToJSON(data), &content); err != nil {
+		return nil, fmt.Errorf("failed to parse gotext file: %w", err)
+	}
+
+	file := &MessageFile{Language: msg.Locale(content.Language)}
+	for _, m := range content.Messages {
+		file.Messages = append(file.Messages, Message{
+			ID:             m.ID,
+			Source:         m.Message,
+			Translation:    m.Translation,
+			Note:           m.Comment,
+			TranslatorNote: m.TranslatorComment,
+			Context:        m.Meaning,
+			Position:       m.Position,
+			Fuzzy:          m.Fuzzy,
+		})
+	}
+	return file, nil
+}
+
+// xliff12 XLIFF 1.2 文档结构
+type xliff12 struct {
+	XMLName xml.Name `xml:"xliff"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Version string   `xml:"version,attr"`
+	File    struct {
+		SourceLanguage string        `xml:"source-language,attr"`
+		TargetLanguage string        `xml:"target-language,attr,omitempty"`
+		Datatype       string        `xml:"datatype,attr"`
+		Original       string        `xml:"original,attr"`
+		Units          []xliff12Unit `xml:"body>trans-unit"`
+	} `xml:"file"`
+}
+
+type xliff12Unit struct {
+	ID           string               `xml:"id,attr"`
+	ResName      string               `xml:"resname,attr,omitempty"`
+	Source       string               `xml:"source"`
+	Target       string               `xml:"target,omitempty"`
+	Note         string               `xml:"note,omitempty"`
+	ContextGroup *xliff12ContextGroup `xml:"context-group,omitempty"`
+}
+
+type xliff12ContextGroup struct {
+	Purpose string           `xml:"purpose,attr,omitempty"`
+	Context []xliff12Context `xml:"context"`
+}
+
+type xliff12Context struct {
+	Type  string `xml:"context-type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// xliff20 XLIFF 2.0 文档结构
+type xliff20 struct {
+	XMLName xml.Name `xml:"xliff"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Version string   `xml:"version,attr"`
+	SrcLang string   `xml:"srcLang,attr"`
+	TrgLang string   `xml:"trgLang,attr,omitempty"`
+	File    struct {
+		ID    string        `xml:"id,attr"`
+		Units []xliff20Unit `xml:"unit"`
+	} `xml:"file"`
+}
+
+type xliff20Unit struct {
+	ID     string        `xml:"id,attr"`
+	Name   string        `xml:"name,attr,omitempty"`
+	Notes  *xliff20Notes `xml:"notes,omitempty"`
+	Source string        `xml:"segment>source"`
+	Target string        `xml:"segment>target,omitempty"`
+}
+
+type xliff20Notes struct {
+	Note []xliff20Note `xml:"note"`
+}
+
+type xliff20Note struct {
+	Category string `xml:"category,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// notes 返回 unit 的所有说明，XLIFF 2.0 不允许空的 notes 元素
+func (u *xliff20Unit) notes() []xliff20Note {
+	if u.Notes == nil {
+		return nil
+	}
+	return u.Notes.Note
+}
+
+// xliffContextType 保存消息上下文的 context-type（1.2）和 note category（2.0）
+const xliffContextType = "x-context"
+
+// xliffPositionType 保存消息在源代码中的位置的 context-type（1.2）和 note category（2.0）
+const xliffPositionType = "x-position"
+
+// ParseXLIFF 解析 XLIFF 1.2 或 2.0 文档，版本由根元素的 version 属性决定。
+func ParseXLIFF(data []byte) (*MessageFile, error) {
+	var root struct {
+		Version string `xml:"version,attr"`
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse XLIFF: %w", err)
+	}
+
+	switch root.Version {
+	case XLIFF12:
+		var doc xliff12
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse XLIFF 1.2: %w", err)
+		}
+		file := &MessageFile{
+			SourceLanguage: msg.Locale(doc.File.SourceLanguage),
+			Language:       msg.Locale(doc.File.TargetLanguage),
+		}
+		for _, u := range doc.File.Units {
+			m := Message{ID: cmp.Or(u.ResName, u.ID), Source: u.Source, Translation: u.Target, Note: u.Note}
+			if u.ContextGroup != nil {
+				for _, c := range u.ContextGroup.Context {
+					switch c.Type {
+					case xliffContextType:
+						m.Context = c.Value
+					case xliffPositionType:
+						m.Position = c.Value
+					}
+				}
+			}
+			file.Messages = append(file.Messages, m)
+		}
+		return file, nil
+
+	case XLIFF20:
+		var doc xliff20
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse XLIFF 2.0: %w", err)
+		}
+		file := &MessageFile{
+			SourceLanguage: msg.Locale(doc.SrcLang),
+			Language:       msg.Locale(doc.TrgLang),
+		}
+		for _, u := range doc.File.Units {
+			m := Message{ID: cmp.Or(u.Name, u.ID), Source: u.Source, Translation: u.Target}
+			var notes []string
+			for _, n := range u.notes() {
+				switch n.Category {
+				case xliffContextType:
+					m.Context = n.Value
+				case xliffPositionType:
+					m.Position = n.Value
+				default:
+					notes = append(notes, n.Value)
+				}
+			}
+			m.Note = strings.Join(notes, "\n")
+			file.Messages = append(file.Messages, m)
+		}
+		return file, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported XLIFF version %q", root.Version)
+	}
+}
+
+// WriteXLIFF 将翻译目录导出为 XLIFF 文档，交给专业翻译服务商处理。
+//
+// version 为 XLIFF12 或 XLIFF20。消息 ID、说明、上下文和源代码位置都会保留，
+// 翻译完成的文件可以直接由 XLIFFLoader 加载，或使用 ParseXLIFF 读回。
+//
+// XLIFF 2.0 要求 unit 的 id 是 NMTOKEN，因此 id 使用 "m1"、"m2" 等序号，
+// 消息 ID 保存在 name 属性中；XLIFF 1.2 同时写入 id 和 resname。
+//
+// 示例：
+//
+//	data, _ := os.ReadFile("locales/zh-CN.gotext.json")
+//	file, _ := xtext.ParseGotext(data)
+//	file.SourceLanguage = msg.English
+//	xtext.WriteXLIFF(os.Stdout, file, xtext.XLIFF20)
+func WriteXLIFF(w io.Writer, file *MessageFile, version string) error {
+	var doc any
+	switch version {
+	case XLIFF12:
+		d := &xliff12{Xmlns: "urn:oasis:names:tc:xliff:document:1.2", Version: XLIFF12}
+		d.File.SourceLanguage = string(file.SourceLanguage)
+		d.File.TargetLanguage = string(file.Language)
+		d.File.Datatype = "plaintext"
+		d.File.Original = "messages"
+		for _, m := range file.Messages {
+			u := xliff12Unit{ID: m.ID, ResName: m.ID, Source: m.Source, Target: m.Translation, Note: m.Note}
+			var contexts []xliff12Context
+			if m.Context != "" {
+				contexts = append(contexts, xliff12Context{Type: xliffContextType, Value: m.Context})
+			}
+			if m.Position != "" {
+				contexts = append(contexts, xliff12Context{Type: xliffPositionType, Value: m.Position})
+			}
+			if len(contexts) > 0 {
+				u.ContextGroup = &xliff12ContextGroup{Purpose: "information", Context: contexts}
+			}
+			d.File.Units = append(d.File.Units, u)
+		}
+		doc = d
+
+	case XLIFF20:
+		d := &xliff20{Xmlns: "urn:oasis:names:tc:xliff:document:2.0", Version: XLIFF20}
+		d.SrcLang = string(file.SourceLanguage)
+		d.TrgLang = string(file.Language)
+		d.File.ID = "messages"
+		for i, m := range file.Messages {
+			u := xliff20Unit{ID: "m" + strconv.Itoa(i+1), Name: m.ID, Source: m.Source, Target: m.Translation}
+			var notes []xliff20Note
+			if m.Context != "" {
+				notes = append(notes, xliff20Note{Category: xliffContextType, Value: m.Context})
+			}
+			if m.Position != "" {
+				notes = append(notes, xliff20Note{Category: xliffPositionType, Value: m.Position})
+			}
+			if m.Note != "" {
+				notes = append(notes, xliff20Note{Value: m.Note})
+			}
+			if len(notes) > 0 {
+				u.Notes = &xliff20Notes{Note: notes}
+			}
+			d.File.Units = append(d.File.Units, u)
+		}
+		doc = d
+
+	default:
+		return fmt.Errorf("unsupported XLIFF version %q", version)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// XLIFFLoader 实现 XLIFF 1.2 和 2.0 格式的加载器，只加载有译文的消息。
+//
+// 支持的文件格式：
+// - .xlf
+// - .xliff
+type XLIFFLoader struct {
+	name       string
+	extensions []string
+}
+
+// NewXLIFFLoader 创建新的 XLIFF 加载器。
+func NewXLIFFLoader() *XLIFFLoader {
+	return &XLIFFLoader{
+		name:       "XLIFF",
+		extensions: []string{".xlf", ".xliff"},
+	}
+}
+
+// Name 返回加载器名称。
+func (l *XLIFFLoader) Name() string {
+	return l.name
+}
+
+// Extensions 返回支持的文件扩展名列表。
+func (l *XLIFFLoader) Extensions() []string {
+	return l.extensions
+}
+
+// CanLoad 检查是否可以加载指定文件。
+func (l *XLIFFLoader) CanLoad(filename string) bool {
+	return hasExtension(filename, l.extensions)
+}
+
+// LoadToBuilder 加载 XLIFF 格式的翻译文件并写入到指定的 builder 中。
+func (l *XLIFFLoader) LoadToBuilder(filename string, data []byte, builder *catalog.Builder, locale msg.Locale) error {
+	messages, err := l.ParseMessages(filename, data)
+	if err != nil {
+		return err
+	}
+	loadMessages(builder, locale, messages)
+	return nil
+}
+
+// ParseMessages 实现 MessageParser 接口。
+func (l *XLIFFLoader) ParseMessages(filename string, data []byte) ([]Message, error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+
+	file, err := ParseXLIFF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XLIFF translation file %s: %w", filename, err)
+	}
+	return file.Messages, nil
+}
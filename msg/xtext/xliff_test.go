@@ -0,0 +1,117 @@
+package xtext
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+func TestXLIFFRoundTrip(t *testing.T) {
+	file, err := ParseGotext([]byte(`{
+  // gotext catalog
+  "language": "zh-CN",
+  "messages": [
+    {"id": "Hello, %s!", "message": "Hello, %s!", "translation": "你好，%s！", "comment": "Greeting on the home page"},
+    {"id": "Open", "message": "Open", "translation": "打开", "meaning": "verb", "position": "ui/menu.go:12:6"},
+    {"id": "Bye", "message": "Bye"}
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("ParseGotext error = %v", err)
+	}
+	file.SourceLanguage = msg.English
+
+	for _, version := range []string{XLIFF12, XLIFF20} {
+		t.Run(version, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteXLIFF(&buf, file, version); err != nil {
+				t.Fatalf("WriteXLIFF error = %v", err)
+			}
+			if !strings.Contains(buf.String(), `version="`+version+`"`) {
+				t.Errorf("WriteXLIFF output missing version %s:\n%s", version, buf.String())
+			}
+
+			got, err := ParseXLIFF(buf.Bytes())
+			if err != nil {
+				t.Fatalf("ParseXLIFF error = %v\n%s", err, buf.String())
+			}
+			if !reflect.DeepEqual(got, file) {
+				t.Errorf("ParseXLIFF() = %+v, want %+v", got, file)
+			}
+		})
+	}
+}
+
+func TestParseXLIFF(t *testing.T) {
+	t.Run("Vendor 1.2 file", func(t *testing.T) {
+		file, err := ParseXLIFF([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file source-language="en" target-language="de" datatype="plaintext" original="messages">
+    <body>
+      <trans-unit id="Hello">
+        <source>Hello</source>
+        <target>Hallo</target>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`))
+		if err != nil {
+			t.Fatalf("ParseXLIFF error = %v", err)
+		}
+		want := &MessageFile{
+			SourceLanguage: "en",
+			Language:       "de",
+			Messages:       []Message{{ID: "Hello", Source: "Hello", Translation: "Hallo"}},
+		}
+		if !reflect.DeepEqual(file, want) {
+			t.Errorf("ParseXLIFF() = %+v, want %+v", file, want)
+		}
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		if _, err := ParseXLIFF([]byte(`<xliff version="3.0"></xliff>`)); err == nil {
+			t.Error("ParseXLIFF should return error for unsupported version")
+		}
+		if err := WriteXLIFF(&bytes.Buffer{}, &MessageFile{}, "3.0"); err == nil {
+			t.Error("WriteXLIFF should return error for unsupported version")
+		}
+	})
+}
+
+func TestXLIFFLoader(t *testing.T) {
+	data := []byte(`<xliff version="2.0" xmlns="urn:oasis:names:tc:xliff:document:2.0" srcLang="en" trgLang="zh-CN">
+  <file id="messages">
+    <unit id="m1" name="Hello">
+      <segment><source>Hello</source><target>你好</target></segment>
+    </unit>
+    <unit id="m2" name="Bye">
+      <segment><source>Bye</source></segment>
+    </unit>
+  </file>
+</xliff>`)
+
+	loader, ok := NewLoaderRegistry().GetLoaderForFile("zh-CN.xlf")
+	if !ok || loader.Name() != "XLIFF" {
+		t.Fatal("GetLoaderForFile(zh-CN.xlf) should select the XLIFF loader")
+	}
+
+	builder := catalog.NewBuilder()
+	if err := loader.LoadToBuilder("zh-CN.xlf", data, builder, msg.Locale("zh-CN")); err != nil {
+		t.Fatalf("LoadToBuilder error = %v", err)
+	}
+	printer, err := NewPrinter(msg.Locale("zh-CN"), message.Catalog(builder))
+	if err != nil {
+		t.Fatalf("NewPrinter error = %v", err)
+	}
+	if got := printer.Sprintf("Hello"); got != "你好" {
+		t.Errorf("Sprintf(Hello) = %q, want %q", got, "你好")
+	}
+	if got := printer.Sprintf("Bye"); got != "Bye" {
+		t.Errorf("Sprintf(Bye) = %q, want untranslated %q", got, "Bye")
+	}
+}
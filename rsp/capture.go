@@ -0,0 +1,141 @@
+package rsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"go-slim.dev/slim"
+)
+
+// Recorded is the response that Respond would write, as computed by Capture.
+// Body holds the envelope with its original Go values, so tests can inspect
+// the data without a JSON round-trip.
+type Recorded struct {
+	Status int         `json:"status"` // HTTP status code
+	Header http.Header `json:"header"` // Response headers, including Set-Cookie
+	Body   slim.Map    `json:"body"`   // Response envelope
+
+	encoded []byte // JSON encoding of Body, if already known
+	stream  bool   // Whether Body must be streamed
+
+	localized bool // Whether Body was localized for the language of the request
+}
+
+// Code returns the "code" field of the envelope.
+func (r *Recorded) Code() string {
+	code, _ := r.Body["code"].(string)
+	return code
+}
+
+// OK returns the "ok" field of the envelope.
+func (r *Recorded) OK() bool {
+	ok, _ := r.Body["ok"].(bool)
+	return ok
+}
+
+// Message returns the "msg" field of the envelope.
+func (r *Recorded) Message() string {
+	msg, _ := r.Body["msg"].(string)
+	return msg
+}
+
+// Data returns the "data" field of the envelope.
+func (r *Recorded) Data() any {
+	return r.Body["data"]
+}
+
+// Meta returns the "meta" field of the envelope, or nil if absent.
+func (r *Recorded) Meta() map[string]any {
+	meta, _ := r.Body["meta"].(map[string]any)
+	return meta
+}
+
+// Problems returns the "problems" field of the envelope, or nil if absent.
+func (r *Recorded) Problems() Problems {
+	problems, _ := r.Body["problems"].(Problems)
+	return problems
+}
+
+// Capture applies the options exactly like Respond does, including defaults
+// attached by the Defaults middleware, but returns the resulting status,
+// headers and envelope instead of writing them. It returns an error if the
+// envelope cannot be encoded, in which case Respond would have written the
+// fallback response.
+//
+// Example:
+//
+//	rec, err := rsp.Capture(c, rsp.Error(err))
+//	rsp.AssertStatus(t, rec, http.StatusBadRequest)
+//	rsp.AssertProblem(t, rec, "email", "INVALID_FORMAT")
+func Capture(c slim.Context, opts ...Option) (*Recorded, error) {
+	r := record(c, newOptions(withDefaults(c, opts)), negotiate(c))
+	if _, err := json.Marshal(r.Body); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TestingT is the subset of testing.TB used by the assertion helpers.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AssertStatus reports an error if the recorded HTTP status differs from want.
+func AssertStatus(t TestingT, r *Recorded, want int) bool {
+	t.Helper()
+	if r.Status != want {
+		t.Errorf("rsp: status = %d, want %d", r.Status, want)
+		return false
+	}
+	return true
+}
+
+// AssertCode reports an error if the recorded envelope code differs from want.
+func AssertCode(t TestingT, r *Recorded, want string) bool {
+	t.Helper()
+	if code := r.Code(); code != want {
+		t.Errorf("rsp: code = %q, want %q", code, want)
+		return false
+	}
+	return true
+}
+
+// AssertData reports an error if the recorded data is not deeply equal to want.
+func AssertData(t TestingT, r *Recorded, want any) bool {
+	t.Helper()
+	if data := r.Data(); !reflect.DeepEqual(data, want) {
+		t.Errorf("rsp: data = %#v, want %#v", data, want)
+		return false
+	}
+	return true
+}
+
+// AssertProblem reports an error if the recorded envelope has no problem
+// with the given code for the given field.
+func AssertProblem(t TestingT, r *Recorded, field, code string) bool {
+	t.Helper()
+	problems := r.Problems()
+	for _, p := range problems[field] {
+		if p.Code == code {
+			return true
+		}
+	}
+	t.Errorf("rsp: no problem %q for field %q in %s", code, field, describeProblems(problems))
+	return false
+}
+
+func describeProblems(problems Problems) string {
+	if len(problems) == 0 {
+		return "empty problems"
+	}
+	codes := make(map[string][]string, len(problems))
+	for field, list := range problems {
+		for _, p := range list {
+			codes[field] = append(codes[field], p.Code)
+		}
+	}
+	return fmt.Sprint(codes)
+}
@@ -0,0 +1,106 @@
+package rsp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-slim.dev/v"
+)
+
+// fakeT records assertion failures instead of failing the test
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCapture(t *testing.T) {
+	ctx, recorder := createContext()
+	data := TestData{ID: 1, Name: "captured"}
+
+	rec, err := Capture(ctx,
+		StatusCode(http.StatusCreated),
+		Header("X-Custom", "value"),
+		SecureCookie("sid", "abc", 0),
+		Data(data),
+	)
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+
+	AssertStatus(t, rec, http.StatusCreated)
+	AssertCode(t, rec, "OK")
+	AssertData(t, rec, data)
+
+	if !rec.OK() {
+		t.Error("Recorded.OK() = false, want true")
+	}
+	if got := rec.Header.Get("X-Custom"); got != "value" {
+		t.Errorf("Header X-Custom = %q, want value", got)
+	}
+	if got := rec.Header.Get("Set-Cookie"); got == "" {
+		t.Error("Header Set-Cookie should be recorded")
+	}
+
+	// Nothing is written to the response
+	if ctx.Written() || recorder.Body.Len() > 0 {
+		t.Error("Capture() should not write the response")
+	}
+}
+
+func TestCaptureProblems(t *testing.T) {
+	ctx, _ := createContext()
+
+	verr := v.Value("invalid", "email", "Email").
+		Custom("INVALID_FORMAT", func(val any) any {
+			return false
+		}, v.ErrorFormat("Invalid email format")).
+		Validate()
+
+	rec, err := Capture(ctx, Error(verr))
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+
+	AssertStatus(t, rec, http.StatusBadRequest)
+	AssertCode(t, rec, "InvalidParams")
+	AssertProblem(t, rec, "email", "INVALID_FORMAT")
+}
+
+func TestCaptureEncodeError(t *testing.T) {
+	ctx, _ := createContext()
+
+	if _, err := Capture(ctx, Data(make(chan int))); err == nil {
+		t.Error("Capture() error = nil, want encode error")
+	}
+}
+
+func TestAssertionsReportFailures(t *testing.T) {
+	ctx, _ := createContext()
+	rec, err := Capture(ctx, StatusCode(http.StatusNotFound))
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+
+	ft := &fakeT{}
+	if AssertStatus(ft, rec, http.StatusOK) {
+		t.Error("AssertStatus() = true, want false")
+	}
+	if AssertCode(ft, rec, "OK") {
+		t.Error("AssertCode() = true, want false")
+	}
+	if AssertData(ft, rec, "data") {
+		t.Error("AssertData() = true, want false")
+	}
+	if AssertProblem(ft, rec, "email", "INVALID_FORMAT") {
+		t.Error("AssertProblem() = true, want false")
+	}
+	if len(ft.errors) != 4 {
+		t.Errorf("Reported failures = %v, want 4", len(ft.errors))
+	}
+}
@@ -0,0 +1,219 @@
+package rsp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-slim.dev/l4g"
+	"go-slim.dev/slim"
+)
+
+var (
+	// ErrCookieCodec is returned when signed cookies are used before CookieSigner is configured.
+	ErrCookieCodec = errors.New("rsp: cookie codec is not configured")
+	// ErrInvalidCookie is returned when a cookie value is malformed or fails authentication.
+	ErrInvalidCookie = errors.New("rsp: invalid cookie value")
+)
+
+var (
+	// CookieDefaults holds the attributes applied to cookies created by
+	// SecureCookie, SignedCookie and DeleteCookie. Only Path, Domain, Secure,
+	// HttpOnly and SameSite are used.
+	CookieDefaults = http.Cookie{
+		Path:     "/",
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	// CookieSigner is the codec used by SignedCookie and ReadSignedCookie.
+	// It must be configured before signed cookies can be used.
+	//
+	// Example:
+	//
+	//	signer, err := rsp.NewHMACCodec([]byte(os.Getenv("COOKIE_SECRET")))
+	//	if err != nil {
+	//		log.Fatal(err)
+	//	}
+	//	rsp.CookieSigner = signer
+	CookieSigner CookieCodec
+)
+
+// CookieCodec encodes and decodes cookie values. The cookie name is passed
+// along so that implementations can bind a value to its cookie, preventing
+// a valid value from being replayed under another name.
+type CookieCodec interface {
+	Encode(name, value string) (string, error)
+	Decode(name, value string) (string, error)
+}
+
+// SecureCookie configures a cookie using CookieDefaults for its attributes.
+// A positive ttl sets both MaxAge and Expires, otherwise a session cookie
+// is created.
+//
+// Example:
+//
+//	rsp.Respond(c, rsp.SecureCookie("session_id", sid, 24*time.Hour))
+func SecureCookie(name, value string, ttl time.Duration) Option {
+	return Cookie(newCookie(name, value, ttl))
+}
+
+// SignedCookie configures a cookie whose value is encoded with CookieSigner,
+// using CookieDefaults for its attributes. If CookieSigner is not configured
+// or encoding fails, the error is logged and the cookie is not set.
+//
+// Use ReadSignedCookie to read the value back.
+func SignedCookie(name, value string, ttl time.Duration) Option {
+	encoded, err := encodeCookie(name, value)
+	if err != nil {
+		l4g.Error("Failed to encode signed cookie", l4g.String("name", name), l4g.String("error", err.Error()))
+		return func(o *options) {}
+	}
+	return Cookie(newCookie(name, encoded, ttl))
+}
+
+// DeleteCookie configures an expired cookie that instructs the client to
+// remove the named cookie. It uses the Path and Domain from CookieDefaults,
+// which must match the ones the cookie was created with.
+func DeleteCookie(name string) Option {
+	cookie := newCookie(name, "", 0)
+	cookie.MaxAge = -1
+	cookie.Expires = time.Unix(0, 0)
+	return Cookie(cookie)
+}
+
+// ReadSignedCookie reads and decodes the named cookie from the request using
+// CookieSigner. It returns ErrInvalidCookie if the value has been tampered
+// with, and http.ErrNoCookie if the cookie is not present.
+func ReadSignedCookie(c slim.Context, name string) (string, error) {
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	if CookieSigner == nil {
+		return "", ErrCookieCodec
+	}
+	return CookieSigner.Decode(name, cookie.Value)
+}
+
+func encodeCookie(name, value string) (string, error) {
+	if CookieSigner == nil {
+		return "", ErrCookieCodec
+	}
+	return CookieSigner.Encode(name, value)
+}
+
+func newCookie(name, value string, ttl time.Duration) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     CookieDefaults.Path,
+		Domain:   CookieDefaults.Domain,
+		Secure:   CookieDefaults.Secure,
+		HttpOnly: CookieDefaults.HttpOnly,
+		SameSite: CookieDefaults.SameSite,
+	}
+	if ttl > 0 {
+		cookie.MaxAge = int(ttl.Seconds())
+		cookie.Expires = time.Now().Add(ttl)
+	}
+	return cookie
+}
+
+// minHMACKeySize is the minimum key length accepted by NewHMACCodec, the
+// output size of SHA-256.
+const minHMACKeySize = 32
+
+// NewHMACCodec returns a CookieCodec that signs values with HMAC-SHA256.
+// Values remain readable by the client but cannot be modified. The key must
+// be at least 32 bytes long, so that the signatures cannot be forged by
+// guessing a short secret.
+func NewHMACCodec(key []byte) (CookieCodec, error) {
+	if len(key) < minHMACKeySize {
+		return nil, fmt.Errorf("rsp: invalid cookie signing key: %d bytes, want at least %d", len(key), minHMACKeySize)
+	}
+	return &hmacCodec{key: key}, nil
+}
+
+type hmacCodec struct {
+	key []byte
+}
+
+func (h *hmacCodec) Encode(name, value string) (string, error) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(h.sign(name, payload)), nil
+}
+
+func (h *hmacCodec) Decode(name, value string) (string, error) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, h.sign(name, payload)) {
+		return "", ErrInvalidCookie
+	}
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	return string(b), nil
+}
+
+func (h *hmacCodec) sign(name, payload string) []byte {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'|'})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// NewAESCodec returns a CookieCodec that encrypts and authenticates values
+// with AES-GCM. The key must be 16, 24 or 32 bytes long to select AES-128,
+// AES-192 or AES-256.
+func NewAESCodec(key []byte) (CookieCodec, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("rsp: invalid cookie encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("rsp: invalid cookie encryption key: %w", err)
+	}
+	return &aesCodec{aead: aead}, nil
+}
+
+type aesCodec struct {
+	aead cipher.AEAD
+}
+
+func (a *aesCodec) Encode(name, value string) (string, error) {
+	nonce := make([]byte, a.aead.NonceSize(), a.aead.NonceSize()+len(value)+a.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("rsp: failed to generate cookie nonce: %w", err)
+	}
+	sealed := a.aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (a *aesCodec) Decode(name, value string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(sealed) < a.aead.NonceSize() {
+		return "", ErrInvalidCookie
+	}
+	nonce, ciphertext := sealed[:a.aead.NonceSize()], sealed[a.aead.NonceSize():]
+	plain, err := a.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	return string(plain), nil
+}
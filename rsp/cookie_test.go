@@ -0,0 +1,152 @@
+package rsp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-slim.dev/slim"
+)
+
+// requestWithCookies creates a slim.Context whose request carries the given cookies
+func requestWithCookies(cookies ...*http.Cookie) slim.Context {
+	s := slim.New()
+	request := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+	return s.NewContext(httptest.NewRecorder(), request)
+}
+
+func TestSecureCookie(t *testing.T) {
+	ctx, recorder := createContext()
+
+	if err := Respond(ctx, SecureCookie("session_id", "abc123", time.Hour)); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Cookies count = %v, want 1", len(cookies))
+	}
+
+	cookie := cookies[0]
+	if cookie.Name != "session_id" || cookie.Value != "abc123" {
+		t.Errorf("Cookie = %s=%s, want session_id=abc123", cookie.Name, cookie.Value)
+	}
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("Cookie flags = HttpOnly:%v Secure:%v SameSite:%v", cookie.HttpOnly, cookie.Secure, cookie.SameSite)
+	}
+	if cookie.Path != "/" {
+		t.Errorf("Cookie path = %q, want /", cookie.Path)
+	}
+	if cookie.MaxAge != 3600 {
+		t.Errorf("Cookie MaxAge = %v, want 3600", cookie.MaxAge)
+	}
+}
+
+func TestDeleteCookie(t *testing.T) {
+	ctx, recorder := createContext()
+
+	if err := Respond(ctx, DeleteCookie("session_id")); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Cookies count = %v, want 1", len(cookies))
+	}
+	if cookies[0].MaxAge >= 0 {
+		t.Errorf("Cookie MaxAge = %v, want negative", cookies[0].MaxAge)
+	}
+}
+
+func TestSignedCookie(t *testing.T) {
+	hmacCodec, err := NewHMACCodec([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewHMACCodec() error = %v", err)
+	}
+	aesCodec, err := NewAESCodec([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewAESCodec() error = %v", err)
+	}
+
+	codecs := map[string]CookieCodec{
+		"hmac": hmacCodec,
+		"aes":  aesCodec,
+	}
+
+	original := CookieSigner
+	defer func() { CookieSigner = original }()
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			CookieSigner = codec
+
+			ctx, recorder := createContext()
+			if err := Respond(ctx, SignedCookie("uid", "42", time.Hour)); err != nil {
+				t.Fatalf("Respond() error = %v", err)
+			}
+
+			cookies := recorder.Result().Cookies()
+			if len(cookies) != 1 {
+				t.Fatalf("Cookies count = %v, want 1", len(cookies))
+			}
+			if cookies[0].Value == "42" {
+				t.Error("Signed cookie value should be encoded")
+			}
+
+			value, err := ReadSignedCookie(requestWithCookies(cookies[0]), "uid")
+			if err != nil || value != "42" {
+				t.Errorf("ReadSignedCookie() = %q, %v, want 42", value, err)
+			}
+
+			// The value is bound to the cookie name
+			renamed := &http.Cookie{Name: "admin", Value: cookies[0].Value}
+			if _, err := ReadSignedCookie(requestWithCookies(renamed), "admin"); !errors.Is(err, ErrInvalidCookie) {
+				t.Errorf("ReadSignedCookie() renamed error = %v, want ErrInvalidCookie", err)
+			}
+
+			tampered := &http.Cookie{Name: "uid", Value: "x" + cookies[0].Value}
+			if _, err := ReadSignedCookie(requestWithCookies(tampered), "uid"); !errors.Is(err, ErrInvalidCookie) {
+				t.Errorf("ReadSignedCookie() tampered error = %v, want ErrInvalidCookie", err)
+			}
+		})
+	}
+}
+
+func TestSignedCookieWithoutCodec(t *testing.T) {
+	original := CookieSigner
+	defer func() { CookieSigner = original }()
+	CookieSigner = nil
+
+	ctx, recorder := createContext()
+	if err := Respond(ctx, SignedCookie("uid", "42", time.Hour)); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+	if len(recorder.Result().Cookies()) != 0 {
+		t.Error("SignedCookie should not set a cookie without a codec")
+	}
+
+	ctx = requestWithCookies(&http.Cookie{Name: "uid", Value: "42"})
+	if _, err := ReadSignedCookie(ctx, "uid"); !errors.Is(err, ErrCookieCodec) {
+		t.Errorf("ReadSignedCookie() error = %v, want ErrCookieCodec", err)
+	}
+}
+
+func TestNewHMACCodecShortKey(t *testing.T) {
+	if _, err := NewHMACCodec([]byte("secret")); err == nil {
+		t.Error("NewHMACCodec() error = nil, want short key error")
+	}
+	if _, err := NewHMACCodec(make([]byte, 32)); err != nil {
+		t.Errorf("NewHMACCodec() error = %v, want nil for a 32-byte key", err)
+	}
+}
+
+func TestNewAESCodecInvalidKey(t *testing.T) {
+	if _, err := NewAESCodec([]byte("short")); err == nil {
+		t.Error("NewAESCodec() error = nil, want invalid key error")
+	}
+}
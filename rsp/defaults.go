@@ -0,0 +1,43 @@
+package rsp
+
+import (
+	"slices"
+
+	"go-slim.dev/slim"
+)
+
+// defaultsContextKey is the slim.Context key under which Defaults stores
+// the default options for the current request.
+const defaultsContextKey = "rsp:defaults"
+
+// Defaults creates a middleware that attaches default options to every
+// Respond call made while handling the request, such as version headers or
+// metadata shared by a route group.
+//
+// Defaults are applied before the options passed to Respond, so the latter
+// take precedence. Nested Defaults middlewares accumulate, with the inner
+// ones applied after the outer ones.
+//
+// Example:
+//
+//	api := app.Group("/api/v2")
+//	api.Use(rsp.Defaults(
+//		rsp.Header("X-API-Version", "2"),
+//		rsp.Meta("api_version", "2"),
+//	))
+func Defaults(opts ...Option) slim.MiddlewareFunc {
+	return func(c slim.Context, next slim.HandlerFunc) error {
+		c.Set(defaultsContextKey, withDefaults(c, opts))
+		return next(c)
+	}
+}
+
+// withDefaults prepends the default options attached to the context, if any.
+// The returned slice never aliases the stored defaults.
+func withDefaults(c slim.Context, opts []Option) []Option {
+	defaults, ok := c.Get(defaultsContextKey).([]Option)
+	if !ok || len(defaults) == 0 {
+		return opts
+	}
+	return append(slices.Clip(defaults), opts...)
+}
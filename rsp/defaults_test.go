@@ -0,0 +1,70 @@
+package rsp
+
+import (
+	"net/http"
+	"testing"
+
+	"go-slim.dev/slim"
+)
+
+func TestDefaults(t *testing.T) {
+	ctx, recorder := createContext()
+
+	outer := Defaults(Header("X-API-Version", "1"), Meta("api", "v1"), Message("outer"))
+	inner := Defaults(Header("X-API-Version", "2"), Meta("group", "users"))
+
+	err := outer(ctx, func(c slim.Context) error {
+		return inner(c, func(c slim.Context) error {
+			return Respond(c, Message("handler"))
+		})
+	})
+	if err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	if got := recorder.Header().Get("X-API-Version"); got != "2" {
+		t.Errorf("X-API-Version = %q, want inner default 2", got)
+	}
+
+	rec, err := Capture(ctx)
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	if rec.Meta()["api"] != "v1" || rec.Meta()["group"] != "users" {
+		t.Errorf("meta = %v, want accumulated defaults", rec.Meta())
+	}
+	if rec.Message() != "outer" {
+		t.Errorf("msg = %q, want default message", rec.Message())
+	}
+}
+
+func TestDefaultsOverriddenByRespond(t *testing.T) {
+	ctx, _ := createContext()
+
+	mw := Defaults(StatusCode(http.StatusAccepted), Header("X-API-Version", "1"))
+	_ = mw(ctx, func(c slim.Context) error { return nil })
+
+	rec, err := Capture(ctx, Header("X-API-Version", "2"))
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	AssertStatus(t, rec, http.StatusAccepted)
+	if got := rec.Header.Get("X-API-Version"); got != "2" {
+		t.Errorf("X-API-Version = %q, want handler option to win", got)
+	}
+}
+
+func TestDefaultsDoNotLeakBetweenRequests(t *testing.T) {
+	mw := Defaults(Meta("a", 1))
+
+	for range 2 {
+		ctx, _ := createContext()
+		_ = mw(ctx, func(c slim.Context) error {
+			opts, _ := c.Get(defaultsContextKey).([]Option)
+			if len(opts) != 1 {
+				t.Errorf("defaults = %v options, want 1", len(opts))
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package rsp
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"go-slim.dev/slim"
+)
+
+// errorMapping translates errors matching target into a status and code.
+type errorMapping struct {
+	target error
+	status int
+	code   string
+}
+
+var (
+	errorMappingsMu sync.RWMutex
+	errorMappings   []errorMapping
+)
+
+// MapError registers a translation for errors matching target, as reported
+// by errors.Is, so that sentinel errors such as sql.ErrNoRows or
+// context.DeadlineExceeded produce a proper status and code instead of the
+// generic 500 InternalError response.
+//
+// Mappings are consulted in registration order, after HTTP, validation and
+// Fundamental errors. Registering the same target again replaces its mapping.
+// The message defaults to the status text and can be overridden per response
+// with the Message option, as can the status with StatusCode.
+//
+// Example:
+//
+//	func init() {
+//		rsp.MapError(sql.ErrNoRows, http.StatusNotFound, "RecordNotFound")
+//		rsp.MapError(context.DeadlineExceeded, http.StatusGatewayTimeout, "Timeout")
+//	}
+func MapError(target error, status int, code string) {
+	errorMappingsMu.Lock()
+	defer errorMappingsMu.Unlock()
+	for i, m := range errorMappings {
+		if sameError(m.target, target) {
+			errorMappings[i] = errorMapping{target, status, code}
+			return
+		}
+	}
+	errorMappings = append(errorMappings, errorMapping{target, status, code})
+}
+
+// sameError reports whether a and b are the same error value. Unlike a == b,
+// it does not panic on errors of the same non-comparable dynamic type.
+func sameError(a, b error) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return false
+	}
+	return ta == nil || ta.Comparable() && a == b
+}
+
+// lookupErrorMapping returns the first mapping matching err.
+func lookupErrorMapping(err error) (errorMapping, bool) {
+	errorMappingsMu.RLock()
+	defer errorMappingsMu.RUnlock()
+	for _, m := range errorMappings {
+		if errors.Is(err, m.target) {
+			return m, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+func inferMappedError(c slim.Context, o *options) (int, slim.Map, bool) {
+	if o.err == nil {
+		return 0, nil, false
+	}
+	mapping, ok := lookupErrorMapping(o.err)
+	if !ok {
+		return 0, nil, false
+	}
+
+	status := cmp.Or(o.status, mapping.status)
+	m := slim.Map{
+		"code": mapping.code,
+		"ok":   status >= 200 && status < 300, // Only 2xx status codes indicate success
+		"msg":  cmp.Or(o.message, http.StatusText(status)),
+	}
+	if o.data != nil {
+		m["data"] = o.data
+	}
+	if c.Slim().Debug {
+		m["error"] = fmt.Sprintf("%+v", o.err)
+	}
+	return status, m, true
+}
@@ -0,0 +1,212 @@
+package rsp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/slim"
+)
+
+// withErrorMappings isolates the error mapping registry for the duration of the test
+func withErrorMappings(t *testing.T) {
+	errorMappingsMu.Lock()
+	saved := errorMappings
+	errorMappings = nil
+	errorMappingsMu.Unlock()
+	t.Cleanup(func() {
+		errorMappingsMu.Lock()
+		errorMappings = saved
+		errorMappingsMu.Unlock()
+	})
+}
+
+func TestMapError(t *testing.T) {
+	withErrorMappings(t)
+	MapError(sql.ErrNoRows, http.StatusNotFound, "RecordNotFound")
+	MapError(context.DeadlineExceeded, http.StatusGatewayTimeout, "Timeout")
+
+	tests := []struct {
+		name       string
+		err        error
+		opts       []Option
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:       "Sentinel error",
+			err:        sql.ErrNoRows,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "RecordNotFound",
+			wantMsg:    "Not Found",
+		},
+		{
+			name:       "Wrapped error",
+			err:        fmt.Errorf("query user: %w", context.DeadlineExceeded),
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   "Timeout",
+			wantMsg:    "Gateway Timeout",
+		},
+		{
+			name:       "Overridden status and message",
+			err:        sql.ErrNoRows,
+			opts:       []Option{StatusCode(http.StatusGone), Message("User is gone")},
+			wantStatus: http.StatusGone,
+			wantCode:   "RecordNotFound",
+			wantMsg:    "User is gone",
+		},
+		{
+			name:       "Unmapped error",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "InternalError",
+			wantMsg:    "An unexpected error occurred",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := createContext()
+			rec, err := Capture(ctx, append([]Option{Error(tt.err)}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("Capture() error = %v", err)
+			}
+			AssertStatus(t, rec, tt.wantStatus)
+			AssertCode(t, rec, tt.wantCode)
+			if rec.Message() != tt.wantMsg {
+				t.Errorf("msg = %q, want %q", rec.Message(), tt.wantMsg)
+			}
+			if rec.OK() {
+				t.Error("ok = true, want false")
+			}
+		})
+	}
+}
+
+func TestMapErrorReplacesMapping(t *testing.T) {
+	withErrorMappings(t)
+	MapError(sql.ErrNoRows, http.StatusNotFound, "RecordNotFound")
+	MapError(sql.ErrNoRows, http.StatusNotFound, "NotFound")
+
+	if len(errorMappings) != 1 {
+		t.Errorf("mappings = %v, want 1", len(errorMappings))
+	}
+
+	ctx, _ := createContext()
+	rec, _ := Capture(ctx, Error(sql.ErrNoRows))
+	AssertCode(t, rec, "NotFound")
+}
+
+// sliceError is an error of a non-comparable type
+type sliceError struct{ fields []string }
+
+func (e sliceError) Error() string { return fmt.Sprint("invalid fields ", e.fields) }
+
+func TestMapErrorNonComparableTargets(t *testing.T) {
+	withErrorMappings(t)
+	MapError(sliceError{[]string{"name"}}, http.StatusBadRequest, "InvalidName")
+	MapError(sliceError{[]string{"email"}}, http.StatusBadRequest, "InvalidEmail")
+
+	if len(errorMappings) != 2 {
+		t.Errorf("mappings = %v, want 2", len(errorMappings))
+	}
+}
+
+func TestMapErrorDoesNotOverrideFundamental(t *testing.T) {
+	withErrorMappings(t)
+	MapError(ErrBadRequest, http.StatusNotFound, "Mapped")
+
+	ctx, _ := createContext()
+	rec, _ := Capture(ctx, Error(ErrBadRequest))
+	AssertStatus(t, rec, http.StatusBadRequest)
+	AssertCode(t, rec, "BadRequest")
+}
+
+func TestMapErrorDebug(t *testing.T) {
+	withErrorMappings(t)
+	MapError(sql.ErrNoRows, http.StatusNotFound, "RecordNotFound")
+
+	ctx, _ := createContextWithDebug(true)
+	rec, _ := Capture(ctx, Error(sql.ErrNoRows))
+	if rec.Body["error"] == nil {
+		t.Error("error field should be present in debug mode")
+	}
+}
+
+func TestRespondWithLocalizedError(t *testing.T) {
+	withErrorMappings(t)
+	errQuota := msg.Error("Quota exceeded")
+	MapError(errQuota, http.StatusTooManyRequests, "QuotaExceeded")
+
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.Chinese, LogFunc: func(string) {}})
+	_ = manager.SetMessage(msg.Chinese, "User %s not found", "未找到用户 %s")
+	_ = manager.SetMessage(msg.Chinese, "Quota exceeded", "超出配额")
+
+	saved := MessagePrinter
+	t.Cleanup(func() { MessagePrinter = saved })
+	MessagePrinter = func(c slim.Context) msg.Printer {
+		return manager.GetPrinterWithContext(c.Request().Context())
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		opts       []Option
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:       "Unmapped error",
+			err:        msg.Error("User %s not found", "alice"),
+			opts:       []Option{StatusCode(http.StatusNotFound)},
+			wantStatus: http.StatusNotFound,
+			wantCode:   "InternalError",
+			wantMsg:    "未找到用户 alice",
+		},
+		{
+			name:       "Mapped sentinel error",
+			err:        fmt.Errorf("create order: %w", errQuota),
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   "QuotaExceeded",
+			wantMsg:    "超出配额",
+		},
+		{
+			name:       "Explicit message",
+			err:        errQuota,
+			opts:       []Option{Message("Slow down")},
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   "QuotaExceeded",
+			wantMsg:    "Slow down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := createContext()
+			rec, err := Capture(ctx, append([]Option{Error(tt.err)}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("Capture() error = %v", err)
+			}
+			AssertStatus(t, rec, tt.wantStatus)
+			AssertCode(t, rec, tt.wantCode)
+			if rec.Message() != tt.wantMsg {
+				t.Errorf("msg = %q, want %q", rec.Message(), tt.wantMsg)
+			}
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		MessagePrinter = nil
+		ctx, _ := createContext()
+		rec, _ := Capture(ctx, Error(msg.Error("User %s not found", "alice")))
+		if want := "An unexpected error occurred"; rec.Message() != want {
+			t.Errorf("msg = %q, want %q", rec.Message(), want)
+		}
+	})
+}
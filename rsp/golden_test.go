@@ -0,0 +1,101 @@
+package rsp_test
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/infra/msg/msgslim"
+	"go-slim.dev/infra/rsp"
+	"go-slim.dev/infra/rsp/rsptest"
+	"go-slim.dev/slim"
+	"go-slim.dev/v"
+)
+
+// Rewrite the golden files in testdata with: go test ./rsp -run TestGolden -update
+var _ = flag.Bool("update", false, "update golden files")
+
+// goldenAccepts and goldenLanguages make up the matrix every case is rendered in
+var (
+	goldenAccepts   = []string{"application/json", "application/xml", "text/plain", "text/html"}
+	goldenLanguages = []string{"en", "zh"}
+)
+
+// quotaError implements rsp.Fundamental
+type quotaError struct{}
+
+func (quotaError) Status() int   { return http.StatusTooManyRequests }
+func (quotaError) Code() string  { return "QuotaExceeded" }
+func (quotaError) Text() string  { return "Quota exceeded" }
+func (quotaError) Data() any     { return slim.Map{"limit": 100} }
+func (quotaError) Cause() error  { return nil }
+func (quotaError) Error() string { return "quota exceeded" }
+
+// errOutOfStock is mapped once for the test binary, since MapError has no way
+// to remove a mapping; the sentinel is not used by any other test
+var errOutOfStock = errors.New("out of stock")
+
+func init() {
+	rsp.MapError(errOutOfStock, http.StatusConflict, "OutOfStock")
+}
+
+func TestGolden(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.English, LogFunc: func(string) {}})
+	_ = manager.SetMessage(msg.Chinese, "INVALID_FORMAT", "邮箱格式无效")
+	_ = manager.SetMessage(msg.Chinese, "User %s not found", "未找到用户 %s")
+
+	savedPrinter, savedTranslator := rsp.MessagePrinter, rsp.ProblemTranslator
+	t.Cleanup(func() {
+		rsp.MessagePrinter, rsp.ProblemTranslator = savedPrinter, savedTranslator
+	})
+	rsp.InstallRspTranslation(manager)
+
+	middleware := msgslim.Middleware(manager, msgslim.WithSupportedLocales(msg.English, msg.Chinese))
+
+	tests := []struct {
+		name    string
+		handler slim.HandlerFunc
+	}{
+		{"ok", func(c slim.Context) error {
+			return rsp.Ok(c, slim.Map{"id": 1, "name": "Alice"})
+		}},
+		{"status_code", func(c slim.Context) error {
+			return rsp.Respond(c, rsp.StatusCode(http.StatusNotFound), rsp.Message("User not found"))
+		}},
+		{"http_error", func(c slim.Context) error {
+			return rsp.Respond(c, rsp.Error(slim.NewHTTPError(http.StatusConflict, "Order already paid")))
+		}},
+		{"validation_error", func(c slim.Context) error {
+			valuer := v.Value("invalid-email", "email", "Email")
+			valuer.Custom("INVALID_FORMAT", func(val any) any {
+				return false
+			}, v.ErrorFormat("Invalid email format"))
+			return rsp.Respond(c, rsp.Error(valuer.Validate()))
+		}},
+		{"fundamental_error", func(c slim.Context) error {
+			return rsp.Respond(c, rsp.Error(quotaError{}))
+		}},
+		{"mapped_error", func(c slim.Context) error {
+			return rsp.Respond(c, rsp.Error(fmt.Errorf("reserve item: %w", errOutOfStock)))
+		}},
+		{"internal_error", func(c slim.Context) error {
+			return rsp.Respond(c, rsp.Error(errors.New("connection refused")))
+		}},
+		{"localized_error", func(c slim.Context) error {
+			return rsp.Respond(c, rsp.StatusCode(http.StatusNotFound), rsp.Error(msg.Error("User %s not found", "alice")))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rsptest.Matrix(t, rsptest.Case{
+				Name: tt.name,
+				Handler: func(c slim.Context) error {
+					return middleware(c, tt.handler)
+				},
+			}, goldenAccepts, goldenLanguages)
+		})
+	}
+}
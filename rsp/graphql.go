@@ -0,0 +1,50 @@
+package rsp
+
+import "go-slim.dev/slim"
+
+// GraphQL renders the response in the GraphQL response format instead of the
+// standard envelope, for handlers that proxy or serve GraphQL results. Errors
+// keep the status, code and debug details rsp infers for them:
+//
+//	{
+//		"errors": [{"message": "...", "extensions": {"code": "...", ...}}], // on failure
+//		"data": ...,
+//		"extensions": {...} // optional, response metadata
+//	}
+//
+// Example:
+//
+//	gql := app.Group("/graphql")
+//	gql.Use(rsp.Defaults(rsp.GraphQL()))
+func GraphQL() Option {
+	return func(o *options) {
+		o.graphql = true
+	}
+}
+
+// toGraphQL converts a standard envelope to the GraphQL response format.
+// The code and any other field of failed envelopes, such as problems and
+// debug errors, become extensions of the error.
+func toGraphQL(m slim.Map) slim.Map {
+	g := slim.Map{"data": m["data"]}
+	if meta, ok := m["meta"]; ok {
+		g["extensions"] = meta
+	}
+	if ok, _ := m["ok"].(bool); ok {
+		return g
+	}
+
+	extensions := map[string]any{}
+	for key, value := range m {
+		switch key {
+		case "ok", "msg", "data", "meta":
+		default:
+			extensions[key] = value
+		}
+	}
+	g["errors"] = []map[string]any{{
+		"message":    m["msg"],
+		"extensions": extensions,
+	}}
+	return g
+}
@@ -0,0 +1,91 @@
+package rsp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"go-slim.dev/v"
+)
+
+func TestGraphQLSuccess(t *testing.T) {
+	ctx, _ := createContextWithAccept("application/json")
+
+	rec, err := Capture(ctx, GraphQL(), Data(map[string]any{"user": "john"}), Meta("cost", 3))
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+
+	AssertStatus(t, rec, http.StatusOK)
+	want := map[string]any{
+		"data":       map[string]any{"user": "john"},
+		"extensions": map[string]any{"cost": 3},
+	}
+	if !reflect.DeepEqual(map[string]any(rec.Body), want) {
+		t.Errorf("body = %v, want %v", rec.Body, want)
+	}
+}
+
+func TestGraphQLSuccessWithoutMeta(t *testing.T) {
+	ctx, recorder := createContextWithAccept("application/json")
+
+	if err := Respond(ctx, GraphQL(), Data(map[string]any{"user": "john"})); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusOK)
+	}
+	body := recorder.Body.Bytes()
+	if !json.Valid(body) || string(body) != `{"data":{"user":"john"}}` {
+		t.Errorf("body = %s, want a data-only GraphQL response", body)
+	}
+}
+
+func TestGraphQLError(t *testing.T) {
+	ctx, _ := createContextWithDebug(true)
+
+	rec, _ := Capture(ctx, GraphQL(), Error(errors.New("resolver failed")))
+
+	AssertStatus(t, rec, http.StatusInternalServerError)
+	if data, ok := rec.Body["data"]; !ok || data != nil {
+		t.Errorf("data = %v, want null", data)
+	}
+	errs, ok := rec.Body["errors"].([]map[string]any)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("errors = %v, want one error", rec.Body["errors"])
+	}
+	if errs[0]["message"] != "An unexpected error occurred" {
+		t.Errorf("message = %v", errs[0]["message"])
+	}
+	extensions := errs[0]["extensions"].(map[string]any)
+	if extensions["code"] != "InternalError" {
+		t.Errorf("extensions.code = %v, want InternalError", extensions["code"])
+	}
+	if extensions["error"] != "resolver failed" {
+		t.Errorf("extensions.error = %v, want debug detail", extensions["error"])
+	}
+}
+
+func TestGraphQLValidationError(t *testing.T) {
+	ctx, _ := createContext()
+
+	valuer := v.Value("invalid-email", "email", "Email")
+	valuer.Custom("INVALID_FORMAT", func(val any) any {
+		return false
+	}, v.ErrorFormat("Invalid email format"))
+
+	rec, _ := Capture(ctx, GraphQL(), Error(valuer.Validate()))
+
+	AssertStatus(t, rec, http.StatusBadRequest)
+	errs := rec.Body["errors"].([]map[string]any)
+	extensions := errs[0]["extensions"].(map[string]any)
+	if extensions["code"] != "InvalidParams" {
+		t.Errorf("extensions.code = %v, want InvalidParams", extensions["code"])
+	}
+	if _, ok := extensions["problems"]; !ok {
+		t.Error("extensions should carry the validation problems")
+	}
+}
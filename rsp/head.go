@@ -0,0 +1,96 @@
+package rsp
+
+import (
+	"cmp"
+	"net/http"
+	"strconv"
+
+	"go-slim.dev/slim"
+)
+
+// HeadContentLength controls whether responses to HEAD requests carry the
+// Content-Type and Content-Length the corresponding GET request would
+// produce, so that caches and health checkers see the real representation.
+// When disabled, HEAD requests are answered with the status and headers only.
+var HeadContentLength = true
+
+// countingWriter is the http.ResponseWriter of a measured response: it keeps
+// the headers and status and counts the bytes of the body, discarding them.
+type countingWriter struct {
+	header http.Header
+	status int
+	n      int64
+}
+
+func (w *countingWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *countingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// head answers a HEAD request with the headers of the corresponding GET
+// response, without its body.
+func head(c slim.Context, format string, r *Recorded) error {
+	if !HeadContentLength {
+		return c.NoContent(r.Status)
+	}
+
+	// Streamed responses are sent without a Content-Length, so there is
+	// no need to encode their data
+	if rendersJSON(format) && (r.stream || shouldStream(r.Body["data"])) {
+		header := c.Response().Header()
+		header.Set("Content-Type", "application/json; charset=UTF-8")
+		if StreamGzip && acceptsEncoding(c.Request(), "gzip") {
+			header.Set("Content-Encoding", "gzip")
+		}
+		c.Response().WriteHeader(r.Status)
+		return nil
+	}
+
+	w, err := measure(c, format, r)
+	if err != nil {
+		return err
+	}
+
+	header := c.Response().Header()
+	for key, values := range w.header {
+		header[key] = values
+	}
+	header.Set("Content-Length", strconv.FormatInt(w.n, 10))
+	c.Response().WriteHeader(cmp.Or(w.status, http.StatusOK))
+	return nil
+}
+
+// measure renders the GET response of the request to a counting writer,
+// through the same path as write, so that the headers, status and length
+// reported for HEAD requests are those of the body GET requests receive.
+// The response is rendered with the context of the request, so that values
+// set by earlier middleware, such as the locale, still apply; its request
+// and response writer are swapped for the duration of the rendering.
+func measure(c slim.Context, format string, r *Recorded) (*countingWriter, error) {
+	req, res := c.Request(), c.Response()
+	defer func() {
+		c.SetRequest(req)
+		c.SetResponse(res)
+	}()
+
+	get := req.Clone(req.Context())
+	get.Method = http.MethodGet
+	w := &countingWriter{header: res.Header().Clone()}
+	c.SetRequest(get)
+	c.SetResponse(slim.NewResponseWriter(http.MethodGet, w))
+	if err := write(c, format, r); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
@@ -0,0 +1,122 @@
+package rsp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"go-slim.dev/slim"
+)
+
+func TestHeadMatchesGet(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		query  string
+	}{
+		{"json", "application/json", ""},
+		{"json pretty", "application/json", "pretty"},
+		{"xml", "application/xml", ""},
+		{"html", "text/html", ""},
+		{"text", "text/plain", ""},
+		{"jsonp", "application/javascript", "callback=handle"},
+		{"jsonp without callback", "application/javascript", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := serveMethod(t, http.MethodGet, tt.accept, tt.query)
+			head := serveMethod(t, http.MethodHead, tt.accept, tt.query)
+
+			if head.Code != get.Code {
+				t.Errorf("HEAD status = %v, want %v", head.Code, get.Code)
+			}
+			if head.Body.Len() != 0 {
+				t.Errorf("HEAD body = %q, want empty", head.Body.String())
+			}
+			if got, want := head.Header().Get("Content-Type"), get.Header().Get("Content-Type"); got != want {
+				t.Errorf("HEAD Content-Type = %q, want %q", got, want)
+			}
+			if got, want := head.Header().Get("Content-Length"), strconv.Itoa(get.Body.Len()); got != want {
+				t.Errorf("HEAD Content-Length = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestHeadKeepsContext(t *testing.T) {
+	original := HTMLRenderer
+	defer func() { HTMLRenderer = original }()
+	HTMLRenderer = func(c slim.Context, m map[string]any) (string, error) {
+		user, _ := c.Get("user").(string)
+		return "<p>Hello " + user + "</p>", nil
+	}
+
+	serve := func(method string) (slim.Context, *httptest.ResponseRecorder) {
+		request := httptest.NewRequest(method, "/", nil)
+		request.Header.Set("Accept", "text/html")
+		recorder := httptest.NewRecorder()
+		ctx := slim.New().NewContext(recorder, request)
+		ctx.Set("user", "gopher")
+		if err := Ok(ctx); err != nil {
+			t.Fatalf("Ok() error = %v", err)
+		}
+		return ctx, recorder
+	}
+
+	_, get := serve(http.MethodGet)
+	ctx, head := serve(http.MethodHead)
+	if got, want := head.Header().Get("Content-Length"), strconv.Itoa(get.Body.Len()); got != want {
+		t.Errorf("HEAD Content-Length = %q, want %q", got, want)
+	}
+	if ctx.Request().Method != http.MethodHead {
+		t.Errorf("request method = %v, want the HEAD request restored", ctx.Request().Method)
+	}
+}
+
+func TestHeadStreamed(t *testing.T) {
+	setStreaming(t, 10, false)
+
+	ctx, recorder := createContextWithMethod(http.MethodHead)
+	ctx.Request().Header.Set("Accept", "application/json")
+	if err := Respond(ctx, Data(largeData(100))); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=UTF-8" {
+		t.Errorf("Content-Type = %q, want JSON", got)
+	}
+	if got := recorder.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content-Length = %q, want none for streamed responses", got)
+	}
+}
+
+func TestHeadContentLengthDisabled(t *testing.T) {
+	HeadContentLength = false
+	defer func() { HeadContentLength = true }()
+
+	recorder := serveMethod(t, http.MethodHead, "application/json", "")
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusOK)
+	}
+	if got := recorder.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content-Length = %q, want none", got)
+	}
+}
+
+// serveMethod responds to a request with the given method, Accept header
+// and query string.
+func serveMethod(t *testing.T, method, accept, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, "/?"+query, nil)
+	request.Header.Set("Accept", accept)
+	ctx := slim.New().NewContext(recorder, request)
+
+	data := []TestData{{ID: 1, Name: "<first>"}, {ID: 2, Name: "second"}}
+	if err := Respond(ctx, Data(data), Message("Fetched & listed")); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+	return recorder
+}
@@ -0,0 +1,376 @@
+package rsp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go-slim.dev/l4g"
+	"go-slim.dev/slim"
+)
+
+// idempotencyContextKey is the slim.Context key under which the idempotency
+// middleware stores the pending entry for the current request.
+const idempotencyContextKey = "rsp:idempotency"
+
+// IdempotencyStore persists recorded responses by idempotency key.
+type IdempotencyStore interface {
+	// Get returns the response recorded for key, or nil if there is none,
+	// including while the request holding key is in progress.
+	Get(ctx context.Context, key string) (*Recorded, error)
+
+	// Reserve holds key for a request in progress until ttl elapses, unless
+	// key is already held or has a recorded response, and reports whether
+	// it did. It must be atomic, so that only one of concurrent duplicates
+	// holds key.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// Set records the response for key, expiring it after ttl.
+	Set(ctx context.Context, key string, r *Recorded, ttl time.Duration) error
+
+	// Release removes the hold of key, unless a response was recorded for it.
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig configures the idempotency middleware.
+type IdempotencyConfig struct {
+	Skipper    func(c slim.Context) bool
+	Store      IdempotencyStore // Required
+	TTL        time.Duration    // How long responses are replayed, defaults to 24 hours
+	PendingTTL time.Duration    // How long a request in progress holds its key, defaults to 1 minute
+	Header     string           // Request header carrying the key, defaults to "Idempotency-Key"
+
+	// KeyFunc returns the caller that keys are scoped to, such as a user,
+	// session or tenant ID, so that a key sent by one caller never replays
+	// the response of another. Required. Requests for which it returns ""
+	// are not deduplicated; return AnyCaller to share keys between all
+	// callers, for example for anonymous requests.
+	KeyFunc func(c slim.Context) string
+}
+
+// AnyCaller is the scope KeyFunc returns for requests whose keys are shared
+// by all callers. Use it only when keys cannot collide between callers, for
+// example when they are random UUIDs generated by clients.
+const AnyCaller = "*"
+
+// idempotencyEntry is the pending record for a request carrying a key.
+type idempotencyEntry struct {
+	store    IdempotencyStore
+	key      string
+	ttl      time.Duration
+	recorded bool // Whether the response was recorded
+}
+
+// ToMiddleware creates a middleware that replays responses for requests
+// carrying an idempotency key. The first request with a given key is handled
+// normally and the envelope written by Respond is recorded; duplicate requests
+// from the same caller (see KeyFunc) with the same key, method and path within
+// the TTL receive the recorded response with an "Idempotent-Replayed: true"
+// header, without reaching the handler again.
+//
+// Duplicates arriving while the first request is in progress are answered
+// with 409 Conflict and a Retry-After header. Server errors (5xx) are not
+// recorded, so that clients can retry them.
+func (config IdempotencyConfig) ToMiddleware() slim.MiddlewareFunc {
+	if config.Store == nil {
+		panic("rsp: idempotency store is required")
+	}
+	if config.KeyFunc == nil {
+		panic("rsp: idempotency key func is required")
+	}
+	ttl := config.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	pending := config.PendingTTL
+	if pending <= 0 {
+		pending = time.Minute
+	}
+	header := config.Header
+	if header == "" {
+		header = "Idempotency-Key"
+	}
+	return func(c slim.Context, next slim.HandlerFunc) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return next(c)
+		}
+		key := c.Header(header)
+		if key == "" {
+			return next(c)
+		}
+		caller := config.KeyFunc(c)
+		if caller == "" {
+			return next(c)
+		}
+		// The caller is hashed so that credentials are not stored in keys
+		sum := sha256.Sum256([]byte(caller))
+		req := c.Request()
+		key = req.Method + " " + req.URL.Path + " " + hex.EncodeToString(sum[:]) + " " + key
+
+		entry := &idempotencyEntry{store: config.Store, key: key, ttl: ttl}
+		return idempotent(c, entry, pending, next, func(r *Recorded) error {
+			return write(c, negotiate(c), r)
+		}, func() error {
+			return Respond(c, Error(errIdempotencyConflict), Header("Retry-After", "1"))
+		})
+	}
+}
+
+// errIdempotencyConflict is responded to duplicates of a request in progress.
+var errIdempotencyConflict = &idempotencyConflict{}
+
+// idempotencyConflict is the error responded to duplicates of a request in
+// progress.
+type idempotencyConflict struct{}
+
+func (e *idempotencyConflict) Error() string {
+	return "rsp: a request with the same idempotency key is in progress"
+}
+
+func (e *idempotencyConflict) Status() int  { return http.StatusConflict }
+func (e *idempotencyConflict) Code() string { return "IdempotencyConflict" }
+func (e *idempotencyConflict) Text() string {
+	return "A request with the same idempotency key is in progress"
+}
+func (e *idempotencyConflict) Data() any    { return nil }
+func (e *idempotencyConflict) Cause() error { return nil }
+
+// idempotent handles the request carrying the key of entry: it replays the
+// response recorded for the key, answers with conflict while another request
+// holds the key, or holds the key for pending while next handles the request,
+// releasing it unless the response was recorded. Store failures are logged
+// and the request is handled as if it carried no key.
+func idempotent(c slim.Context, entry *idempotencyEntry, pending time.Duration, next slim.HandlerFunc, replay func(*Recorded) error, conflict func() error) error {
+	ctx := c.Request().Context()
+	reserved, err := entry.store.Reserve(ctx, entry.key, pending)
+	if err != nil {
+		l4g.Error("Failed to reserve idempotency key", l4g.String("error", err.Error()))
+		return next(c)
+	}
+	if !reserved {
+		r, err := entry.store.Get(ctx, entry.key)
+		if err != nil {
+			l4g.Error("Failed to load idempotent response", l4g.String("error", err.Error()))
+			return next(c)
+		}
+		if r == nil {
+			return conflict()
+		}
+		c.SetHeader("Idempotent-Replayed", "true")
+		return replay(r)
+	}
+
+	c.Set(idempotencyContextKey, entry)
+	defer func() {
+		if entry.recorded {
+			return
+		}
+		// Let the request be retried
+		if err := entry.store.Release(context.WithoutCancel(ctx), entry.key); err != nil {
+			l4g.Error("Failed to release idempotency key", l4g.String("error", err.Error()))
+		}
+	}()
+	return next(c)
+}
+
+// Idempotency creates the idempotency middleware from the given config.
+//
+// Example:
+//
+//	app.Use(rsp.Idempotency(rsp.IdempotencyConfig{
+//		Store:   rsp.NewRedisStore(rdb, "idempotency"),
+//		KeyFunc: func(c slim.Context) string { return userID(c) },
+//	}))
+func Idempotency(config IdempotencyConfig) slim.MiddlewareFunc {
+	return config.ToMiddleware()
+}
+
+// replayable returns the response to record for replay if the request
+// carries an idempotency key, or nil. It must be called before the response
+// is written: headers that were already on the response before Respond was
+// called, such as request IDs set by other middleware, are not recorded.
+func replayable(c slim.Context, r *Recorded) *Recorded {
+	if _, ok := c.Get(idempotencyContextKey).(*idempotencyEntry); !ok || r.Status >= http.StatusInternalServerError {
+		return nil
+	}
+	current := c.Response().Header()
+	header := make(http.Header)
+	for key, values := range r.Header {
+		if !slices.Equal(current[key], values) {
+			header[key] = values
+		}
+	}
+	return &Recorded{Status: r.Status, Header: header, Body: r.Body}
+}
+
+// remember records the response returned by replayable once it was written.
+func remember(c slim.Context, recorded *Recorded) {
+	entry, ok := c.Get(idempotencyContextKey).(*idempotencyEntry)
+	if !ok || recorded == nil {
+		return
+	}
+	if err := entry.store.Set(c.Request().Context(), entry.key, recorded, entry.ttl); err != nil {
+		l4g.Error("Failed to record idempotent response", l4g.String("error", err.Error()))
+		return
+	}
+	entry.recorded = true
+}
+
+// memorySweepInterval is the minimum interval between two sweeps of the
+// expired entries of a MemoryStore.
+const memorySweepInterval = time.Minute
+
+// MemoryStore is an in-process IdempotencyStore, suitable for single
+// instance deployments and tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	swept   time.Time // When expired entries were last swept
+}
+
+type memoryEntry struct {
+	r       *Recorded // Recorded response, nil while the key is held by a request in progress
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get implements IdempotencyStore.
+func (s *MemoryStore) Get(_ context.Context, key string) (*Recorded, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(e.expires) {
+		delete(s.entries, key)
+		return nil, nil
+	}
+	return e.r, nil
+}
+
+// Reserve implements IdempotencyStore.
+func (s *MemoryStore) Reserve(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if e, ok := s.entries[key]; ok && !now.After(e.expires) {
+		return false, nil
+	}
+	s.sweep(now)
+	s.entries[key] = memoryEntry{expires: now.Add(ttl)}
+	return true, nil
+}
+
+// Set implements IdempotencyStore.
+func (s *MemoryStore) Set(_ context.Context, key string, r *Recorded, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	s.entries[key] = memoryEntry{
+		r:       &Recorded{Status: r.Status, Header: r.Header.Clone(), Body: r.Body},
+		expires: now.Add(ttl),
+	}
+	return nil
+}
+
+// Release implements IdempotencyStore.
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok && e.r == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// sweep removes the expired entries, at most once per memorySweepInterval so
+// that writes do not scan every entry. Entries read after they expire are
+// removed by Get.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < memorySweepInterval {
+		return
+	}
+	s.swept = now
+	maps.DeleteFunc(s.entries, func(_ string, e memoryEntry) bool {
+		return now.After(e.expires)
+	})
+}
+
+// RedisStore is an IdempotencyStore backed by Redis. Responses are stored as
+// JSON, so replayed data is rendered from its decoded JSON representation,
+// and keys held by requests in progress as empty strings.
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisStore creates a RedisStore using the given client, typically the
+// same client passed to sdm.SetRedis. Keys are stored as "prefix:key".
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) redisKey(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return s.prefix + ":" + key
+}
+
+// Get implements IdempotencyStore.
+func (s *RedisStore) Get(ctx context.Context, key string) (*Recorded, error) {
+	b, err := s.client.Get(ctx, s.redisKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil || len(b) == 0 {
+		return nil, err
+	}
+	r := &Recorded{}
+	if err = json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Set implements IdempotencyStore.
+func (s *RedisStore) Set(ctx context.Context, key string, r *Recorded, ttl time.Duration) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.redisKey(key), b, ttl).Err()
+}
+
+// Reserve implements IdempotencyStore.
+func (s *RedisStore) Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.redisKey(key), "", ttl).Result()
+}
+
+// releaseScript deletes a key held by a request in progress, leaving
+// recorded responses
+var releaseScript = redis.NewScript(`
+	if redis.call("GET", KEYS[1]) == "" then
+		return redis.call("DEL", KEYS[1])
+	end
+	return 0
+`)
+
+// Release implements IdempotencyStore.
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return releaseScript.Run(ctx, s.client, []string{s.redisKey(key)}).Err()
+}
@@ -0,0 +1,294 @@
+package rsp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go-slim.dev/slim"
+)
+
+// authScope scopes idempotency keys to the Authorization header
+func authScope(c slim.Context) string {
+	return c.Header("Authorization")
+}
+
+// serveIdempotent runs the handler behind the idempotency middleware
+func serveIdempotent(mw slim.MiddlewareFunc, method, key string, handler slim.HandlerFunc) *httptest.ResponseRecorder {
+	return serveIdempotentAs(mw, "Bearer alice", method, key, handler)
+}
+
+// serveIdempotentAs runs the handler behind the idempotency middleware with
+// the given Authorization header
+func serveIdempotentAs(mw slim.MiddlewareFunc, auth, method, key string, handler slim.HandlerFunc) *httptest.ResponseRecorder {
+	s := slim.New()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, "/orders", nil)
+	if auth != "" {
+		request.Header.Set("Authorization", auth)
+	}
+	if key != "" {
+		request.Header.Set("Idempotency-Key", key)
+	}
+	_ = mw(s.NewContext(recorder, request), handler)
+	return recorder
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	mw := Idempotency(IdempotencyConfig{Store: NewMemoryStore(), KeyFunc: authScope})
+
+	calls := 0
+	handler := func(c slim.Context) error {
+		calls++
+		c.SetHeader("X-Request-Id", "req-"+strconv.Itoa(calls))
+		return Respond(c, StatusCode(http.StatusCreated), Header("Location", "/orders/1"), Data(map[string]any{"order": calls}))
+	}
+
+	first := serveIdempotent(mw, http.MethodPost, "k1", handler)
+	second := serveIdempotent(mw, http.MethodPost, "k1", handler)
+
+	if calls != 1 {
+		t.Errorf("handler calls = %v, want 1", calls)
+	}
+	if second.Code != http.StatusCreated {
+		t.Errorf("replayed status = %v, want %v", second.Code, http.StatusCreated)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("replayed body = %s, want %s", second.Body.String(), first.Body.String())
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response should carry Idempotent-Replayed header")
+	}
+	if second.Header().Get("Location") != "/orders/1" {
+		t.Errorf("replayed Location = %q, want /orders/1", second.Header().Get("Location"))
+	}
+	if second.Header().Get("X-Request-Id") != "" {
+		t.Error("headers set before Respond should not be replayed")
+	}
+
+	// A different key or method reaches the handler
+	serveIdempotent(mw, http.MethodPost, "k2", handler)
+	serveIdempotent(mw, http.MethodPut, "k1", handler)
+	if calls != 3 {
+		t.Errorf("handler calls = %v, want 3", calls)
+	}
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	mw := Idempotency(IdempotencyConfig{Store: NewMemoryStore(), KeyFunc: authScope})
+
+	calls := 0
+	handler := func(c slim.Context) error {
+		calls++
+		return Ok(c)
+	}
+	serveIdempotent(mw, http.MethodPost, "", handler)
+	serveIdempotent(mw, http.MethodPost, "", handler)
+
+	if calls != 2 {
+		t.Errorf("handler calls = %v, want 2", calls)
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	calls := 0
+	handler := func(c slim.Context) error {
+		calls++
+		return Ok(c, Data(calls))
+	}
+
+	mw := Idempotency(IdempotencyConfig{Store: NewMemoryStore(), KeyFunc: authScope})
+	serveIdempotentAs(mw, "Bearer alice", http.MethodPost, "k1", handler)
+	if rec := serveIdempotentAs(mw, "Bearer bob", http.MethodPost, "k1", handler); rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("the response of one caller should not be replayed to another")
+	}
+	// Requests without a scope are not deduplicated
+	serveIdempotentAs(mw, "", http.MethodPost, "k1", handler)
+	serveIdempotentAs(mw, "", http.MethodPost, "k1", handler)
+	if calls != 4 {
+		t.Errorf("handler calls = %v, want 4", calls)
+	}
+
+	calls = 0
+	mw = Idempotency(IdempotencyConfig{
+		Store:   NewMemoryStore(),
+		KeyFunc: func(c slim.Context) string { return AnyCaller },
+	})
+	serveIdempotentAs(mw, "", http.MethodPost, "k1", handler)
+	if rec := serveIdempotentAs(mw, "", http.MethodPost, "k1", handler); rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("requests in the scope of KeyFunc should be replayed")
+	}
+	if calls != 1 {
+		t.Errorf("handler calls = %v, want 1", calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	mw := Idempotency(IdempotencyConfig{Store: NewMemoryStore(), KeyFunc: authScope})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(c slim.Context) error {
+		close(started)
+		<-release
+		return Respond(c, StatusCode(http.StatusCreated))
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- serveIdempotent(mw, http.MethodPost, "k1", handler)
+	}()
+	<-started
+
+	duplicate := serveIdempotent(mw, http.MethodPost, "k1", handler)
+	if duplicate.Code != http.StatusConflict {
+		t.Errorf("duplicate status = %v, want %v", duplicate.Code, http.StatusConflict)
+	}
+	if duplicate.Header().Get("Retry-After") == "" {
+		t.Error("duplicate response should carry Retry-After header")
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first status = %v, want %v", first.Code, http.StatusCreated)
+	}
+	if rec := serveIdempotent(mw, http.MethodPost, "k1", handler); rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry = %v, want replayed %v", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotencySkipsServerErrors(t *testing.T) {
+	mw := Idempotency(IdempotencyConfig{Store: NewMemoryStore(), KeyFunc: authScope})
+
+	calls := 0
+	handler := func(c slim.Context) error {
+		calls++
+		return Respond(c, Error(ErrInternal))
+	}
+	serveIdempotent(mw, http.MethodPost, "k1", handler)
+	serveIdempotent(mw, http.MethodPost, "k1", handler)
+
+	if calls != 2 {
+		t.Errorf("handler calls = %v, want 2", calls)
+	}
+}
+
+func TestIdempotencySkipsFailedWrites(t *testing.T) {
+	mw := Idempotency(IdempotencyConfig{Store: NewMemoryStore(), KeyFunc: authScope})
+
+	calls := 0
+	handler := func(c slim.Context) error {
+		calls++
+		if calls == 1 {
+			// Cannot be encoded, so the fallback envelope is written instead
+			return Ok(c, map[string]any{"ch": make(chan int)})
+		}
+		return Respond(c, StatusCode(http.StatusCreated))
+	}
+
+	if rec := serveIdempotent(mw, http.MethodPost, "k1", handler); rec.Code != http.StatusInternalServerError {
+		t.Errorf("failed status = %v, want %v", rec.Code, http.StatusInternalServerError)
+	}
+	if rec := serveIdempotent(mw, http.MethodPost, "k1", handler); rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("retry = %v, want handled %v", rec.Code, http.StatusCreated)
+	}
+	if calls != 2 {
+		t.Errorf("handler calls = %v, want 2", calls)
+	}
+}
+
+func TestIdempotencyRequiresKeyFunc(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Idempotency() without KeyFunc should panic")
+		}
+	}()
+	Idempotency(IdempotencyConfig{Store: NewMemoryStore()})
+}
+
+func TestMemoryStoreExpiration(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if err := store.Set(ctx, "key", &Recorded{Status: http.StatusOK}, time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	r, err := store.Get(ctx, "key")
+	if err != nil || r != nil {
+		t.Errorf("Get() = %v, %v, want expired", r, err)
+	}
+}
+
+func TestMemoryStoreReserve(t *testing.T) {
+	testStoreReserve(t, NewMemoryStore())
+}
+
+func TestRedisStoreReserve(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	testStoreReserve(t, NewRedisStore(client, "idempotency"))
+}
+
+// testStoreReserve checks that keys are held and released as expected
+func testStoreReserve(t *testing.T, store IdempotencyStore) {
+	ctx := context.Background()
+
+	if ok, err := store.Reserve(ctx, "key", time.Minute); !ok || err != nil {
+		t.Fatalf("Reserve() = %v, %v, want true", ok, err)
+	}
+	if ok, err := store.Reserve(ctx, "key", time.Minute); ok || err != nil {
+		t.Errorf("Reserve() of a held key = %v, %v, want false", ok, err)
+	}
+	if r, err := store.Get(ctx, "key"); r != nil || err != nil {
+		t.Errorf("Get() of a held key = %v, %v, want nil", r, err)
+	}
+
+	if err := store.Release(ctx, "key"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if ok, err := store.Reserve(ctx, "key", time.Minute); !ok || err != nil {
+		t.Fatalf("Reserve() of a released key = %v, %v, want true", ok, err)
+	}
+
+	// Recorded responses are neither released nor reserved again
+	if err := store.Set(ctx, "key", &Recorded{Status: http.StatusOK}, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := store.Release(ctx, "key"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if ok, err := store.Reserve(ctx, "key", time.Minute); ok || err != nil {
+		t.Errorf("Reserve() of a recorded key = %v, %v, want false", ok, err)
+	}
+	if r, err := store.Get(ctx, "key"); r == nil || r.Status != http.StatusOK || err != nil {
+		t.Errorf("Get() = %v, %v, want the recorded response", r, err)
+	}
+}
+
+func TestRecordedJSON(t *testing.T) {
+	r := &Recorded{
+		Status: http.StatusCreated,
+		Header: http.Header{"Location": {"/orders/1"}},
+		Body:   slim.Map{"code": "OK", "ok": true},
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded Recorded
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.Status != r.Status || decoded.Header.Get("Location") != "/orders/1" || decoded.Code() != "OK" {
+		t.Errorf("decoded = %+v, want %+v", decoded, r)
+	}
+}
@@ -0,0 +1,195 @@
+package rsp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+
+	"go-slim.dev/l4g"
+	"go-slim.dev/slim"
+)
+
+// SizePolicy determines what happens when a response exceeds its size limit.
+type SizePolicy int
+
+const (
+	// SizeError replaces the response with a 500 ResponseTooLarge envelope.
+	SizeError SizePolicy = iota
+
+	// SizeTruncate drops trailing elements of slice data until the response
+	// fits, and sets the "truncated" meta flag. Responses that cannot be
+	// truncated to fit are handled like SizeError.
+	SizeTruncate
+
+	// SizeStream streams the response without buffering it, like responses
+	// exceeding StreamThreshold, so its size does not affect memory usage.
+	// Only JSON responses can be streamed; other formats are rendered in full.
+	SizeStream
+)
+
+var (
+	// MaxResponseSize is the maximum size in bytes of responses, enforced
+	// according to ResponseSizePolicy. Zero means no limit.
+	//
+	// The size is measured on the JSON encoding of the envelope, whatever
+	// the negotiated format, which is exact for JSON responses and a close
+	// estimate for the others.
+	MaxResponseSize int
+
+	// ResponseSizePolicy is the policy applied to responses exceeding
+	// MaxResponseSize.
+	ResponseSizePolicy SizePolicy
+)
+
+var errTooLarge = errors.New("rsp: response too large")
+
+// sizeLimit is the per-response size limit configured by SizeLimit.
+type sizeLimit struct {
+	max    int
+	policy SizePolicy
+}
+
+// SizeLimit configures the maximum size in bytes of the response and the
+// policy to apply when it is exceeded, overriding MaxResponseSize and
+// ResponseSizePolicy. A max of zero disables the limit for the response.
+//
+// Example:
+//
+//	rsp.Respond(c, rsp.Data(rows), rsp.SizeLimit(10<<20, rsp.SizeTruncate))
+func SizeLimit(max int, policy SizePolicy) Option {
+	return func(o *options) {
+		o.sizeLimit = &sizeLimit{max: max, policy: policy}
+	}
+}
+
+// limitedBuffer is a buffer that refuses writes beyond max bytes, so that
+// measuring an oversized response stops early instead of encoding it all.
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.max {
+		return 0, errTooLarge
+	}
+	return b.buf.Write(p)
+}
+
+// encodeLimited encodes the envelope, returning errTooLarge if it
+// exceeds max bytes.
+func encodeLimited(m slim.Map, max int) ([]byte, error) {
+	b := &limitedBuffer{max: max}
+	if err := encodeEnvelope(b, m); err != nil {
+		return nil, err
+	}
+	return b.buf.Bytes(), nil
+}
+
+// limitSize enforces the size limit on a recorded response. Responses
+// within the limit keep their encoded body, so that JSON responses are not
+// encoded twice.
+func limitSize(c slim.Context, o *options, r *Recorded) {
+	max, policy := MaxResponseSize, ResponseSizePolicy
+	if o.sizeLimit != nil {
+		max, policy = o.sizeLimit.max, o.sizeLimit.policy
+	}
+	if max <= 0 {
+		return
+	}
+
+	b, err := encodeLimited(r.Body, max)
+	if err == nil {
+		r.encoded = b
+		return
+	}
+	if !errors.Is(err, errTooLarge) {
+		// Leave encoding errors to the rendering
+		return
+	}
+
+	switch policy {
+	case SizeStream:
+		r.stream = true
+		return
+	case SizeTruncate:
+		if truncate(r, max) {
+			return
+		}
+	}
+
+	l4g.Error("Response exceeds the maximum size", l4g.Int("max", max))
+	// Build the envelope like any other error response, keeping the meta
+	status, m := result(c, newOptions([]Option{Error(&responseTooLarge{max: max})}))
+	if len(o.meta) > 0 {
+		m["meta"] = maps.Clone(o.meta)
+	}
+	if o.graphql {
+		m = toGraphQL(m)
+	}
+	r.Status = status
+	r.Body = m
+}
+
+// responseTooLarge is the error responded in place of a response exceeding
+// its size limit.
+type responseTooLarge struct {
+	max int
+}
+
+func (e *responseTooLarge) Error() string {
+	return fmt.Sprintf("response exceeds the maximum size of %d bytes", e.max)
+}
+
+func (e *responseTooLarge) Status() int  { return http.StatusInternalServerError }
+func (e *responseTooLarge) Code() string { return "ResponseTooLarge" }
+func (e *responseTooLarge) Text() string { return "The response is too large" }
+func (e *responseTooLarge) Data() any    { return nil }
+func (e *responseTooLarge) Cause() error { return nil }
+
+// truncate finds the largest prefix of slice data for which the response
+// fits within max bytes, marking it with the "truncated" meta flag.
+// It reports false if the data is not a slice or no prefix fits.
+func truncate(r *Recorded, max int) bool {
+	v := reflect.ValueOf(r.Body["data"])
+	if v.Kind() != reflect.Slice {
+		return false
+	}
+
+	meta := map[string]any{}
+	if m, ok := r.Body["meta"].(map[string]any); ok {
+		meta = maps.Clone(m)
+	}
+	meta["truncated"] = true
+
+	m := maps.Clone(r.Body)
+	m["meta"] = meta
+
+	// Binary search for the largest number of elements that fits
+	var best []byte
+	var bestN int
+	lo, hi := 0, v.Len()-1
+	for lo <= hi {
+		n := (lo + hi) / 2
+		m["data"] = v.Slice(0, n).Interface()
+		if b, err := encodeLimited(m, max); err == nil {
+			best, bestN = b, n
+			lo = n + 1
+		} else if errors.Is(err, errTooLarge) {
+			hi = n - 1
+		} else {
+			return false
+		}
+	}
+	if best == nil {
+		return false
+	}
+
+	m["data"] = v.Slice(0, bestN).Interface()
+	r.Body = m
+	r.encoded = best
+	return true
+}
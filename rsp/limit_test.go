@@ -0,0 +1,163 @@
+package rsp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-slim.dev/slim"
+)
+
+func TestSizeLimitWithinLimit(t *testing.T) {
+	ctx, recorder := createContext()
+
+	if err := Respond(ctx, Data(largeData(3)), SizeLimit(1<<10, SizeError)); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Data []TestData `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON response = %v", err)
+	}
+	if len(response.Data) != 3 {
+		t.Errorf("data length = %v, want 3", len(response.Data))
+	}
+}
+
+func TestSizeLimitError(t *testing.T) {
+	ctx, recorder := createContextWithDebug(true)
+
+	if err := Respond(ctx, Data(largeData(100)), SizeLimit(256, SizeError)); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusInternalServerError)
+	}
+
+	var response map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON response = %v", err)
+	}
+	if response["code"] != "ResponseTooLarge" {
+		t.Errorf("code = %v, want ResponseTooLarge", response["code"])
+	}
+	if detail, _ := response["error"].(string); !strings.Contains(detail, "256") {
+		t.Errorf("error = %q, want size detail in debug mode", detail)
+	}
+}
+
+func TestSizeLimitErrorRendering(t *testing.T) {
+	original := TextMarshaller
+	defer func() { TextMarshaller = original }()
+	var rendered map[string]any
+	TextMarshaller = func(m map[string]any) (string, error) {
+		rendered = m
+		return "too large", nil
+	}
+
+	ctx, recorder := createContextWithAccept("text/plain")
+	if err := Respond(ctx, Data(largeData(100)), Meta("page", 1), SizeLimit(256, SizeError)); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	// The error envelope is rendered in the negotiated format
+	if recorder.Body.String() != "too large" {
+		t.Errorf("body = %q, want the output of TextMarshaller", recorder.Body.String())
+	}
+	if rendered["code"] != "ResponseTooLarge" || rendered["ok"] != false || rendered["msg"] != "The response is too large" {
+		t.Errorf("envelope = %v", rendered)
+	}
+	if meta, _ := rendered["meta"].(map[string]any); meta["page"] != 1 {
+		t.Errorf("meta = %v, want page 1", rendered["meta"])
+	}
+}
+
+func TestSizeLimitTruncate(t *testing.T) {
+	ctx, recorder := createContext()
+
+	if err := Respond(ctx, Data(largeData(100)), Meta("page", 1), SizeLimit(512, SizeTruncate)); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusOK)
+	}
+	if recorder.Body.Len() > 512 {
+		t.Errorf("body size = %v, want at most 512", recorder.Body.Len())
+	}
+
+	var response struct {
+		Data []TestData     `json:"data"`
+		Meta map[string]any `json:"meta"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON response = %v", err)
+	}
+	if len(response.Data) == 0 || len(response.Data) >= 100 {
+		t.Errorf("data length = %v, want truncated", len(response.Data))
+	}
+	if response.Meta["truncated"] != true || response.Meta["page"] != float64(1) {
+		t.Errorf("meta = %v, want truncated flag alongside existing meta", response.Meta)
+	}
+
+	// One more element would not fit
+	var body slim.Map
+	_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+	body["data"] = largeData(len(response.Data) + 1)
+	if _, err := encodeLimited(body, 512); err == nil {
+		t.Errorf("truncated to %v elements, but one more fits", len(response.Data))
+	}
+}
+
+func TestSizeLimitTruncateNonSlice(t *testing.T) {
+	ctx, _ := createContext()
+
+	rec, err := Capture(ctx, Data(strings.Repeat("x", 1024)), SizeLimit(256, SizeTruncate))
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	AssertStatus(t, rec, http.StatusInternalServerError)
+	AssertCode(t, rec, "ResponseTooLarge")
+}
+
+func TestSizeLimitStream(t *testing.T) {
+	ctx, recorder := createContext()
+
+	if err := Respond(ctx, Data(largeData(100)), SizeLimit(256, SizeStream)); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	var response struct {
+		Data []TestData `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON response = %v", err)
+	}
+	if len(response.Data) != 100 {
+		t.Errorf("data length = %v, want 100", len(response.Data))
+	}
+}
+
+func TestMaxResponseSizeGlobal(t *testing.T) {
+	max, policy := MaxResponseSize, ResponseSizePolicy
+	defer func() { MaxResponseSize, ResponseSizePolicy = max, policy }()
+	MaxResponseSize, ResponseSizePolicy = 256, SizeError
+
+	ctx, _ := createContext()
+	rec, _ := Capture(ctx, Data(largeData(100)))
+	AssertCode(t, rec, "ResponseTooLarge")
+
+	// A per-response limit overrides the global one
+	rec, _ = Capture(ctx, Data(largeData(100)), SizeLimit(0, SizeError))
+	AssertCode(t, rec, "OK")
+
+	// Other formats are limited too
+	ctx, _ = createContextWithAccept("text/plain")
+	rec, _ = Capture(ctx, Data(largeData(100)))
+	AssertCode(t, rec, "ResponseTooLarge")
+}
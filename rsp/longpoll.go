@@ -0,0 +1,62 @@
+package rsp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go-slim.dev/slim"
+)
+
+// LongPollTimeoutStatus is the status LongPoll responds with when no data
+// became available before the timeout: http.StatusNoContent (the default),
+// which has no body, or http.StatusRequestTimeout, which carries an envelope
+// with the "Timeout" code.
+var LongPollTimeoutStatus = http.StatusNoContent
+
+// pollTimeout is the error rendered when a long poll times out.
+type pollTimeout struct{}
+
+func (pollTimeout) Error() string { return "rsp: long poll timed out" }
+func (pollTimeout) Status() int   { return http.StatusRequestTimeout }
+func (pollTimeout) Code() string  { return "Timeout" }
+func (pollTimeout) Text() string  { return "No data became available in time" }
+func (pollTimeout) Data() any     { return nil }
+func (pollTimeout) Cause() error  { return nil }
+
+// LongPoll blocks until wait returns or the timeout expires. The context
+// passed to wait is canceled at the deadline or when the client disconnects.
+//
+// When wait returns data, it responds with 200 and the data. If wait fails
+// after the deadline, the poll has timed out and it responds according to
+// LongPollTimeoutStatus; so does a deadline of the request context, such as
+// one set by a timeout middleware. Other errors are rendered like Error.
+// Nothing is written once the client has disconnected.
+//
+// Example:
+//
+//	return rsp.LongPoll(c, func(ctx context.Context) (any, error) {
+//		return events.Next(ctx, cursor)
+//	}, 30*time.Second)
+func LongPoll(c slim.Context, wait func(ctx context.Context) (any, error), timeout time.Duration) error {
+	parent := c.Request().Context()
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	data, err := wait(ctx)
+	switch {
+	case errors.Is(parent.Err(), context.Canceled):
+		// The client is gone, there is nobody to respond to
+		return nil
+	case err == nil:
+		return Ok(c, data)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		if LongPollTimeoutStatus == http.StatusNoContent {
+			return c.NoContent(http.StatusNoContent)
+		}
+		return Respond(c, StatusCode(LongPollTimeoutStatus), Error(pollTimeout{}))
+	default:
+		return Respond(c, Error(err))
+	}
+}
@@ -0,0 +1,113 @@
+package rsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLongPollData(t *testing.T) {
+	ctx, recorder := createContextWithAccept("application/json")
+
+	err := LongPoll(ctx, func(ctx context.Context) (any, error) {
+		return []string{"event"}, nil
+	}, time.Second)
+	if err != nil {
+		t.Fatalf("LongPoll() error = %v", err)
+	}
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusOK)
+	}
+	var response map[string]any
+	_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+	if data, _ := response["data"].([]any); len(data) != 1 {
+		t.Errorf("data = %v, want one event", response["data"])
+	}
+}
+
+func TestLongPollTimeout(t *testing.T) {
+	wait := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, recorder := createContextWithAccept("application/json")
+	if err := LongPoll(ctx, wait, 10*time.Millisecond); err != nil {
+		t.Fatalf("LongPoll() error = %v", err)
+	}
+	if recorder.Code != http.StatusNoContent || recorder.Body.Len() != 0 {
+		t.Errorf("response = %v %q, want empty 204", recorder.Code, recorder.Body.String())
+	}
+
+	LongPollTimeoutStatus = http.StatusRequestTimeout
+	defer func() { LongPollTimeoutStatus = http.StatusNoContent }()
+
+	ctx, recorder = createContextWithAccept("application/json")
+	if err := LongPoll(ctx, wait, 10*time.Millisecond); err != nil {
+		t.Fatalf("LongPoll() error = %v", err)
+	}
+	if recorder.Code != http.StatusRequestTimeout {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusRequestTimeout)
+	}
+	var response map[string]any
+	_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+	if response["code"] != "Timeout" {
+		t.Errorf("code = %v, want Timeout", response["code"])
+	}
+}
+
+func TestLongPollError(t *testing.T) {
+	ctx, recorder := createContextWithAccept("application/json")
+
+	err := LongPoll(ctx, func(ctx context.Context) (any, error) {
+		return nil, errors.New("broker unavailable")
+	}, time.Second)
+	if err != nil {
+		t.Fatalf("LongPoll() error = %v", err)
+	}
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestLongPollClientDisconnect(t *testing.T) {
+	ctx, recorder := createContextWithAccept("application/json")
+	parent, cancel := context.WithCancel(context.Background())
+	ctx.SetRequest(ctx.Request().WithContext(parent))
+
+	err := LongPoll(ctx, func(ctx context.Context) (any, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, time.Second)
+	if err != nil {
+		t.Fatalf("LongPoll() error = %v", err)
+	}
+	if ctx.Written() {
+		t.Errorf("response written with status %v after the client disconnected", recorder.Code)
+	}
+}
+
+func TestLongPollRequestDeadline(t *testing.T) {
+	ctx, recorder := createContextWithAccept("application/json")
+	parent, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ctx.SetRequest(ctx.Request().WithContext(parent))
+
+	// The deadline of the request, such as one set by a timeout middleware,
+	// expires before the timeout of the poll
+	err := LongPoll(ctx, func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, time.Second)
+	if err != nil {
+		t.Fatalf("LongPoll() error = %v", err)
+	}
+	if recorder.Code != http.StatusNoContent || !ctx.Written() {
+		t.Errorf("response = %v, written = %v, want 204", recorder.Code, ctx.Written())
+	}
+}
@@ -0,0 +1,129 @@
+package rsp
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"go-slim.dev/slim"
+)
+
+// offerTypes maps the short names accepted by Negotiate to media types.
+var offerTypes = map[string]string{
+	"html":  "text/html",
+	"json":  "application/json",
+	"jsonp": "application/javascript",
+	"xml":   "application/xml",
+	"text":  "text/plain",
+}
+
+// acceptRange is a media range of the Accept header.
+type acceptRange struct {
+	typ, sub string
+	q        float64
+}
+
+// Negotiate returns the offer that best matches the Accept header of the
+// request, or an empty string if none is acceptable. Offers are media types,
+// possibly with wildcards such as "text/*", or short names such as "json"
+// and "html".
+//
+// Each offer gets the quality of the most specific media range matching it,
+// so "text/html;q=0.8, */*" prefers any other offer over "html". Offers with
+// the same quality are ranked by the specificity of their matching range,
+// then by their order. The first offer is returned when the request has no
+// Accept header.
+//
+// Example:
+//
+//	switch rsp.Negotiate(c, "json", "text/csv") {
+//	case "text/csv":
+//		return exportCSV(c, rows)
+//	default:
+//		return rsp.Ok(c, rows)
+//	}
+func Negotiate(c slim.Context, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	header := c.Request().Header.Get("Accept")
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+	ranges := parseAccept(header)
+
+	best, bestQ, bestSpec := "", 0.0, 0
+	for _, offer := range offers {
+		typ, sub := offerType(offer)
+		q, spec := 0.0, 0
+		for _, r := range ranges {
+			s, ok := r.match(typ, sub)
+			if ok && s > spec {
+				q, spec = r.q, s
+			}
+		}
+		if q > bestQ || (q == bestQ && q > 0 && spec > bestSpec) {
+			best, bestQ, bestSpec = offer, q, spec
+		}
+	}
+	return best
+}
+
+// offerType returns the media type and subtype of an offer.
+func offerType(offer string) (string, string) {
+	t, ok := offerTypes[offer]
+	if !ok {
+		t = offer
+		if !strings.Contains(t, "/") {
+			t, _, _ = strings.Cut(mime.TypeByExtension("."+offer), ";")
+		}
+	}
+	typ, sub, _ := strings.Cut(strings.ToLower(t), "/")
+	return typ, sub
+}
+
+// parseAccept parses the media ranges of an Accept header. Ranges with an
+// invalid quality are treated as not acceptable.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		media, params, _ := strings.Cut(part, ";")
+		typ, sub, ok := strings.Cut(strings.ToLower(strings.TrimSpace(media)), "/")
+		if !ok {
+			if typ != "*" {
+				continue
+			}
+			sub = "*"
+		}
+		r := acceptRange{typ: strings.TrimSpace(typ), sub: strings.TrimSpace(sub), q: 1}
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+				r.q = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// match reports whether the range matches the offered type and how specific
+// the match is: 3 for an exact match, 2 for "type/*" and 1 for "*/*".
+func (r acceptRange) match(typ, sub string) (int, bool) {
+	switch {
+	case r.typ == "*":
+		return 1, true
+	case r.typ != typ && typ != "*":
+		return 0, false
+	case r.sub == "*":
+		return 2, true
+	case r.sub != sub && sub != "*":
+		return 0, false
+	default:
+		return 3, true
+	}
+}
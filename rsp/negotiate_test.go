@@ -0,0 +1,68 @@
+package rsp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"html", "json", "jsonp", "xml", "text", "text/*"}
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "html"},
+		{"application/json", "json"},
+		{"text/html;q=0.8, application/json;q=0.9", "json"},
+		{"text/html;q=0.8, */*", "json"},
+		{"application/json, */*", "json"},
+		{"*/*", "html"},
+		{"text/*;q=0.5, text/plain", "text"},
+		{"application/javascript;q=1.0, application/json;q=0.1", "jsonp"},
+		{"APPLICATION/XML", "xml"},
+		{"text/html;q=0, */*;q=0.1", "json"},
+		{"image/png", ""},
+		{"application/json;q=oops, text/plain", "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			ctx, _ := createContextWithAccept(tt.accept)
+			if got := Negotiate(ctx, offers...); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiateMediaTypes(t *testing.T) {
+	ctx, _ := createContextWithAccept("text/csv, application/json;q=0.5")
+
+	if got := Negotiate(ctx, "json", "text/csv"); got != "text/csv" {
+		t.Errorf("Negotiate() = %q, want text/csv", got)
+	}
+	ctx, _ = createContextWithAccept("application/pdf, application/json;q=0.5")
+	if got := Negotiate(ctx, "json", "pdf"); got != "pdf" {
+		t.Errorf("Negotiate() = %q, want pdf", got)
+	}
+	if got := Negotiate(ctx); got != "" {
+		t.Errorf("Negotiate() without offers = %q, want empty", got)
+	}
+}
+
+func TestRespondWithoutPreference(t *testing.T) {
+	// Clients without a preference receive the first format, as before
+	// quality values were supported
+	for _, accept := range []string{"", "*/*"} {
+		t.Run(accept, func(t *testing.T) {
+			ctx, recorder := createContextWithAccept(accept)
+			if err := Ok(ctx); err != nil {
+				t.Fatalf("Ok() error = %v", err)
+			}
+			if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+				t.Errorf("Content-Type = %q, want HTML", got)
+			}
+		})
+	}
+}
@@ -18,9 +18,12 @@
 package rsp
 
 import (
+	"encoding/json"
+	"maps"
 	"net/http"
 
 	"go-slim.dev/l4g"
+	"go-slim.dev/slim"
 )
 
 // options holds all the configurable parameters for an HTTP response.
@@ -33,6 +36,27 @@ type options struct {
 	err     error             // Error to include in the response (if any)
 	message string            // Custom message for the response
 	data    any               // Data payload to include in the response
+
+	security *SecurityPolicy // Security headers to apply to the response
+	merge    map[string]any  // Fields merged into the data payload
+	meta     map[string]any  // Metadata rendered in the "meta" field
+
+	sizeLimit *sizeLimit // Per-response size limit
+	vary      []string   // Custom values appended to the Vary header
+	graphql   bool       // Render in the GraphQL response format
+}
+
+// newOptions applies the given options and resolves the fields that depend
+// on more than one option, such as merged data.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, option := range opts {
+		option(o)
+	}
+	if len(o.merge) > 0 {
+		o.data = mergeData(o.data, o.merge)
+	}
+	return o
 }
 
 // Option is a function type that configures response options.
@@ -158,6 +182,97 @@ func Data(data any) Option {
 	}
 }
 
+// Meta configures a metadata entry for the response. Metadata is rendered in
+// the "meta" field of the envelope, separate from data, and is meant for
+// cross-cutting information such as the server time, API version or feature
+// flags. Multiple Meta calls can be made to set multiple entries.
+//
+// Parameters:
+//   - key: The metadata key
+//   - value: The metadata value (any JSON-serializable type)
+//
+// Returns:
+//   - Option: A function that configures the metadata entry when applied
+//
+// Example:
+//
+//	rsp.Respond(c,
+//		rsp.Data(items),
+//		rsp.Meta("api_version", "2024-01"),
+//		rsp.Meta("server_time", time.Now()),
+//	)
+func Meta(key string, value any) Option {
+	return func(o *options) {
+		if o.meta == nil {
+			o.meta = make(map[string]any)
+		}
+		o.meta[key] = value
+	}
+}
+
+// DataIf configures the data payload only if cond is true.
+// It is useful for composing responses without branching in handlers.
+//
+// Example:
+//
+//	rsp.Respond(c, rsp.Data(publicProfile), rsp.DataIf(isOwner, fullProfile))
+func DataIf(cond bool, data any) Option {
+	return func(o *options) {
+		if cond {
+			o.data = data
+		}
+	}
+}
+
+// Merge configures fields to be merged into the data payload.
+// Multiple Merge calls accumulate, and later values win on key conflicts.
+// Merged fields also take precedence over fields of the data payload.
+//
+// If no data is configured, the merged fields become the data. Maps are
+// copied before merging, and structs are converted to a map through their
+// JSON representation. Data that does not encode as a JSON object cannot be
+// merged into; it is kept as is and the merged fields are dropped.
+//
+// Example:
+//
+//	opts := []rsp.Option{rsp.Data(user)}
+//	if isAdmin {
+//		opts = append(opts, rsp.Merge(map[string]any{"permissions": perms}))
+//	}
+//	rsp.Respond(c, opts...)
+func Merge(fields map[string]any) Option {
+	return func(o *options) {
+		if o.merge == nil {
+			o.merge = make(map[string]any, len(fields))
+		}
+		maps.Copy(o.merge, fields)
+	}
+}
+
+// mergeData merges fields into a copy of data as described by Merge.
+func mergeData(data any, fields map[string]any) any {
+	var m map[string]any
+	switch d := data.(type) {
+	case nil:
+		m = make(map[string]any, len(fields))
+	case map[string]any:
+		m = maps.Clone(d)
+	case slim.Map:
+		m = maps.Clone(map[string]any(d))
+	default:
+		b, err := json.Marshal(data)
+		if err == nil {
+			err = json.Unmarshal(b, &m)
+		}
+		if err != nil || m == nil {
+			l4g.Error("Cannot merge fields into non-object response data")
+			return data
+		}
+	}
+	maps.Copy(m, fields)
+	return m
+}
+
 // Error configures an error for the response.
 // This error will be included in the response and processed
 // according to the error handling logic.
@@ -671,3 +671,80 @@ func BenchmarkOptionCombination(b *testing.B) {
 		}
 	}
 }
+
+func TestDataIfOption(t *testing.T) {
+	o := newOptions([]Option{Data("public"), DataIf(false, "private")})
+	if o.data != "public" {
+		t.Errorf("DataIf(false) data = %v, want public", o.data)
+	}
+
+	o = newOptions([]Option{Data("public"), DataIf(true, "private")})
+	if o.data != "private" {
+		t.Errorf("DataIf(true) data = %v, want private", o.data)
+	}
+}
+
+func TestMergeOption(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want any
+	}{
+		{
+			name: "Merge without data",
+			opts: []Option{Merge(map[string]any{"a": 1})},
+			want: map[string]any{"a": 1},
+		},
+		{
+			name: "Merge into map data",
+			opts: []Option{Data(map[string]any{"a": 1, "b": 2}), Merge(map[string]any{"b": 3})},
+			want: map[string]any{"a": 1, "b": 3},
+		},
+		{
+			name: "Merge accumulates",
+			opts: []Option{Merge(map[string]any{"a": 1}), Merge(map[string]any{"b": 2})},
+			want: map[string]any{"a": 1, "b": 2},
+		},
+		{
+			name: "Merge into struct data",
+			opts: []Option{Data(user{ID: 1, Name: "john"}), Merge(map[string]any{"admin": true})},
+			want: map[string]any{"id": float64(1), "name": "john", "admin": true},
+		},
+		{
+			name: "Merge into non-object data",
+			opts: []Option{Data([]int{1, 2}), Merge(map[string]any{"a": 1})},
+			want: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOptions(tt.opts)
+			if !reflect.DeepEqual(o.data, tt.want) {
+				t.Errorf("data = %#v, want %#v", o.data, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDoesNotMutateData(t *testing.T) {
+	data := map[string]any{"a": 1}
+	newOptions([]Option{Data(data), Merge(map[string]any{"b": 2})})
+	if len(data) != 1 {
+		t.Errorf("original data = %v, want unchanged", data)
+	}
+}
+
+func TestMetaOption(t *testing.T) {
+	o := newOptions([]Option{Meta("version", "v1"), Meta("flags", []string{"beta"})})
+
+	want := map[string]any{"version": "v1", "flags": []string{"beta"}}
+	if !reflect.DeepEqual(o.meta, want) {
+		t.Errorf("Meta() = %v, want %v", o.meta, want)
+	}
+}
@@ -0,0 +1,96 @@
+package rsp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+
+	"go-slim.dev/l4g"
+	"go-slim.dev/slim"
+)
+
+// PanicError is the error a recovered panic is converted to.
+type PanicError struct {
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace of the panicking goroutine, if captured
+}
+
+// Error returns the panic value as the error message.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Format formats the error. The %+v verb includes the stack trace, which is
+// what the debug "error" field of the envelope shows.
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	io.WriteString(s, e.Error())
+	if verb == 'v' && s.Flag('+') && len(e.Stack) > 0 {
+		io.WriteString(s, "\n")
+		s.Write(e.Stack)
+	}
+}
+
+// RecoverConfig configures the panic recovery middleware.
+type RecoverConfig struct {
+	Skipper      func(c slim.Context) bool
+	StackSize    int  // Maximum size of the captured stack trace, defaults to 4 KB
+	DisableStack bool // Disables capturing the stack trace
+}
+
+// ToMiddleware creates a middleware that recovers panics raised by the next
+// handlers, logs them with their stack trace, and responds with the standard
+// 500 envelope. As for any other error, the panic value and stack trace are
+// only exposed in the "error" field in debug mode.
+//
+// Panics with http.ErrAbortHandler are re-raised, as they are meant to abort
+// the request without a response.
+func (config RecoverConfig) ToMiddleware() slim.MiddlewareFunc {
+	size := config.StackSize
+	if size <= 0 {
+		size = 4 << 10
+	}
+	return func(c slim.Context, next slim.HandlerFunc) (err error) {
+		if config.Skipper != nil && config.Skipper(c) {
+			return next(c)
+		}
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+			perr := &PanicError{Value: r}
+			if !config.DisableStack {
+				stack := make([]byte, size)
+				perr.Stack = stack[:runtime.Stack(stack, false)]
+			}
+			l4g.Error("Recovered from panic",
+				l4g.String("panic", fmt.Sprint(r)),
+				l4g.String("stack", string(perr.Stack)),
+			)
+			err = Respond(c, Error(perr))
+		}()
+		return next(c)
+	}
+}
+
+// Recover creates the panic recovery middleware, optionally from a config.
+//
+// Example:
+//
+//	app.Use(rsp.Recover())
+func Recover(config ...RecoverConfig) slim.MiddlewareFunc {
+	if len(config) > 0 {
+		return config[0].ToMiddleware()
+	}
+	return RecoverConfig{}.ToMiddleware()
+}
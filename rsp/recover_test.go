@@ -0,0 +1,103 @@
+package rsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-slim.dev/slim"
+)
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name      string
+		debug     bool
+		config    RecoverConfig
+		wantStack bool
+	}{
+		{name: "Production", debug: false},
+		{name: "Debug", debug: true, wantStack: true},
+		{name: "Debug without stack", debug: true, config: RecoverConfig{DisableStack: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, recorder := createContextWithDebug(tt.debug)
+
+			err := Recover(tt.config)(ctx, func(c slim.Context) error {
+				panic("something broke")
+			})
+			if err != nil {
+				t.Fatalf("Recover() error = %v", err)
+			}
+
+			if recorder.Code != http.StatusInternalServerError {
+				t.Errorf("status = %v, want %v", recorder.Code, http.StatusInternalServerError)
+			}
+
+			var response map[string]any
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("invalid JSON response = %v", err)
+			}
+			if response["code"] != "InternalError" || response["ok"] != false {
+				t.Errorf("envelope = %v, want standard 500 envelope", response)
+			}
+
+			detail, _ := response["error"].(string)
+			if tt.debug != (detail != "") {
+				t.Errorf("error field = %q, want present only in debug mode", detail)
+			}
+			if tt.debug && !strings.Contains(detail, "panic: something broke") {
+				t.Errorf("error field = %q, want panic value", detail)
+			}
+			if hasStack := strings.Contains(detail, "goroutine"); hasStack != tt.wantStack {
+				t.Errorf("error field has stack = %v, want %v", hasStack, tt.wantStack)
+			}
+		})
+	}
+}
+
+func TestRecoverWithoutPanic(t *testing.T) {
+	ctx, recorder := createContext()
+
+	err := Recover()(ctx, func(c slim.Context) error {
+		return Ok(c, "fine")
+	})
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	ctx, _ := createContext()
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler to be re-raised", r)
+		}
+	}()
+	_ = Recover()(ctx, func(c slim.Context) error {
+		panic(http.ErrAbortHandler)
+	})
+}
+
+func TestPanicError(t *testing.T) {
+	cause := errors.New("cause")
+	err := &PanicError{Value: cause, Stack: []byte("stack trace")}
+
+	if !errors.Is(err, cause) {
+		t.Error("PanicError should unwrap an error value")
+	}
+	if got := fmt.Sprintf("%v", err); got != "panic: cause" {
+		t.Errorf("%%v = %q", got)
+	}
+	if got := fmt.Sprintf("%+v", err); got != "panic: cause\nstack trace" {
+		t.Errorf("%%+v = %q", got)
+	}
+}
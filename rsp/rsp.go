@@ -29,6 +29,7 @@
 //		"ok": true,
 //		"msg": "OK",
 //		"data": {...},           // optional
+//		"meta": {...},           // optional, response metadata
 //		"problems": {...},       // optional, for validation errors
 //		"error": "..."           // optional, only in debug mode
 //	}
@@ -40,8 +41,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/l4g"
 	"go-slim.dev/misc"
 	"go-slim.dev/slim"
 	"go-slim.dev/v"
@@ -53,6 +57,11 @@ var (
 	// By default, it uses JSON formatting, but can be customized for proper HTML rendering.
 	HTMLMarshaller func(map[string]any) (string, error)
 
+	// HTMLRenderer converts response data maps to HTML with access to the request,
+	// for example to translate server-rendered pages into the request locale
+	// (see TemplateRenderer). When set, it takes precedence over HTMLMarshaller.
+	HTMLRenderer func(c slim.Context, m map[string]any) (string, error)
+
 	// TextMarshaller converts response data maps to plain text format for client responses.
 	// This function is used when the client accepts text/plain or text/* content types.
 	// By default, it uses JSON encoding for text output.
@@ -67,6 +76,21 @@ var (
 	// no JSONP callback is found in the query parameters. This ensures JSONP responses
 	// always have a valid callback function name.
 	DefaultJsonpCallback string
+
+	// FallbackWriter writes a minimal response when rendering the envelope fails,
+	// for example when HTMLMarshaller returns an error or the data cannot be
+	// encoded as JSON. It is only invoked while nothing has been written yet,
+	// so the client never receives a half-written response.
+	// Set it to nil to propagate rendering errors up the stack instead.
+	FallbackWriter func(c slim.Context, err error) error
+
+	// MessagePrinter returns the printer used to localize errors created by
+	// msg.Error (or any other msg.Localizable error): the "msg" field of the
+	// response uses its localized text unless Message was given explicitly.
+	// By default it uses the locale stored in the request context by
+	// msgslim.Middleware with the default msg.Manager. Set it to nil to disable
+	// localization.
+	MessagePrinter func(c slim.Context) msg.Printer
 )
 
 // init initializes the package with default values for marshalling functions
@@ -77,6 +101,13 @@ func init() {
 	HTMLMarshaller = toText
 	JsonpCallbacks = []string{"callback", "cb", "jsonp"}
 	DefaultJsonpCallback = "callback"
+	FallbackWriter = writeFallback
+	MessagePrinter = requestPrinter
+}
+
+// requestPrinter returns the default manager's printer for the request locale.
+func requestPrinter(c slim.Context) msg.Printer {
+	return msg.GetPrinterWithContext(c.Request().Context())
 }
 
 // toText is the default marshaller function that converts a response map to JSON text.
@@ -91,6 +122,35 @@ func toText(m map[string]any) (string, error) {
 	return buf.String(), nil
 }
 
+// fallbackBody is the guaranteed-minimal envelope written by writeFallback.
+// It is pre-encoded so that writing it can never fail due to marshalling.
+var fallbackBody = []byte(`{"code":"InternalError","ok":false,"msg":"An unexpected error occurred"}`)
+
+// writeFallback is the default FallbackWriter. It logs the rendering error and
+// writes a 500 JSON envelope directly to the response writer. In debug mode the
+// rendering error is included in the "error" field.
+func writeFallback(c slim.Context, err error) error {
+	l4g.Error("Failed to render response", l4g.String("error", err.Error()))
+
+	body := fallbackBody
+	if c.Slim().Debug {
+		if b, merr := json.Marshal(slim.Map{
+			"code":  "InternalError",
+			"ok":    false,
+			"msg":   "An unexpected error occurred",
+			"error": fmt.Sprintf("%+v", err),
+		}); merr == nil {
+			body = b
+		}
+	}
+
+	w := c.Response()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	_, err = w.Write(body)
+	return err
+}
+
 // Ok responds to a successful request with HTTP 200 status.
 // It's the most common response method for successful operations.
 // If data is provided, it will be included in the response body.
@@ -198,11 +258,7 @@ func Accepted(c slim.Context, data ...any) error {
 // Returns:
 //   - error: Any error that occurred during response writing
 func Respond(c slim.Context, opts ...Option) error {
-	o := options{}
-	for _, option := range opts {
-		option(&o)
-	}
-	return respond(c, &o)
+	return respond(c, newOptions(withDefaults(c, opts)))
 }
 
 func respond(c slim.Context, o *options) (err error) {
@@ -211,57 +267,202 @@ func respond(c slim.Context, o *options) (err error) {
 		return
 	}
 
-	for key, value := range o.headers {
-		c.SetHeader(key, value)
+	format := negotiate(c)
+	return commit(c, format, record(c, o, format))
+}
+
+// commit writes the recorded response like write and, once it was written
+// successfully, records it for replay if the request carries an idempotency
+// key. Responses replaced by the fallback envelope are not recorded.
+func commit(c slim.Context, format string, r *Recorded) error {
+	replay := replayable(c, r)
+	if err := render(c, format, r); err != nil {
+		return fallback(c, err)
 	}
+	remember(c, replay)
+	return nil
+}
 
-	for _, cookie := range o.cookies {
-		c.SetCookie(cookie)
+// write renders the recorded response, replacing a failed rendering with the
+// fallback envelope.
+func write(c slim.Context, format string, r *Recorded) error {
+	if err := render(c, format, r); err != nil {
+		return fallback(c, err)
 	}
+	return nil
+}
 
-	status, m := result(c, o)
+// fallback replaces a failed rendering with the fallback envelope, as long
+// as nothing has reached the client yet.
+func fallback(c slim.Context, err error) error {
+	if FallbackWriter != nil && !c.Written() {
+		return FallbackWriter(c, err)
+	}
+	return err
+}
+
+// render copies the recorded headers to the response and renders the
+// envelope in the negotiated format.
+func render(c slim.Context, format string, r *Recorded) (err error) {
+	status, m := r.Status, r.Body
+
+	header := c.Response().Header()
+	for key, values := range r.Header {
+		header[key] = values
+	}
 
 	// HEAD requests have no response body
 	if c.Request().Method == http.MethodHead {
-		return c.NoContent(status)
+		return head(c, format, r)
+	}
+
+	if rendersJSON(format) {
+		// Large data is streamed
+		if r.stream || shouldStream(m["data"]) {
+			return stream(c, status, m)
+		}
+		return writeJSON(c, status, m, r.encoded)
 	}
 
 	// Respond with different formats based on Accept header
-	switch c.Accepts("html", "json", "jsonp", "xml", "text", "text/*") {
+	switch format {
 	case "html":
 		var html string
-		if html, err = HTMLMarshaller(m); err == nil {
+		if html, err = marshalHTML(c, m); err == nil {
 			err = c.HTML(status, html)
 		}
-	case "json":
-		err = c.JSON(status, m)
 	case "jsonp":
-		qs := c.Request().URL.Query()
-		for _, name := range JsonpCallbacks {
-			if cb := qs.Get(name); cb != "" {
-				err = c.JSONP(status, cb, m)
-				return
-			}
+		if cb := jsonpCallback(c); cb != "" {
+			err = c.JSONP(status, cb, m)
+		} else {
+			// No callback parameter found, fall back to JSON instead of using default callback
+			err = writeJSON(c, status, m, nil)
 		}
-		// No callback parameter found, fall back to JSON instead of using default callback
-		err = c.JSON(status, m)
-	case "xml":
-		// Note: XML support is limited. For now, fall back to JSON
-		// since XML marshalling of interface{} types is complex
-		err = c.JSON(status, m)
 	case "text", "text/*":
 		var text string
 		if text, err = TextMarshaller(m); err == nil {
 			err = c.String(status, text)
 		}
-	default:
-		err = c.JSON(status, m)
 	}
 
 	return
 }
 
+// writeJSON writes the envelope as JSON. The body is encoded before the
+// status is written, so that an encoding error leaves the response untouched
+// for the FallbackWriter. encoded is the body already encoded while enforcing
+// the size limit, if any.
+//
+// Formats without dedicated rendering, such as "xml", are written as JSON too,
+// since XML marshalling of interface{} types is complex.
+func writeJSON(c slim.Context, status int, m slim.Map, encoded []byte) error {
+	if encoded == nil {
+		var buf bytes.Buffer
+		if err := encodeEnvelope(&buf, m); err != nil {
+			return err
+		}
+		encoded = buf.Bytes()
+	}
+	c.Response().Header().Set("Content-Type", "application/json; charset=UTF-8")
+	c.Response().WriteHeader(status)
+	_, err := c.Response().Write(encoded)
+	return err
+}
+
+// marshalHTML renders m with HTMLRenderer, or HTMLMarshaller when it is not set.
+func marshalHTML(c slim.Context, m map[string]any) (string, error) {
+	if HTMLRenderer != nil {
+		return HTMLRenderer(c, m)
+	}
+	return HTMLMarshaller(m)
+}
+
+// jsonpCallback returns the JSONP callback name from the query string, or
+// an empty string if none of JsonpCallbacks is set.
+func jsonpCallback(c slim.Context) string {
+	qs := c.Request().URL.Query()
+	for _, name := range JsonpCallbacks {
+		if cb := qs.Get(name); cb != "" {
+			return cb
+		}
+	}
+	return ""
+}
+
+// negotiate selects the response format based on the Accept header.
+func negotiate(c slim.Context) string {
+	return Negotiate(c, "html", "json", "jsonp", "xml", "text", "text/*")
+}
+
+// rendersJSON reports whether the negotiated format is rendered as plain JSON.
+func rendersJSON(format string) bool {
+	switch format {
+	case "html", "jsonp", "text", "text/*":
+		return false
+	default:
+		return true
+	}
+}
+
+// record computes the status, headers and envelope of the response for the
+// negotiated format without writing anything. The returned headers start
+// from a copy of the headers already set on the response.
+func record(c slim.Context, o *options, format string) *Recorded {
+	header := c.Response().Header().Clone()
+	for key, value := range o.headers {
+		header.Set(key, value)
+	}
+
+	for _, cookie := range o.cookies {
+		if v := cookie.String(); v != "" {
+			header.Add("Set-Cookie", v)
+		}
+	}
+
+	if policy := cmp.Or(o.security, SecurityHeaders); policy != nil {
+		policy.apply(header, format == "html")
+	}
+
+	status, m, localized := localizedResult(c, o)
+	if len(o.meta) > 0 {
+		m["meta"] = maps.Clone(o.meta)
+	}
+	if o.graphql {
+		m = toGraphQL(m)
+	}
+	r := &Recorded{Status: status, Header: header, Body: m, localized: localized}
+	limitSize(c, o, r)
+	addVary(header, varyHeaders(o, format, r)...)
+	return r
+}
+
 func result(c slim.Context, o *options) (int, slim.Map) {
+	status, m, _ := localizedResult(c, o)
+	return status, m
+}
+
+// localizedResult is like result, and also reports whether the envelope was
+// localized for the language of the request.
+func localizedResult(c slim.Context, o *options) (int, slim.Map, bool) {
+	status, m := inferResult(c, o)
+	localized := localizeMessage(c, o, m)
+	translated := translateProblems(c, m)
+	return status, m, localized || translated
+}
+
+// localizeMessage replaces the "msg" field with the localized text of a
+// msg.Localizable error, unless a message was set explicitly with Message.
+// It reports whether the message was localized.
+func localizeMessage(c slim.Context, o *options, m slim.Map) bool {
+	var l msg.Localizable
+	if o.err == nil || o.message != "" || MessagePrinter == nil || !errors.As(o.err, &l) {
+		return false
+	}
+	m["msg"] = l.Localize(MessagePrinter(c))
+	return true
+}
+
+func inferResult(c slim.Context, o *options) (int, slim.Map) {
 	if status, m, ok := inferHTTPError(c, o); ok {
 		return status, m
 	}
@@ -271,6 +472,9 @@ func result(c slim.Context, o *options) (int, slim.Map) {
 	if status, m, ok := inferFundamentalErrir(c, o); ok {
 		return status, m
 	}
+	if status, m, ok := inferMappedError(c, o); ok {
+		return status, m
+	}
 	if o.err != nil {
 		return inferMistaken(c, o)
 	}
@@ -2,6 +2,7 @@ package rsp
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -1637,3 +1638,120 @@ func TestIntegrationJSONPResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestRespondFallbackOnMarshalError(t *testing.T) {
+	original := HTMLMarshaller
+	defer func() { HTMLMarshaller = original }()
+	HTMLMarshaller = func(map[string]any) (string, error) {
+		return "", errors.New("template exploded")
+	}
+
+	ctx, recorder := createContextWithAccept("text/html")
+
+	if err := Ok(ctx, TestData{ID: 1, Name: "fallback"}); err != nil {
+		t.Errorf("Ok() error = %v, want nil", err)
+	}
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Fallback status = %v, want %v", recorder.Code, http.StatusInternalServerError)
+	}
+
+	var response map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Fallback invalid JSON response = %v", err)
+	}
+	if response["code"] != "InternalError" || response["ok"] != false {
+		t.Errorf("Fallback envelope = %v", response)
+	}
+	if _, ok := response["error"]; ok {
+		t.Error("Fallback should not expose error outside debug mode")
+	}
+}
+
+func TestRespondFallbackOnEncodeError(t *testing.T) {
+	ctx, recorder := createContextWithDebug(true)
+	ctx.Request().Header.Set("Accept", "application/json")
+
+	if err := Ok(ctx, map[string]any{"ch": make(chan int)}); err != nil {
+		t.Errorf("Ok() error = %v, want nil", err)
+	}
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Fallback status = %v, want %v", recorder.Code, http.StatusInternalServerError)
+	}
+
+	var response map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Fallback invalid JSON response = %v", err)
+	}
+	if response["error"] == nil {
+		t.Error("Fallback should expose error in debug mode")
+	}
+}
+
+func TestRespondWithoutFallback(t *testing.T) {
+	original := FallbackWriter
+	defer func() { FallbackWriter = original }()
+	FallbackWriter = nil
+
+	ctx, _ := createContext()
+
+	if err := Ok(ctx, map[string]any{"ch": make(chan int)}); err == nil {
+		t.Error("Ok() error = nil, want encode error")
+	}
+}
+
+func TestRespondWithMeta(t *testing.T) {
+	ctx, recorder := createContext()
+
+	err := Respond(ctx, Data(TestData{ID: 1}), Meta("version", "v1"))
+	if err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	var response map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Respond() invalid JSON response = %v", err)
+	}
+
+	meta, ok := response["meta"].(map[string]any)
+	if !ok || meta["version"] != "v1" {
+		t.Errorf("Respond() meta = %v, want version v1", response["meta"])
+	}
+	if data, _ := response["data"].(map[string]any); data["version"] != nil {
+		t.Error("Meta should not be mixed into data")
+	}
+
+	// Meta is rendered on error envelopes too
+	rec, err := Capture(ctx, Error(ErrBadRequest), Meta("request_id", "r-1"))
+	if err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	if rec.Meta()["request_id"] != "r-1" {
+		t.Errorf("Capture() meta = %v, want request_id r-1", rec.Meta())
+	}
+
+	// Meta is omitted when unset
+	rec, _ = Capture(ctx)
+	if _, exists := rec.Body["meta"]; exists {
+		t.Error("meta field should be omitted when unset")
+	}
+}
+
+func BenchmarkRespondWithLargeDataStreaming(b *testing.B) {
+	threshold := StreamThreshold
+	defer func() { StreamThreshold = threshold }()
+	StreamThreshold = 10
+
+	bc := NewBenchmarkContext()
+	bc.SetAccept("application/json")
+	largeData := make([]BenchmarkUser, 100) // 100 users
+	for i := range largeData {
+		largeData[i] = benchmarkUser
+		largeData[i].ID = i
+	}
+
+	for b.Loop() {
+		_ = Respond(bc.GetContext(), Data(largeData))
+	}
+}
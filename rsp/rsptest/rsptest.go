@@ -0,0 +1,215 @@
+// Package rsptest provides golden-file snapshot testing for responses rendered
+// by the rsp package. Snapshots capture the status, content type and body of
+// a response per content type and locale, so accidental wire-format changes
+// across the many envelope code paths show up as test failures.
+//
+// Golden files are stored in Dir (testdata by default) and are rewritten when
+// Update is set, or when the tests run with an -update flag. rsptest does not
+// register the flag, so that it cannot clash with the flags of the tests; the
+// package under test declares it:
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+//
+// and the golden files are then rewritten with:
+//
+//	go test ./... -update
+//
+// Typical Usage:
+//
+//	func TestUserNotFound(t *testing.T) {
+//		rsptest.Matrix(t, rsptest.Case{
+//			Name: "user_not_found",
+//			Handler: func(c slim.Context) error {
+//				return rsp.Respond(c, rsp.StatusCode(404), rsp.Message("User not found"))
+//			},
+//		}, []string{"application/json", "text/html"}, []string{"en", "zh-CN"})
+//	}
+package rsptest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-slim.dev/slim"
+)
+
+var (
+	// Dir is the directory golden files are read from and written to,
+	// relative to the package under test.
+	Dir = "testdata"
+
+	// Update forces golden files to be rewritten, in addition to an -update
+	// command line flag declared by the package under test.
+	Update bool
+)
+
+// T is the subset of testing.TB used by the snapshot helpers.
+type T interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Case describes a single request to snapshot.
+type Case struct {
+	Name     string           // Base name of the golden file
+	Method   string           // HTTP method, defaults to GET
+	Target   string           // Request target, defaults to "/"
+	Accept   string           // Accept header, defaults to application/json
+	Language string           // Accept-Language header, optional
+	Debug    bool             // Enables slim debug mode
+	Header   http.Header      // Additional request headers
+	Handler  slim.HandlerFunc // Handler that renders the response
+}
+
+// Matrix snapshots the case once for every combination of Accept and
+// Accept-Language values. Empty slices leave the corresponding header unset.
+func Matrix(t T, tc Case, accepts, languages []string) {
+	t.Helper()
+	if len(accepts) == 0 {
+		accepts = []string{tc.Accept}
+	}
+	if len(languages) == 0 {
+		languages = []string{tc.Language}
+	}
+	for _, accept := range accepts {
+		for _, language := range languages {
+			c := tc
+			c.Accept = accept
+			c.Language = language
+			Snapshot(t, c)
+		}
+	}
+}
+
+// Snapshot renders the case and compares the result with its golden file.
+// When updating, the golden file is rewritten instead.
+func Snapshot(t T, tc Case) {
+	t.Helper()
+
+	got, err := Render(tc)
+	if err != nil {
+		t.Fatalf("rsptest: %s: %v", tc.Name, err)
+		return
+	}
+
+	path := filepath.Join(Dir, GoldenName(tc))
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("rsptest: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("rsptest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("rsptest: %v (run the tests with -update to create it)", err)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("rsptest: %s does not match the rendered response\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
+
+// Render executes the case and returns its snapshot representation: a status
+// line, the Content-Type header and the body, with JSON bodies indented.
+func Render(tc Case) ([]byte, error) {
+	if tc.Handler == nil {
+		return nil, fmt.Errorf("missing handler")
+	}
+
+	s := slim.New()
+	s.Debug = tc.Debug
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(cmp.Or(tc.Method, http.MethodGet), cmp.Or(tc.Target, "/"), nil)
+	for key, values := range tc.Header {
+		request.Header[key] = values
+	}
+	request.Header.Set("Accept", cmp.Or(tc.Accept, "application/json"))
+	if tc.Language != "" {
+		request.Header.Set("Accept-Language", tc.Language)
+	}
+
+	if err := tc.Handler(s.NewContext(recorder, request)); err != nil {
+		return nil, err
+	}
+
+	contentType := recorder.Header().Get("Content-Type")
+	body := recorder.Body.Bytes()
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/json" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, bytes.TrimSpace(body), "", "  "); err == nil {
+			body = buf.Bytes()
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP %d %s\n", recorder.Code, http.StatusText(recorder.Code))
+	fmt.Fprintf(&buf, "Content-Type: %s\n\n", contentType)
+	buf.Write(bytes.TrimSpace(body))
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// GoldenName returns the golden file name for the case, made up of its name,
+// locale and content type, e.g. "user_not_found.zh-CN.json.golden".
+func GoldenName(tc Case) string {
+	parts := []string{tc.Name}
+	if tc.Language != "" {
+		parts = append(parts, sanitize(tc.Language))
+	}
+	parts = append(parts, formatName(tc.Accept), "golden")
+	return strings.Join(parts, ".")
+}
+
+func formatName(accept string) string {
+	mediaType, _, _ := mime.ParseMediaType(strings.TrimSpace(strings.Split(accept, ",")[0]))
+	switch mediaType {
+	case "", "application/json", "*/*":
+		return "json"
+	case "text/html":
+		return "html"
+	case "application/javascript":
+		return "jsonp"
+	case "application/xml", "text/xml":
+		return "xml"
+	case "text/plain", "text/*":
+		return "text"
+	default:
+		return sanitize(mediaType)
+	}
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// updating reports whether golden files are rewritten, reading the -update
+// flag at call time since tests declare it after rsptest is initialized
+func updating() bool {
+	if Update {
+		return true
+	}
+	f := flag.Lookup("update")
+	return f != nil && f.Value.String() == "true"
+}
@@ -0,0 +1,158 @@
+package rsptest
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-slim.dev/infra/rsp"
+	"go-slim.dev/slim"
+)
+
+// update is declared like in the packages under test, which must not clash with rsptest
+var update = flag.Bool("update", false, "update golden files")
+
+// recordingT records failures instead of failing the test
+type recordingT struct {
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// useTempDir points Dir at a temporary directory for the duration of the test
+func useTempDir(t *testing.T) string {
+	dir := t.TempDir()
+	original := Dir
+	Dir = dir
+	t.Cleanup(func() { Dir = original })
+	return dir
+}
+
+// setUpdate toggles Update for the duration of the test
+func setUpdate(t *testing.T, update bool) {
+	original := Update
+	Update = update
+	t.Cleanup(func() { Update = original })
+}
+
+func notFound(c slim.Context) error {
+	return rsp.Respond(c, rsp.StatusCode(http.StatusNotFound), rsp.Message("User not found"))
+}
+
+func TestGoldenName(t *testing.T) {
+	tests := []struct {
+		tc   Case
+		want string
+	}{
+		{Case{Name: "ok"}, "ok.json.golden"},
+		{Case{Name: "ok", Accept: "text/html"}, "ok.html.golden"},
+		{Case{Name: "ok", Accept: "text/plain", Language: "zh-CN"}, "ok.zh-CN.text.golden"},
+		{Case{Name: "ok", Accept: "application/javascript, */*"}, "ok.jsonp.golden"},
+		{Case{Name: "ok", Language: "en;q=0.8"}, "ok.en_q_0_8.json.golden"},
+	}
+
+	for _, tt := range tests {
+		if got := GoldenName(tt.tc); got != tt.want {
+			t.Errorf("GoldenName(%+v) = %q, want %q", tt.tc, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	got, err := Render(Case{Name: "not_found", Handler: notFound})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	s := string(got)
+	if !strings.HasPrefix(s, "HTTP 404 Not Found\n") {
+		t.Errorf("Render() status line missing, got:\n%s", s)
+	}
+	if !strings.Contains(s, "\n  \"msg\": \"User not found\"") {
+		t.Errorf("Render() body should be indented JSON, got:\n%s", s)
+	}
+
+	if _, err := Render(Case{Name: "missing"}); err == nil {
+		t.Error("Render() error = nil, want missing handler error")
+	}
+}
+
+func TestSnapshotUpdateAndCompare(t *testing.T) {
+	dir := useTempDir(t)
+	tc := Case{Name: "not_found", Handler: notFound}
+
+	// Missing golden file
+	rt := &recordingT{}
+	Snapshot(rt, tc)
+	if len(rt.failures) != 1 {
+		t.Errorf("Snapshot() without golden file failures = %v, want 1", rt.failures)
+	}
+
+	// Create the golden file
+	setUpdate(t, true)
+	Snapshot(t, tc)
+	if _, err := os.Stat(filepath.Join(dir, "not_found.json.golden")); err != nil {
+		t.Fatalf("golden file was not written: %v", err)
+	}
+
+	// Compare against it
+	setUpdate(t, false)
+	Snapshot(t, tc)
+
+	// A changed response is reported
+	rt = &recordingT{}
+	Snapshot(rt, Case{Name: "not_found", Handler: func(c slim.Context) error {
+		return rsp.Respond(c, rsp.StatusCode(http.StatusNotFound), rsp.Message("No such user"))
+	}})
+	if len(rt.failures) != 1 {
+		t.Errorf("Snapshot() with changed response failures = %v, want 1", rt.failures)
+	}
+}
+
+func TestUpdateFlag(t *testing.T) {
+	original := *update
+	t.Cleanup(func() { *update = original })
+
+	*update = false
+	if updating() {
+		t.Error("updating() = true without -update")
+	}
+	if err := flag.Set("update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if !updating() {
+		t.Error("updating() = false with -update")
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	dir := useTempDir(t)
+	setUpdate(t, true)
+
+	Matrix(t, Case{Name: "ok", Handler: func(c slim.Context) error {
+		return rsp.Ok(c, map[string]string{"hello": "world"})
+	}}, []string{"application/json", "text/plain"}, []string{"en", "zh-CN"})
+
+	for _, name := range []string{
+		"ok.en.json.golden",
+		"ok.en.text.golden",
+		"ok.zh-CN.json.golden",
+		"ok.zh-CN.text.golden",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("golden file %s was not written: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package rsp
+
+import "net/http"
+
+// SecurityPolicy describes a curated set of security-related response headers.
+// Empty fields are skipped, and headers that were already set on the response
+// (for example via the Header option) are never overwritten.
+type SecurityPolicy struct {
+	// ContentTypeOptions is the X-Content-Type-Options value, usually "nosniff".
+	ContentTypeOptions string
+
+	// FrameOptions is the X-Frame-Options value, e.g. "DENY" or "SAMEORIGIN".
+	FrameOptions string
+
+	// ReferrerPolicy is the Referrer-Policy value, e.g. "no-referrer".
+	ReferrerPolicy string
+
+	// ContentSecurityPolicy is the Content-Security-Policy value. It is only
+	// applied to HTML responses, such as rendered error pages.
+	ContentSecurityPolicy string
+
+	// StrictTransportSecurity is the Strict-Transport-Security value.
+	// It is left empty by default because HSTS should only be enabled
+	// deliberately for hosts that are served exclusively over HTTPS.
+	StrictTransportSecurity string
+}
+
+var (
+	// DefaultSecurityPolicy is the policy used by SecureHeaders when no
+	// policy is given. It is a conservative baseline for API responses.
+	DefaultSecurityPolicy = SecurityPolicy{
+		ContentTypeOptions:    "nosniff",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		ContentSecurityPolicy: "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'",
+	}
+
+	// SecurityHeaders is the policy applied to every response that does not
+	// configure its own via SecureHeaders. It is nil by default, which means
+	// no security headers are added globally.
+	//
+	// Example:
+	//
+	//	rsp.SecurityHeaders = &rsp.DefaultSecurityPolicy
+	SecurityHeaders *SecurityPolicy
+)
+
+// SecureHeaders configures the security headers for the response.
+// If no policy is given, DefaultSecurityPolicy is used. It takes precedence
+// over the global SecurityHeaders policy.
+//
+// Parameters:
+//   - policy: Optional policy to apply (0 or 1 parameter)
+//
+// Returns:
+//   - Option: A function that configures the security headers when applied
+//
+// Example:
+//
+//	rsp.Respond(c, rsp.SecureHeaders(), rsp.Data(userData))
+func SecureHeaders(policy ...SecurityPolicy) Option {
+	p := DefaultSecurityPolicy
+	if len(policy) > 0 {
+		p = policy[0]
+	}
+	return func(o *options) {
+		o.security = &p
+	}
+}
+
+// apply sets the policy headers that are not already present on h.
+// The Content-Security-Policy header is only set when html is true.
+func (p *SecurityPolicy) apply(h http.Header, html bool) {
+	set := func(key, value string) {
+		if value != "" && h.Get(key) == "" {
+			h.Set(key, value)
+		}
+	}
+	set("X-Content-Type-Options", p.ContentTypeOptions)
+	set("X-Frame-Options", p.FrameOptions)
+	set("Referrer-Policy", p.ReferrerPolicy)
+	set("Strict-Transport-Security", p.StrictTransportSecurity)
+	if html {
+		set("Content-Security-Policy", p.ContentSecurityPolicy)
+	}
+}
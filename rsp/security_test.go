@@ -0,0 +1,92 @@
+package rsp
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSecureHeadersDefault(t *testing.T) {
+	ctx, recorder := createContextWithAccept("application/json")
+
+	if err := Respond(ctx, SecureHeaders()); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	want := map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        "DENY",
+		"Referrer-Policy":        "strict-origin-when-cross-origin",
+	}
+	for key, value := range want {
+		if got := recorder.Header().Get(key); got != value {
+			t.Errorf("Header %s = %q, want %q", key, got, value)
+		}
+	}
+
+	if got := recorder.Header().Get("Content-Security-Policy"); got != "" {
+		t.Errorf("Content-Security-Policy = %q, want empty for JSON responses", got)
+	}
+	if got := recorder.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security = %q, want empty by default", got)
+	}
+}
+
+func TestSecureHeadersHTML(t *testing.T) {
+	ctx, recorder := createContextWithAccept("text/html")
+
+	if err := Respond(ctx, StatusCode(http.StatusNotFound), SecureHeaders()); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	if got := recorder.Header().Get("Content-Security-Policy"); got != DefaultSecurityPolicy.ContentSecurityPolicy {
+		t.Errorf("Content-Security-Policy = %q, want %q", got, DefaultSecurityPolicy.ContentSecurityPolicy)
+	}
+}
+
+func TestSecureHeadersCustomPolicy(t *testing.T) {
+	ctx, recorder := createContext()
+
+	err := Respond(ctx,
+		Header("X-Frame-Options", "SAMEORIGIN"),
+		SecureHeaders(SecurityPolicy{
+			FrameOptions:            "DENY",
+			StrictTransportSecurity: "max-age=63072000",
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	if got := recorder.Header().Get("X-Frame-Options"); got != "SAMEORIGIN" {
+		t.Errorf("X-Frame-Options = %q, want explicit header to win", got)
+	}
+	if got := recorder.Header().Get("Strict-Transport-Security"); got != "max-age=63072000" {
+		t.Errorf("Strict-Transport-Security = %q", got)
+	}
+	if got := recorder.Header().Get("X-Content-Type-Options"); got != "" {
+		t.Errorf("X-Content-Type-Options = %q, want empty for unset field", got)
+	}
+}
+
+func TestSecurityHeadersGlobal(t *testing.T) {
+	original := SecurityHeaders
+	defer func() { SecurityHeaders = original }()
+	SecurityHeaders = &SecurityPolicy{ContentTypeOptions: "nosniff"}
+
+	ctx, recorder := createContext()
+	if err := Ok(ctx); err != nil {
+		t.Fatalf("Ok() error = %v", err)
+	}
+	if got := recorder.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+	}
+
+	// A per-response policy replaces the global one
+	ctx, recorder = createContext()
+	if err := Respond(ctx, SecureHeaders(SecurityPolicy{FrameOptions: "DENY"})); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+	if got := recorder.Header().Get("X-Content-Type-Options"); got != "" {
+		t.Errorf("X-Content-Type-Options = %q, want per-response policy to replace global", got)
+	}
+}
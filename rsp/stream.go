@@ -0,0 +1,192 @@
+package rsp
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go-slim.dev/slim"
+)
+
+var (
+	// StreamThreshold is the number of elements above which slice, array and
+	// map data is streamed directly to the response in JSON responses,
+	// instead of being encoded into an intermediate buffer first.
+	// Zero disables streaming.
+	//
+	// Once streaming has started the status and headers have been sent, so an
+	// encoding error part way through cannot be replaced by FallbackWriter and
+	// results in a truncated body.
+	StreamThreshold int
+
+	// StreamGzip enables gzip compression of streamed responses for clients
+	// that accept it.
+	StreamGzip bool
+)
+
+// streamBufferSize is the size of the buffer between the encoder and the
+// response writer, so that small elements do not each cause a write.
+const streamBufferSize = 32 << 10
+
+// shouldStream reports whether the data is large enough to be streamed.
+func shouldStream(data any) bool {
+	if StreamThreshold <= 0 || data == nil {
+		return false
+	}
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() > StreamThreshold
+	default:
+		return false
+	}
+}
+
+// stream writes the envelope as JSON, encoding slice and array data one
+// element at a time, so memory usage does not grow with the payload size.
+func stream(c slim.Context, status int, m slim.Map) (err error) {
+	w := c.Response()
+	header := w.Header()
+	header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	// The status is only sent with the first buffered chunk, so that encoding
+	// errors before that point can still be handled by FallbackWriter.
+	var out io.Writer = &statusWriter{w: w, status: status}
+	var gz *gzip.Writer
+	if StreamGzip && acceptsEncoding(c.Request(), "gzip") {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		gz = gzip.NewWriter(out)
+		out = gz
+	}
+
+	buf := bufio.NewWriterSize(out, streamBufferSize)
+	if err = encodeEnvelope(buf, m); err == nil {
+		err = buf.Flush()
+	}
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err != nil && !c.Written() {
+		header.Del("Content-Encoding")
+	}
+	return err
+}
+
+// statusWriter writes the status code before the first write.
+type statusWriter struct {
+	w      http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (s *statusWriter) Write(p []byte) (int, error) {
+	if !s.wrote {
+		s.w.WriteHeader(s.status)
+		s.wrote = true
+	}
+	return s.w.Write(p)
+}
+
+// encodeEnvelope writes the envelope as JSON to w, with the data field last
+// and encoded by encodeStream.
+func encodeEnvelope(w io.Writer, m slim.Map) error {
+	data, ok := m["data"]
+	envelope := make(slim.Map, len(m))
+	for key, value := range m {
+		if key != "data" {
+			envelope[key] = value
+		}
+	}
+	head, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		_, err = w.Write(head)
+		return err
+	}
+
+	// Replace the closing brace of the envelope with the data field,
+	// separated from the other fields if there are any
+	field := `,"data":`
+	if len(envelope) == 0 {
+		field = `"data":`
+	}
+	if _, err = w.Write(head[:len(head)-1]); err != nil {
+		return err
+	}
+	if _, err = io.WriteString(w, field); err != nil {
+		return err
+	}
+	if err = encodeStream(w, reflect.ValueOf(data)); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "}")
+	return err
+}
+
+// encodeStream encodes slices and arrays element by element, and any other
+// value at once. The output is the same as json.Marshal, minus the key order
+// of maps.
+func encodeStream(w io.Writer, v reflect.Value) error {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		if !v.IsValid() {
+			_, err := io.WriteString(w, "null")
+			return err
+		}
+		return encodeValue(w, v.Interface())
+	}
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		_, err := io.WriteString(w, "null")
+		return err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i := range v.Len() {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := encodeValue(w, v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+// encodeValue writes the JSON encoding of a single value to w.
+func encodeValue(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// acceptsEncoding reports whether the request accepts the given content
+// coding with a non-zero quality.
+func acceptsEncoding(r *http.Request, coding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), coding) && strings.TrimSpace(name) != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(q, 64); err == nil && f == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package rsp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// setStreaming configures streaming for the duration of the test
+func setStreaming(t *testing.T, threshold int, gz bool) {
+	threshold0, gz0 := StreamThreshold, StreamGzip
+	StreamThreshold, StreamGzip = threshold, gz
+	t.Cleanup(func() { StreamThreshold, StreamGzip = threshold0, gz0 })
+}
+
+func largeData(n int) []TestData {
+	data := make([]TestData, n)
+	for i := range data {
+		data[i] = TestData{ID: i, Name: "user"}
+	}
+	return data
+}
+
+func TestShouldStream(t *testing.T) {
+	setStreaming(t, 2, false)
+
+	tests := []struct {
+		data any
+		want bool
+	}{
+		{nil, false},
+		{[]int{1, 2}, false},
+		{[]int{1, 2, 3}, true},
+		{[3]int{}, true},
+		{map[string]int{"a": 1, "b": 2, "c": 3}, true},
+		{"a long string", false},
+		{TestData{}, false},
+	}
+	for _, tt := range tests {
+		if got := shouldStream(tt.data); got != tt.want {
+			t.Errorf("shouldStream(%#v) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+
+	StreamThreshold = 0
+	if shouldStream([]int{1, 2, 3}) {
+		t.Error("shouldStream() = true, want false when disabled")
+	}
+}
+
+func TestRespondStreamsLargeData(t *testing.T) {
+	setStreaming(t, 10, false)
+
+	for _, data := range []any{largeData(100), map[string]int{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6, "g": 7, "h": 8, "i": 9, "j": 10, "k": 11}} {
+		ctx, recorder := createContextWithAccept("application/json")
+		if err := Respond(ctx, StatusCode(http.StatusCreated), Data(data), Meta("total", 100)); err != nil {
+			t.Fatalf("Respond() error = %v", err)
+		}
+
+		if recorder.Code != http.StatusCreated {
+			t.Errorf("status = %v, want %v", recorder.Code, http.StatusCreated)
+		}
+
+		// The streamed body must match the buffered rendering
+		rec, _ := Capture(ctx, StatusCode(http.StatusCreated), Data(data), Meta("total", 100))
+		want, _ := json.Marshal(rec.Body)
+
+		var got, expected map[string]any
+		if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+			t.Fatalf("streamed invalid JSON = %v: %s", err, recorder.Body.String())
+		}
+		_ = json.Unmarshal(want, &expected)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("streamed envelope = %v, want %v", got, expected)
+		}
+	}
+}
+
+func TestRespondStreamsWithGzip(t *testing.T) {
+	setStreaming(t, 10, true)
+
+	ctx, recorder := createContextWithAccept("application/json")
+	ctx.Request().Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	if err := Ok(ctx, largeData(50)); err != nil {
+		t.Fatalf("Ok() error = %v", err)
+	}
+
+	if recorder.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", recorder.Header().Get("Content-Encoding"))
+	}
+
+	zr, err := gzip.NewReader(recorder.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	var response struct {
+		OK   bool       `json:"ok"`
+		Data []TestData `json:"data"`
+	}
+	if err := json.NewDecoder(zr).Decode(&response); err != nil {
+		t.Fatalf("invalid gzipped JSON = %v", err)
+	}
+	if !response.OK || len(response.Data) != 50 {
+		t.Errorf("response ok = %v, data length = %v, want true and 50", response.OK, len(response.Data))
+	}
+}
+
+func TestRespondStreamFallbackOnFirstElement(t *testing.T) {
+	setStreaming(t, 1, false)
+
+	ctx, recorder := createContext()
+	if err := Ok(ctx, []any{make(chan int), 1}); err != nil {
+		t.Fatalf("Ok() error = %v", err)
+	}
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("status = %v, want fallback %v", recorder.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestEncodeEnvelope(t *testing.T) {
+	tests := []struct {
+		name string
+		m    map[string]any
+		want string
+	}{
+		{"data only", map[string]any{"data": map[string]string{"user": "john"}}, `{"data":{"user":"john"}}`},
+		{"without data", map[string]any{"code": "OK", "ok": true}, `{"code":"OK","ok":true}`},
+		{"data last", map[string]any{"data": []int{1, 2}, "ok": true}, `{"ok":true,"data":[1,2]}`},
+		{"nil data", map[string]any{"data": nil}, `{"data":null}`},
+		{"empty", map[string]any{}, `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeEnvelope(&buf, tt.m); err != nil {
+				t.Fatalf("encodeEnvelope() error = %v", err)
+			}
+			if !json.Valid(buf.Bytes()) || buf.String() != tt.want {
+				t.Errorf("encodeEnvelope() = %s, want %s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, GZIP", true},
+		{"gzip;q=0", false},
+		{"*", true},
+		{"br", false},
+	}
+	for _, tt := range tests {
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", tt.header)
+		if got := acceptsEncoding(r, "gzip"); got != tt.want {
+			t.Errorf("acceptsEncoding(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
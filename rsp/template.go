@@ -0,0 +1,39 @@
+package rsp
+
+import (
+	"html/template"
+	"strings"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/slim"
+)
+
+// TemplateRenderer returns an HTMLRenderer that executes the named template
+// of t with the response envelope as data. The msg template functions
+// (T, plural, number, currency, date, ...) are bound to the printer returned
+// by MessagePrinter, or to the default msg.Manager when it is nil, so pages
+// are translated into the request locale.
+//
+// The functions must be registered before parsing:
+//
+//	tmpl := template.Must(template.New("").Funcs(msg.FuncMap(context.Background())).ParseFS(views, "*.html"))
+//	rsp.HTMLRenderer = rsp.TemplateRenderer(tmpl, "response.html")
+//
+// Each response renders a clone of t, so t itself must not be executed.
+func TemplateRenderer(t *template.Template, name string) func(c slim.Context, m map[string]any) (string, error) {
+	return func(c slim.Context, m map[string]any) (string, error) {
+		page, err := t.Clone()
+		if err != nil {
+			return "", err
+		}
+		p := requestPrinter(c)
+		if MessagePrinter != nil {
+			p = MessagePrinter(c)
+		}
+		var b strings.Builder
+		if err = page.Funcs(msg.PrinterFuncMap(p)).ExecuteTemplate(&b, name, m); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+}
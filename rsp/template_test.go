@@ -0,0 +1,45 @@
+package rsp
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/slim"
+)
+
+func TestTemplateRenderer(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.Chinese, LogFunc: func(string) {}})
+	_ = manager.SetMessage(msg.Chinese, "Welcome, %s", "欢迎，%s")
+
+	savedPrinter, savedRenderer := MessagePrinter, HTMLRenderer
+	t.Cleanup(func() { MessagePrinter, HTMLRenderer = savedPrinter, savedRenderer })
+	MessagePrinter = func(c slim.Context) msg.Printer {
+		return manager.GetPrinterWithContext(c.Request().Context())
+	}
+
+	tmpl := template.Must(template.New("").Funcs(msg.FuncMap(context.Background())).Parse(
+		`{{ define "page" }}<p>{{ T "Welcome, %s" .data }}</p>{{ end }}`))
+	HTMLRenderer = TemplateRenderer(tmpl, "page")
+
+	ctx, recorder := createContextWithAccept("text/html")
+	if err := Ok(ctx, "<Bob>"); err != nil {
+		t.Fatalf("Ok() error = %v", err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+	if got, want := recorder.Body.String(), "<p>欢迎，&lt;Bob&gt;</p>"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+
+	// Missing templates fall back to the JSON envelope
+	HTMLRenderer = TemplateRenderer(tmpl, "missing")
+	ctx, recorder = createContextWithAccept("text/html")
+	_ = Ok(ctx, "x")
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+	}
+}
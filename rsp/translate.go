@@ -0,0 +1,101 @@
+package rsp
+
+import (
+	"strings"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/slim"
+)
+
+// ProblemTranslator rewrites the "problems" field of the response envelope
+// before it is serialized, for example to translate each Problem.Message into
+// the request locale. It receives the problems collected from validation
+// errors and returns the problems to render. It is nil by default, leaving the
+// messages untouched; InstallRspTranslation sets it up with a msg.Manager.
+var ProblemTranslator func(c slim.Context, problems Problems) Problems
+
+// InstallRspTranslation localizes responses with the given manager: the "msg"
+// field of msg.Localizable errors (see MessagePrinter) and the message of every
+// problem (see Problems.Localize) are translated with the manager's printer for
+// the locale stored in the request context, typically by msgslim.Middleware.
+// A nil manager uses the global msg manager.
+//
+// It is meant to be called once during application setup:
+//
+//	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.English})
+//	rsp.InstallRspTranslation(manager)
+//
+//	app.Use(msgslim.Middleware(manager))
+func InstallRspTranslation(manager *msg.Manager) {
+	printer := requestPrinter
+	if manager != nil {
+		printer = func(c slim.Context) msg.Printer {
+			return manager.GetPrinterWithContext(c.Request().Context())
+		}
+	}
+	MessagePrinter = printer
+	ProblemTranslator = func(c slim.Context, problems Problems) Problems {
+		return problems.Localize(printer(c))
+	}
+}
+
+// Localize returns a copy of the problems, including nested problems, whose
+// messages are translated with the printer. The problems are left unchanged.
+//
+// Each problem is looked up by its Code first, so catalogs can translate
+// validation errors by their machine-readable code. When the catalog has no
+// entry for the code, the Message itself is used as the message ID; messages
+// containing "%" are kept as-is since they are not format strings. Problems
+// without any translation keep their original message.
+//
+// Example:
+//
+//	_ = manager.SetMessage(msg.Chinese, "INVALID_FORMAT", "格式无效")
+//	problems.Localize(manager.GetPrinter(msg.Chinese))
+func (p Problems) Localize(printer msg.Printer) Problems {
+	if p == nil || printer == nil {
+		return p
+	}
+	localized := make(Problems, len(p))
+	for label, problems := range p {
+		items := make([]*Problem, len(problems))
+		for i, problem := range problems {
+			if problem == nil {
+				continue
+			}
+			items[i] = &Problem{
+				Label:    problem.Label,
+				Code:     problem.Code,
+				Message:  localizeProblem(printer, problem),
+				Problems: problem.Problems.Localize(printer),
+			}
+		}
+		localized[label] = items
+	}
+	return localized
+}
+
+// localizeProblem returns the translated message of a problem, falling back
+// to its original message when neither the code nor the message is translated.
+func localizeProblem(printer msg.Printer, problem *Problem) string {
+	if problem.Code != "" && !strings.Contains(problem.Code, "%") {
+		if text := printer.Sprintf(problem.Code); text != problem.Code {
+			return text
+		}
+	}
+	if problem.Message != "" && !strings.Contains(problem.Message, "%") {
+		return printer.Sprintf(problem.Message)
+	}
+	return problem.Message
+}
+
+// translateProblems applies ProblemTranslator to the "problems" field of the
+// envelope and reports whether it was applied.
+func translateProblems(c slim.Context, m slim.Map) bool {
+	problems, ok := m["problems"].(Problems)
+	if !ok || ProblemTranslator == nil {
+		return false
+	}
+	m["problems"] = ProblemTranslator(c, problems)
+	return true
+}
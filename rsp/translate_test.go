@@ -0,0 +1,108 @@
+package rsp
+
+import (
+	"testing"
+
+	"go-slim.dev/infra/msg"
+	"go-slim.dev/v"
+)
+
+// withTranslation installs the translation hooks for the duration of the test
+func withTranslation(t *testing.T, manager *msg.Manager) {
+	savedPrinter, savedTranslator := MessagePrinter, ProblemTranslator
+	t.Cleanup(func() {
+		MessagePrinter, ProblemTranslator = savedPrinter, savedTranslator
+	})
+	InstallRspTranslation(manager)
+}
+
+func TestProblemsLocalize(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.Chinese, LogFunc: func(string) {}})
+	_ = manager.SetMessage(msg.Chinese, "INVALID_FORMAT", "格式无效")
+	_ = manager.SetMessage(msg.Chinese, "Must not be empty", "不能为空")
+	printer := manager.GetPrinter(msg.Chinese)
+
+	problems := Problems{
+		"email": {
+			{Label: "email", Code: "INVALID_FORMAT", Message: "Invalid email format"},
+			{Label: "email", Code: "REQUIRED", Message: "Must not be empty"},
+			{Label: "email", Code: "BLACKLISTED", Message: "Domain not allowed"},
+			{Label: "email", Code: "TOO_LONG", Message: "Length exceeds 100%"},
+		},
+		"$some": {
+			{Label: "$some", Code: "some", Message: "At least one", Problems: Problems{
+				"phone": {{Label: "phone", Code: "INVALID_FORMAT", Message: "Invalid phone"}},
+			}},
+		},
+	}
+
+	localized := problems.Localize(printer)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"By code", localized["email"][0].Message, "格式无效"},
+		{"By message", localized["email"][1].Message, "不能为空"},
+		{"Untranslated", localized["email"][2].Message, "Domain not allowed"},
+		{"Percent sign", localized["email"][3].Message, "Length exceeds 100%"},
+		{"Nested", localized["$some"][0].Problems["phone"][0].Message, "格式无效"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("Message = %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	if problems["email"][0].Message != "Invalid email format" {
+		t.Error("Localize should not modify the original problems")
+	}
+	if localized["email"][0].Code != "INVALID_FORMAT" || localized["email"][0].Label != "email" {
+		t.Errorf("Localize should keep code and label, got %+v", localized["email"][0])
+	}
+}
+
+func TestInstallRspTranslation(t *testing.T) {
+	manager := msg.NewManager(msg.ManagerConfig{Locale: msg.Chinese, LogFunc: func(string) {}})
+	_ = manager.SetMessage(msg.Chinese, "INVALID_FORMAT", "邮箱格式无效")
+	_ = manager.SetMessage(msg.Chinese, "User %s not found", "未找到用户 %s")
+
+	validationError := func() error {
+		valuer := v.Value("invalid-email", "email", "Email")
+		valuer.Custom("INVALID_FORMAT", func(val any) any {
+			return false
+		}, v.ErrorFormat("Invalid email format"))
+		return valuer.Validate()
+	}
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		ctx, _ := createContext()
+		rec, _ := Capture(ctx, Error(validationError()))
+		AssertProblem(t, rec, "email", "INVALID_FORMAT")
+		if got := rec.Problems()["email"][0].Message; got != "Invalid email format" {
+			t.Errorf("problem msg = %q, want untranslated message", got)
+		}
+	})
+
+	withTranslation(t, manager)
+
+	t.Run("Problems", func(t *testing.T) {
+		ctx, _ := createContext()
+		rec, _ := Capture(ctx, Error(validationError()))
+		AssertProblem(t, rec, "email", "INVALID_FORMAT")
+		if got := rec.Problems()["email"][0].Message; got != "邮箱格式无效" {
+			t.Errorf("problem msg = %q, want %q", got, "邮箱格式无效")
+		}
+	})
+
+	t.Run("Localizable error", func(t *testing.T) {
+		ctx, _ := createContext()
+		rec, _ := Capture(ctx, Error(msg.Error("User %s not found", "alice")))
+		if want := "未找到用户 alice"; rec.Message() != want {
+			t.Errorf("msg = %q, want %q", rec.Message(), want)
+		}
+	})
+}
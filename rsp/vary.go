@@ -0,0 +1,62 @@
+package rsp
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Vary appends values to the Vary header of the response, in addition to the
+// request headers that rsp adds on its own:
+//
+//   - Accept, since the format of every response is negotiated.
+//   - Accept-Encoding, when the response may be streamed with gzip.
+//   - Accept-Language, when the message or the problems of the response were
+//     localized with MessagePrinter or ProblemTranslator.
+//
+// Handlers or middleware that localize responses on their own, such as data or
+// server-rendered pages, should declare the headers they depend on.
+//
+// Example:
+//
+//	// In a localization middleware
+//	rsp.Defaults(rsp.Vary("Accept-Language"))
+func Vary(values ...string) Option {
+	return func(o *options) {
+		o.vary = append(o.vary, values...)
+	}
+}
+
+// varyHeaders returns the request headers that influenced the recorded
+// response, followed by the custom values of the Vary option.
+func varyHeaders(o *options, format string, r *Recorded) []string {
+	vary := []string{"Accept"}
+	if rendersJSON(format) && StreamGzip && (r.stream || shouldStream(r.Body["data"])) {
+		vary = append(vary, "Accept-Encoding")
+	}
+	if r.localized {
+		vary = append(vary, "Accept-Language")
+	}
+	return append(vary, o.vary...)
+}
+
+// addVary adds the values to the Vary header of h, skipping values that are
+// already listed. A Vary header of "*" is left unchanged.
+func addVary(h http.Header, values ...string) {
+	seen := map[string]bool{}
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			seen[http.CanonicalHeaderKey(strings.TrimSpace(name))] = true
+		}
+	}
+	if seen["*"] {
+		return
+	}
+	for _, value := range values {
+		key := http.CanonicalHeaderKey(strings.TrimSpace(value))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		h.Add("Vary", key)
+	}
+}
@@ -0,0 +1,82 @@
+package rsp
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"go-slim.dev/infra/msg"
+)
+
+func TestVaryAccept(t *testing.T) {
+	ctx, recorder := createContextWithAccept("application/json")
+
+	if err := Respond(ctx, Data("ok")); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	if got := recorder.Header().Values("Vary"); !slices.Equal(got, []string{"Accept"}) {
+		t.Errorf("Vary = %v, want [Accept]", got)
+	}
+}
+
+func TestVaryAcceptEncoding(t *testing.T) {
+	setStreaming(t, 10, true)
+
+	// Small responses are never compressed
+	ctx, recorder := createContextWithAccept("application/json")
+	_ = Respond(ctx, Data(largeData(5)))
+	if got := recorder.Header().Values("Vary"); !slices.Equal(got, []string{"Accept"}) {
+		t.Errorf("Vary = %v, want [Accept]", got)
+	}
+
+	// Streamed responses depend on Accept-Encoding, even when not compressed
+	ctx, recorder = createContextWithAccept("application/json")
+	_ = Respond(ctx, Data(largeData(100)))
+	if got := recorder.Header().Values("Vary"); !slices.Equal(got, []string{"Accept", "Accept-Encoding"}) {
+		t.Errorf("Vary = %v, want [Accept Accept-Encoding]", got)
+	}
+}
+
+func TestVaryOption(t *testing.T) {
+	ctx, recorder := createContextWithAccept("application/json")
+	ctx.Response().Header().Set("Vary", "Origin")
+
+	if err := Respond(ctx, Vary("accept-language", "Origin", "Accept"), Data("ok")); err != nil {
+		t.Fatalf("Respond() error = %v", err)
+	}
+
+	want := []string{"Origin", "Accept", "Accept-Language"}
+	if got := recorder.Header().Values("Vary"); !slices.Equal(got, want) {
+		t.Errorf("Vary = %v, want %v", got, want)
+	}
+}
+
+func TestVaryAcceptLanguage(t *testing.T) {
+	saved := MessagePrinter
+	t.Cleanup(func() { MessagePrinter = saved })
+	MessagePrinter = requestPrinter
+
+	// Messages that are not localized do not depend on the language
+	ctx, recorder := createContextWithAccept("application/json")
+	_ = Respond(ctx, Error(errors.New("boom")))
+	if got := recorder.Header().Values("Vary"); !slices.Equal(got, []string{"Accept"}) {
+		t.Errorf("Vary = %v, want [Accept]", got)
+	}
+
+	ctx, recorder = createContextWithAccept("application/json")
+	_ = Respond(ctx, Error(msg.Error("Quota exceeded")))
+	if got := recorder.Header().Values("Vary"); !slices.Equal(got, []string{"Accept", "Accept-Language"}) {
+		t.Errorf("Vary = %v, want [Accept Accept-Language]", got)
+	}
+}
+
+func TestAddVaryWildcard(t *testing.T) {
+	h := http.Header{"Vary": {"*"}}
+	addVary(h, "Accept")
+
+	if got := h.Values("Vary"); !slices.Equal(got, []string{"*"}) {
+		t.Errorf("Vary = %v, want [*]", got)
+	}
+}
@@ -0,0 +1,239 @@
+package rsp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go-slim.dev/slim"
+)
+
+// ErrInvalidSignature is returned by VerifyWebhook when the signature of a
+// webhook delivery is missing, malformed, stale or does not match.
+var ErrInvalidSignature = errors.New("rsp: invalid webhook signature")
+
+// Webhook responds to a webhook delivery with a minimal envelope, such as
+// {"ok":true,"code":"OK"}, that does not expose messages, data or error
+// details to the sender.
+//
+// The status follows the conventions of webhook senders strictly: 200 when
+// err is nil, 4xx for errors the sender should not retry (invalid signatures,
+// oversized bodies, validation and client errors), and 500 for everything else, including
+// non-error statuses attached to err, so that the delivery is retried.
+//
+// Example:
+//
+//	app.POST("/webhooks/payments", func(c slim.Context) error {
+//		return rsp.Webhook(c, handlePayment(c))
+//	})
+func Webhook(c slim.Context, err error) error {
+	if c.Written() {
+		return nil
+	}
+	status, code := webhookResult(c, err)
+	r := &Recorded{
+		Status: status,
+		Header: make(http.Header),
+		Body:   slim.Map{"ok": status < 400, "code": code},
+	}
+	return commit(c, "json", r)
+}
+
+// webhookResult maps err to the status and code of a webhook response.
+func webhookResult(c slim.Context, err error) (int, string) {
+	if err == nil {
+		return http.StatusOK, "OK"
+	}
+	if errors.Is(err, ErrInvalidSignature) {
+		return http.StatusUnauthorized, "InvalidSignature"
+	}
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return http.StatusRequestEntityTooLarge, statusCode(http.StatusRequestEntityTooLarge)
+	}
+	var he *slim.HTTPError
+	if errors.As(err, &he) {
+		// The status of an HTTP error tells the sender whether to retry
+		if code := statusCode(he.Code); code != "" && he.Code >= 400 && he.Code < 500 {
+			return he.Code, code
+		}
+		return http.StatusInternalServerError, "InternalError"
+	}
+	status, m := result(c, newOptions([]Option{Error(err)}))
+	if status < 400 {
+		return http.StatusInternalServerError, "InternalError"
+	}
+	code, _ := m["code"].(string)
+	return status, code
+}
+
+// statusCode returns the envelope code of status, its status text in
+// CamelCase such as "NotFound" for 404, or "" for unknown statuses.
+func statusCode(status int) string {
+	var b strings.Builder
+	for _, word := range strings.Fields(http.StatusText(status)) {
+		for i, r := range word {
+			switch {
+			case i == 0:
+				b.WriteRune(unicode.ToUpper(r))
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// WebhookSignature configures the verification of webhook signatures,
+// computed as the hex encoded HMAC-SHA256 of the request body.
+type WebhookSignature struct {
+	Secret []byte // Required
+	Header string // Request header carrying the signature, defaults to "X-Signature"
+
+	// TimestampHeader is the request header carrying the Unix time of the
+	// delivery. When set, the signed payload is "<timestamp>.<body>" and
+	// deliveries older than Tolerance are rejected, so that captured
+	// requests cannot be replayed later.
+	TimestampHeader string
+	Tolerance       time.Duration // Defaults to 5 minutes
+
+	// MaxBodySize is the maximum size of the body read before the signature
+	// is verified, in bytes, defaults to DefaultWebhookMaxBodySize.
+	MaxBodySize int64
+}
+
+// DefaultWebhookMaxBodySize is the maximum size of webhook bodies read by
+// VerifyWebhook when WebhookSignature.MaxBodySize is not set.
+const DefaultWebhookMaxBodySize = 1 << 20
+
+// VerifyWebhook reads the request body and verifies its signature,
+// returning ErrInvalidSignature if it does not match. The body is restored
+// so that it can still be bound by the handler. Signatures may carry a
+// "sha256=" prefix, as sent by many providers.
+//
+// Bodies larger than MaxBodySize are rejected with an *http.MaxBytesError,
+// which Webhook answers with 413 Request Entity Too Large, so that
+// unauthenticated senders cannot make the server buffer any amount of data.
+func VerifyWebhook(c slim.Context, sig WebhookSignature) ([]byte, error) {
+	req := c.Request()
+	limit := sig.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultWebhookMaxBodySize
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, limit))
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := sig.Header
+	if header == "" {
+		header = "X-Signature"
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(c.Header(header), "sha256="))
+	if err != nil || len(got) == 0 {
+		return nil, ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, sig.Secret)
+	if sig.TimestampHeader != "" {
+		ts := c.Header(sig.TimestampHeader)
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidSignature
+		}
+		tolerance := sig.Tolerance
+		if tolerance <= 0 {
+			tolerance = 5 * time.Minute
+		}
+		if age := time.Since(time.Unix(sec, 0)); age > tolerance || age < -tolerance {
+			return nil, ErrInvalidSignature
+		}
+		mac.Write([]byte(ts + "."))
+	}
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+	return body, nil
+}
+
+// WebhookConfig configures the webhook receiver middleware.
+type WebhookConfig struct {
+	Skipper    func(c slim.Context) bool
+	Signature  *WebhookSignature // Verified before the handler when set
+	Store      IdempotencyStore  // Deduplicates deliveries when set
+	TTL        time.Duration     // How long outcomes are replayed, defaults to 24 hours
+	PendingTTL time.Duration     // How long a delivery in progress holds its ID, defaults to 1 minute
+	Header     string            // Request header carrying the delivery ID, defaults to "X-Webhook-Id"
+}
+
+// ToMiddleware creates a middleware for webhook receiver endpoints. It
+// rejects deliveries with an invalid signature, and replays the recorded
+// outcome of deliveries whose ID was already handled, with an
+// "Idempotent-Replayed: true" header, so that redelivered events are not
+// processed twice. Redeliveries arriving while the first delivery is in
+// progress are answered with 409 Conflict. Server errors are not recorded,
+// so that the sender can retry them.
+func (config WebhookConfig) ToMiddleware() slim.MiddlewareFunc {
+	ttl := config.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	pending := config.PendingTTL
+	if pending <= 0 {
+		pending = time.Minute
+	}
+	header := config.Header
+	if header == "" {
+		header = "X-Webhook-Id"
+	}
+	return func(c slim.Context, next slim.HandlerFunc) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return next(c)
+		}
+		if config.Signature != nil {
+			if _, err := VerifyWebhook(c, *config.Signature); err != nil {
+				return Webhook(c, err)
+			}
+		}
+
+		id := c.Header(header)
+		if config.Store == nil || id == "" {
+			return next(c)
+		}
+		entry := &idempotencyEntry{
+			store: config.Store,
+			key:   "webhook " + c.Request().URL.Path + " " + id,
+			ttl:   ttl,
+		}
+		return idempotent(c, entry, pending, next, func(r *Recorded) error {
+			return write(c, "json", r)
+		}, func() error {
+			c.SetHeader("Retry-After", "1")
+			return Webhook(c, slim.NewHTTPError(http.StatusConflict))
+		})
+	}
+}
+
+// WebhookReceiver creates the webhook receiver middleware from the given
+// config.
+//
+// Example:
+//
+//	hooks := app.Group("/webhooks")
+//	hooks.Use(rsp.WebhookReceiver(rsp.WebhookConfig{
+//		Signature: &rsp.WebhookSignature{Secret: secret},
+//		Store:     rsp.NewRedisStore(rdb, "webhooks"),
+//	}))
+func WebhookReceiver(config WebhookConfig) slim.MiddlewareFunc {
+	return config.ToMiddleware()
+}
@@ -0,0 +1,198 @@
+package rsp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-slim.dev/slim"
+)
+
+var webhookSecret = []byte("webhook-secret")
+
+// sign returns the hex HMAC-SHA256 of the payload with webhookSecret
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, webhookSecret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDelivery creates a context for a webhook delivery with the given body and headers
+func newDelivery(body string, headers map[string]string) (slim.Context, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	return slim.New().NewContext(recorder, request), recorder
+}
+
+func TestWebhookStatusMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{"success", nil, http.StatusOK, `{"code":"OK","ok":true}`},
+		{"client error", slim.NewHTTPError(http.StatusNotFound), http.StatusNotFound, `{"code":"NotFound","ok":false}`},
+		{"invalid signature", ErrInvalidSignature, http.StatusUnauthorized, `{"code":"InvalidSignature","ok":false}`},
+		{"server error", errors.New("database is down"), http.StatusInternalServerError, `{"code":"InternalError","ok":false}`},
+		{"conflict", slim.NewHTTPError(http.StatusConflict, "Order already paid"), http.StatusConflict, `{"code":"Conflict","ok":false}`},
+		{"redirect", slim.NewHTTPError(http.StatusFound), http.StatusInternalServerError, `{"code":"InternalError","ok":false}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, recorder := createContextWithAccept("text/html")
+			if err := Webhook(ctx, tt.err); err != nil {
+				t.Fatalf("Webhook() error = %v", err)
+			}
+			if recorder.Code != tt.status {
+				t.Errorf("status = %v, want %v", recorder.Code, tt.status)
+			}
+			if got := strings.TrimSpace(recorder.Body.String()); got != tt.body {
+				t.Errorf("body = %s, want %s", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := map[int]string{
+		http.StatusBadRequest:          "BadRequest",
+		http.StatusNotFound:            "NotFound",
+		http.StatusRequestURITooLong:   "RequestURITooLong",
+		http.StatusTeapot:              "ImATeapot",
+		http.StatusUnprocessableEntity: "UnprocessableEntity",
+		499:                            "",
+	}
+	for status, want := range tests {
+		if got := statusCode(status); got != want {
+			t.Errorf("statusCode(%d) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := `{"event":"paid"}`
+
+	ctx, _ := newDelivery(body, map[string]string{"X-Signature": "sha256=" + sign(body)})
+	got, err := VerifyWebhook(ctx, WebhookSignature{Secret: webhookSecret})
+	if err != nil {
+		t.Fatalf("VerifyWebhook() error = %v", err)
+	}
+	if string(got) != body {
+		t.Errorf("body = %s, want %s", got, body)
+	}
+	if restored, _ := io.ReadAll(ctx.Request().Body); string(restored) != body {
+		t.Errorf("restored body = %s, want %s", restored, body)
+	}
+
+	for name, headers := range map[string]map[string]string{
+		"missing":   {},
+		"malformed": {"X-Signature": "not-hex"},
+		"mismatch":  {"X-Signature": sign(`{"event":"refunded"}`)},
+	} {
+		ctx, _ := newDelivery(body, headers)
+		if _, err := VerifyWebhook(ctx, WebhookSignature{Secret: webhookSecret}); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: VerifyWebhook() error = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+}
+
+func TestVerifyWebhookMaxBodySize(t *testing.T) {
+	body := `{"event":"paid","note":"` + strings.Repeat("x", 64) + `"}`
+
+	ctx, recorder := newDelivery(body, map[string]string{"X-Signature": sign(body)})
+	_, err := VerifyWebhook(ctx, WebhookSignature{Secret: webhookSecret, MaxBodySize: 32})
+	var mbe *http.MaxBytesError
+	if !errors.As(err, &mbe) {
+		t.Fatalf("VerifyWebhook() error = %v, want *http.MaxBytesError", err)
+	}
+	if err := Webhook(ctx, err); err != nil {
+		t.Fatalf("Webhook() error = %v", err)
+	}
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %v, want %v", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	// Bodies within the limit are verified as usual
+	ctx, _ = newDelivery(body, map[string]string{"X-Signature": sign(body)})
+	if _, err := VerifyWebhook(ctx, WebhookSignature{Secret: webhookSecret, MaxBodySize: int64(len(body))}); err != nil {
+		t.Errorf("VerifyWebhook() error = %v", err)
+	}
+}
+
+func TestVerifyWebhookTimestamp(t *testing.T) {
+	body := `{"event":"paid"}`
+	sig := WebhookSignature{Secret: webhookSecret, Header: "X-Hub-Signature", TimestampHeader: "X-Timestamp"}
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	ctx, _ := newDelivery(body, map[string]string{"X-Timestamp": now, "X-Hub-Signature": sign(now + "." + body)})
+	if _, err := VerifyWebhook(ctx, sig); err != nil {
+		t.Errorf("VerifyWebhook() error = %v", err)
+	}
+
+	// A correctly signed but old delivery is rejected
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	ctx, _ = newDelivery(body, map[string]string{"X-Timestamp": old, "X-Hub-Signature": sign(old + "." + body)})
+	if _, err := VerifyWebhook(ctx, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyWebhook() error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestWebhookReceiver(t *testing.T) {
+	mw := WebhookReceiver(WebhookConfig{
+		Signature: &WebhookSignature{Secret: webhookSecret},
+		Store:     NewMemoryStore(),
+	})
+
+	calls := 0
+	fail := true
+	handler := func(c slim.Context) error {
+		calls++
+		if fail {
+			return Webhook(c, errors.New("temporary failure"))
+		}
+		return Webhook(c, nil)
+	}
+
+	body := `{"event":"paid"}`
+	deliver := func(id, signature string) *httptest.ResponseRecorder {
+		ctx, recorder := newDelivery(body, map[string]string{"X-Webhook-Id": id, "X-Signature": signature})
+		_ = mw(ctx, handler)
+		return recorder
+	}
+
+	// Invalid signatures never reach the handler
+	if rec := deliver("evt_1", sign("forged")); rec.Code != http.StatusUnauthorized || calls != 0 {
+		t.Errorf("forged delivery: status = %v, calls = %v", rec.Code, calls)
+	}
+
+	// Server errors are not recorded, so the retry is processed
+	if rec := deliver("evt_1", sign(body)); rec.Code != http.StatusInternalServerError {
+		t.Errorf("failed delivery status = %v, want %v", rec.Code, http.StatusInternalServerError)
+	}
+	fail = false
+	first := deliver("evt_1", sign(body))
+	second := deliver("evt_1", sign(body))
+
+	if calls != 2 {
+		t.Errorf("handler calls = %v, want 2", calls)
+	}
+	if second.Code != http.StatusOK || second.Body.String() != first.Body.String() {
+		t.Errorf("replayed response = %v %s, want %v %s", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response should carry Idempotent-Replayed header")
+	}
+}
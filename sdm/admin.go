@@ -0,0 +1,325 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the administration API listing the active locks and breaking
+// stuck ones, and its HTTP handler.
+package sdm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error definitions
+var (
+	// ErrAdminNotSupported is returned by ListLocks and ForceUnlock when the backend
+	// cannot enumerate or force the release of its leases (see LockAdmin).
+	ErrAdminNotSupported = errors.New("sdm: backend does not support lock administration")
+	// ErrTokenMismatch is returned by ForceUnlock when no lease of the lock holds the
+	// given fencing token, because it has been released, expired or acquired again.
+	ErrTokenMismatch = errors.New("sdm: no lease holds the fencing token")
+)
+
+// LockAdmin is implemented by the backends supporting ListLocks and ForceUnlock.
+//
+// Every new lease is given a fencing token, greater than the tokens of the leases
+// previously acquired on the same lock and reported in Holder.Token, with which
+// ForceRelease confirms which lease it breaks.
+type LockAdmin interface {
+	LeaseInspector
+
+	// Locks returns the keys of the locks matching the glob pattern (see MatchPattern)
+	// that may have unexpired leases, in any order.
+	Locks(ctx context.Context, pattern string) ([]string, error)
+
+	// ForceRelease removes the unexpired lease of key holding the fencing token,
+	// whatever its value, owner and hold count, and wakes up the waiting lockers.
+	// It returns false if no lease holds the token.
+	ForceRelease(ctx context.Context, key string, token int64) (bool, error)
+}
+
+// lockAdmin returns the LockAdmin of the backend given to ListLocks or ForceUnlock,
+// the Redis backend of the registry r by default
+func lockAdmin(r *Registry, backend []LockBackend) (LockAdmin, error) {
+	var b LockBackend = r.backend
+	if len(backend) > 0 && backend[0] != nil {
+		b = backend[0]
+	}
+	admin, ok := b.(LockAdmin)
+	if !ok {
+		return nil, ErrAdminNotSupported
+	}
+	return admin, nil
+}
+
+// ListLocks returns the currently held locks whose name matches the glob pattern,
+// with their holders (see Mutex.Info), sorted by name. The pattern follows the
+// syntax of Redis KEYS (see MatchPattern); "*" lists every lock.
+//
+// The locks are looked up in the given backend, the Redis client set with SetRedis
+// by default. On Redis, the keys are enumerated with SCAN, on every master of a
+// Redis Cluster. Returns ErrAdminNotSupported if the backend does not implement LockAdmin.
+//
+// Example:
+//
+//	locks, err := sdm.ListLocks(ctx, "orders:*")
+//	if err != nil {
+//	    return err
+//	}
+//	for _, l := range locks {
+//	    for _, h := range l.Holders {
+//	        log.Printf("%s held by %s (pid %d), token %d", l.Name, h.Hostname, h.PID, h.Token)
+//	    }
+//	}
+func ListLocks(ctx context.Context, pattern string, backend ...LockBackend) ([]LockInfo, error) {
+	return listLocks(ctx, defaultRegistry, pattern, backend)
+}
+
+// listLocks lists the locks of the registry r matching pattern, see ListLocks
+func listLocks(ctx context.Context, r *Registry, pattern string, backend []LockBackend) ([]LockInfo, error) {
+	admin, err := lockAdmin(r, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := r.Prefix()
+	if prefix != "" {
+		prefix += ":"
+	}
+	keys, err := admin.Locks(ctx, prefix+pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	locks := make([]LockInfo, 0, len(keys))
+	for _, key := range keys {
+		holders, err := admin.Holders(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(holders) == 0 {
+			continue
+		}
+		sort.Slice(holders, func(i, j int) bool { return holders[i].Value < holders[j].Value })
+		locks = append(locks, LockInfo{Name: strings.TrimPrefix(key, prefix), Key: key, Holders: holders})
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Name < locks[j].Name })
+	return locks, nil
+}
+
+// ForceUnlock breaks the lease holding the fencing token on the lock of the mutex
+// named name, whatever its value, so operators can release a lock stuck with a
+// holder that is gone. Waiting lockers are woken up, and the former holder gets
+// ErrMutexNotAcquired from Unlock.
+//
+// The token is the Holder.Token returned by ListLocks or Mutex.Info. It confirms
+// that the lease broken is the one that was inspected: if it has been released
+// or expired in the meantime, possibly to be acquired again by a healthy holder,
+// ForceUnlock returns ErrTokenMismatch instead.
+//
+// The lease is looked up in the given backend, the Redis client set with SetRedis
+// by default. Returns ErrAdminNotSupported if the backend does not implement LockAdmin.
+//
+// Example:
+//
+//	err := sdm.ForceUnlock(ctx, "orders:42", holder.Token)
+//	if errors.Is(err, sdm.ErrTokenMismatch) {
+//	    log.Println("the lock changed hands, inspect it again")
+//	}
+func ForceUnlock(ctx context.Context, name string, token int64, backend ...LockBackend) error {
+	return forceUnlock(ctx, defaultRegistry, name, token, backend)
+}
+
+// forceUnlock breaks the lease holding token on the lock named name in the registry
+// r, see ForceUnlock
+func forceUnlock(ctx context.Context, r *Registry, name string, token int64, backend []LockBackend) error {
+	admin, err := lockAdmin(r, backend)
+	if err != nil {
+		return err
+	}
+
+	key, err := r.LockKey(name)
+	if err != nil {
+		return err
+	}
+	released, err := admin.ForceRelease(ctx, key, token)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrTokenMismatch
+	}
+	observeForced(name)
+	return nil
+}
+
+// AdminHandler returns an HTTP handler exposing ListLocks and ForceUnlock for
+// dashboards and operators, on the given backend or the default Redis backend:
+//
+//   - GET lists the locks matching the "pattern" query parameter ("*" by default)
+//     as a JSON array of LockInfo.
+//   - DELETE breaks the lease of the lock named by the "name" query parameter
+//     holding the fencing token given by the "token" query parameter, and responds
+//     with 204, or 409 if no lease holds the token.
+//
+// Other methods, including POST, are rejected with 405: unlike POST, DELETE cannot
+// be sent cross-site by a form or without a CORS preflight, so a page visited by an
+// operator cannot break locks with the operator's credentials.
+//
+// The handler performs no authentication, mount it behind the access control of
+// the application.
+//
+// Example:
+//
+//	http.Handle("/admin/locks", sdm.AdminHandler())
+func AdminHandler(backend ...LockBackend) http.Handler {
+	return adminHandler(defaultRegistry, backend)
+}
+
+// adminHandler returns the handler administering the locks of the registry reg,
+// see AdminHandler
+func adminHandler(reg *Registry, backend []LockBackend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			pattern := query.Get("pattern")
+			if pattern == "" {
+				pattern = "*"
+			}
+			locks, err := listLocks(r.Context(), reg, pattern, backend)
+			if err != nil {
+				adminError(w, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(locks)
+
+		case http.MethodDelete:
+			token, err := strconv.ParseInt(query.Get("token"), 10, 64)
+			if err != nil {
+				http.Error(w, "sdm: invalid fencing token", http.StatusBadRequest)
+				return
+			}
+			if err := forceUnlock(r.Context(), reg, query.Get("name"), token, backend); err != nil {
+				adminError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.Header().Set("Allow", "GET, HEAD, DELETE")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// adminError responds to an administration request that failed with err
+func adminError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrMutexNameEmpty), errors.Is(err, ErrMutexNameInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrTokenMismatch):
+		status = http.StatusConflict
+	case errors.Is(err, ErrAdminNotSupported):
+		status = http.StatusNotImplemented
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// MatchPattern reports whether key matches the glob pattern, following the syntax
+// of Redis KEYS and SCAN: "*" matches any sequence of characters, "?" any single
+// character, "[abc]", "[a-z]" and "[^a]" a character class, and "\" escapes the
+// next character. It is meant for LockAdmin implementations outside Redis.
+func MatchPattern(pattern, key string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			if pattern == "" {
+				return true
+			}
+			for i := range len(key) + 1 {
+				if MatchPattern(pattern, key[i:]) {
+					return true
+				}
+			}
+			return false
+
+		case '?':
+			if key == "" {
+				return false
+			}
+			_, n := utf8.DecodeRuneInString(key)
+			pattern, key = pattern[1:], key[n:]
+
+		case '[':
+			if key == "" {
+				return false
+			}
+			c, n := utf8.DecodeRuneInString(key)
+			rest, ok := matchClass(pattern[1:], c)
+			if !ok {
+				return false
+			}
+			pattern, key = rest, key[n:]
+
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if key == "" || key[0] != pattern[0] {
+				return false
+			}
+			pattern, key = pattern[1:], key[1:]
+		}
+	}
+	return key == ""
+}
+
+// matchClass matches c against the character class at the start of pattern,
+// following its opening "[". It returns the pattern after the closing "]" and
+// whether c belongs to the class, false for an unterminated class.
+func matchClass(pattern string, c rune) (string, bool) {
+	negate := strings.HasPrefix(pattern, "^")
+	if negate {
+		pattern = pattern[1:]
+	}
+
+	// next returns the next character of the class, unescaped
+	next := func() rune {
+		if pattern[0] == '\\' && len(pattern) > 1 {
+			pattern = pattern[1:]
+		}
+		r, n := utf8.DecodeRuneInString(pattern)
+		pattern = pattern[n:]
+		return r
+	}
+
+	matched := false
+	for {
+		if pattern == "" {
+			return "", false
+		}
+		if pattern[0] == ']' {
+			return pattern[1:], matched != negate
+		}
+		lo := next()
+		hi := lo
+		if len(pattern) > 1 && pattern[0] == '-' && pattern[1] != ']' {
+			pattern = pattern[1:]
+			hi = next()
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if lo <= c && c <= hi {
+			matched = true
+		}
+	}
+}
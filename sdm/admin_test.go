@@ -0,0 +1,244 @@
+package sdm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"*", "", true},
+		{"*", "mutex:orders", true},
+		{"mutex:*", "mutex:orders:42", true},
+		{"mutex:*", "other:orders", false},
+		{"*:42", "mutex:orders:42", true},
+		{"mutex:order?", "mutex:orders", true},
+		{"mutex:order?", "mutex:order", false},
+		{"mutex:订单-?", "mutex:订单-一", true},
+		{"mutex:[ab]", "mutex:a", true},
+		{"mutex:[ab]", "mutex:c", false},
+		{"mutex:[a-c]x", "mutex:bx", true},
+		{"mutex:[^a-c]", "mutex:b", false},
+		{"mutex:[^a-c]", "mutex:d", true},
+		{"mutex:[a", "mutex:a", false},
+		{`mutex:\*`, "mutex:*", true},
+		{`mutex:\*`, "mutex:a", false},
+		{"mutex:*:*:end", "mutex:a:b:c:end", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.pattern, tt.key), func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchPattern(tt.pattern, tt.key))
+		})
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	backends := map[string]LockBackend{
+		"Redis": defaultBackend,
+		"内存":    NewMemoryBackend(),
+	}
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			orders, err := New[string]("test-admin:orders")
+			require.NoError(t, err)
+			orders = orders.With(WithBackend(b))
+			users, err := New[string]("test-admin:users")
+			require.NoError(t, err)
+			users = users.With(WithBackend(b))
+
+			require.NoError(t, orders.Lock(ctx, "owner-1"))
+			require.NoError(t, users.Lock(ctx, "owner-2"))
+			require.NoError(t, users.With(WithTTL(time.Minute)).Lock(ctx, "owner-3"))
+
+			t.Run("列出锁", func(t *testing.T) {
+				locks, err := ListLocks(ctx, "test-admin:*", b)
+				require.NoError(t, err)
+				require.Len(t, locks, 2)
+				assert.Equal(t, "test-admin:orders", locks[0].Name)
+				assert.Equal(t, RedisKeyPrefix+":test-admin:orders", locks[0].Key)
+				require.Len(t, locks[0].Holders, 1)
+				assert.Equal(t, "owner-1", locks[0].Holders[0].Value)
+				assert.Equal(t, "test-admin:users", locks[1].Name)
+				require.Len(t, locks[1].Holders, 2)
+
+				locks, err = ListLocks(ctx, "test-admin:u*", b)
+				require.NoError(t, err)
+				require.Len(t, locks, 1)
+				assert.Equal(t, "test-admin:users", locks[0].Name)
+
+				locks, err = ListLocks(ctx, "test-admin:none", b)
+				require.NoError(t, err)
+				assert.Empty(t, locks)
+			})
+
+			t.Run("令牌递增", func(t *testing.T) {
+				info, err := users.Info(ctx)
+				require.NoError(t, err)
+				require.Len(t, info.Holders, 2)
+				assert.Positive(t, info.Holders[0].Token)
+				assert.Greater(t, info.Holders[1].Token, info.Holders[0].Token)
+			})
+
+			t.Run("强制释放", func(t *testing.T) {
+				info, err := orders.Info(ctx)
+				require.NoError(t, err)
+				require.Len(t, info.Holders, 1)
+				token := info.Holders[0].Token
+
+				// 等待者在锁被强制释放后立即获取
+				done := make(chan error, 1)
+				go func() {
+					done <- orders.Lock(ctx, "owner-1")
+				}()
+				time.Sleep(50 * time.Millisecond)
+
+				require.NoError(t, ForceUnlock(ctx, "test-admin:orders", token, b))
+				select {
+				case err := <-done:
+					require.NoError(t, err)
+				case <-time.After(maxBackoff / 2):
+					t.Fatal("强制释放后等待者应该被唤醒")
+				}
+
+				// 旧令牌不能释放重新获取的锁
+				assert.Equal(t, ErrTokenMismatch, ForceUnlock(ctx, "test-admin:orders", token, b))
+				locked, err := orders.IsLocked(ctx)
+				require.NoError(t, err)
+				assert.True(t, locked)
+
+				info, err = orders.Info(ctx)
+				require.NoError(t, err)
+				require.Len(t, info.Holders, 1)
+				assert.Greater(t, info.Holders[0].Token, token)
+				require.NoError(t, orders.Unlock(ctx, "owner-1"))
+			})
+
+			t.Run("强制释放可重入锁", func(t *testing.T) {
+				m := orders.With(WithReentrant("owner-a"))
+				require.NoError(t, m.Lock(ctx, "job"))
+				require.NoError(t, m.Lock(ctx, "job"))
+
+				info, err := m.Info(ctx)
+				require.NoError(t, err)
+				require.Len(t, info.Holders, 1)
+				require.NoError(t, ForceUnlock(ctx, "test-admin:orders", info.Holders[0].Token, b))
+
+				locked, err := m.IsLocked(ctx)
+				require.NoError(t, err)
+				assert.False(t, locked)
+				assert.Equal(t, ErrMutexNotAcquired, m.Unlock(ctx, "job"))
+			})
+
+			require.NoError(t, users.Unlock(ctx, "owner-2"))
+			require.NoError(t, users.Unlock(ctx, "owner-3"))
+		})
+	}
+
+	t.Run("后端不支持", func(t *testing.T) {
+		b := struct{ LockBackend }{NewMemoryBackend()}
+		_, err := ListLocks(ctx, "*", b)
+		assert.Equal(t, ErrAdminNotSupported, err)
+		assert.Equal(t, ErrAdminNotSupported, ForceUnlock(ctx, "test-admin", 1, b))
+	})
+}
+
+func TestAdminHandler(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBackend()
+	handler := AdminHandler(b)
+
+	m, err := New[string]("test-handler")
+	require.NoError(t, err)
+	m = m.With(WithBackend(b), WithLabels(map[string]string{"job": "report"}))
+	require.NoError(t, m.Lock(ctx, "owner-1"))
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	t.Run("列出锁", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/?pattern=test-*")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var locks []LockInfo
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &locks))
+		require.Len(t, locks, 1)
+		assert.Equal(t, "test-handler", locks[0].Name)
+		require.Len(t, locks[0].Holders, 1)
+		assert.Equal(t, "owner-1", locks[0].Holders[0].Value)
+		assert.Equal(t, "report", locks[0].Holders[0].Labels["job"])
+	})
+
+	t.Run("请求错误", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodDelete, "/?name=test-handler&token=x").Code)
+		assert.Equal(t, http.StatusConflict, serve(http.MethodDelete, "/?name=test-handler&token=999").Code)
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPut, "/").Code)
+	})
+
+	t.Run("拒绝表单提交", func(t *testing.T) {
+		info, err := m.Info(ctx)
+		require.NoError(t, err)
+		require.Len(t, info.Holders, 1)
+
+		// 跨站页面可以提交的普通表单不能强制释放锁
+		form := url.Values{"name": {"test-handler"}, "token": {strconv.FormatInt(info.Holders[0].Token, 10)}}
+		req := httptest.NewRequest(http.MethodPost, "/?"+form.Encode(), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, HEAD, DELETE", rec.Header().Get("Allow"))
+
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+	})
+
+	t.Run("强制释放", func(t *testing.T) {
+		info, err := m.Info(ctx)
+		require.NoError(t, err)
+		require.Len(t, info.Holders, 1)
+
+		rec := serve(http.MethodDelete, fmt.Sprintf("/?name=test-handler&token=%d", info.Holders[0].Token))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("后端不支持", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		AdminHandler(struct{ LockBackend }{b}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusNotImplemented, rec.Code)
+	})
+}
@@ -0,0 +1,115 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the LockBackend interface through which a Mutex stores its leases.
+package sdm
+
+import (
+	"context"
+	"time"
+)
+
+// LockBackend stores the leases of the locks of a Mutex. The default backend is
+// Redis (see RedisBackend); others are selected per Mutex with WithBackend.
+//
+// A lock is identified by its key and may be held by several values at once, each
+// value holding at most one lease. Implementations must be safe for concurrent use
+// and make every method atomic with respect to the others.
+type LockBackend interface {
+	// Acquire creates the lease of lease.Value on lease.Key. If the value already
+	// holds a lease, Acquire returns LeaseNested after incrementing its hold count
+	// and renewing it when both were acquired by the same non-empty lease.Owner,
+	// and LeaseUnchanged otherwise.
+	Acquire(ctx context.Context, lease Lease) (LeaseOutcome, error)
+
+	// Release removes the lease of lease.Value on lease.Key and returns LeaseChanged.
+	// If the lease has a hold count above one for lease.Owner, Release decrements it
+	// and returns LeaseNested instead. It returns LeaseUnchanged if the value does
+	// not hold a lease.
+	Release(ctx context.Context, lease Lease) (LeaseOutcome, error)
+
+	// Renew extends the lease of lease.Value on lease.Key by lease.TTL from now.
+	// It returns false if the value no longer holds a lease.
+	Renew(ctx context.Context, lease Lease) (bool, error)
+
+	// IsLocked reports whether any value holds an unexpired lease on key.
+	IsLocked(ctx context.Context, key string) (bool, error)
+}
+
+// ReleaseWatcher is implemented by the backends able to notify waiting lockers
+// of released leases, so they retry at once instead of polling.
+type ReleaseWatcher interface {
+	// WatchReleases returns a channel receiving the values whose lease on key is
+	// released, until ctx is done. No release happening after WatchReleases returns
+	// may be missed, while a release may be dropped if the receiver falls behind.
+	WatchReleases(ctx context.Context, key string) (<-chan string, error)
+}
+
+// FairBackend is implemented by the backends able to grant a lock to its waiters
+// by priority, then in the order they started waiting (see WithFairness and
+// WithPriority), instead of to the first one to retry after a release.
+//
+// A waiter is identified by a ticket unique to one TryLock or Lock call. Waiters
+// are queued per lock value, since only the lockers sharing a value exclude each
+// other.
+type FairBackend interface {
+	// AcquireFair is like Acquire, except that it only creates the lease of
+	// lease.Value when no waiter for the value is queued ahead of ticket, that is
+	// with a higher priority, or with the same priority and queued earlier. If the
+	// lease is not created, the waiter ticket is queued with its priority, or keeps
+	// its place in the queue, for wait; a zero wait does not queue it. The waiter
+	// leaves the queue once it acquires the lease, when Dequeue is called, or once
+	// wait elapses without a new call.
+	AcquireFair(ctx context.Context, lease Lease, ticket string, priority int32, wait time.Duration) (LeaseOutcome, error)
+
+	// Dequeue removes the waiter ticket from the queue of lease.Value on lease.Key.
+	Dequeue(ctx context.Context, lease Lease, ticket string) error
+}
+
+// LeaseExtender is implemented by the backends able to extend a lease by any
+// duration, which Mutex.Extend requires. Renew only has to extend a lease by the
+// TTL it was acquired with, which some backends cannot change.
+type LeaseExtender interface {
+	// Extend makes the lease of lease.Value on lease.Key expire lease.TTL from now,
+	// exactly, whatever the TTL it was acquired with, including none. It returns
+	// false if the value no longer holds a lease.
+	Extend(ctx context.Context, lease Lease) (bool, error)
+}
+
+// Lease describes the lease of a value on a lock.
+type Lease struct {
+	Key   string        // Key of the lock, "<RedisKeyPrefix>:<name>"
+	Value string        // Serialized value of the lock owner
+	TTL   time.Duration // Lease duration, zero if the lease never expires
+	Owner string        // Owner ID of reentrant acquisitions (see WithReentrant), empty otherwise
+
+	// Metadata describes the process acquiring the lease. Acquire records it with a
+	// new lease for LeaseInspector, backends unable to store it ignore it.
+	Metadata *Metadata
+}
+
+// LeaseOutcome reports how a LockBackend operation changed a lease.
+type LeaseOutcome int
+
+const (
+	// LeaseUnchanged means Acquire found the value holding a lease it may not
+	// reenter, or Release found no lease of the value.
+	LeaseUnchanged LeaseOutcome = iota
+	// LeaseChanged means Acquire created the lease, or Release removed it.
+	LeaseChanged
+	// LeaseNested means Acquire or Release changed the hold count of a reentrant
+	// lease that its owner still holds.
+	LeaseNested
+)
+
+// watchReleases returns the values released from key, or nil if b does not
+// notify releases, in which case waiters poll.
+func watchReleases(ctx context.Context, b LockBackend, key string) <-chan string {
+	w, ok := b.(ReleaseWatcher)
+	if !ok {
+		return nil
+	}
+	released, err := w.WatchReleases(ctx, key)
+	if err != nil {
+		return nil
+	}
+	return released
+}
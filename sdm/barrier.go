@@ -0,0 +1,243 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the Barrier and CountdownLatch synchronization primitives.
+package sdm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidCount is returned by NewBarrier and NewCountdownLatch for a number of
+// parties or an initial count lower than 1
+var ErrInvalidCount = errors.New("sdm: count must be positive")
+
+// Barrier is a distributed cyclic barrier stored in Redis, at which a fixed number
+// of parties across processes wait for each other, such as the pods of a deployment
+// warming up their caches before serving traffic. Once the last party arrives the
+// barrier trips, releasing every waiting party, and can be used again.
+//
+// The barrier is stored at the key "{<RedisKeyPrefix>:<name>}:barrier". Like Mutex,
+// a Barrier is an immutable value safe for concurrent use.
+type Barrier struct {
+	name     string    // Unique identifier for the barrier
+	parties  int       // Number of parties tripping the barrier
+	registry *Registry // Registry storing the barrier, nil for the default one
+}
+
+// NewBarrier creates a distributed barrier with the given name, tripped once the
+// given number of parties have arrived.
+//
+// Example:
+//
+//	b, err := sdm.NewBarrier("cache:warm-up", replicas)
+//	if err != nil {
+//	    return err
+//	}
+//	warmUp()
+//	if err := b.Await(ctx); err != nil { // waits for the other replicas
+//	    return err
+//	}
+//
+// Returns ErrMutexNameEmpty if the name is empty, ErrInvalidCount if parties is
+// lower than 1.
+func NewBarrier(name string, parties int) (Barrier, error) {
+	return defaultRegistry.NewBarrier(name, parties)
+}
+
+// Name returns the unique identifier for this barrier.
+func (b Barrier) Name() string {
+	return b.name
+}
+
+// Parties returns the number of parties tripping the barrier.
+func (b Barrier) Parties() int {
+	return b.parties
+}
+
+// Await registers the arrival of a party and waits until the barrier trips, that
+// is until the given number of parties have arrived. Waiting parties are woken up
+// by a Redis Pub/Sub notification, or else by polling.
+//
+// If ctx is done before the barrier trips, or waiting fails, the arrival is
+// withdrawn and the error is returned. A party that stops without withdrawing its
+// arrival, such as a crashed process, still counts until the barrier trips or is
+// reset.
+func (b Barrier) Await(ctx context.Context) error {
+	rdb, key, err := primitiveKey(b.registry, b.name, "barrier")
+	if err != nil {
+		return err
+	}
+	channel := releaseChannel(key)
+
+	// Subscribe before arriving, so that the trip is not missed
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	released := redisReleases(wctx, rdb, channel)
+
+	result, err := barrierAwaitScript.Run(ctx, rdb, []string{key}, b.parties, channel).Int64Slice()
+	if err != nil {
+		return fmt.Errorf("sdm: failed to arrive at barrier: %w", err)
+	}
+	generation, tripped := result[0], result[1] == 1
+	if tripped {
+		return nil
+	}
+
+	err = awaitNotified(wctx, released, func(ctx context.Context) (bool, error) {
+		current, _, err := barrierState(ctx, rdb, key)
+		return current > generation, err
+	})
+	if err == nil {
+		return nil
+	}
+
+	// Withdraw the arrival, unless the barrier tripped meanwhile
+	left, lerr := barrierLeaveScript.Run(context.WithoutCancel(ctx), rdb, []string{key}, generation).Int64()
+	if lerr == nil && left == 0 {
+		return nil
+	}
+	return err
+}
+
+// Waiting returns the number of parties waiting at the barrier.
+func (b Barrier) Waiting(ctx context.Context) (int, error) {
+	rdb, key, err := primitiveKey(b.registry, b.name, "barrier")
+	if err != nil {
+		return 0, err
+	}
+	_, arrived, err := barrierState(ctx, rdb, key)
+	return int(arrived), err
+}
+
+// Reset deletes the barrier, discarding the arrivals of the parties that stopped
+// without withdrawing them. It must not be called while parties are waiting.
+func (b Barrier) Reset(ctx context.Context) error {
+	return resetPrimitive(ctx, b.registry, b.name, "barrier")
+}
+
+// barrierState returns the current generation of the barrier stored at key and the
+// number of parties arrived in it
+func barrierState(ctx context.Context, rdb redis.Scripter, key string) (generation, arrived int64, err error) {
+	result, err := barrierStateScript.Run(ctx, rdb, []string{key}).Int64Slice()
+	if err != nil {
+		return 0, 0, fmt.Errorf("sdm: failed to read barrier: %w", err)
+	}
+	return result[0], result[1], nil
+}
+
+// CountdownLatch is a distributed countdown latch stored in Redis, which opens once
+// it has been counted down a given number of times, such as by the workers of a
+// batch job, releasing the processes waiting for it. Unlike a Barrier, counting
+// down does not wait, and the latch stays open until it is reset.
+//
+// The latch is stored at the key "{<RedisKeyPrefix>:<name>}:latch". Like Mutex, a
+// CountdownLatch is an immutable value safe for concurrent use.
+type CountdownLatch struct {
+	name     string    // Unique identifier for the latch
+	count    int       // Number of countdowns opening the latch
+	registry *Registry // Registry storing the latch, nil for the default one
+}
+
+// NewCountdownLatch creates a distributed countdown latch with the given name,
+// opened once counted down count times.
+//
+// Example:
+//
+//	done, err := sdm.NewCountdownLatch("import:2024-06-01", shards)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	// On each worker, once its shard is imported
+//	_, err = done.CountDown(ctx)
+//
+//	// On the coordinator
+//	err = done.Wait(ctx)
+//
+// Returns ErrMutexNameEmpty if the name is empty, ErrInvalidCount if count is
+// lower than 1.
+func NewCountdownLatch(name string, count int) (CountdownLatch, error) {
+	return defaultRegistry.NewCountdownLatch(name, count)
+}
+
+// Name returns the unique identifier for this latch.
+func (l CountdownLatch) Name() string {
+	return l.name
+}
+
+// CountDown counts the latch down and returns the remaining count, opening the
+// latch when it drops to 0. Counting down an open latch has no effect.
+func (l CountdownLatch) CountDown(ctx context.Context) (int, error) {
+	rdb, key, err := primitiveKey(l.registry, l.name, "latch")
+	if err != nil {
+		return 0, err
+	}
+	remaining, err := countDownScript.Run(ctx, rdb, []string{key}, l.count, releaseChannel(key)).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("sdm: failed to count down latch: %w", err)
+	}
+	return int(remaining), nil
+}
+
+// Count returns the remaining count of the latch, 0 if it is open.
+func (l CountdownLatch) Count(ctx context.Context) (int, error) {
+	rdb, key, err := primitiveKey(l.registry, l.name, "latch")
+	if err != nil {
+		return 0, err
+	}
+	return l.remaining(ctx, rdb, key)
+}
+
+// Wait waits until the latch is open. Waiting processes are woken up by a Redis
+// Pub/Sub notification, or else by polling.
+func (l CountdownLatch) Wait(ctx context.Context) error {
+	rdb, key, err := primitiveKey(l.registry, l.name, "latch")
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before checking the count, so that the opening is not missed
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	released := redisReleases(wctx, rdb, releaseChannel(key))
+
+	return awaitNotified(wctx, released, func(ctx context.Context) (bool, error) {
+		remaining, err := l.remaining(ctx, rdb, key)
+		return remaining == 0, err
+	})
+}
+
+// Reset closes the latch again with its initial count.
+func (l CountdownLatch) Reset(ctx context.Context) error {
+	return resetPrimitive(ctx, l.registry, l.name, "latch")
+}
+
+// remaining returns the remaining count of the latch stored at key
+func (l CountdownLatch) remaining(ctx context.Context, rdb redis.Scripter, key string) (int, error) {
+	done, err := getScript.Run(ctx, rdb, []string{key}).Int64()
+	if errors.Is(err, redis.Nil) {
+		return l.count, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sdm: failed to read latch: %w", err)
+	}
+	return max(l.count-int(done), 0), nil
+}
+
+// awaitNotified waits until done reports true, checking it again whenever a
+// notification is received from released, and at least every maxBackoff in case
+// a notification is missed or released is nil.
+func awaitNotified(ctx context.Context, released <-chan string, done func(context.Context) (bool, error)) error {
+	for {
+		ok, err := done(ctx)
+		if err != nil || ok {
+			return err
+		}
+		if err := waitRelease(ctx, released, nil, maxBackoff); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package sdm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBarrier(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	_, err := NewBarrier(" ", 2)
+	assert.Equal(t, ErrMutexNameEmpty, err)
+	_, err = NewBarrier("test-barrier", 0)
+	assert.Equal(t, ErrInvalidCount, err)
+
+	b, err := NewBarrier("test-barrier", 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, b.Parties())
+
+	t.Run("所有参与方到达后放行", func(t *testing.T) {
+		for round := range 2 {
+			var passed atomic.Int32
+			var wg sync.WaitGroup
+			for range 2 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					assert.NoError(t, b.Await(ctx))
+					passed.Add(1)
+				}()
+			}
+
+			// 前两个参与方等待最后一个
+			assert.Eventually(t, func() bool {
+				n, err := b.Waiting(ctx)
+				return err == nil && n == 2
+			}, time.Second, 10*time.Millisecond, "第 %d 轮", round)
+			assert.Equal(t, int32(0), passed.Load())
+
+			require.NoError(t, b.Await(ctx))
+			wg.Wait()
+			assert.Equal(t, int32(2), passed.Load())
+
+			// 屏障可以重复使用
+			n, err := b.Waiting(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 0, n)
+		}
+	})
+
+	t.Run("取消等待时撤回到达", func(t *testing.T) {
+		cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, b.Await(cctx), context.DeadlineExceeded)
+
+		n, err := b.Waiting(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("单个参与方", func(t *testing.T) {
+		single, err := NewBarrier("test-barrier-single", 1)
+		require.NoError(t, err)
+		assert.NoError(t, single.Await(ctx))
+		require.NoError(t, single.Reset(ctx))
+	})
+
+	require.NoError(t, b.Reset(ctx))
+}
+
+func TestCountdownLatch(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	_, err := NewCountdownLatch("test-latch", -1)
+	assert.Equal(t, ErrInvalidCount, err)
+
+	l, err := NewCountdownLatch("test-latch", 3)
+	require.NoError(t, err)
+	defer l.Reset(ctx)
+
+	count, err := l.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Wait(ctx)
+	}()
+
+	for want := 2; want >= 0; want-- {
+		select {
+		case <-done:
+			t.Fatal("计数归零前不应该放行")
+		case <-time.After(20 * time.Millisecond):
+		}
+		remaining, err := l.CountDown(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, remaining)
+	}
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("计数归零后应该放行")
+	}
+
+	// 打开后再次倒数不受影响，等待立即返回
+	remaining, err := l.CountDown(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, remaining)
+	assert.NoError(t, l.Wait(ctx))
+
+	t.Run("重置", func(t *testing.T) {
+		require.NoError(t, l.Reset(ctx))
+		count, err := l.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, l.Wait(cctx), context.DeadlineExceeded)
+	})
+}
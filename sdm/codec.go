@@ -0,0 +1,100 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the codecs serializing lock values.
+package sdm
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+)
+
+// defaultHashThreshold is the length above which lock values are stored as their
+// hash by default, see WithValueHashing
+const defaultHashThreshold = 1024
+
+// Codec serializes the values passed to TryLock, Lock and Unlock into the lock
+// values stored by the backend. A codec must be deterministic: equal values must
+// always be encoded into the same string, on every node, so that Unlock releases the
+// lease acquired with an equal value and the nodes sharing a value exclude each other.
+//
+// Codecs are selected per Mutex with WithCodec, JSONCodec being the default.
+// Custom codecs, such as one based on MessagePack, can be written as a CodecFunc.
+type Codec interface {
+	// Encode returns the lock value of value.
+	Encode(value any) (string, error)
+}
+
+// CodecFunc is an adapter to use an ordinary function as a Codec.
+//
+// Example:
+//
+//	msgpackCodec := sdm.CodecFunc(func(v any) (string, error) {
+//	    data, err := msgpack.Marshal(v)
+//	    return string(data), err
+//	})
+type CodecFunc func(value any) (string, error)
+
+// Encode calls f(value).
+func (f CodecFunc) Encode(value any) (string, error) {
+	return f(value)
+}
+
+var (
+	// JSONCodec is the default codec: strings are stored verbatim and other values
+	// as their JSON encoding, whose object keys are sorted for maps.
+	JSONCodec Codec = CodecFunc(serializeValue[any])
+
+	// StringCodec stores values in their textual form: strings and byte slices
+	// verbatim, and the text of the values implementing encoding.TextMarshaler or
+	// fmt.Stringer. Other values are rejected with ErrInvalidMutexValue.
+	StringCodec Codec = CodecFunc(encodeString)
+)
+
+// HashCodec returns a codec storing the hex-encoded SHA-256 hash of the values
+// encoded by codec, JSONCodec if nil, so that large values such as structs are not
+// embedded verbatim in Redis and the scripts. Holders of equal values still exclude
+// each other, but the original values cannot be read back from the backend.
+func HashCodec(codec Codec) Codec {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	return CodecFunc(func(value any) (string, error) {
+		encoded, err := codec.Encode(value)
+		if err != nil {
+			return "", err
+		}
+		return hashValue(encoded), nil
+	})
+}
+
+// hashValue returns the hex-encoded SHA-256 hash of a lock value
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeString encodes value in its textual form, see StringCodec
+func encodeString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case *string:
+		if v == nil {
+			return "", fmt.Errorf("%w: nil string pointer", ErrInvalidMutexValue)
+		}
+		return *v, nil
+	case []byte:
+		return string(v), nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("sdm: failed to marshal value: %w", err)
+		}
+		return string(text), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("%w: %T has no textual form", ErrInvalidMutexValue, value)
+	}
+}
@@ -0,0 +1,137 @@
+package sdm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecs(t *testing.T) {
+	type job struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	sum := sha256.Sum256([]byte(`{"id":42,"name":"导出"}`))
+
+	tests := []struct {
+		name     string
+		codec    Codec
+		value    any
+		expected string
+		err      error
+	}{
+		{name: "JSON 字符串", codec: JSONCodec, value: "job-42", expected: "job-42"},
+		{name: "JSON 结构体", codec: JSONCodec, value: job{ID: 42, Name: "导出"}, expected: `{"id":42,"name":"导出"}`},
+		{name: "JSON 映射键有序", codec: JSONCodec, value: map[string]int{"b": 2, "a": 1}, expected: `{"a":1,"b":2}`},
+		{name: "文本字符串", codec: StringCodec, value: "job-42", expected: "job-42"},
+		{name: "文本字节", codec: StringCodec, value: []byte("job-42"), expected: "job-42"},
+		{name: "文本 TextMarshaler", codec: StringCodec, value: netip.MustParseAddr("10.0.0.1"), expected: "10.0.0.1"},
+		{name: "文本 Stringer", codec: StringCodec, value: time.Minute, expected: "1m0s"},
+		{name: "文本不支持的类型", codec: StringCodec, value: 42, err: ErrInvalidMutexValue},
+		{name: "哈希", codec: HashCodec(nil), value: job{ID: 42, Name: "导出"}, expected: hex.EncodeToString(sum[:])},
+		{name: "哈希传递错误", codec: HashCodec(StringCodec), value: 42, err: ErrInvalidMutexValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.codec.Encode(tt.value)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, encoded)
+		})
+	}
+}
+
+func TestMutex_WithCodec(t *testing.T) {
+	ctx := context.Background()
+
+	type job struct {
+		ID      int
+		Payload string
+	}
+	mutex, err := New[job]("test-codec")
+	require.NoError(t, err)
+	mutex = mutex.With(WithBackend(NewMemoryBackend()), WithCodec(HashCodec(JSONCodec)))
+
+	value := job{ID: 1, Payload: string(make([]byte, 4096))}
+	lctx, err := mutex.LockContext(ctx, value)
+	require.NoError(t, err)
+
+	// 相等的值互斥，锁值为哈希
+	acquired, err := mutex.TryLock(ctx, job{ID: 1, Payload: value.Payload})
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	locks := HeldLocks(lctx)
+	require.Len(t, locks, 1)
+	assert.Len(t, locks[0].Value, 64)
+
+	require.NoError(t, mutex.Unlock(ctx, value))
+}
+
+func TestMutex_WithValueHashing(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	backends := map[string]LockBackend{
+		"Redis": defaultBackend,
+		"内存":    NewMemoryBackend(),
+	}
+	long := strings.Repeat("x", 2000)
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			mutex, err := New[string]("test-value-hashing")
+			require.NoError(t, err)
+			mutex = mutex.With(WithBackend(b))
+
+			holder := func(value string) Holder {
+				require.NoError(t, mutex.Lock(ctx, value))
+				defer mutex.Unlock(ctx, value)
+				info, err := mutex.Info(ctx)
+				require.NoError(t, err)
+				require.Len(t, info.Holders, 1)
+				return info.Holders[0]
+			}
+
+			// 默认只哈希超过 1024 字节的值，原始值保存在元数据中
+			h := holder("job")
+			assert.Equal(t, "job", h.Value)
+			assert.Empty(t, h.OriginalValue)
+
+			h = holder(long)
+			assert.Equal(t, hashValue(long), h.Value)
+			assert.Equal(t, long, h.OriginalValue)
+
+			// 相等的值仍然互斥
+			require.NoError(t, mutex.Lock(ctx, long))
+			acquired, err := mutex.TryLock(ctx, strings.Clone(long))
+			require.NoError(t, err)
+			assert.False(t, acquired)
+			require.NoError(t, mutex.Unlock(ctx, long))
+
+			mutex = mutex.With(WithValueHashing(2))
+			h = holder("job")
+			assert.Equal(t, hashValue("job"), h.Value)
+			assert.Equal(t, "job", h.OriginalValue)
+
+			mutex = mutex.With(WithValueHashing(0))
+			h = holder(long)
+			assert.Equal(t, long, h.Value)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the Counter, Gauge and Flag coordination primitives.
+package sdm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Counter is a distributed integer counter stored in Redis, such as the number of
+// jobs processed by a cluster or a sequence of IDs. An absent counter has the value
+// 0. Like Mutex, a Counter is an immutable value safe for concurrent use.
+//
+// The counter is stored at the key "{<RedisKeyPrefix>:<name>}:counter", so that it
+// does not collide with a Mutex of the same name.
+type Counter struct {
+	name     string    // Unique identifier for the counter
+	registry *Registry // Registry storing the counter, nil for the default one
+}
+
+// NewCounter creates a distributed counter with the given name.
+//
+// Example:
+//
+//	jobs, err := sdm.NewCounter("jobs:processed")
+//	if err != nil {
+//	    return err
+//	}
+//	n, err := jobs.Incr(ctx)
+//
+// Returns an error if the name is empty.
+func NewCounter(name string) (Counter, error) {
+	return defaultRegistry.NewCounter(name)
+}
+
+// Name returns the unique identifier for this counter.
+func (c Counter) Name() string {
+	return c.name
+}
+
+// Incr adds delta to the counter, 1 if omitted, and returns the new value.
+func (c Counter) Incr(ctx context.Context, delta ...int64) (int64, error) {
+	n := int64(1)
+	if len(delta) > 0 {
+		n = delta[0]
+	}
+	return c.add(ctx, n)
+}
+
+// Decr subtracts delta from the counter, 1 if omitted, and returns the new value.
+func (c Counter) Decr(ctx context.Context, delta ...int64) (int64, error) {
+	n := int64(1)
+	if len(delta) > 0 {
+		n = delta[0]
+	}
+	return c.add(ctx, -n)
+}
+
+func (c Counter) add(ctx context.Context, n int64) (int64, error) {
+	rdb, key, err := primitiveKey(c.registry, c.name, "counter")
+	if err != nil {
+		return 0, err
+	}
+	value, err := incrScript.Run(ctx, rdb, []string{key}, n).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("sdm: failed to update counter: %w", err)
+	}
+	return value, nil
+}
+
+// Get returns the value of the counter.
+func (c Counter) Get(ctx context.Context) (int64, error) {
+	rdb, key, err := primitiveKey(c.registry, c.name, "counter")
+	if err != nil {
+		return 0, err
+	}
+	value, err := getScript.Run(ctx, rdb, []string{key}).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sdm: failed to read counter: %w", err)
+	}
+	return value, nil
+}
+
+// Reset deletes the counter, whose value becomes 0.
+func (c Counter) Reset(ctx context.Context) error {
+	return resetPrimitive(ctx, c.registry, c.name, "counter")
+}
+
+// CompareAndSet sets the counter to new if its value is old, and reports whether
+// it was set.
+func (c Counter) CompareAndSet(ctx context.Context, old, new int64) (bool, error) {
+	return compareAndSet(ctx, c.registry, c.name, "counter", strconv.FormatInt(old, 10), strconv.FormatInt(new, 10), false)
+}
+
+// Gauge is a distributed floating point value stored in Redis, such as the backlog
+// of a queue reported by one node and read by the others. An absent gauge has the
+// value 0. Like Mutex, a Gauge is an immutable value safe for concurrent use.
+//
+// The gauge is stored at the key "{<RedisKeyPrefix>:<name>}:gauge".
+type Gauge struct {
+	name     string    // Unique identifier for the gauge
+	registry *Registry // Registry storing the gauge, nil for the default one
+}
+
+// NewGauge creates a distributed gauge with the given name.
+//
+// Example:
+//
+//	backlog, err := sdm.NewGauge("queue:backlog")
+//	if err != nil {
+//	    return err
+//	}
+//	err = backlog.Set(ctx, float64(len(pending)), time.Minute)
+//
+// Returns an error if the name is empty.
+func NewGauge(name string) (Gauge, error) {
+	return defaultRegistry.NewGauge(name)
+}
+
+// Name returns the unique identifier for this gauge.
+func (g Gauge) Name() string {
+	return g.name
+}
+
+// Set sets the value of the gauge. If a TTL is given, the gauge is reset once it
+// elapses without the value being set again, so that a value reported by a node
+// that stopped does not linger.
+func (g Gauge) Set(ctx context.Context, value float64, ttl ...time.Duration) error {
+	_, err := setPrimitive(ctx, g.registry, g.name, "gauge", strconv.FormatFloat(value, 'g', -1, 64), ttl...)
+	return err
+}
+
+// Add adds delta to the gauge, negative to subtract, and returns the new value.
+func (g Gauge) Add(ctx context.Context, delta float64) (float64, error) {
+	rdb, key, err := primitiveKey(g.registry, g.name, "gauge")
+	if err != nil {
+		return 0, err
+	}
+	value, err := incrFloatScript.Run(ctx, rdb, []string{key}, strconv.FormatFloat(delta, 'g', -1, 64)).Float64()
+	if err != nil {
+		return 0, fmt.Errorf("sdm: failed to update gauge: %w", err)
+	}
+	return value, nil
+}
+
+// Get returns the value of the gauge.
+func (g Gauge) Get(ctx context.Context) (float64, error) {
+	rdb, key, err := primitiveKey(g.registry, g.name, "gauge")
+	if err != nil {
+		return 0, err
+	}
+	value, err := getScript.Run(ctx, rdb, []string{key}).Float64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sdm: failed to read gauge: %w", err)
+	}
+	return value, nil
+}
+
+// Reset deletes the gauge, whose value becomes 0.
+func (g Gauge) Reset(ctx context.Context) error {
+	return resetPrimitive(ctx, g.registry, g.name, "gauge")
+}
+
+// CompareAndSet sets the gauge to new if its value is old, and reports whether it
+// was set. The TTL of the gauge, if any, is kept.
+func (g Gauge) CompareAndSet(ctx context.Context, old, new float64) (bool, error) {
+	return compareAndSet(ctx, g.registry, g.name, "gauge", strconv.FormatFloat(old, 'g', -1, 64), strconv.FormatFloat(new, 'g', -1, 64), true)
+}
+
+// Flag is a distributed boolean stored in Redis, such as a maintenance mode or a
+// kill switch shared by a cluster. A flag is set while its key exists. Like Mutex,
+// a Flag is an immutable value safe for concurrent use.
+//
+// The flag is stored at the key "{<RedisKeyPrefix>:<name>}:flag".
+type Flag struct {
+	name     string    // Unique identifier for the flag
+	registry *Registry // Registry storing the flag, nil for the default one
+}
+
+// NewFlag creates a distributed flag with the given name.
+//
+// Example:
+//
+//	maintenance, err := sdm.NewFlag("maintenance")
+//	if err != nil {
+//	    return err
+//	}
+//	if on, _ := maintenance.IsSet(ctx); on {
+//	    return errMaintenance
+//	}
+//
+// Returns an error if the name is empty.
+func NewFlag(name string) (Flag, error) {
+	return defaultRegistry.NewFlag(name)
+}
+
+// Name returns the unique identifier for this flag.
+func (f Flag) Name() string {
+	return f.name
+}
+
+// Set sets the flag and reports whether it was cleared before. If a TTL is given,
+// the flag is cleared once it elapses, replacing any previous TTL; otherwise the
+// flag stays set until Clear is called.
+func (f Flag) Set(ctx context.Context, ttl ...time.Duration) (bool, error) {
+	return setPrimitive(ctx, f.registry, f.name, "flag", "1", ttl...)
+}
+
+// Clear clears the flag and reports whether it was set before.
+func (f Flag) Clear(ctx context.Context) (bool, error) {
+	rdb, key, err := primitiveKey(f.registry, f.name, "flag")
+	if err != nil {
+		return false, err
+	}
+	deleted, err := deleteScript.Run(ctx, rdb, []string{key}).Int64()
+	if err != nil {
+		return false, fmt.Errorf("sdm: failed to clear flag: %w", err)
+	}
+	return deleted == 1, nil
+}
+
+// IsSet reports whether the flag is set.
+func (f Flag) IsSet(ctx context.Context) (bool, error) {
+	rdb, key, err := primitiveKey(f.registry, f.name, "flag")
+	if err != nil {
+		return false, err
+	}
+	err = getScript.Run(ctx, rdb, []string{key}).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("sdm: failed to read flag: %w", err)
+	}
+	return true, nil
+}
+
+// primitiveKey returns the Redis client and the key of the primitive of the given
+// kind named name in the registry r, the default one if nil
+func primitiveKey(r *Registry, name, kind string) (redis.Scripter, string, error) {
+	r = registryOrDefault(r)
+	rdb, err := r.client()
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := r.LockKey(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return rdb, companionKey(key, kind), nil
+}
+
+// setPrimitive sets the primitive of the given kind named name in r to value, expiring
+// after the optional ttl, and reports whether it was absent before
+func setPrimitive(ctx context.Context, r *Registry, name, kind, value string, ttl ...time.Duration) (bool, error) {
+	rdb, key, err := primitiveKey(r, name, kind)
+	if err != nil {
+		return false, err
+	}
+	var ms int64
+	if len(ttl) > 0 && ttl[0] > 0 {
+		ms = max(ttl[0].Milliseconds(), 1)
+	}
+	created, err := setScript.Run(ctx, rdb, []string{key}, value, ms).Int64()
+	if err != nil {
+		return false, fmt.Errorf("sdm: failed to set %s: %w", kind, err)
+	}
+	return created == 1, nil
+}
+
+// resetPrimitive deletes the primitive of the given kind named name in r
+func resetPrimitive(ctx context.Context, r *Registry, name, kind string) error {
+	rdb, key, err := primitiveKey(r, name, kind)
+	if err != nil {
+		return err
+	}
+	if err := deleteScript.Run(ctx, rdb, []string{key}).Err(); err != nil {
+		return fmt.Errorf("sdm: failed to reset %s: %w", kind, err)
+	}
+	return nil
+}
+
+// compareAndSet sets the primitive of the given kind named name in r to new if its value
+// is old, comparing the values as numbers if numeric is set
+func compareAndSet(ctx context.Context, r *Registry, name, kind, old, new string, numeric bool) (bool, error) {
+	rdb, key, err := primitiveKey(r, name, kind)
+	if err != nil {
+		return false, err
+	}
+	mode := "0"
+	if numeric {
+		mode = "1"
+	}
+	set, err := compareAndSetScript.Run(ctx, rdb, []string{key}, old, new, mode).Int64()
+	if err != nil {
+		return false, fmt.Errorf("sdm: failed to compare and set %s: %w", kind, err)
+	}
+	return set == 1, nil
+}
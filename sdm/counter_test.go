@@ -0,0 +1,230 @@
+package sdm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounter(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	_, err := NewCounter("  ")
+	assert.Equal(t, ErrMutexNameEmpty, err)
+
+	c, err := NewCounter("test-counter")
+	require.NoError(t, err)
+	assert.Equal(t, "test-counter", c.Name())
+
+	t.Run("增减", func(t *testing.T) {
+		v, err := c.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), v)
+
+		v, err = c.Incr(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), v)
+		v, err = c.Incr(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), v)
+		v, err = c.Decr(ctx, 5)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), v)
+		v, err = c.Decr(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), v)
+
+		require.NoError(t, c.Reset(ctx))
+		v, err = c.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), v)
+	})
+
+	t.Run("并发递增", func(t *testing.T) {
+		defer c.Reset(ctx)
+
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.Incr(ctx)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		v, err := c.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), v)
+	})
+
+	t.Run("比较并设置", func(t *testing.T) {
+		defer c.Reset(ctx)
+
+		// 不存在的计数器视为 0
+		set, err := c.CompareAndSet(ctx, 0, 7)
+		require.NoError(t, err)
+		assert.True(t, set)
+
+		set, err = c.CompareAndSet(ctx, 0, 8)
+		require.NoError(t, err)
+		assert.False(t, set)
+
+		set, err = c.CompareAndSet(ctx, 7, 1<<62)
+		require.NoError(t, err)
+		assert.True(t, set)
+		v, err := c.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1<<62), v)
+
+		// 超出浮点数精度的值按字符串精确比较
+		set, err = c.CompareAndSet(ctx, 1<<62+1, 0)
+		require.NoError(t, err)
+		assert.False(t, set)
+	})
+
+	t.Run("不与同名互斥锁冲突", func(t *testing.T) {
+		defer c.Reset(ctx)
+
+		m, err := New[string]("test-counter")
+		require.NoError(t, err)
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		defer m.Unlock(ctx, "owner-1")
+
+		_, err = c.Incr(ctx)
+		assert.NoError(t, err)
+	})
+}
+
+func TestGauge(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	g, err := NewGauge("test-gauge")
+	require.NoError(t, err)
+
+	v, err := g.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, v)
+
+	require.NoError(t, g.Set(ctx, 1.5))
+	v, err = g.Add(ctx, 2.25)
+	require.NoError(t, err)
+	assert.Equal(t, 3.75, v)
+	v, err = g.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3.75, v)
+
+	set, err := g.CompareAndSet(ctx, 3.75, -1)
+	require.NoError(t, err)
+	assert.True(t, set)
+	set, err = g.CompareAndSet(ctx, 3.75, 0)
+	require.NoError(t, err)
+	assert.False(t, set)
+	v, err = g.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, -1.0, v)
+
+	t.Run("过期", func(t *testing.T) {
+		require.NoError(t, g.Set(ctx, 42, time.Minute))
+		key, err := LockKey("test-gauge")
+		require.NoError(t, err)
+		ttl, err := client.PTTL(ctx, companionKey(key, "gauge")).Result()
+		require.NoError(t, err)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+		// 比较并设置保留过期时间
+		set, err := g.CompareAndSet(ctx, 42, 43)
+		require.NoError(t, err)
+		assert.True(t, set)
+		ttl, err = client.PTTL(ctx, companionKey(key, "gauge")).Result()
+		require.NoError(t, err)
+		assert.Greater(t, ttl, time.Duration(0))
+	})
+
+	require.NoError(t, g.Reset(ctx))
+}
+
+func TestFlag(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	f, err := NewFlag("test-flag")
+	require.NoError(t, err)
+
+	on, err := f.IsSet(ctx)
+	require.NoError(t, err)
+	assert.False(t, on)
+
+	changed, err := f.Set(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	changed, err = f.Set(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	on, err = f.IsSet(ctx)
+	require.NoError(t, err)
+	assert.True(t, on)
+
+	changed, err = f.Clear(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	changed, err = f.Clear(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	t.Run("过期", func(t *testing.T) {
+		defer f.Clear(ctx)
+
+		key, err := LockKey("test-flag")
+		require.NoError(t, err)
+		_, err = f.Set(ctx, time.Minute)
+		require.NoError(t, err)
+		ttl, err := client.PTTL(ctx, companionKey(key, "flag")).Result()
+		require.NoError(t, err)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+		// 不带 TTL 再次设置后不再过期
+		_, err = f.Set(ctx)
+		require.NoError(t, err)
+		ttl, err = client.PTTL(ctx, companionKey(key, "flag")).Result()
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(-1), ttl)
+	})
+
+	t.Run("未初始化 Redis", func(t *testing.T) {
+		original := rdb.Load()
+		defer rdb.Store(original)
+		rdb.Store(nil)
+
+		_, err := f.IsSet(ctx)
+		assert.Equal(t, ErrRedisNotInitialized, err)
+	})
+}
@@ -0,0 +1,230 @@
+package sdm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutex_Fairness(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	backends := map[string]LockBackend{
+		"Redis": defaultBackend,
+		"内存":    NewMemoryBackend(),
+	}
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			mutex, err := New[string]("test-fair")
+			require.NoError(t, err)
+			mutex = mutex.With(WithBackend(b), WithFairness())
+
+			t.Run("按等待顺序获取锁", func(t *testing.T) {
+				require.NoError(t, mutex.Lock(ctx, "job"))
+
+				var mu sync.Mutex
+				var order []int
+				var wg sync.WaitGroup
+				for i := range 3 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if !assert.NoError(t, mutex.Lock(ctx, "job")) {
+							return
+						}
+						mu.Lock()
+						order = append(order, i)
+						mu.Unlock()
+						time.Sleep(10 * time.Millisecond)
+						assert.NoError(t, mutex.Unlock(ctx, "job"))
+					}()
+					// 确保等待者依次入队
+					time.Sleep(50 * time.Millisecond)
+				}
+
+				require.NoError(t, mutex.Unlock(ctx, "job"))
+				wg.Wait()
+				assert.Equal(t, []int{0, 1, 2}, order)
+			})
+
+			t.Run("非阻塞获取不插队", func(t *testing.T) {
+				require.NoError(t, mutex.Lock(ctx, "job"))
+
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					assert.NoError(t, mutex.Lock(ctx, "job"))
+				}()
+				time.Sleep(50 * time.Millisecond)
+
+				require.NoError(t, mutex.Unlock(ctx, "job"))
+				acquired, err := mutex.TryLock(ctx, "job")
+				require.NoError(t, err)
+				assert.False(t, acquired)
+
+				<-done
+				require.NoError(t, mutex.Unlock(ctx, "job"))
+			})
+
+			t.Run("放弃等待后离开队列", func(t *testing.T) {
+				require.NoError(t, mutex.Lock(ctx, "job"))
+
+				acquired, err := mutex.TryLock(ctx, "job", 50*time.Millisecond)
+				require.NoError(t, err)
+				assert.False(t, acquired)
+
+				require.NoError(t, mutex.Unlock(ctx, "job"))
+				acquired, err = mutex.TryLock(ctx, "job")
+				require.NoError(t, err)
+				assert.True(t, acquired)
+				require.NoError(t, mutex.Unlock(ctx, "job"))
+			})
+
+			t.Run("按优先级获取锁", func(t *testing.T) {
+				require.NoError(t, mutex.Lock(ctx, "job"))
+
+				var mu sync.Mutex
+				var order []int32
+				var wg sync.WaitGroup
+				for _, priority := range []int32{-1, 0, 5, 0} {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						m := mutex.With(WithPriority(priority))
+						if !assert.NoError(t, m.Lock(ctx, "job")) {
+							return
+						}
+						mu.Lock()
+						order = append(order, priority)
+						mu.Unlock()
+						time.Sleep(10 * time.Millisecond)
+						assert.NoError(t, m.Unlock(ctx, "job"))
+					}()
+					time.Sleep(50 * time.Millisecond)
+				}
+
+				require.NoError(t, mutex.Unlock(ctx, "job"))
+				wg.Wait()
+				assert.Equal(t, []int32{5, 0, 0, -1}, order)
+			})
+
+			t.Run("不同的值互不排队", func(t *testing.T) {
+				require.NoError(t, mutex.Lock(ctx, "job"))
+				defer mutex.Unlock(ctx, "job")
+
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					mutex.TryLock(ctx, "job", 100*time.Millisecond)
+				}()
+				time.Sleep(20 * time.Millisecond)
+
+				acquired, err := mutex.TryLock(ctx, "other")
+				require.NoError(t, err)
+				assert.True(t, acquired)
+				require.NoError(t, mutex.Unlock(ctx, "other"))
+				<-done
+			})
+
+			t.Run("重入不排队", func(t *testing.T) {
+				m := mutex.With(WithReentrant("owner-a"))
+				require.NoError(t, m.Lock(ctx, "job"))
+
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					mutex.TryLock(ctx, "job", 100*time.Millisecond)
+				}()
+				time.Sleep(20 * time.Millisecond)
+
+				acquired, err := m.TryLock(ctx, "job")
+				require.NoError(t, err)
+				assert.True(t, acquired)
+				require.NoError(t, m.Unlock(ctx, "job"))
+				require.NoError(t, m.Unlock(ctx, "job"))
+				<-done
+			})
+		})
+	}
+}
+
+func TestFairBackend(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	backends := map[string]FairBackend{
+		"Redis": NewRedisBackend(client),
+		"内存":    NewMemoryBackend(),
+	}
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			lease := Lease{Key: "mutex:test-fair-backend", Value: "job"}
+			acquire := func(ticket string, priority int32, wait time.Duration) LeaseOutcome {
+				outcome, err := b.AcquireFair(ctx, lease, ticket, priority, wait)
+				require.NoError(t, err)
+				return outcome
+			}
+			release := func() {
+				_, err := b.(LockBackend).Release(ctx, lease)
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, LeaseChanged, acquire("holder", 0, time.Second))
+
+			// 依次入队
+			assert.Equal(t, LeaseUnchanged, acquire("first", 0, time.Second))
+			assert.Equal(t, LeaseUnchanged, acquire("second", 0, time.Second))
+			release()
+
+			// 只有队首可以获取
+			assert.Equal(t, LeaseUnchanged, acquire("second", 0, time.Second))
+			assert.Equal(t, LeaseUnchanged, acquire("late", 0, 0))
+
+			// 队首离开后轮到下一个
+			require.NoError(t, b.Dequeue(ctx, lease, "first"))
+			assert.Equal(t, LeaseChanged, acquire("second", 0, time.Second))
+
+			// 按优先级排队，相同优先级先来先得
+			waiters := map[string]int32{"low": -1, "normal": 0, "high": 1, "normal-2": 0}
+			for _, ticket := range []string{"low", "normal", "high", "normal-2"} {
+				assert.Equal(t, LeaseUnchanged, acquire(ticket, waiters[ticket], time.Second))
+			}
+			release()
+
+			// 更高优先级的调用方不排队也可以越过等待者
+			assert.Equal(t, LeaseUnchanged, acquire("urgent", 1, 0))
+			assert.Equal(t, LeaseChanged, acquire("urgent", 2, 0))
+			release()
+
+			for _, next := range []string{"high", "normal", "normal-2", "low"} {
+				for _, ticket := range []string{"low", "normal-2", "normal", "high"} {
+					if _, queued := waiters[ticket]; queued && ticket != next {
+						assert.Equal(t, LeaseUnchanged, acquire(ticket, waiters[ticket], time.Second), ticket)
+					}
+				}
+				assert.Equal(t, LeaseChanged, acquire(next, waiters[next], time.Second), next)
+				delete(waiters, next)
+				release()
+			}
+		})
+	}
+}
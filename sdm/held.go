@@ -0,0 +1,252 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file tracks the leases held by this process and detects their loss.
+package sdm
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLeaseLost is the cause of the cancellation of the contexts returned by
+// Mutex.LockContext and Mutex.TryLockContext when the lease is lost while held,
+// because its TTL elapsed without renewal or it was broken with ForceUnlock.
+var ErrLeaseLost = errors.New("sdm: lease lost")
+
+// heldLeases tracks the leases acquired by this process until they are released
+// or found lost, so that the loss of a held lease can be told apart from the
+// release of a lease that was never held.
+var heldLeases sync.Map // map[leaseID]*heldLease
+
+// heldLease describes a lease held by this process
+type heldLease struct {
+	name     string    // Name of the mutex
+	metric   string    // Label of the mutex in the metrics
+	hooks    []Hooks   // Hooks of the mutex, see WithHooks
+	lease    Lease     // The lease, as acquired by the outermost acquisition
+	acquired time.Time // When the outermost acquisition created the lease
+	stack    []byte    // Stack trace of the outermost acquisition, captured with WithMaxHold
+
+	// releasing is set while Unlock releases the lease, so that its own release
+	// notification is not taken for a loss
+	releasing atomic.Bool
+
+	mu      sync.Mutex
+	timer   *time.Timer               // Fires once the TTL elapses without renewal, nil without TTL
+	guard   *time.Timer               // Fires once the lease is held longer than maxHold, nil without it
+	cancels []context.CancelCauseFunc // Cancel the contexts returned by LockContext
+	ended   bool                      // Whether the lease has been released or lost
+}
+
+// holdLease records the lease of mutex name, labelled metric in the metrics and
+// requested at start, as held by this process. A lease with a TTL is presumed lost once the TTL elapses from start
+// without being renewed (see extend). A lease still tracked under the same ID has
+// been lost without notice, since its value acquired the lock again. A lease still
+// held after a positive maxHold is reported as a long hold.
+func holdLease(name, metric string, hooks []Hooks, lease Lease, start time.Time, maxHold time.Duration) {
+	id := leaseID{key: lease.Key, value: lease.Value}
+	h := &heldLease{name: name, metric: metric, hooks: hooks, lease: lease, acquired: time.Now()}
+	if maxHold > 0 {
+		h.stack = debug.Stack()
+		h.guard = time.AfterFunc(maxHold, h.longHold)
+	}
+	if lease.TTL > 0 {
+		h.timer = time.AfterFunc(time.Until(start.Add(lease.TTL)), h.expire)
+	}
+	if old, loaded := heldLeases.Swap(id, h); loaded {
+		old.(*heldLease).lose()
+	}
+}
+
+// lookupLease returns the lease id if it is held, nil otherwise
+func lookupLease(id leaseID) *heldLease {
+	h, ok := heldLeases.Load(id)
+	if !ok {
+		return nil
+	}
+	return h.(*heldLease)
+}
+
+// dropLease stops tracking the lease id, returning nil if it was not held
+func dropLease(id leaseID) *heldLease {
+	h, ok := heldLeases.LoadAndDelete(id)
+	if !ok {
+		return nil
+	}
+	return h.(*heldLease)
+}
+
+// extend records the renewal of the lease by ttl from start, reporting false if
+// the lease has already been released or lost. A lease acquired without TTL starts
+// expiring once renewed with a positive ttl (see Mutex.Extend).
+func (h *heldLease) extend(start time.Time, ttl time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch {
+	case h.ended:
+		return false
+	case h.timer != nil:
+		h.timer.Reset(time.Until(start.Add(ttl)))
+	case ttl > 0:
+		h.timer = time.AfterFunc(time.Until(start.Add(ttl)), h.expire)
+	}
+	return true
+}
+
+// expire reports the lease lost once its TTL elapsed without renewal, unless it
+// has stopped being tracked meanwhile
+func (h *heldLease) expire() {
+	if heldLeases.CompareAndDelete(leaseID{key: h.lease.Key, value: h.lease.Value}, h) {
+		h.lose()
+	}
+}
+
+// heldFor returns how long the lease has been held
+func (h *heldLease) heldFor() time.Duration {
+	return time.Since(h.acquired)
+}
+
+// end stops tracking the expiration of the lease and cancels its contexts with cause
+func (h *heldLease) end(cause error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.ended {
+		return
+	}
+	h.ended = true
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+	if h.guard != nil {
+		h.guard.Stop()
+	}
+	for _, cancel := range h.cancels {
+		cancel(cause)
+	}
+	h.cancels = nil
+}
+
+// release ends the lease released by Unlock
+func (h *heldLease) release() {
+	h.end(context.Canceled)
+}
+
+// lose ends the lease found lost, which the caller has stopped tracking, and
+// reports the loss. The watchdog stops renewing the lease, leaving the lock to
+// expire if the lease is in fact still valid.
+func (h *heldLease) lose() {
+	h.end(ErrLeaseLost)
+	haltWatchdog(h.lease.Key, h.lease.Value)
+	observeLost(h.metric)
+	emit(eventLost, h.name, h.hooks, h.lease, LockEvent{Held: h.heldFor()})
+}
+
+// longHold reports the lease held longer than its maximum hold duration, unless it
+// ended meanwhile
+func (h *heldLease) longHold() {
+	h.mu.Lock()
+	ended := h.ended
+	h.mu.Unlock()
+	if ended {
+		return
+	}
+	observeLongHold(h.metric)
+	emit(eventLongHold, h.name, h.hooks, h.lease, LockEvent{Held: h.heldFor(), Stack: h.stack})
+}
+
+// context returns a context derived from ctx canceled once the lease is released
+// or lost. While the context is not done, the releases of the lease notified by b
+// (see ReleaseWatcher) other than by Unlock are taken for a loss.
+func (h *heldLease) context(ctx context.Context, b LockBackend) context.Context {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	h.mu.Lock()
+	if h.ended {
+		h.mu.Unlock()
+		cancel(ErrLeaseLost)
+		return ctx
+	}
+	h.cancels = append(h.cancels, cancel)
+	h.mu.Unlock()
+
+	released := watchReleases(ctx, b, h.lease.Key)
+	if released == nil {
+		return ctx
+	}
+	id := leaseID{key: h.lease.Key, value: h.lease.Value}
+	go func() {
+		for value := range released {
+			if value == h.lease.Value && !h.releasing.Load() && heldLeases.CompareAndDelete(id, h) {
+				h.lose()
+				return
+			}
+		}
+	}()
+	return ctx
+}
+
+// LockContext acquires the lock like Lock and returns a context derived from ctx
+// that records the lock as held by the call chain (see ContextWithLock) and is
+// canceled once the lease ends, so that work done under the lock can stop as soon
+// as the lock is no longer held:
+//
+//   - When the lease is lost, because its TTL elapsed without renewal (see WithTTL
+//     and WithWatchdog) or it was broken with ForceUnlock, the context is canceled
+//     with ErrLeaseLost as its cause (see context.Cause).
+//   - When Unlock releases the lease, the context is canceled with context.Canceled.
+//
+// The expiration of the TTL is measured from the time the last acquisition or
+// renewal was requested, so the context may be canceled slightly before the lease
+// actually expires, never after. A lease broken with ForceUnlock is noticed as soon
+// as its release is notified (see ReleaseWatcher), or otherwise by the next renewal
+// of the watchdog or by Unlock.
+//
+// Example:
+//
+//	ctx, err := m.LockContext(ctx, "job-42")
+//	if err != nil {
+//	    return err
+//	}
+//	defer m.Unlock(context.WithoutCancel(ctx), "job-42")
+//	if err := process(ctx); errors.Is(context.Cause(ctx), sdm.ErrLeaseLost) {
+//	    return fmt.Errorf("lock lost while processing: %w", err)
+//	}
+func (m Mutex[T]) LockContext(ctx context.Context, value T) (context.Context, error) {
+	if err := m.Lock(ctx, value); err != nil {
+		return nil, err
+	}
+	return m.leaseContext(ctx, value), nil
+}
+
+// TryLockContext attempts to acquire the lock like TryLock and, if acquired, returns
+// a context derived from ctx that is canceled once the lease ends, like LockContext.
+// It returns a nil context if the lock was not acquired.
+func (m Mutex[T]) TryLockContext(ctx context.Context, value T, timeout ...time.Duration) (context.Context, bool, error) {
+	acquired, err := m.TryLock(ctx, value, timeout...)
+	if err != nil || !acquired {
+		return nil, acquired, err
+	}
+	return m.leaseContext(ctx, value), true, nil
+}
+
+// leaseContext returns the context of the lease of value acquired through the mutex
+func (m Mutex[T]) leaseContext(ctx context.Context, value T) context.Context {
+	lease, err := m.lease(value)
+	if err != nil {
+		// Not reached, the value was serialized by the acquisition
+		ctx, cancel := context.WithCancelCause(ctx)
+		cancel(err)
+		return ctx
+	}
+	h := lookupLease(leaseID{key: lease.Key, value: lease.Value})
+	if h == nil {
+		// The lease was lost right after its acquisition
+		ctx, cancel := context.WithCancelCause(ctx)
+		cancel(ErrLeaseLost)
+		return ctx
+	}
+	return withHeldLock(h.context(ctx, m.backend()), HeldLock{Mutex: m.name, Key: lease.Key, Value: lease.Value, Owner: lease.Owner})
+}
@@ -0,0 +1,132 @@
+package sdm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertLost 断言 ctx 在 timeout 内以 ErrLeaseLost 取消
+func assertLost(t *testing.T, ctx context.Context, timeout time.Duration) {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+		assert.Equal(t, ErrLeaseLost, context.Cause(ctx))
+	case <-time.After(timeout):
+		t.Fatal("租约丢失后上下文应该被取消")
+	}
+}
+
+func TestMutex_LockContext(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	backends := map[string]LockBackend{
+		"Redis": defaultBackend,
+		"内存":    NewMemoryBackend(),
+	}
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			mutex, err := New[string]("test-lock-context")
+			require.NoError(t, err)
+			mutex = mutex.With(WithBackend(b))
+
+			t.Run("释放后取消", func(t *testing.T) {
+				lctx, err := mutex.LockContext(ctx, "owner-1")
+				require.NoError(t, err)
+				assert.NoError(t, lctx.Err())
+
+				require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+				<-lctx.Done()
+				assert.Equal(t, context.Canceled, context.Cause(lctx))
+			})
+
+			t.Run("租约过期", func(t *testing.T) {
+				m := mutex.With(WithTTL(100 * time.Millisecond))
+				lctx, err := m.LockContext(ctx, "owner-1")
+				require.NoError(t, err)
+				assertLost(t, lctx, time.Second)
+				assert.Equal(t, ErrMutexNotAcquired, m.Unlock(ctx, "owner-1"))
+			})
+
+			t.Run("看门狗续期", func(t *testing.T) {
+				m := mutex.With(WithTTL(100*time.Millisecond), WithWatchdog(20*time.Millisecond))
+				lctx, err := m.LockContext(ctx, "owner-1")
+				require.NoError(t, err)
+
+				time.Sleep(300 * time.Millisecond)
+				assert.NoError(t, lctx.Err())
+				require.NoError(t, m.Unlock(ctx, "owner-1"))
+				assert.Equal(t, context.Canceled, context.Cause(lctx))
+			})
+
+			t.Run("强制释放", func(t *testing.T) {
+				lctx, err := mutex.LockContext(ctx, "owner-1")
+				require.NoError(t, err)
+
+				info, err := mutex.Info(ctx)
+				require.NoError(t, err)
+				require.Len(t, info.Holders, 1)
+				require.NoError(t, ForceUnlock(ctx, "test-lock-context", info.Holders[0].Token, b))
+
+				// 通过释放通知立即发现，无需等待看门狗或 Unlock
+				assertLost(t, lctx, maxBackoff/2)
+				assert.Equal(t, ErrMutexNotAcquired, mutex.Unlock(ctx, "owner-1"))
+			})
+
+			t.Run("重入", func(t *testing.T) {
+				m := mutex.With(WithReentrant("owner-a"))
+				outer, err := m.LockContext(ctx, "job")
+				require.NoError(t, err)
+				inner, err := m.LockContext(ctx, "job")
+				require.NoError(t, err)
+
+				require.NoError(t, m.Unlock(ctx, "job"))
+				assert.NoError(t, outer.Err())
+				assert.NoError(t, inner.Err())
+
+				require.NoError(t, m.Unlock(ctx, "job"))
+				assert.Equal(t, context.Canceled, context.Cause(outer))
+				assert.Equal(t, context.Canceled, context.Cause(inner))
+			})
+		})
+	}
+
+	t.Run("未获取到锁", func(t *testing.T) {
+		m, err := New[string]("test-lock-context")
+		require.NoError(t, err)
+		m = m.With(WithBackend(NewMemoryBackend()))
+
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		lctx, acquired, err := m.TryLockContext(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+		assert.Nil(t, lctx)
+	})
+
+	t.Run("父上下文取消", func(t *testing.T) {
+		m, err := New[string]("test-lock-context")
+		require.NoError(t, err)
+		m = m.With(WithBackend(NewMemoryBackend()))
+
+		parent, cancel := context.WithCancel(ctx)
+		lctx, acquired, err := m.TryLockContext(parent, "owner-1")
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		cancel()
+		<-lctx.Done()
+		assert.Equal(t, context.Canceled, context.Cause(lctx))
+		require.NoError(t, m.Unlock(ctx, "owner-1"))
+	})
+}
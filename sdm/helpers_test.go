@@ -2,32 +2,47 @@ package sdm
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 )
 
-// setupTestRedis 创建测试用的 Redis 客户端
-// 注意：这些测试需要一个运行中的 Redis 实例
-func setupTestRedis(t testing.TB) *redis.Client {
+// testRedisAddr 默认 Redis 地址
+const testRedisAddr = "localhost:6379"
+
+// redisAvailable 检测是否有运行中的 Redis 实例，只检测一次，避免每个测试都等待重试
+var redisAvailable = sync.OnceValue(func() bool {
 	client := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379", // 默认 Redis 地址
-		DB:   1,                // 使用专用的测试数据库
+		Addr:       testRedisAddr,
+		MaxRetries: -1, // 不重试
 	})
+	defer client.Close()
 
-	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	return client.Ping(ctx).Err() == nil
+})
 
-	err := client.Ping(ctx).Err()
-	if err != nil {
-		// 使用 t.Skip 或 b.Skip 来跳过测试
-		t.Skip("Redis 不可用，跳过测试")
-		return nil
+// setupTestRedis 创建测试用的 Redis 客户端
+// 优先使用运行中的 Redis 实例，不可用时回退到内嵌的 miniredis，因此 CI 无需 Redis 容器
+func setupTestRedis(t testing.TB) *redis.Client {
+	addr := testRedisAddr
+	if !redisAvailable() {
+		// 回退到内嵌的 miniredis，测试结束时自动关闭
+		addr = miniredis.RunT(t).Addr()
 	}
 
+	client := redis.NewClient(&redis.Options{
+		Addr: addr,
+		DB:   1, // 使用专用的测试数据库
+	})
+
 	// 清理测试数据
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	client.FlushDB(ctx)
 
 	return client
@@ -0,0 +1,126 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the hooks notified of the lifecycle of the leases.
+package sdm
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LockEvent describes a change of a lease held by this process, passed to Hooks.
+type LockEvent struct {
+	Mutex  string        // Name of the mutex
+	Key    string        // Key of the lock, "<RedisKeyPrefix>:<name>" (with a suffix for RWMutex)
+	Value  string        // Serialized lock value
+	Owner  string        // Owner ID of reentrant leases (see WithReentrant), empty otherwise
+	Nested bool          // Whether the event changed the hold count of a lease its owner still holds
+	Time   time.Time     // When the event occurred
+	Held   time.Duration // How long the lease had been held, for OnRelease, OnLost and OnLongHold events
+	Err    error         // Error of a failed renewal, for OnRenewal events
+	Stack  []byte        // Stack trace of the goroutine that acquired the lease, for OnLongHold events
+}
+
+// Hooks are callbacks notified of the lifecycle of the leases held by this process,
+// so that applications can log, alert or update their health state. Any of them may
+// be nil.
+//
+// The hooks are called synchronously, by the goroutine calling TryLock, Lock and
+// Unlock, by the watchdog or by a timer, and should return quickly.
+type Hooks struct {
+	// OnAcquire is called when the lock is acquired, including reentrant
+	// acquisitions for which LockEvent.Nested is set.
+	OnAcquire func(LockEvent)
+
+	// OnRelease is called when Unlock releases the lease, or decrements the hold
+	// count of a reentrant lease for which LockEvent.Nested is set.
+	OnRelease func(LockEvent)
+
+	// OnRenewal is called after each renewal of the lease by the watchdog (see
+	// WithWatchdog), with LockEvent.Err set if the renewal failed, and after each
+	// extension with Mutex.Extend.
+	OnRenewal func(LockEvent)
+
+	// OnLost is called when a lease held by this process is found to have expired
+	// or to have been broken with ForceUnlock, so that the work it protected may no
+	// longer be exclusive: once its TTL elapses without renewal, when the watchdog
+	// fails to renew it, when Unlock finds it gone, or when the release is notified
+	// to a context returned by Mutex.LockContext.
+	OnLost func(LockEvent)
+
+	// OnLongHold is called once a lease has been held longer than the maximum hold
+	// duration set with WithMaxHold, with LockEvent.Stack set to the stack trace of
+	// its acquisition, to detect leaked or stuck locks. It is called at most once
+	// per lease, which is not released.
+	OnLongHold func(LockEvent)
+}
+
+// globalHooks holds the hooks set with SetHooks
+var globalHooks atomic.Pointer[Hooks]
+
+// SetHooks sets the hooks notified of the events of every mutex, in addition to
+// the hooks of the mutex (see WithHooks), which are called after them. It replaces
+// the hooks previously set.
+//
+// Example:
+//
+//	sdm.SetHooks(sdm.Hooks{
+//	    OnLost: func(e sdm.LockEvent) {
+//	        log.Printf("lost lock %s held for %s", e.Mutex, e.Held)
+//	    },
+//	})
+func SetHooks(hooks Hooks) {
+	globalHooks.Store(&hooks)
+}
+
+// eventKind identifies the hook called for an event
+type eventKind int
+
+const (
+	eventAcquire eventKind = iota
+	eventRelease
+	eventRenewal
+	eventLost
+	eventLongHold
+)
+
+// hook returns the hook of h called for events of kind
+func (h *Hooks) hook(kind eventKind) func(LockEvent) {
+	switch kind {
+	case eventAcquire:
+		return h.OnAcquire
+	case eventRelease:
+		return h.OnRelease
+	case eventRenewal:
+		return h.OnRenewal
+	case eventLongHold:
+		return h.OnLongHold
+	default:
+		return h.OnLost
+	}
+}
+
+// emit calls the global hooks of kind, then those of the mutex, with the event
+// of lease on the mutex name
+func emit(kind eventKind, name string, hooks []Hooks, lease Lease, event LockEvent) {
+	global := globalHooks.Load()
+	if global == nil && len(hooks) == 0 {
+		return
+	}
+
+	event.Mutex = name
+	event.Key = lease.Key
+	event.Value = lease.Value
+	event.Owner = lease.Owner
+	event.Time = time.Now()
+
+	if global != nil {
+		if fn := global.hook(kind); fn != nil {
+			fn(event)
+		}
+	}
+	for i := range hooks {
+		if fn := hooks[i].hook(kind); fn != nil {
+			fn(event)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package sdm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder 记录钩子收到的事件
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+	last   map[string]LockEvent
+}
+
+// hooks 返回记录事件的钩子，事件记为 prefix 加事件名
+func (r *eventRecorder) hooks(prefix string) Hooks {
+	record := func(kind string) func(LockEvent) {
+		return func(e LockEvent) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.last == nil {
+				r.last = make(map[string]LockEvent)
+			}
+			r.events = append(r.events, prefix+kind)
+			r.last[prefix+kind] = e
+		}
+	}
+	return Hooks{
+		OnAcquire:  record("acquire"),
+		OnRelease:  record("release"),
+		OnRenewal:  record("renewal"),
+		OnLost:     record("lost"),
+		OnLongHold: record("longhold"),
+	}
+}
+
+// take 返回并清空记录的事件
+func (r *eventRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events, r.last = nil, nil
+	return events
+}
+
+// event 返回最后一个名为 name 的事件
+func (r *eventRecorder) event(name string) LockEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last[name]
+}
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	now := time.Now()
+	b := NewMemoryBackend(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	})
+
+	rec := &eventRecorder{}
+	SetHooks(rec.hooks("global:"))
+	t.Cleanup(func() { SetHooks(Hooks{}) })
+
+	mutex, err := New[string]("test-hooks")
+	require.NoError(t, err)
+	mutex = mutex.With(WithBackend(b), WithHooks(rec.hooks("")))
+
+	t.Run("获取与释放", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+		e := rec.event("release")
+		assert.Equal(t, []string{"global:acquire", "acquire", "global:release", "release"}, rec.take())
+		assert.Equal(t, "test-hooks", e.Mutex)
+		assert.Equal(t, RedisKeyPrefix+":test-hooks", e.Key)
+		assert.Equal(t, "owner-1", e.Value)
+		assert.False(t, e.Nested)
+		assert.WithinDuration(t, time.Now(), e.Time, time.Second)
+
+		// 未持有的锁不触发事件
+		assert.Equal(t, ErrMutexNotAcquired, mutex.Unlock(ctx, "owner-1"))
+		assert.Empty(t, rec.take())
+	})
+
+	t.Run("重入", func(t *testing.T) {
+		m := mutex.With(WithReentrant("owner-a"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		assert.True(t, rec.event("acquire").Nested)
+		assert.Equal(t, "owner-a", rec.event("acquire").Owner)
+
+		require.NoError(t, m.Unlock(ctx, "job"))
+		assert.True(t, rec.event("release").Nested)
+		require.NoError(t, m.Unlock(ctx, "job"))
+		assert.False(t, rec.event("release").Nested)
+		rec.take()
+	})
+
+	t.Run("租约过期", func(t *testing.T) {
+		m := mutex.With(WithTTL(time.Minute))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		now = now.Add(time.Minute)
+		mu.Unlock()
+
+		assert.Equal(t, ErrMutexNotAcquired, m.Unlock(ctx, "owner-1"))
+		assert.GreaterOrEqual(t, rec.event("lost").Held, 10*time.Millisecond)
+		assert.Equal(t, []string{"global:acquire", "acquire", "global:lost", "lost"}, rec.take())
+	})
+
+	t.Run("看门狗", func(t *testing.T) {
+		rec.take()
+		m := mutex.With(WithTTL(time.Minute), WithWatchdog(10*time.Millisecond))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		assert.Eventually(t, func() bool {
+			return rec.event("renewal").Value == "owner-1"
+		}, time.Second, 10*time.Millisecond)
+
+		// 看门狗发现被强制释放的租约
+		info, err := m.Info(ctx)
+		require.NoError(t, err)
+		require.Len(t, info.Holders, 1)
+		require.NoError(t, ForceUnlock(ctx, "test-hooks", info.Holders[0].Token, b))
+		assert.Eventually(t, func() bool {
+			return rec.event("lost").Value == "owner-1" && rec.event("lost").Held > 0
+		}, time.Second, 10*time.Millisecond)
+
+		// 已报告丢失的租约不再重复报告
+		rec.take()
+		assert.Equal(t, ErrMutexNotAcquired, m.Unlock(ctx, "owner-1"))
+		assert.Empty(t, rec.take())
+	})
+
+	t.Run("持有时间过长", func(t *testing.T) {
+		rec.take()
+		m := mutex.With(WithMaxHold(20 * time.Millisecond))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		assert.Eventually(t, func() bool {
+			return rec.event("longhold").Value == "owner-1"
+		}, time.Second, 5*time.Millisecond)
+
+		e := rec.event("longhold")
+		assert.GreaterOrEqual(t, e.Held, 20*time.Millisecond)
+		assert.Contains(t, string(e.Stack), "TestHooks")
+		require.NoError(t, m.Unlock(ctx, "owner-1"))
+		assert.Equal(t, []string{"global:acquire", "acquire", "global:longhold", "longhold", "global:release", "release"}, rec.take())
+
+		// 在最长持有时间内释放的锁不报告
+		m = mutex.With(WithMaxHold(50 * time.Millisecond))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		require.NoError(t, m.Unlock(ctx, "owner-1"))
+		time.Sleep(100 * time.Millisecond)
+		assert.NotContains(t, rec.take(), "longhold")
+	})
+}
@@ -0,0 +1,224 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the holder metadata recorded with the leases and Mutex.Info.
+package sdm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInspectNotSupported is returned by Mutex.Info when the backend of the mutex
+// cannot describe the holders of its lock (see LeaseInspector).
+var ErrInspectNotSupported = errors.New("sdm: backend does not support lock inspection")
+
+// Metadata describes the process that acquired a lease. It is recorded with the
+// lease to answer "who holds this lock" when debugging, and has no effect on locking.
+type Metadata struct {
+	Hostname   string            `json:"hostname,omitempty"` // Host name of the process
+	PID        int               `json:"pid,omitempty"`      // Process ID
+	Labels     map[string]string `json:"labels,omitempty"`   // Custom labels, see WithLabels
+	AcquiredAt time.Time         `json:"acquired_at"`        // Time the lease was acquired, by the clock of the process
+
+	// OriginalValue is the serialized value of a lease stored under its hash
+	// because of its length, see WithValueHashing
+	OriginalValue string `json:"original_value,omitempty"`
+}
+
+// Holder describes a value holding an unexpired lease on a lock.
+type Holder struct {
+	Value     string    `json:"value"`               // Serialized lock value
+	Owner     string    `json:"owner,omitempty"`     // Owner ID of a reentrant lease (see WithReentrant), empty otherwise
+	Holds     int       `json:"holds"`               // Hold count of a reentrant lease, 1 otherwise
+	ExpiresAt time.Time `json:"expires_at,omitzero"` // Lease expiration time, zero if the lease never expires
+	Token     int64     `json:"token,omitempty"`     // Fencing token of the lease, see ForceUnlock
+	Metadata            // Metadata recorded when the lease was acquired, zero if none
+}
+
+// LockInfo describes the current holders of a lock.
+type LockInfo struct {
+	Name    string   `json:"name"`    // Name of the mutex
+	Key     string   `json:"key"`     // Key of the lock in the backend, see LockKey
+	Holders []Holder `json:"holders"` // Values holding an unexpired lease, sorted by value
+}
+
+// Locked reports whether any value holds the lock.
+func (i LockInfo) Locked() bool {
+	return len(i.Holders) > 0
+}
+
+// LeaseInspector is implemented by the backends able to describe the holders of
+// a lock, which Mutex.Info requires.
+type LeaseInspector interface {
+	// Holders returns the values holding an unexpired lease on key, in any order.
+	Holders(ctx context.Context, key string) ([]Holder, error)
+}
+
+// processMetadata returns the host name and process ID of the current process
+var processMetadata = sync.OnceValue(func() Metadata {
+	host, _ := os.Hostname()
+	return Metadata{Hostname: host, PID: os.Getpid()}
+})
+
+// newMetadata returns the metadata of a lease acquired now by the current process
+func newMetadata(labels map[string]string) *Metadata {
+	md := processMetadata()
+	md.Labels = labels
+	md.AcquiredAt = time.Now()
+	return &md
+}
+
+// encodeMetadata encodes md as JSON, returning an empty string for nil metadata
+func encodeMetadata(md *Metadata) string {
+	if md == nil {
+		return ""
+	}
+	b, err := json.Marshal(md)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// decodeMetadata decodes metadata encoded by encodeMetadata, ignoring invalid data
+func decodeMetadata(s string) Metadata {
+	var md Metadata
+	if s != "" {
+		_ = json.Unmarshal([]byte(s), &md)
+	}
+	return md
+}
+
+// parseInfo decodes the "<fencing token>:<metadata>" a lease is recorded with,
+// ignoring invalid data
+func parseInfo(s string) (token int64, md Metadata) {
+	t, meta, _ := strings.Cut(s, ":")
+	token, _ = strconv.ParseInt(t, 10, 64)
+	return token, decodeMetadata(meta)
+}
+
+// parseHoldCount decodes the "<count>:<owner>" hold count of a reentrant lease,
+// returning a hold count of 1 without owner for other leases
+func parseHoldCount(s string) (holds int, owner string) {
+	count, owner, ok := strings.Cut(s, ":")
+	if !ok {
+		return 1, ""
+	}
+	holds, err := strconv.Atoi(count)
+	if err != nil {
+		return 1, ""
+	}
+	return holds, owner
+}
+
+// Info returns the current holders of the lock, with the metadata recorded when
+// they acquired it: host name, process ID, custom labels (see WithLabels) and
+// acquisition time. It is meant for debugging which process holds a lock.
+//
+// Returns ErrInspectNotSupported if the backend of the mutex does not implement
+// LeaseInspector.
+//
+// Example:
+//
+//	info, err := m.Info(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, h := range info.Holders {
+//	    log.Printf("%s held by %s (pid %d) since %s", info.Name, h.Hostname, h.PID, h.AcquiredAt)
+//	}
+func (m Mutex[T]) Info(ctx context.Context) (LockInfo, error) {
+	key, err := m.opts.storage().LockKey(m.name)
+	if err != nil {
+		return LockInfo{}, err
+	}
+
+	inspector, ok := m.backend().(LeaseInspector)
+	if !ok {
+		return LockInfo{}, ErrInspectNotSupported
+	}
+	holders, err := inspector.Holders(ctx, key)
+	if err != nil {
+		return LockInfo{}, err
+	}
+
+	sort.Slice(holders, func(i, j int) bool { return holders[i].Value < holders[j].Value })
+	return LockInfo{Name: m.name, Key: key, Holders: holders}, nil
+}
+
+// Local reports whether the lease was acquired by the current process, according
+// to the host name and process ID recorded with it.
+func (h Holder) Local() bool {
+	md := processMetadata()
+	return h.PID != 0 && h.PID == md.PID && h.Hostname == md.Hostname
+}
+
+// IsLockedBy reports whether value holds an unexpired lease on the lock, unlike
+// IsLocked which reports whether any value holds the lock. For a reentrant mutex
+// (see WithReentrant), the lease must also be held by its owner. Whether the lease
+// was acquired by this process can be told with Holder.Local.
+//
+// Returns ErrInspectNotSupported if the backend of the mutex does not implement
+// LeaseInspector.
+//
+// Example:
+//
+//	mine, err := m.IsLockedBy(ctx, "job-42")
+//	if err != nil {
+//	    return err
+//	}
+//	if !mine {
+//	    return errors.New("job-42 is not locked")
+//	}
+func (m Mutex[T]) IsLockedBy(ctx context.Context, value T) (bool, error) {
+	lease, err := m.lease(value)
+	if err != nil {
+		return false, err
+	}
+	info, err := m.Info(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, h := range info.Holders {
+		if h.Value == lease.Value && (lease.Owner == "" || h.Owner == lease.Owner) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Holder returns the holder of the lock, the earliest acquired one if several values
+// hold a lease on it, and false if the lock is not held. Together with Holder.Local,
+// it tells a lock held by this process from a lock held by another process.
+//
+// Returns ErrInspectNotSupported if the backend of the mutex does not implement
+// LeaseInspector.
+//
+// Example:
+//
+//	h, locked, err := m.Holder(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	if locked && !h.Local() {
+//	    log.Printf("%s held by %s (pid %d)", m.Name(), h.Hostname, h.PID)
+//	}
+func (m Mutex[T]) Holder(ctx context.Context) (Holder, bool, error) {
+	info, err := m.Info(ctx)
+	if err != nil || !info.Locked() {
+		return Holder{}, false, err
+	}
+	first := info.Holders[0]
+	for _, h := range info.Holders[1:] {
+		if h.AcquiredAt.Before(first.AcquiredAt) {
+			first = h
+		}
+	}
+	return first, true, nil
+}
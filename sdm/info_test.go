@@ -0,0 +1,196 @@
+package sdm
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHoldCount(t *testing.T) {
+	holds, owner := parseHoldCount("3:host:1:owner")
+	assert.Equal(t, 3, holds)
+	assert.Equal(t, "host:1:owner", owner)
+
+	holds, owner = parseHoldCount("")
+	assert.Equal(t, 1, holds)
+	assert.Empty(t, owner)
+
+	holds, owner = parseHoldCount("x:owner")
+	assert.Equal(t, 1, holds)
+	assert.Empty(t, owner)
+}
+
+func TestMetadata(t *testing.T) {
+	md := newMetadata(map[string]string{"job": "report"})
+	assert.Equal(t, os.Getpid(), md.PID)
+	assert.WithinDuration(t, time.Now(), md.AcquiredAt, time.Second)
+
+	decoded := decodeMetadata(encodeMetadata(md))
+	assert.Equal(t, md.Hostname, decoded.Hostname)
+	assert.Equal(t, md.PID, decoded.PID)
+	assert.Equal(t, md.Labels, decoded.Labels)
+	assert.True(t, md.AcquiredAt.Equal(decoded.AcquiredAt))
+
+	assert.Empty(t, encodeMetadata(nil))
+	assert.Zero(t, decodeMetadata("invalid"))
+}
+
+func TestMutex_Info(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	mutex, err := New[string]("test-info")
+	require.NoError(t, err)
+
+	backends := map[string]LockBackend{
+		"Redis": defaultBackend,
+		"内存":    NewMemoryBackend(),
+	}
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			m := mutex.With(WithBackend(b), WithLabels(map[string]string{"job": "report"}))
+
+			info, err := m.Info(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "test-info", info.Name)
+			assert.False(t, info.Locked())
+
+			before := time.Now()
+			require.NoError(t, m.With(WithTTL(time.Minute)).Lock(ctx, "owner-1"))
+			require.NoError(t, m.With(WithReentrant("owner-a"), WithLabels(map[string]string{"step": "2"})).Lock(ctx, "owner-2"))
+			require.NoError(t, m.With(WithReentrant("owner-a")).Lock(ctx, "owner-2"))
+
+			info, err = m.Info(ctx)
+			require.NoError(t, err)
+			assert.True(t, info.Locked())
+			require.Len(t, info.Holders, 2)
+
+			h := info.Holders[0]
+			assert.Equal(t, `owner-1`, h.Value)
+			assert.Empty(t, h.Owner)
+			assert.Equal(t, 1, h.Holds)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), h.ExpiresAt, 5*time.Second)
+			assert.Equal(t, os.Getpid(), h.PID)
+			assert.Equal(t, map[string]string{"job": "report"}, h.Labels)
+			assert.False(t, h.AcquiredAt.Before(before.Truncate(time.Millisecond)))
+
+			// 重入保留最外层获取时记录的元数据
+			h = info.Holders[1]
+			assert.Equal(t, "owner-2", h.Value)
+			assert.Equal(t, "owner-a", h.Owner)
+			assert.Equal(t, 2, h.Holds)
+			assert.True(t, h.ExpiresAt.IsZero())
+			assert.Equal(t, map[string]string{"job": "report", "step": "2"}, h.Labels)
+
+			require.NoError(t, m.Unlock(ctx, "owner-1"))
+			require.NoError(t, m.With(WithReentrant("owner-a")).Unlock(ctx, "owner-2"))
+			require.NoError(t, m.With(WithReentrant("owner-a")).Unlock(ctx, "owner-2"))
+
+			info, err = m.Info(ctx)
+			require.NoError(t, err)
+			assert.False(t, info.Locked())
+		})
+	}
+
+	t.Run("释放后清除元数据", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+
+		key, err := LockKey("test-info")
+		require.NoError(t, err)
+		exists, err := client.Exists(ctx, infoKey(key)).Result()
+		require.NoError(t, err)
+		assert.Zero(t, exists)
+	})
+
+	t.Run("后端不支持", func(t *testing.T) {
+		m := mutex.With(WithBackend(struct{ LockBackend }{NewMemoryBackend()}))
+		_, err := m.Info(ctx)
+		assert.Equal(t, ErrInspectNotSupported, err)
+	})
+}
+
+func TestMutex_IsLockedBy(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	mutex, err := New[string]("test-locked-by")
+	require.NoError(t, err)
+
+	backends := map[string]LockBackend{
+		"Redis": defaultBackend,
+		"内存":    NewMemoryBackend(),
+	}
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			m := mutex.With(WithBackend(b))
+
+			_, locked, err := m.Holder(ctx)
+			require.NoError(t, err)
+			assert.False(t, locked)
+
+			require.NoError(t, m.Lock(ctx, "owner-1"))
+			time.Sleep(2 * time.Millisecond)
+			require.NoError(t, m.With(WithReentrant("owner-a")).Lock(ctx, "owner-0"))
+
+			mine, err := m.IsLockedBy(ctx, "owner-1")
+			require.NoError(t, err)
+			assert.True(t, mine)
+			mine, err = m.IsLockedBy(ctx, "owner-2")
+			require.NoError(t, err)
+			assert.False(t, mine)
+
+			// 可重入锁还需要所有者一致
+			mine, err = m.With(WithReentrant("owner-a")).IsLockedBy(ctx, "owner-0")
+			require.NoError(t, err)
+			assert.True(t, mine)
+			mine, err = m.With(WithReentrant("owner-b")).IsLockedBy(ctx, "owner-0")
+			require.NoError(t, err)
+			assert.False(t, mine)
+
+			// 返回最早获取锁的持有者
+			h, locked, err := m.Holder(ctx)
+			require.NoError(t, err)
+			assert.True(t, locked)
+			assert.Equal(t, "owner-1", h.Value)
+			assert.True(t, h.Local())
+
+			require.NoError(t, m.Unlock(ctx, "owner-1"))
+			require.NoError(t, m.With(WithReentrant("owner-a")).Unlock(ctx, "owner-0"))
+			mine, err = m.IsLockedBy(ctx, "owner-1")
+			require.NoError(t, err)
+			assert.False(t, mine)
+		})
+	}
+
+	t.Run("其他进程持有", func(t *testing.T) {
+		assert.False(t, Holder{Metadata: Metadata{Hostname: processMetadata().Hostname, PID: -1}}.Local())
+		assert.False(t, Holder{}.Local())
+	})
+
+	t.Run("后端不支持", func(t *testing.T) {
+		m := mutex.With(WithBackend(struct{ LockBackend }{NewMemoryBackend()}))
+		_, err := m.IsLockedBy(ctx, "owner-1")
+		assert.Equal(t, ErrInspectNotSupported, err)
+		_, _, err = m.Holder(ctx)
+		assert.Equal(t, ErrInspectNotSupported, err)
+	})
+}
@@ -0,0 +1,184 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the KeyedMutex type deriving mutexes from resource IDs.
+package sdm
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxKeyIDLength is the length above which an escaped resource ID is replaced by its hash
+const maxKeyIDLength = 128
+
+// Error definitions
+var (
+	// ErrInvalidKeyTemplate is returned by NewKeyed for a template with unbalanced braces
+	ErrInvalidKeyTemplate = errors.New("sdm: invalid key template")
+	// ErrInvalidKeyID is returned by KeyedMutex.For for an empty resource ID, or for a
+	// number of IDs different from the number of placeholders of the template
+	ErrInvalidKeyID = errors.New("sdm: invalid resource ID")
+)
+
+// KeyedMutex derives the mutexes of a family of resources, such as orders, from their
+// IDs, so that callers do not build mutex names by string concatenation. The names
+// are made from a template whose placeholders are filled with the escaped IDs.
+//
+// Like Mutex, a KeyedMutex is an immutable value safe for concurrent use, and the
+// options applied with With are passed on to the derived mutexes.
+type KeyedMutex[T any] struct {
+	template string   // Template of the names, the label of the derived mutexes in the metrics
+	literals []string // Text around the placeholders of the template, one more than the placeholders
+	title    string   // Display title of the derived mutexes, their name if empty
+	opts     options  // Lock configuration of the derived mutexes, see Option
+}
+
+// NewKeyed creates a KeyedMutex deriving the names of its mutexes from template, with
+// an optional title shared by the derived mutexes.
+//
+// Each placeholder of the template, a name between braces such as "{id}", is filled
+// with one of the IDs passed to For, in order; the names only document the template.
+// Doubled braces "{{" and "}}" stand for literal braces, such as the hash tag of a
+// Redis Cluster key (see SetRedis). A template without placeholders takes one ID
+// appended after a colon.
+//
+// Example:
+//
+//	orders, err := sdm.NewKeyed[string]("order:{id}")
+//	if err != nil {
+//	    return err
+//	}
+//	m, err := orders.For(orderID) // the mutex named "order:<orderID>"
+//
+//	// Keeps the locks of a tenant in one Redis Cluster slot
+//	items, _ := sdm.NewKeyed[string]("{{tenant:{tenant}}}:item:{item}")
+//	m, err = items.For("acme", 42) // "{tenant:acme}:item:42"
+//
+// Returns ErrMutexNameEmpty if the template is empty, ErrInvalidKeyTemplate if its
+// braces are unbalanced.
+func NewKeyed[T any](template string, title ...string) (KeyedMutex[T], error) {
+	if template = strings.TrimSpace(template); template == "" {
+		return KeyedMutex[T]{}, ErrMutexNameEmpty
+	}
+
+	literals, err := parseKeyTemplate(template)
+	if err != nil {
+		return KeyedMutex[T]{}, err
+	}
+	if len(literals) == 1 {
+		literals = []string{template + ":", ""}
+	}
+
+	return KeyedMutex[T]{
+		template: template,
+		literals: literals,
+		title:    strings.TrimSpace(cmp.Or(title...)),
+	}, nil
+}
+
+// parseKeyTemplate returns the text around the placeholders of template, unescaping
+// the doubled braces
+func parseKeyTemplate(template string) ([]string, error) {
+	var literals []string
+	var text strings.Builder
+	for i := 0; i < len(template); i++ {
+		switch c := template[i]; {
+		case strings.HasPrefix(template[i:], "{{"), strings.HasPrefix(template[i:], "}}"):
+			text.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexAny(template[i+1:], "{}")
+			if end < 0 || template[i+1+end] != '}' {
+				return nil, fmt.Errorf("%w: unclosed placeholder in %q", ErrInvalidKeyTemplate, template)
+			}
+			literals = append(literals, text.String())
+			text.Reset()
+			i += end + 1
+		case c == '}':
+			return nil, fmt.Errorf("%w: unbalanced brace in %q", ErrInvalidKeyTemplate, template)
+		default:
+			text.WriteByte(c)
+		}
+	}
+	return append(literals, text.String()), nil
+}
+
+// With returns a copy of the keyed mutex whose derived mutexes are configured with
+// the given options, in addition to the options already applied.
+func (k KeyedMutex[T]) With(opts ...Option) KeyedMutex[T] {
+	for _, opt := range opts {
+		opt(&k.opts)
+	}
+	return k
+}
+
+// Name returns the name of the mutex of the resource identified by ids, the template
+// filled with the escaped IDs (see For).
+func (k KeyedMutex[T]) Name(ids ...any) (string, error) {
+	if len(ids) != len(k.literals)-1 {
+		return "", fmt.Errorf("%w: %d IDs given for %d placeholders", ErrInvalidKeyID, len(ids), len(k.literals)-1)
+	}
+
+	var name strings.Builder
+	name.WriteString(k.literals[0])
+	for i, id := range ids {
+		escaped, err := escapeKeyID(fmt.Sprint(id))
+		if err != nil {
+			return "", err
+		}
+		name.WriteString(escaped)
+		name.WriteString(k.literals[i+1])
+	}
+	return name.String(), nil
+}
+
+// For returns the mutex of the resource identified by ids, one per placeholder of
+// the template, formatted with fmt.Sprint. The IDs are trimmed and escaped so that
+// they cannot alter the structure of the key:
+//
+//   - Characters with a meaning in Redis keys and patterns (":", "{", "}", "*", "?",
+//     "[", "]", "\"), "%", spaces and control characters are percent-encoded.
+//   - IDs longer than 128 bytes once escaped are replaced by "~" and a SHA-256 hash.
+//
+// The metrics of the derived mutexes are labelled by the template rather than their
+// name, so that the label values stay bounded (see EnableMetrics).
+//
+// Returns ErrInvalidKeyID if an ID is empty or the number of IDs does not match
+// the number of placeholders.
+func (k KeyedMutex[T]) For(ids ...any) (Mutex[T], error) {
+	name, err := k.Name(ids...)
+	if err != nil {
+		return Mutex[T]{}, err
+	}
+	return Mutex[T]{
+		name:   name,
+		title:  cmp.Or(k.title, name),
+		metric: k.template,
+		opts:   k.opts,
+	}, nil
+}
+
+// escapeKeyID returns the resource ID escaped for use in a mutex name, see KeyedMutex.For
+func escapeKeyID(id string) (string, error) {
+	if id = strings.TrimSpace(id); id == "" {
+		return "", fmt.Errorf("%w: empty ID", ErrInvalidKeyID)
+	}
+
+	var escaped strings.Builder
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case c <= ' ' || c == 0x7f || strings.IndexByte(`%:{}*?[]\`, c) >= 0:
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		default:
+			escaped.WriteByte(c)
+		}
+	}
+	if escaped.Len() > maxKeyIDLength {
+		sum := sha256.Sum256([]byte(id))
+		return "~" + hex.EncodeToString(sum[:]), nil
+	}
+	return escaped.String(), nil
+}
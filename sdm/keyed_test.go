@@ -0,0 +1,130 @@
+package sdm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeyed(t *testing.T) {
+	tests := []struct {
+		template string
+		ids      []any
+		want     string
+	}{
+		{"order:{id}", []any{"42"}, "order:42"},
+		{"order:{id}", []any{42}, "order:42"},
+		{"orders", []any{"42"}, "orders:42"},
+		{"tenant:{tenant}:item:{item}", []any{"acme", 7}, "tenant:acme:item:7"},
+		{"{{tenant:{tenant}}}:item:{item}", []any{"acme", 7}, "{tenant:acme}:item:7"},
+		{"  order:{id}  ", []any{"  42  "}, "order:42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			k, err := NewKeyed[string](tt.template)
+			require.NoError(t, err)
+			name, err := k.Name(tt.ids...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, name)
+		})
+	}
+
+	t.Run("无效模板", func(t *testing.T) {
+		_, err := NewKeyed[string]("  ")
+		assert.Equal(t, ErrMutexNameEmpty, err)
+
+		for _, template := range []string{"order:{id", "order:id}", "order:{a{b}}"} {
+			_, err := NewKeyed[string](template)
+			assert.ErrorIs(t, err, ErrInvalidKeyTemplate, template)
+		}
+	})
+
+	t.Run("无效 ID", func(t *testing.T) {
+		k, err := NewKeyed[string]("tenant:{tenant}:item:{item}")
+		require.NoError(t, err)
+
+		_, err = k.For("acme")
+		assert.ErrorIs(t, err, ErrInvalidKeyID)
+		_, err = k.For("acme", 1, 2)
+		assert.ErrorIs(t, err, ErrInvalidKeyID)
+		_, err = k.For("acme", " ")
+		assert.ErrorIs(t, err, ErrInvalidKeyID)
+	})
+}
+
+func TestEscapeKeyID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"order-42_a.b@c", "order-42_a.b@c"},
+		{"订单", "订单"},
+		{"a:b", "a%3Ab"},
+		{"{tag}", "%7Btag%7D"},
+		{"a*b?[c]", "a%2Ab%3F%5Bc%5D"},
+		{`a\b`, "a%5Cb"},
+		{"100%", "100%25"},
+		{"a b\n", "a%20b"},
+		{"a\x00b", "a%00b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			escaped, err := escapeKeyID(tt.id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, escaped)
+		})
+	}
+
+	t.Run("过长的 ID", func(t *testing.T) {
+		escaped, err := escapeKeyID(strings.Repeat("x", maxKeyIDLength))
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("x", maxKeyIDLength), escaped)
+
+		long, err := escapeKeyID(strings.Repeat("x", maxKeyIDLength+1))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(long, "~"))
+		assert.Len(t, long, 65)
+
+		other, err := escapeKeyID(strings.Repeat("y", maxKeyIDLength+1))
+		require.NoError(t, err)
+		assert.NotEqual(t, long, other)
+	})
+}
+
+func TestKeyedMutex(t *testing.T) {
+	ctx := context.Background()
+
+	orders, err := NewKeyed[string]("test-keyed:{id}", "订单锁")
+	require.NoError(t, err)
+	orders = orders.With(WithBackend(NewMemoryBackend()), WithTTL(time.Minute))
+
+	m1, err := orders.For(1)
+	require.NoError(t, err)
+	assert.Equal(t, "test-keyed:1", m1.Name())
+	assert.Equal(t, "订单锁", m1.Title())
+	m2, err := orders.For(2)
+	require.NoError(t, err)
+
+	// 不同资源的锁互不影响
+	require.NoError(t, m1.Lock(ctx, "owner-1"))
+	acquired, err := m2.TryLock(ctx, "owner-1")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// 同一资源派生的互斥锁共享锁和选项
+	again, err := orders.For(" 1 ")
+	require.NoError(t, err)
+	info, err := again.Info(ctx)
+	require.NoError(t, err)
+	require.Len(t, info.Holders, 1)
+	assert.False(t, info.Holders[0].ExpiresAt.IsZero())
+
+	require.NoError(t, again.Unlock(ctx, "owner-1"))
+	require.NoError(t, m2.Unlock(ctx, "owner-1"))
+}
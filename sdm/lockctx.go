@@ -0,0 +1,91 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file propagates the locks held by a call chain in its context.
+package sdm
+
+import (
+	"context"
+	"slices"
+)
+
+// heldLocksKey is the context key of the locks held by the call chain
+type heldLocksKey struct{}
+
+// HeldLock describes a lock recorded in a context as held by the call chain, see
+// ContextWithLock.
+type HeldLock struct {
+	Mutex string // Name of the mutex
+	Key   string // Key of the lock, "<RedisKeyPrefix>:<name>"
+	Value string // Serialized lock value
+	Owner string // Owner ID of reentrant leases (see WithReentrant), empty otherwise
+}
+
+// ContextWithLock returns a copy of ctx recording that the call chain holds the lock
+// of value on the mutex, so that nested code can tell that a lock it requires is
+// already held (see Mutex.HeldIn and HeldLocks) instead of acquiring it again, which
+// would block until the lock expires unless the mutex is reentrant. The contexts
+// returned by Mutex.LockContext and Mutex.TryLockContext already record their lock.
+//
+// Example:
+//
+//	if err := m.Lock(ctx, "job-42"); err != nil {
+//	    return err
+//	}
+//	defer m.Unlock(ctx, "job-42")
+//	ctx = sdm.ContextWithLock(ctx, m, "job-42")
+//
+// The record does not hold the lock itself: a lock released or lost since is no
+// longer reported. A value that cannot be serialized is not recorded.
+func ContextWithLock[T any](ctx context.Context, m Mutex[T], value T) context.Context {
+	lease, err := m.lease(value)
+	if err != nil {
+		return ctx
+	}
+	return withHeldLock(ctx, HeldLock{Mutex: m.name, Key: lease.Key, Value: lease.Value, Owner: lease.Owner})
+}
+
+// HeldLocks returns the locks recorded in ctx with ContextWithLock, in the order
+// they were recorded, leaving out those no longer held by this process because they
+// have been released or lost.
+func HeldLocks(ctx context.Context) []HeldLock {
+	locks, _ := ctx.Value(heldLocksKey{}).([]HeldLock)
+	return slices.DeleteFunc(slices.Clone(locks), func(l HeldLock) bool {
+		return lookupLease(leaseID{key: l.Key, value: l.Value}) == nil
+	})
+}
+
+// HeldIn reports whether ctx records the lock of value on the mutex as held by the
+// call chain (see ContextWithLock), and the lock is still held by this process.
+// For a reentrant mutex (see WithReentrant), the lock must also be held by the
+// owner of the mutex.
+//
+// Example:
+//
+//	func process(ctx context.Context, job string) error {
+//	    if !m.HeldIn(ctx, job) {
+//	        if err := m.Lock(ctx, job); err != nil {
+//	            return err
+//	        }
+//	        defer m.Unlock(ctx, job)
+//	        ctx = sdm.ContextWithLock(ctx, m, job)
+//	    }
+//	    return step(ctx, job) // nested steps check m.HeldIn as well
+//	}
+func (m Mutex[T]) HeldIn(ctx context.Context, value T) bool {
+	lease, err := m.lease(value)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(HeldLocks(ctx), func(l HeldLock) bool {
+		return l.Key == lease.Key && l.Value == lease.Value && l.Owner == lease.Owner
+	})
+}
+
+// withHeldLock returns a copy of ctx recording lock in addition to the locks it
+// already records
+func withHeldLock(ctx context.Context, lock HeldLock) context.Context {
+	locks, _ := ctx.Value(heldLocksKey{}).([]HeldLock)
+	if slices.Contains(locks, lock) {
+		return ctx
+	}
+	return context.WithValue(ctx, heldLocksKey{}, append(slices.Clip(locks), lock))
+}
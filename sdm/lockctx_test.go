@@ -0,0 +1,75 @@
+package sdm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWithLock(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBackend()
+
+	mutex, err := New[string]("test-lockctx")
+	require.NoError(t, err)
+	mutex = mutex.With(WithBackend(b))
+	other, err := New[string]("test-lockctx-other")
+	require.NoError(t, err)
+	other = other.With(WithBackend(b))
+
+	t.Run("记录持有的锁", func(t *testing.T) {
+		assert.False(t, mutex.HeldIn(ctx, "job"))
+		assert.Empty(t, HeldLocks(ctx))
+
+		require.NoError(t, mutex.Lock(ctx, "job"))
+		lctx := ContextWithLock(ctx, mutex, "job")
+		lctx = ContextWithLock(lctx, mutex, "job")
+
+		assert.True(t, mutex.HeldIn(lctx, "job"))
+		assert.False(t, mutex.HeldIn(lctx, "other-job"))
+		assert.False(t, other.HeldIn(lctx, "job"))
+		assert.False(t, mutex.HeldIn(ctx, "job"))
+
+		key, err := LockKey("test-lockctx")
+		require.NoError(t, err)
+		assert.Equal(t, []HeldLock{{Mutex: "test-lockctx", Key: key, Value: "job"}}, HeldLocks(lctx))
+
+		// 释放后不再报告
+		require.NoError(t, mutex.Unlock(ctx, "job"))
+		assert.False(t, mutex.HeldIn(lctx, "job"))
+		assert.Empty(t, HeldLocks(lctx))
+	})
+
+	t.Run("LockContext 自动记录", func(t *testing.T) {
+		lctx, err := mutex.LockContext(ctx, "job")
+		require.NoError(t, err)
+		octx, acquired, err := other.TryLockContext(lctx, "job")
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		var names []string
+		for _, l := range HeldLocks(octx) {
+			names = append(names, l.Mutex)
+		}
+		assert.Equal(t, []string{"test-lockctx", "test-lockctx-other"}, names)
+		assert.True(t, mutex.HeldIn(octx, "job"))
+		assert.True(t, other.HeldIn(octx, "job"))
+
+		require.NoError(t, other.Unlock(ctx, "job"))
+		require.NoError(t, mutex.Unlock(ctx, "job"))
+		assert.Empty(t, HeldLocks(octx))
+	})
+
+	t.Run("可重入锁区分所有者", func(t *testing.T) {
+		owner := mutex.With(WithReentrant("owner-a"))
+		lctx, err := owner.LockContext(ctx, "job")
+		require.NoError(t, err)
+		defer owner.Unlock(ctx, "job")
+
+		assert.True(t, owner.HeldIn(lctx, "job"))
+		assert.False(t, mutex.With(WithReentrant("owner-b")).HeldIn(lctx, "job"))
+		assert.False(t, mutex.HeldIn(lctx, "job"))
+	})
+}
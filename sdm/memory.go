@@ -0,0 +1,388 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the in-process memory LockBackend.
+package sdm
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryBackend is a LockBackend keeping leases in the memory of the current process.
+// It only coordinates the mutexes sharing it within one process, which suits tests
+// and single-node deployments.
+//
+// Example:
+//
+//	b := sdm.NewMemoryBackend()
+//	m, _ := sdm.New[string]("orders")
+//	m = m.With(sdm.WithBackend(b))
+type MemoryBackend struct {
+	mu       sync.Mutex
+	locks    map[string]map[string]*memoryLease  // Leases by lock key and value
+	watchers map[string]map[chan string]struct{} // Release watchers by lock key
+	fences   map[string]int64                    // Last fencing token by lock key
+	queues   map[leaseID][]*memoryWaiter         // Fair waiters by lock key and value, by priority then in FIFO order
+	now      func() time.Time                    // Clock measuring lease expiration
+}
+
+var (
+	_ LockBackend    = (*MemoryBackend)(nil)
+	_ ReleaseWatcher = (*MemoryBackend)(nil)
+	_ LockAdmin      = (*MemoryBackend)(nil)
+	_ FairBackend    = (*MemoryBackend)(nil)
+	_ LeaseExtender  = (*MemoryBackend)(nil)
+)
+
+// memoryLease is the lease of one value on one lock
+type memoryLease struct {
+	expires time.Time // Expiration time, zero if the lease never expires
+	owner   string    // Owner ID of a reentrant lease
+	holds   int       // Hold count of a reentrant lease
+	token   int64     // Fencing token
+	meta    Metadata  // Metadata of the holder
+}
+
+// memoryWaiter is a fair waiter queued for the lease of one value on one lock
+type memoryWaiter struct {
+	ticket   string    // Ticket of the waiter
+	priority int32     // Priority of the waiter, higher first
+	expires  time.Time // Expiration of the registration of the waiter
+}
+
+// NewMemoryBackend creates an empty memory backend. Lease expiration is measured
+// with the given clock, time.Now by default, such as a fake clock in tests (see
+// package sdmtest).
+func NewMemoryBackend(clock ...func() time.Time) *MemoryBackend {
+	now := time.Now
+	if len(clock) > 0 && clock[0] != nil {
+		now = clock[0]
+	}
+	return &MemoryBackend{
+		locks:    make(map[string]map[string]*memoryLease),
+		watchers: make(map[string]map[chan string]struct{}),
+		fences:   make(map[string]int64),
+		queues:   make(map[leaseID][]*memoryWaiter),
+		now:      now,
+	}
+}
+
+// Expire removes the leases of the given values on key, or all its leases if no
+// value is given, as if they had expired: waiters are not notified and the holders
+// get ErrMutexNotAcquired from Unlock. It simulates lease expiration in tests.
+func (b *MemoryBackend) Expire(key string, values ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(values) == 0 {
+		delete(b.locks, key)
+		return
+	}
+	leases := b.locks[key]
+	for _, value := range values {
+		delete(leases, value)
+	}
+	if len(leases) == 0 {
+		delete(b.locks, key)
+	}
+}
+
+// leases returns the unexpired leases of key, after removing the expired ones.
+// b.mu must be held.
+func (b *MemoryBackend) leases(key string) map[string]*memoryLease {
+	leases := b.locks[key]
+	now := b.now()
+	for value, l := range leases {
+		if !l.expires.IsZero() && !now.Before(l.expires) {
+			delete(leases, value)
+		}
+	}
+	if len(leases) == 0 {
+		delete(b.locks, key)
+		return nil
+	}
+	return leases
+}
+
+// expiry returns the expiration time of a lease of duration ttl acquired now
+func (b *MemoryBackend) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return b.now().Add(ttl)
+}
+
+// Acquire implements LockBackend.
+func (b *MemoryBackend) Acquire(ctx context.Context, lease Lease) (LeaseOutcome, error) {
+	if err := ctx.Err(); err != nil {
+		return LeaseUnchanged, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.acquire(lease), nil
+}
+
+// acquire creates the lease of lease.Value on lease.Key, see Acquire.
+// b.mu must be held.
+func (b *MemoryBackend) acquire(lease Lease) LeaseOutcome {
+	leases := b.leases(lease.Key)
+	if l, ok := leases[lease.Value]; ok {
+		if lease.Owner == "" || l.owner != lease.Owner {
+			return LeaseUnchanged
+		}
+		l.holds++
+		l.expires = b.expiry(lease.TTL)
+		return LeaseNested
+	}
+
+	if leases == nil {
+		leases = make(map[string]*memoryLease)
+		b.locks[lease.Key] = leases
+	}
+	b.fences[lease.Key]++
+	l := &memoryLease{
+		expires: b.expiry(lease.TTL),
+		owner:   lease.Owner,
+		holds:   1,
+		token:   b.fences[lease.Key],
+	}
+	if lease.Metadata != nil {
+		l.meta = *lease.Metadata
+		l.meta.Labels = maps.Clone(l.meta.Labels)
+	}
+	leases[lease.Value] = l
+	return LeaseChanged
+}
+
+// AcquireFair implements FairBackend.
+func (b *MemoryBackend) AcquireFair(ctx context.Context, lease Lease, ticket string, priority int32, wait time.Duration) (LeaseOutcome, error) {
+	if err := ctx.Err(); err != nil {
+		return LeaseUnchanged, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := leaseID{key: lease.Key, value: lease.Value}
+	waiters := b.queue(id)
+	i := slices.IndexFunc(waiters, func(w *memoryWaiter) bool { return w.ticket == ticket })
+
+	// A fair waiter waits for its turn, and a new one for the waiters of the same or
+	// a higher priority, unless its owner reenters the lock
+	outcome := LeaseUnchanged
+	_, held := b.leases(lease.Key)[lease.Value]
+	if held || len(waiters) == 0 || i == 0 || (i < 0 && waiters[0].priority < priority) {
+		outcome = b.acquire(lease)
+	}
+
+	switch {
+	case outcome != LeaseUnchanged && i >= 0:
+		b.queues[id] = slices.Delete(waiters, i, i+1)
+	case outcome == LeaseUnchanged && wait > 0 && i >= 0:
+		waiters[i].expires = b.now().Add(wait)
+	case outcome == LeaseUnchanged && wait > 0:
+		// Queue the waiter behind the waiters of the same or a higher priority
+		j := slices.IndexFunc(waiters, func(w *memoryWaiter) bool { return w.priority < priority })
+		if j < 0 {
+			j = len(waiters)
+		}
+		w := &memoryWaiter{ticket: ticket, priority: priority, expires: b.now().Add(wait)}
+		b.queues[id] = slices.Insert(waiters, j, w)
+	}
+	return outcome, nil
+}
+
+// Dequeue implements FairBackend.
+func (b *MemoryBackend) Dequeue(ctx context.Context, lease Lease, ticket string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := leaseID{key: lease.Key, value: lease.Value}
+	waiters := slices.DeleteFunc(b.queue(id), func(w *memoryWaiter) bool { return w.ticket == ticket })
+	if len(waiters) == 0 {
+		delete(b.queues, id)
+	} else {
+		b.queues[id] = waiters
+	}
+	return nil
+}
+
+// queue returns the unexpired fair waiters for id, after removing the expired ones.
+// b.mu must be held.
+func (b *MemoryBackend) queue(id leaseID) []*memoryWaiter {
+	now := b.now()
+	waiters := slices.DeleteFunc(b.queues[id], func(w *memoryWaiter) bool { return !now.Before(w.expires) })
+	if len(waiters) == 0 {
+		delete(b.queues, id)
+		return nil
+	}
+	b.queues[id] = waiters
+	return waiters
+}
+
+// Release implements LockBackend.
+func (b *MemoryBackend) Release(ctx context.Context, lease Lease) (LeaseOutcome, error) {
+	if err := ctx.Err(); err != nil {
+		return LeaseUnchanged, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	leases := b.leases(lease.Key)
+	l, ok := leases[lease.Value]
+	if !ok {
+		return LeaseUnchanged, nil
+	}
+
+	// A reentrant lock is only released once its hold count drops to zero
+	if lease.Owner != "" && l.owner == lease.Owner && l.holds > 1 {
+		l.holds--
+		return LeaseNested, nil
+	}
+
+	b.remove(lease.Key, lease.Value)
+	return LeaseChanged, nil
+}
+
+// remove removes the lease of value on key and notifies the watchers of key.
+// b.mu must be held.
+func (b *MemoryBackend) remove(key, value string) {
+	leases := b.locks[key]
+	delete(leases, value)
+	if len(leases) == 0 {
+		delete(b.locks, key)
+	}
+	for w := range b.watchers[key] {
+		// Drop the release rather than block if the watcher falls behind
+		select {
+		case w <- value:
+		default:
+		}
+	}
+}
+
+// Renew implements LockBackend.
+func (b *MemoryBackend) Renew(ctx context.Context, lease Lease) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l, ok := b.leases(lease.Key)[lease.Value]
+	if !ok {
+		return false, nil
+	}
+	l.expires = b.expiry(lease.TTL)
+	return true, nil
+}
+
+// Extend implements LeaseExtender, since Renew already extends a lease by any duration.
+func (b *MemoryBackend) Extend(ctx context.Context, lease Lease) (bool, error) {
+	return b.Renew(ctx, lease)
+}
+
+// IsLocked implements LockBackend.
+func (b *MemoryBackend) IsLocked(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.leases(key)) > 0, nil
+}
+
+// Holders implements LeaseInspector.
+func (b *MemoryBackend) Holders(ctx context.Context, key string) ([]Holder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	leases := b.leases(key)
+	holders := make([]Holder, 0, len(leases))
+	for value, l := range leases {
+		h := Holder{Value: value, Owner: l.owner, Holds: l.holds, ExpiresAt: l.expires, Token: l.token, Metadata: l.meta}
+		h.Labels = maps.Clone(h.Labels)
+		holders = append(holders, h)
+	}
+	return holders, nil
+}
+
+// Locks implements LockAdmin.
+func (b *MemoryBackend) Locks(ctx context.Context, pattern string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var keys []string
+	for key := range b.locks {
+		if MatchPattern(pattern, key) && len(b.leases(key)) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// ForceRelease implements LockAdmin.
+func (b *MemoryBackend) ForceRelease(ctx context.Context, key string, token int64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for value, l := range b.leases(key) {
+		if l.token == token {
+			b.remove(key, value)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// WatchReleases implements ReleaseWatcher.
+func (b *MemoryBackend) WatchReleases(ctx context.Context, key string) (<-chan string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w := make(chan string, 16)
+
+	b.mu.Lock()
+	if b.watchers[key] == nil {
+		b.watchers[key] = make(map[chan string]struct{})
+	}
+	b.watchers[key][w] = struct{}{}
+	b.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.watchers[key], w)
+		if len(b.watchers[key]) == 0 {
+			delete(b.watchers, key)
+		}
+		close(w)
+	})
+	return w, nil
+}
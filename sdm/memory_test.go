@@ -0,0 +1,133 @@
+package sdm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBackend()
+
+	mutex, err := New[string]("test-memory")
+	require.NoError(t, err)
+	mutex = mutex.With(WithBackend(b))
+
+	t.Run("获取与释放", func(t *testing.T) {
+		acquired, err := mutex.TryLock(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		acquired, err = mutex.TryLock(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		locked, err := mutex.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+		assert.Equal(t, ErrMutexNotAcquired, mutex.Unlock(ctx, "owner-1"))
+
+		locked, err = mutex.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("不使用 Redis", func(t *testing.T) {
+		originalValue := rdb.Load()
+		defer rdb.Store(originalValue)
+		rdb.Store(nil)
+
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+	})
+
+	t.Run("后端之间互不影响", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+		defer mutex.Unlock(ctx, "owner-1")
+
+		other := mutex.With(WithBackend(NewMemoryBackend()))
+		acquired, err := other.TryLock(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, other.Unlock(ctx, "owner-1"))
+	})
+
+	t.Run("可重入", func(t *testing.T) {
+		m := mutex.With(WithReentrant("owner-a"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Lock(ctx, "job"))
+
+		acquired, err := mutex.With(WithReentrant("owner-b")).TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		require.NoError(t, m.Unlock(ctx, "job"))
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		require.NoError(t, m.Unlock(ctx, "job"))
+		locked, err = m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("租约过期", func(t *testing.T) {
+		m := mutex.With(WithTTL(50 * time.Millisecond))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		time.Sleep(80 * time.Millisecond)
+
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+		assert.Equal(t, ErrMutexNotAcquired, m.Unlock(ctx, "owner-1"))
+	})
+
+	t.Run("看门狗续期", func(t *testing.T) {
+		m := mutex.With(WithTTL(60*time.Millisecond), WithWatchdog(10*time.Millisecond))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		time.Sleep(150 * time.Millisecond)
+
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+		require.NoError(t, m.Unlock(ctx, "owner-1"))
+	})
+
+	t.Run("释放后唤醒等待者", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+
+		done := make(chan error, 1)
+		go func() {
+			done <- mutex.Lock(ctx, "owner-1")
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("锁被持有时 Lock 不应该返回")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(maxBackoff / 2):
+			t.Fatal("释放后等待者应该被唤醒")
+		}
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+
+		// 等待结束后取消订阅
+		assert.Eventually(t, func() bool {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			return len(b.watchers) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
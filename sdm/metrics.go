@@ -0,0 +1,204 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the Prometheus metrics recorded once EnableMetrics is called.
+package sdm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Failure reasons of the sdm_acquire_failures_total metric
+const (
+	failureContended = "contended" // The lock was held and the call did not wait
+	failureTimeout   = "timeout"   // The lock was still held when the timeout elapsed
+	failureCanceled  = "canceled"  // The context was done while acquiring the lock
+	failureError     = "error"     // The backend failed
+)
+
+// mutexMetrics holds the collectors of the metrics of the mutexes
+type mutexMetrics struct {
+	acquisitions *prometheus.CounterVec
+	failures     *prometheus.CounterVec
+	wait         *prometheus.HistogramVec
+	hold         *prometheus.HistogramVec
+	renewals     *prometheus.CounterVec
+	expirations  *prometheus.CounterVec
+	longHolds    *prometheus.CounterVec
+}
+
+// metrics is the recorder installed by EnableMetrics, nil while metrics are disabled
+var metrics atomic.Pointer[mutexMetrics]
+
+// newMutexMetrics creates the collectors of the metrics, once per process so that
+// they can be registered with several registries
+var newMutexMetrics = sync.OnceValue(func() *mutexMetrics {
+	return &mutexMetrics{
+		acquisitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sdm",
+			Name:      "acquisitions_total",
+			Help:      "Number of lock acquisitions, including reentrant ones.",
+		}, []string{"mutex"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sdm",
+			Name:      "acquire_failures_total",
+			Help:      "Number of calls that did not acquire the lock, by reason: contended, timeout, canceled or error.",
+		}, []string{"mutex", "reason"}),
+		wait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "sdm",
+			Name:      "wait_seconds",
+			Help:      "Time spent waiting for contended locks by the calls given a timeout, whether they acquired the lock or not.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{"mutex"}),
+		hold: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "sdm",
+			Name:      "hold_seconds",
+			Help:      "Time locks were held by this process, from acquisition to release.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 12),
+		}, []string{"mutex"}),
+		renewals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sdm",
+			Name:      "renewals_total",
+			Help:      "Number of lease renewals by the watchdog, by result: renewed, lost or error.",
+		}, []string{"mutex", "result"}),
+		expirations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sdm",
+			Name:      "forced_expirations_total",
+			Help:      "Number of leases that ended without being released by their holder, by reason: expired or forced.",
+		}, []string{"mutex", "reason"}),
+		longHolds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sdm",
+			Name:      "long_holds_total",
+			Help:      "Number of leases held longer than the maximum hold duration of their mutex.",
+		}, []string{"mutex"}),
+	}
+})
+
+// collectors returns the collectors of m
+func (m *mutexMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.acquisitions, m.failures, m.wait, m.hold, m.renewals, m.expirations, m.longHolds}
+}
+
+// EnableMetrics registers the metrics of the mutexes with registry, such as
+// prometheus.DefaultRegisterer, and starts recording them. It may be called with
+// several registries. The metrics are labelled by the name of the mutex:
+//
+//   - sdm_acquisitions_total: lock acquisitions, including reentrant ones
+//   - sdm_acquire_failures_total: calls that did not acquire the lock, by reason
+//     (contended, timeout, canceled or error)
+//   - sdm_wait_seconds: time spent waiting for the lock by calls given a timeout,
+//     including Lock
+//   - sdm_hold_seconds: time locks were held, from acquisition to Unlock
+//   - sdm_renewals_total: lease renewals by the watchdog, by result (renewed, lost
+//     or error)
+//   - sdm_forced_expirations_total: leases that ended without Unlock, by reason
+//     (expired, found lost as reported by Hooks.OnLost, or forced with ForceUnlock)
+//   - sdm_long_holds_total: leases held longer than the maximum hold duration of
+//     their mutex (see WithMaxHold)
+//
+// Each mutex name is a label value, so mutexes should have a bounded set of names
+// rather than one per resource ID. The mutexes derived with KeyedMutex.For are
+// labelled by the template of their names instead.
+//
+// Example:
+//
+//	if err := sdm.EnableMetrics(prometheus.DefaultRegisterer); err != nil {
+//	    log.Fatal(err)
+//	}
+func EnableMetrics(registry prometheus.Registerer) error {
+	m := newMutexMetrics()
+	for _, c := range m.collectors() {
+		if err := registry.Register(c); err != nil {
+			var already prometheus.AlreadyRegisteredError
+			if !errors.As(err, &already) || already.ExistingCollector != c {
+				return err
+			}
+		}
+	}
+	metrics.Store(m)
+	return nil
+}
+
+// observeAcquired records an acquisition of the lock of mutex name
+func observeAcquired(name string) {
+	if m := metrics.Load(); m != nil {
+		m.acquisitions.WithLabelValues(name).Inc()
+	}
+}
+
+// observeFailure records a call that did not acquire the lock of mutex name
+// after waiting up to timeout, because of err if not nil
+func observeFailure(name string, timeout time.Duration, err error) {
+	m := metrics.Load()
+	if m == nil {
+		return
+	}
+
+	reason := failureContended
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		reason = failureCanceled
+	case err != nil:
+		reason = failureError
+	case timeout != 0:
+		reason = failureTimeout
+	}
+	m.failures.WithLabelValues(name, reason).Inc()
+}
+
+// observeWait records the time a call waited for the lock of mutex name since start
+func observeWait(name string, start time.Time) {
+	if m := metrics.Load(); m != nil {
+		m.wait.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	}
+}
+
+// observeReleased records the release by Unlock of a lease of mutex name held for d
+func observeReleased(name string, d time.Duration) {
+	if m := metrics.Load(); m != nil {
+		m.hold.WithLabelValues(name).Observe(d.Seconds())
+	}
+}
+
+// observeLost records the expiration of a held lease of mutex name, found lost
+// by Unlock, the watchdog or its release notification
+func observeLost(name string) {
+	if m := metrics.Load(); m != nil {
+		m.expirations.WithLabelValues(name, "expired").Inc()
+	}
+}
+
+// observeRenewal records a renewal of a lease of mutex name by the watchdog
+func observeRenewal(name string, renewed bool, err error) {
+	m := metrics.Load()
+	if m == nil {
+		return
+	}
+	switch {
+	case err != nil:
+		m.renewals.WithLabelValues(name, "error").Inc()
+	case renewed:
+		m.renewals.WithLabelValues(name, "renewed").Inc()
+	default:
+		m.renewals.WithLabelValues(name, "lost").Inc()
+	}
+}
+
+// observeForced records a lease of mutex name broken with ForceUnlock
+func observeForced(name string) {
+	if m := metrics.Load(); m != nil {
+		m.expirations.WithLabelValues(name, "forced").Inc()
+	}
+}
+
+// observeLongHold records a lease of mutex name held longer than its maximum hold
+// duration
+func observeLongHold(name string) {
+	if m := metrics.Load(); m != nil {
+		m.longHolds.WithLabelValues(name).Inc()
+	}
+}
@@ -0,0 +1,168 @@
+package sdm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricValue 返回指标的值，直方图返回样本数，labels 为标签名和值交替组成
+func metricValue(t *testing.T, reg *prometheus.Registry, name string, labels ...string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range family.GetMetric() {
+			values := make(map[string]string)
+			for _, label := range m.GetLabel() {
+				values[label.GetName()] = label.GetValue()
+			}
+			for i := 0; i+1 < len(labels); i += 2 {
+				if values[labels[i]] != labels[i+1] {
+					continue metrics
+				}
+			}
+			if m.GetHistogram() != nil {
+				return float64(m.GetHistogram().GetSampleCount())
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestEnableMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, EnableMetrics(reg))
+	// 重复注册到同一个注册表不报错
+	require.NoError(t, EnableMetrics(reg))
+	// 可以注册到多个注册表
+	require.NoError(t, EnableMetrics(prometheus.NewRegistry()))
+
+	// 与其他同名指标冲突时报错
+	conflicting := prometheus.NewRegistry()
+	conflicting.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "sdm_acquisitions_total", Help: "冲突的指标"}))
+	assert.Error(t, EnableMetrics(conflicting))
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, EnableMetrics(reg))
+
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	now := time.Now()
+	b := NewMemoryBackend(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	})
+
+	mutex, err := New[string]("test-metrics")
+	require.NoError(t, err)
+	mutex = mutex.With(WithBackend(b))
+	label := []string{"mutex", "test-metrics"}
+
+	t.Run("获取与释放", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+		assert.Equal(t, 1.0, metricValue(t, reg, "sdm_acquisitions_total", label...))
+
+		acquired, err := mutex.TryLock(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+		assert.Equal(t, 1.0, metricValue(t, reg, "sdm_acquire_failures_total", "mutex", "test-metrics", "reason", "contended"))
+
+		acquired, err = mutex.TryLock(ctx, "owner-1", 20*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+		assert.Equal(t, 1.0, metricValue(t, reg, "sdm_acquire_failures_total", "mutex", "test-metrics", "reason", "timeout"))
+
+		canceled, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		require.Error(t, mutex.Lock(canceled, "owner-1"))
+		assert.Equal(t, 1.0, metricValue(t, reg, "sdm_acquire_failures_total", "mutex", "test-metrics", "reason", "canceled"))
+
+		// Lock 和带超时的 TryLock 记录等待时间，无论是否获取到锁
+		assert.Equal(t, 3.0, metricValue(t, reg, "sdm_wait_seconds", label...))
+
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+		assert.Equal(t, 1.0, metricValue(t, reg, "sdm_hold_seconds", label...))
+	})
+
+	t.Run("重入", func(t *testing.T) {
+		m := mutex.With(WithReentrant("owner-a"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Unlock(ctx, "job"))
+		require.NoError(t, m.Unlock(ctx, "job"))
+
+		assert.Equal(t, 3.0, metricValue(t, reg, "sdm_acquisitions_total", label...))
+		assert.Equal(t, 2.0, metricValue(t, reg, "sdm_hold_seconds", label...))
+	})
+
+	t.Run("租约过期", func(t *testing.T) {
+		m := mutex.With(WithTTL(time.Minute))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+
+		mu.Lock()
+		now = now.Add(time.Minute)
+		mu.Unlock()
+
+		assert.Equal(t, ErrMutexNotAcquired, m.Unlock(ctx, "owner-1"))
+		assert.Equal(t, 1.0, metricValue(t, reg, "sdm_forced_expirations_total", "mutex", "test-metrics", "reason", "expired"))
+
+		// 未持有的锁不计为过期
+		assert.Equal(t, ErrMutexNotAcquired, m.Unlock(ctx, "owner-1"))
+		assert.Equal(t, 1.0, metricValue(t, reg, "sdm_forced_expirations_total", "mutex", "test-metrics", "reason", "expired"))
+	})
+
+	t.Run("看门狗续期", func(t *testing.T) {
+		m := mutex.With(WithTTL(time.Minute), WithWatchdog(10*time.Millisecond))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+
+		assert.Eventually(t, func() bool {
+			return metricValue(t, reg, "sdm_renewals_total", "mutex", "test-metrics", "result", "renewed") >= 2
+		}, time.Second, 10*time.Millisecond)
+		require.NoError(t, m.Unlock(ctx, "owner-1"))
+	})
+
+	t.Run("强制释放", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+		info, err := mutex.Info(ctx)
+		require.NoError(t, err)
+		require.Len(t, info.Holders, 1)
+
+		require.NoError(t, ForceUnlock(ctx, "test-metrics", info.Holders[0].Token, b))
+		assert.Equal(t, 1.0, metricValue(t, reg, "sdm_forced_expirations_total", "mutex", "test-metrics", "reason", "forced"))
+	})
+
+	t.Run("按模板标记", func(t *testing.T) {
+		orders, err := NewKeyed[string]("test-metrics-order:{id}")
+		require.NoError(t, err)
+		orders = orders.With(WithBackend(b))
+
+		for _, id := range []string{"1", "2"} {
+			m, err := orders.For(id)
+			require.NoError(t, err)
+			require.NoError(t, m.Lock(ctx, "owner-1"))
+			require.NoError(t, m.Unlock(ctx, "owner-1"))
+		}
+
+		label := []string{"mutex", "test-metrics-order:{id}"}
+		assert.Equal(t, 2.0, metricValue(t, reg, "sdm_acquisitions_total", label...))
+		assert.Equal(t, 2.0, metricValue(t, reg, "sdm_hold_seconds", label...))
+		// 展开后的名称不作为标签值
+		assert.Zero(t, metricValue(t, reg, "sdm_acquisitions_total", "mutex", "test-metrics-order:1"))
+	})
+}
@@ -0,0 +1,187 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the MultiMutex type locking several mutexes together.
+package sdm
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrNoMutexes is returned by NewMulti and MultiLock when no mutex is given
+var ErrNoMutexes = errors.New("sdm: no mutexes to lock")
+
+// MultiMutex locks several mutexes together, such as the two accounts of a transfer,
+// without the deadlocks callers create by locking them in different orders: while
+// one caller holds A and waits for B, another holds B and waits for A. The mutexes
+// are always locked in a canonical order, sorted by name, and the locks acquired so
+// far are released if one of them cannot be acquired, so a caller never keeps part
+// of the locks.
+//
+// Each mutex keeps its own options, so the mutexes may use different backends.
+// Like Mutex, a MultiMutex is an immutable value safe for concurrent use.
+type MultiMutex[T any] struct {
+	mutexes []Mutex[T] // Mutexes in locking order, sorted by name without duplicates
+}
+
+// NewMulti creates a MultiMutex locking the given mutexes together, such as mutexes
+// derived from resource IDs with KeyedMutex.For. Mutexes sharing a name are locked
+// once, with the options of the first one.
+//
+// Example:
+//
+//	accounts, _ := sdm.NewKeyed[string]("account:{id}")
+//	from, _ := accounts.For(fromID)
+//	to, _ := accounts.For(toID)
+//	both, err := sdm.NewMulti(from, to)
+//	if err != nil {
+//	    return err
+//	}
+//	if err := both.Lock(ctx, "transfer-1"); err != nil {
+//	    return err
+//	}
+//	defer both.Unlock(ctx, "transfer-1")
+//
+// Returns ErrNoMutexes if no mutex is given, ErrMutexNameEmpty for a mutex
+// without a name, such as the zero Mutex.
+func NewMulti[T any](mutexes ...Mutex[T]) (MultiMutex[T], error) {
+	if len(mutexes) == 0 {
+		return MultiMutex[T]{}, ErrNoMutexes
+	}
+	for _, m := range mutexes {
+		if m.name == "" {
+			return MultiMutex[T]{}, ErrMutexNameEmpty
+		}
+	}
+
+	sorted := slices.Clone(mutexes)
+	slices.SortStableFunc(sorted, func(a, b Mutex[T]) int { return cmp.Compare(a.name, b.name) })
+	sorted = slices.CompactFunc(sorted, func(a, b Mutex[T]) bool { return a.name == b.name })
+	return MultiMutex[T]{mutexes: sorted}, nil
+}
+
+// MultiLock locks the given mutexes together with value, blocking until all of
+// them are acquired or the context is cancelled, and returns the MultiMutex
+// releasing them (see NewMulti and MultiMutex.Lock).
+//
+// Example:
+//
+//	locks, err := sdm.MultiLock(ctx, "transfer-1", from, to)
+//	if err != nil {
+//	    return err
+//	}
+//	defer locks.Unlock(ctx, "transfer-1")
+func MultiLock[T any](ctx context.Context, value T, mutexes ...Mutex[T]) (MultiMutex[T], error) {
+	m, err := NewMulti(mutexes...)
+	if err != nil {
+		return MultiMutex[T]{}, err
+	}
+	if err := m.Lock(ctx, value); err != nil {
+		return MultiMutex[T]{}, err
+	}
+	return m, nil
+}
+
+// Names returns the names of the mutexes, in the order they are locked.
+func (m MultiMutex[T]) Names() []string {
+	names := make([]string, len(m.mutexes))
+	for i, mu := range m.mutexes {
+		names[i] = mu.name
+	}
+	return names
+}
+
+// With returns a copy of the MultiMutex whose mutexes are all configured with the
+// given options, in addition to their own.
+func (m MultiMutex[T]) With(opts ...Option) MultiMutex[T] {
+	mutexes := make([]Mutex[T], len(m.mutexes))
+	for i, mu := range m.mutexes {
+		mutexes[i] = mu.With(opts...)
+	}
+	return MultiMutex[T]{mutexes: mutexes}
+}
+
+// TryLock attempts to acquire the locks of all the mutexes with value, one after
+// the other in their canonical order, with an optional timeout shared by all of
+// them. If not provided or zero, the call does not block.
+//
+// If a lock cannot be acquired, the locks acquired so far are released and false
+// is returned. The call is traced as a span named "sdm.MultiMutex.TryLock", whose
+// children trace the acquisition of each lock.
+func (m MultiMutex[T]) TryLock(ctx context.Context, value T, timeout ...time.Duration) (acquired bool, err error) {
+	ctx, span := startSpan(ctx, "MultiMutex.TryLock", m.name())
+	defer func() { endSpan(span, err, attrAcquired.Bool(acquired)) }()
+
+	if len(timeout) == 0 || timeout[0] <= 0 {
+		return m.lock(ctx, value, func(mu Mutex[T]) (bool, error) {
+			return mu.TryLock(ctx, value)
+		})
+	}
+	deadline := time.Now().Add(timeout[0])
+	return m.lock(ctx, value, func(mu Mutex[T]) (bool, error) {
+		// Once the deadline has passed, the remaining locks are tried without blocking
+		return mu.TryLock(ctx, value, time.Until(deadline))
+	})
+}
+
+// Lock acquires the locks of all the mutexes with value, one after the other in
+// their canonical order, blocking until they are all available or the context is
+// cancelled. If a lock cannot be acquired, the locks acquired so far are released
+// and the error is returned. The call is traced as a span named
+// "sdm.MultiMutex.Lock".
+func (m MultiMutex[T]) Lock(ctx context.Context, value T) (err error) {
+	ctx, span := startSpan(ctx, "MultiMutex.Lock", m.name())
+	defer func() { endSpan(span, err, attrAcquired.Bool(err == nil)) }()
+
+	_, err = m.lock(ctx, value, func(mu Mutex[T]) (bool, error) {
+		err := mu.Lock(ctx, value)
+		return err == nil, err
+	})
+	return err
+}
+
+// Unlock releases the locks of all the mutexes, in the reverse of their locking
+// order. Every lock is released even if releasing another one fails, and the errors
+// are joined, such as ErrMutexNotAcquired for the locks whose lease has expired.
+// The call is traced as a span named "sdm.MultiMutex.Unlock".
+func (m MultiMutex[T]) Unlock(ctx context.Context, value T) (err error) {
+	ctx, span := startSpan(ctx, "MultiMutex.Unlock", m.name())
+	defer func() { endSpan(span, err) }()
+
+	return unlockAll(ctx, value, m.mutexes)
+}
+
+// name returns the names of the mutexes joined, to identify the MultiMutex in traces
+func (m MultiMutex[T]) name() string {
+	return strings.Join(m.Names(), ",")
+}
+
+// lock acquires the locks of the mutexes in order with acquire, releasing the locks
+// acquired so far if one of them is not acquired
+func (m MultiMutex[T]) lock(ctx context.Context, value T, acquire func(Mutex[T]) (bool, error)) (bool, error) {
+	for i, mu := range m.mutexes {
+		acquired, err := acquire(mu)
+		if acquired {
+			continue
+		}
+		if uerr := unlockAll(context.WithoutCancel(ctx), value, m.mutexes[:i]); uerr != nil {
+			err = errors.Join(err, uerr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// unlockAll releases the locks of the mutexes in reverse order, joining the errors
+func unlockAll[T any](ctx context.Context, value T, mutexes []Mutex[T]) error {
+	var errs []error
+	for _, mu := range slices.Backward(mutexes) {
+		if err := mu.Unlock(ctx, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package sdm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiMutex(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	a, err := New[string]("test-multi-a")
+	require.NoError(t, err)
+	b, err := New[string]("test-multi-b")
+	require.NoError(t, err)
+	c, err := New[string]("test-multi-c")
+	require.NoError(t, err)
+
+	_, err = NewMulti[string]()
+	assert.Equal(t, ErrNoMutexes, err)
+	_, err = NewMulti(a, Mutex[string]{})
+	assert.Equal(t, ErrMutexNameEmpty, err)
+
+	t.Run("按名称排序并去重", func(t *testing.T) {
+		m, err := NewMulti(c, a, b, a)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"test-multi-a", "test-multi-b", "test-multi-c"}, m.Names())
+	})
+
+	t.Run("获取和释放全部锁", func(t *testing.T) {
+		m, err := MultiLock(ctx, "transfer", b, a)
+		require.NoError(t, err)
+		for _, mu := range []Mutex[string]{a, b} {
+			locked, err := mu.IsLocked(ctx)
+			require.NoError(t, err)
+			assert.True(t, locked, mu.Name())
+		}
+
+		require.NoError(t, m.Unlock(ctx, "transfer"))
+		for _, mu := range []Mutex[string]{a, b} {
+			locked, err := mu.IsLocked(ctx)
+			require.NoError(t, err)
+			assert.False(t, locked, mu.Name())
+		}
+	})
+
+	t.Run("部分失败时释放已获取的锁", func(t *testing.T) {
+		require.NoError(t, c.Lock(ctx, "transfer"))
+		defer c.Unlock(ctx, "transfer")
+
+		m, err := NewMulti(a, b, c)
+		require.NoError(t, err)
+		acquired, err := m.TryLock(ctx, "transfer")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		acquired, err = m.TryLock(ctx, "transfer", 50*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		for _, mu := range []Mutex[string]{a, b} {
+			locked, err := mu.IsLocked(ctx)
+			require.NoError(t, err)
+			assert.False(t, locked, mu.Name())
+		}
+	})
+
+	t.Run("相反顺序加锁不会死锁", func(t *testing.T) {
+		tctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for _, pair := range [][]Mutex[string]{{a, b}, {b, a}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 10 {
+					m, err := MultiLock(tctx, "transfer", pair...)
+					if !assert.NoError(t, err) {
+						return
+					}
+					time.Sleep(time.Millisecond)
+					assert.NoError(t, m.Unlock(ctx, "transfer"))
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("释放时合并错误", func(t *testing.T) {
+		m, err := NewMulti(a, b)
+		require.NoError(t, err)
+		require.NoError(t, m.Lock(ctx, "transfer"))
+		require.NoError(t, a.Unlock(ctx, "transfer"))
+
+		err = m.Unlock(ctx, "transfer")
+		assert.ErrorIs(t, err, ErrMutexNotAcquired)
+		locked, err := b.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("锁选项", func(t *testing.T) {
+		backend := NewMemoryBackend()
+		m, err := NewMulti(a, b)
+		require.NoError(t, err)
+		m = m.With(WithBackend(backend))
+
+		require.NoError(t, m.Lock(ctx, "transfer"))
+		key, err := LockKey("test-multi-b")
+		require.NoError(t, err)
+		locked, err := backend.IsLocked(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, locked)
+		require.NoError(t, m.Unlock(ctx, "transfer"))
+	})
+}
@@ -5,13 +5,15 @@ package sdm
 import (
 	"cmp"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
 	"strings"
 	"time"
 
-	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -24,10 +26,13 @@ const (
 // across multiple processes or servers. Each mutex is identified by a unique name.
 //
 // The generic type parameter T specifies the type of the value that will be stored in Redis
-// to identify the lock owner. This is typically a string or a struct that can be serialized to JSON.
+// to identify the lock owner. This is typically a string or a struct that can be serialized to JSON,
+// or by the codec selected with WithCodec.
 type Mutex[T any] struct {
-	name  string // Unique identifier for the lock
-	title string // Display title for the lock, used for logging and debugging
+	name   string  // Unique identifier for the lock
+	title  string  // Display title for the lock, used for logging and debugging
+	metric string  // Label of the lock in the metrics, the template of a KeyedMutex, the name if empty
+	opts   options // Lock configuration, see Option
 }
 
 // New creates a new distributed mutex with the given name and optional title.
@@ -70,10 +75,38 @@ func (m Mutex[T]) Title() string {
 	return m.title
 }
 
+// metricName returns the label of the mutex in the metrics, see EnableMetrics
+func (m Mutex[T]) metricName() string {
+	return cmp.Or(m.metric, m.name)
+}
+
+// With returns a copy of the mutex configured with the given options.
+// The original mutex is not modified.
+//
+// Example:
+//
+//	// The lock expires 30 seconds after the owner stops renewing it
+//	m = m.With(sdm.WithTTL(30*time.Second), sdm.WithWatchdog())
+func (m Mutex[T]) With(opts ...Option) Mutex[T] {
+	for _, opt := range opts {
+		opt(&m.opts)
+	}
+	return m
+}
+
 // TryLock attempts to acquire the mutex lock with an optional timeout.
 // If the lock is already held by another process, it will either return immediately
 // (if no timeout is specified) or wait for the specified duration before giving up.
 //
+// While waiting, the caller subscribes to the release notifications that Unlock
+// publishes over Redis Pub/Sub and retries as soon as the lock is released, polling
+// about once a second to notice leases that expired (see WithTTL). Clients without
+// Pub/Sub support poll with exponential backoff instead.
+//
+// The call is traced as an OpenTelemetry span named "sdm.Mutex.TryLock" (see
+// otel.SetTracerProvider), whose context is passed down to the backend so that
+// instrumented Redis clients trace their commands as its children.
+//
 // Parameters:
 //   - ctx: Context for cancellation and timeouts (must not be nil)
 //   - value: A value that identifies the lock owner (must be JSON-serializable)
@@ -93,7 +126,10 @@ func (m Mutex[T]) Title() string {
 //	    return errors.New("could not acquire lock within timeout")
 //	}
 //	defer m.Unlock(ctx, "process-1")
-func (m Mutex[T]) TryLock(ctx context.Context, value T, timeout ...time.Duration) (bool, error) {
+func (m Mutex[T]) TryLock(ctx context.Context, value T, timeout ...time.Duration) (acquired bool, err error) {
+	ctx, span := startSpan(ctx, "Mutex.TryLock", m.name)
+	defer func() { endSpan(span, err, attrAcquired.Bool(acquired)) }()
+
 	if len(timeout) == 0 || timeout[0] <= 0 {
 		return m.tryLock(ctx, value)
 	}
@@ -101,9 +137,14 @@ func (m Mutex[T]) TryLock(ctx context.Context, value T, timeout ...time.Duration
 }
 
 // Lock acquires the mutex lock, blocking until it is available or the context is cancelled.
-// This is a convenience method that calls TryLock with a very long timeout.
+// This is a convenience method that calls TryLock without a timeout, and is woken
+// up the same way when the lock is released.
 // The context parameter must not be nil and should be used for cancellation and timeouts.
 //
+// When the mutex has a TTL (see WithTTL), the lock expires unless it is renewed
+// by the watchdog (see WithWatchdog), which stops renewing once ctx is done.
+// The call is traced as a span named "sdm.Mutex.Lock", like TryLock.
+//
 // Example:
 //
 //	err := m.Lock(ctx, "process-1")
@@ -112,7 +153,10 @@ func (m Mutex[T]) TryLock(ctx context.Context, value T, timeout ...time.Duration
 //	}
 //	defer m.Unlock(ctx, "process-1")
 //	// ... critical section ...
-func (m Mutex[T]) Lock(ctx context.Context, value T) error {
+func (m Mutex[T]) Lock(ctx context.Context, value T) (err error) {
+	ctx, span := startSpan(ctx, "Mutex.Lock", m.name)
+	defer func() { endSpan(span, err, attrAcquired.Bool(err == nil)) }()
+
 	acquired, err := m.tryLockWithTimeout(ctx, value, -1)
 	if err != nil {
 		return err
@@ -132,26 +176,114 @@ func (m Mutex[T]) tryLock(ctx context.Context, value T) (bool, error) {
 	default:
 	}
 
-	valstr, err := serializeValue(value)
+	lease, err := m.lease(value)
 	if err != nil {
-		return false, fmt.Errorf("sdm: failed to serialize value: %w", err)
+		return false, err
 	}
+	// A fair attempt that does not wait only succeeds if no waiter is queued
+	acquired, err := m.attempt(ctx, ctx, lease, m.ticket(), 0)
+	if !acquired {
+		observeFailure(m.metricName(), 0, err)
+	}
+	return acquired, err
+}
 
-	rdb, err := db()
+// backend returns the backend storing the leases of the mutex
+func (m Mutex[T]) backend() LockBackend {
+	if m.opts.backend != nil {
+		return m.opts.backend
+	}
+	return m.opts.storage().backend
+}
+
+// lease returns the lease of value on the lock of the mutex
+func (m Mutex[T]) lease(value T) (Lease, error) {
+	valstr, original, err := m.opts.encode(value)
 	if err != nil {
-		return false, err
+		return Lease{}, fmt.Errorf("sdm: failed to serialize value: %w", err)
 	}
 
-	key, err := getRedisKeyWithPrefix(RedisKeyPrefix, m.name)
+	key, err := m.opts.storage().LockKey(m.name)
 	if err != nil {
-		return false, err
+		return Lease{}, err
+	}
+
+	lease := Lease{Key: key, Value: valstr, TTL: m.opts.ttl, Owner: m.opts.owner}
+	if original != "" {
+		// The metadata of a hashed value records the original
+		lease.Metadata = &Metadata{OriginalValue: original}
+	}
+	return lease, nil
+}
+
+// fairBackend returns the backend of the mutex if it grants the lock by priority
+// then in FIFO order (see WithFairness and WithPriority), nil otherwise
+func (m Mutex[T]) fairBackend() FairBackend {
+	if !m.opts.fair {
+		return nil
+	}
+	fb, _ := m.backend().(FairBackend)
+	return fb
+}
+
+// ticket returns a new ticket identifying a fair waiter of the mutex, or an empty
+// ticket if the mutex is not fair
+func (m Mutex[T]) ticket() string {
+	if m.fairBackend() == nil {
+		return ""
+	}
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// attempt runs a single acquisition attempt of lease.
+// When the lock is newly acquired, the watchdog is started following parent,
+// the context the caller passed to TryLock or Lock. Reentrant acquisitions
+// keep the watchdog of the outermost one. A non-empty ticket identifies a fair
+// waiter, queued for wait if the attempt fails (see FairBackend).
+func (m Mutex[T]) attempt(ctx, parent context.Context, lease Lease, ticket string, wait time.Duration) (bool, error) {
+	b := m.backend()
+	md := newMetadata(m.opts.labels)
+	if lease.Metadata != nil {
+		md.OriginalValue = lease.Metadata.OriginalValue
+	}
+	lease.Metadata = md
+	start := time.Now()
+	var outcome LeaseOutcome
+	var err error
+	if fb := m.fairBackend(); fb != nil && ticket != "" {
+		outcome, err = fb.AcquireFair(ctx, lease, ticket, m.opts.priority, wait)
+	} else {
+		outcome, err = b.Acquire(ctx, lease)
 	}
-	result, err := tryLockScript.Run(ctx, rdb, []string{key}, valstr).Result()
 	if err != nil {
-		return false, fmt.Errorf("sdm: try lock failed: %w", err)
+		return false, err
 	}
 
-	return result.(int64) == 1, nil
+	switch outcome {
+	case LeaseChanged:
+		holdLease(m.name, m.metricName(), m.opts.hooks, lease, start, m.opts.maxHold)
+		observeAcquired(m.metricName())
+		traceToken(ctx, b, lease)
+		emit(eventAcquire, m.name, m.opts.hooks, lease, LockEvent{})
+		if m.opts.watchdog && m.opts.ttl > 0 {
+			startWatchdog(parent, m.name, m.metricName(), m.opts.hooks, b, lease, m.opts.renewInterval())
+		}
+		return true, nil
+	case LeaseNested:
+		// Reentrant acquisitions renew the lease
+		if h := lookupLease(leaseID{key: lease.Key, value: lease.Value}); h != nil {
+			h.extend(start, lease.TTL)
+		}
+		observeAcquired(m.metricName())
+		trace.SpanFromContext(ctx).SetAttributes(attrNested.Bool(true))
+		traceToken(ctx, b, lease)
+		emit(eventAcquire, m.name, m.opts.hooks, lease, LockEvent{Nested: true})
+		return true, nil
+	default:
+		return false, nil
+	}
 }
 
 func (m Mutex[T]) tryLockWithTimeout(ctx context.Context, value T, timeout time.Duration) (bool, error) {
@@ -162,64 +294,92 @@ func (m Mutex[T]) tryLockWithTimeout(ctx context.Context, value T, timeout time.
 	default:
 	}
 
-	// Create context with timeout (if timeout > 0)
-	var cancel context.CancelFunc
-	if timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, timeout)
-		defer cancel()
-	}
-
 	// Pre-fetch Redis key and serialize value
-	key, err := getRedisKeyWithPrefix(RedisKeyPrefix, m.name)
+	lease, err := m.lease(value)
 	if err != nil {
 		return false, err
 	}
 
-	valstr, err := serializeValue(value)
-	if err != nil {
-		return false, err
+	// Releases of other values do not free this one, the watchdog follows the caller's context
+	b := m.backend()
+	match := func(value string) bool { return value == lease.Value }
+	subscribe := func(ctx context.Context) <-chan string { return watchReleases(ctx, b, lease.Key) }
+	start := time.Now()
+	// Fair waiters keep their place in the queue while retrying at least every maxBackoff
+	ticket := m.ticket()
+	acquired, err := retry(ctx, subscribe, timeout, match, func(actx context.Context) (bool, error) {
+		return m.attempt(actx, ctx, lease, ticket, waitingLease)
+	})
+	observeWait(m.metricName(), start)
+	traceWait(ctx, start)
+	if !acquired {
+		observeFailure(m.metricName(), timeout, err)
+		if ticket != "" {
+			// Stop holding back the waiters queued behind once giving up
+			_ = m.fairBackend().Dequeue(context.WithoutCancel(ctx), lease, ticket)
+		}
 	}
+	return acquired, err
+}
 
-	rdb, err := db()
-	if err != nil {
-		return false, err
+// retry calls attempt until it succeeds, fails, the timeout elapses or ctx is done.
+// A negative timeout waits until ctx is done. The context passed to attempt is done
+// once the timeout elapses.
+//
+// Between attempts, retry waits for a release received from the channel returned by
+// subscribe that satisfies match (any release if match is nil), polling about once a
+// second to notice leases that expired without a release. If subscribe returns nil,
+// such as for clients without Pub/Sub support, retry polls with exponential backoff.
+func retry(ctx context.Context, subscribe func(context.Context) <-chan string, timeout time.Duration, match func(string) bool, attempt func(context.Context) (bool, error)) (bool, error) {
+	// Create context with timeout (if timeout > 0)
+	parent := ctx
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
+	// Subscribe to release notifications before the first attempt, so that a
+	// release happening between a failed attempt and the wait cannot be missed
+	sctx, unsubscribe := context.WithCancel(ctx)
+	defer unsubscribe()
+	released := subscribe(sctx)
+
 	// Get current time
 	startTime := time.Now()
-	attempt := 0
+	attempts := 0
 
 	for {
-		attempt++
+		attempts++
 
-		// Try to acquire lock
-		result, err := tryLockScript.Run(ctx, rdb, []string{key}, valstr).Result()
-		if err != nil {
-			return false, fmt.Errorf("sdm: try lock failed: %w", err)
+		// Try to acquire lock, return if acquired successfully
+		acquired, err := attempt(ctx)
+		if err != nil || acquired {
+			return acquired, err
 		}
 
-		// If lock acquired successfully, return
-		if result.(int64) == 1 {
-			return true, nil
+		// Check if timeout is reached, a negative timeout waits until ctx is done
+		if timeout > 0 && time.Since(startTime) >= timeout {
+			return false, nil
 		}
 
-		// Calculate backoff time
-		backoff := min(
-			time.Duration(math.Pow(float64(backoffFactor), float64(attempt-1))*float64(minBackoff)),
-			maxBackoff,
-		)
-
-		// Check if timeout is reached
-		if time.Since(startTime) >= timeout {
-			return false, nil
+		// Calculate backoff time. Waiters notified of releases are woken up by
+		// Unlock and only poll to notice leases that expired without an Unlock.
+		backoff := maxBackoff
+		if released == nil {
+			backoff = min(
+				time.Duration(math.Pow(float64(backoffFactor), float64(attempts-1))*float64(minBackoff)),
+				maxBackoff,
+			)
 		}
 
-		// Wait for a while before retrying
-		select {
-		case <-time.After(backoff):
-			continue
-		case <-ctx.Done():
-			return false, ctx.Err()
+		// Wait for a release or a while before retrying
+		if err := waitRelease(ctx, released, match, backoff); err != nil {
+			if timeout > 0 && parent.Err() == nil {
+				// Only the timeout of this call has been reached
+				return false, nil
+			}
+			return false, err
 		}
 	}
 }
@@ -237,31 +397,140 @@ func (m Mutex[T]) tryLockWithTimeout(ctx context.Context, value T, timeout time.
 //	}
 //	defer m.Unlock(ctx, "process-1")
 //
+// Unlock stops the watchdog renewing the lease (see WithWatchdog). If the lease
+// has already expired (see WithTTL), ErrMutexNotAcquired is returned.
+// A reentrant lock (see WithReentrant) is only released once Unlock has been
+// called as many times as it was acquired. The call is traced as a span named
+// "sdm.Mutex.Unlock".
+//
 // Note: If the context is cancelled while trying to release the lock, the error from
 // the context will be returned, but the lock may still be released in the background.
-func (m Mutex[T]) Unlock(ctx context.Context, value T) error {
-	valstr, err := serializeValue(value)
+func (m Mutex[T]) Unlock(ctx context.Context, value T) (err error) {
+	ctx, span := startSpan(ctx, "Mutex.Unlock", m.name)
+	var outcome LeaseOutcome
+	defer func() { endSpan(span, err, attrReleased.Bool(outcome == LeaseChanged)) }()
+
+	lease, err := m.lease(value)
 	if err != nil {
-		return fmt.Errorf("sdm: failed to serialize value: %w", err)
+		return err
+	}
+
+	if lease.Owner == "" {
+		// Stop renewing the lease before releasing it
+		stopWatchdog(lease.Key, lease.Value)
 	}
 
-	rdb, err := db()
+	id := leaseID{key: lease.Key, value: lease.Value}
+	if h := lookupLease(id); h != nil {
+		h.releasing.Store(true)
+		defer h.releasing.Store(false)
+	}
+
+	outcome, err = m.backend().Release(ctx, lease)
 	if err != nil {
 		return err
 	}
 
-	key, err := getRedisKeyWithPrefix(RedisKeyPrefix, m.name)
+	switch outcome {
+	case LeaseUnchanged:
+		// A lease held by this process has been lost
+		if h := dropLease(id); h != nil {
+			h.lose()
+		}
+		return ErrMutexNotAcquired
+	case LeaseChanged:
+		// A reentrant lock stops renewing once its hold count drops to zero
+		stopWatchdog(lease.Key, lease.Value)
+		event := LockEvent{}
+		if h := dropLease(id); h != nil {
+			h.release()
+			event.Held = h.heldFor()
+			observeReleased(m.metricName(), event.Held)
+		}
+		emit(eventRelease, m.name, m.opts.hooks, lease, event)
+	case LeaseNested:
+		event := LockEvent{Nested: true}
+		if h := lookupLease(id); h != nil {
+			event.Held = h.heldFor()
+		}
+		emit(eventRelease, m.name, m.opts.hooks, lease, event)
+	}
+	return nil
+}
+
+// Extend extends the lease of value by ttl from now, so that long-running jobs
+// without watchdog (see WithWatchdog) can keep the lock explicitly at their
+// checkpoints. A zero or negative ttl extends the lease by the TTL of the mutex (see
+// WithTTL). The lease then expires ttl from now even if it was acquired without TTL.
+// The call is traced as a span named "sdm.Mutex.Extend".
+//
+// Returns ErrExtendNotSupported if the backend of the mutex cannot apply ttl (see
+// LeaseExtender), such as etcd whose leases keep the TTL they were granted, and
+// ErrLeaseWatched if a watchdog of this process renews the lease, since its next
+// renewal would override ttl. Returns ErrMutexNotAcquired if value no longer holds a
+// lease acquired by this process, because it was released, expired or broken with
+// ForceUnlock. The loss of the lease is then reported like by Unlock (see
+// Hooks.OnLost and LockContext), unless it was already: a lease whose TTL elapsed is
+// never extended, even if the backend has not expired it yet.
+//
+// Example:
+//
+//	for _, batch := range batches {
+//	    if err := m.Extend(ctx, "job-42", time.Minute); err != nil {
+//	        return fmt.Errorf("lock lost before batch %d: %w", batch.ID, err)
+//	    }
+//	    process(batch)
+//	}
+func (m Mutex[T]) Extend(ctx context.Context, value T, ttl time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "Mutex.Extend", m.name)
+	defer func() { endSpan(span, err) }()
+
+	if ttl <= 0 {
+		ttl = m.opts.ttl
+	}
+	if ttl <= 0 {
+		return errors.New("sdm: lease duration must be positive")
+	}
+
+	extender, ok := m.backend().(LeaseExtender)
+	if !ok {
+		return ErrExtendNotSupported
+	}
+
+	lease, err := m.lease(value)
 	if err != nil {
 		return err
 	}
-	result, err := unlockScript.Run(ctx, rdb, []string{key}, valstr).Result()
-	if err != nil {
-		return fmt.Errorf("sdm: unlock failed: %w", err)
+	lease.TTL = ttl
+
+	id := leaseID{key: lease.Key, value: lease.Value}
+	if _, watched := watchdogs.Load(id); watched {
+		return ErrLeaseWatched
 	}
 
-	if result.(int64) == 0 {
+	// A lease already reported lost, for example because its TTL elapsed locally
+	// just before the backend expires it, must not be brought back
+	h := lookupLease(id)
+	if h == nil {
 		return ErrMutexNotAcquired
 	}
+
+	start := time.Now()
+	extended, err := extender.Extend(ctx, lease)
+	if err != nil {
+		return err
+	}
+	if !extended {
+		if h := dropLease(id); h != nil {
+			h.lose()
+		}
+		return ErrMutexNotAcquired
+	}
+	if !h.extend(start, ttl) {
+		// Lost or released while it was being extended
+		return ErrMutexNotAcquired
+	}
+	emit(eventRenewal, m.name, m.opts.hooks, lease, LockEvent{})
 	return nil
 }
 
@@ -286,21 +555,9 @@ func (m Mutex[T]) Unlock(ctx context.Context, value T) error {
 //	    fmt.Println("Mutex is currently locked")
 //	}
 func (m Mutex[T]) IsLocked(ctx context.Context) (bool, error) {
-	rdb, err := db()
+	key, err := m.opts.storage().LockKey(m.name)
 	if err != nil {
 		return false, err
 	}
-
-	key, err := getRedisKeyWithPrefix(RedisKeyPrefix, m.name)
-	if err != nil {
-		return false, err
-	}
-
-	// Check if the key exists and has any members using SCARD
-	count, err := rdb.(redis.Cmdable).SCard(ctx, key).Result()
-	if err != nil {
-		return false, fmt.Errorf("sdm: failed to check lock status: %w", err)
-	}
-
-	return count > 0, nil
+	return m.backend().IsLocked(ctx, key)
 }
@@ -750,3 +750,222 @@ func TestMutex_IsLocked_ConcurrentAccess(t *testing.T) {
 	// 所有检查都应该返回 false（锁未被持有）
 	assert.Equal(t, numGoroutines, unlockedCount, "所有并发检查都应该返回 false")
 }
+
+// TestMutex_Reentrant 测试可重入锁
+func TestMutex_Reentrant(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	mutex, err := New[string]("test-reentrant")
+	require.NoError(t, err)
+	reentrant := mutex.With(WithReentrant("owner-a"))
+
+	t.Run("同一所有者可以重复获取", func(t *testing.T) {
+		require.NoError(t, reentrant.Lock(ctx, "job"))
+		acquired, err := reentrant.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		// 计数未归零前锁仍被持有
+		require.NoError(t, reentrant.Unlock(ctx, "job"))
+		locked, err := reentrant.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		require.NoError(t, reentrant.Unlock(ctx, "job"))
+		locked, err = reentrant.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+
+		assert.Equal(t, ErrMutexNotAcquired, reentrant.Unlock(ctx, "job"))
+
+		// 完全释放后清理持有计数
+		key, err := getRedisKeyWithPrefix(RedisKeyPrefix, mutex.Name())
+		require.NoError(t, err)
+		exists, err := client.Exists(ctx, holdsKey(key)).Result()
+		require.NoError(t, err)
+		assert.Zero(t, exists)
+	})
+
+	t.Run("其他所有者不能重入", func(t *testing.T) {
+		require.NoError(t, reentrant.Lock(ctx, "job"))
+		defer reentrant.Unlock(ctx, "job")
+
+		acquired, err := mutex.With(WithReentrant("owner-b")).TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		// 不可重入的锁保持原有语义
+		acquired, err = mutex.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+	})
+
+	t.Run("不可重入获取的锁不能重入", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "job"))
+		defer mutex.Unlock(ctx, "job")
+
+		acquired, err := reentrant.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+	})
+
+	t.Run("默认使用进程所有者", func(t *testing.T) {
+		m := mutex.With(WithReentrant())
+		assert.Equal(t, processOwner(), m.opts.owner)
+
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Unlock(ctx, "job"))
+		require.NoError(t, m.Unlock(ctx, "job"))
+	})
+
+	t.Run("重入时续期租约", func(t *testing.T) {
+		m := reentrant.With(WithTTL(200 * time.Millisecond))
+		require.NoError(t, m.Lock(ctx, "job"))
+		time.Sleep(120 * time.Millisecond)
+		require.NoError(t, m.Lock(ctx, "job"))
+		time.Sleep(120 * time.Millisecond)
+
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked, "重入应该续期租约")
+		require.NoError(t, m.Unlock(ctx, "job"))
+		require.NoError(t, m.Unlock(ctx, "job"))
+	})
+}
+
+func TestMutex_Extend(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	backends := map[string]LockBackend{
+		"Redis": defaultBackend,
+		"内存":    NewMemoryBackend(),
+	}
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			mutex, err := New[string]("test-extend")
+			require.NoError(t, err)
+			mutex = mutex.With(WithBackend(b), WithTTL(200*time.Millisecond))
+
+			// 在检查点续期租约，远超原来的 TTL 后租约仍然有效
+			lctx, err := mutex.LockContext(ctx, "job")
+			require.NoError(t, err)
+			require.NoError(t, mutex.Extend(ctx, "job", 0))
+			require.NoError(t, mutex.Extend(ctx, "job", time.Minute))
+			time.Sleep(400 * time.Millisecond)
+
+			mine, err := mutex.IsLockedBy(ctx, "job")
+			require.NoError(t, err)
+			assert.True(t, mine, "续期后租约应该仍然有效")
+			assert.NoError(t, lctx.Err())
+			require.NoError(t, mutex.Unlock(ctx, "job"))
+
+			// 未持有的租约无法续期
+			assert.Equal(t, ErrMutexNotAcquired, mutex.Extend(ctx, "job", time.Minute))
+
+			// 永不过期的租约续期后开始过期
+			m := mutex.With(WithTTL(0))
+			assert.Error(t, m.Extend(ctx, "job", 0))
+			lctx, err = m.LockContext(ctx, "job")
+			require.NoError(t, err)
+			require.NoError(t, m.Extend(ctx, "job", 50*time.Millisecond))
+			select {
+			case <-lctx.Done():
+				assert.ErrorIs(t, context.Cause(lctx), ErrLeaseLost)
+			case <-time.After(time.Second):
+				t.Fatal("租约过期后上下文应该被取消")
+			}
+			// 本地已报告丢失的租约不能再续期，即使后端还没有让它过期
+			assert.Equal(t, ErrMutexNotAcquired, m.Extend(ctx, "job", time.Minute))
+
+			// 本地过期与后端过期之间，租约也不能续期
+			lctx, err = mutex.LockContext(ctx, "expiring")
+			require.NoError(t, err)
+			lease, err := mutex.lease("expiring")
+			require.NoError(t, err)
+			lookupLease(leaseID{key: lease.Key, value: lease.Value}).expire()
+			assert.ErrorIs(t, context.Cause(lctx), ErrLeaseLost)
+			assert.Equal(t, ErrMutexNotAcquired, mutex.Extend(ctx, "expiring", time.Minute))
+			_ = mutex.Unlock(ctx, "expiring")
+
+			// 看门狗续期的租约不能手动续期
+			wm := mutex.With(WithWatchdog(time.Minute))
+			require.NoError(t, wm.Lock(ctx, "job"))
+			assert.Equal(t, ErrLeaseWatched, wm.Extend(ctx, "job", time.Minute))
+			require.NoError(t, wm.Unlock(ctx, "job"))
+		})
+	}
+
+	t.Run("不支持续期的后端", func(t *testing.T) {
+		// 只实现 LockBackend 的后端无法按任意时长续期
+		b := struct{ LockBackend }{NewMemoryBackend()}
+		mutex, err := New[string]("test-extend-unsupported")
+		require.NoError(t, err)
+		mutex = mutex.With(WithBackend(b), WithTTL(time.Second))
+
+		lctx, err := mutex.LockContext(ctx, "job")
+		require.NoError(t, err)
+		assert.Equal(t, ErrExtendNotSupported, mutex.Extend(ctx, "job", time.Minute))
+		select {
+		case <-lctx.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("未续期的租约应该按原来的 TTL 过期")
+		}
+		assert.ErrorIs(t, context.Cause(lctx), ErrLeaseLost)
+	})
+}
+
+func TestMutex_LegacySetKey(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	mutex, err := New[string]("test-legacy-set")
+	require.NoError(t, err)
+	key, err := getRedisKeyWithPrefix(RedisKeyPrefix, mutex.Name())
+	require.NoError(t, err)
+
+	// 旧版本将锁保存为集合
+	require.NoError(t, client.SAdd(ctx, key, "old-holder").Err())
+
+	locked, err := mutex.IsLocked(ctx)
+	require.NoError(t, err)
+	assert.True(t, locked, "旧版本持有的锁应该仍被锁定")
+
+	acquired, err := mutex.TryLock(ctx, "old-holder")
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	typ, err := client.Type(ctx, key).Result()
+	require.NoError(t, err)
+	assert.Equal(t, "zset", typ, "集合应该被转换为有序集合")
+
+	// 转换后的租约不会过期，可以使用原来的值释放
+	require.NoError(t, mutex.Unlock(ctx, "old-holder"))
+	acquired, err = mutex.TryLock(ctx, "new-holder")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	require.NoError(t, mutex.Unlock(ctx, "new-holder"))
+}
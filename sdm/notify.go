@@ -0,0 +1,97 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the Pub/Sub release notifications that wake up waiting lockers.
+package sdm
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// subscriber is implemented by Redis clients supporting Pub/Sub,
+// such as *redis.Client and *redis.ClusterClient.
+type subscriber interface {
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+}
+
+// releaseChannel returns the Pub/Sub channel on which Unlock publishes
+// the values released from the lock stored at key.
+func releaseChannel(key string) string {
+	return key + ":released"
+}
+
+// subscribeReleases subscribes to the release notifications published on channel.
+// It waits for the subscription to be confirmed, so that no release published
+// afterwards is missed. It returns nil if the client does not support Pub/Sub or
+// the subscription fails, in which case waiters fall back to polling.
+func subscribeReleases(ctx context.Context, rdb redis.Scripter, channel string) *redis.PubSub {
+	s, ok := rdb.(subscriber)
+	if !ok {
+		return nil
+	}
+	ps := s.Subscribe(ctx, channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return nil
+	}
+	return ps
+}
+
+// redisReleases returns the payloads published on channel until ctx is done, or nil
+// if the client does not support Pub/Sub or the subscription fails.
+func redisReleases(ctx context.Context, rdb redis.Scripter, channel string) <-chan string {
+	ps := subscribeReleases(ctx, rdb, channel)
+	if ps == nil {
+		return nil
+	}
+
+	released := make(chan string)
+	go func() {
+		defer close(released)
+		defer ps.Close()
+
+		messages := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case released <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return released
+}
+
+// waitRelease waits until a release satisfying match (any release if match is nil)
+// is received from released, d elapses, or ctx is done.
+// A nil released channel only waits for d.
+func waitRelease(ctx context.Context, released <-chan string, match func(string) bool, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case value, ok := <-released:
+			if !ok {
+				released = nil
+				continue
+			}
+			if match == nil || match(value) {
+				return nil
+			}
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package sdm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitRelease(t *testing.T) {
+	owner1 := func(payload string) bool { return payload == `"owner-1"` }
+
+	t.Run("释放相同的值时返回", func(t *testing.T) {
+		released := make(chan string, 2)
+		released <- `"other"`
+		released <- `"owner-1"`
+
+		start := time.Now()
+		require.NoError(t, waitRelease(context.Background(), released, owner1, time.Second))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("忽略其他值的释放", func(t *testing.T) {
+		released := make(chan string, 1)
+		released <- `"other"`
+
+		start := time.Now()
+		require.NoError(t, waitRelease(context.Background(), released, owner1, 50*time.Millisecond))
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("不过滤时任意释放都返回", func(t *testing.T) {
+		released := make(chan string, 1)
+		released <- `"other"`
+		require.NoError(t, waitRelease(context.Background(), released, nil, time.Second))
+	})
+
+	t.Run("没有订阅时只等待退避时间", func(t *testing.T) {
+		require.NoError(t, waitRelease(context.Background(), nil, owner1, time.Millisecond))
+	})
+
+	t.Run("上下文取消", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, waitRelease(ctx, nil, owner1, time.Second), context.Canceled)
+	})
+}
+
+func TestMutex_ReleaseNotification(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	mutex, err := New[string]("test-release-notification")
+	require.NoError(t, err)
+
+	t.Run("释放后立即唤醒等待者", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+
+		done := make(chan time.Time, 1)
+		go func() {
+			if err := mutex.Lock(ctx, "owner-1"); err == nil {
+				done <- time.Now()
+			}
+		}()
+
+		// Lock 应该阻塞直到锁被释放
+		select {
+		case <-done:
+			t.Fatal("锁被持有时 Lock 不应该返回")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		released := time.Now()
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+
+		select {
+		case acquired := <-done:
+			// 被通知唤醒，而不是等待下一次轮询
+			assert.Less(t, acquired.Sub(released), maxBackoff/2)
+		case <-time.After(2 * maxBackoff):
+			t.Fatal("释放后等待者应该获取到锁")
+		}
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+	})
+
+	t.Run("超时返回 false", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-2"))
+		defer mutex.Unlock(ctx, "owner-2")
+
+		start := time.Now()
+		acquired, err := mutex.TryLock(ctx, "owner-2", 100*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+		assert.Less(t, time.Since(start), maxBackoff)
+	})
+
+	t.Run("上下文取消时返回错误", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-3"))
+		defer mutex.Unlock(ctx, "owner-3")
+
+		cctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, mutex.Lock(cctx, "owner-3"), context.DeadlineExceeded)
+	})
+}
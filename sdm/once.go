@@ -0,0 +1,156 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains Once, which runs a function once across the nodes of a cluster.
+package sdm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrOnceFailed is returned by Once.Do once the function has failed the number of
+// times allowed with WithMaxAttempts
+var ErrOnceFailed = errors.New("sdm: once failed")
+
+// onceValue is the lock value shared by the nodes running a Once, so that they
+// exclude each other
+const onceValue = "sdm.Once"
+
+// Once runs a function exactly once across the cluster, such as an idempotent
+// migration or a one-time initialization, however many nodes and processes call
+// Do. The completion is persisted in Redis at the key "{<RedisKeyPrefix>:<name>}:once",
+// so that the function is not run again after restarts.
+//
+// Like Mutex, a Once is an immutable value safe for concurrent use, configured with
+// the options applied with With.
+type Once struct {
+	name string  // Unique identifier for the once
+	opts options // Configuration of the lock and of the retries, see Option
+}
+
+// NewOnce creates a distributed once with the given name.
+//
+// Example:
+//
+//	migration, err := sdm.NewOnce("migrations:2024-06-add-index")
+//	if err != nil {
+//	    return err
+//	}
+//	err = migration.Do(ctx, func(ctx context.Context) error {
+//	    return addIndex(ctx)
+//	})
+//
+// Returns an error if the name is empty.
+func NewOnce(name string) (Once, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return Once{}, ErrMutexNameEmpty
+	}
+	return Once{name: name}.With(WithTTL(defaultDoTTL), WithWatchdog()), nil
+}
+
+// Name returns the unique identifier for this once.
+func (o Once) Name() string {
+	return o.name
+}
+
+// With returns a copy of the once configured with the given options, in addition to
+// the options already applied, such as WithMaxAttempts or the options of the lock.
+func (o Once) With(opts ...Option) Once {
+	for _, opt := range opts {
+		opt(&o.opts)
+	}
+	return o
+}
+
+// Do runs fn unless it has already completed, on this node or another one, and
+// returns its error. Concurrent calls wait for the running call under the lock of
+// the mutex named after the once, acquired with the value "sdm.Once", a TTL of 30
+// seconds and the watchdog unless changed with With (see WithTTL, WithWatchdog,
+// WithBackend). fn is passed a context canceled if the lease is lost (see
+// Mutex.LockContext), and should then stop without completing.
+//
+// fn has completed once it returns nil. A failed attempt is retried by the next call
+// to Do, on any node, unless WithMaxAttempts is reached, after which Do returns
+// ErrOnceFailed with the error of the last attempt until Reset is called.
+func (o Once) Do(ctx context.Context, fn func(context.Context) error) error {
+	rdb, key, err := primitiveKey(o.opts.storage(), o.name, "once")
+	if err != nil {
+		return err
+	}
+
+	done, err := o.check(ctx)
+	if err != nil || done {
+		return err
+	}
+
+	m, err := New[string](o.name)
+	if err != nil {
+		return err
+	}
+	m.opts = o.opts
+
+	lctx, err := m.LockContext(ctx, onceValue)
+	if err != nil {
+		return err
+	}
+	defer m.Unlock(context.WithoutCancel(ctx), onceValue)
+
+	// Another node may have run fn while this one waited for the lock
+	if done, err := o.check(ctx); err != nil || done {
+		return err
+	}
+
+	if err := fn(lctx); err != nil {
+		if ferr := onceFailScript.Run(context.WithoutCancel(ctx), rdb, []string{key}, err.Error()).Err(); ferr != nil {
+			return errors.Join(err, fmt.Errorf("sdm: failed to record once attempt: %w", ferr))
+		}
+		return err
+	}
+	if err := onceDoneScript.Run(context.WithoutCancel(ctx), rdb, []string{key}).Err(); err != nil {
+		return fmt.Errorf("sdm: failed to record once completion: %w", err)
+	}
+	return nil
+}
+
+// Done reports whether the function of the once has completed.
+func (o Once) Done(ctx context.Context) (bool, error) {
+	done, _, _, err := o.state(ctx)
+	return done, err
+}
+
+// Reset forgets the completion and the failed attempts of the once, so that the
+// next call to Do runs its function again.
+func (o Once) Reset(ctx context.Context) error {
+	return resetPrimitive(ctx, o.opts.storage(), o.name, "once")
+}
+
+// check reports whether the function of the once has completed, or returns
+// ErrOnceFailed if it has failed too many times
+func (o Once) check(ctx context.Context) (bool, error) {
+	done, attempts, last, err := o.state(ctx)
+	if err != nil || done {
+		return done, err
+	}
+	if o.opts.attempts > 0 && attempts >= o.opts.attempts {
+		return false, fmt.Errorf("%w after %d attempts: %s", ErrOnceFailed, attempts, last)
+	}
+	return false, nil
+}
+
+// state returns whether the function of the once has completed, the number of its
+// failed attempts and the error of the last one
+func (o Once) state(ctx context.Context) (done bool, attempts int, last string, err error) {
+	rdb, key, err := primitiveKey(o.opts.storage(), o.name, "once")
+	if err != nil {
+		return false, 0, "", err
+	}
+	result, err := onceStateScript.Run(ctx, rdb, []string{key}).Slice()
+	if err != nil {
+		return false, 0, "", fmt.Errorf("sdm: failed to read once: %w", err)
+	}
+	completed, _ := result[0].(int64)
+	failed, _ := result[1].(int64)
+	last, _ = result[2].(string)
+	return completed > 0, int(failed), last, nil
+}
@@ -0,0 +1,135 @@
+package sdm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnce(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	_, err := NewOnce(" ")
+	assert.Equal(t, ErrMutexNameEmpty, err)
+
+	t.Run("并发调用只执行一次", func(t *testing.T) {
+		once, err := NewOnce("test-once")
+		require.NoError(t, err)
+		defer once.Reset(ctx)
+
+		var calls atomic.Int32
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, once.Do(ctx, func(ctx context.Context) error {
+					calls.Add(1)
+					return nil
+				}))
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), calls.Load())
+
+		done, err := once.Done(ctx)
+		require.NoError(t, err)
+		assert.True(t, done)
+
+		// 完成标记持久保存，其他节点不再执行
+		other, err := NewOnce("test-once")
+		require.NoError(t, err)
+		require.NoError(t, other.Do(ctx, func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		}))
+		assert.Equal(t, int32(1), calls.Load())
+
+		// 重置后再次执行
+		require.NoError(t, once.Reset(ctx))
+		require.NoError(t, once.Do(ctx, func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		}))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("失败后重试", func(t *testing.T) {
+		once, err := NewOnce("test-once-retry")
+		require.NoError(t, err)
+		defer once.Reset(ctx)
+
+		errMigrate := errors.New("migration failed")
+		assert.Equal(t, errMigrate, once.Do(ctx, func(ctx context.Context) error {
+			return errMigrate
+		}))
+		done, err := once.Done(ctx)
+		require.NoError(t, err)
+		assert.False(t, done)
+
+		require.NoError(t, once.Do(ctx, func(ctx context.Context) error {
+			return nil
+		}))
+		done, err = once.Done(ctx)
+		require.NoError(t, err)
+		assert.True(t, done)
+	})
+
+	t.Run("达到最大尝试次数", func(t *testing.T) {
+		once, err := NewOnce("test-once-attempts")
+		require.NoError(t, err)
+		once = once.With(WithMaxAttempts(2))
+		defer once.Reset(ctx)
+
+		var calls atomic.Int32
+		fail := func(ctx context.Context) error {
+			calls.Add(1)
+			return errors.New("migration failed")
+		}
+		for range 2 {
+			assert.EqualError(t, once.Do(ctx, fail), "migration failed")
+		}
+
+		err = once.Do(ctx, fail)
+		assert.ErrorIs(t, err, ErrOnceFailed)
+		assert.Contains(t, err.Error(), "migration failed")
+		assert.Equal(t, int32(2), calls.Load())
+
+		// 重置后重新计算尝试次数
+		require.NoError(t, once.Reset(ctx))
+		require.NoError(t, once.Do(ctx, func(ctx context.Context) error {
+			return nil
+		}))
+	})
+
+	t.Run("锁选项", func(t *testing.T) {
+		once, err := NewOnce("test-once-backend")
+		require.NoError(t, err)
+		backend := NewMemoryBackend()
+		once = once.With(WithBackend(backend))
+		defer once.Reset(ctx)
+
+		require.NoError(t, once.Do(ctx, func(ctx context.Context) error {
+			// 执行期间持有内存后端中的锁
+			key, err := LockKey("test-once-backend")
+			require.NoError(t, err)
+			locked, err := backend.IsLocked(ctx, key)
+			require.NoError(t, err)
+			assert.True(t, locked)
+			return nil
+		}))
+	})
+}
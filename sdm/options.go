@@ -0,0 +1,311 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the options that configure how a Mutex acquires and holds its lock.
+package sdm
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Option configures a Mutex. Options are applied with Mutex.With and affect
+// every TryLock and Lock call made through the returned Mutex.
+//
+// Example:
+//
+//	m, _ := sdm.New[string]("orders")
+//	m = m.With(sdm.WithTTL(30*time.Second), sdm.WithWatchdog())
+type Option func(*options)
+
+// options holds the lock configuration of a Mutex
+type options struct {
+	ttl      time.Duration     // Lease duration, zero means the lock never expires
+	watchdog bool              // Whether the lease is renewed in the background while held
+	interval time.Duration     // Renewal interval of the watchdog, zero means ttl/3
+	owner    string            // Owner ID of reentrant acquisitions, empty if the lock is not reentrant
+	writers  bool              // Whether waiting writers take precedence over new readers of a RWMutex
+	backend  LockBackend       // Backend storing the leases, nil for the default Redis backend
+	labels   map[string]string // Custom labels recorded with the leases, see WithLabels
+	hooks    []Hooks           // Hooks notified of the events of the leases, see WithHooks
+	maxHold  time.Duration     // Expected maximum hold duration, zero for no limit, see WithMaxHold
+	fair     bool              // Whether waiters are granted the lock in FIFO order, see WithFairness
+	priority int32             // Priority of the waiters in the queue of a fair lock, see WithPriority
+	codec    Codec             // Codec serializing the lock values, nil for JSONCodec
+	registry *Registry         // Registry of the keys and Redis client, nil for the default one
+	redis    *RedisBackend     // Backend of the client set with WithClient, nil for the client of the registry
+	hashing  int               // Length above which lock values are hashed, zero for the default, negative for never
+	results  time.Duration     // Lifetime of the results cached by Do, zero to not cache them
+	attempts int               // Failed attempts after which Once gives up, zero for no limit
+}
+
+// WithTTL sets the lease duration of the lock. A lock acquired with a TTL is
+// released automatically once the TTL elapses without being renewed, so a
+// crashed process cannot hold the resource forever. The expiration is measured
+// with the Redis server clock, not the clocks of the clients.
+//
+// A zero or negative TTL means the lock never expires, which is the default.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = max(ttl, 0)
+	}
+}
+
+// WithWatchdog renews the lease of an acquired lock in the background, so a
+// lock held longer than its TTL does not expire while its owner is alive.
+//
+// The watchdog starts when TryLock or Lock acquires the lock and renews the
+// lease every interval (one third of the TTL by default) until Unlock is called
+// or the context passed to TryLock/Lock is done. If the process exits, renewal
+// stops with it and the lock expires after at most one TTL.
+//
+// The watchdog only takes effect together with WithTTL.
+func WithWatchdog(interval ...time.Duration) Option {
+	return func(o *options) {
+		o.watchdog = true
+		if len(interval) > 0 {
+			o.interval = max(interval[0], 0)
+		}
+	}
+}
+
+// WithReentrant makes the lock reentrant: the owner holding the lock may acquire it
+// again with the same value, which increments a hold count instead of blocking.
+// Unlock decrements the hold count and only releases the lock once it drops to
+// zero, so nested functions locking the same resource do not deadlock themselves.
+//
+// The owner is identified by the lock value together with the owner ID, which
+// defaults to an ID unique to the current process. Pass an owner ID to tell apart
+// callers within one process that share a lock value, such as concurrent requests.
+// Each reentrant acquisition renews the lease (see WithTTL), and the watchdog
+// (see WithWatchdog) follows the context of the outermost acquisition.
+//
+// Example:
+//
+//	m = m.With(sdm.WithReentrant())
+//	_ = m.Lock(ctx, "job-42")   // acquires the lock
+//	_ = m.Lock(ctx, "job-42")   // hold count 2
+//	_ = m.Unlock(ctx, "job-42") // hold count 1, still locked
+//	_ = m.Unlock(ctx, "job-42") // released
+func WithReentrant(owner ...string) Option {
+	return func(o *options) {
+		o.owner = strings.TrimSpace(cmp.Or(owner...))
+		if o.owner == "" {
+			o.owner = processOwner()
+		}
+	}
+}
+
+// WithWriterPreference makes writers waiting for a RWMutex take precedence over
+// new readers: once a writer is blocked in Lock or TryLock with a timeout, RLock
+// waits until the writer has acquired and released the lock. This keeps a steady
+// stream of readers from starving writers, at the cost of read throughput.
+//
+// The option only takes effect on RWMutex.
+func WithWriterPreference() Option {
+	return func(o *options) {
+		o.writers = true
+	}
+}
+
+// WithBackend stores the leases of the lock in the given backend instead of the
+// Redis client set with SetRedis, such as a RedisBackend with another client,
+// the MemoryBackend for tests and single-node deployments, or the etcd and
+// PostgreSQL backends of the sdmetcd and sdmpg packages.
+//
+// The option only takes effect on Mutex, RWMutex always uses Redis.
+func WithBackend(b LockBackend) Option {
+	return func(o *options) {
+		o.backend = b
+	}
+}
+
+// WithLabels records custom labels, such as a job or request ID, with the leases
+// acquired through the mutex. Together with the host name, process ID and
+// acquisition time recorded with every lease, they are returned by Mutex.Info
+// to identify the holder of a lock. Labels accumulate over calls, a later label
+// replacing an earlier one with the same name.
+//
+// Example:
+//
+//	m = m.With(sdm.WithLabels(map[string]string{"job": "nightly-report"}))
+func WithLabels(labels map[string]string) Option {
+	return func(o *options) {
+		merged := make(map[string]string, len(o.labels)+len(labels))
+		maps.Copy(merged, o.labels)
+		maps.Copy(merged, labels)
+		o.labels = merged
+	}
+}
+
+// WithHooks adds hooks notified of the events of the leases acquired through the
+// mutex, such as a lease lost while held. They are called after the hooks set with
+// SetHooks and the hooks added before them.
+//
+// Example:
+//
+//	m = m.With(sdm.WithHooks(sdm.Hooks{
+//	    OnLost: func(e sdm.LockEvent) { health.MarkDegraded(e.Mutex) },
+//	}))
+func WithHooks(hooks Hooks) Option {
+	return func(o *options) {
+		o.hooks = append(slices.Clip(o.hooks), hooks)
+	}
+}
+
+// WithMaxHold sets the maximum duration the leases acquired through the mutex are
+// expected to be held. A lease still held after d is reported once to the
+// Hooks.OnLongHold hooks, with the stack trace of its acquisition, and counted by
+// the sdm_long_holds_total metric, so that leaked or stuck locks are detected in
+// production. The lease itself is left untouched.
+//
+// Capturing the stack trace makes acquisitions slightly slower. A zero or negative
+// duration disables the detection, which is the default.
+//
+// Example:
+//
+//	m = m.With(sdm.WithMaxHold(time.Minute), sdm.WithHooks(sdm.Hooks{
+//	    OnLongHold: func(e sdm.LockEvent) {
+//	        log.Printf("lock %s held for %s, acquired at:\n%s", e.Mutex, e.Held, e.Stack)
+//	    },
+//	}))
+func WithMaxHold(d time.Duration) Option {
+	return func(o *options) {
+		o.maxHold = max(d, 0)
+	}
+}
+
+// WithFairness grants the lock to its waiters in the order they started waiting,
+// so that under heavy contention a slow waiter is not starved by faster ones that
+// happen to retry first after each release. Waiters blocked in Lock or TryLock with
+// a timeout are queued, and TryLock without a timeout fails while any waiter is
+// queued ahead of it.
+//
+// A waiter that stops without leaving the queue, such as a crashed process, holds
+// back the waiters behind it for at most two seconds.
+//
+// The option only takes effect on Mutex with a backend implementing FairBackend,
+// such as the Redis and memory backends; other backends keep granting the lock to
+// the first waiter to retry.
+func WithFairness() Option {
+	return func(o *options) {
+		o.fair = true
+	}
+}
+
+// WithPriority sets the priority of the waiters of the lock, so that when the lock
+// is released it is granted to the waiters of the highest priority first, such as
+// user-facing requests ahead of background jobs locking the same resource. Waiters
+// of the same priority are granted the lock in FIFO order, and the default
+// priority is 0. Priorities only order the waiters: they do not preempt a holder.
+//
+// WithPriority implies WithFairness, whose requirements apply. TryLock without a
+// timeout fails while a waiter of the same or a higher priority is queued.
+//
+// Example:
+//
+//	m, _ := sdm.New[string]("reports")
+//	interactive := m.With(sdm.WithPriority(10))
+//	batch := m.With(sdm.WithPriority(-10))
+func WithPriority(priority int32) Option {
+	return func(o *options) {
+		o.fair = true
+		o.priority = priority
+	}
+}
+
+// WithResultTTL sets how long the result computed by Do is cached in Redis, one
+// minute by default. A zero or negative TTL disables the cache, so that Do only
+// keeps nodes from computing the value at the same time.
+//
+// The option only takes effect on Do.
+func WithResultTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.results = max(ttl, 0)
+	}
+}
+
+// WithCodec sets the codec serializing the values passed to TryLock, Lock and
+// Unlock into lock values, instead of JSONCodec. Every node locking the mutex must
+// use the same codec, since the values they encode differently do not exclude each
+// other.
+//
+// Example:
+//
+//	// Values stored as their SHA-256 hash instead of their JSON encoding
+//	m = m.With(sdm.WithCodec(sdm.HashCodec(sdm.JSONCodec)))
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// WithValueHashing sets the length in bytes above which the serialized lock values
+// (see WithCodec) are stored as their SHA-256 hash, so that values of several
+// kilobytes do not bloat Redis and the scripts. The original value is recorded
+// with the lease metadata, and returned by Mutex.Info as Holder.OriginalValue.
+// Values longer than 1024 bytes are hashed by default, and a zero or negative
+// threshold never hashes them.
+//
+// Like the codec, the threshold must be the same on every node locking the mutex.
+func WithValueHashing(threshold int) Option {
+	return func(o *options) {
+		if threshold <= 0 {
+			threshold = -1
+		}
+		o.hashing = threshold
+	}
+}
+
+// WithMaxAttempts makes Once give up after the given number of failed attempts
+// across the cluster: further calls to Once.Do return ErrOnceFailed without
+// running the function, until Once.Reset is called. By default failed attempts
+// are retried by every following call.
+//
+// The option only takes effect on Once.
+func WithMaxAttempts(attempts int) Option {
+	return func(o *options) {
+		o.attempts = max(attempts, 0)
+	}
+}
+
+// processOwner returns the owner ID of the current process, made of the
+// host name, the process ID and a random suffix.
+var processOwner = sync.OnceValue(func() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b))
+})
+
+// renewInterval returns the interval at which the watchdog renews the lease
+func (o options) renewInterval() time.Duration {
+	if o.interval > 0 && o.interval < o.ttl {
+		return o.interval
+	}
+	return max(o.ttl/3, time.Millisecond)
+}
+
+// encode serializes value into a lock value with the codec of the options. A value
+// longer than the threshold of WithValueHashing is replaced by its hash, and then
+// returned as original.
+func (o options) encode(value any) (valstr, original string, err error) {
+	if o.codec == nil {
+		valstr, err = serializeValue(value)
+	} else {
+		valstr, err = o.codec.Encode(value)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if threshold := cmp.Or(o.hashing, defaultHashThreshold); threshold > 0 && len(valstr) > threshold {
+		return hashValue(valstr), valstr, nil
+	}
+	return valstr, "", nil
+}
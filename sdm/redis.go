@@ -0,0 +1,253 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the Redis LockBackend, the default backend of Mutex.
+package sdm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBackend is the LockBackend storing leases in Redis, the default backend of Mutex.
+//
+// The leases of a lock are stored in a Sorted Set at the key of the lock, whose members
+// are the lock values and whose scores are the lease expiration times. The hold counts
+// of reentrant leases and the holder metadata are stored in companion Hashes. Releases
+// are published over Redis Pub/Sub to wake up waiting lockers.
+type RedisBackend struct {
+	rdb redis.Scripter // Redis client, nil to use the client set with SetRedis
+}
+
+// NewRedisBackend creates a Redis backend using the given client, which may be any
+// redis.UniversalClient (see SetRedis). A nil client uses the client set with SetRedis.
+//
+// Example:
+//
+//	m = m.With(sdm.WithBackend(sdm.NewRedisBackend(otherClient)))
+func NewRedisBackend(rdb redis.Scripter) *RedisBackend {
+	return &RedisBackend{rdb: rdb}
+}
+
+var (
+	_ LockBackend    = (*RedisBackend)(nil)
+	_ ReleaseWatcher = (*RedisBackend)(nil)
+	_ LockAdmin      = (*RedisBackend)(nil)
+	_ FairBackend    = (*RedisBackend)(nil)
+	_ LeaseExtender  = (*RedisBackend)(nil)
+)
+
+// defaultBackend is the backend of the default registry, used by the mutexes
+// without WithBackend nor WithRegistry
+var defaultBackend = &RedisBackend{}
+
+func (b *RedisBackend) client() (redis.Scripter, error) {
+	if b.rdb != nil {
+		return b.rdb, nil
+	}
+	return db()
+}
+
+// Acquire implements LockBackend.
+func (b *RedisBackend) Acquire(ctx context.Context, lease Lease) (LeaseOutcome, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return LeaseUnchanged, err
+	}
+
+	keys := []string{lease.Key, holdsKey(lease.Key), infoKey(lease.Key), fenceKey(lease.Key)}
+	args := []any{lease.Value, lease.TTL.Milliseconds(), lease.Owner, encodeMetadata(lease.Metadata)}
+	result, err := tryLockScript.Run(ctx, rdb, keys, args...).Int64()
+	if err != nil {
+		return LeaseUnchanged, fmt.Errorf("sdm: try lock failed: %w", err)
+	}
+	return LeaseOutcome(result), nil
+}
+
+// AcquireFair implements FairBackend. The waiters are queued in a companion Sorted
+// Set of the lock, by priority then in the order of positions drawn from a
+// companion counter.
+func (b *RedisBackend) AcquireFair(ctx context.Context, lease Lease, ticket string, priority int32, wait time.Duration) (LeaseOutcome, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return LeaseUnchanged, err
+	}
+
+	keys := []string{lease.Key, holdsKey(lease.Key), infoKey(lease.Key), fenceKey(lease.Key), queueKey(lease.Key), queueSeqKey(lease.Key)}
+	args := []any{lease.Value, lease.TTL.Milliseconds(), lease.Owner, encodeMetadata(lease.Metadata), ticket, wait.Milliseconds(), priority}
+	result, err := tryLockScript.Run(ctx, rdb, keys, args...).Int64()
+	if err != nil {
+		return LeaseUnchanged, fmt.Errorf("sdm: try lock failed: %w", err)
+	}
+	return LeaseOutcome(result), nil
+}
+
+// Dequeue implements FairBackend.
+func (b *RedisBackend) Dequeue(ctx context.Context, lease Lease, ticket string) error {
+	rdb, err := b.client()
+	if err != nil {
+		return err
+	}
+
+	if err := dequeueScript.Run(ctx, rdb, []string{queueKey(lease.Key)}, lease.Value, ticket).Err(); err != nil {
+		return fmt.Errorf("sdm: failed to leave the wait queue: %w", err)
+	}
+	return nil
+}
+
+// Release implements LockBackend.
+func (b *RedisBackend) Release(ctx context.Context, lease Lease) (LeaseOutcome, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return LeaseUnchanged, err
+	}
+
+	args := []any{lease.Value, releaseChannel(lease.Key)}
+	if lease.Owner != "" {
+		args = append(args, lease.Owner)
+	}
+	keys := []string{lease.Key, holdsKey(lease.Key), infoKey(lease.Key)}
+	result, err := unlockScript.Run(ctx, rdb, keys, args...).Int64()
+	if err != nil {
+		return LeaseUnchanged, fmt.Errorf("sdm: unlock failed: %w", err)
+	}
+	return LeaseOutcome(result), nil
+}
+
+// Renew implements LockBackend.
+func (b *RedisBackend) Renew(ctx context.Context, lease Lease) (bool, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return false, err
+	}
+
+	keys := []string{lease.Key, holdsKey(lease.Key), infoKey(lease.Key)}
+	result, err := renewScript.Run(ctx, rdb, keys, lease.Value, lease.TTL.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("sdm: renew failed: %w", err)
+	}
+	return result == 1, nil
+}
+
+// Extend implements LeaseExtender, since Renew already extends a lease by any duration.
+func (b *RedisBackend) Extend(ctx context.Context, lease Lease) (bool, error) {
+	return b.Renew(ctx, lease)
+}
+
+// IsLocked implements LockBackend.
+func (b *RedisBackend) IsLocked(ctx context.Context, key string) (bool, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return false, err
+	}
+
+	// Count the values holding an unexpired lease
+	count, err := isLockedScript.Run(ctx, rdb, []string{key}).Int64()
+	if err != nil {
+		return false, fmt.Errorf("sdm: failed to check lock status: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Holders implements LeaseInspector.
+func (b *RedisBackend) Holders(ctx context.Context, key string) ([]Holder, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := holdersScript.Run(ctx, rdb, []string{key, holdsKey(key), infoKey(key)}).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("sdm: failed to inspect lock: %w", err)
+	}
+
+	holders := make([]Holder, 0, len(result)/4)
+	for i := 0; i+3 < len(result); i += 4 {
+		value, _ := result[i].(string)
+		score, _ := result[i+1].(string)
+		hold, _ := result[i+2].(string)
+		info, _ := result[i+3].(string)
+
+		h := Holder{Value: value}
+		h.Token, h.Metadata = parseInfo(info)
+		h.Holds, h.Owner = parseHoldCount(hold)
+		if ms, err := strconv.ParseFloat(score, 64); err == nil && !math.IsInf(ms, 1) {
+			h.ExpiresAt = time.UnixMilli(int64(ms))
+		}
+		holders = append(holders, h)
+	}
+	return holders, nil
+}
+
+// Locks implements LockAdmin by scanning the Sorted Sets matching pattern, on every
+// master of a Redis Cluster and every shard of a Redis Ring.
+func (b *RedisBackend) Locks(ctx context.Context, pattern string) ([]string, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var keys []string
+	scan := func(ctx context.Context, c *redis.Client) error {
+		iter := c.ScanType(ctx, 0, pattern, 100, "zset").Iterator()
+		for iter.Next(ctx) {
+			mu.Lock()
+			keys = append(keys, iter.Val())
+			mu.Unlock()
+		}
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("sdm: failed to scan locks: %w", err)
+		}
+		return nil
+	}
+
+	switch c := rdb.(type) {
+	case *redis.Client:
+		err = scan(ctx, c)
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, scan)
+	case *redis.Ring:
+		err = c.ForEachShard(ctx, scan)
+	default:
+		return nil, fmt.Errorf("sdm: cannot scan locks with %T: %w", rdb, ErrAdminNotSupported)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ForceRelease implements LockAdmin.
+func (b *RedisBackend) ForceRelease(ctx context.Context, key string, token int64) (bool, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return false, err
+	}
+
+	keys := []string{key, holdsKey(key), infoKey(key)}
+	result, err := forceUnlockScript.Run(ctx, rdb, keys, token, releaseChannel(key)).Int64()
+	if err != nil {
+		return false, fmt.Errorf("sdm: force unlock failed: %w", err)
+	}
+	return result == 1, nil
+}
+
+// WatchReleases implements ReleaseWatcher through the Pub/Sub channel on which
+// Release publishes the released values. It fails if the client does not support Pub/Sub.
+func (b *RedisBackend) WatchReleases(ctx context.Context, key string) (<-chan string, error) {
+	rdb, err := b.client()
+	if err != nil {
+		return nil, err
+	}
+
+	released := redisReleases(ctx, rdb, releaseChannel(key))
+	if released == nil {
+		return nil, fmt.Errorf("sdm: failed to subscribe to %s", releaseChannel(key))
+	}
+	return released, nil
+}
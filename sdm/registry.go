@@ -0,0 +1,199 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the Registry holding the configuration shared by mutexes.
+package sdm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Registry holds the configuration shared by the mutexes and the coordination
+// primitives created from it: the prefix of their keys and the Redis client storing
+// them. Unlike the package-level RedisKeyPrefix and SetRedis, which configure the
+// default registry, registries are independent of each other, so that libraries and
+// tests do not race on package globals, and one process can keep several sets of
+// locks apart.
+//
+// Mutexes are bound to a registry with WithRegistry, the other primitives are
+// created from its methods. A Registry is safe for concurrent use.
+type Registry struct {
+	prefix  string         // Prefix of the keys, see RedisKeyPrefix
+	rdb     redis.Scripter // Redis client, nil to use the client set with SetRedis
+	backend *RedisBackend  // Backend of the mutexes without WithBackend
+	global  bool           // Whether the registry reads RedisKeyPrefix instead of prefix
+}
+
+// defaultRegistry is the registry of the mutexes without WithRegistry, configured by
+// RedisKeyPrefix and SetRedis
+var defaultRegistry = &Registry{backend: defaultBackend, global: true}
+
+// NewRegistry creates a registry storing the keys of its mutexes and primitives
+// under prefix, "<prefix>:<name>", in the given Redis client, which may be any
+// redis.UniversalClient (see SetRedis). A nil client uses the client set with
+// SetRedis, and an empty prefix stores the keys under their bare names.
+//
+// Example:
+//
+//	payments := sdm.NewRegistry("payments", paymentsRedis)
+//	m, err := sdm.New[string]("invoice:42")
+//	if err != nil {
+//	    return err
+//	}
+//	m = m.With(sdm.WithRegistry(payments)) // locks "payments:invoice:42"
+//
+//	processed, err := payments.NewCounter("invoices:processed")
+func NewRegistry(prefix string, client redis.Scripter) *Registry {
+	return &Registry{prefix: strings.TrimSpace(prefix), rdb: client, backend: NewRedisBackend(client)}
+}
+
+// WithRegistry binds the mutex to the registry r, whose prefix and Redis client
+// replace RedisKeyPrefix and the client set with SetRedis. A nil registry is the
+// default one. The backend set with WithBackend still takes precedence over the
+// client of the registry, but the keys keep the prefix of the registry.
+func WithRegistry(r *Registry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
+
+// WithClient binds the mutex to the given Redis client, which may be any
+// redis.UniversalClient (see SetRedis), so that one process can coordinate against
+// several Redis deployments. The client replaces the one of the registry of the mutex
+// for its leases, the results cached by Do and the completion of a Once, while the
+// keys keep the prefix of the registry. A nil client uses the client set with SetRedis.
+//
+// WithClient and WithRegistry compose in any order. The backend set with
+// WithBackend still takes precedence over the client.
+//
+// Example:
+//
+//	m, err := sdm.New[string]("orders")
+//	if err != nil {
+//	    return err
+//	}
+//	m = m.With(sdm.WithClient(euRedis))
+func WithClient(client redis.Scripter) Option {
+	return func(o *options) {
+		o.redis = NewRedisBackend(client)
+	}
+}
+
+// Prefix returns the prefix of the keys stored by the registry.
+func (r *Registry) Prefix() string {
+	if r.global {
+		return RedisKeyPrefix
+	}
+	return r.prefix
+}
+
+// LockKey returns the key under which the lock of the mutex named name is stored in
+// the registry, "<prefix>:<name>". It is the Lease.Key passed to the LockBackend.
+func (r *Registry) LockKey(name string) (string, error) {
+	return getRedisKeyWithPrefix(r.Prefix(), name)
+}
+
+// NewCounter creates a distributed counter stored in the registry, see NewCounter.
+func (r *Registry) NewCounter(name string) (Counter, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return Counter{}, ErrMutexNameEmpty
+	}
+	return Counter{name: name, registry: r}, nil
+}
+
+// NewGauge creates a distributed gauge stored in the registry, see NewGauge.
+func (r *Registry) NewGauge(name string) (Gauge, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return Gauge{}, ErrMutexNameEmpty
+	}
+	return Gauge{name: name, registry: r}, nil
+}
+
+// NewFlag creates a distributed flag stored in the registry, see NewFlag.
+func (r *Registry) NewFlag(name string) (Flag, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return Flag{}, ErrMutexNameEmpty
+	}
+	return Flag{name: name, registry: r}, nil
+}
+
+// NewBarrier creates a distributed barrier stored in the registry, see NewBarrier.
+func (r *Registry) NewBarrier(name string, parties int) (Barrier, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return Barrier{}, ErrMutexNameEmpty
+	}
+	if parties < 1 {
+		return Barrier{}, ErrInvalidCount
+	}
+	return Barrier{name: name, parties: parties, registry: r}, nil
+}
+
+// NewCountdownLatch creates a distributed countdown latch stored in the registry,
+// see NewCountdownLatch.
+func (r *Registry) NewCountdownLatch(name string, count int) (CountdownLatch, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return CountdownLatch{}, ErrMutexNameEmpty
+	}
+	if count < 1 {
+		return CountdownLatch{}, ErrInvalidCount
+	}
+	return CountdownLatch{name: name, count: count, registry: r}, nil
+}
+
+// NewOnce creates a distributed once stored in the registry, see NewOnce.
+func (r *Registry) NewOnce(name string) (Once, error) {
+	o, err := NewOnce(name)
+	if err != nil {
+		return Once{}, err
+	}
+	return o.With(WithRegistry(r)), nil
+}
+
+// ListLocks lists the locks of the registry, see ListLocks. The locks are looked
+// up in the given backend, the Redis client of the registry by default.
+func (r *Registry) ListLocks(ctx context.Context, pattern string, backend ...LockBackend) ([]LockInfo, error) {
+	return listLocks(ctx, r, pattern, backend)
+}
+
+// ForceUnlock breaks a lease on a lock of the registry, see ForceUnlock. The lease
+// is looked up in the given backend, the Redis client of the registry by default.
+func (r *Registry) ForceUnlock(ctx context.Context, name string, token int64, backend ...LockBackend) error {
+	return forceUnlock(ctx, r, name, token, backend)
+}
+
+// AdminHandler returns an HTTP handler administering the locks of the registry,
+// see AdminHandler.
+func (r *Registry) AdminHandler(backend ...LockBackend) http.Handler {
+	return adminHandler(r, backend)
+}
+
+// client returns the Redis client of the registry
+func (r *Registry) client() (redis.Scripter, error) {
+	if r.rdb == nil {
+		return db()
+	}
+	return r.rdb, nil
+}
+
+// storage returns the registry storing the keys of the mutex configured by o: the
+// registry set with WithRegistry, or the default one, using the client set with
+// WithClient if any
+func (o *options) storage() *Registry {
+	r := registryOrDefault(o.registry)
+	if o.redis == nil {
+		return r
+	}
+	bound := *r
+	bound.rdb, bound.backend = o.redis.rdb, o.redis
+	return &bound
+}
+
+// registryOrDefault returns r, or the default registry if r is nil
+func registryOrDefault(r *Registry) *Registry {
+	if r == nil {
+		return defaultRegistry
+	}
+	return r
+}
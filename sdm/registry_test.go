@@ -0,0 +1,203 @@
+package sdm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	payments := NewRegistry(" payments ", client)
+	orders := NewRegistry("orders", nil)
+	assert.Equal(t, "payments", payments.Prefix())
+	assert.Equal(t, RedisKeyPrefix, defaultRegistry.Prefix())
+
+	key, err := payments.LockKey("invoice:42")
+	require.NoError(t, err)
+	assert.Equal(t, "payments:invoice:42", key)
+
+	t.Run("注册表之间互不影响", func(t *testing.T) {
+		m, err := New[string]("test-registry")
+		require.NoError(t, err)
+		pm := m.With(WithRegistry(payments))
+		om := m.With(WithRegistry(orders))
+
+		require.NoError(t, pm.Lock(ctx, "job"))
+		defer pm.Unlock(ctx, "job")
+
+		// 相同名称的锁在其他注册表和默认注册表中都未被持有
+		for _, other := range []Mutex[string]{om, m} {
+			acquired, err := other.TryLock(ctx, "job")
+			require.NoError(t, err)
+			assert.True(t, acquired)
+			require.NoError(t, other.Unlock(ctx, "job"))
+		}
+
+		exists, err := client.Exists(ctx, "payments:test-registry").Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), exists)
+
+		locks, err := payments.ListLocks(ctx, "test-registry*")
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+		assert.Equal(t, "test-registry", locks[0].Name)
+
+		locks, err = orders.ListLocks(ctx, "test-registry*")
+		require.NoError(t, err)
+		assert.Empty(t, locks)
+	})
+
+	t.Run("强制释放", func(t *testing.T) {
+		m, err := New[string]("test-registry-force")
+		require.NoError(t, err)
+		m = m.With(WithRegistry(payments))
+		require.NoError(t, m.Lock(ctx, "job"))
+
+		info, err := m.Info(ctx)
+		require.NoError(t, err)
+		require.Len(t, info.Holders, 1)
+		assert.Equal(t, "payments:test-registry-force", info.Key)
+
+		require.NoError(t, payments.ForceUnlock(ctx, "test-registry-force", info.Holders[0].Token))
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("协调原语", func(t *testing.T) {
+		c, err := payments.NewCounter("test-registry-counter")
+		require.NoError(t, err)
+		defer c.Reset(ctx)
+		_, err = payments.NewCounter(" ")
+		assert.Equal(t, ErrMutexNameEmpty, err)
+
+		n, err := c.Incr(ctx, 5)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), n)
+
+		// 默认注册表中的同名计数器不受影响
+		other, err := NewCounter("test-registry-counter")
+		require.NoError(t, err)
+		n, err = other.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+
+		exists, err := client.Exists(ctx, "{payments:test-registry-counter}:counter").Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), exists)
+
+		once, err := payments.NewOnce("test-registry-once")
+		require.NoError(t, err)
+		defer once.Reset(ctx)
+		require.NoError(t, once.Do(ctx, func(ctx context.Context) error { return nil }))
+		exists, err = client.Exists(ctx, "{payments:test-registry-once}:once").Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), exists)
+	})
+
+	t.Run("未初始化的客户端", func(t *testing.T) {
+		SetRedis(nil)
+		defer SetRedis(client)
+
+		// 没有自己客户端的注册表使用 SetRedis 设置的客户端
+		m, err := New[string]("test-registry-client")
+		require.NoError(t, err)
+		_, err = m.With(WithRegistry(orders)).TryLock(ctx, "job")
+		assert.ErrorIs(t, err, ErrRedisNotInitialized)
+
+		acquired, err := m.With(WithRegistry(payments)).TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, m.With(WithRegistry(payments)).Unlock(ctx, "job"))
+	})
+}
+
+func TestMutex_WithClient(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	// 另一个 Redis 部署
+	other := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	defer other.Close()
+
+	m, err := New[string]("test-with-client")
+	require.NoError(t, err)
+	om := m.With(WithClient(other))
+
+	t.Run("锁存储在指定的客户端", func(t *testing.T) {
+		require.NoError(t, om.Lock(ctx, "job"))
+		defer om.Unlock(ctx, "job")
+
+		key, err := LockKey("test-with-client")
+		require.NoError(t, err)
+		exists, err := other.Exists(ctx, key).Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), exists)
+
+		// 默认客户端中的同名锁未被持有
+		acquired, err := m.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, m.Unlock(ctx, "job"))
+
+		rw, err := NewRWMutex[string]("test-with-client-rw")
+		require.NoError(t, err)
+		rw = rw.With(WithClient(other))
+		require.NoError(t, rw.Lock(ctx, "job"))
+		defer rw.Unlock(ctx, "job")
+		rwKey, err := LockKey("test-with-client-rw")
+		require.NoError(t, err)
+		keys, err := other.Keys(ctx, "*"+rwKey+"*").Result()
+		require.NoError(t, err)
+		assert.NotEmpty(t, keys)
+	})
+
+	t.Run("保留注册表的前缀", func(t *testing.T) {
+		app := NewRegistry("app", nil)
+		// 选项的应用顺序不影响结果
+		for _, rm := range []Mutex[string]{
+			m.With(WithRegistry(app), WithClient(other)),
+			m.With(WithClient(other), WithRegistry(app)),
+		} {
+			require.NoError(t, rm.Lock(ctx, "job"))
+			exists, err := other.Exists(ctx, "app:test-with-client").Result()
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), exists)
+			require.NoError(t, rm.Unlock(ctx, "job"))
+		}
+	})
+
+	t.Run("不影响其他互斥锁", func(t *testing.T) {
+		SetRedis(nil)
+		defer SetRedis(client)
+
+		acquired, err := om.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, om.Unlock(ctx, "job"))
+
+		_, err = m.TryLock(ctx, "job")
+		assert.ErrorIs(t, err, ErrRedisNotInitialized)
+	})
+}
@@ -0,0 +1,317 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the RWMutex type for distributed read-write locking.
+package sdm
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// waitingLease is how long a writer blocked on a RWMutex, or a fair waiter of a Mutex
+// (see WithFairness), stays registered as waiting after its last attempt. Waiters
+// retry at least every maxBackoff, so the registration only lapses once the waiter
+// has given up without unregistering.
+const waitingLease = 2 * maxBackoff
+
+// RWMutex represents a distributed reader/writer mutual exclusion lock.
+// The lock can be held by any number of readers or by a single writer,
+// which suits read-heavy shared resources.
+//
+// Like Mutex, the generic type parameter T specifies the type of the value
+// identifying the lock owner. Each value may hold at most one read lease.
+//
+// A RWMutex is configured with the same options as Mutex (see With), except for
+// WithReentrant which it ignores. WithWriterPreference only applies to RWMutex.
+// Its operations are traced like those of Mutex, as spans named after the method,
+// such as "sdm.RWMutex.RLock".
+type RWMutex[T any] struct {
+	name  string  // Unique identifier for the lock
+	title string  // Display title for the lock, used for logging and debugging
+	opts  options // Lock configuration, see Option
+}
+
+// rwKeys holds the Redis keys of a RWMutex
+type rwKeys struct {
+	writer  string // Sorted Set holding the lease of the writer
+	readers string // Sorted Set holding the leases of the readers
+	waiting string // Sorted Set of the writers waiting for the lock
+	channel string // Pub/Sub channel on which releases are published
+}
+
+// NewRWMutex creates a new distributed read-write lock with the given name and optional title.
+// The name must be a non-empty string that uniquely identifies the resource being locked.
+// A RWMutex does not share its state with a Mutex of the same name.
+//
+// Example:
+//
+//	m, err := sdm.NewRWMutex[string]("config:global", "global configuration lock")
+//	if err != nil {
+//	    return err
+//	}
+//	if err := m.RLock(ctx, "reader-1"); err != nil {
+//	    return err
+//	}
+//	defer m.RUnlock(ctx, "reader-1")
+//
+// Returns an error if the name is empty.
+func NewRWMutex[T any](name string, title ...string) (RWMutex[T], error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return RWMutex[T]{}, ErrMutexNameEmpty
+	}
+
+	ttl := strings.TrimSpace(cmp.Or(title...))
+	ttl = cmp.Or(ttl, name)
+
+	return RWMutex[T]{
+		name:  name,
+		title: ttl,
+	}, nil
+}
+
+// Name returns the unique identifier for this lock.
+func (m RWMutex[T]) Name() string {
+	return m.name
+}
+
+// Title returns the human-readable title for this lock.
+// If no title was provided when creating the lock, this returns the same as Name().
+func (m RWMutex[T]) Title() string {
+	return m.title
+}
+
+// With returns a copy of the lock configured with the given options.
+// The original lock is not modified.
+//
+// Example:
+//
+//	m = m.With(sdm.WithTTL(30*time.Second), sdm.WithWatchdog(), sdm.WithWriterPreference())
+func (m RWMutex[T]) With(opts ...Option) RWMutex[T] {
+	for _, opt := range opts {
+		opt(&m.opts)
+	}
+	return m
+}
+
+// TryRLock attempts to acquire the lock for reading with an optional timeout.
+// The read lock is available as long as no writer holds the lock, or waits for it
+// when the lock is configured with WithWriterPreference.
+//
+// If no timeout is given or it is zero, the call will not block. Otherwise it waits
+// for the lock to be released the same way as Mutex.TryLock.
+func (m RWMutex[T]) TryRLock(ctx context.Context, value T, timeout ...time.Duration) (bool, error) {
+	if len(timeout) == 0 || timeout[0] <= 0 {
+		return m.tryLock(ctx, value, false, 0)
+	}
+	return m.tryLock(ctx, value, false, timeout[0])
+}
+
+// RLock acquires the lock for reading, blocking until it is available or the context is cancelled.
+func (m RWMutex[T]) RLock(ctx context.Context, value T) error {
+	return m.lock(ctx, value, false)
+}
+
+// RUnlock releases a read lock acquired with the same value.
+// If the value does not hold a read lease, ErrMutexNotAcquired is returned.
+func (m RWMutex[T]) RUnlock(ctx context.Context, value T) error {
+	return m.unlock(ctx, value, false)
+}
+
+// TryLock attempts to acquire the lock for writing with an optional timeout.
+// The write lock is only available while no reader or other writer holds the lock.
+//
+// If no timeout is given or it is zero, the call will not block. Otherwise it waits
+// for the lock to be released the same way as Mutex.TryLock.
+func (m RWMutex[T]) TryLock(ctx context.Context, value T, timeout ...time.Duration) (bool, error) {
+	if len(timeout) == 0 || timeout[0] <= 0 {
+		return m.tryLock(ctx, value, true, 0)
+	}
+	return m.tryLock(ctx, value, true, timeout[0])
+}
+
+// Lock acquires the lock for writing, blocking until it is available or the context is cancelled.
+func (m RWMutex[T]) Lock(ctx context.Context, value T) error {
+	return m.lock(ctx, value, true)
+}
+
+// Unlock releases a write lock acquired with the same value.
+// If the value does not hold the write lease, ErrMutexNotAcquired is returned.
+func (m RWMutex[T]) Unlock(ctx context.Context, value T) error {
+	return m.unlock(ctx, value, true)
+}
+
+func (m RWMutex[T]) keys() (rwKeys, error) {
+	key, err := m.opts.storage().LockKey(m.name)
+	if err != nil {
+		return rwKeys{}, err
+	}
+	return rwKeys{
+		writer:  companionKey(key, "writer"),
+		readers: companionKey(key, "readers"),
+		waiting: companionKey(key, "waiting"),
+		channel: releaseChannel(companionKey(key, "rw")),
+	}, nil
+}
+
+func (m RWMutex[T]) lock(ctx context.Context, value T, write bool) error {
+	acquired, err := m.tryLock(ctx, value, write, -1)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		// This should theoretically not be reached, as negative timeout causes infinite retries
+		return errors.New("sdm: failed to acquire lock: unknown error")
+	}
+	return nil
+}
+
+// tryLock acquires the read or write lock. A zero timeout makes a single attempt,
+// a negative one waits until ctx is done.
+func (m RWMutex[T]) tryLock(ctx context.Context, value T, write bool, timeout time.Duration) (acquired bool, err error) {
+	op := "RLock"
+	if write {
+		op = "Lock"
+	}
+	if timeout >= 0 {
+		op = "Try" + op
+	}
+	ctx, span := startSpan(ctx, "RWMutex."+op, m.name)
+	defer func() { endSpan(span, err, attrAcquired.Bool(acquired)) }()
+
+	// Check if context is already cancelled
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	valstr, _, err := m.opts.encode(value)
+	if err != nil {
+		return false, fmt.Errorf("sdm: failed to serialize value: %w", err)
+	}
+
+	rdb, err := m.opts.storage().client()
+	if err != nil {
+		return false, err
+	}
+
+	keys, err := m.keys()
+	if err != nil {
+		return false, err
+	}
+
+	script, held := rLockScript, keys.readers
+	arg := "0"
+	if m.opts.writers {
+		arg = "1"
+	}
+	if write {
+		script, held = wLockScript, keys.writer
+		// Only blocking writers are registered as waiting
+		arg = "0"
+		if m.opts.writers && timeout != 0 {
+			arg = fmt.Sprint(waitingLease.Milliseconds())
+		}
+	}
+
+	attempt := func(actx context.Context) (bool, error) {
+		start := time.Now()
+		result, err := script.Run(actx, rdb, []string{keys.writer, keys.readers, keys.waiting}, valstr, m.opts.ttl.Milliseconds(), arg).Result()
+		if err != nil {
+			return false, fmt.Errorf("sdm: try lock failed: %w", err)
+		}
+		if result.(int64) != 1 {
+			return false, nil
+		}
+		lease := Lease{Key: held, Value: valstr, TTL: m.opts.ttl}
+		holdLease(m.name, m.name, m.opts.hooks, lease, start, m.opts.maxHold)
+		observeAcquired(m.name)
+		emit(eventAcquire, m.name, m.opts.hooks, lease, LockEvent{})
+		// The watchdog follows the caller's context
+		if m.opts.watchdog && m.opts.ttl > 0 {
+			startWatchdog(ctx, m.name, m.name, m.opts.hooks, NewRedisBackend(rdb), lease, m.opts.renewInterval())
+		}
+		return true, nil
+	}
+
+	if timeout == 0 {
+		acquired, err := attempt(ctx)
+		if !acquired {
+			observeFailure(m.name, 0, err)
+		}
+		return acquired, err
+	}
+
+	// Any release may free the lock for readers and writers alike
+	subscribe := func(ctx context.Context) <-chan string { return redisReleases(ctx, rdb, keys.channel) }
+	start := time.Now()
+	acquired, err = retry(ctx, subscribe, timeout, nil, attempt)
+	observeWait(m.name, start)
+	traceWait(ctx, start)
+	if !acquired {
+		observeFailure(m.name, timeout, err)
+	}
+	if write && m.opts.writers && !acquired {
+		// Stop holding back readers once the writer gives up
+		_ = unlockScript.Run(context.WithoutCancel(ctx), rdb, []string{keys.waiting}, valstr, keys.channel).Err()
+	}
+	return acquired, err
+}
+
+func (m RWMutex[T]) unlock(ctx context.Context, value T, write bool) (err error) {
+	op := "RWMutex.RUnlock"
+	if write {
+		op = "RWMutex.Unlock"
+	}
+	ctx, span := startSpan(ctx, op, m.name)
+	defer func() { endSpan(span, err, attrReleased.Bool(err == nil)) }()
+
+	valstr, _, err := m.opts.encode(value)
+	if err != nil {
+		return fmt.Errorf("sdm: failed to serialize value: %w", err)
+	}
+
+	rdb, err := m.opts.storage().client()
+	if err != nil {
+		return err
+	}
+
+	keys, err := m.keys()
+	if err != nil {
+		return err
+	}
+
+	held := keys.readers
+	if write {
+		held = keys.writer
+	}
+
+	// Stop renewing the lease before releasing it
+	stopWatchdog(held, valstr)
+
+	result, err := unlockScript.Run(ctx, rdb, []string{held}, valstr, keys.channel).Result()
+	if err != nil {
+		return fmt.Errorf("sdm: unlock failed: %w", err)
+	}
+
+	h := dropLease(leaseID{key: held, value: valstr})
+	if result.(int64) == 0 {
+		// A lease held by this process has been lost
+		if h != nil {
+			h.lose()
+		}
+		return ErrMutexNotAcquired
+	}
+
+	event := LockEvent{}
+	if h != nil {
+		h.release()
+		event.Held = h.heldFor()
+		observeReleased(m.name, event.Held)
+	}
+	emit(eventRelease, m.name, m.opts.hooks, Lease{Key: held, Value: valstr, TTL: m.opts.ttl}, event)
+	return nil
+}
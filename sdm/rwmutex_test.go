@@ -0,0 +1,226 @@
+package sdm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRWMutex(t *testing.T) {
+	t.Run("名称为空", func(t *testing.T) {
+		_, err := NewRWMutex[string]("  ")
+		assert.Equal(t, ErrMutexNameEmpty, err)
+	})
+
+	t.Run("默认标题", func(t *testing.T) {
+		m, err := NewRWMutex[string]("rw-test")
+		require.NoError(t, err)
+		assert.Equal(t, "rw-test", m.Name())
+		assert.Equal(t, "rw-test", m.Title())
+
+		m, err = NewRWMutex[string]("rw-test", "读写锁")
+		require.NoError(t, err)
+		assert.Equal(t, "读写锁", m.Title())
+	})
+}
+
+func TestRWMutex(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	m, err := NewRWMutex[string]("test-rwmutex")
+	require.NoError(t, err)
+
+	t.Run("多个读者共享", func(t *testing.T) {
+		require.NoError(t, m.RLock(ctx, "reader-1"))
+		require.NoError(t, m.RLock(ctx, "reader-2"))
+
+		// 有读者时不能获取写锁
+		acquired, err := m.TryLock(ctx, "writer")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		require.NoError(t, m.RUnlock(ctx, "reader-1"))
+		require.NoError(t, m.RUnlock(ctx, "reader-2"))
+		assert.Equal(t, ErrMutexNotAcquired, m.RUnlock(ctx, "reader-1"))
+
+		acquired, err = m.TryLock(ctx, "writer")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, m.Unlock(ctx, "writer"))
+	})
+
+	t.Run("写者独占", func(t *testing.T) {
+		require.NoError(t, m.Lock(ctx, "writer-1"))
+
+		acquired, err := m.TryRLock(ctx, "reader")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		acquired, err = m.TryLock(ctx, "writer-2")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		// 读锁不能释放写锁
+		assert.Equal(t, ErrMutexNotAcquired, m.RUnlock(ctx, "writer-1"))
+		require.NoError(t, m.Unlock(ctx, "writer-1"))
+	})
+
+	t.Run("释放写锁后唤醒读者", func(t *testing.T) {
+		require.NoError(t, m.Lock(ctx, "writer"))
+
+		done := make(chan error, 1)
+		go func() {
+			done <- m.RLock(ctx, "reader")
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("写锁被持有时 RLock 不应该返回")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		require.NoError(t, m.Unlock(ctx, "writer"))
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(maxBackoff / 2):
+			t.Fatal("释放写锁后读者应该被唤醒")
+		}
+		require.NoError(t, m.RUnlock(ctx, "reader"))
+	})
+
+	t.Run("超时返回 false", func(t *testing.T) {
+		require.NoError(t, m.RLock(ctx, "reader"))
+		defer m.RUnlock(ctx, "reader")
+
+		acquired, err := m.TryLock(ctx, "writer", 50*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+	})
+
+	t.Run("不与同名的互斥锁共享状态", func(t *testing.T) {
+		mutex, err := New[string]("test-rwmutex")
+		require.NoError(t, err)
+		require.NoError(t, mutex.Lock(ctx, "owner"))
+		defer mutex.Unlock(ctx, "owner")
+
+		acquired, err := m.TryLock(ctx, "owner")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, m.Unlock(ctx, "owner"))
+	})
+}
+
+func TestRWMutex_WriterPreference(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	m, err := NewRWMutex[string]("test-rwmutex-writers")
+	require.NoError(t, err)
+
+	t.Run("默认读者优先", func(t *testing.T) {
+		require.NoError(t, m.RLock(ctx, "reader-1"))
+
+		acquired, err := m.TryLock(ctx, "writer", 50*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		acquired, err = m.TryRLock(ctx, "reader-2")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		require.NoError(t, m.RUnlock(ctx, "reader-1"))
+		require.NoError(t, m.RUnlock(ctx, "reader-2"))
+	})
+
+	t.Run("等待的写者阻止新读者", func(t *testing.T) {
+		wm := m.With(WithWriterPreference())
+		require.NoError(t, wm.RLock(ctx, "reader-1"))
+
+		done := make(chan error, 1)
+		go func() {
+			done <- wm.Lock(ctx, "writer")
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		acquired, err := wm.TryRLock(ctx, "reader-2")
+		require.NoError(t, err)
+		assert.False(t, acquired, "有写者等待时新读者不能获取读锁")
+
+		require.NoError(t, wm.RUnlock(ctx, "reader-1"))
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(maxBackoff / 2):
+			t.Fatal("读者释放后写者应该获取到锁")
+		}
+		require.NoError(t, wm.Unlock(ctx, "writer"))
+
+		acquired, err = wm.TryRLock(ctx, "reader-2")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, wm.RUnlock(ctx, "reader-2"))
+	})
+
+	t.Run("写者放弃后不再阻止读者", func(t *testing.T) {
+		wm := m.With(WithWriterPreference())
+		require.NoError(t, wm.RLock(ctx, "reader-1"))
+		defer wm.RUnlock(ctx, "reader-1")
+
+		acquired, err := wm.TryLock(ctx, "writer", 50*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		acquired, err = wm.TryRLock(ctx, "reader-2")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, wm.RUnlock(ctx, "reader-2"))
+	})
+}
+
+func TestRWMutex_Watchdog(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	m, err := NewRWMutex[string]("test-rwmutex-watchdog")
+	require.NoError(t, err)
+	m = m.With(WithTTL(150*time.Millisecond), WithWatchdog(30*time.Millisecond))
+
+	require.NoError(t, m.RLock(ctx, "reader"))
+	time.Sleep(300 * time.Millisecond)
+
+	acquired, err := m.TryLock(ctx, "writer")
+	require.NoError(t, err)
+	assert.False(t, acquired, "看门狗应该续期读锁")
+
+	require.NoError(t, m.RUnlock(ctx, "reader"))
+	acquired, err = m.TryLock(ctx, "writer")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	require.NoError(t, m.Unlock(ctx, "writer"))
+}
@@ -0,0 +1,126 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the runner of the Lua scripts, which survives script cache flushes
+// and failovers.
+package sdm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	failoverTimeout    = 5 * time.Second        // Time during which scripts are retried while Redis fails over
+	failoverMinBackoff = 10 * time.Millisecond  // Delay before the first retry of a script
+	failoverMaxBackoff = 500 * time.Millisecond // Maximum delay between the retries of a script
+)
+
+// script is a Lua script run by the package. Like redis.Script, it runs with EVALSHA
+// and falls back to EVAL, which also caches the script again, when Redis replies
+// NOSCRIPT, such as after a restart, a SCRIPT FLUSH or a failover to a replica whose
+// cache was never populated.
+//
+// Unlike redis.Script, it also retries the scripts rejected while Redis fails over,
+// for up to failoverTimeout. Only the replies guaranteeing that the script did not
+// run are retried (see isFailoverError), so scripts with side effects, such as the
+// counter increments, are never applied twice.
+type script struct {
+	*redis.Script
+}
+
+// scripts holds every script of the package, registered during the package
+// initialization and loaded by PreloadScripts
+var scripts []*script
+
+// newScript creates the script of the given source and registers it for PreloadScripts
+func newScript(src string) *script {
+	s := &script{redis.NewScript(src)}
+	scripts = append(scripts, s)
+	return s
+}
+
+// Run runs the script on c, reloading it if Redis lost it and retrying it while
+// Redis fails over.
+func (s *script) Run(ctx context.Context, c redis.Scripter, keys []string, args ...any) *redis.Cmd {
+	var deadline time.Time
+	backoff := failoverMinBackoff
+	for {
+		cmd := s.Script.Run(ctx, c, keys, args...)
+		if !isFailoverError(cmd.Err()) {
+			return cmd
+		}
+
+		if deadline.IsZero() {
+			deadline = time.Now().Add(failoverTimeout)
+		}
+		wait := min(backoff, time.Until(deadline))
+		if wait <= 0 {
+			return cmd
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return cmd
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, failoverMaxBackoff)
+	}
+}
+
+// isFailoverError reports whether err is a reply of a Redis server refusing to run a
+// script while it fails over, which guarantees that the script did not run: the
+// server is a replica not yet promoted (READONLY), is loading its dataset (LOADING),
+// lost its master (MASTERDOWN), or the cluster is reconfiguring (CLUSTERDOWN,
+// TRYAGAIN). Network errors are not, since the script may have run before the
+// connection broke.
+func isFailoverError(err error) bool {
+	for _, prefix := range []string{"READONLY", "LOADING", "MASTERDOWN", "CLUSTERDOWN", "TRYAGAIN"} {
+		if redis.HasErrorPrefix(err, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PreloadScripts loads the Lua scripts of the package into the script cache of the
+// given Redis client, or of the client set with SetRedis if nil, so that the first
+// calls do not pay for the NOSCRIPT round trip. Cluster clients load the scripts on
+// every master.
+//
+// SetRedis preloads the scripts in the background. Scripts missing from the cache
+// are loaded again on demand anyway, so preloading is only an optimization, useful
+// for instance with the clients passed to NewRegistry and WithClient, or after a
+// failover.
+//
+// Example:
+//
+//	if err := sdm.PreloadScripts(ctx, euRedis); err != nil {
+//	    log.Printf("failed to preload lock scripts: %v", err)
+//	}
+func PreloadScripts(ctx context.Context, client redis.Scripter) error {
+	if client == nil {
+		var err error
+		if client, err = db(); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range scripts {
+		if err := s.Load(ctx, client).Err(); err != nil {
+			return fmt.Errorf("sdm: failed to load script: %w", err)
+		}
+	}
+	return nil
+}
+
+// preloadScripts preloads the scripts into client in the background, see SetRedis
+func preloadScripts(client redis.Scripter) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), failoverTimeout)
+		defer cancel()
+		_ = PreloadScripts(ctx, client)
+	}()
+}
@@ -0,0 +1,133 @@
+package sdm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replyError 模拟 Redis 服务器的错误回复
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+func (replyError) RedisError()     {}
+
+// failingScripter 在前 failures 次执行脚本时返回 err
+type failingScripter struct {
+	redis.Scripter
+	err      error
+	failures atomic.Int32
+	calls    atomic.Int32
+}
+
+func (s *failingScripter) EvalSha(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+	s.calls.Add(1)
+	if s.failures.Add(-1) >= 0 {
+		cmd := redis.NewCmd(ctx)
+		cmd.SetErr(s.err)
+		return cmd
+	}
+	return s.Scripter.EvalSha(ctx, sha1, keys, args...)
+}
+
+func TestScript_Run(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	key := "test-script"
+
+	t.Run("脚本缓存清空后重新加载", func(t *testing.T) {
+		require.NoError(t, client.ScriptFlush(ctx).Err())
+		n, err := incrScript.Run(ctx, client, []string{key}, 1).Int64()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		exists, err := incrScript.Exists(ctx, client).Result()
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true}, exists)
+	})
+
+	t.Run("故障转移期间重试", func(t *testing.T) {
+		for _, reply := range []string{
+			"READONLY You can't write against a read only replica.",
+			"LOADING Redis is loading the dataset in memory",
+			"MASTERDOWN Link with MASTER is down",
+		} {
+			s := &failingScripter{Scripter: client, err: replyError(reply)}
+			s.failures.Store(2)
+			before, err := client.Get(ctx, key).Int64()
+			require.NoError(t, err)
+
+			n, err := incrScript.Run(ctx, s, []string{key}, 1).Int64()
+			require.NoError(t, err, reply)
+			assert.Equal(t, before+1, n)
+			assert.Equal(t, int32(3), s.calls.Load())
+		}
+	})
+
+	t.Run("其他错误不重试", func(t *testing.T) {
+		for _, err := range []error{replyError("ERR unknown command"), errors.New("connection reset by peer")} {
+			s := &failingScripter{Scripter: client, err: err}
+			s.failures.Store(1)
+			_, rerr := incrScript.Run(ctx, s, []string{key}, 1).Int64()
+			assert.Equal(t, err, rerr)
+			assert.Equal(t, int32(1), s.calls.Load())
+		}
+	})
+
+	t.Run("上下文结束时停止重试", func(t *testing.T) {
+		s := &failingScripter{Scripter: client, err: replyError("READONLY You can't write against a read only replica.")}
+		s.failures.Store(1000)
+
+		cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := incrScript.Run(cctx, s, []string{key}, 1).Int64()
+		assert.True(t, redis.HasErrorPrefix(err, "READONLY"))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestPreloadScripts(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+	require.NoError(t, PreloadScripts(ctx, client))
+
+	hashes := make([]string, len(scripts))
+	for i, s := range scripts {
+		hashes[i] = s.Hash()
+	}
+	exists, err := client.ScriptExists(ctx, hashes...).Result()
+	require.NoError(t, err)
+	assert.NotContains(t, exists, false)
+	assert.Len(t, exists, len(scripts))
+
+	SetRedis(nil)
+	assert.ErrorIs(t, PreloadScripts(ctx, nil), ErrRedisNotInitialized)
+
+	// SetRedis 在后台预加载脚本
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+	SetRedis(client)
+	assert.Eventually(t, func() bool {
+		exists, err := tryLockScript.Exists(ctx, client).Result()
+		return err == nil && exists[0]
+	}, time.Second, 10*time.Millisecond)
+}
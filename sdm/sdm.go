@@ -44,12 +44,23 @@ import (
 var (
 	// ErrMutexNameEmpty is returned when attempting to create a mutex with an empty name
 	ErrMutexNameEmpty = errors.New("sdm: mutex name cannot be empty")
+	// ErrMutexNameInvalid is returned when the key of a mutex contains a "}" outside
+	// of a Redis Cluster hash tag, such as "orders}" or "{}orders", so that its
+	// companion keys could not be kept in its slot
+	ErrMutexNameInvalid = errors.New("sdm: mutex name has a malformed hash tag")
 	// ErrInvalidMutexValue is returned when the mutex value is invalid (empty or serialization failed)
 	ErrInvalidMutexValue = errors.New("sdm: invalid mutex value")
 	// ErrMutexNotAcquired is returned when the lock cannot be acquired within the specified timeout
 	ErrMutexNotAcquired = errors.New("sdm: failed to acquire mutex")
+	// ErrExtendNotSupported is returned by Mutex.Extend when the backend of the mutex
+	// cannot extend a lease by any duration (see LeaseExtender)
+	ErrExtendNotSupported = errors.New("sdm: backend does not support lease extension")
+	// ErrLeaseWatched is returned by Mutex.Extend when the lease is renewed by a
+	// watchdog of this process (see WithWatchdog)
+	ErrLeaseWatched = errors.New("sdm: lease is renewed by a watchdog")
 
-	// RedisKeyPrefix storage prefix, should only be specified during initialization
+	// RedisKeyPrefix storage prefix of the default registry (see Registry), should only
+	// be specified during initialization
 	RedisKeyPrefix = "mutex"
 	// DefaultMutexName global mutex name, should only be specified during initialization
 	DefaultMutexName = "default"
@@ -57,10 +68,37 @@ var (
 	// Global default mutex object
 	mtx *Mutex[any]
 
-	rdb atomic.Value // redis.Scripter
+	rdb scripterValue
 	sfg singleflight.Group
 )
 
+// scripterValue holds the Redis client of the package. Unlike a bare atomic.Value,
+// it accepts clients of different types over time, such as a *redis.Client replaced
+// by a *redis.ClusterClient, as well as nil.
+type scripterValue struct {
+	v atomic.Value // scripterBox
+}
+
+// scripterBox gives every stored client the same concrete type
+type scripterBox struct {
+	s redis.Scripter
+}
+
+// Load returns the stored client, or nil if none is stored.
+func (v *scripterValue) Load() any {
+	b, _ := v.v.Load().(scripterBox)
+	if b.s == nil {
+		return nil
+	}
+	return b.s
+}
+
+// Store stores the client s, which must implement redis.Scripter or be nil.
+func (v *scripterValue) Store(s any) {
+	c, _ := s.(redis.Scripter)
+	v.v.Store(scripterBox{s: c})
+}
+
 // init initializes the default mutex instance with default values.
 // This function is automatically called when the package is imported.
 // It creates a default mutex instance that can be used with the package-level
@@ -76,19 +114,40 @@ func init() {
 }
 
 // SetRedis sets the Redis client to be used by the package for distributed locking.
-// This function must be called before any lock operations are performed.
+// This function must be called before any lock operations are performed, except by
+// the mutexes bound to a Registry with its own client (see NewRegistry).
 //
 // The provided client must implement the redis.Scripter interface, which is satisfied
-// by both *redis.Client and *redis.ClusterClient from github.com/redis/go-redis/v9.
+// by every redis.UniversalClient from github.com/redis/go-redis/v9: *redis.Client,
+// *redis.ClusterClient, *redis.Ring and the Sentinel backed client returned by
+// redis.NewFailoverClient. Clients supporting Pub/Sub also wake up waiting lockers
+// as soon as a lock is released (see Mutex.TryLock).
+//
+// In Redis Cluster, all the keys of a lock hash to the slot of its main key
+// "<RedisKeyPrefix>:<name>", so the scripts operating on them stay single-slot.
+// Use a hash tag in the name, such as "{user:123}:profile", to keep the locks of
+// related resources in the same slot.
 //
 // Example:
 //
 //	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
 //	sdm.SetRedis(rdb)
 //
+//	// Redis Cluster or Sentinel
+//	sdm.SetRedis(redis.NewUniversalClient(&redis.UniversalOptions{
+//	    Addrs: []string{"node-1:6379", "node-2:6379", "node-3:6379"},
+//	}))
+//
+// The Lua scripts of the package are loaded into the client in the background (see
+// PreloadScripts), and scripts rejected while Redis fails over are retried for a few
+// seconds, so the first calls after a restart or a failover do not fail.
+//
 // Note: This function is safe to call concurrently.
 func SetRedis(v redis.Scripter) {
 	rdb.Store(v)
+	if c, err := db(); err == nil {
+		preloadScripts(c)
+	}
 }
 
 // TryLock attempts to acquire the default mutex lock with an optional timeout.
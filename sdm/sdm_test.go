@@ -3,6 +3,7 @@ package sdm
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -276,13 +277,16 @@ func TestConcurrentAccess(t *testing.T) {
 	successCount := 0
 	ch := make(chan bool, numGoroutines)
 
+	// 持有锁的 goroutine 等所有尝试都返回后才释放，避免较慢的 goroutine 在释放后再次获取
+	var attempts sync.WaitGroup
+	attempts.Add(numGoroutines)
 	for i := 0; i < numGoroutines; i++ {
 		go func(id int) {
 			acquired, err := mutex.TryLock(ctx, value)
+			attempts.Done()
 			if err == nil && acquired {
 				ch <- true
-				// 模拟一些工作
-				time.Sleep(10 * time.Millisecond)
+				attempts.Wait()
 				mutex.Unlock(ctx, value)
 			} else {
 				ch <- false
@@ -363,6 +367,12 @@ func TestIsLocked(t *testing.T) {
 
 	SetRedis(client)
 
+	// 保存全局互斥锁，测试结束后恢复
+	originalMtx := mtx
+	defer func() {
+		mtx = originalMtx
+	}()
+
 	tests := []struct {
 		name      string
 		setupFunc func(*testing.T)
@@ -0,0 +1,367 @@
+// Package sdmetcd provides an etcd backend for the distributed mutexes of package sdm.
+//
+// Each lease is stored at its own etcd key "<lock key>\x00<value>", whose value holds
+// the hold count of a reentrant lease and the metadata of its holder. It is attached
+// to an etcd lease when the mutex has a TTL, so that it is deleted once the TTL elapses
+// without renewal. etcd leases have a granularity of one second and a server-side
+// minimum, so TTLs are rounded up accordingly. Waiting lockers watch the deletions
+// of the leases of the lock and retry as soon as one is released or expires.
+//
+// Example:
+//
+//	client, err := clientv3.New(clientv3.Config{Endpoints: []string{"localhost:2379"}})
+//	if err != nil {
+//	    return err
+//	}
+//	m, _ := sdm.New[string]("orders")
+//	m = m.With(sdm.WithBackend(sdmetcd.New(client)), sdm.WithTTL(30*time.Second), sdm.WithWatchdog())
+package sdmetcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-slim.dev/infra/sdm"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// separator separates the lock key from the value in the etcd key of a lease.
+// Lock names do not contain it, so the leases of one lock are never mistaken
+// for those of another lock whose name extends it.
+const separator = "\x00"
+
+// Backend is the sdm.LockBackend storing leases in etcd.
+type Backend struct {
+	client *clientv3.Client
+}
+
+var (
+	_ sdm.LockBackend    = (*Backend)(nil)
+	_ sdm.ReleaseWatcher = (*Backend)(nil)
+	_ sdm.LockAdmin      = (*Backend)(nil)
+)
+
+// New creates an etcd backend using the given client.
+func New(client *clientv3.Client) *Backend {
+	return &Backend{client: client}
+}
+
+// leaseKey returns the etcd key of the lease of value on the lock stored at key
+func leaseKey(key, value string) string {
+	return key + separator + value
+}
+
+// holdValue encodes the hold count of a reentrant lease as "<count>:<owner>",
+// the leases acquired without owner store an empty value
+func holdValue(holds int, owner string) string {
+	if owner == "" {
+		return ""
+	}
+	return strconv.Itoa(holds) + ":" + owner
+}
+
+// parseHold decodes a value encoded by holdValue
+func parseHold(v []byte) (holds int, owner string) {
+	count, owner, ok := strings.Cut(string(v), ":")
+	if !ok {
+		return 0, ""
+	}
+	holds, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, ""
+	}
+	return holds, owner
+}
+
+// leaseValue encodes the etcd value of a lease, its hold count (see holdValue)
+// followed by the JSON metadata of its holder, if any
+func leaseValue(hold string, md []byte) string {
+	if len(md) == 0 {
+		return hold
+	}
+	return hold + separator + string(md)
+}
+
+// splitLease splits an etcd value encoded by leaseValue
+func splitLease(v []byte) (hold, md []byte) {
+	hold, md, _ = bytes.Cut(v, []byte(separator))
+	return hold, md
+}
+
+// Acquire implements sdm.LockBackend.
+func (b *Backend) Acquire(ctx context.Context, lease sdm.Lease) (sdm.LeaseOutcome, error) {
+	k := leaseKey(lease.Key, lease.Value)
+
+	var md []byte
+	if lease.Metadata != nil {
+		md, _ = json.Marshal(lease.Metadata)
+	}
+
+	var opts []clientv3.OpOption
+	var id clientv3.LeaseID
+	if lease.TTL > 0 {
+		grant, err := b.client.Grant(ctx, int64(math.Ceil(lease.TTL.Seconds())))
+		if err != nil {
+			return sdm.LeaseUnchanged, fmt.Errorf("sdmetcd: failed to grant lease: %w", err)
+		}
+		id = grant.ID
+		opts = append(opts, clientv3.WithLease(id))
+	}
+
+	resp, err := b.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(k), "=", 0)).
+		Then(clientv3.OpPut(k, leaseValue(holdValue(1, lease.Owner), md), opts...)).
+		Else(clientv3.OpGet(k)).
+		Commit()
+	if err != nil {
+		b.revoke(ctx, id)
+		return sdm.LeaseUnchanged, fmt.Errorf("sdmetcd: try lock failed: %w", err)
+	}
+	if resp.Succeeded {
+		return sdm.LeaseChanged, nil
+	}
+
+	// The value already holds a lease, which only its owner may reenter
+	b.revoke(ctx, id)
+	kvs := resp.Responses[0].GetResponseRange().GetKvs()
+	if lease.Owner == "" || len(kvs) == 0 {
+		return sdm.LeaseUnchanged, nil
+	}
+	hold, held := splitLease(kvs[0].Value)
+	holds, owner := parseHold(hold)
+	if owner != lease.Owner {
+		return sdm.LeaseUnchanged, nil
+	}
+
+	// Keep the metadata of the outermost acquisition
+	resp, err = b.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(k), "=", kvs[0].ModRevision)).
+		Then(clientv3.OpPut(k, leaseValue(holdValue(holds+1, owner), held), clientv3.WithIgnoreLease())).
+		Commit()
+	if err != nil {
+		return sdm.LeaseUnchanged, fmt.Errorf("sdmetcd: try lock failed: %w", err)
+	}
+	if !resp.Succeeded {
+		// Released or reentered concurrently, the caller retries
+		return sdm.LeaseUnchanged, nil
+	}
+	if kvs[0].Lease != 0 {
+		_, _ = b.client.KeepAliveOnce(ctx, clientv3.LeaseID(kvs[0].Lease))
+	}
+	return sdm.LeaseNested, nil
+}
+
+// revoke revokes an etcd lease granted for an acquisition that failed
+func (b *Backend) revoke(ctx context.Context, id clientv3.LeaseID) {
+	if id != clientv3.NoLease {
+		_, _ = b.client.Revoke(context.WithoutCancel(ctx), id)
+	}
+}
+
+// Release implements sdm.LockBackend.
+func (b *Backend) Release(ctx context.Context, lease sdm.Lease) (sdm.LeaseOutcome, error) {
+	k := leaseKey(lease.Key, lease.Value)
+
+	// Retry while the lease is modified concurrently, such as by a reentrant acquisition
+	for {
+		get, err := b.client.Get(ctx, k)
+		if err != nil {
+			return sdm.LeaseUnchanged, fmt.Errorf("sdmetcd: unlock failed: %w", err)
+		}
+		if len(get.Kvs) == 0 {
+			return sdm.LeaseUnchanged, nil
+		}
+		kv := get.Kvs[0]
+		unchanged := clientv3.Compare(clientv3.ModRevision(k), "=", kv.ModRevision)
+
+		// A reentrant lock is only released once its hold count drops to zero
+		hold, md := splitLease(kv.Value)
+		if holds, owner := parseHold(hold); lease.Owner != "" && owner == lease.Owner && holds > 1 {
+			resp, err := b.client.Txn(ctx).
+				If(unchanged).
+				Then(clientv3.OpPut(k, leaseValue(holdValue(holds-1, owner), md), clientv3.WithIgnoreLease())).
+				Commit()
+			if err != nil {
+				return sdm.LeaseUnchanged, fmt.Errorf("sdmetcd: unlock failed: %w", err)
+			}
+			if resp.Succeeded {
+				return sdm.LeaseNested, nil
+			}
+			continue
+		}
+
+		resp, err := b.client.Txn(ctx).If(unchanged).Then(clientv3.OpDelete(k)).Commit()
+		if err != nil {
+			return sdm.LeaseUnchanged, fmt.Errorf("sdmetcd: unlock failed: %w", err)
+		}
+		if resp.Succeeded {
+			b.revoke(ctx, clientv3.LeaseID(kv.Lease))
+			return sdm.LeaseChanged, nil
+		}
+	}
+}
+
+// Renew implements sdm.LockBackend. The lease is extended by the TTL it was
+// acquired with, as etcd leases cannot change their TTL.
+func (b *Backend) Renew(ctx context.Context, lease sdm.Lease) (bool, error) {
+	get, err := b.client.Get(ctx, leaseKey(lease.Key, lease.Value))
+	if err != nil {
+		return false, fmt.Errorf("sdmetcd: renew failed: %w", err)
+	}
+	if len(get.Kvs) == 0 {
+		return false, nil
+	}
+	if get.Kvs[0].Lease == 0 {
+		// The lease never expires
+		return true, nil
+	}
+
+	_, err = b.client.KeepAliveOnce(ctx, clientv3.LeaseID(get.Kvs[0].Lease))
+	if errors.Is(err, rpctypes.ErrLeaseNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("sdmetcd: renew failed: %w", err)
+	}
+	return true, nil
+}
+
+// IsLocked implements sdm.LockBackend.
+func (b *Backend) IsLocked(ctx context.Context, key string) (bool, error) {
+	resp, err := b.client.Get(ctx, key+separator, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return false, fmt.Errorf("sdmetcd: failed to check lock status: %w", err)
+	}
+	return resp.Count > 0, nil
+}
+
+// Holders implements sdm.LeaseInspector. The expiration time of a lease is
+// estimated from the remaining TTL of its etcd lease, and its fencing token is
+// the revision at which it was created.
+func (b *Backend) Holders(ctx context.Context, key string) ([]sdm.Holder, error) {
+	prefix := key + separator
+	resp, err := b.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("sdmetcd: failed to inspect lock: %w", err)
+	}
+
+	holders := make([]sdm.Holder, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		h := sdm.Holder{Value: strings.TrimPrefix(string(kv.Key), prefix), Holds: 1, Token: kv.CreateRevision}
+		hold, md := splitLease(kv.Value)
+		if holds, owner := parseHold(hold); owner != "" {
+			h.Holds, h.Owner = holds, owner
+		}
+		if len(md) > 0 {
+			_ = json.Unmarshal(md, &h.Metadata)
+		}
+
+		if kv.Lease != 0 {
+			ttl, err := b.client.TimeToLive(ctx, clientv3.LeaseID(kv.Lease))
+			if err != nil {
+				return nil, fmt.Errorf("sdmetcd: failed to inspect lock: %w", err)
+			}
+			if ttl.TTL <= 0 {
+				// Expired since the lease was read
+				continue
+			}
+			h.ExpiresAt = time.Now().Add(time.Duration(ttl.TTL) * time.Second)
+		}
+		holders = append(holders, h)
+	}
+	return holders, nil
+}
+
+// Locks implements sdm.LockAdmin by listing the leases under the literal prefix
+// of pattern and matching their lock keys against it.
+func (b *Backend) Locks(ctx context.Context, pattern string) ([]string, error) {
+	prefix := pattern
+	if i := strings.IndexAny(pattern, "*?[\\"); i >= 0 {
+		prefix = pattern[:i]
+	}
+	resp, err := b.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, fmt.Errorf("sdmetcd: failed to list locks: %w", err)
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, kv := range resp.Kvs {
+		key, _, ok := strings.Cut(string(kv.Key), separator)
+		if ok && !seen[key] && sdm.MatchPattern(pattern, key) {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// ForceRelease implements sdm.LockAdmin.
+func (b *Backend) ForceRelease(ctx context.Context, key string, token int64) (bool, error) {
+	resp, err := b.client.Get(ctx, key+separator, clientv3.WithPrefix())
+	if err != nil {
+		return false, fmt.Errorf("sdmetcd: force unlock failed: %w", err)
+	}
+
+	for _, kv := range resp.Kvs {
+		if kv.CreateRevision != token {
+			continue
+		}
+		k := string(kv.Key)
+		txn, err := b.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(k), "=", token)).
+			Then(clientv3.OpDelete(k)).
+			Commit()
+		if err != nil {
+			return false, fmt.Errorf("sdmetcd: force unlock failed: %w", err)
+		}
+		if txn.Succeeded {
+			b.revoke(ctx, clientv3.LeaseID(kv.Lease))
+		}
+		return txn.Succeeded, nil
+	}
+	return false, nil
+}
+
+// WatchReleases implements sdm.ReleaseWatcher by watching the deletions of the
+// leases of key, whether released or expired.
+func (b *Backend) WatchReleases(ctx context.Context, key string) (<-chan string, error) {
+	prefix := key + separator
+	wch := b.client.Watch(ctx, prefix, clientv3.WithPrefix(), clientv3.WithFilterPut(), clientv3.WithCreatedNotify())
+
+	// Wait for the watch to be created, so that no release happening afterwards is missed
+	select {
+	case resp, ok := <-wch:
+		if !ok {
+			return nil, ctx.Err()
+		}
+		if err := resp.Err(); err != nil {
+			return nil, fmt.Errorf("sdmetcd: failed to watch %s: %w", key, err)
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	released := make(chan string)
+	go func() {
+		defer close(released)
+		for resp := range wch {
+			for _, ev := range resp.Events {
+				select {
+				case released <- strings.TrimPrefix(string(ev.Kv.Key), prefix):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return released, nil
+}
@@ -0,0 +1,169 @@
+package sdmetcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-slim.dev/infra/sdm"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// setupTestEtcd 创建测试用的 etcd 客户端
+// 注意：这些测试需要一个运行中的 etcd 实例
+func setupTestEtcd(t *testing.T) *clientv3.Client {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{"localhost:2379"},
+		DialTimeout: time.Second,
+	})
+	if err != nil {
+		t.Skip("etcd 不可用，跳过测试")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.Status(ctx, "localhost:2379"); err != nil {
+		client.Close()
+		t.Skip("etcd 不可用，跳过测试")
+		return nil
+	}
+
+	// 清理测试数据
+	_, _ = client.Delete(ctx, sdm.RedisKeyPrefix+":sdmetcd-test", clientv3.WithPrefix())
+	return client
+}
+
+func TestHold(t *testing.T) {
+	assert.Equal(t, "", holdValue(1, ""))
+	assert.Equal(t, "2:owner", holdValue(2, "owner"))
+
+	holds, owner := parseHold([]byte("2:host:1:owner"))
+	assert.Equal(t, 2, holds)
+	assert.Equal(t, "host:1:owner", owner)
+
+	holds, owner = parseHold(nil)
+	assert.Zero(t, holds)
+	assert.Empty(t, owner)
+
+	hold, md := splitLease([]byte(leaseValue(holdValue(2, "owner"), []byte(`{"pid":1}`))))
+	assert.Equal(t, "2:owner", string(hold))
+	assert.Equal(t, `{"pid":1}`, string(md))
+
+	hold, md = splitLease([]byte(leaseValue("", nil)))
+	assert.Empty(t, hold)
+	assert.Empty(t, md)
+}
+
+func TestBackendExtend(t *testing.T) {
+	// etcd 租约的 TTL 在授予后无法更改，因此不支持 Mutex.Extend
+	_, ok := any(&Backend{}).(sdm.LeaseExtender)
+	assert.False(t, ok)
+}
+
+func TestBackend(t *testing.T) {
+	client := setupTestEtcd(t)
+	if client == nil {
+		return
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	b := New(client)
+
+	mutex, err := sdm.New[string]("sdmetcd-test")
+	require.NoError(t, err)
+	mutex = mutex.With(sdm.WithBackend(b))
+
+	t.Run("获取与释放", func(t *testing.T) {
+		acquired, err := mutex.TryLock(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		acquired, err = mutex.TryLock(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		locked, err := mutex.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+		assert.Equal(t, sdm.ErrMutexNotAcquired, mutex.Unlock(ctx, "owner-1"))
+	})
+
+	t.Run("持有者信息", func(t *testing.T) {
+		m := mutex.With(sdm.WithReentrant("owner-a"), sdm.WithLabels(map[string]string{"job": "report"}))
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Lock(ctx, "job"))
+
+		info, err := m.Info(ctx)
+		require.NoError(t, err)
+		require.Len(t, info.Holders, 1)
+		assert.Equal(t, "owner-a", info.Holders[0].Owner)
+		assert.Equal(t, 2, info.Holders[0].Holds)
+		assert.Equal(t, "report", info.Holders[0].Labels["job"])
+
+		require.NoError(t, m.Unlock(ctx, "job"))
+		require.NoError(t, m.Unlock(ctx, "job"))
+	})
+
+	t.Run("可重入", func(t *testing.T) {
+		m := mutex.With(sdm.WithReentrant("owner-a"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		require.NoError(t, m.Unlock(ctx, "job"))
+
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		require.NoError(t, m.Unlock(ctx, "job"))
+		locked, err = m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("租约过期", func(t *testing.T) {
+		m := mutex.With(sdm.WithTTL(time.Second))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+
+		assert.Eventually(t, func() bool {
+			locked, err := m.IsLocked(ctx)
+			return err == nil && !locked
+		}, 10*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("不支持按任意时长续期", func(t *testing.T) {
+		// etcd 租约的 TTL 在授予后无法更改
+		m := mutex.With(sdm.WithTTL(time.Second))
+		require.NoError(t, m.Lock(ctx, "owner-1"))
+		defer m.Unlock(ctx, "owner-1")
+
+		assert.Equal(t, sdm.ErrExtendNotSupported, m.Extend(ctx, "owner-1", time.Minute))
+		mine, err := m.IsLockedBy(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.True(t, mine)
+	})
+
+	t.Run("释放后唤醒等待者", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+
+		done := make(chan error, 1)
+		go func() {
+			done <- mutex.Lock(ctx, "owner-1")
+		}()
+		time.Sleep(100 * time.Millisecond)
+
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(500 * time.Millisecond):
+			t.Fatal("释放后等待者应该被唤醒")
+		}
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+	})
+}
@@ -0,0 +1,186 @@
+// Package sdmpg provides a PostgreSQL backend for the distributed mutexes of package sdm,
+// built on session-level advisory locks.
+//
+// Each lease takes the advisory lock identified by the hashes of the lock key and
+// of the value, on a connection the backend keeps out of the pool until the lease
+// is released. The lease ends with the database session, such as when the process
+// exits or the connection is lost, so it does not expire with a TTL: WithTTL is
+// ignored and the watchdog (see sdm.WithWatchdog) only checks that the connection
+// holding the lease is still alive.
+//
+// Advisory locks are identified by two 32-bit integers, so locks or values whose
+// hashes collide exclude each other. Waiting lockers poll, as the backend does not
+// notify releases.
+//
+// Example:
+//
+//	db, err := sql.Open("pgx", "postgres://localhost:5432/app")
+//	if err != nil {
+//	    return err
+//	}
+//	m, _ := sdm.New[string]("orders")
+//	m = m.With(sdm.WithBackend(sdmpg.New(db)))
+package sdmpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"go-slim.dev/infra/sdm"
+)
+
+// Backend is the sdm.LockBackend storing leases as PostgreSQL advisory locks.
+type Backend struct {
+	db   *sql.DB
+	mu   sync.Mutex
+	held map[heldID]*heldLease // Leases held by this backend
+}
+
+var _ sdm.LockBackend = (*Backend)(nil)
+
+// heldID identifies a lease held by the backend
+type heldID struct {
+	key   string
+	value string
+}
+
+// heldLease is a lease held by the backend on its own connection
+type heldLease struct {
+	conn  *sql.Conn // Connection whose session holds the advisory lock
+	owner string    // Owner ID of a reentrant lease
+	holds int       // Hold count of a reentrant lease
+}
+
+// New creates a PostgreSQL backend using the given database.
+func New(db *sql.DB) *Backend {
+	return &Backend{db: db, held: make(map[heldID]*heldLease)}
+}
+
+// hash returns the 32-bit advisory lock identifier of s
+func hash(s string) int32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return int32(h.Sum32())
+}
+
+// Acquire implements sdm.LockBackend.
+func (b *Backend) Acquire(ctx context.Context, lease sdm.Lease) (sdm.LeaseOutcome, error) {
+	id := heldID{key: lease.Key, value: lease.Value}
+
+	b.mu.Lock()
+	if h, ok := b.held[id]; ok {
+		defer b.mu.Unlock()
+		if lease.Owner == "" || h.owner != lease.Owner {
+			return sdm.LeaseUnchanged, nil
+		}
+		h.holds++
+		return sdm.LeaseNested, nil
+	}
+	b.mu.Unlock()
+
+	// The backend is not locked while waiting for the database, a concurrent
+	// acquisition of the same lease fails to take the advisory lock
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return sdm.LeaseUnchanged, fmt.Errorf("sdmpg: try lock failed: %w", err)
+	}
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, $2)", hash(lease.Key), hash(lease.Value)).Scan(&acquired)
+	if err != nil || !acquired {
+		_ = conn.Close()
+		if err != nil {
+			return sdm.LeaseUnchanged, fmt.Errorf("sdmpg: try lock failed: %w", err)
+		}
+		return sdm.LeaseUnchanged, nil
+	}
+
+	b.mu.Lock()
+	b.held[id] = &heldLease{conn: conn, owner: lease.Owner, holds: 1}
+	b.mu.Unlock()
+	return sdm.LeaseChanged, nil
+}
+
+// Release implements sdm.LockBackend. Only the leases acquired through this
+// backend can be released.
+func (b *Backend) Release(ctx context.Context, lease sdm.Lease) (sdm.LeaseOutcome, error) {
+	id := heldID{key: lease.Key, value: lease.Value}
+
+	b.mu.Lock()
+	h, ok := b.held[id]
+	if !ok {
+		b.mu.Unlock()
+		return sdm.LeaseUnchanged, nil
+	}
+
+	// A reentrant lock is only released once its hold count drops to zero
+	if lease.Owner != "" && h.owner == lease.Owner && h.holds > 1 {
+		h.holds--
+		b.mu.Unlock()
+		return sdm.LeaseNested, nil
+	}
+	delete(b.held, id)
+	b.mu.Unlock()
+
+	// Closing the connection ends the session and its advisory locks, even if
+	// the unlock fails. The lease is also lost if the session already ended.
+	defer h.conn.Close()
+
+	var released bool
+	err := h.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1, $2)", hash(lease.Key), hash(lease.Value)).Scan(&released)
+	if err != nil {
+		return sdm.LeaseUnchanged, fmt.Errorf("sdmpg: unlock failed: %w", err)
+	}
+	if !released {
+		return sdm.LeaseUnchanged, nil
+	}
+	return sdm.LeaseChanged, nil
+}
+
+// Renew implements sdm.LockBackend by checking the session holding the lease is alive.
+func (b *Backend) Renew(ctx context.Context, lease sdm.Lease) (bool, error) {
+	id := heldID{key: lease.Key, value: lease.Value}
+
+	b.mu.Lock()
+	h, ok := b.held[id]
+	b.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	if err := h.conn.PingContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("sdmpg: renew failed: %w", err)
+		}
+		// The session ended along with its advisory locks
+		b.mu.Lock()
+		if b.held[id] == h {
+			delete(b.held, id)
+		}
+		b.mu.Unlock()
+		_ = h.conn.Close()
+		return false, nil
+	}
+	return true, nil
+}
+
+// IsLocked implements sdm.LockBackend by looking up the advisory locks of key
+// held by any session of the current database.
+func (b *Backend) IsLocked(ctx context.Context, key string) (bool, error) {
+	// Advisory locks taken with two keys store the first one in classid as an oid
+	const query = `SELECT EXISTS (
+		SELECT 1 FROM pg_locks
+		WHERE locktype = 'advisory' AND objsubid = 2 AND granted
+			AND database = (SELECT oid FROM pg_database WHERE datname = current_database())
+			AND classid::bigint = $1
+	)`
+
+	var locked bool
+	if err := b.db.QueryRowContext(ctx, query, int64(uint32(hash(key)))).Scan(&locked); err != nil {
+		return false, fmt.Errorf("sdmpg: failed to check lock status: %w", err)
+	}
+	return locked, nil
+}
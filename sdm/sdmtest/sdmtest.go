@@ -0,0 +1,173 @@
+// Package sdmtest provides test helpers for code using package sdm, so that unit
+// tests run without a Redis server.
+//
+// Backend is an in-memory sdm.LockBackend driven by a fake Clock. Tests advance the
+// clock to expire leases, expire them at once with Expire, and make locks appear
+// held by another process with Contend.
+//
+// Example:
+//
+//	b := sdmtest.NewBackend()
+//	m, _ := sdm.New[string]("orders")
+//	m = m.With(sdm.WithBackend(b), sdm.WithTTL(time.Minute))
+//
+//	m.Lock(ctx, "job")
+//	b.Clock.Advance(time.Minute) // The lease expires
+package sdmtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go-slim.dev/infra/sdm"
+)
+
+// Clock is a fake clock which only moves when advanced. It is safe for concurrent use.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock set to the given time, or to the current time by default.
+func NewClock(now ...time.Time) *Clock {
+	if len(now) > 0 {
+		return &Clock{now: now[0]}
+	}
+	return &Clock{now: time.Now()}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d, expiring the leases whose TTL has elapsed.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Backend is an in-memory sdm.LockBackend measuring lease expiration with Clock.
+// Beyond the sdm.MemoryBackend it embeds, it can force contention on a lock.
+type Backend struct {
+	*sdm.MemoryBackend
+	Clock *Clock // Clock measuring lease expiration
+
+	mu        sync.Mutex
+	contended map[string]int // Forced contentions by lock key
+}
+
+// NewBackend creates an empty backend with a new Clock.
+func NewBackend() *Backend {
+	clock := NewClock()
+	return &Backend{
+		MemoryBackend: sdm.NewMemoryBackend(clock.Now),
+		Clock:         clock,
+		contended:     make(map[string]int),
+	}
+}
+
+// Contend makes the lock of the mutex named name appear held by another process
+// until the returned function is called: acquisitions fail and IsLocked reports
+// true, while existing leases are kept. Contentions nest.
+//
+// Waiters blocked in Lock are not notified when the contention ends; they retry
+// within their maximum backoff of one second.
+func (b *Backend) Contend(name string) (release func()) {
+	key := mustKey(name)
+
+	b.mu.Lock()
+	b.contended[key]++
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.contended[key]--; b.contended[key] <= 0 {
+				delete(b.contended, key)
+			}
+		})
+	}
+}
+
+// Expire removes the leases of the given values on the mutex named name, or all
+// its leases if no value is given, as if their TTL had elapsed.
+func (b *Backend) Expire(name string, values ...string) {
+	b.MemoryBackend.Expire(mustKey(name), values...)
+}
+
+// isContended reports whether contention is forced on key
+func (b *Backend) isContended(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.contended[key] > 0
+}
+
+// Acquire implements sdm.LockBackend, failing while contention is forced.
+func (b *Backend) Acquire(ctx context.Context, lease sdm.Lease) (sdm.LeaseOutcome, error) {
+	if b.isContended(lease.Key) {
+		if err := ctx.Err(); err != nil {
+			return sdm.LeaseUnchanged, err
+		}
+		return sdm.LeaseUnchanged, nil
+	}
+	return b.MemoryBackend.Acquire(ctx, lease)
+}
+
+// AcquireFair implements sdm.FairBackend, failing while contention is forced.
+func (b *Backend) AcquireFair(ctx context.Context, lease sdm.Lease, ticket string, priority int32, wait time.Duration) (sdm.LeaseOutcome, error) {
+	if b.isContended(lease.Key) {
+		if err := ctx.Err(); err != nil {
+			return sdm.LeaseUnchanged, err
+		}
+		return sdm.LeaseUnchanged, nil
+	}
+	return b.MemoryBackend.AcquireFair(ctx, lease, ticket, priority, wait)
+}
+
+// IsLocked implements sdm.LockBackend, reporting true while contention is forced.
+func (b *Backend) IsLocked(ctx context.Context, key string) (bool, error) {
+	if b.isContended(key) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return b.MemoryBackend.IsLocked(ctx, key)
+}
+
+// NewMutex creates a mutex named name using a new Backend, which it also returns.
+// The test fails immediately if the mutex cannot be created.
+func NewMutex[T any](tb testing.TB, name string, opts ...sdm.Option) (sdm.Mutex[T], *Backend) {
+	tb.Helper()
+
+	m, err := sdm.New[T](name)
+	if err != nil {
+		tb.Fatalf("sdmtest: %v", err)
+	}
+	b := NewBackend()
+	return m.With(append([]sdm.Option{sdm.WithBackend(b)}, opts...)...), b
+}
+
+// mustKey returns the lock key of the mutex named name
+func mustKey(name string) string {
+	key, err := sdm.LockKey(name)
+	if err != nil {
+		panic("sdmtest: " + err.Error())
+	}
+	return key
+}
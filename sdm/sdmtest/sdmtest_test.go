@@ -0,0 +1,104 @@
+package sdmtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-slim.dev/infra/sdm"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+
+	clock.Set(start)
+	assert.Equal(t, start, clock.Now())
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("时钟推进使租约过期", func(t *testing.T) {
+		m, b := NewMutex[string](t, "sdmtest-expiry", sdm.WithTTL(time.Minute))
+		require.NoError(t, m.Lock(ctx, "job"))
+
+		b.Clock.Advance(59 * time.Second)
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		b.Clock.Advance(time.Second)
+		locked, err = m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+		assert.Equal(t, sdm.ErrMutexNotAcquired, m.Unlock(ctx, "job"))
+	})
+
+	t.Run("立即过期", func(t *testing.T) {
+		m, b := NewMutex[string](t, "sdmtest-expire")
+		require.NoError(t, m.Lock(ctx, "job-1"))
+		require.NoError(t, m.Lock(ctx, "job-2"))
+
+		b.Expire("sdmtest-expire", "job-1")
+		assert.Equal(t, sdm.ErrMutexNotAcquired, m.Unlock(ctx, "job-1"))
+
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		b.Expire("sdmtest-expire")
+		locked, err = m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("强制竞争", func(t *testing.T) {
+		m, b := NewMutex[string](t, "sdmtest-contend")
+
+		release := b.Contend("sdmtest-contend")
+		acquired, err := m.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		acquired, err = m.TryLock(ctx, "job", 50*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		// 重复释放无效
+		release()
+		release()
+
+		acquired, err = m.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, m.Unlock(ctx, "job"))
+	})
+
+	t.Run("竞争可嵌套", func(t *testing.T) {
+		m, b := NewMutex[string](t, "sdmtest-nested")
+
+		release1 := b.Contend("sdmtest-nested")
+		release2 := b.Contend("sdmtest-nested")
+		release1()
+
+		acquired, err := m.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		release2()
+		acquired, err = m.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+	})
+}
@@ -0,0 +1,143 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains Do, which computes a value once across the nodes of a cluster.
+package sdm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Defaults of Do
+const (
+	defaultDoTTL     = 30 * time.Second // Lease duration of the lock taken by Do, see WithTTL
+	defaultResultTTL = time.Minute      // Lifetime of the results cached by Do, see WithResultTTL
+)
+
+// doValue is the lock value shared by the nodes computing a value with Do, so
+// that they exclude each other
+const doValue = "sdm.Do"
+
+// Do returns the value identified by key, computing it with fn at most once at a
+// time across the cluster, so that an expensive value is not computed by every node
+// that misses it at the same time:
+//
+//   - Concurrent calls for the same key within this process share one computation,
+//     like golang.org/x/sync/singleflight.
+//   - The value is read from a Redis cache, or else computed by fn under the lock of
+//     the mutex named key, acquired with the value "sdm.Do", then cached as JSON for
+//     one minute (see WithResultTTL). Nodes waiting for the lock read the value
+//     cached by its holder.
+//
+// The lock is acquired with a TTL of 30 seconds renewed by the watchdog, which can be
+// changed with opts (see WithTTL, WithWatchdog, WithBackend). fn is passed a context
+// canceled if the lease is lost (see Mutex.LockContext). Errors returned by fn are not
+// cached, the next call computing the value again.
+//
+// The computation is shared by the callers, so it runs with the values of the
+// context of the first caller but is not canceled with it: every caller stops
+// waiting when its own context is done, and the computation carries on for the
+// others. Do requires the Redis client set with SetRedis, which stores the cache
+// even if the lock uses another backend.
+//
+// Example:
+//
+//	report, err := sdm.Do(ctx, "report:daily", func(ctx context.Context) (*Report, error) {
+//	    return buildReport(ctx)
+//	}, sdm.WithResultTTL(10*time.Minute))
+func Do[T any](ctx context.Context, key string, fn func(context.Context) (T, error), opts ...Option) (T, error) {
+	var zero T
+	m, err := New[string](key)
+	if err != nil {
+		return zero, err
+	}
+	m = m.With(WithTTL(defaultDoTTL), WithWatchdog(), WithResultTTL(defaultResultTTL)).With(opts...)
+
+	lockKey, err := m.opts.storage().LockKey(m.name)
+	if err != nil {
+		return zero, err
+	}
+	cache := resultKey(lockKey)
+
+	shared := context.WithoutCancel(ctx)
+	results := sfg.DoChan(cache, func() (any, error) {
+		return computeResult(shared, m, cache, func(ctx context.Context) ([]byte, error) {
+			v, err := fn(ctx)
+			if err != nil {
+				return nil, err
+			}
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("sdm: failed to marshal result: %w", err)
+			}
+			return data, nil
+		})
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case r := <-results:
+		if r.Err != nil {
+			return zero, r.Err
+		}
+		// Every caller decodes its own copy of the value
+		var v T
+		if err := json.Unmarshal(r.Val.([]byte), &v); err != nil {
+			return zero, fmt.Errorf("sdm: failed to unmarshal result: %w", err)
+		}
+		return v, nil
+	}
+}
+
+// computeResult returns the result cached at key, computing it with fn under the
+// lock of m and caching it if absent
+func computeResult(ctx context.Context, m Mutex[string], key string, fn func(context.Context) ([]byte, error)) ([]byte, error) {
+	rdb, err := m.opts.storage().client()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := loadResult(ctx, rdb, key); err != nil || data != nil {
+		return data, err
+	}
+
+	lctx, err := m.LockContext(ctx, doValue)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Unlock(context.WithoutCancel(ctx), doValue)
+
+	// The previous holder of the lock may have cached the result meanwhile
+	if data, err := loadResult(ctx, rdb, key); err != nil || data != nil {
+		return data, err
+	}
+
+	data, err := fn(lctx)
+	if err != nil {
+		return nil, err
+	}
+	if m.opts.results > 0 {
+		ttl := strconv.FormatInt(max(m.opts.results.Milliseconds(), 1), 10)
+		if err := setResultScript.Run(ctx, rdb, []string{key}, data, ttl).Err(); err != nil {
+			return nil, fmt.Errorf("sdm: failed to cache result: %w", err)
+		}
+	}
+	return data, nil
+}
+
+// loadResult returns the result cached at key, nil if absent
+func loadResult(ctx context.Context, rdb redis.Scripter, key string) ([]byte, error) {
+	data, err := getScript.Run(ctx, rdb, []string{key}).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sdm: failed to read cached result: %w", err)
+	}
+	return []byte(data), nil
+}
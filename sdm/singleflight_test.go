@@ -0,0 +1,220 @@
+package sdm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	// DoChan 在结果送达后才释放 singleflight 的内部锁，Forget 获取该锁，
+	// 确保后台调用结束后其他测试才能重置 sfg
+	t.Cleanup(func() { sfg.Forget("") })
+
+	type report struct {
+		Total int
+		Items []string
+	}
+
+	t.Run("并发调用只计算一次", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		fn := func(ctx context.Context) (report, error) {
+			calls.Add(1)
+			<-release
+			return report{Total: 2, Items: []string{"a", "b"}}, nil
+		}
+
+		var wg sync.WaitGroup
+		results := make([]report, 10)
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r, err := Do(ctx, "test-do-concurrent", fn)
+				assert.NoError(t, err)
+				results[i] = r
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		for _, r := range results {
+			assert.Equal(t, report{Total: 2, Items: []string{"a", "b"}}, r)
+		}
+	})
+
+	t.Run("读取缓存的结果", func(t *testing.T) {
+		var calls atomic.Int32
+		fn := func(ctx context.Context) (int, error) {
+			return int(calls.Add(1)), nil
+		}
+
+		v, err := Do(ctx, "test-do-cached", fn)
+		require.NoError(t, err)
+		assert.Equal(t, 1, v)
+
+		// 其他节点读取缓存的结果，不再计算
+		v, err = Do(ctx, "test-do-cached", fn)
+		require.NoError(t, err)
+		assert.Equal(t, 1, v)
+
+		key, err := LockKey("test-do-cached")
+		require.NoError(t, err)
+		ttl, err := client.PTTL(ctx, resultKey(key)).Result()
+		require.NoError(t, err)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+		// 锁已释放
+		m, err := New[string]("test-do-cached")
+		require.NoError(t, err)
+		locked, err := m.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("等待锁的节点读取持有者缓存的结果", func(t *testing.T) {
+		m, err := New[string]("test-do-waiting")
+		require.NoError(t, err)
+		require.NoError(t, m.Lock(ctx, doValue))
+
+		done := make(chan string)
+		go func() {
+			v, err := Do(ctx, "test-do-waiting", func(ctx context.Context) (string, error) {
+				return "本节点的结果", nil
+			})
+			assert.NoError(t, err)
+			done <- v
+		}()
+
+		// 模拟持有锁的节点缓存结果后释放锁
+		time.Sleep(50 * time.Millisecond)
+		key, err := LockKey("test-do-waiting")
+		require.NoError(t, err)
+		require.NoError(t, client.Set(ctx, resultKey(key), `"其他节点的结果"`, time.Minute).Err())
+		require.NoError(t, m.Unlock(ctx, doValue))
+
+		select {
+		case v := <-done:
+			assert.Equal(t, "其他节点的结果", v)
+		case <-time.After(2 * time.Second):
+			t.Fatal("释放锁后应该读取缓存的结果")
+		}
+	})
+
+	t.Run("错误不会被缓存", func(t *testing.T) {
+		errCompute := errors.New("compute failed")
+		_, err := Do(ctx, "test-do-error", func(ctx context.Context) (int, error) {
+			return 0, errCompute
+		})
+		assert.Equal(t, errCompute, err)
+
+		v, err := Do(ctx, "test-do-error", func(ctx context.Context) (int, error) {
+			return 42, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 42, v)
+	})
+
+	t.Run("禁用缓存", func(t *testing.T) {
+		var calls atomic.Int32
+		fn := func(ctx context.Context) (int, error) {
+			return int(calls.Add(1)), nil
+		}
+		for range 2 {
+			_, err := Do(ctx, "test-do-uncached", fn, WithResultTTL(0))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("调用方上下文取消", func(t *testing.T) {
+		release := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = Do(ctx, "test-do-cancel", func(ctx context.Context) (int, error) {
+				<-release
+				return 1, nil
+			})
+		}()
+		defer func() {
+			close(release)
+			<-done
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		cctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := Do(cctx, "test-do-cancel", func(ctx context.Context) (int, error) {
+			return 2, nil
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("首个调用方取消不影响其他调用方", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		fn := func(ctx context.Context) (int, error) {
+			close(started)
+			select {
+			case <-release:
+				return 42, ctx.Err()
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+
+		cctx, cancel := context.WithCancel(ctx)
+		first := make(chan error)
+		go func() {
+			_, err := Do(cctx, "test-do-first-cancel", fn)
+			first <- err
+		}()
+		<-started
+
+		second := make(chan int)
+		go func() {
+			v, err := Do(ctx, "test-do-first-cancel", fn)
+			assert.NoError(t, err)
+			second <- v
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+		close(release)
+
+		select {
+		case v := <-second:
+			assert.Equal(t, 42, v)
+		case <-time.After(2 * time.Second):
+			t.Fatal("其他调用方应该得到计算结果")
+		}
+	})
+
+	t.Run("空键", func(t *testing.T) {
+		_, err := Do(ctx, " ", func(ctx context.Context) (int, error) {
+			return 0, nil
+		})
+		assert.Equal(t, ErrMutexNameEmpty, err)
+	})
+}
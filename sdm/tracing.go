@@ -0,0 +1,72 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the OpenTelemetry spans wrapping the lock operations.
+package sdm
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of the lock operations
+const tracerName = "go-slim.dev/infra/sdm"
+
+// Attributes of the spans of the lock operations
+const (
+	attrMutex    = attribute.Key("sdm.mutex")        // Name of the mutex
+	attrAcquired = attribute.Key("sdm.acquired")     // Whether the lock was acquired
+	attrNested   = attribute.Key("sdm.nested")       // Whether the lock was reentered by its owner
+	attrWait     = attribute.Key("sdm.wait_seconds") // Time spent waiting for the lock
+	attrToken    = attribute.Key("sdm.token")        // Fencing token of the acquired lease
+	attrReleased = attribute.Key("sdm.released")     // Whether the lease was removed
+)
+
+// startSpan starts the span of the lock operation op on the mutex name, with the
+// tracer provider registered with otel.SetTracerProvider. The backend calls made
+// with the returned context are traced as its children.
+func startSpan(ctx context.Context, op, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "sdm."+op, trace.WithAttributes(attrMutex.String(name)))
+}
+
+// endSpan ends span with attrs, recording err as the error of the operation
+func endSpan(span trace.Span, err error, attrs ...attribute.KeyValue) {
+	span.SetAttributes(attrs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceWait records on the span of ctx the time spent waiting for the lock since start
+func traceWait(ctx context.Context, start time.Time) {
+	trace.SpanFromContext(ctx).SetAttributes(attrWait.Float64(time.Since(start).Seconds()))
+}
+
+// traceToken records on the span of ctx the fencing token of the lease acquired
+// through b. The token is looked up only for recorded spans of backends
+// implementing LeaseInspector.
+func traceToken(ctx context.Context, b LockBackend, lease Lease) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	inspector, ok := b.(LeaseInspector)
+	if !ok {
+		return
+	}
+	holders, err := inspector.Holders(ctx, lease.Key)
+	if err != nil {
+		return
+	}
+	for _, h := range holders {
+		if h.Value == lease.Value && h.Token != 0 {
+			span.SetAttributes(attrToken.Int64(h.Token))
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package sdm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// setupTracing 安装记录 span 的 TracerProvider，测试结束后恢复
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+// spanAttrs 返回 span 的属性
+func spanAttrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracing(t *testing.T) {
+	recorder := setupTracing(t)
+	ctx := context.Background()
+
+	mutex, err := New[string]("test-tracing")
+	require.NoError(t, err)
+	mutex = mutex.With(WithBackend(NewMemoryBackend()))
+
+	t.Run("获取与释放", func(t *testing.T) {
+		tracer := otel.Tracer("test")
+		pctx, parent := tracer.Start(ctx, "parent")
+		require.NoError(t, mutex.Lock(pctx, "owner-1"))
+		require.NoError(t, mutex.Unlock(pctx, "owner-1"))
+		parent.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 3)
+
+		lock := spans[0]
+		assert.Equal(t, "sdm.Mutex.Lock", lock.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), lock.Parent().SpanID())
+		attrs := spanAttrs(lock)
+		assert.Equal(t, "test-tracing", attrs[attrMutex].AsString())
+		assert.True(t, attrs[attrAcquired].AsBool())
+		assert.Positive(t, attrs[attrToken].AsInt64())
+		assert.Contains(t, attrs, attrWait)
+
+		unlock := spans[1]
+		assert.Equal(t, "sdm.Mutex.Unlock", unlock.Name())
+		assert.True(t, spanAttrs(unlock)[attrReleased].AsBool())
+		assert.Equal(t, codes.Unset, unlock.Status().Code)
+	})
+
+	t.Run("获取失败", func(t *testing.T) {
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+		defer func() { _ = mutex.Unlock(ctx, "owner-1") }()
+
+		recorder.Reset()
+		acquired, err := mutex.TryLock(ctx, "owner-1", 20*time.Millisecond)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "sdm.Mutex.TryLock", spans[0].Name())
+		attrs := spanAttrs(spans[0])
+		assert.False(t, attrs[attrAcquired].AsBool())
+		assert.NotContains(t, attrs, attrToken)
+		assert.GreaterOrEqual(t, attrs[attrWait].AsFloat64(), 0.02)
+	})
+
+	t.Run("错误", func(t *testing.T) {
+		recorder.Reset()
+		assert.Equal(t, ErrMutexNotAcquired, mutex.Unlock(ctx, "owner-2"))
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.False(t, spanAttrs(spans[0])[attrReleased].AsBool())
+		require.Len(t, spans[0].Events(), 1)
+		assert.Equal(t, "exception", spans[0].Events()[0].Name)
+	})
+
+	t.Run("重入", func(t *testing.T) {
+		m := mutex.With(WithReentrant("owner-a"))
+		require.NoError(t, m.Lock(ctx, "job"))
+		recorder.Reset()
+		acquired, err := m.TryLock(ctx, "job")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		require.NoError(t, m.Unlock(ctx, "job"))
+		require.NoError(t, m.Unlock(ctx, "job"))
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 3)
+		attrs := spanAttrs(spans[0])
+		assert.True(t, attrs[attrNested].AsBool())
+		assert.Positive(t, attrs[attrToken].AsInt64())
+		assert.False(t, spanAttrs(spans[1])[attrReleased].AsBool())
+		assert.True(t, spanAttrs(spans[2])[attrReleased].AsBool())
+	})
+}
+
+func TestTracing_RWMutex(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	recorder := setupTracing(t)
+	ctx := context.Background()
+
+	m, err := NewRWMutex[string]("test-tracing-rw")
+	require.NoError(t, err)
+
+	require.NoError(t, m.RLock(ctx, "reader-1"))
+	acquired, err := m.TryLock(ctx, "writer-1")
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	require.NoError(t, m.RUnlock(ctx, "reader-1"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "sdm.RWMutex.RLock", spans[0].Name())
+	assert.True(t, spanAttrs(spans[0])[attrAcquired].AsBool())
+	assert.Equal(t, "sdm.RWMutex.TryLock", spans[1].Name())
+	assert.False(t, spanAttrs(spans[1])[attrAcquired].AsBool())
+	assert.Equal(t, "sdm.RWMutex.RUnlock", spans[2].Name())
+	assert.True(t, spanAttrs(spans[2])[attrReleased].AsBool())
+}
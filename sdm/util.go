@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/redis/go-redis/v9"
@@ -18,60 +19,594 @@ var (
 	ErrRedisNotInitialized = errors.New("sdm: redis client not initialized")
 )
 
-var tryLockScript = redis.NewScript(`
+// luaNow is the common prelude of the lease scripts. It reads the Redis server
+// time in milliseconds into the local variable now, so lease expiration does not
+// depend on the clocks of the clients. Command replication is enabled first so
+// that scripts calling TIME may still write on Redis versions before 5.0.
+const luaNow = `
+	redis.replicate_commands()
+	local now_time = redis.call("TIME")
+	local now = tonumber(now_time[1]) * 1000 + math.floor(tonumber(now_time[2]) / 1000)
+`
+
+// luaMigrate converts KEYS[1] from the Set left by versions before lease
+// expiration, whose members held the lock until they were released, into a
+// Sorted Set of leases that never expire. Locks held across an upgrade stay
+// held and can still be released, instead of failing with WRONGTYPE.
+const luaMigrate = `
+	if redis.call("TYPE", KEYS[1]).ok == "set" then
+		local members = redis.call("SMEMBERS", KEYS[1])
+		redis.call("DEL", KEYS[1])
+		for _, member in ipairs(members) do
+			redis.call("ZADD", KEYS[1], "+inf", member)
+		end
+	end
+`
+
+// luaPurge removes the expired leases of KEYS[1].
+const luaPurge = `
+	redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", string.format("%d", now))
+`
+
+// luaExpire makes KEYS[1] and its companion Hashes KEYS[2] and KEYS[3] expire
+// together with the longest lease of KEYS[1], or persist while any lease never
+// expires. The companion Hashes are deleted once KEYS[1] holds no lease anymore.
+// Further keys, such as the fencing token counter, outlive the leases.
+const luaExpire = `
+	local last = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+	for i = 1, math.min(#KEYS, 3) do
+		if #last == 0 then
+			redis.call("DEL", KEYS[i])
+		elseif last[2] == "inf" or last[2] == "+inf" then
+			redis.call("PERSIST", KEYS[i])
+		else
+			redis.call("PEXPIREAT", KEYS[i], last[2])
+		end
+	end
+`
+
+var tryLockScript = newScript(luaNow + luaMigrate + luaPurge + `
 	-- Attempt to acquire distributed lock
-	-- Uses Set data structure where key is the lock name and member is the lock value
+	-- Uses Sorted Set data structure where key is the lock name, member is the lock value
+	-- and score is the lease expiration time in milliseconds (+inf if the lock never expires)
 	-- KEYS[1]: Lock key name
+	-- KEYS[2]: Hold counts of reentrant leases, a Hash of lock value to "<count>:<owner>" (optional)
+	-- KEYS[3]: Holder metadata, a Hash of lock value to "<fencing token>:<JSON>" (optional)
+	-- KEYS[4]: Fencing token counter, incremented by every new lease (required with KEYS[3])
 	-- ARGV[1]: Lock value
-	-- Returns: 1 for successful acquisition, 0 for lock already occupied
+	-- ARGV[2]: Lease duration in milliseconds, 0 or absent if the lock never expires
+	-- ARGV[3]: Owner ID of a reentrant acquisition, empty or absent otherwise
+	-- ARGV[4]: Holder metadata recorded with a new lease (optional)
+	-- KEYS[5]: Queue of the fair waiters, a Sorted Set of "<rank>:<position>:<ticket>:<value>"
+	--          scored by the expiration of their registration, the members ordering the waiters
+	--          by priority then arrival (optional, see WithFairness and WithPriority)
+	-- KEYS[6]: Counter of the queue positions (required with KEYS[5])
+	-- ARGV[5]: Ticket of the fair waiter (required with KEYS[5])
+	-- ARGV[6]: Duration in milliseconds for which the waiter stays queued if it does not
+	--          acquire the lock, 0 to not queue it (required with KEYS[5])
+	-- ARGV[7]: Priority of the fair waiter, an int32 (optional, defaults to 0)
+	-- Returns: 1 for successful acquisition, 2 for reentrant acquisition by the holding owner,
+	--          0 for lock already occupied or earlier fair waiters (or waiters of higher priority) queued
 
 	local key = KEYS[1]
+	local holds = KEYS[2]
+	local info = KEYS[3]
 	local value = ARGV[1]
+	local ttl = tonumber(ARGV[2]) or 0
+	local owner = ARGV[3]
+	if owner == "" then
+		owner = nil
+	end
 
-	-- Use SADD to try adding the value to the set
-	-- If value already exists, returns 0; if addition succeeds, returns 1
-	local added = redis.call("SADD", key, value)
+	local score = "+inf"
+	if ttl > 0 then
+		score = string.format("%d", now + ttl)
+	end
+
+	-- Find the entry of the fair waiter and the head of the queue of value. Ranks
+	-- sort the waiters of higher priority first
+	local queue = KEYS[5]
+	local rank = string.format("%010d", 2147483647 - (tonumber(ARGV[7]) or 0))
+	local entry, head
+	if queue then
+		redis.call("ZREMRANGEBYSCORE", queue, "-inf", string.format("%d", now))
+		local suffix = ":" .. value
+		for _, member in ipairs(redis.call("ZRANGE", queue, 0, -1)) do
+			local ticket, rest = string.match(member, "^%d+:%d+:([^:]*)(:.*)$")
+			if rest == suffix then
+				if ticket == ARGV[5] then
+					entry = member
+				end
+				if not head or member < head then
+					head = member
+				end
+			end
+		end
+	end
 
-	-- If value already exists in set, lock is occupied
-	if added == 0 then
+	-- Queue the fair waiter failing to acquire the lock, or keep its place
+	local function enqueue()
+		local wait = tonumber(ARGV[6]) or 0
+		if not queue or wait <= 0 then
+			return 0
+		end
+		if not entry then
+			entry = string.format("%s:%020d:%s:%s", rank, redis.call("INCR", KEYS[6]), ARGV[5], value)
+		end
+		redis.call("ZADD", queue, string.format("%d", now + wait), entry)
+		redis.call("PEXPIRE", queue, wait)
+		redis.call("PEXPIRE", KEYS[6], wait)
 		return 0
 	end
 
+	-- Remove the fair waiter acquiring the lock from the queue
+	local function dequeue()
+		if entry then
+			redis.call("ZREM", queue, entry)
+		end
+	end
+
+	-- If value already holds an unexpired lease, lock is occupied unless
+	-- the same owner acquires it again
+	if redis.call("ZSCORE", key, value) then
+		if not (holds and owner) then
+			return enqueue()
+		end
+		local hold = redis.call("HGET", holds, value)
+		if not hold then
+			return enqueue()
+		end
+		local count, holder = string.match(hold, "^(%d+):(.*)$")
+		if holder ~= owner then
+			return enqueue()
+		end
+		redis.call("HSET", holds, value, string.format("%d:%s", tonumber(count) + 1, owner))
+		redis.call("ZADD", key, "XX", score, value)
+		dequeue()
+` + luaExpire + `
+		return 2
+	end
+
+	-- A fair waiter waits for its turn, and a new one for the waiters of the same or a
+	-- higher priority
+	if head and head ~= entry and (entry or string.sub(head, 1, 10) <= rank) then
+		return enqueue()
+	end
+	dequeue()
+
+	redis.call("ZADD", key, score, value)
+	if holds then
+		-- Drop the hold count left by an expired lease
+		if owner then
+			redis.call("HSET", holds, value, "1:" .. owner)
+		else
+			redis.call("HDEL", holds, value)
+		end
+	end
+	if info then
+		-- Replace the metadata left by an expired lease
+		local token = redis.call("INCR", KEYS[4])
+		redis.call("HSET", info, value, string.format("%d:%s", token, ARGV[4] or ""))
+	end
+` + luaExpire + `
 	-- Successfully acquired lock
 	return 1
 `)
 
-var unlockScript = redis.NewScript(`
+var unlockScript = newScript(luaNow + luaMigrate + luaPurge + `
 	-- Release distributed lock
 	-- KEYS[1]: Lock key name
+	-- KEYS[2]: Hold counts of reentrant leases (optional)
+	-- KEYS[3]: Holder metadata (optional)
 	-- ARGV[1]: Expected lock value
-	-- Returns: 1 for successful release, 0 for failed release (lock doesn't exist or value mismatch)
+	-- ARGV[2]: Channel on which the released value is published to wake up waiters (optional)
+	-- ARGV[3]: Owner ID of a reentrant release (optional)
+	-- Returns: 1 for successful release, 2 if the owner still holds the lock after decrementing
+	--          its hold count, 0 for failed release (lock doesn't exist, expired or value mismatch)
 
 	local key = KEYS[1]
+	local holds = KEYS[2]
 	local expected_value = ARGV[1]
+	local owner = ARGV[3]
+
+	if not redis.call("ZSCORE", key, expected_value) then
+		return 0
+	end
+
+	-- A reentrant lock is only released once its hold count drops to zero
+	if holds and owner then
+		local hold = redis.call("HGET", holds, expected_value)
+		if hold then
+			local count, holder = string.match(hold, "^(%d+):(.*)$")
+			if holder == owner and tonumber(count) > 1 then
+				redis.call("HSET", holds, expected_value, string.format("%d:%s", tonumber(count) - 1, owner))
+				return 2
+			end
+		end
+	end
+
+	-- Remove value from sorted set, the key is deleted once it becomes empty
+	redis.call("ZREM", key, expected_value)
+	for i = 2, #KEYS do
+		redis.call("HDEL", KEYS[i], expected_value)
+	end
+` + luaExpire + `
+	if ARGV[2] and ARGV[2] ~= "" then
+		redis.call("PUBLISH", ARGV[2], expected_value)
+	end
 
-	-- Check if value exists in set
-	local is_member = redis.call("SISMEMBER", key, expected_value)
+	return 1
+`)
+
+var renewScript = newScript(luaNow + luaMigrate + luaPurge + `
+	-- Renew the lease of a held lock
+	-- KEYS[1]: Lock key name
+	-- KEYS[2]: Hold counts of reentrant leases (optional)
+	-- KEYS[3]: Holder metadata (optional)
+	-- ARGV[1]: Lock value
+	-- ARGV[2]: New lease duration in milliseconds, counted from now
+	-- Returns: 1 for successful renewal, 0 if the lock is no longer held (released or expired)
 
-	-- If value not in set, return failure
-	if is_member == 0 then
+	local key = KEYS[1]
+	local value = ARGV[1]
+	local ttl = tonumber(ARGV[2])
+
+	if not redis.call("ZSCORE", key, value) then
 		return 0
 	end
 
-	-- Remove value from set
-	redis.call("SREM", key, expected_value)
+	redis.call("ZADD", key, "XX", string.format("%d", now + ttl), value)
+` + luaExpire + `
+	return 1
+`)
+
+var isLockedScript = newScript(luaNow + luaMigrate + `
+	-- Count the unexpired leases of a lock
+	-- KEYS[1]: Lock key name
+	-- Returns: the number of values currently holding the lock
 
-	-- Delete key if set becomes empty
-	if redis.call("SCARD", key) == 0 then
-		redis.call("DEL", key)
+	return redis.call("ZCOUNT", KEYS[1], string.format("(%d", now), "+inf")
+`)
+
+var holdersScript = newScript(luaNow + luaMigrate + `
+	-- Describe the holders of the unexpired leases of a lock
+	-- KEYS[1]: Lock key name
+	-- KEYS[2]: Hold counts of reentrant leases
+	-- KEYS[3]: Holder metadata
+	-- Returns: a flat array of value, expiration score, hold count and "<fencing token>:<metadata>"
+	--          per holder, the last two being false when absent
+
+	local result = {}
+	local leases = redis.call("ZRANGEBYSCORE", KEYS[1], string.format("(%d", now), "+inf", "WITHSCORES")
+	for i = 1, #leases, 2 do
+		local value = leases[i]
+		table.insert(result, value)
+		table.insert(result, leases[i + 1])
+		table.insert(result, redis.call("HGET", KEYS[2], value))
+		table.insert(result, redis.call("HGET", KEYS[3], value))
+	end
+	return result
+`)
+
+var forceUnlockScript = newScript(luaNow + luaMigrate + luaPurge + `
+	-- Release the lease holding a fencing token, whatever its value, owner and hold count
+	-- KEYS[1]: Lock key name
+	-- KEYS[2]: Hold counts of reentrant leases
+	-- KEYS[3]: Holder metadata
+	-- ARGV[1]: Fencing token of the lease
+	-- ARGV[2]: Channel on which the released value is published to wake up waiters (optional)
+	-- Returns: 1 for successful release, 0 if no unexpired lease holds the token
+
+	local prefix = ARGV[1] .. ":"
+	local leases = redis.call("ZRANGE", KEYS[1], 0, -1)
+	for i = 1, #leases do
+		local value = leases[i]
+		local info = redis.call("HGET", KEYS[3], value)
+		if info and string.sub(info, 1, #prefix) == prefix then
+			redis.call("ZREM", KEYS[1], value)
+			redis.call("HDEL", KEYS[2], value)
+			redis.call("HDEL", KEYS[3], value)
+` + luaExpire + `
+			if ARGV[2] and ARGV[2] ~= "" then
+				redis.call("PUBLISH", ARGV[2], value)
+			end
+			return 1
+		end
 	end
+	return 0
+`)
+
+var getScript = newScript(`
+	-- Read a string value, such as the result cached by Do or a Counter
+	-- KEYS[1]: Key name
+	-- Returns: the value, false if absent or expired
+
+	return redis.call("GET", KEYS[1])
+`)
+
+var setResultScript = newScript(`
+	-- Cache the result computed by Do
+	-- KEYS[1]: Result key name
+	-- ARGV[1]: Encoded result
+	-- ARGV[2]: Lifetime of the result in milliseconds
+	-- Returns: 1
 
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+`)
+
+var deleteScript = newScript(`
+	-- Delete a value, such as a Counter
+	-- KEYS[1]: Key name
+	-- Returns: 1 if the key existed, 0 otherwise
+
+	return redis.call("DEL", KEYS[1])
+`)
+
+var incrScript = newScript(`
+	-- Add to the integer value of a Counter, absent counters starting from 0
+	-- KEYS[1]: Counter key name
+	-- ARGV[1]: Integer to add, negative to subtract
+	-- Returns: the new value
+
+	return redis.call("INCRBY", KEYS[1], ARGV[1])
+`)
+
+var incrFloatScript = newScript(`
+	-- Add to the floating point value of a Gauge, absent gauges starting from 0
+	-- KEYS[1]: Gauge key name
+	-- ARGV[1]: Number to add, negative to subtract
+	-- Returns: the new value, as a string
+
+	return redis.call("INCRBYFLOAT", KEYS[1], ARGV[1])
+`)
+
+var setScript = newScript(`
+	-- Set a value, such as a Gauge or a Flag
+	-- KEYS[1]: Key name
+	-- ARGV[1]: Value
+	-- ARGV[2]: Lifetime of the value in milliseconds, 0 or absent if it never expires
+	-- Returns: 1 if the key was absent, 0 if its value was replaced
+
+	local existed = redis.call("EXISTS", KEYS[1])
+	local ttl = tonumber(ARGV[2]) or 0
+	if ttl > 0 then
+		redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+	else
+		redis.call("SET", KEYS[1], ARGV[1])
+	end
+	return 1 - existed
+`)
+
+var compareAndSetScript = newScript(`
+	-- Set a Counter or a Gauge if it holds the expected value, absent values standing for 0
+	-- KEYS[1]: Key name
+	-- ARGV[1]: Expected value
+	-- ARGV[2]: New value
+	-- ARGV[3]: "1" to compare the values as floating point numbers, as strings otherwise
+	-- Returns: 1 if the value was set, 0 if the current value differs from the expected one
+
+	local current = redis.call("GET", KEYS[1]) or "0"
+	if ARGV[3] == "1" then
+		if tonumber(current) ~= tonumber(ARGV[1]) then
+			return 0
+		end
+	elseif current ~= ARGV[1] then
+		return 0
+	end
+	redis.call("SET", KEYS[1], ARGV[2], "KEEPTTL")
+	return 1
+`)
+
+var countDownScript = newScript(`
+	-- Count down a CountdownLatch, stored as the number of countdowns so far
+	-- KEYS[1]: Latch key name
+	-- ARGV[1]: Initial count of the latch
+	-- ARGV[2]: Channel on which the opening of the latch is published
+	-- Returns: the remaining count, 0 once the latch is open
+
+	local count = tonumber(ARGV[1])
+	local done = tonumber(redis.call("GET", KEYS[1]) or "0")
+	if done >= count then
+		return 0
+	end
+	done = redis.call("INCR", KEYS[1])
+	if done == count then
+		redis.call("PUBLISH", ARGV[2], "0")
+	end
+	return count - done
+`)
+
+var barrierAwaitScript = newScript(`
+	-- Register the arrival of a party at a Barrier
+	-- KEYS[1]: Barrier Hash, with the current generation and the number of parties arrived in it
+	-- ARGV[1]: Number of parties tripping the barrier
+	-- ARGV[2]: Channel on which the new generation is published when the barrier trips
+	-- Returns: the generation in which the party arrived, and 1 if its arrival tripped the
+	--          barrier, 0 otherwise
+
+	local generation = tonumber(redis.call("HGET", KEYS[1], "generation") or "0")
+	local arrived = redis.call("HINCRBY", KEYS[1], "arrived", 1)
+	if arrived >= tonumber(ARGV[1]) then
+		redis.call("HSET", KEYS[1], "generation", generation + 1, "arrived", 0)
+		redis.call("PUBLISH", ARGV[2], generation + 1)
+		return {generation, 1}
+	end
+	return {generation, 0}
+`)
+
+var barrierLeaveScript = newScript(`
+	-- Withdraw the arrival of a party that stopped waiting at a Barrier
+	-- KEYS[1]: Barrier Hash
+	-- ARGV[1]: Generation in which the party arrived
+	-- Returns: 1 if the arrival was withdrawn, 0 if the barrier tripped meanwhile
+
+	local generation = tonumber(redis.call("HGET", KEYS[1], "generation") or "0")
+	if generation ~= tonumber(ARGV[1]) then
+		return 0
+	end
+	redis.call("HINCRBY", KEYS[1], "arrived", -1)
+	return 1
+`)
+
+var barrierStateScript = newScript(`
+	-- Read the state of a Barrier
+	-- KEYS[1]: Barrier Hash
+	-- Returns: the current generation and the number of parties arrived in it
+
+	local generation = tonumber(redis.call("HGET", KEYS[1], "generation") or "0")
+	local arrived = tonumber(redis.call("HGET", KEYS[1], "arrived") or "0")
+	return {generation, arrived}
+`)
+
+var onceStateScript = newScript(`
+	-- Read the state of a Once
+	-- KEYS[1]: Once Hash, with the completion time, the number of failed attempts and the last error
+	-- Returns: the completion time in milliseconds (0 if not done), the number of failed
+	--          attempts and the error of the last one (empty if none)
+
+	local state = redis.call("HMGET", KEYS[1], "done", "attempts", "error")
+	return {tonumber(state[1] or "0"), tonumber(state[2] or "0"), state[3] or ""}
+`)
+
+var onceDoneScript = newScript(luaNow + `
+	-- Record the completion of a Once
+	-- KEYS[1]: Once Hash
+	-- Returns: the completion time in milliseconds
+
+	redis.call("HSET", KEYS[1], "done", now)
+	redis.call("HDEL", KEYS[1], "error")
+	return now
+`)
+
+var onceFailScript = newScript(`
+	-- Record a failed attempt of a Once
+	-- KEYS[1]: Once Hash
+	-- ARGV[1]: Error of the attempt
+	-- Returns: the number of failed attempts
+
+	redis.call("HSET", KEYS[1], "error", ARGV[1])
+	return redis.call("HINCRBY", KEYS[1], "attempts", 1)
+`)
+
+var dequeueScript = newScript(`
+	-- Remove a fair waiter from the queue of a lock (see WithFairness)
+	-- KEYS[1]: Queue of the fair waiters
+	-- ARGV[1]: Lock value
+	-- ARGV[2]: Ticket of the waiter
+	-- Returns: 1 if the waiter was queued, 0 otherwise
+
+	local suffix = ":" .. ARGV[1]
+	for _, member in ipairs(redis.call("ZRANGE", KEYS[1], 0, -1)) do
+		local ticket, rest = string.match(member, "^%d+:%d+:([^:]*)(:.*)$")
+		if ticket == ARGV[2] and rest == suffix then
+			redis.call("ZREM", KEYS[1], member)
+			return 1
+		end
+	end
+	return 0
+`)
+
+// luaPurgeEach removes the expired leases of every key in KEYS.
+const luaPurgeEach = `
+	for i = 1, #KEYS do
+		redis.call("ZREMRANGEBYSCORE", KEYS[i], "-inf", string.format("%d", now))
+	end
+`
+
+// luaExpireEach makes every key in KEYS expire together with its own longest
+// lease, or persist while any of its leases never expires.
+const luaExpireEach = `
+	for i = 1, #KEYS do
+		local last = redis.call("ZRANGE", KEYS[i], -1, -1, "WITHSCORES")
+		if #last > 0 then
+			if last[2] == "inf" or last[2] == "+inf" then
+				redis.call("PERSIST", KEYS[i])
+			else
+				redis.call("PEXPIREAT", KEYS[i], last[2])
+			end
+		end
+	end
+`
+
+var rLockScript = newScript(luaNow + luaPurgeEach + `
+	-- Attempt to acquire the read lock of a read-write lock
+	-- The writer, the readers and the waiting writers are Sorted Sets of lock values
+	-- scored by their lease expiration time in milliseconds (+inf if it never expires)
+	-- KEYS[1]: Writer key name
+	-- KEYS[2]: Readers key name
+	-- KEYS[3]: Waiting writers key name
+	-- ARGV[1]: Lock value
+	-- ARGV[2]: Lease duration in milliseconds, 0 if the lock never expires
+	-- ARGV[3]: "1" if waiting writers take precedence over new readers
+	-- Returns: 1 for successful acquisition, 0 for lock already occupied
+
+	local value = ARGV[1]
+	local ttl = tonumber(ARGV[2]) or 0
+
+	-- Readers are excluded by a writer holding the lock, or waiting for it if writers are preferred
+	if redis.call("ZCARD", KEYS[1]) > 0 then
+		return 0
+	end
+	if ARGV[3] == "1" and redis.call("ZCARD", KEYS[3]) > 0 then
+		return 0
+	end
+
+	-- If value already holds an unexpired read lease, lock is occupied
+	if redis.call("ZSCORE", KEYS[2], value) then
+		return 0
+	end
+
+	if ttl > 0 then
+		redis.call("ZADD", KEYS[2], string.format("%d", now + ttl), value)
+	else
+		redis.call("ZADD", KEYS[2], "+inf", value)
+	end
+` + luaExpireEach + `
+	return 1
+`)
+
+var wLockScript = newScript(luaNow + luaPurgeEach + `
+	-- Attempt to acquire the write lock of a read-write lock
+	-- KEYS[1]: Writer key name
+	-- KEYS[2]: Readers key name
+	-- KEYS[3]: Waiting writers key name
+	-- ARGV[1]: Lock value
+	-- ARGV[2]: Lease duration in milliseconds, 0 if the lock never expires
+	-- ARGV[3]: Duration in milliseconds for which a writer failing to acquire the lock
+	--          is registered as waiting, 0 to not register it
+	-- Returns: 1 for successful acquisition, 0 for lock already occupied
+
+	local value = ARGV[1]
+	local ttl = tonumber(ARGV[2]) or 0
+	local wait = tonumber(ARGV[3]) or 0
+
+	-- Writers are excluded by any other writer or reader
+	if redis.call("ZCARD", KEYS[1]) > 0 or redis.call("ZCARD", KEYS[2]) > 0 then
+		if wait > 0 then
+			redis.call("ZADD", KEYS[3], string.format("%d", now + wait), value)
+` + luaExpireEach + `
+		end
+		return 0
+	end
+
+	if ttl > 0 then
+		redis.call("ZADD", KEYS[1], string.format("%d", now + ttl), value)
+	else
+		redis.call("ZADD", KEYS[1], "+inf", value)
+	end
+	redis.call("ZREM", KEYS[3], value)
+` + luaExpireEach + `
 	return 1
 `)
 
 func db() (redis.Scripter, error) {
 	v := rdb.Load()
-	if v == nil || v == (*redis.Client)(nil) {
+	if v == nil {
+		return nil, ErrRedisNotInitialized
+	}
+	// A typed nil client, such as (*redis.ClusterClient)(nil), is not initialized either
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
 		return nil, ErrRedisNotInitialized
 	}
 	return v.(redis.Scripter), nil
@@ -100,6 +635,14 @@ func getRedisKey(name string) (string, error) {
 	return getRedisKeyWithPrefix(RedisKeyPrefix, name)
 }
 
+// LockKey returns the key under which the lock of the mutex named name is stored,
+// "<RedisKeyPrefix>:<name>". It is the Lease.Key passed to the LockBackend.
+//
+// Returns an error if both RedisKeyPrefix and name are empty.
+func LockKey(name string) (string, error) {
+	return defaultRegistry.LockKey(name)
+}
+
 // getRedisKeyWithPrefix generates a Redis key using the specified prefix and name.
 // This function follows the principle of "explicit dependencies over implicit dependencies"
 // by requiring the prefix to be passed as a parameter rather than relying on global state.
@@ -118,7 +661,8 @@ func getRedisKey(name string) (string, error) {
 //
 // Returns:
 //   - The generated Redis key as a string
-//   - An error if both prefix and name are empty after trimming
+//   - An error if both prefix and name are empty after trimming, or if the key
+//     contains a "}" but no hash tag (see companionKey)
 //
 // The function handles various edge cases:
 //   - Trims whitespace from the name
@@ -141,10 +685,83 @@ func getRedisKeyWithPrefix(prefix, name string) (string, error) {
 	}
 
 	// If prefix is empty, return just the name without a separator
-	if prefix == "" {
-		return name, nil
+	key := name
+	if prefix != "" {
+		key = fmt.Sprintf("%s:%s", prefix, name)
+	}
+
+	// Without a hash tag, the whole key becomes the hash tag of its companion keys,
+	// which only hash to its slot if it contains no "}"
+	if !hasHashTag(key) && strings.Contains(key, "}") {
+		return "", ErrMutexNameInvalid
 	}
-	return fmt.Sprintf("%s:%s", prefix, name), nil
+	return key, nil
+}
+
+// companionKey returns the key named suffix that accompanies the lock stored at key,
+// such as the hold counts of its reentrant leases. Companion keys hash to the same
+// Redis Cluster slot as key, so the scripts accessing them together stay single-slot:
+// if key has no hash tag, it becomes the hash tag of the companion key. Keys
+// containing a "}" but no hash tag are rejected by getRedisKeyWithPrefix, since the
+// hash tag of their companion keys would end at that "}".
+//
+// Example:
+//   - companionKey("mutex:orders", "holds") → "{mutex:orders}:holds"
+//   - companionKey("mutex:{orders}", "holds") → "mutex:{orders}:holds"
+func companionKey(key, suffix string) string {
+	if hasHashTag(key) {
+		return key + ":" + suffix
+	}
+	return "{" + key + "}:" + suffix
+}
+
+// hasHashTag reports whether only part of key is hashed to find its Redis Cluster
+// slot, that is whether key contains a non-empty substring between the first "{"
+// and the first "}" after it.
+func hasHashTag(key string) bool {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return false
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	return end > 0
+}
+
+// infoKey returns the key of the Hash storing the holder metadata of the leases
+// of the lock stored at key, see Metadata.
+func infoKey(key string) string {
+	return companionKey(key, "info")
+}
+
+// fenceKey returns the key of the counter from which the fencing tokens of the
+// leases of the lock stored at key are drawn. Unlike the other companion keys it
+// never expires, so the tokens keep increasing over the lifetime of the lock.
+func fenceKey(key string) string {
+	return companionKey(key, "fence")
+}
+
+// queueKey returns the key of the Sorted Set queuing the fair waiters for the lock
+// stored at key (see WithFairness).
+func queueKey(key string) string {
+	return companionKey(key, "queue")
+}
+
+// queueSeqKey returns the key of the counter from which the positions of the fair
+// waiters for the lock stored at key are drawn.
+func queueSeqKey(key string) string {
+	return companionKey(key, "queue:seq")
+}
+
+// holdsKey returns the key of the Hash that stores the hold counts of the
+// reentrant leases of the lock stored at key (see WithReentrant).
+func holdsKey(key string) string {
+	return companionKey(key, "holds")
+}
+
+// resultKey returns the key caching the result computed by Do under the lock
+// stored at key.
+func resultKey(key string) string {
+	return companionKey(key, "result")
 }
 
 // serializeValue converts a value to a string representation for storage in Redis.
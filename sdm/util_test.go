@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -127,6 +128,34 @@ func TestGetRedisKey(t *testing.T) {
 			input:       "",
 			expectedKey: "default",
 		},
+		{
+			name:        "哈希标签",
+			prefix:      "mutex",
+			defaultName: "default",
+			input:       "{orders}:items",
+			expectedKey: "mutex:{orders}:items",
+		},
+		{
+			name:        "只有左花括号",
+			prefix:      "mutex",
+			defaultName: "default",
+			input:       "{orders",
+			expectedKey: "mutex:{orders",
+		},
+		{
+			name:        "错误情况：空的哈希标签",
+			prefix:      "mutex",
+			defaultName: "default",
+			input:       "{}orders",
+			hasError:    true,
+		},
+		{
+			name:        "错误情况：倒置的花括号",
+			prefix:      "mutex",
+			defaultName: "default",
+			input:       "}a{",
+			hasError:    true,
+		},
 		{
 			name:        "错误情况：前缀为空且名称为空",
 			prefix:      "",
@@ -635,3 +664,103 @@ func BenchmarkRedisScript(b *testing.B) {
 		}
 	}
 }
+
+// TestCompanionKey 测试伴随键的生成
+func TestCompanionKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"mutex:orders", "{mutex:orders}:holds"},
+		{"mutex:{orders}", "mutex:{orders}:holds"},
+		{"mutex:{orders}:items", "mutex:{orders}:items:holds"},
+		{"mutex:{orders", "{mutex:{orders}:holds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, companionKey(tt.key, "holds"))
+		})
+	}
+	assert.Equal(t, companionKey("mutex:orders", "holds"), holdsKey("mutex:orders"))
+}
+
+// TestCompanionKeySlot 测试伴随键与主键位于同一个 Redis 集群槽，花括号不成对的名称被拒绝
+func TestCompanionKeySlot(t *testing.T) {
+	// CLUSTER KEYSLOT 的已知结果
+	require.Equal(t, 12182, keySlot("foo"))
+	require.Equal(t, keySlot("bar"), keySlot("{bar}:foo"))
+
+	for _, name := range []string{"orders", "{orders}", "{orders}:items", "{orders", "a{b}c}", "{}orders", "}a{", "orders}", "{{}}"} {
+		t.Run(name, func(t *testing.T) {
+			key, err := getRedisKeyWithPrefix("mutex", name)
+			if err != nil {
+				assert.ErrorIs(t, err, ErrMutexNameInvalid)
+				assert.NotEqual(t, keySlot("mutex:"+name), keySlot(companionKey("mutex:"+name, "holds")),
+					"只应拒绝伴随键无法与主键位于同一个槽的名称")
+				return
+			}
+			assert.Equal(t, keySlot(key), keySlot(companionKey(key, "holds")))
+		})
+	}
+}
+
+// keySlot 返回 key 所在的 Redis 集群槽，与 CLUSTER KEYSLOT 相同
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	// CRC16-CCITT (XMODEM)
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return int(crc) % 16384
+}
+
+// TestUtilDB_Clients 测试不同类型的 Redis 客户端
+func TestUtilDB_Clients(t *testing.T) {
+	originalValue := rdb.Load()
+	defer func() {
+		rdb.Store(originalValue)
+	}()
+
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{"localhost:7000"}})
+	defer cluster.Close()
+
+	t.Run("支持集群客户端", func(t *testing.T) {
+		SetRedis(cluster)
+		retrievedClient, err := db()
+		require.NoError(t, err)
+		assert.Equal(t, cluster, retrievedClient)
+	})
+
+	t.Run("可以替换为其他类型的客户端", func(t *testing.T) {
+		client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+		defer client.Close()
+
+		SetRedis(redis.UniversalClient(client))
+		retrievedClient, err := db()
+		require.NoError(t, err)
+		assert.Equal(t, client, retrievedClient)
+	})
+
+	t.Run("nil 客户端", func(t *testing.T) {
+		SetRedis((*redis.ClusterClient)(nil))
+		_, err := db()
+		assert.Equal(t, ErrRedisNotInitialized, err)
+
+		SetRedis(nil)
+		_, err = db()
+		assert.Equal(t, ErrRedisNotInitialized, err)
+	})
+}
@@ -0,0 +1,105 @@
+// Package sdm provides a simple distributed mutex (lock) implementation using Redis.
+// This file contains the watchdog that renews the lease of held locks in the background.
+package sdm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// watchdogs tracks the running watchdogs by lease, so that Unlock can stop
+// the watchdog of the lease it releases.
+var watchdogs sync.Map // map[leaseID]*watchdog
+
+// leaseID identifies the lease of one value on one lock key
+type leaseID struct {
+	key   string
+	value string
+}
+
+// watchdog renews a single lease until it is stopped
+type watchdog struct {
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+}
+
+// startWatchdog starts renewing lease of mutex name through b every interval until
+// ctx is done, stopWatchdog is called for the lease, or the lease is lost, notifying
+// hooks of the renewals and the loss and recording them under metric. An already running watchdog for the same lease
+// is replaced.
+func startWatchdog(ctx context.Context, name, metric string, hooks []Hooks, b LockBackend, lease Lease, interval time.Duration) {
+	id := leaseID{key: lease.Key, value: lease.Value}
+	w := &watchdog{stop: make(chan struct{}), done: make(chan struct{})}
+	if old, loaded := watchdogs.Swap(id, w); loaded {
+		old.(*watchdog).halt()
+	}
+
+	go func() {
+		defer close(w.done)
+		defer watchdogs.CompareAndDelete(id, w)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+
+			start := time.Now()
+			renewed, err := b.Renew(ctx, lease)
+			if ctx.Err() != nil {
+				// The caller's context was done during the renewal
+				return
+			}
+			if err != nil {
+				// Transient failure: retry on the next tick, the lease stays
+				// valid until its TTL elapses
+				observeRenewal(metric, false, err)
+				emit(eventRenewal, name, hooks, lease, LockEvent{Err: err})
+				continue
+			}
+			observeRenewal(metric, renewed, nil)
+			if !renewed {
+				// The lease was released or has expired, it is lost unless
+				// Unlock released it in the meantime
+				if h := dropLease(id); h != nil {
+					h.lose()
+				}
+				return
+			}
+			if h := lookupLease(id); h != nil {
+				h.extend(start, lease.TTL)
+			}
+			emit(eventRenewal, name, hooks, lease, LockEvent{})
+		}
+	}()
+}
+
+// stopWatchdog stops the watchdog of the lease of value on key, if any,
+// and waits for it to exit so no renewal races with the release that follows.
+func stopWatchdog(key, value string) {
+	if w, ok := watchdogs.LoadAndDelete(leaseID{key: key, value: value}); ok {
+		w.(*watchdog).halt()
+		<-w.(*watchdog).done
+	}
+}
+
+// haltWatchdog signals the watchdog of the lease of value on key, if any, to stop
+// without waiting for it, so that the watchdog itself may call it.
+func haltWatchdog(key, value string) {
+	if w, ok := watchdogs.LoadAndDelete(leaseID{key: key, value: value}); ok {
+		w.(*watchdog).halt()
+	}
+}
+
+// halt signals the watchdog to stop
+func (w *watchdog) halt() {
+	w.once.Do(func() { close(w.stop) })
+}
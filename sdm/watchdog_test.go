@@ -0,0 +1,160 @@
+package sdm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions(t *testing.T) {
+	t.Run("默认不过期", func(t *testing.T) {
+		m, err := New[string]("test-options")
+		require.NoError(t, err)
+		assert.Zero(t, m.opts.ttl)
+		assert.False(t, m.opts.watchdog)
+	})
+
+	t.Run("With 不修改原互斥锁", func(t *testing.T) {
+		m, err := New[string]("test-options")
+		require.NoError(t, err)
+		m2 := m.With(WithTTL(time.Second), WithWatchdog())
+		assert.Zero(t, m.opts.ttl)
+		assert.Equal(t, time.Second, m2.opts.ttl)
+		assert.True(t, m2.opts.watchdog)
+		assert.Equal(t, m.Name(), m2.Name())
+	})
+
+	t.Run("续期间隔", func(t *testing.T) {
+		assert.Equal(t, 10*time.Second, options{ttl: 30 * time.Second}.renewInterval())
+		assert.Equal(t, 5*time.Second, options{ttl: 30 * time.Second, interval: 5 * time.Second}.renewInterval())
+		// 间隔不小于 TTL 时使用默认值
+		assert.Equal(t, 10*time.Second, options{ttl: 30 * time.Second, interval: time.Minute}.renewInterval())
+	})
+
+	t.Run("负数 TTL 表示不过期", func(t *testing.T) {
+		m, err := New[string]("test-options")
+		require.NoError(t, err)
+		assert.Zero(t, m.With(WithTTL(-time.Second)).opts.ttl)
+	})
+}
+
+func TestMutex_TTL(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+	ctx := context.Background()
+
+	mutex, err := New[string]("test-ttl")
+	require.NoError(t, err)
+	mutex = mutex.With(WithTTL(200 * time.Millisecond))
+	key, err := getRedisKeyWithPrefix(RedisKeyPrefix, mutex.Name())
+	require.NoError(t, err)
+
+	acquired, err := mutex.TryLock(ctx, "owner-1")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// 键随租约过期
+	pttl, err := client.PTTL(ctx, key).Result()
+	require.NoError(t, err)
+	assert.Greater(t, pttl, time.Duration(0))
+
+	acquired, err = mutex.TryLock(ctx, "owner-1")
+	require.NoError(t, err)
+	assert.False(t, acquired, "租约有效期内不能重复获取")
+
+	time.Sleep(300 * time.Millisecond)
+
+	locked, err := mutex.IsLocked(ctx)
+	require.NoError(t, err)
+	assert.False(t, locked, "租约过期后锁应该被释放")
+
+	// 过期后释放应该返回 ErrMutexNotAcquired
+	assert.Equal(t, ErrMutexNotAcquired, mutex.Unlock(ctx, "owner-1"))
+
+	// 过期后可以重新获取
+	acquired, err = mutex.TryLock(ctx, "owner-1")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+
+	t.Run("不过期的锁", func(t *testing.T) {
+		m, err := New[string]("test-ttl-persist")
+		require.NoError(t, err)
+		acquired, err := m.TryLock(ctx, "owner-1")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		persistKey, err := getRedisKeyWithPrefix(RedisKeyPrefix, m.Name())
+		require.NoError(t, err)
+		pttl, err := client.PTTL(ctx, persistKey).Result()
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(-1), pttl, "没有 TTL 的锁不应该过期")
+		require.NoError(t, m.Unlock(ctx, "owner-1"))
+	})
+}
+
+func TestMutex_Watchdog(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		t.Skip("需要 Redis 服务器")
+		return
+	}
+	defer client.Close()
+
+	SetRedis(client)
+
+	mutex, err := New[string]("test-watchdog")
+	require.NoError(t, err)
+	mutex = mutex.With(WithTTL(150*time.Millisecond), WithWatchdog(30*time.Millisecond))
+
+	t.Run("持有期间自动续期", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, mutex.Lock(ctx, "owner-1"))
+
+		time.Sleep(400 * time.Millisecond)
+
+		locked, err := mutex.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.True(t, locked, "看门狗应该在持有期间续期")
+
+		require.NoError(t, mutex.Unlock(ctx, "owner-1"))
+		locked, err = mutex.IsLocked(ctx)
+		require.NoError(t, err)
+		assert.False(t, locked)
+
+		// 释放后看门狗停止
+		key, err := getRedisKeyWithPrefix(RedisKeyPrefix, mutex.Name())
+		require.NoError(t, err)
+		_, running := watchdogs.Load(leaseID{key: key, value: "owner-1"})
+		assert.False(t, running)
+	})
+
+	t.Run("上下文结束后停止续期", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		acquired, err := mutex.TryLock(ctx, "owner-2", time.Second)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		// TryLock 的超时不影响看门狗
+		time.Sleep(300 * time.Millisecond)
+		locked, err := mutex.IsLocked(context.Background())
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		cancel()
+		time.Sleep(300 * time.Millisecond)
+
+		locked, err = mutex.IsLocked(context.Background())
+		require.NoError(t, err)
+		assert.False(t, locked, "上下文结束后锁应该过期")
+	})
+}